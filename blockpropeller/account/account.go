@@ -36,6 +36,14 @@ type Account struct {
 	Email    Email    `json:"email" gorm:"type:varchar(255) not null;unique_index"`
 	Password Password `json:"-" gorm:"type:varchar(255)"`
 
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty" gorm:"type:timestamp"`
+
+	// TOTPSecret is set once the Account enrolls into two-factor authentication,
+	// which is enabled only after the first code generated from it is verified.
+	TOTPSecret         TOTPSecret `json:"-" gorm:"type:text"`
+	TOTPLastStep       int64      `json:"-" gorm:"not null;default:0"`
+	TwoFactorEnabledAt *time.Time `json:"two_factor_enabled_at,omitempty" gorm:"type:timestamp"`
+
 	CreatedAt time.Time `json:"created_at" gorm:"type:timestamp not null;default:CURRENT_TIMESTAMP"`
 	UpdatedAt time.Time `json:"updated_at" gorm:"type:timestamp not null;default:CURRENT_TIMESTAMP"`
 }
@@ -48,3 +56,19 @@ func NewAccount(email Email, password Password) *Account {
 		Password: password,
 	}
 }
+
+// IsEmailVerified checks whether the Account proved that it owns its Email.
+func (acc *Account) IsEmailVerified() bool {
+	return acc.EmailVerifiedAt != nil
+}
+
+// VerifyEmail marks the Email of the Account as verified.
+func (acc *Account) VerifyEmail() {
+	now := time.Now()
+	acc.EmailVerifiedAt = &now
+}
+
+// IsTwoFactorEnabled checks whether logging into the Account requires a second factor.
+func (acc *Account) IsTwoFactorEnabled() bool {
+	return acc.TwoFactorEnabledAt != nil
+}
@@ -0,0 +1,262 @@
+package account
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql/driver"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+)
+
+const (
+	// APIKeyPrefix prefixes every APIKeyToken, telling them apart from session tokens.
+	APIKeyPrefix = "bpk_"
+
+	// apiKeyUsageInterval is the resolution at which the last usage of an APIKey is tracked,
+	// so that authenticating bursts of requests does not update the key each time.
+	apiKeyUsageInterval = time.Minute
+)
+
+// Scope restricts the actions an APIKey can be used for.
+type Scope string
+
+// Scopes available to API keys.
+const (
+	ScopeOrganizationsRead    Scope = "organizations:read"
+	ScopeProjectsRead         Scope = "projects:read"
+	ScopeProjectsWrite        Scope = "projects:write"
+	ScopeProvidersRead        Scope = "providers:read"
+	ScopeProvidersWrite       Scope = "providers:write"
+	ScopeJobsRead             Scope = "jobs:read"
+	ScopeJobsWrite            Scope = "jobs:write"
+	ScopeServersRead          Scope = "servers:read"
+	ScopeServersWrite         Scope = "servers:write"
+	ScopeReservedIPsRead      Scope = "reserved_ips:read"
+	ScopeReservedIPsWrite     Scope = "reserved_ips:write"
+	ScopeVolumeSnapshotsRead  Scope = "volume_snapshots:read"
+	ScopeVolumeSnapshotsWrite Scope = "volume_snapshots:write"
+	ScopeAuditRead            Scope = "audit:read"
+)
+
+// ValidScopes lists all the Scopes available to API keys.
+var ValidScopes = []Scope{
+	ScopeOrganizationsRead,
+	ScopeProjectsRead, ScopeProjectsWrite,
+	ScopeProvidersRead, ScopeProvidersWrite,
+	ScopeJobsRead, ScopeJobsWrite,
+	ScopeServersRead, ScopeServersWrite,
+	ScopeReservedIPsRead, ScopeReservedIPsWrite,
+	ScopeVolumeSnapshotsRead, ScopeVolumeSnapshotsWrite,
+	ScopeAuditRead,
+}
+
+// NewScope returns a new Scope instance.
+func NewScope(scope string) Scope {
+	return Scope(scope)
+}
+
+// IsValid checks whether the Scope is one of the available Scopes.
+func (s Scope) IsValid() bool {
+	for _, valid := range ValidScopes {
+		if s == valid {
+			return true
+		}
+	}
+
+	return false
+}
+
+// String satisfies the Stringer interface.
+func (s Scope) String() string {
+	return string(s)
+}
+
+// Scopes is a set of Scopes granted to an APIKey.
+type Scopes []Scope
+
+// IsValid checks whether there is at least one Scope and all of them are valid.
+func (s Scopes) IsValid() bool {
+	if len(s) == 0 {
+		return false
+	}
+	for _, scope := range s {
+		if !scope.IsValid() {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Has checks whether the provided Scope is granted.
+func (s Scopes) Has(scope Scope) bool {
+	for _, granted := range s {
+		if granted == scope {
+			return true
+		}
+	}
+
+	return false
+}
+
+// String returns the Scopes delimited with a comma.
+func (s Scopes) String() string {
+	names := make([]string, len(s))
+	for i, scope := range s {
+		names[i] = scope.String()
+	}
+
+	return strings.Join(names, ",")
+}
+
+// Value satisfies the driver.Valuer interface.
+func (s Scopes) Value() (driver.Value, error) {
+	return s.String(), nil
+}
+
+// Scan satisfies the sql.Scanner interface.
+func (s *Scopes) Scan(src interface{}) error {
+	var raw string
+	switch src := src.(type) {
+	case nil:
+		*s = nil
+		return nil
+	case string:
+		raw = src
+	case []byte:
+		raw = string(src)
+	default:
+		return errors.Errorf("unsupported scopes type: %T", src)
+	}
+
+	*s = nil
+	for _, name := range strings.Split(raw, ",") {
+		if name != "" {
+			*s = append(*s, NewScope(name))
+		}
+	}
+
+	return nil
+}
+
+// NilAPIKeyID is an empty APIKeyID.
+var NilAPIKeyID APIKeyID
+
+// APIKeyID is a unique API key identifier.
+type APIKeyID string
+
+// NewAPIKeyID returns a new unique APIKeyID.
+func NewAPIKeyID() APIKeyID {
+	return APIKeyID(uuid.NewV4().String())
+}
+
+// String satisfies the Stringer interface.
+func (id APIKeyID) String() string {
+	return string(id)
+}
+
+// APIKeyToken is the secret used for authenticating with an APIKey.
+//
+// Only the hash of the token is stored, so the token is available only once the APIKey is created.
+type APIKeyToken string
+
+// NewAPIKeyToken returns a new random APIKeyToken.
+func NewAPIKeyToken() (APIKeyToken, error) {
+	raw := make([]byte, 32)
+
+	_, err := rand.Read(raw)
+	if err != nil {
+		return "", errors.Wrap(err, "generate api key token")
+	}
+
+	return APIKeyToken(APIKeyPrefix + hex.EncodeToString(raw)), nil
+}
+
+// IsAPIKeyToken checks whether the provided Token is an APIKeyToken rather than a session token.
+func IsAPIKeyToken(token Token) bool {
+	return strings.HasPrefix(token.String(), APIKeyPrefix)
+}
+
+// Hash returns the hash of the APIKeyToken under which the APIKey is stored.
+func (t APIKeyToken) Hash() string {
+	hash := sha256.Sum256([]byte(t))
+
+	return hex.EncodeToString(hash[:])
+}
+
+// Hint returns the beginning of the APIKeyToken, which helps users tell their keys apart.
+func (t APIKeyToken) Hint() string {
+	if len(t) < len(APIKeyPrefix)+6 {
+		return string(t)
+	}
+
+	return string(t[:len(APIKeyPrefix)+6])
+}
+
+// String satisfies the Stringer interface.
+func (t APIKeyToken) String() string {
+	return string(t)
+}
+
+// APIKey is a long-lived credential, which lets automation act on behalf of an Account
+// within the granted Scopes.
+type APIKey struct {
+	ID        APIKeyID `json:"id" gorm:"type:varchar(36) not null"`
+	AccountID ID       `json:"account_id" gorm:"type:varchar(36) not null references accounts(id)"`
+
+	Name   string `json:"name" gorm:"type:varchar(255) not null"`
+	Scopes Scopes `json:"scopes" gorm:"type:varchar(1024) not null"`
+
+	TokenHash string `json:"-" gorm:"type:varchar(64) not null;unique_index"`
+	TokenHint string `json:"token_hint" gorm:"type:varchar(20) not null"`
+
+	ExpiresAt  *time.Time `json:"expires_at,omitempty" gorm:"type:timestamp"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty" gorm:"type:timestamp"`
+
+	CreatedAt time.Time `json:"created_at" gorm:"type:timestamp not null;default:CURRENT_TIMESTAMP"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"type:timestamp not null;default:CURRENT_TIMESTAMP"`
+}
+
+// NewAPIKey returns a new APIKey instance, along with the token for authenticating with it.
+//
+// APIKeys without an expiry are valid until they are revoked.
+func NewAPIKey(accountID ID, name string, scopes Scopes, expiresAt *time.Time) (*APIKey, APIKeyToken, error) {
+	token, err := NewAPIKeyToken()
+	if err != nil {
+		return nil, "", err
+	}
+
+	return &APIKey{
+		ID:        NewAPIKeyID(),
+		AccountID: accountID,
+
+		Name:   name,
+		Scopes: scopes,
+
+		TokenHash: token.Hash(),
+		TokenHint: token.Hint(),
+
+		ExpiresAt: expiresAt,
+	}, token, nil
+}
+
+// IsExpired checks whether the APIKey can no longer be used.
+func (key *APIKey) IsExpired() bool {
+	return key.ExpiresAt != nil && !time.Now().Before(*key.ExpiresAt)
+}
+
+// MarkUsed records the usage of the APIKey, returning whether the last usage changed.
+func (key *APIKey) MarkUsed() bool {
+	now := time.Now()
+	if key.LastUsedAt != nil && now.Sub(*key.LastUsedAt) < apiKeyUsageInterval {
+		return false
+	}
+
+	key.LastUsedAt = &now
+
+	return true
+}
@@ -0,0 +1,106 @@
+package account
+
+import (
+	"context"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// ErrAPIKeyNotFound is returned when an APIKeyRepository does not find an API key to return.
+var ErrAPIKeyNotFound = errors.New("api key not found")
+
+// APIKeyRepository defines an interface for storing and retrieving API keys.
+type APIKeyRepository interface {
+	// Find an APIKey given an APIKeyID.
+	Find(ctx context.Context, id APIKeyID) (*APIKey, error)
+	// FindByToken returns the APIKey authenticated by the provided token.
+	FindByToken(ctx context.Context, token APIKeyToken) (*APIKey, error)
+	// ListByAccount lists all the API keys of an Account.
+	ListByAccount(ctx context.Context, accountID ID) ([]*APIKey, error)
+
+	// Create a new APIKey.
+	Create(ctx context.Context, key *APIKey) error
+	// Update an existing APIKey.
+	Update(ctx context.Context, key *APIKey) error
+	// Delete an existing APIKey.
+	Delete(ctx context.Context, key *APIKey) error
+}
+
+// InMemoryAPIKeyRepository holds the API keys inside an in-memory map.
+//
+// API keys are not persisted on disk and won't survive program restarts.
+type InMemoryAPIKeyRepository struct {
+	keys sync.Map
+}
+
+// NewInMemoryAPIKeyRepository returns a new InMemoryAPIKeyRepository instance.
+func NewInMemoryAPIKeyRepository() *InMemoryAPIKeyRepository {
+	return &InMemoryAPIKeyRepository{}
+}
+
+// Find an APIKey given an APIKeyID.
+func (repo *InMemoryAPIKeyRepository) Find(ctx context.Context, id APIKeyID) (*APIKey, error) {
+	key, ok := repo.keys.Load(id)
+	if !ok {
+		return nil, ErrAPIKeyNotFound
+	}
+
+	return key.(*APIKey), nil
+}
+
+// FindByToken returns the APIKey authenticated by the provided token.
+func (repo *InMemoryAPIKeyRepository) FindByToken(ctx context.Context, token APIKeyToken) (*APIKey, error) {
+	var found *APIKey
+	repo.keys.Range(func(k, v interface{}) bool {
+		key := v.(*APIKey)
+		if key.TokenHash != token.Hash() {
+			return true
+		}
+
+		found = key
+
+		return false
+	})
+	if found == nil {
+		return nil, ErrAPIKeyNotFound
+	}
+
+	return found, nil
+}
+
+// ListByAccount lists all the API keys of an Account.
+func (repo *InMemoryAPIKeyRepository) ListByAccount(ctx context.Context, accountID ID) ([]*APIKey, error) {
+	var keys []*APIKey
+	repo.keys.Range(func(k, v interface{}) bool {
+		key := v.(*APIKey)
+		if key.AccountID == accountID {
+			keys = append(keys, key)
+		}
+
+		return true
+	})
+
+	return keys, nil
+}
+
+// Create a new APIKey.
+func (repo *InMemoryAPIKeyRepository) Create(ctx context.Context, key *APIKey) error {
+	repo.keys.Store(key.ID, key)
+
+	return nil
+}
+
+// Update an existing APIKey.
+func (repo *InMemoryAPIKeyRepository) Update(ctx context.Context, key *APIKey) error {
+	repo.keys.Store(key.ID, key)
+
+	return nil
+}
+
+// Delete an existing APIKey.
+func (repo *InMemoryAPIKeyRepository) Delete(ctx context.Context, key *APIKey) error {
+	repo.keys.Delete(key.ID)
+
+	return nil
+}
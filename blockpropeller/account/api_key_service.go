@@ -0,0 +1,124 @@
+package account
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// MaxAPIKeysPerAccount is the maximum number of API keys a single Account can hold.
+const MaxAPIKeysPerAccount = 50
+
+var (
+	// ErrAPIKeyExpired is returned when authenticating with an APIKey past its expiry.
+	ErrAPIKeyExpired = errors.New("api key expired")
+	// ErrInvalidScopes is returned when an APIKey is created without any, or with unknown Scopes.
+	ErrInvalidScopes = errors.New("invalid scopes")
+	// ErrTooManyAPIKeys is returned when an Account already holds MaxAPIKeysPerAccount API keys.
+	ErrTooManyAPIKeys = errors.New("too many api keys")
+)
+
+// APIKeyService is responsible for issuing, authenticating and revoking API keys.
+type APIKeyService struct {
+	accRepo Repository
+	keyRepo APIKeyRepository
+}
+
+// NewAPIKeyService returns a new APIKeyService instance.
+func NewAPIKeyService(accRepo Repository, keyRepo APIKeyRepository) *APIKeyService {
+	return &APIKeyService{accRepo: accRepo, keyRepo: keyRepo}
+}
+
+// Create a new APIKey for an Account, returning the token for authenticating with it.
+func (s *APIKeyService) Create(
+	ctx context.Context,
+	acc *Account,
+	name string,
+	scopes Scopes,
+	expiresAt *time.Time,
+) (*APIKey, APIKeyToken, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, "", errors.New("missing api key name")
+	}
+	if !scopes.IsValid() {
+		return nil, "", errors.Wrapf(ErrInvalidScopes, "scopes %q", scopes.String())
+	}
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		return nil, "", errors.New("api key expiry must be in the future")
+	}
+
+	keys, err := s.keyRepo.ListByAccount(ctx, acc.ID)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "list api keys")
+	}
+	if len(keys) >= MaxAPIKeysPerAccount {
+		return nil, "", ErrTooManyAPIKeys
+	}
+
+	key, token, err := NewAPIKey(acc.ID, name, scopes, expiresAt)
+	if err != nil {
+		return nil, "", err
+	}
+
+	err = s.keyRepo.Create(ctx, key)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "create api key")
+	}
+
+	return key, token, nil
+}
+
+// Authenticate an APIKeyToken as an Account, returning the APIKey the Account acts through.
+func (s *APIKeyService) Authenticate(ctx context.Context, token APIKeyToken) (*Account, *APIKey, error) {
+	key, err := s.keyRepo.FindByToken(ctx, token)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "find api key by token")
+	}
+	if key.IsExpired() {
+		return nil, nil, ErrAPIKeyExpired
+	}
+
+	acc, err := s.accRepo.FindByID(ctx, key.AccountID)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "find account by id")
+	}
+
+	if key.MarkUsed() {
+		err = s.keyRepo.Update(ctx, key)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "update api key usage")
+		}
+	}
+
+	return acc, key, nil
+}
+
+// Revoke an APIKey, so it can no longer be used for authentication.
+func (s *APIKeyService) Revoke(ctx context.Context, key *APIKey) error {
+	err := s.keyRepo.Delete(ctx, key)
+	if err != nil {
+		return errors.Wrap(err, "delete api key")
+	}
+
+	return nil
+}
+
+// RevokeAll API keys of an Account, for when the Account is taken over by someone else.
+func (s *APIKeyService) RevokeAll(ctx context.Context, acc *Account) error {
+	keys, err := s.keyRepo.ListByAccount(ctx, acc.ID)
+	if err != nil {
+		return errors.Wrap(err, "list api keys")
+	}
+
+	for _, key := range keys {
+		err = s.Revoke(ctx, key)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,73 @@
+package account_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"blockpropeller.dev/blockpropeller/account"
+	"blockpropeller.dev/lib/test"
+	"github.com/pkg/errors"
+)
+
+func TestAPIKeyAuthentication(t *testing.T) {
+	ctx := context.Background()
+
+	accRepo := account.NewInMemoryRepository()
+	keyRepo := account.NewInMemoryAPIKeyRepository()
+	svc := account.NewAPIKeyService(accRepo, keyRepo)
+
+	acc := newTestAccount("ci@example.com")
+	test.CheckErr(t, "create account", accRepo.Create(ctx, acc))
+
+	_, _, err := svc.Create(ctx, acc, "CI", nil, nil)
+	test.AssertBoolEqual(t, "create without scopes", errors.Cause(err) == account.ErrInvalidScopes, true)
+
+	_, _, err = svc.Create(ctx, acc, "CI", account.Scopes{"servers:delete"}, nil)
+	test.AssertBoolEqual(t, "create with invalid scope", errors.Cause(err) == account.ErrInvalidScopes, true)
+
+	past := time.Now().Add(-time.Hour)
+	_, _, err = svc.Create(ctx, acc, "CI", account.Scopes{account.ScopeServersRead}, &past)
+	test.CheckErrExists(t, "create with expiry in the past", err)
+
+	key, token, err := svc.Create(ctx, acc, "CI", account.Scopes{account.ScopeServersRead, account.ScopeJobsWrite}, nil)
+	test.CheckErr(t, "create api key", err)
+	test.AssertBoolEqual(t, "token is an api key", account.IsAPIKeyToken(account.NewToken(token.String())), true)
+	test.AssertBoolEqual(t, "token is not stored", key.TokenHash != token.String(), true)
+	test.AssertBoolEqual(t, "key has servers:read", key.Scopes.Has(account.ScopeServersRead), true)
+	test.AssertBoolEqual(t, "key lacks servers:write", key.Scopes.Has(account.ScopeServersWrite), false)
+
+	authAcc, authKey, err := svc.Authenticate(ctx, token)
+	test.CheckErr(t, "authenticate api key", err)
+	test.AssertStringsEqual(t, "authenticated account", authAcc.ID.String(), acc.ID.String())
+	test.AssertStringsEqual(t, "authenticated key", authKey.ID.String(), key.ID.String())
+	test.AssertBoolEqual(t, "last usage tracked", authKey.LastUsedAt != nil, true)
+
+	_, _, err = svc.Authenticate(ctx, account.APIKeyToken(account.APIKeyPrefix+"unknown"))
+	test.AssertBoolEqual(t, "authenticate unknown key", errors.Cause(err) == account.ErrAPIKeyNotFound, true)
+
+	key.ExpiresAt = &past
+	_, _, err = svc.Authenticate(ctx, token)
+	test.AssertBoolEqual(t, "authenticate expired key", errors.Cause(err) == account.ErrAPIKeyExpired, true)
+
+	key.ExpiresAt = nil
+	test.CheckErr(t, "revoke api key", svc.Revoke(ctx, key))
+
+	_, _, err = svc.Authenticate(ctx, token)
+	test.AssertBoolEqual(t, "authenticate revoked key", errors.Cause(err) == account.ErrAPIKeyNotFound, true)
+}
+
+func TestScopesValue(t *testing.T) {
+	scopes := account.Scopes{account.ScopeServersRead, account.ScopeJobsWrite}
+
+	value, err := scopes.Value()
+	test.CheckErr(t, "scopes value", err)
+	test.AssertStringsEqual(t, "stored scopes", value.(string), "servers:read,jobs:write")
+
+	var scanned account.Scopes
+	test.CheckErr(t, "scan scopes", scanned.Scan([]byte("servers:read,jobs:write")))
+	test.AssertStringsEqual(t, "scanned scopes", scanned.String(), scopes.String())
+
+	test.CheckErr(t, "scan empty scopes", scanned.Scan(""))
+	test.AssertIntsEqual(t, "no scopes", len(scanned), 0)
+}
@@ -1,10 +1,19 @@
 package account
 
-import "github.com/pkg/errors"
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
 
 // JWTConfig defines the configuration parameters for JWT services.
 type JWTConfig struct {
 	Secret string `yaml:"secret"`
+
+	// AccessTokenTTL is the lifetime of access tokens, which are renewed using refresh tokens.
+	AccessTokenTTL time.Duration `yaml:"access_token_ttl"`
+	// RefreshTokenTTL is the time a session stays valid without being refreshed.
+	RefreshTokenTTL time.Duration `yaml:"refresh_token_ttl"`
 }
 
 // Validate satisfies the config.Config interface.
@@ -12,6 +21,15 @@ func (cfg *JWTConfig) Validate() error {
 	if cfg.Secret == "" {
 		return errors.New("missing JWT secret")
 	}
+	if cfg.AccessTokenTTL == 0 {
+		cfg.AccessTokenTTL = 15 * time.Minute
+	}
+	if cfg.RefreshTokenTTL == 0 {
+		cfg.RefreshTokenTTL = 30 * 24 * time.Hour
+	}
+	if cfg.RefreshTokenTTL < cfg.AccessTokenTTL {
+		return errors.New("refresh token TTL must not be shorter than access token TTL")
+	}
 
 	return nil
 }
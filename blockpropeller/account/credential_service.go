@@ -0,0 +1,262 @@
+package account
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"blockpropeller.dev/blockpropeller/database/transaction"
+	"blockpropeller.dev/blockpropeller/mail"
+	"blockpropeller.dev/lib/log"
+	"github.com/pkg/errors"
+)
+
+// ErrEmailAlreadyVerified is returned when requesting the verification of an already verified Email.
+var ErrEmailAlreadyVerified = errors.New("email already verified")
+
+// CredentialService is responsible for verifying Account emails and recovering forgotten passwords.
+type CredentialService struct {
+	txContext transaction.TxContext
+	accRepo   Repository
+	tokenRepo OneTimeTokenRepository
+	accSvc    *Service
+	apiKeySvc *APIKeyService
+
+	mailer  mail.Mailer
+	baseURL string
+}
+
+// NewCredentialService returns a new CredentialService instance.
+func NewCredentialService(
+	txContext transaction.TxContext,
+	accRepo Repository,
+	tokenRepo OneTimeTokenRepository,
+	accSvc *Service,
+	apiKeySvc *APIKeyService,
+	mailer mail.Mailer,
+	mailCfg *mail.Config,
+) *CredentialService {
+	return &CredentialService{
+		txContext: txContext,
+		accRepo:   accRepo,
+		tokenRepo: tokenRepo,
+		accSvc:    accSvc,
+		apiKeySvc: apiKeySvc,
+		mailer:    mailer,
+		baseURL:   strings.TrimSuffix(mailCfg.BaseURL, "/"),
+	}
+}
+
+// SendVerification emails the Account a link for verifying its Email.
+func (s *CredentialService) SendVerification(ctx context.Context, acc *Account) error {
+	if acc.IsEmailVerified() {
+		return ErrEmailAlreadyVerified
+	}
+
+	secret, err := s.issue(ctx, acc, PurposeEmailVerification)
+	if err != nil {
+		return err
+	}
+
+	return s.send(ctx, acc, "Verify your BlockPropeller email", fmt.Sprintf(
+		"Confirm that %s is your email address by following the link below:\n\n%s\n\n"+
+			"The link expires in %s. If you did not create a BlockPropeller account, ignore this email.\n",
+		acc.Email, s.link("verify_email", secret), PurposeEmailVerification.TTL()))
+}
+
+// VerifyEmail verifies the Email of the Account the secret was sent to.
+func (s *CredentialService) VerifyEmail(ctx context.Context, secret OneTimeSecret) (*Account, error) {
+	token, acc, err := s.redeem(ctx, PurposeEmailVerification, secret)
+	if err != nil {
+		return nil, err
+	}
+
+	acc.VerifyEmail()
+
+	err = s.accRepo.Update(ctx, acc)
+	if err != nil {
+		return nil, errors.Wrap(err, "update account")
+	}
+
+	err = s.use(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	return acc, nil
+}
+
+// RequestPasswordReset emails the owner of an Email a link for resetting the password of their Account.
+//
+// Requests for unknown emails succeed without sending anything,
+// so that the registered emails cannot be discovered.
+func (s *CredentialService) RequestPasswordReset(ctx context.Context, email Email) error {
+	acc, err := s.accRepo.FindByEmail(ctx, email)
+	if errors.Cause(err) == ErrAccountNotFound {
+		log.Debug("password reset requested for unknown email", log.Fields{"email": email})
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "find account by email")
+	}
+
+	secret, err := s.issue(ctx, acc, PurposePasswordReset)
+	if err != nil {
+		return err
+	}
+
+	return s.send(ctx, acc, "Reset your BlockPropeller password", fmt.Sprintf(
+		"A password reset was requested for your BlockPropeller account. Choose a new password by following the link below:\n\n%s\n\n"+
+			"The link expires in %s and can be used once. If you did not request a password reset, ignore this email.\n",
+		s.link("reset_password", secret), PurposePasswordReset.TTL()))
+}
+
+// ResetPassword sets a new password for the Account the secret was sent to, returning the Account.
+//
+// Resetting the password logs the Account out of all of its Sessions, revokes its API keys and invalidates
+// all the other password reset links. As the secret was received by email, the Email is verified as well.
+//
+// The secret is redeemed and the password updated in a single transaction,
+// so concurrent requests with the same secret cannot both succeed.
+func (s *CredentialService) ResetPassword(ctx context.Context, secret OneTimeSecret, password ClearPassword) (*Account, error) {
+	if err := password.Validate(); err != nil {
+		return nil, errors.Wrap(err, "invalid password")
+	}
+
+	// In-memory transactions cannot fail, so an unusable secret is reported after the transaction.
+	var acc *Account
+	var redeemErr error
+
+	err := s.txContext.RunInTransaction(ctx, func(ctx context.Context) error {
+		var token *OneTimeToken
+		token, acc, redeemErr = s.redeem(ctx, PurposePasswordReset, secret)
+		if redeemErr != nil {
+			return nil
+		}
+
+		redeemErr = s.use(ctx, token)
+		if redeemErr != nil {
+			return nil
+		}
+
+		return s.resetPassword(ctx, acc, password)
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "reset password")
+	}
+	if redeemErr != nil {
+		return nil, redeemErr
+	}
+
+	err = s.accSvc.LogoutAll(acc)
+	if err != nil {
+		return nil, errors.Wrap(err, "logout all sessions")
+	}
+
+	return acc, nil
+}
+
+// resetPassword updates the password of the Account, using up its other password reset tokens
+// and revoking its API keys.
+func (s *CredentialService) resetPassword(ctx context.Context, acc *Account, password ClearPassword) error {
+	var err error
+	acc.Password, err = GeneratePassword(password)
+	if err != nil {
+		return errors.Wrap(err, "generate password")
+	}
+	if !acc.IsEmailVerified() {
+		acc.VerifyEmail()
+	}
+
+	err = s.accRepo.Update(ctx, acc)
+	if err != nil {
+		return errors.Wrap(err, "update account")
+	}
+
+	tokens, err := s.tokenRepo.ListUsable(ctx, acc.ID, PurposePasswordReset)
+	if err != nil {
+		return errors.Wrap(err, "list password reset tokens")
+	}
+	for _, token := range tokens {
+		err = s.use(ctx, token)
+		// Tokens used by a concurrent request in the meantime are used up all the same.
+		if err != nil && errors.Cause(err) != ErrOneTimeTokenNotFound {
+			return err
+		}
+	}
+
+	err = s.apiKeySvc.RevokeAll(ctx, acc)
+	if err != nil {
+		return errors.Wrap(err, "revoke all api keys")
+	}
+
+	return nil
+}
+
+func (s *CredentialService) issue(ctx context.Context, acc *Account, purpose TokenPurpose) (OneTimeSecret, error) {
+	token, secret, err := NewOneTimeToken(acc, purpose)
+	if err != nil {
+		return "", errors.Wrapf(err, "issue %s token", purpose)
+	}
+
+	err = s.tokenRepo.Create(ctx, token)
+	if err != nil {
+		return "", errors.Wrapf(err, "create %s token", purpose)
+	}
+
+	return secret, nil
+}
+
+// redeem returns the usable token for the secret, along with the Account it was issued to.
+//
+// Tokens issued to a different email than the current email of the Account are not usable.
+func (s *CredentialService) redeem(
+	ctx context.Context,
+	purpose TokenPurpose,
+	secret OneTimeSecret,
+) (*OneTimeToken, *Account, error) {
+	token, err := s.tokenRepo.FindBySecret(ctx, purpose, secret)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "find %s token", purpose)
+	}
+	if !token.IsUsable() {
+		return nil, nil, errors.Wrapf(ErrOneTimeTokenNotFound, "%s token used or expired", purpose)
+	}
+
+	acc, err := s.accRepo.FindByID(ctx, token.AccountID)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "find account by id")
+	}
+	if acc.Email != token.Email {
+		return nil, nil, errors.Wrapf(ErrOneTimeTokenNotFound, "%s token sent to previous email", purpose)
+	}
+
+	return token, acc, nil
+}
+
+func (s *CredentialService) use(ctx context.Context, token *OneTimeToken) error {
+	err := s.tokenRepo.Use(ctx, token)
+	if err != nil {
+		return errors.Wrapf(err, "use %s token", token.Purpose)
+	}
+
+	return nil
+}
+
+func (s *CredentialService) send(ctx context.Context, acc *Account, subject, body string) error {
+	err := s.mailer.Send(ctx, &mail.Message{
+		To:      acc.Email.String(),
+		Subject: subject,
+		Body:    body,
+	})
+	if err != nil {
+		return errors.Wrap(err, "send mail")
+	}
+
+	return nil
+}
+
+func (s *CredentialService) link(path string, secret OneTimeSecret) string {
+	return fmt.Sprintf("%s/%s?token=%s", s.baseURL, path, url.QueryEscape(secret.String()))
+}
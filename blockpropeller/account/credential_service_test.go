@@ -0,0 +1,227 @@
+package account_test
+
+import (
+	"context"
+	"net/url"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+
+	"blockpropeller.dev/blockpropeller/account"
+	"blockpropeller.dev/blockpropeller/database/transaction"
+	"blockpropeller.dev/blockpropeller/mail"
+	"blockpropeller.dev/lib/test"
+	"github.com/pkg/errors"
+)
+
+var linkPattern = regexp.MustCompile(`https?://\S+`)
+
+type credentialFixture struct {
+	accRepo   *account.InMemoryRepository
+	accSvc    *account.Service
+	apiKeySvc *account.APIKeyService
+	credSvc   *account.CredentialService
+	mailer    *mail.InMemoryMailer
+}
+
+func newCredentialFixture() *credentialFixture {
+	accRepo := account.NewInMemoryRepository()
+	sessionRepo := account.NewInMemorySessionRepository()
+	accSvc := account.NewService(
+		accRepo,
+		sessionRepo,
+		account.NewTokenService("secret", time.Minute, time.Hour),
+		account.NewTwoFactorService(accRepo, account.NewInMemoryRecoveryCodeRepository(), sessionRepo),
+	)
+	apiKeySvc := account.NewAPIKeyService(accRepo, account.NewInMemoryAPIKeyRepository())
+	mailer := mail.NewInMemoryMailer()
+
+	return &credentialFixture{
+		accRepo:   accRepo,
+		accSvc:    accSvc,
+		apiKeySvc: apiKeySvc,
+		credSvc: account.NewCredentialService(
+			transaction.NewInMemoryTransactionContext(),
+			accRepo,
+			account.NewInMemoryOneTimeTokenRepository(),
+			accSvc,
+			apiKeySvc,
+			mailer,
+			&mail.Config{BaseURL: "https://app.blockpropeller.dev/"},
+		),
+		mailer: mailer,
+	}
+}
+
+// lastSecret extracts the secret from the link within the last email sent to the recipient.
+func (f *credentialFixture) lastSecret(t *testing.T, to account.Email) account.OneTimeSecret {
+	messages := f.mailer.Messages(to.String())
+	if len(messages) == 0 {
+		t.Fatalf("no mail sent to %s", to)
+	}
+
+	link, err := url.Parse(linkPattern.FindString(messages[len(messages)-1].Body))
+	test.CheckErr(t, "parse mail link", err)
+	test.AssertStringsEqual(t, "mail link host", link.Host, "app.blockpropeller.dev")
+
+	return account.OneTimeSecret(link.Query().Get("token"))
+}
+
+func TestEmailVerification(t *testing.T) {
+	ctx := context.Background()
+	f := newCredentialFixture()
+
+	acc, _, err := f.accSvc.Register("user@example.com", "password")
+	test.CheckErr(t, "register account", err)
+	test.AssertBoolEqual(t, "email not verified", acc.IsEmailVerified(), false)
+
+	test.CheckErr(t, "send verification", f.credSvc.SendVerification(ctx, acc))
+	secret := f.lastSecret(t, acc.Email)
+
+	_, err = f.credSvc.VerifyEmail(ctx, "unknown")
+	test.AssertBoolEqual(t, "verify unknown secret", errors.Cause(err) == account.ErrOneTimeTokenNotFound, true)
+
+	verified, err := f.credSvc.VerifyEmail(ctx, secret)
+	test.CheckErr(t, "verify email", err)
+	test.AssertBoolEqual(t, "email verified", verified.IsEmailVerified(), true)
+
+	_, err = f.credSvc.VerifyEmail(ctx, secret)
+	test.AssertBoolEqual(t, "verify email twice", errors.Cause(err) == account.ErrOneTimeTokenNotFound, true)
+
+	err = f.credSvc.SendVerification(ctx, verified)
+	test.AssertBoolEqual(t, "send verification to verified email",
+		errors.Cause(err) == account.ErrEmailAlreadyVerified, true)
+}
+
+func TestEmailVerificationOfChangedEmail(t *testing.T) {
+	ctx := context.Background()
+	f := newCredentialFixture()
+
+	acc, _, err := f.accSvc.Register("user@example.com", "password")
+	test.CheckErr(t, "register account", err)
+
+	test.CheckErr(t, "send verification", f.credSvc.SendVerification(ctx, acc))
+	secret := f.lastSecret(t, acc.Email)
+
+	acc.Email = "changed@example.com"
+	test.CheckErr(t, "change email", f.accRepo.Update(ctx, acc))
+
+	_, err = f.credSvc.VerifyEmail(ctx, secret)
+	test.AssertBoolEqual(t, "verify previous email", errors.Cause(err) == account.ErrOneTimeTokenNotFound, true)
+}
+
+func TestPasswordReset(t *testing.T) {
+	ctx := context.Background()
+	f := newCredentialFixture()
+
+	acc, tokens, err := f.accSvc.Register("user@example.com", "password")
+	test.CheckErr(t, "register account", err)
+
+	_, apiKeyToken, err := f.apiKeySvc.Create(ctx, acc, "ci", account.Scopes{account.ScopeServersWrite}, nil)
+	test.CheckErr(t, "create api key", err)
+
+	err = f.credSvc.RequestPasswordReset(ctx, "unknown@example.com")
+	test.CheckErr(t, "request reset for unknown email", err)
+	test.AssertIntsEqual(t, "no mail sent for unknown email", len(f.mailer.Messages("unknown@example.com")), 0)
+
+	test.CheckErr(t, "request first reset", f.credSvc.RequestPasswordReset(ctx, acc.Email))
+	first := f.lastSecret(t, acc.Email)
+
+	test.CheckErr(t, "request second reset", f.credSvc.RequestPasswordReset(ctx, acc.Email))
+	second := f.lastSecret(t, acc.Email)
+
+	_, err = f.credSvc.ResetPassword(ctx, second, "short")
+	test.CheckErrExists(t, "reset to invalid password", err)
+
+	resetAcc, err := f.credSvc.ResetPassword(ctx, second, "new-password")
+	test.CheckErr(t, "reset password", err)
+	test.AssertStringsEqual(t, "reset account", resetAcc.ID.String(), acc.ID.String())
+
+	_, err = f.accSvc.Login(acc.Email, "password", "")
+	test.AssertBoolEqual(t, "login with old password", errors.Cause(err) == account.ErrInvalidCredentials, true)
+
+	_, err = f.accSvc.Login(acc.Email, "new-password", "")
+	test.CheckErr(t, "login with new password", err)
+
+	_, _, err = f.accSvc.Authenticate(tokens.AccessToken)
+	test.AssertBoolEqual(t, "sessions revoked on reset", errors.Cause(err) == account.ErrSessionRevoked, true)
+
+	_, _, err = f.apiKeySvc.Authenticate(ctx, apiKeyToken)
+	test.AssertBoolEqual(t, "api keys revoked on reset", errors.Cause(err) == account.ErrAPIKeyNotFound, true)
+
+	_, err = f.credSvc.ResetPassword(ctx, second, "another-password")
+	test.AssertBoolEqual(t, "reuse reset secret", errors.Cause(err) == account.ErrOneTimeTokenNotFound, true)
+
+	_, err = f.credSvc.ResetPassword(ctx, first, "another-password")
+	test.AssertBoolEqual(t, "use earlier reset secret", errors.Cause(err) == account.ErrOneTimeTokenNotFound, true)
+
+	reset, err := f.accRepo.FindByID(ctx, acc.ID)
+	test.CheckErr(t, "find account", err)
+	test.AssertBoolEqual(t, "email verified by reset", reset.IsEmailVerified(), true)
+}
+
+func TestConcurrentPasswordReset(t *testing.T) {
+	ctx := context.Background()
+	f := newCredentialFixture()
+
+	acc, _, err := f.accSvc.Register("user@example.com", "password")
+	test.CheckErr(t, "register account", err)
+
+	test.CheckErr(t, "request reset", f.credSvc.RequestPasswordReset(ctx, acc.Email))
+	secret := f.lastSecret(t, acc.Email)
+
+	const requests = 10
+
+	var wg sync.WaitGroup
+	errs := make(chan error, requests)
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			_, err := f.credSvc.ResetPassword(ctx, secret, "new-password")
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	var succeeded int
+	for err := range errs {
+		if err == nil {
+			succeeded++
+			continue
+		}
+		test.AssertBoolEqual(t, "concurrent reset rejected", errors.Cause(err) == account.ErrOneTimeTokenNotFound, true)
+	}
+	test.AssertIntsEqual(t, "successful resets", succeeded, 1)
+}
+
+func TestChangePassword(t *testing.T) {
+	f := newCredentialFixture()
+
+	acc, current, err := f.accSvc.Register("user@example.com", "password")
+	test.CheckErr(t, "register account", err)
+
+	other, err := f.accSvc.Login(acc.Email, "password", "")
+	test.CheckErr(t, "login", err)
+
+	err = f.accSvc.ChangePassword(acc, "wrong-password", "new-password")
+	test.AssertBoolEqual(t, "change with wrong password", errors.Cause(err) == account.ErrInvalidPassword, true)
+
+	err = f.accSvc.ChangePassword(acc, "password", "short")
+	test.CheckErrExists(t, "change to invalid password", err)
+
+	test.CheckErr(t, "change password", f.accSvc.ChangePassword(acc, "password", "new-password"))
+
+	_, session, err := f.accSvc.Authenticate(current.AccessToken)
+	test.CheckErr(t, "authenticate current session", err)
+	test.CheckErr(t, "logout other sessions", f.accSvc.LogoutOthers(acc, session))
+
+	_, _, err = f.accSvc.Authenticate(current.AccessToken)
+	test.CheckErr(t, "current session kept", err)
+
+	_, _, err = f.accSvc.Authenticate(other.AccessToken)
+	test.AssertBoolEqual(t, "other session revoked", errors.Cause(err) == account.ErrSessionRevoked, true)
+}
@@ -0,0 +1,146 @@
+package account
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+)
+
+// SSOAuthorizationTTL is the time a user has for authenticating with the identity provider.
+const SSOAuthorizationTTL = 10 * time.Minute
+
+// IdentityID is a unique identity identifier.
+type IdentityID string
+
+// NewIdentityID returns a new unique IdentityID.
+func NewIdentityID() IdentityID {
+	return IdentityID(uuid.NewV4().String())
+}
+
+// String satisfies the Stringer interface.
+func (id IdentityID) String() string {
+	return string(id)
+}
+
+// Identity links an Account to a user of an external identity provider,
+// letting the Account log in through single sign-on.
+//
+// Identities are matched by the issuer and subject of the identity provider,
+// since the email of the user might change.
+type Identity struct {
+	ID        IdentityID `json:"id" gorm:"type:varchar(36) not null"`
+	AccountID ID         `json:"account_id" gorm:"type:varchar(36) not null references accounts(id)"`
+
+	Issuer  string `json:"issuer" gorm:"type:varchar(255) not null;unique_index:idx_identity_subject"`
+	Subject string `json:"subject" gorm:"type:varchar(255) not null;unique_index:idx_identity_subject"`
+	Email   Email  `json:"email" gorm:"type:varchar(255) not null"`
+
+	CreatedAt   time.Time `json:"created_at" gorm:"type:timestamp not null;default:CURRENT_TIMESTAMP"`
+	LastLoginAt time.Time `json:"last_login_at" gorm:"type:timestamp not null;default:CURRENT_TIMESTAMP"`
+}
+
+// NewIdentity returns a new Identity instance.
+func NewIdentity(accountID ID, issuer, subject string, email Email) *Identity {
+	return &Identity{
+		ID:        NewIdentityID(),
+		AccountID: accountID,
+
+		Issuer:  issuer,
+		Subject: subject,
+		Email:   email,
+
+		CreatedAt:   time.Now(),
+		LastLoginAt: time.Now(),
+	}
+}
+
+// SSOAuthorizationID is a unique single sign-on authorization identifier.
+type SSOAuthorizationID string
+
+// NewSSOAuthorizationID returns a new unique SSOAuthorizationID.
+func NewSSOAuthorizationID() SSOAuthorizationID {
+	return SSOAuthorizationID(uuid.NewV4().String())
+}
+
+// String satisfies the Stringer interface.
+func (id SSOAuthorizationID) String() string {
+	return string(id)
+}
+
+// SSOState is the opaque value which ties the redirect from the identity provider
+// to the SSOAuthorization it was started with.
+//
+// Only the hash of the state is stored.
+type SSOState string
+
+// NewSSOState returns a new random SSOState.
+func NewSSOState() (SSOState, error) {
+	raw := make([]byte, 32)
+
+	_, err := rand.Read(raw)
+	if err != nil {
+		return "", errors.Wrap(err, "generate sso state")
+	}
+
+	return SSOState(hex.EncodeToString(raw)), nil
+}
+
+// Hash returns the hash of the SSOState under which the SSOAuthorization is stored.
+func (s SSOState) Hash() string {
+	hash := sha256.Sum256([]byte(s))
+
+	return hex.EncodeToString(hash[:])
+}
+
+// String satisfies the Stringer interface.
+func (s SSOState) String() string {
+	return string(s)
+}
+
+// SSOAuthorization is a single sign-on login in progress, holding the PKCE code verifier
+// and the nonce the authorization was started with.
+type SSOAuthorization struct {
+	ID SSOAuthorizationID `json:"id" gorm:"type:varchar(36) not null"`
+
+	StateHash    string `json:"-" gorm:"type:varchar(64) not null;unique_index"`
+	Nonce        string `json:"-" gorm:"type:varchar(255) not null"`
+	CodeVerifier string `json:"-" gorm:"type:varchar(255) not null"`
+
+	CreatedAt time.Time  `json:"created_at" gorm:"type:timestamp not null;default:CURRENT_TIMESTAMP"`
+	ExpiresAt time.Time  `json:"expires_at" gorm:"type:timestamp not null"`
+	UsedAt    *time.Time `json:"used_at,omitempty" gorm:"type:timestamp"`
+}
+
+// NewSSOAuthorization returns a new SSOAuthorization instance, along with the state identifying it.
+func NewSSOAuthorization(nonce, codeVerifier string) (*SSOAuthorization, SSOState, error) {
+	state, err := NewSSOState()
+	if err != nil {
+		return nil, "", err
+	}
+
+	return &SSOAuthorization{
+		ID: NewSSOAuthorizationID(),
+
+		StateHash:    state.Hash(),
+		Nonce:        nonce,
+		CodeVerifier: codeVerifier,
+
+		CreatedAt: time.Now(),
+		ExpiresAt: time.Now().Add(SSOAuthorizationTTL),
+	}, state, nil
+}
+
+// IsUsable checks whether the SSOAuthorization was neither used, nor has it expired.
+func (auth *SSOAuthorization) IsUsable() bool {
+	return auth.UsedAt == nil && time.Now().Before(auth.ExpiresAt)
+}
+
+// Use marks the SSOAuthorization as used.
+func (auth *SSOAuthorization) Use() {
+	now := time.Now()
+	auth.UsedAt = &now
+}
@@ -0,0 +1,146 @@
+package account
+
+import (
+	"context"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrIdentityNotFound is returned when an IdentityRepository does not find an identity to return.
+	ErrIdentityNotFound = errors.New("identity not found")
+	// ErrSSOAuthorizationNotFound is returned when an SSOAuthorizationRepository does not find an authorization to return.
+	ErrSSOAuthorizationNotFound = errors.New("sso authorization not found")
+)
+
+// IdentityRepository defines an interface for storing and retrieving identities.
+type IdentityRepository interface {
+	// FindBySubject returns the Identity of the subject at the issuer.
+	FindBySubject(ctx context.Context, issuer, subject string) (*Identity, error)
+	// ListByAccount lists all the identities linked to an Account.
+	ListByAccount(ctx context.Context, accountID ID) ([]*Identity, error)
+
+	// Create a new Identity.
+	Create(ctx context.Context, identity *Identity) error
+	// Update an existing Identity.
+	Update(ctx context.Context, identity *Identity) error
+}
+
+// SSOAuthorizationRepository defines an interface for storing and retrieving single sign-on authorizations.
+type SSOAuthorizationRepository interface {
+	// FindByState returns the SSOAuthorization started with the provided state.
+	FindByState(ctx context.Context, state SSOState) (*SSOAuthorization, error)
+
+	// Create a new SSOAuthorization.
+	Create(ctx context.Context, auth *SSOAuthorization) error
+	// Update an existing SSOAuthorization.
+	Update(ctx context.Context, auth *SSOAuthorization) error
+}
+
+// InMemoryIdentityRepository holds the identities inside an in-memory map.
+//
+// Identities are not persisted on disk and won't survive program restarts.
+type InMemoryIdentityRepository struct {
+	identities sync.Map
+}
+
+// NewInMemoryIdentityRepository returns a new InMemoryIdentityRepository instance.
+func NewInMemoryIdentityRepository() *InMemoryIdentityRepository {
+	return &InMemoryIdentityRepository{}
+}
+
+// FindBySubject returns the Identity of the subject at the issuer.
+func (repo *InMemoryIdentityRepository) FindBySubject(ctx context.Context, issuer, subject string) (*Identity, error) {
+	var found *Identity
+	repo.identities.Range(func(k, v interface{}) bool {
+		identity := v.(*Identity)
+		if identity.Issuer != issuer || identity.Subject != subject {
+			return true
+		}
+
+		found = identity
+
+		return false
+	})
+	if found == nil {
+		return nil, ErrIdentityNotFound
+	}
+
+	return found, nil
+}
+
+// ListByAccount lists all the identities linked to an Account.
+func (repo *InMemoryIdentityRepository) ListByAccount(ctx context.Context, accountID ID) ([]*Identity, error) {
+	var identities []*Identity
+	repo.identities.Range(func(k, v interface{}) bool {
+		identity := v.(*Identity)
+		if identity.AccountID == accountID {
+			identities = append(identities, identity)
+		}
+
+		return true
+	})
+
+	return identities, nil
+}
+
+// Create a new Identity.
+func (repo *InMemoryIdentityRepository) Create(ctx context.Context, identity *Identity) error {
+	repo.identities.Store(identity.ID, identity)
+
+	return nil
+}
+
+// Update an existing Identity.
+func (repo *InMemoryIdentityRepository) Update(ctx context.Context, identity *Identity) error {
+	repo.identities.Store(identity.ID, identity)
+
+	return nil
+}
+
+// InMemorySSOAuthorizationRepository holds the single sign-on authorizations inside an in-memory map.
+//
+// Authorizations are not persisted on disk and won't survive program restarts.
+type InMemorySSOAuthorizationRepository struct {
+	authorizations sync.Map
+}
+
+// NewInMemorySSOAuthorizationRepository returns a new InMemorySSOAuthorizationRepository instance.
+func NewInMemorySSOAuthorizationRepository() *InMemorySSOAuthorizationRepository {
+	return &InMemorySSOAuthorizationRepository{}
+}
+
+// FindByState returns the SSOAuthorization started with the provided state.
+func (repo *InMemorySSOAuthorizationRepository) FindByState(ctx context.Context, state SSOState) (*SSOAuthorization, error) {
+	var found *SSOAuthorization
+	repo.authorizations.Range(func(k, v interface{}) bool {
+		auth := v.(*SSOAuthorization)
+		if auth.StateHash != state.Hash() {
+			return true
+		}
+
+		found = auth
+
+		return false
+	})
+	if found == nil {
+		return nil, ErrSSOAuthorizationNotFound
+	}
+
+	return found, nil
+}
+
+// Create a new SSOAuthorization.
+func (repo *InMemorySSOAuthorizationRepository) Create(ctx context.Context, auth *SSOAuthorization) error {
+	repo.authorizations.Store(auth.ID, auth)
+
+	return nil
+}
+
+// Update an existing SSOAuthorization.
+func (repo *InMemorySSOAuthorizationRepository) Update(ctx context.Context, auth *SSOAuthorization) error {
+	repo.authorizations.Store(auth.ID, auth)
+
+	return nil
+}
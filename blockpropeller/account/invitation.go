@@ -0,0 +1,109 @@
+package account
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+)
+
+// InvitationTTL is the time an Invitation can be accepted for after it was sent.
+const InvitationTTL = 7 * 24 * time.Hour
+
+// NilInvitationID is an empty InvitationID.
+var NilInvitationID InvitationID
+
+// InvitationID is a unique invitation identifier.
+type InvitationID string
+
+// NewInvitationID returns a new unique InvitationID.
+func NewInvitationID() InvitationID {
+	return InvitationID(uuid.NewV4().String())
+}
+
+// String satisfies the Stringer interface.
+func (id InvitationID) String() string {
+	return string(id)
+}
+
+// InvitationToken is the secret handed to an invitee for accepting an Invitation.
+//
+// Only the hash of the token is stored, so the token is available only once the Invitation is created.
+type InvitationToken string
+
+// NewInvitationToken returns a new random InvitationToken.
+func NewInvitationToken() (InvitationToken, error) {
+	raw := make([]byte, 32)
+
+	_, err := rand.Read(raw)
+	if err != nil {
+		return "", errors.Wrap(err, "generate invitation token")
+	}
+
+	return InvitationToken(hex.EncodeToString(raw)), nil
+}
+
+// Hash returns the hash of the InvitationToken under which the Invitation is stored.
+func (t InvitationToken) Hash() string {
+	hash := sha256.Sum256([]byte(t))
+
+	return hex.EncodeToString(hash[:])
+}
+
+// String satisfies the Stringer interface.
+func (t InvitationToken) String() string {
+	return string(t)
+}
+
+// Invitation invites the owner of an Email to join an Organization with the provided Role.
+type Invitation struct {
+	ID             InvitationID   `json:"id" gorm:"type:varchar(36) not null"`
+	OrganizationID OrganizationID `json:"organization_id" gorm:"type:varchar(36) not null references organizations(id)"`
+	// InvitedByID is the ID of the member that sent the Invitation.
+	InvitedByID ID `json:"invited_by_id" gorm:"type:varchar(36) not null references accounts(id)"`
+
+	Email Email `json:"email" gorm:"type:varchar(255) not null"`
+	Role  Role  `json:"role" gorm:"type:varchar(20) not null"`
+
+	TokenHash string `json:"-" gorm:"type:varchar(64) not null;unique_index"`
+
+	CreatedAt  time.Time  `json:"created_at" gorm:"type:timestamp not null;default:CURRENT_TIMESTAMP"`
+	ExpiresAt  time.Time  `json:"expires_at" gorm:"type:timestamp not null"`
+	AcceptedAt *time.Time `json:"accepted_at,omitempty" gorm:"type:timestamp"`
+}
+
+// NewInvitation returns a new Invitation instance, along with the token for accepting it.
+func NewInvitation(orgID OrganizationID, invitedBy ID, email Email, role Role) (*Invitation, InvitationToken, error) {
+	token, err := NewInvitationToken()
+	if err != nil {
+		return nil, "", err
+	}
+
+	return &Invitation{
+		ID:             NewInvitationID(),
+		OrganizationID: orgID,
+		InvitedByID:    invitedBy,
+
+		Email: email,
+		Role:  role,
+
+		TokenHash: token.Hash(),
+
+		CreatedAt: time.Now(),
+		ExpiresAt: time.Now().Add(InvitationTTL),
+	}, token, nil
+}
+
+// IsPending checks whether the Invitation can still be accepted.
+func (inv *Invitation) IsPending() bool {
+	return inv.AcceptedAt == nil && time.Now().Before(inv.ExpiresAt)
+}
+
+// Accept marks the Invitation as accepted.
+func (inv *Invitation) Accept() {
+	now := time.Now()
+	inv.AcceptedAt = &now
+}
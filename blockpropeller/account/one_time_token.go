@@ -0,0 +1,128 @@
+package account
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+)
+
+// TokenPurpose is the action a OneTimeToken can be used for.
+type TokenPurpose string
+
+// Purposes of one-time tokens.
+const (
+	// PurposeEmailVerification tokens prove the ownership of an Email.
+	PurposeEmailVerification TokenPurpose = "email_verification"
+	// PurposePasswordReset tokens allow setting a new password without knowing the current one.
+	PurposePasswordReset TokenPurpose = "password_reset"
+)
+
+// TTL returns the time a OneTimeToken with the TokenPurpose can be used for after it was issued.
+func (p TokenPurpose) TTL() time.Duration {
+	switch p {
+	case PurposePasswordReset:
+		return time.Hour
+	default:
+		return 48 * time.Hour
+	}
+}
+
+// String satisfies the Stringer interface.
+func (p TokenPurpose) String() string {
+	return string(p)
+}
+
+// OneTimeSecret is the secret sent to an Account for using a OneTimeToken.
+//
+// Only the hash of the secret is stored, so the secret is available only once the OneTimeToken is issued.
+type OneTimeSecret string
+
+// NewOneTimeSecret returns a new random OneTimeSecret.
+func NewOneTimeSecret() (OneTimeSecret, error) {
+	raw := make([]byte, 32)
+
+	_, err := rand.Read(raw)
+	if err != nil {
+		return "", errors.Wrap(err, "generate one-time secret")
+	}
+
+	return OneTimeSecret(hex.EncodeToString(raw)), nil
+}
+
+// Hash returns the hash of the OneTimeSecret under which the OneTimeToken is stored.
+func (s OneTimeSecret) Hash() string {
+	hash := sha256.Sum256([]byte(s))
+
+	return hex.EncodeToString(hash[:])
+}
+
+// String satisfies the Stringer interface.
+func (s OneTimeSecret) String() string {
+	return string(s)
+}
+
+// OneTimeTokenID is a unique one-time token identifier.
+type OneTimeTokenID string
+
+// NewOneTimeTokenID returns a new unique OneTimeTokenID.
+func NewOneTimeTokenID() OneTimeTokenID {
+	return OneTimeTokenID(uuid.NewV4().String())
+}
+
+// String satisfies the Stringer interface.
+func (id OneTimeTokenID) String() string {
+	return string(id)
+}
+
+// OneTimeToken lets an Account take a sensitive action, such as verifying its Email
+// or resetting its password, once and within a limited time.
+type OneTimeToken struct {
+	ID        OneTimeTokenID `json:"id" gorm:"type:varchar(36) not null"`
+	AccountID ID             `json:"account_id" gorm:"type:varchar(36) not null references accounts(id)"`
+
+	Purpose TokenPurpose `json:"purpose" gorm:"type:varchar(32) not null"`
+	// Email the token was sent to, which must still match the Account Email when the token is used.
+	Email Email `json:"email" gorm:"type:varchar(255) not null"`
+
+	SecretHash string `json:"-" gorm:"type:varchar(64) not null;unique_index"`
+
+	CreatedAt time.Time  `json:"created_at" gorm:"type:timestamp not null;default:CURRENT_TIMESTAMP"`
+	ExpiresAt time.Time  `json:"expires_at" gorm:"type:timestamp not null"`
+	UsedAt    *time.Time `json:"used_at,omitempty" gorm:"type:timestamp"`
+}
+
+// NewOneTimeToken returns a new OneTimeToken instance for the Account, along with the secret for using it.
+func NewOneTimeToken(acc *Account, purpose TokenPurpose) (*OneTimeToken, OneTimeSecret, error) {
+	secret, err := NewOneTimeSecret()
+	if err != nil {
+		return nil, "", err
+	}
+
+	return &OneTimeToken{
+		ID:        NewOneTimeTokenID(),
+		AccountID: acc.ID,
+
+		Purpose: purpose,
+		Email:   acc.Email,
+
+		SecretHash: secret.Hash(),
+
+		CreatedAt: time.Now(),
+		ExpiresAt: time.Now().Add(purpose.TTL()),
+	}, secret, nil
+}
+
+// IsUsable checks whether the OneTimeToken was not used yet and has not expired.
+func (t *OneTimeToken) IsUsable() bool {
+	return t.UsedAt == nil && time.Now().Before(t.ExpiresAt)
+}
+
+// Use marks the OneTimeToken as used.
+func (t *OneTimeToken) Use() {
+	now := time.Now()
+	t.UsedAt = &now
+}
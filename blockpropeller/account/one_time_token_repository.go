@@ -0,0 +1,114 @@
+package account
+
+import (
+	"context"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// ErrOneTimeTokenNotFound is returned when a OneTimeTokenRepository does not find a token to return.
+var ErrOneTimeTokenNotFound = errors.New("one-time token not found")
+
+// OneTimeTokenRepository defines an interface for storing and retrieving one-time tokens.
+type OneTimeTokenRepository interface {
+	// FindBySecret returns the OneTimeToken with the given purpose that can be used with the provided secret.
+	FindBySecret(ctx context.Context, purpose TokenPurpose, secret OneTimeSecret) (*OneTimeToken, error)
+	// ListUsable lists the tokens of an Account with the given purpose that can still be used.
+	ListUsable(ctx context.Context, accountID ID, purpose TokenPurpose) ([]*OneTimeToken, error)
+
+	// Create a new OneTimeToken.
+	Create(ctx context.Context, token *OneTimeToken) error
+	// Update an existing OneTimeToken.
+	Update(ctx context.Context, token *OneTimeToken) error
+	// Use marks an existing OneTimeToken as used, failing with ErrOneTimeTokenNotFound if it already was,
+	// so a token can only be used once even by concurrent requests.
+	Use(ctx context.Context, token *OneTimeToken) error
+}
+
+// InMemoryOneTimeTokenRepository holds the one-time tokens inside an in-memory map.
+//
+// Tokens are not persisted on disk and won't survive program restarts.
+type InMemoryOneTimeTokenRepository struct {
+	tokens sync.Map
+
+	mu sync.Mutex
+}
+
+// NewInMemoryOneTimeTokenRepository returns a new InMemoryOneTimeTokenRepository instance.
+func NewInMemoryOneTimeTokenRepository() *InMemoryOneTimeTokenRepository {
+	return &InMemoryOneTimeTokenRepository{}
+}
+
+// FindBySecret returns the OneTimeToken with the given purpose that can be used with the provided secret.
+func (repo *InMemoryOneTimeTokenRepository) FindBySecret(
+	ctx context.Context,
+	purpose TokenPurpose,
+	secret OneTimeSecret,
+) (*OneTimeToken, error) {
+	var found *OneTimeToken
+	repo.tokens.Range(func(k, v interface{}) bool {
+		token := v.(*OneTimeToken)
+		if token.Purpose != purpose || token.SecretHash != secret.Hash() {
+			return true
+		}
+
+		found = token
+
+		return false
+	})
+	if found == nil {
+		return nil, ErrOneTimeTokenNotFound
+	}
+
+	return found, nil
+}
+
+// ListUsable lists the tokens of an Account with the given purpose that can still be used.
+func (repo *InMemoryOneTimeTokenRepository) ListUsable(
+	ctx context.Context,
+	accountID ID,
+	purpose TokenPurpose,
+) ([]*OneTimeToken, error) {
+	var tokens []*OneTimeToken
+	repo.tokens.Range(func(k, v interface{}) bool {
+		token := v.(*OneTimeToken)
+		if token.AccountID == accountID && token.Purpose == purpose && token.IsUsable() {
+			tokens = append(tokens, token)
+		}
+
+		return true
+	})
+
+	return tokens, nil
+}
+
+// Create a new OneTimeToken.
+func (repo *InMemoryOneTimeTokenRepository) Create(ctx context.Context, token *OneTimeToken) error {
+	repo.tokens.Store(token.ID, token)
+
+	return nil
+}
+
+// Update an existing OneTimeToken.
+func (repo *InMemoryOneTimeTokenRepository) Update(ctx context.Context, token *OneTimeToken) error {
+	repo.tokens.Store(token.ID, token)
+
+	return nil
+}
+
+// Use marks an existing OneTimeToken as used, failing with ErrOneTimeTokenNotFound if it already was.
+func (repo *InMemoryOneTimeTokenRepository) Use(ctx context.Context, token *OneTimeToken) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	stored, ok := repo.tokens.Load(token.ID)
+	if !ok || stored.(*OneTimeToken).UsedAt != nil {
+		return ErrOneTimeTokenNotFound
+	}
+
+	token.Use()
+	repo.tokens.Store(token.ID, token)
+
+	return nil
+}
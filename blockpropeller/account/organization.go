@@ -0,0 +1,129 @@
+package account
+
+import (
+	"database/sql/driver"
+	"time"
+
+	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+)
+
+// NilOrganizationID is an empty OrganizationID.
+var NilOrganizationID OrganizationID
+
+// OrganizationID is a unique organization identifier.
+type OrganizationID string
+
+// NewOrganizationID returns a new unique OrganizationID.
+func NewOrganizationID() OrganizationID {
+	return OrganizationID(uuid.NewV4().String())
+}
+
+// String satisfies the Stringer interface.
+func (id OrganizationID) String() string {
+	return string(id)
+}
+
+// Value implements the sql.Valuer interface.
+//
+// A NilOrganizationID is stored as NULL, so resources created before organizations
+// do not violate the foreign key constraint until they are migrated.
+func (id OrganizationID) Value() (driver.Value, error) {
+	if id == NilOrganizationID {
+		return nil, nil
+	}
+
+	return string(id), nil
+}
+
+// Scan implements the sql.Scanner interface.
+func (id *OrganizationID) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*id = NilOrganizationID
+	case string:
+		*id = OrganizationID(v)
+	case []byte:
+		*id = OrganizationID(v)
+	default:
+		return errors.New("invalid organization id type")
+	}
+
+	return nil
+}
+
+// Organization owns infrastructure shared between its members.
+//
+// Every Account has a personal Organization, which holds the infrastructure
+// created without selecting another Organization.
+type Organization struct {
+	ID OrganizationID `json:"id" gorm:"type:varchar(36) not null"`
+	// AccountID of the Account that created the Organization.
+	AccountID ID `json:"-" gorm:"type:varchar(36) not null references accounts(id)"`
+
+	Name     string `json:"name" gorm:"type:varchar(255) not null"`
+	Personal bool   `json:"personal" gorm:"type:boolean not null;default:false"`
+
+	CreatedAt time.Time  `json:"created_at" gorm:"type:timestamp not null;default:CURRENT_TIMESTAMP"`
+	UpdatedAt time.Time  `json:"updated_at" gorm:"type:timestamp not null;default:CURRENT_TIMESTAMP"`
+	DeletedAt *time.Time `json:"-" gorm:"type:timestamp"`
+}
+
+// NewOrganization returns a new Organization instance created by the provided Account.
+func NewOrganization(accountID ID, name string) *Organization {
+	return &Organization{
+		ID:        NewOrganizationID(),
+		AccountID: accountID,
+
+		Name: name,
+
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+}
+
+// NewPersonalOrganization returns the Organization every Account starts with.
+func NewPersonalOrganization(acc *Account) *Organization {
+	org := NewOrganization(acc.ID, acc.Email.String())
+	org.Personal = true
+
+	return org
+}
+
+// Validate checks that the Organization is well formed.
+func (org *Organization) Validate() error {
+	if org.Name == "" || len(org.Name) > 255 {
+		return errors.Errorf("invalid organization name: %q", org.Name)
+	}
+
+	return nil
+}
+
+// Membership grants an Account a Role within an Organization.
+type Membership struct {
+	OrganizationID OrganizationID `json:"organization_id" gorm:"type:varchar(36) not null references organizations(id);primary_key"`
+	AccountID      ID             `json:"account_id" gorm:"type:varchar(36) not null references accounts(id);primary_key"`
+
+	Role Role `json:"role" gorm:"type:varchar(20) not null"`
+
+	CreatedAt time.Time `json:"created_at" gorm:"type:timestamp not null;default:CURRENT_TIMESTAMP"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"type:timestamp not null;default:CURRENT_TIMESTAMP"`
+}
+
+// NewMembership returns a new Membership instance.
+func NewMembership(orgID OrganizationID, accountID ID, role Role) *Membership {
+	return &Membership{
+		OrganizationID: orgID,
+		AccountID:      accountID,
+
+		Role: role,
+
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+}
+
+// Can checks whether the member holds the provided Permission.
+func (m *Membership) Can(perm Permission) bool {
+	return m.Role.Can(perm)
+}
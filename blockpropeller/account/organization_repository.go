@@ -0,0 +1,273 @@
+package account
+
+import (
+	"context"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrOrganizationNotFound is returned when an OrganizationRepository does not find an organization to return.
+	ErrOrganizationNotFound = errors.New("organization not found")
+	// ErrMembershipNotFound is returned when a MembershipRepository does not find a membership to return.
+	ErrMembershipNotFound = errors.New("membership not found")
+	// ErrInvitationNotFound is returned when an InvitationRepository does not find an invitation to return.
+	ErrInvitationNotFound = errors.New("invitation not found")
+)
+
+// OrganizationRepository defines an interface for storing and retrieving organizations.
+type OrganizationRepository interface {
+	// Find an Organization given an OrganizationID.
+	Find(ctx context.Context, id OrganizationID) (*Organization, error)
+	// FindPersonal returns the personal Organization of an Account.
+	FindPersonal(ctx context.Context, accountID ID) (*Organization, error)
+
+	// Create a new Organization.
+	Create(ctx context.Context, org *Organization) error
+	// Update an existing Organization.
+	Update(ctx context.Context, org *Organization) error
+}
+
+// MembershipRepository defines an interface for storing and retrieving organization memberships.
+type MembershipRepository interface {
+	// Find the Membership of an Account within an Organization.
+	Find(ctx context.Context, orgID OrganizationID, accountID ID) (*Membership, error)
+	// ListByOrganization lists all the members of an Organization.
+	ListByOrganization(ctx context.Context, orgID OrganizationID) ([]*Membership, error)
+	// ListByAccount lists all the memberships of an Account.
+	ListByAccount(ctx context.Context, accountID ID) ([]*Membership, error)
+
+	// Create a new Membership.
+	Create(ctx context.Context, membership *Membership) error
+	// Update an existing Membership.
+	Update(ctx context.Context, membership *Membership) error
+	// Delete an existing Membership.
+	Delete(ctx context.Context, membership *Membership) error
+}
+
+// InvitationRepository defines an interface for storing and retrieving organization invitations.
+type InvitationRepository interface {
+	// Find an Invitation given an InvitationID.
+	Find(ctx context.Context, id InvitationID) (*Invitation, error)
+	// FindByToken returns the Invitation that can be accepted with the provided token.
+	FindByToken(ctx context.Context, token InvitationToken) (*Invitation, error)
+	// ListByOrganization lists all the invitations to an Organization.
+	ListByOrganization(ctx context.Context, orgID OrganizationID) ([]*Invitation, error)
+
+	// Create a new Invitation.
+	Create(ctx context.Context, inv *Invitation) error
+	// Update an existing Invitation.
+	Update(ctx context.Context, inv *Invitation) error
+	// Delete an existing Invitation.
+	Delete(ctx context.Context, inv *Invitation) error
+}
+
+// InMemoryOrganizationRepository holds the organizations inside an in-memory map.
+//
+// Organizations are not persisted on disk and won't survive program restarts.
+type InMemoryOrganizationRepository struct {
+	organizations sync.Map
+}
+
+// NewInMemoryOrganizationRepository returns a new InMemoryOrganizationRepository instance.
+func NewInMemoryOrganizationRepository() *InMemoryOrganizationRepository {
+	return &InMemoryOrganizationRepository{}
+}
+
+// Find an Organization given an OrganizationID.
+func (repo *InMemoryOrganizationRepository) Find(ctx context.Context, id OrganizationID) (*Organization, error) {
+	org, ok := repo.organizations.Load(id)
+	if !ok {
+		return nil, ErrOrganizationNotFound
+	}
+
+	return org.(*Organization), nil
+}
+
+// FindPersonal returns the personal Organization of an Account.
+func (repo *InMemoryOrganizationRepository) FindPersonal(ctx context.Context, accountID ID) (*Organization, error) {
+	var personal *Organization
+	repo.organizations.Range(func(k, v interface{}) bool {
+		org := v.(*Organization)
+		if !org.Personal || org.AccountID != accountID {
+			return true
+		}
+
+		personal = org
+
+		return false
+	})
+	if personal == nil {
+		return nil, ErrOrganizationNotFound
+	}
+
+	return personal, nil
+}
+
+// Create a new Organization.
+func (repo *InMemoryOrganizationRepository) Create(ctx context.Context, org *Organization) error {
+	repo.organizations.Store(org.ID, org)
+
+	return nil
+}
+
+// Update an existing Organization.
+func (repo *InMemoryOrganizationRepository) Update(ctx context.Context, org *Organization) error {
+	repo.organizations.Store(org.ID, org)
+
+	return nil
+}
+
+// InMemoryMembershipRepository holds the memberships inside an in-memory map.
+//
+// Memberships are not persisted on disk and won't survive program restarts.
+type InMemoryMembershipRepository struct {
+	memberships sync.Map
+}
+
+// NewInMemoryMembershipRepository returns a new InMemoryMembershipRepository instance.
+func NewInMemoryMembershipRepository() *InMemoryMembershipRepository {
+	return &InMemoryMembershipRepository{}
+}
+
+// Find the Membership of an Account within an Organization.
+func (repo *InMemoryMembershipRepository) Find(ctx context.Context, orgID OrganizationID, accountID ID) (*Membership, error) {
+	membership, ok := repo.memberships.Load(membershipKey(orgID, accountID))
+	if !ok {
+		return nil, ErrMembershipNotFound
+	}
+
+	return membership.(*Membership), nil
+}
+
+// ListByOrganization lists all the members of an Organization.
+func (repo *InMemoryMembershipRepository) ListByOrganization(ctx context.Context, orgID OrganizationID) ([]*Membership, error) {
+	return repo.filter(func(membership *Membership) bool {
+		return membership.OrganizationID == orgID
+	}), nil
+}
+
+// ListByAccount lists all the memberships of an Account.
+func (repo *InMemoryMembershipRepository) ListByAccount(ctx context.Context, accountID ID) ([]*Membership, error) {
+	return repo.filter(func(membership *Membership) bool {
+		return membership.AccountID == accountID
+	}), nil
+}
+
+// Create a new Membership.
+func (repo *InMemoryMembershipRepository) Create(ctx context.Context, membership *Membership) error {
+	repo.memberships.Store(membershipKey(membership.OrganizationID, membership.AccountID), membership)
+
+	return nil
+}
+
+// Update an existing Membership.
+func (repo *InMemoryMembershipRepository) Update(ctx context.Context, membership *Membership) error {
+	repo.memberships.Store(membershipKey(membership.OrganizationID, membership.AccountID), membership)
+
+	return nil
+}
+
+// Delete an existing Membership.
+func (repo *InMemoryMembershipRepository) Delete(ctx context.Context, membership *Membership) error {
+	repo.memberships.Delete(membershipKey(membership.OrganizationID, membership.AccountID))
+
+	return nil
+}
+
+func (repo *InMemoryMembershipRepository) filter(match func(membership *Membership) bool) []*Membership {
+	var memberships []*Membership
+	repo.memberships.Range(func(k, v interface{}) bool {
+		membership := v.(*Membership)
+		if match(membership) {
+			memberships = append(memberships, membership)
+		}
+
+		return true
+	})
+
+	return memberships
+}
+
+func membershipKey(orgID OrganizationID, accountID ID) string {
+	return orgID.String() + "/" + accountID.String()
+}
+
+// InMemoryInvitationRepository holds the invitations inside an in-memory map.
+//
+// Invitations are not persisted on disk and won't survive program restarts.
+type InMemoryInvitationRepository struct {
+	invitations sync.Map
+}
+
+// NewInMemoryInvitationRepository returns a new InMemoryInvitationRepository instance.
+func NewInMemoryInvitationRepository() *InMemoryInvitationRepository {
+	return &InMemoryInvitationRepository{}
+}
+
+// Find an Invitation given an InvitationID.
+func (repo *InMemoryInvitationRepository) Find(ctx context.Context, id InvitationID) (*Invitation, error) {
+	inv, ok := repo.invitations.Load(id)
+	if !ok {
+		return nil, ErrInvitationNotFound
+	}
+
+	return inv.(*Invitation), nil
+}
+
+// FindByToken returns the Invitation that can be accepted with the provided token.
+func (repo *InMemoryInvitationRepository) FindByToken(ctx context.Context, token InvitationToken) (*Invitation, error) {
+	var found *Invitation
+	repo.invitations.Range(func(k, v interface{}) bool {
+		inv := v.(*Invitation)
+		if inv.TokenHash != token.Hash() {
+			return true
+		}
+
+		found = inv
+
+		return false
+	})
+	if found == nil {
+		return nil, ErrInvitationNotFound
+	}
+
+	return found, nil
+}
+
+// ListByOrganization lists all the invitations to an Organization.
+func (repo *InMemoryInvitationRepository) ListByOrganization(ctx context.Context, orgID OrganizationID) ([]*Invitation, error) {
+	var invitations []*Invitation
+	repo.invitations.Range(func(k, v interface{}) bool {
+		inv := v.(*Invitation)
+		if inv.OrganizationID == orgID {
+			invitations = append(invitations, inv)
+		}
+
+		return true
+	})
+
+	return invitations, nil
+}
+
+// Create a new Invitation.
+func (repo *InMemoryInvitationRepository) Create(ctx context.Context, inv *Invitation) error {
+	repo.invitations.Store(inv.ID, inv)
+
+	return nil
+}
+
+// Update an existing Invitation.
+func (repo *InMemoryInvitationRepository) Update(ctx context.Context, inv *Invitation) error {
+	repo.invitations.Store(inv.ID, inv)
+
+	return nil
+}
+
+// Delete an existing Invitation.
+func (repo *InMemoryInvitationRepository) Delete(ctx context.Context, inv *Invitation) error {
+	repo.invitations.Delete(inv.ID)
+
+	return nil
+}
@@ -0,0 +1,243 @@
+package account
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrPermissionDenied is returned when an Account is not allowed to take an action within an Organization.
+	ErrPermissionDenied = errors.New("permission denied")
+	// ErrAlreadyMember is returned when an Account is added to an Organization it is already a member of.
+	ErrAlreadyMember = errors.New("account is already a member")
+	// ErrLastOwner is returned when the last owner of an Organization would be removed or demoted.
+	ErrLastOwner = errors.New("organization must keep at least one owner")
+	// ErrInvalidRole is returned when a Role is not one of the ValidRoles.
+	ErrInvalidRole = errors.New("invalid role")
+)
+
+// OrganizationService is responsible for managing Organizations and their members.
+type OrganizationService struct {
+	orgRepo    OrganizationRepository
+	memberRepo MembershipRepository
+	inviteRepo InvitationRepository
+}
+
+// NewOrganizationService returns a new OrganizationService instance.
+func NewOrganizationService(
+	orgRepo OrganizationRepository,
+	memberRepo MembershipRepository,
+	inviteRepo InvitationRepository,
+) *OrganizationService {
+	return &OrganizationService{orgRepo: orgRepo, memberRepo: memberRepo, inviteRepo: inviteRepo}
+}
+
+// Personal returns the personal Organization of an Account, creating it for Accounts that have none yet.
+func (s *OrganizationService) Personal(ctx context.Context, acc *Account) (*Organization, error) {
+	org, err := s.orgRepo.FindPersonal(ctx, acc.ID)
+	if err == nil {
+		return org, nil
+	}
+	if errors.Cause(err) != ErrOrganizationNotFound {
+		return nil, errors.Wrap(err, "find personal organization")
+	}
+
+	org = NewPersonalOrganization(acc)
+
+	err = s.create(ctx, org)
+	if err != nil {
+		return nil, errors.Wrap(err, "create personal organization")
+	}
+
+	return org, nil
+}
+
+// Create a new Organization, owned by the Account creating it.
+func (s *OrganizationService) Create(ctx context.Context, acc *Account, name string) (*Organization, error) {
+	org := NewOrganization(acc.ID, name)
+
+	err := org.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.create(ctx, org)
+	if err != nil {
+		return nil, errors.Wrap(err, "create organization")
+	}
+
+	return org, nil
+}
+
+func (s *OrganizationService) create(ctx context.Context, org *Organization) error {
+	err := s.orgRepo.Create(ctx, org)
+	if err != nil {
+		return err
+	}
+
+	return s.memberRepo.Create(ctx, NewMembership(org.ID, org.AccountID, RoleOwner))
+}
+
+// Authorize checks that the Account holds the Permission within the Organization, and returns its Membership.
+func (s *OrganizationService) Authorize(ctx context.Context, accountID ID, orgID OrganizationID, perm Permission) (*Membership, error) {
+	membership, err := s.memberRepo.Find(ctx, orgID, accountID)
+	if err != nil {
+		return nil, errors.Wrapf(ErrPermissionDenied, "account %s is not a member of organization %s", accountID, orgID)
+	}
+
+	if !membership.Can(perm) {
+		return nil, errors.Wrapf(ErrPermissionDenied, "%s of organization %s cannot %s", membership.Role, orgID, perm)
+	}
+
+	return membership, nil
+}
+
+// Invite the owner of the Email to join the Organization of the inviting member.
+//
+// The returned token is required for accepting the Invitation.
+func (s *OrganizationService) Invite(ctx context.Context, inviter *Membership, email Email, role Role) (*Invitation, InvitationToken, error) {
+	if !role.IsValid() {
+		return nil, "", errors.Wrap(ErrInvalidRole, role.String())
+	}
+	if !inviter.Role.CanGrant(role) {
+		return nil, "", errors.Wrapf(ErrPermissionDenied, "%s cannot invite %s", inviter.Role, role)
+	}
+
+	err := email.Validate()
+	if err != nil {
+		return nil, "", err
+	}
+
+	inv, token, err := NewInvitation(inviter.OrganizationID, inviter.AccountID, email, role)
+	if err != nil {
+		return nil, "", err
+	}
+
+	err = s.inviteRepo.Create(ctx, inv)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "create invitation")
+	}
+
+	return inv, token, nil
+}
+
+// AcceptInvitation adds the Account to the Organization it was invited to.
+//
+// Invitations can only be accepted by the Account they were sent to,
+// and are reported as missing to everyone else.
+func (s *OrganizationService) AcceptInvitation(ctx context.Context, acc *Account, token InvitationToken) (*Membership, error) {
+	inv, err := s.inviteRepo.FindByToken(ctx, token)
+	if err != nil {
+		return nil, errors.Wrap(ErrInvitationNotFound, "find invitation by token")
+	}
+	if !inv.IsPending() || !strings.EqualFold(inv.Email.String(), acc.Email.String()) {
+		return nil, errors.Wrapf(ErrInvitationNotFound, "invitation %s cannot be accepted by %s", inv.ID, acc.ID)
+	}
+
+	_, err = s.memberRepo.Find(ctx, inv.OrganizationID, acc.ID)
+	if err == nil {
+		return nil, errors.Wrapf(ErrAlreadyMember, "organization %s", inv.OrganizationID)
+	}
+
+	membership := NewMembership(inv.OrganizationID, acc.ID, inv.Role)
+
+	err = s.memberRepo.Create(ctx, membership)
+	if err != nil {
+		return nil, errors.Wrap(err, "create membership")
+	}
+
+	inv.Accept()
+
+	err = s.inviteRepo.Update(ctx, inv)
+	if err != nil {
+		return nil, errors.Wrap(err, "update invitation")
+	}
+
+	return membership, nil
+}
+
+// AddMember adds the Account to the Organization with the provided Role, without an Invitation.
+func (s *OrganizationService) AddMember(ctx context.Context, orgID OrganizationID, acc *Account, role Role) (*Membership, error) {
+	if !role.IsValid() {
+		return nil, errors.Wrap(ErrInvalidRole, role.String())
+	}
+
+	_, err := s.orgRepo.Find(ctx, orgID)
+	if err != nil {
+		return nil, errors.Wrap(err, "find organization")
+	}
+
+	_, err = s.memberRepo.Find(ctx, orgID, acc.ID)
+	if err == nil {
+		return nil, errors.Wrapf(ErrAlreadyMember, "organization %s", orgID)
+	}
+
+	membership := NewMembership(orgID, acc.ID, role)
+
+	err = s.memberRepo.Create(ctx, membership)
+	if err != nil {
+		return nil, errors.Wrap(err, "create membership")
+	}
+
+	return membership, nil
+}
+
+// ChangeRole changes the Role of a member of the Organization.
+//
+// Members can only change the roles they could grant, and the last owner of an Organization cannot be demoted.
+func (s *OrganizationService) ChangeRole(ctx context.Context, actor *Membership, member *Membership, role Role) error {
+	if !role.IsValid() {
+		return errors.Wrap(ErrInvalidRole, role.String())
+	}
+	if !actor.Role.CanGrant(member.Role) || !actor.Role.CanGrant(role) {
+		return errors.Wrapf(ErrPermissionDenied, "%s cannot change %s to %s", actor.Role, member.Role, role)
+	}
+
+	if member.Role == RoleOwner && role != RoleOwner {
+		err := s.checkOtherOwners(ctx, member)
+		if err != nil {
+			return err
+		}
+	}
+
+	member.Role = role
+	member.UpdatedAt = time.Now()
+
+	return s.memberRepo.Update(ctx, member)
+}
+
+// RemoveMember removes a member from the Organization.
+//
+// Members can always leave an Organization, as long as they are not its last owner.
+func (s *OrganizationService) RemoveMember(ctx context.Context, actor *Membership, member *Membership) error {
+	if actor.AccountID != member.AccountID && !actor.Role.CanGrant(member.Role) {
+		return errors.Wrapf(ErrPermissionDenied, "%s cannot remove %s", actor.Role, member.Role)
+	}
+
+	if member.Role == RoleOwner {
+		err := s.checkOtherOwners(ctx, member)
+		if err != nil {
+			return err
+		}
+	}
+
+	return s.memberRepo.Delete(ctx, member)
+}
+
+func (s *OrganizationService) checkOtherOwners(ctx context.Context, owner *Membership) error {
+	members, err := s.memberRepo.ListByOrganization(ctx, owner.OrganizationID)
+	if err != nil {
+		return errors.Wrap(err, "list organization members")
+	}
+
+	for _, member := range members {
+		if member.Role == RoleOwner && member.AccountID != owner.AccountID {
+			return nil
+		}
+	}
+
+	return ErrLastOwner
+}
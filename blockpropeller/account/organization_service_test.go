@@ -0,0 +1,132 @@
+package account_test
+
+import (
+	"context"
+	"testing"
+
+	"blockpropeller.dev/blockpropeller/account"
+	"blockpropeller.dev/lib/test"
+	"github.com/pkg/errors"
+)
+
+func newOrganizationService() (*account.OrganizationService, *account.InMemoryMembershipRepository) {
+	memberRepo := account.NewInMemoryMembershipRepository()
+
+	return account.NewOrganizationService(
+		account.NewInMemoryOrganizationRepository(),
+		memberRepo,
+		account.NewInMemoryInvitationRepository(),
+	), memberRepo
+}
+
+func newTestAccount(email string) *account.Account {
+	return account.NewAccount(account.NewEmail(email), "")
+}
+
+func TestRolePermissions(t *testing.T) {
+	test.AssertBoolEqual(t, "viewer can view", account.RoleViewer.Can(account.PermissionView), true)
+	test.AssertBoolEqual(t, "viewer cannot operate", account.RoleViewer.Can(account.PermissionOperate), false)
+	test.AssertBoolEqual(t, "operator can operate", account.RoleOperator.Can(account.PermissionOperate), true)
+	test.AssertBoolEqual(t, "operator cannot manage", account.RoleOperator.Can(account.PermissionManage), false)
+	test.AssertBoolEqual(t, "admin can manage members", account.RoleAdmin.Can(account.PermissionManageMembers), true)
+	test.AssertBoolEqual(t, "admin cannot manage organization",
+		account.RoleAdmin.Can(account.PermissionManageOrganization), false)
+	test.AssertBoolEqual(t, "owner can manage organization",
+		account.RoleOwner.Can(account.PermissionManageOrganization), true)
+	test.AssertBoolEqual(t, "invalid role cannot view", account.NewRole("root").Can(account.PermissionView), false)
+
+	test.AssertBoolEqual(t, "admin grants operator", account.RoleAdmin.CanGrant(account.RoleOperator), true)
+	test.AssertBoolEqual(t, "admin grants admin", account.RoleAdmin.CanGrant(account.RoleAdmin), true)
+	test.AssertBoolEqual(t, "admin cannot grant owner", account.RoleAdmin.CanGrant(account.RoleOwner), false)
+	test.AssertBoolEqual(t, "owner grants owner", account.RoleOwner.CanGrant(account.RoleOwner), true)
+	test.AssertBoolEqual(t, "operator cannot grant viewer", account.RoleOperator.CanGrant(account.RoleViewer), false)
+}
+
+func TestOrganizationInvitation(t *testing.T) {
+	ctx := context.Background()
+	svc, _ := newOrganizationService()
+
+	owner := newTestAccount("owner@example.com")
+	invitee := newTestAccount("invitee@example.com")
+
+	org, err := svc.Create(ctx, owner, "Acme")
+	test.CheckErr(t, "create organization", err)
+
+	ownership, err := svc.Authorize(ctx, owner.ID, org.ID, account.PermissionManageOrganization)
+	test.CheckErr(t, "authorize owner", err)
+
+	_, err = svc.Authorize(ctx, invitee.ID, org.ID, account.PermissionView)
+	test.AssertBoolEqual(t, "authorize non member", errors.Cause(err) == account.ErrPermissionDenied, true)
+
+	_, token, err := svc.Invite(ctx, ownership, invitee.Email, account.RoleViewer)
+	test.CheckErr(t, "invite viewer", err)
+
+	_, err = svc.AcceptInvitation(ctx, newTestAccount("other@example.com"), token)
+	test.AssertBoolEqual(t, "accept invitation of other account", errors.Cause(err) == account.ErrInvitationNotFound, true)
+
+	membership, err := svc.AcceptInvitation(ctx, invitee, token)
+	test.CheckErr(t, "accept invitation", err)
+	test.AssertStringsEqual(t, "invitee role", membership.Role.String(), account.RoleViewer.String())
+
+	_, err = svc.AcceptInvitation(ctx, invitee, token)
+	test.AssertBoolEqual(t, "accept invitation twice", errors.Cause(err) == account.ErrInvitationNotFound, true)
+
+	_, err = svc.Authorize(ctx, invitee.ID, org.ID, account.PermissionView)
+	test.CheckErr(t, "authorize viewer to view", err)
+
+	_, err = svc.Authorize(ctx, invitee.ID, org.ID, account.PermissionOperate)
+	test.AssertBoolEqual(t, "authorize viewer to operate", errors.Cause(err) == account.ErrPermissionDenied, true)
+
+	_, _, err = svc.Invite(ctx, membership, "another@example.com", account.RoleViewer)
+	test.AssertBoolEqual(t, "viewer invites", errors.Cause(err) == account.ErrPermissionDenied, true)
+}
+
+func TestOrganizationMemberRoles(t *testing.T) {
+	ctx := context.Background()
+	svc, memberRepo := newOrganizationService()
+
+	owner := newTestAccount("owner@example.com")
+	org, err := svc.Personal(ctx, owner)
+	test.CheckErr(t, "create personal organization", err)
+	test.AssertBoolEqual(t, "organization is personal", org.Personal, true)
+
+	personal, err := svc.Personal(ctx, owner)
+	test.CheckErr(t, "find personal organization", err)
+	test.AssertStringsEqual(t, "same personal organization", personal.ID.String(), org.ID.String())
+
+	ownership, err := memberRepo.Find(ctx, org.ID, owner.ID)
+	test.CheckErr(t, "find owner membership", err)
+
+	admin := account.NewMembership(org.ID, account.NewID(), account.RoleAdmin)
+	operator := account.NewMembership(org.ID, account.NewID(), account.RoleOperator)
+	test.CheckErr(t, "add admin", memberRepo.Create(ctx, admin))
+	test.CheckErr(t, "add operator", memberRepo.Create(ctx, operator))
+
+	err = svc.ChangeRole(ctx, admin, operator, account.RoleAdmin)
+	test.CheckErr(t, "admin promotes operator to admin", err)
+
+	err = svc.ChangeRole(ctx, admin, operator, account.RoleOwner)
+	test.AssertBoolEqual(t, "admin promotes to owner", errors.Cause(err) == account.ErrPermissionDenied, true)
+
+	err = svc.ChangeRole(ctx, admin, ownership, account.RoleViewer)
+	test.AssertBoolEqual(t, "admin demotes owner", errors.Cause(err) == account.ErrPermissionDenied, true)
+
+	err = svc.ChangeRole(ctx, ownership, ownership, account.RoleAdmin)
+	test.AssertBoolEqual(t, "last owner demotes itself", errors.Cause(err) == account.ErrLastOwner, true)
+
+	err = svc.RemoveMember(ctx, ownership, ownership)
+	test.AssertBoolEqual(t, "last owner leaves", errors.Cause(err) == account.ErrLastOwner, true)
+
+	err = svc.ChangeRole(ctx, ownership, admin, account.NewRole("root"))
+	test.AssertBoolEqual(t, "change to invalid role", errors.Cause(err) == account.ErrInvalidRole, true)
+
+	err = svc.RemoveMember(ctx, operator, admin)
+	test.CheckErr(t, "admin removes admin", err)
+
+	err = svc.RemoveMember(ctx, operator, operator)
+	test.CheckErr(t, "member leaves", err)
+
+	members, err := memberRepo.ListByOrganization(ctx, org.ID)
+	test.CheckErr(t, "list members", err)
+	test.AssertIntsEqual(t, "remaining members", len(members), 1)
+}
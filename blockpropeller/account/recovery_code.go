@@ -0,0 +1,107 @@
+package account
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+)
+
+// RecoveryCodeCount is the number of RecoveryCodes generated each time two-factor authentication is enabled.
+const RecoveryCodeCount = 10
+
+// RecoveryCodeID is a unique recovery code identifier.
+type RecoveryCodeID string
+
+// NewRecoveryCodeID returns a new unique RecoveryCodeID.
+func NewRecoveryCodeID() RecoveryCodeID {
+	return RecoveryCodeID(uuid.NewV4().String())
+}
+
+// String satisfies the Stringer interface.
+func (id RecoveryCodeID) String() string {
+	return string(id)
+}
+
+// RecoverySecret is a single-use code that can replace a TOTP code,
+// in case the Account loses access to its authenticator app.
+//
+// Only the hash of the secret is stored, so the secrets are available only once they are generated.
+type RecoverySecret string
+
+// NewRecoverySecret returns a new random RecoverySecret, formatted as `xxxxx-xxxxx`.
+func NewRecoverySecret() (RecoverySecret, error) {
+	raw := make([]byte, 5)
+
+	_, err := rand.Read(raw)
+	if err != nil {
+		return "", errors.Wrap(err, "generate recovery secret")
+	}
+
+	encoded := hex.EncodeToString(raw)
+
+	return RecoverySecret(encoded[:5] + "-" + encoded[5:]), nil
+}
+
+// Hash returns the hash of the RecoverySecret under which the RecoveryCode is stored.
+//
+// Secrets are hashed regardless of their case and the dash separating their halves.
+func (s RecoverySecret) Hash() string {
+	normalized := strings.ToLower(strings.Replace(strings.TrimSpace(string(s)), "-", "", -1))
+	hash := sha256.Sum256([]byte(normalized))
+
+	return hex.EncodeToString(hash[:])
+}
+
+// String satisfies the Stringer interface.
+func (s RecoverySecret) String() string {
+	return string(s)
+}
+
+// RecoveryCode is a hashed RecoverySecret of an Account.
+type RecoveryCode struct {
+	ID        RecoveryCodeID `json:"id" gorm:"type:varchar(36) not null"`
+	AccountID ID             `json:"account_id" gorm:"type:varchar(36) not null references accounts(id)"`
+
+	SecretHash string `json:"-" gorm:"type:varchar(64) not null;index"`
+
+	CreatedAt time.Time  `json:"created_at" gorm:"type:timestamp not null;default:CURRENT_TIMESTAMP"`
+	UsedAt    *time.Time `json:"used_at,omitempty" gorm:"type:timestamp"`
+}
+
+// NewRecoveryCodes returns a new set of RecoveryCodes for an Account, along with their secrets.
+func NewRecoveryCodes(accountID ID) ([]*RecoveryCode, []RecoverySecret, error) {
+	codes := make([]*RecoveryCode, 0, RecoveryCodeCount)
+	secrets := make([]RecoverySecret, 0, RecoveryCodeCount)
+	for i := 0; i < RecoveryCodeCount; i++ {
+		secret, err := NewRecoverySecret()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		codes = append(codes, &RecoveryCode{
+			ID:         NewRecoveryCodeID(),
+			AccountID:  accountID,
+			SecretHash: secret.Hash(),
+			CreatedAt:  time.Now(),
+		})
+		secrets = append(secrets, secret)
+	}
+
+	return codes, secrets, nil
+}
+
+// IsUsed checks whether the RecoveryCode was already used.
+func (code *RecoveryCode) IsUsed() bool {
+	return code.UsedAt != nil
+}
+
+// Use marks the RecoveryCode as used.
+func (code *RecoveryCode) Use() {
+	now := time.Now()
+	code.UsedAt = &now
+}
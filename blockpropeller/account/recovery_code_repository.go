@@ -0,0 +1,104 @@
+package account
+
+import (
+	"context"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// ErrRecoveryCodeNotFound is returned when a RecoveryCodeRepository does not find a recovery code to return.
+var ErrRecoveryCodeNotFound = errors.New("recovery code not found")
+
+// RecoveryCodeRepository defines an interface for storing and retrieving recovery codes.
+type RecoveryCodeRepository interface {
+	// FindUnused returns the unused RecoveryCode of an Account that matches the provided secret.
+	FindUnused(ctx context.Context, accountID ID, secret RecoverySecret) (*RecoveryCode, error)
+	// CountUnused returns the number of RecoveryCodes an Account has left.
+	CountUnused(ctx context.Context, accountID ID) (int, error)
+
+	// Create a new RecoveryCode.
+	Create(ctx context.Context, code *RecoveryCode) error
+	// Update an existing RecoveryCode.
+	Update(ctx context.Context, code *RecoveryCode) error
+	// DeleteByAccount deletes all the RecoveryCodes of an Account.
+	DeleteByAccount(ctx context.Context, accountID ID) error
+}
+
+// InMemoryRecoveryCodeRepository holds the recovery codes inside an in-memory map.
+//
+// Recovery codes are not persisted on disk and won't survive program restarts.
+type InMemoryRecoveryCodeRepository struct {
+	codes sync.Map
+}
+
+// NewInMemoryRecoveryCodeRepository returns a new InMemoryRecoveryCodeRepository instance.
+func NewInMemoryRecoveryCodeRepository() *InMemoryRecoveryCodeRepository {
+	return &InMemoryRecoveryCodeRepository{}
+}
+
+// FindUnused returns the unused RecoveryCode of an Account that matches the provided secret.
+func (repo *InMemoryRecoveryCodeRepository) FindUnused(
+	ctx context.Context,
+	accountID ID,
+	secret RecoverySecret,
+) (*RecoveryCode, error) {
+	var found *RecoveryCode
+	repo.codes.Range(func(k, v interface{}) bool {
+		code := v.(*RecoveryCode)
+		if code.AccountID != accountID || code.SecretHash != secret.Hash() || code.IsUsed() {
+			return true
+		}
+
+		found = code
+
+		return false
+	})
+	if found == nil {
+		return nil, ErrRecoveryCodeNotFound
+	}
+
+	return found, nil
+}
+
+// CountUnused returns the number of RecoveryCodes an Account has left.
+func (repo *InMemoryRecoveryCodeRepository) CountUnused(ctx context.Context, accountID ID) (int, error) {
+	var count int
+	repo.codes.Range(func(k, v interface{}) bool {
+		code := v.(*RecoveryCode)
+		if code.AccountID == accountID && !code.IsUsed() {
+			count++
+		}
+
+		return true
+	})
+
+	return count, nil
+}
+
+// Create a new RecoveryCode.
+func (repo *InMemoryRecoveryCodeRepository) Create(ctx context.Context, code *RecoveryCode) error {
+	repo.codes.Store(code.ID, code)
+
+	return nil
+}
+
+// Update an existing RecoveryCode.
+func (repo *InMemoryRecoveryCodeRepository) Update(ctx context.Context, code *RecoveryCode) error {
+	repo.codes.Store(code.ID, code)
+
+	return nil
+}
+
+// DeleteByAccount deletes all the RecoveryCodes of an Account.
+func (repo *InMemoryRecoveryCodeRepository) DeleteByAccount(ctx context.Context, accountID ID) error {
+	repo.codes.Range(func(k, v interface{}) bool {
+		if v.(*RecoveryCode).AccountID == accountID {
+			repo.codes.Delete(k)
+		}
+
+		return true
+	})
+
+	return nil
+}
@@ -0,0 +1,105 @@
+package account
+
+// Role of an Account within an Organization, which determines the Permissions of the Account.
+//
+// Roles are ordered, with every role holding all the permissions of the roles below it.
+type Role string
+
+// Roles available to Organization members.
+const (
+	// RoleViewer can view the infrastructure of the Organization.
+	RoleViewer Role = "viewer"
+	// RoleOperator can additionally provision, configure and destroy infrastructure.
+	RoleOperator Role = "operator"
+	// RoleAdmin can additionally manage provider settings, projects and members, and view the audit log.
+	RoleAdmin Role = "admin"
+	// RoleOwner can additionally manage the Organization and its other owners.
+	RoleOwner Role = "owner"
+)
+
+// ValidRoles lists all the Roles available to Organization members, from the least to the most privileged.
+var ValidRoles = []Role{RoleViewer, RoleOperator, RoleAdmin, RoleOwner}
+
+// NewRole returns a new Role instance.
+func NewRole(role string) Role {
+	return Role(role)
+}
+
+// IsValid checks whether the Role is one of the available Roles.
+func (r Role) IsValid() bool {
+	return r.rank() > 0
+}
+
+// Can checks whether the Role holds the provided Permission.
+func (r Role) Can(perm Permission) bool {
+	required, ok := permissionRoles[perm]
+	if !ok {
+		return false
+	}
+
+	return r.rank() >= required.rank()
+}
+
+// CanGrant checks whether a member with the Role can assign the provided Role to other members,
+// or change the Role of members holding it.
+//
+// Members managing others can only grant Roles up to their own, and only owners can grant ownership.
+func (r Role) CanGrant(role Role) bool {
+	if !r.Can(PermissionManageMembers) || !role.IsValid() {
+		return false
+	}
+	if role == RoleOwner {
+		return r == RoleOwner
+	}
+
+	return r.rank() >= role.rank()
+}
+
+// String satisfies the Stringer interface.
+func (r Role) String() string {
+	return string(r)
+}
+
+func (r Role) rank() int {
+	for i, role := range ValidRoles {
+		if role == r {
+			return i + 1
+		}
+	}
+
+	return 0
+}
+
+// Permission is an action an Account can be allowed to take within an Organization.
+type Permission string
+
+// Permissions checked when accessing Organization resources.
+const (
+	// PermissionView allows listing and reading the infrastructure.
+	PermissionView Permission = "view"
+	// PermissionOperate allows provisioning, configuring and destroying servers and their resources.
+	PermissionOperate Permission = "operate"
+	// PermissionManage allows managing provider settings and projects.
+	PermissionManage Permission = "manage"
+	// PermissionManageMembers allows inviting members and changing their roles.
+	PermissionManageMembers Permission = "manage_members"
+	// PermissionManageOrganization allows changing the Organization itself.
+	PermissionManageOrganization Permission = "manage_organization"
+	// PermissionViewAudit allows querying the audit log of the Organization.
+	PermissionViewAudit Permission = "view_audit"
+)
+
+// permissionRoles maps every Permission to the least privileged Role holding it.
+var permissionRoles = map[Permission]Role{
+	PermissionView:               RoleViewer,
+	PermissionOperate:            RoleOperator,
+	PermissionManage:             RoleAdmin,
+	PermissionManageMembers:      RoleAdmin,
+	PermissionManageOrganization: RoleOwner,
+	PermissionViewAudit:          RoleAdmin,
+}
+
+// String satisfies the Stringer interface.
+func (p Permission) String() string {
+	return string(p)
+}
@@ -2,6 +2,7 @@ package account
 
 import (
 	"context"
+	"time"
 
 	"github.com/pkg/errors"
 )
@@ -9,75 +10,141 @@ import (
 var (
 	// ErrInvalidPassword is returned when we cannot match a provided password with the stored hash.
 	ErrInvalidPassword = errors.New("invalid password")
+	// ErrInvalidCredentials is returned when logging in with an unknown email or a wrong password,
+	// which are not told apart so the registered emails cannot be enumerated.
+	ErrInvalidCredentials = errors.New("invalid email or password")
+	// ErrSessionRevoked is returned when authenticating within a Session that was revoked or has expired.
+	ErrSessionRevoked = errors.New("session revoked")
+	// ErrRefreshTokenReused is returned when a rotated RefreshToken is used again,
+	// which revokes the Session it was issued for.
+	ErrRefreshTokenReused = errors.New("refresh token reused")
 )
 
+// Tokens are issued to an Account upon logging in or refreshing a Session.
+type Tokens struct {
+	// AccessToken authenticates the requests of the Account until it expires.
+	AccessToken Token
+	// AccessTokenExpiresAt is the time at which the AccessToken must be refreshed.
+	AccessTokenExpiresAt time.Time
+	// RefreshToken can be exchanged for new Tokens once.
+	RefreshToken RefreshToken
+}
+
 // Service is responsible for creating and manipulating the Accounts.
 type Service struct {
-	accRepo  Repository
-	tokenSvc *TokenService
+	accRepo      Repository
+	sessionRepo  SessionRepository
+	tokenSvc     *TokenService
+	twoFactorSvc *TwoFactorService
 }
 
 // NewService returns a new Service instance.
-func NewService(accRepo Repository, tokenSvc *TokenService) *Service {
-	return &Service{accRepo: accRepo, tokenSvc: tokenSvc}
+func NewService(
+	accRepo Repository,
+	sessionRepo SessionRepository,
+	tokenSvc *TokenService,
+	twoFactorSvc *TwoFactorService,
+) *Service {
+	return &Service{accRepo: accRepo, sessionRepo: sessionRepo, tokenSvc: tokenSvc, twoFactorSvc: twoFactorSvc}
 }
 
 // Register an Account with the platform.
-func (s *Service) Register(email Email, password ClearPassword) (*Account, Token, error) {
+func (s *Service) Register(email Email, password ClearPassword) (*Account, *Tokens, error) {
 	if err := email.Validate(); err != nil {
-		return nil, NilToken, errors.Wrap(err, "invalid email")
+		return nil, nil, errors.Wrap(err, "invalid email")
 	}
 	if err := password.Validate(); err != nil {
-		return nil, NilToken, errors.Wrap(err, "invalid password")
+		return nil, nil, errors.Wrap(err, "invalid password")
 	}
 
 	pass, err := GeneratePassword(password)
 	if err != nil {
-		return nil, NilToken, errors.Wrap(err, "generate password")
+		return nil, nil, errors.Wrap(err, "generate password")
 	}
 
 	acc := NewAccount(email, pass)
 
 	err = s.accRepo.Create(context.TODO(), acc)
 	if err != nil {
-		return nil, NilToken, errors.Wrap(err, "create account")
+		return nil, nil, errors.Wrap(err, "create account")
 	}
 
-	token, err := s.tokenSvc.GenerateToken(acc.ID)
+	tokens, err := s.startSession(acc, false)
 	if err != nil {
-		return nil, NilToken, errors.Wrap(err, "generate token")
+		return nil, nil, err
 	}
 
-	return acc, token, nil
+	return acc, tokens, nil
 }
 
-// Login to an Account using a token.
-func (s *Service) Login(email Email, password ClearPassword) (Token, error) {
+// Login to an Account, starting a new Session.
+//
+// ErrInvalidCredentials is returned for both unknown emails and wrong passwords.
+// Accounts with two-factor authentication must provide a code as the second step,
+// which is either a TOTP code or a recovery secret. ErrTwoFactorRequired is returned
+// when the code is missing, but only once the password was verified.
+func (s *Service) Login(email Email, password ClearPassword, code string) (*Tokens, error) {
 	acc, err := s.accRepo.FindByEmail(context.TODO(), email)
+	if errors.Cause(err) == ErrAccountNotFound {
+		// Unknown accounts take as long to reject as wrong passwords.
+		_ = unknownAccountPassword().Compare(password)
+
+		return nil, ErrInvalidCredentials
+	}
 	if err != nil {
-		return NilToken, err
+		return nil, errors.Wrap(err, "find account by email")
 	}
 
 	if err = acc.Password.Compare(password); err != nil {
-		return NilToken, ErrInvalidPassword
+		return nil, ErrInvalidCredentials
+	}
+
+	return s.startSessionWithSecondFactor(acc, code)
+}
+
+// Refresh a Session, exchanging its current RefreshToken for new Tokens.
+func (s *Service) Refresh(refreshToken RefreshToken) (*Tokens, error) {
+	session, err := s.sessionRepo.FindByRefreshToken(context.TODO(), refreshToken)
+	if err != nil {
+		return nil, errors.Wrap(err, "find session by refresh token")
+	}
+	if !session.IsActive() {
+		return nil, ErrSessionRevoked
+	}
+
+	if session.RefreshTokenHash != refreshToken.Hash() {
+		session.Revoke()
+
+		err = s.sessionRepo.Update(context.TODO(), session)
+		if err != nil {
+			return nil, errors.Wrap(err, "revoke session")
+		}
+
+		return nil, ErrRefreshTokenReused
 	}
 
-	token, err := s.tokenSvc.GenerateToken(acc.ID)
+	refreshToken, err = session.Rotate(s.tokenSvc.RefreshTTL())
 	if err != nil {
-		return NilToken, err
+		return nil, errors.Wrap(err, "rotate refresh token")
 	}
 
-	return token, nil
+	err = s.sessionRepo.Update(context.TODO(), session)
+	if err != nil {
+		return nil, errors.Wrap(err, "update session")
+	}
+
+	return s.issueTokens(session, refreshToken)
 }
 
-// Authenticate an access token as an Account.
-func (s *Service) Authenticate(token Token) (*Account, error) {
-	id, err := s.tokenSvc.ParseToken(token)
+// FindByRefreshToken returns the Account the provided token is the current or the previous refresh token of,
+// whether or not its Session is still active.
+func (s *Service) FindByRefreshToken(refreshToken RefreshToken) (*Account, error) {
+	session, err := s.sessionRepo.FindByRefreshToken(context.TODO(), refreshToken)
 	if err != nil {
-		return nil, errors.Wrap(err, "could not parse token")
+		return nil, errors.Wrap(err, "find session by refresh token")
 	}
 
-	acc, err := s.accRepo.FindByID(context.TODO(), id)
+	acc, err := s.accRepo.FindByID(context.TODO(), session.AccountID)
 	if err != nil {
 		return nil, errors.Wrap(err, "find account by id")
 	}
@@ -85,10 +152,78 @@ func (s *Service) Authenticate(token Token) (*Account, error) {
 	return acc, nil
 }
 
+// Authenticate an access token as an Account, returning the Session the token was issued for.
+func (s *Service) Authenticate(token Token) (*Account, *Session, error) {
+	claims, err := s.tokenSvc.ParseToken(token)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "could not parse token")
+	}
+
+	session, err := s.sessionRepo.Find(context.TODO(), claims.SessionID)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "find session by id")
+	}
+	if !session.IsActive() || session.AccountID != claims.AccountID() {
+		return nil, nil, ErrSessionRevoked
+	}
+
+	acc, err := s.accRepo.FindByID(context.TODO(), claims.AccountID())
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "find account by id")
+	}
+
+	return acc, session, nil
+}
+
+// Logout from a Session, invalidating all the tokens issued for it.
+func (s *Service) Logout(session *Session) error {
+	session.Revoke()
+
+	err := s.sessionRepo.Update(context.TODO(), session)
+	if err != nil {
+		return errors.Wrap(err, "revoke session")
+	}
+
+	return nil
+}
+
+// LogoutAll logs an Account out of all of its Sessions.
+func (s *Service) LogoutAll(acc *Account) error {
+	return s.logoutAllExcept(acc, NilSessionID)
+}
+
+// LogoutOthers logs an Account out of all of its Sessions, except for the current one.
+func (s *Service) LogoutOthers(acc *Account, current *Session) error {
+	return s.logoutAllExcept(acc, current.ID)
+}
+
+func (s *Service) logoutAllExcept(acc *Account, except SessionID) error {
+	sessions, err := s.sessionRepo.ListActiveByAccount(context.TODO(), acc.ID)
+	if err != nil {
+		return errors.Wrap(err, "list active sessions")
+	}
+
+	for _, session := range sessions {
+		if session.ID == except {
+			continue
+		}
+
+		err = s.Logout(session)
+		if err != nil {
+			return errors.Wrapf(err, "logout session %s", session.ID)
+		}
+	}
+
+	return nil
+}
+
 // ChangePassword changes the password of an Account.
 func (s *Service) ChangePassword(acc *Account, oldPassword ClearPassword, newPassword ClearPassword) error {
 	if err := acc.Password.Compare(oldPassword); err != nil {
-		return errors.Wrap(err, "invalid old password")
+		return errors.Wrap(ErrInvalidPassword, "invalid old password")
+	}
+	if err := newPassword.Validate(); err != nil {
+		return errors.Wrap(err, "invalid new password")
 	}
 
 	password, err := GeneratePassword(newPassword)
@@ -105,3 +240,51 @@ func (s *Service) ChangePassword(acc *Account, oldPassword ClearPassword, newPas
 
 	return nil
 }
+
+// startSessionWithSecondFactor starts a new Session for an Account whose first factor was verified,
+// verifying the second factor code first if the Account has two-factor authentication enabled.
+func (s *Service) startSessionWithSecondFactor(acc *Account, code string) (*Tokens, error) {
+	if !acc.IsTwoFactorEnabled() {
+		return s.startSession(acc, false)
+	}
+	if code == "" {
+		return nil, ErrTwoFactorRequired
+	}
+
+	err := s.twoFactorSvc.Verify(context.TODO(), acc, code)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.startSession(acc, true)
+}
+
+func (s *Service) startSession(acc *Account, twoFactorVerified bool) (*Tokens, error) {
+	session, refreshToken, err := NewSession(acc.ID, s.tokenSvc.RefreshTTL())
+	if err != nil {
+		return nil, errors.Wrap(err, "start session")
+	}
+	if twoFactorVerified {
+		session.VerifyTwoFactor()
+	}
+
+	err = s.sessionRepo.Create(context.TODO(), session)
+	if err != nil {
+		return nil, errors.Wrap(err, "create session")
+	}
+
+	return s.issueTokens(session, refreshToken)
+}
+
+func (s *Service) issueTokens(session *Session, refreshToken RefreshToken) (*Tokens, error) {
+	accessToken, expiresAt, err := s.tokenSvc.GenerateToken(session.AccountID, session.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "generate token")
+	}
+
+	return &Tokens{
+		AccessToken:          accessToken,
+		AccessTokenExpiresAt: expiresAt,
+		RefreshToken:         refreshToken,
+	}, nil
+}
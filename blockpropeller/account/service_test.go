@@ -0,0 +1,114 @@
+package account_test
+
+import (
+	"testing"
+	"time"
+
+	"blockpropeller.dev/blockpropeller/account"
+	"blockpropeller.dev/lib/test"
+	"github.com/pkg/errors"
+)
+
+func newAccountService(accessTTL time.Duration) *account.Service {
+	accRepo := account.NewInMemoryRepository()
+	sessionRepo := account.NewInMemorySessionRepository()
+
+	return account.NewService(
+		accRepo,
+		sessionRepo,
+		account.NewTokenService("secret", accessTTL, 24*time.Hour),
+		account.NewTwoFactorService(accRepo, account.NewInMemoryRecoveryCodeRepository(), sessionRepo),
+	)
+}
+
+func TestTokenClaims(t *testing.T) {
+	tokenSvc := account.NewTokenService("secret", time.Minute, time.Hour)
+
+	accID := account.NewID()
+	sessionID := account.NewSessionID()
+
+	token, expiresAt, err := tokenSvc.GenerateToken(accID, sessionID)
+	test.CheckErr(t, "generate token", err)
+	test.AssertBoolEqual(t, "token expires within TTL", expiresAt.After(time.Now().Add(time.Minute)), false)
+
+	claims, err := tokenSvc.ParseToken(token)
+	test.CheckErr(t, "parse token", err)
+	test.AssertStringsEqual(t, "account claim", claims.AccountID().String(), accID.String())
+	test.AssertStringsEqual(t, "session claim", claims.SessionID.String(), sessionID.String())
+	test.AssertBoolEqual(t, "token ID claim", claims.Id != "", true)
+	test.AssertBoolEqual(t, "issued at claim", claims.IssuedAt != 0, true)
+
+	_, err = account.NewTokenService("other", time.Minute, time.Hour).ParseToken(token)
+	test.CheckErrExists(t, "parse token with another secret", err)
+
+	expiredSvc := account.NewTokenService("secret", -time.Minute, time.Hour)
+
+	expired, _, err := expiredSvc.GenerateToken(accID, sessionID)
+	test.CheckErr(t, "generate expired token", err)
+
+	_, err = expiredSvc.ParseToken(expired)
+	test.CheckErrExists(t, "parse expired token", err)
+}
+
+func TestSessionRefresh(t *testing.T) {
+	svc := newAccountService(time.Minute)
+
+	acc, tokens, err := svc.Register("user@example.com", "password")
+	test.CheckErr(t, "register account", err)
+
+	authAcc, session, err := svc.Authenticate(tokens.AccessToken)
+	test.CheckErr(t, "authenticate access token", err)
+	test.AssertStringsEqual(t, "authenticated account", authAcc.ID.String(), acc.ID.String())
+
+	refreshed, err := svc.Refresh(tokens.RefreshToken)
+	test.CheckErr(t, "refresh session", err)
+	test.AssertBoolEqual(t, "refresh token rotated", refreshed.RefreshToken != tokens.RefreshToken, true)
+
+	_, refreshedSession, err := svc.Authenticate(refreshed.AccessToken)
+	test.CheckErr(t, "authenticate refreshed token", err)
+	test.AssertStringsEqual(t, "same session", refreshedSession.ID.String(), session.ID.String())
+
+	_, err = svc.Refresh(tokens.RefreshToken)
+	test.AssertBoolEqual(t, "reuse rotated refresh token", errors.Cause(err) == account.ErrRefreshTokenReused, true)
+
+	_, err = svc.Refresh(refreshed.RefreshToken)
+	test.AssertBoolEqual(t, "refresh revoked session", errors.Cause(err) == account.ErrSessionRevoked, true)
+
+	_, _, err = svc.Authenticate(refreshed.AccessToken)
+	test.AssertBoolEqual(t, "authenticate within revoked session", errors.Cause(err) == account.ErrSessionRevoked, true)
+}
+
+func TestLogout(t *testing.T) {
+	svc := newAccountService(time.Minute)
+
+	acc, first, err := svc.Register("user@example.com", "password")
+	test.CheckErr(t, "register account", err)
+
+	second, err := svc.Login("user@example.com", "password", "")
+	test.CheckErr(t, "login", err)
+
+	third, err := svc.Login("user@example.com", "password", "")
+	test.CheckErr(t, "login again", err)
+
+	_, session, err := svc.Authenticate(first.AccessToken)
+	test.CheckErr(t, "authenticate first session", err)
+
+	test.CheckErr(t, "logout", svc.Logout(session))
+
+	_, _, err = svc.Authenticate(first.AccessToken)
+	test.AssertBoolEqual(t, "authenticate logged out session", errors.Cause(err) == account.ErrSessionRevoked, true)
+
+	_, err = svc.Refresh(first.RefreshToken)
+	test.AssertBoolEqual(t, "refresh logged out session", errors.Cause(err) == account.ErrSessionRevoked, true)
+
+	_, _, err = svc.Authenticate(second.AccessToken)
+	test.CheckErr(t, "authenticate other session", err)
+
+	test.CheckErr(t, "logout all", svc.LogoutAll(acc))
+
+	_, _, err = svc.Authenticate(second.AccessToken)
+	test.AssertBoolEqual(t, "authenticate second session", errors.Cause(err) == account.ErrSessionRevoked, true)
+
+	_, _, err = svc.Authenticate(third.AccessToken)
+	test.AssertBoolEqual(t, "authenticate third session", errors.Cause(err) == account.ErrSessionRevoked, true)
+}
@@ -0,0 +1,138 @@
+package account
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+)
+
+// TwoFactorFreshness is the time after verifying a second factor within which a Session
+// can perform sensitive operations without verifying it again.
+const TwoFactorFreshness = 10 * time.Minute
+
+// NilSessionID is an empty SessionID.
+var NilSessionID SessionID
+
+// SessionID is a unique session identifier.
+type SessionID string
+
+// NewSessionID returns a new unique SessionID.
+func NewSessionID() SessionID {
+	return SessionID(uuid.NewV4().String())
+}
+
+// String satisfies the Stringer interface.
+func (id SessionID) String() string {
+	return string(id)
+}
+
+// RefreshToken is the secret used for renewing the access tokens of a Session.
+//
+// Only the hash of the token is stored, so the token is available only once it is issued.
+type RefreshToken string
+
+// NewRefreshToken returns a new random RefreshToken.
+func NewRefreshToken() (RefreshToken, error) {
+	raw := make([]byte, 32)
+
+	_, err := rand.Read(raw)
+	if err != nil {
+		return "", errors.Wrap(err, "generate refresh token")
+	}
+
+	return RefreshToken(hex.EncodeToString(raw)), nil
+}
+
+// Hash returns the hash of the RefreshToken under which the Session is stored.
+func (t RefreshToken) Hash() string {
+	hash := sha256.Sum256([]byte(t))
+
+	return hex.EncodeToString(hash[:])
+}
+
+// String satisfies the Stringer interface.
+func (t RefreshToken) String() string {
+	return string(t)
+}
+
+// Session is started each time an Account logs in, and lasts until it is revoked
+// or is not refreshed for longer than its TTL.
+//
+// Refresh tokens are rotated on each use. The previous refresh token is remembered,
+// so that its reuse, which means that it was leaked, revokes the Session.
+type Session struct {
+	ID        SessionID `json:"id" gorm:"type:varchar(36) not null"`
+	AccountID ID        `json:"account_id" gorm:"type:varchar(36) not null references accounts(id)"`
+
+	RefreshTokenHash         string `json:"-" gorm:"type:varchar(64) not null;unique_index"`
+	PreviousRefreshTokenHash string `json:"-" gorm:"type:varchar(64);index"`
+
+	ExpiresAt time.Time  `json:"expires_at" gorm:"type:timestamp not null"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty" gorm:"type:timestamp"`
+
+	TwoFactorVerifiedAt *time.Time `json:"two_factor_verified_at,omitempty" gorm:"type:timestamp"`
+
+	CreatedAt time.Time `json:"created_at" gorm:"type:timestamp not null;default:CURRENT_TIMESTAMP"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"type:timestamp not null;default:CURRENT_TIMESTAMP"`
+}
+
+// NewSession returns a new Session instance, along with its first RefreshToken.
+func NewSession(accountID ID, ttl time.Duration) (*Session, RefreshToken, error) {
+	token, err := NewRefreshToken()
+	if err != nil {
+		return nil, "", err
+	}
+
+	return &Session{
+		ID:        NewSessionID(),
+		AccountID: accountID,
+
+		RefreshTokenHash: token.Hash(),
+
+		ExpiresAt: time.Now().Add(ttl),
+	}, token, nil
+}
+
+// IsActive checks whether the Session was neither revoked, nor has it expired.
+func (s *Session) IsActive() bool {
+	return s.RevokedAt == nil && time.Now().Before(s.ExpiresAt)
+}
+
+// Rotate replaces the RefreshToken of the Session, extending the Session for another TTL.
+func (s *Session) Rotate(ttl time.Duration) (RefreshToken, error) {
+	token, err := NewRefreshToken()
+	if err != nil {
+		return "", err
+	}
+
+	s.PreviousRefreshTokenHash = s.RefreshTokenHash
+	s.RefreshTokenHash = token.Hash()
+	s.ExpiresAt = time.Now().Add(ttl)
+
+	return token, nil
+}
+
+// VerifyTwoFactor records that the Account verified its second factor within the Session.
+func (s *Session) VerifyTwoFactor() {
+	now := time.Now()
+	s.TwoFactorVerifiedAt = &now
+}
+
+// HasFreshTwoFactor checks whether the second factor was verified within the TwoFactorFreshness.
+func (s *Session) HasFreshTwoFactor() bool {
+	return s.TwoFactorVerifiedAt != nil && time.Since(*s.TwoFactorVerifiedAt) < TwoFactorFreshness
+}
+
+// Revoke the Session, invalidating both its refresh and access tokens.
+func (s *Session) Revoke() {
+	if s.RevokedAt != nil {
+		return
+	}
+
+	now := time.Now()
+	s.RevokedAt = &now
+}
@@ -0,0 +1,99 @@
+package account
+
+import (
+	"context"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// ErrSessionNotFound is returned when a SessionRepository does not find a session to return.
+var ErrSessionNotFound = errors.New("session not found")
+
+// SessionRepository defines an interface for storing and retrieving sessions.
+type SessionRepository interface {
+	// Find a Session given a SessionID.
+	Find(ctx context.Context, id SessionID) (*Session, error)
+	// FindByRefreshToken returns the Session the provided token is the current or the previous refresh token of.
+	FindByRefreshToken(ctx context.Context, token RefreshToken) (*Session, error)
+	// ListActiveByAccount lists the sessions of an Account that were not revoked.
+	ListActiveByAccount(ctx context.Context, accountID ID) ([]*Session, error)
+
+	// Create a new Session.
+	Create(ctx context.Context, session *Session) error
+	// Update an existing Session.
+	Update(ctx context.Context, session *Session) error
+}
+
+// InMemorySessionRepository holds the sessions inside an in-memory map.
+//
+// Sessions are not persisted on disk and won't survive program restarts.
+type InMemorySessionRepository struct {
+	sessions sync.Map
+}
+
+// NewInMemorySessionRepository returns a new InMemorySessionRepository instance.
+func NewInMemorySessionRepository() *InMemorySessionRepository {
+	return &InMemorySessionRepository{}
+}
+
+// Find a Session given a SessionID.
+func (repo *InMemorySessionRepository) Find(ctx context.Context, id SessionID) (*Session, error) {
+	session, ok := repo.sessions.Load(id)
+	if !ok {
+		return nil, ErrSessionNotFound
+	}
+
+	return session.(*Session), nil
+}
+
+// FindByRefreshToken returns the Session the provided token is the current or the previous refresh token of.
+func (repo *InMemorySessionRepository) FindByRefreshToken(ctx context.Context, token RefreshToken) (*Session, error) {
+	hash := token.Hash()
+
+	var found *Session
+	repo.sessions.Range(func(k, v interface{}) bool {
+		session := v.(*Session)
+		if session.RefreshTokenHash != hash && session.PreviousRefreshTokenHash != hash {
+			return true
+		}
+
+		found = session
+
+		return false
+	})
+	if found == nil {
+		return nil, ErrSessionNotFound
+	}
+
+	return found, nil
+}
+
+// ListActiveByAccount lists the sessions of an Account that were not revoked.
+func (repo *InMemorySessionRepository) ListActiveByAccount(ctx context.Context, accountID ID) ([]*Session, error) {
+	var sessions []*Session
+	repo.sessions.Range(func(k, v interface{}) bool {
+		session := v.(*Session)
+		if session.AccountID == accountID && session.RevokedAt == nil {
+			sessions = append(sessions, session)
+		}
+
+		return true
+	})
+
+	return sessions, nil
+}
+
+// Create a new Session.
+func (repo *InMemorySessionRepository) Create(ctx context.Context, session *Session) error {
+	repo.sessions.Store(session.ID, session)
+
+	return nil
+}
+
+// Update an existing Session.
+func (repo *InMemorySessionRepository) Update(ctx context.Context, session *Session) error {
+	repo.sessions.Store(session.ID, session)
+
+	return nil
+}
@@ -0,0 +1,246 @@
+package account
+
+import (
+	"context"
+	"time"
+
+	"blockpropeller.dev/blockpropeller/oidc"
+	"github.com/pkg/errors"
+)
+
+// ErrEmailNotVerified is returned when the identity provider did not verify the email of a user
+// without a linked Identity, so the user cannot be matched with an Account.
+var ErrEmailNotVerified = errors.New("email not verified by identity provider")
+
+// SSOService is responsible for logging Accounts in through an OpenID Connect identity provider.
+//
+// Users are matched with Accounts by the Identity linked on their first login,
+// which is looked up by the email verified by the identity provider.
+// Accounts with two-factor authentication still require their second factor,
+// the same way as logging in with a password does.
+type SSOService struct {
+	cfg      *oidc.Config
+	provider *oidc.Provider
+
+	accRepo      Repository
+	identityRepo IdentityRepository
+	authRepo     SSOAuthorizationRepository
+
+	accSvc    *Service
+	apiKeySvc *APIKeyService
+	orgSvc    *OrganizationService
+}
+
+// NewSSOService returns a new SSOService instance.
+func NewSSOService(
+	cfg *oidc.Config,
+	provider *oidc.Provider,
+	accRepo Repository,
+	identityRepo IdentityRepository,
+	authRepo SSOAuthorizationRepository,
+	accSvc *Service,
+	apiKeySvc *APIKeyService,
+	orgSvc *OrganizationService,
+) *SSOService {
+	return &SSOService{
+		cfg:          cfg,
+		provider:     provider,
+		accRepo:      accRepo,
+		identityRepo: identityRepo,
+		authRepo:     authRepo,
+		accSvc:       accSvc,
+		apiKeySvc:    apiKeySvc,
+		orgSvc:       orgSvc,
+	}
+}
+
+// Enabled checks whether an identity provider is configured.
+func (s *SSOService) Enabled() bool {
+	return s.cfg.Enabled()
+}
+
+// Begin a single sign-on login, returning the URL the user authenticates at with the identity provider.
+func (s *SSOService) Begin(ctx context.Context) (string, error) {
+	if !s.Enabled() {
+		return "", oidc.ErrDisabled
+	}
+
+	verifier, err := oidc.NewCodeVerifier()
+	if err != nil {
+		return "", err
+	}
+	nonce, err := oidc.NewNonce()
+	if err != nil {
+		return "", err
+	}
+
+	auth, state, err := NewSSOAuthorization(nonce, verifier)
+	if err != nil {
+		return "", err
+	}
+
+	err = s.authRepo.Create(ctx, auth)
+	if err != nil {
+		return "", errors.Wrap(err, "create sso authorization")
+	}
+
+	authURL, err := s.provider.AuthorizationURL(ctx, state.String(), nonce, oidc.CodeChallenge(verifier))
+	if err != nil {
+		return "", errors.Wrap(err, "build authorization url")
+	}
+
+	return authURL, nil
+}
+
+// Complete a single sign-on login with the state and authorization code the identity provider
+// redirected the user back with, starting a new Session for the matching Account.
+//
+// Each authorization can be completed only once, whether it succeeds or not.
+// ErrTwoFactorRequired is returned when the Account requires a twoFactorCode which was not provided,
+// in which case the login has to be started again along with the code.
+func (s *SSOService) Complete(
+	ctx context.Context,
+	state SSOState,
+	code string,
+	twoFactorCode string,
+) (*Account, *Tokens, error) {
+	if !s.Enabled() {
+		return nil, nil, oidc.ErrDisabled
+	}
+
+	auth, err := s.authRepo.FindByState(ctx, state)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "find sso authorization")
+	}
+	if !auth.IsUsable() {
+		return nil, nil, errors.Wrapf(ErrSSOAuthorizationNotFound, "sso authorization %s already used or expired", auth.ID)
+	}
+
+	auth.Use()
+
+	err = s.authRepo.Update(ctx, auth)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "use sso authorization")
+	}
+
+	claims, err := s.provider.Exchange(ctx, code, auth.CodeVerifier, auth.Nonce)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "exchange authorization code")
+	}
+
+	acc, err := s.resolve(ctx, claims)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if s.cfg.Organization != "" {
+		_, err = s.orgSvc.AddMember(ctx, OrganizationID(s.cfg.Organization), acc, NewRole(s.cfg.Role))
+		if err != nil && errors.Cause(err) != ErrAlreadyMember {
+			return nil, nil, errors.Wrap(err, "add account to sso organization")
+		}
+	}
+
+	tokens, err := s.accSvc.startSessionWithSecondFactor(acc, twoFactorCode)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return acc, tokens, nil
+}
+
+// resolve the Account of the user authenticated by the identity provider,
+// linking the Identity of the user to an Account on the first login.
+func (s *SSOService) resolve(ctx context.Context, claims *oidc.Claims) (*Account, error) {
+	identity, err := s.identityRepo.FindBySubject(ctx, claims.Issuer, claims.Subject)
+	if err == nil {
+		identity.LastLoginAt = time.Now()
+
+		err = s.identityRepo.Update(ctx, identity)
+		if err != nil {
+			return nil, errors.Wrap(err, "update identity")
+		}
+
+		return s.accRepo.FindByID(ctx, identity.AccountID)
+	}
+	if errors.Cause(err) != ErrIdentityNotFound {
+		return nil, errors.Wrap(err, "find identity")
+	}
+
+	if claims.Email == "" || !claims.EmailVerified {
+		return nil, errors.Wrapf(ErrEmailNotVerified, "subject %s", claims.Subject)
+	}
+
+	email := NewEmail(claims.Email)
+
+	acc, err := s.accRepo.FindByEmail(ctx, email)
+	switch {
+	case err == nil:
+	case errors.Cause(err) == ErrAccountNotFound && s.cfg.AutoProvision:
+		acc, err = s.provision(ctx, email)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errors.Wrap(err, "find account by email")
+	}
+
+	if !acc.IsEmailVerified() {
+		err = s.claim(ctx, acc)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = s.identityRepo.Create(ctx, NewIdentity(acc.ID, claims.Issuer, claims.Subject, email))
+	if err != nil {
+		return nil, errors.Wrap(err, "link identity")
+	}
+
+	return acc, nil
+}
+
+// claim an Account with an unverified email for the user of the identity provider who owns the email.
+//
+// Anyone could have registered the Account before the email was verified, so its password,
+// second factor, Sessions and API keys are revoked before it is linked.
+func (s *SSOService) claim(ctx context.Context, acc *Account) error {
+	acc.Password = ""
+	acc.VerifyEmail()
+
+	// Resetting the second factor updates the Account as well.
+	err := s.accSvc.twoFactorSvc.reset(ctx, acc)
+	if err != nil {
+		return errors.Wrap(err, "claim account")
+	}
+
+	err = s.accSvc.LogoutAll(acc)
+	if err != nil {
+		return errors.Wrap(err, "logout all sessions")
+	}
+
+	err = s.apiKeySvc.RevokeAll(ctx, acc)
+	if err != nil {
+		return errors.Wrap(err, "revoke all api keys")
+	}
+
+	return nil
+}
+
+// provision an Account for a user of the identity provider.
+//
+// Provisioned Accounts have no password, until one is set through a password reset.
+func (s *SSOService) provision(ctx context.Context, email Email) (*Account, error) {
+	if err := email.Validate(); err != nil {
+		return nil, errors.Wrap(err, "invalid email")
+	}
+
+	acc := NewAccount(email, "")
+	acc.VerifyEmail()
+
+	err := s.accRepo.Create(ctx, acc)
+	if err != nil {
+		return nil, errors.Wrap(err, "create account")
+	}
+
+	return acc, nil
+}
@@ -0,0 +1,335 @@
+package account_test
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"blockpropeller.dev/blockpropeller/account"
+	"blockpropeller.dev/blockpropeller/oidc"
+	"blockpropeller.dev/blockpropeller/oidc/oidctest"
+	"blockpropeller.dev/lib/test"
+	"github.com/pkg/errors"
+)
+
+type ssoFixture struct {
+	iss *oidctest.Issuer
+	cfg *oidc.Config
+
+	accRepo    *account.InMemoryRepository
+	memberRepo *account.InMemoryMembershipRepository
+
+	accSvc       *account.Service
+	twoFactorSvc *account.TwoFactorService
+	apiKeySvc    *account.APIKeyService
+	orgSvc       *account.OrganizationService
+	ssoSvc       *account.SSOService
+}
+
+func newSSOFixture(configure func(cfg *oidc.Config)) *ssoFixture {
+	iss := oidctest.NewIssuer()
+
+	cfg := iss.Config()
+	if configure != nil {
+		configure(cfg)
+	}
+
+	accRepo := account.NewInMemoryRepository()
+	sessionRepo := account.NewInMemorySessionRepository()
+	memberRepo := account.NewInMemoryMembershipRepository()
+
+	twoFactorSvc := account.NewTwoFactorService(accRepo, account.NewInMemoryRecoveryCodeRepository(), sessionRepo)
+	accSvc := account.NewService(
+		accRepo,
+		sessionRepo,
+		account.NewTokenService("secret", time.Minute, time.Hour),
+		twoFactorSvc,
+	)
+	apiKeySvc := account.NewAPIKeyService(accRepo, account.NewInMemoryAPIKeyRepository())
+	orgSvc := account.NewOrganizationService(
+		account.NewInMemoryOrganizationRepository(),
+		memberRepo,
+		account.NewInMemoryInvitationRepository(),
+	)
+
+	return &ssoFixture{
+		iss:          iss,
+		cfg:          cfg,
+		accRepo:      accRepo,
+		memberRepo:   memberRepo,
+		accSvc:       accSvc,
+		twoFactorSvc: twoFactorSvc,
+		apiKeySvc:    apiKeySvc,
+		orgSvc:       orgSvc,
+		ssoSvc: account.NewSSOService(
+			cfg,
+			oidc.NewProvider(cfg),
+			accRepo,
+			account.NewInMemoryIdentityRepository(),
+			account.NewInMemorySSOAuthorizationRepository(),
+			accSvc,
+			apiKeySvc,
+			orgSvc,
+		),
+	}
+}
+
+// authorize begins a single sign-on login and authenticates the user with the Issuer,
+// returning the state and authorization code the Issuer redirected back with.
+func (f *ssoFixture) authorize(t *testing.T, user oidctest.User) (account.SSOState, string) {
+	f.iss.SetUser(user)
+
+	authURL, err := f.ssoSvc.Begin(context.Background())
+	test.CheckErr(t, "begin sso login", err)
+
+	code, state, err := f.iss.Authorize(authURL)
+	test.CheckErr(t, "authorize with issuer", err)
+
+	return account.SSOState(state), code
+}
+
+// login completes a full single sign-on login as the provided user.
+func (f *ssoFixture) login(t *testing.T, user oidctest.User) (*account.Account, error) {
+	acc, _, err := f.loginWithSecondFactor(t, user, "")
+
+	return acc, err
+}
+
+// loginWithSecondFactor completes a full single sign-on login as the provided user,
+// providing the second factor code, and returns the Session it started.
+func (f *ssoFixture) loginWithSecondFactor(
+	t *testing.T,
+	user oidctest.User,
+	twoFactorCode string,
+) (*account.Account, *account.Session, error) {
+	state, code := f.authorize(t, user)
+
+	acc, tokens, err := f.ssoSvc.Complete(context.Background(), state, code, twoFactorCode)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	authenticated, session, err := f.accSvc.Authenticate(tokens.AccessToken)
+	test.CheckErr(t, "authenticate sso session", err)
+	test.AssertStringsEqual(t, "authenticated account", authenticated.ID.String(), acc.ID.String())
+
+	return acc, session, nil
+}
+
+// register an Account with a password, verifying its email if requested.
+func (f *ssoFixture) register(t *testing.T, email account.Email, verified bool) (*account.Account, *account.Tokens) {
+	acc, tokens, err := f.accSvc.Register(email, "password")
+	test.CheckErr(t, "register account", err)
+
+	if verified {
+		acc.VerifyEmail()
+		test.CheckErr(t, "verify account email", f.accRepo.Update(context.Background(), acc))
+	}
+
+	return acc, tokens
+}
+
+// enableTwoFactor enables two-factor authentication for an Account within the Session of the tokens.
+func (f *ssoFixture) enableTwoFactor(t *testing.T, acc *account.Account, tokens *account.Tokens) {
+	ctx := context.Background()
+
+	_, session, err := f.accSvc.Authenticate(tokens.AccessToken)
+	test.CheckErr(t, "authenticate account", err)
+
+	secret, _, err := f.twoFactorSvc.Enroll(ctx, acc)
+	test.CheckErr(t, "enroll account", err)
+
+	code, err := account.GenerateTOTPCode(secret, time.Now())
+	test.CheckErr(t, "generate totp code", err)
+
+	_, err = f.twoFactorSvc.Enable(ctx, acc, session, code)
+	test.CheckErr(t, "enable two-factor authentication", err)
+}
+
+func TestSSOLinksExistingAccount(t *testing.T) {
+	f := newSSOFixture(nil)
+	defer f.iss.Close()
+
+	registered, _ := f.register(t, "user@example.com", true)
+
+	_, err := f.login(t, oidctest.User{Subject: "user-1", Email: "user@example.com"})
+	test.AssertBoolEqual(t, "login with unverified email", errors.Cause(err) == account.ErrEmailNotVerified, true)
+
+	acc, err := f.login(t, oidctest.User{Subject: "user-1", Email: "User@Example.com", EmailVerified: true})
+	test.CheckErr(t, "login with verified email", err)
+	test.AssertStringsEqual(t, "linked account", acc.ID.String(), registered.ID.String())
+
+	// Accounts which verified their email keep their password.
+	_, err = f.accSvc.Login("user@example.com", "password", "")
+	test.CheckErr(t, "login linked account with password", err)
+
+	// Once linked, the Identity is matched by subject, even if the email changed or is not verified anymore.
+	acc, err = f.login(t, oidctest.User{Subject: "user-1", Email: "renamed@example.com"})
+	test.CheckErr(t, "login through linked identity", err)
+	test.AssertStringsEqual(t, "identity account", acc.ID.String(), registered.ID.String())
+
+	_, err = f.login(t, oidctest.User{Subject: "user-2", Email: "other@example.com", EmailVerified: true})
+	test.AssertBoolEqual(t, "login unknown user", errors.Cause(err) == account.ErrAccountNotFound, true)
+}
+
+func TestSSOClaimsUnverifiedAccount(t *testing.T) {
+	f := newSSOFixture(nil)
+	defer f.iss.Close()
+
+	// Anyone could have registered the account before the owner of the email did.
+	registered, tokens := f.register(t, "user@example.com", false)
+	f.enableTwoFactor(t, registered, tokens)
+
+	acc, err := f.login(t, oidctest.User{Subject: "user-1", Email: "user@example.com", EmailVerified: true})
+	test.CheckErr(t, "login with verified email", err)
+	test.AssertStringsEqual(t, "linked account", acc.ID.String(), registered.ID.String())
+	test.AssertBoolEqual(t, "account email verified", acc.IsEmailVerified(), true)
+	test.AssertBoolEqual(t, "two-factor reset", acc.IsTwoFactorEnabled(), false)
+
+	_, err = f.accSvc.Login("user@example.com", "password", "")
+	test.AssertBoolEqual(t, "login with previous password", errors.Cause(err) == account.ErrInvalidCredentials, true)
+
+	_, _, err = f.accSvc.Authenticate(tokens.AccessToken)
+	test.AssertBoolEqual(t, "previous session revoked", errors.Cause(err) == account.ErrSessionRevoked, true)
+}
+
+func TestSSOClaimRevokesAPIKeys(t *testing.T) {
+	f := newSSOFixture(nil)
+	defer f.iss.Close()
+
+	registered, _ := f.register(t, "user@example.com", false)
+
+	_, token, err := f.apiKeySvc.Create(context.Background(), registered, "squatter", account.Scopes{account.ScopeServersWrite}, nil)
+	test.CheckErr(t, "create api key", err)
+
+	_, err = f.login(t, oidctest.User{Subject: "user-1", Email: "user@example.com", EmailVerified: true})
+	test.CheckErr(t, "login with verified email", err)
+
+	_, _, err = f.apiKeySvc.Authenticate(context.Background(), token)
+	test.AssertBoolEqual(t, "previous api key revoked", errors.Cause(err) == account.ErrAPIKeyNotFound, true)
+}
+
+func TestSSORequiresSecondFactor(t *testing.T) {
+	f := newSSOFixture(nil)
+	defer f.iss.Close()
+
+	registered, tokens := f.register(t, "user@example.com", true)
+	f.enableTwoFactor(t, registered, tokens)
+
+	user := oidctest.User{Subject: "user-1", Email: "user@example.com", EmailVerified: true}
+
+	_, _, err := f.loginWithSecondFactor(t, user, "")
+	test.AssertBoolEqual(t, "login without code", errors.Cause(err) == account.ErrTwoFactorRequired, true)
+
+	_, _, err = f.loginWithSecondFactor(t, user, "000000")
+	test.AssertBoolEqual(t, "login with invalid code", errors.Cause(err) == account.ErrInvalidTwoFactorCode, true)
+
+	// The current code was already used for enabling two-factor authentication.
+	code, err := account.GenerateTOTPCode(registered.TOTPSecret, time.Now().Add(30*time.Second))
+	test.CheckErr(t, "generate next totp code", err)
+
+	acc, session, err := f.loginWithSecondFactor(t, user, code)
+	test.CheckErr(t, "login with code", err)
+	test.AssertStringsEqual(t, "linked account", acc.ID.String(), registered.ID.String())
+	test.AssertBoolEqual(t, "session verified", session.HasFreshTwoFactor(), true)
+}
+
+func TestSSOAutoProvision(t *testing.T) {
+	f := newSSOFixture(func(cfg *oidc.Config) {
+		cfg.AutoProvision = true
+	})
+	defer f.iss.Close()
+
+	owner, _, err := f.accSvc.Register("owner@example.com", "password")
+	test.CheckErr(t, "register owner", err)
+
+	org, err := f.orgSvc.Create(context.Background(), owner, "Acme")
+	test.CheckErr(t, "create organization", err)
+
+	f.cfg.Organization = org.ID.String()
+	test.CheckErr(t, "validate config", f.cfg.Validate())
+
+	acc, err := f.login(t, oidctest.User{Subject: "user-1", Email: "user@example.com", EmailVerified: true})
+	test.CheckErr(t, "login new user", err)
+	test.AssertStringsEqual(t, "provisioned email", acc.Email.String(), "user@example.com")
+	test.AssertBoolEqual(t, "provisioned email verified", acc.IsEmailVerified(), true)
+
+	_, err = f.accSvc.Login("user@example.com", "", "")
+	test.CheckErrExists(t, "login provisioned account without password", err)
+
+	membership, err := f.memberRepo.Find(context.Background(), org.ID, acc.ID)
+	test.CheckErr(t, "find sso membership", err)
+	test.AssertStringsEqual(t, "sso role", string(membership.Role), string(account.RoleViewer))
+
+	again, err := f.login(t, oidctest.User{Subject: "user-1", Email: "user@example.com", EmailVerified: true})
+	test.CheckErr(t, "login provisioned user again", err)
+	test.AssertStringsEqual(t, "same account", again.ID.String(), acc.ID.String())
+
+	// Owners keep their role when logging in through single sign-on.
+	_, err = f.login(t, oidctest.User{Subject: "owner-1", Email: "owner@example.com", EmailVerified: true})
+	test.CheckErr(t, "login owner", err)
+
+	membership, err = f.memberRepo.Find(context.Background(), org.ID, owner.ID)
+	test.CheckErr(t, "find owner membership", err)
+	test.AssertStringsEqual(t, "owner role", string(membership.Role), string(account.RoleOwner))
+}
+
+func TestSSOAuthorizationIsSingleUse(t *testing.T) {
+	f := newSSOFixture(func(cfg *oidc.Config) {
+		cfg.AutoProvision = true
+	})
+	defer f.iss.Close()
+
+	ctx := context.Background()
+	user := oidctest.User{Subject: "user-1", Email: "user@example.com", EmailVerified: true}
+
+	state, code := f.authorize(t, user)
+
+	_, _, err := f.ssoSvc.Complete(ctx, "unknown-state", code, "")
+	test.AssertBoolEqual(t, "complete unknown state", errors.Cause(err) == account.ErrSSOAuthorizationNotFound, true)
+
+	_, _, err = f.ssoSvc.Complete(ctx, state, code, "")
+	test.CheckErr(t, "complete sso login", err)
+
+	_, _, err = f.ssoSvc.Complete(ctx, state, code, "")
+	test.AssertBoolEqual(t, "complete used state", errors.Cause(err) == account.ErrSSOAuthorizationNotFound, true)
+
+	// A failed exchange uses up the authorization as well.
+	state, _ = f.authorize(t, user)
+
+	_, _, err = f.ssoSvc.Complete(ctx, state, "invalid-code", "")
+	test.CheckErrExists(t, "complete with invalid code", err)
+
+	_, code = f.authorize(t, user)
+
+	_, _, err = f.ssoSvc.Complete(ctx, state, code, "")
+	test.AssertBoolEqual(t, "complete failed state", errors.Cause(err) == account.ErrSSOAuthorizationNotFound, true)
+}
+
+func TestSSODisabled(t *testing.T) {
+	f := newSSOFixture(func(cfg *oidc.Config) {
+		*cfg = oidc.Config{}
+	})
+	defer f.iss.Close()
+
+	test.AssertBoolEqual(t, "sso enabled", f.ssoSvc.Enabled(), false)
+
+	_, err := f.ssoSvc.Begin(context.Background())
+	test.AssertBoolEqual(t, "begin disabled sso", errors.Cause(err) == oidc.ErrDisabled, true)
+}
+
+func TestSSOBeginUsesPKCE(t *testing.T) {
+	f := newSSOFixture(nil)
+	defer f.iss.Close()
+
+	authURL, err := f.ssoSvc.Begin(context.Background())
+	test.CheckErr(t, "begin sso login", err)
+
+	parsed, err := url.Parse(authURL)
+	test.CheckErr(t, "parse authorization url", err)
+	test.AssertStringsEqual(t, "client id", parsed.Query().Get("client_id"), oidctest.ClientID)
+	test.AssertStringsEqual(t, "code challenge method", parsed.Query().Get("code_challenge_method"), "S256")
+	test.AssertBoolEqual(t, "has state", parsed.Query().Get("state") != "", true)
+	test.AssertBoolEqual(t, "has nonce", parsed.Query().Get("nonce") != "", true)
+}
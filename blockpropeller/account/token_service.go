@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/dgrijalva/jwt-go"
+	uuid "github.com/satori/go.uuid"
 )
 
 // NilToken is an empty Token used for returning and checking nil values.
@@ -24,41 +25,76 @@ func (t Token) String() string {
 	return string(t)
 }
 
+// Claims are the claims encoded within an access Token.
+//
+// Access tokens identify the Session they were issued for, so they can be revoked
+// together with the Session before they expire.
+type Claims struct {
+	SessionID SessionID `json:"sid"`
+
+	jwt.StandardClaims
+}
+
+// AccountID returns the ID of the Account the Token was issued to.
+func (c *Claims) AccountID() ID {
+	return IDFromString(c.Subject)
+}
+
 // TokenService handles generation and parsing of Tokens.
 type TokenService struct {
 	secret string
+
+	accessTTL  time.Duration
+	refreshTTL time.Duration
 }
 
 // NewTokenService returns a new TokenService instance.
-func NewTokenService(secret string) *TokenService {
-	return &TokenService{secret: secret}
+func NewTokenService(secret string, accessTTL, refreshTTL time.Duration) *TokenService {
+	return &TokenService{secret: secret, accessTTL: accessTTL, refreshTTL: refreshTTL}
 }
 
 // ConfigureTokenService configures the TokenService based on configuration parameters.
 func ConfigureTokenService(cfg *JWTConfig) *TokenService {
-	return NewTokenService(cfg.Secret)
+	return NewTokenService(cfg.Secret, cfg.AccessTokenTTL, cfg.RefreshTokenTTL)
+}
+
+// RefreshTTL returns the time a Session stays valid without being refreshed.
+func (ts *TokenService) RefreshTTL() time.Duration {
+	return ts.refreshTTL
 }
 
-// GenerateToken returns a Token with the encoded ID.
-func (ts *TokenService) GenerateToken(id ID) (Token, error) {
-	claims := jwt.MapClaims{
-		"account_id": id.String(),
-		"valid_to":   time.Now().AddDate(0, 1, 0).Unix(),
+// GenerateToken returns a short-lived access Token for the Account within the provided Session,
+// along with the time the Token expires at.
+func (ts *TokenService) GenerateToken(id ID, sessionID SessionID) (Token, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(ts.accessTTL)
+
+	claims := &Claims{
+		SessionID: sessionID,
+		StandardClaims: jwt.StandardClaims{
+			Id:        uuid.NewV4().String(),
+			Subject:   id.String(),
+			IssuedAt:  now.Unix(),
+			ExpiresAt: expiresAt.Unix(),
+		},
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 
 	tokenString, err := token.SignedString([]byte(ts.secret))
 	if err != nil {
-		return NilToken, fmt.Errorf("sign user JWT token: %s", err)
+		return NilToken, time.Time{}, fmt.Errorf("sign user JWT token: %s", err)
 	}
 
-	return NewToken(tokenString), nil
+	return NewToken(tokenString), expiresAt, nil
 }
 
-// ParseToken returns the encoded ID from a provided Token.
-func (ts *TokenService) ParseToken(token Token) (ID, error) {
-	jwtToken, err := jwt.Parse(string(token), func(token *jwt.Token) (interface{}, error) {
+// ParseToken returns the Claims encoded within a provided Token.
+//
+// Expired tokens, as well as tokens missing any of the required claims, are rejected.
+func (ts *TokenService) ParseToken(token Token) (*Claims, error) {
+	var claims Claims
+	_, err := jwt.ParseWithClaims(string(token), &claims, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
@@ -66,13 +102,13 @@ func (ts *TokenService) ParseToken(token Token) (ID, error) {
 		return []byte(ts.secret), nil
 	})
 	if err != nil {
-		return NilID, fmt.Errorf("parse user JWT token: %s", err)
+		return nil, fmt.Errorf("parse user JWT token: %s", err)
 	}
 
-	claims, ok := jwtToken.Claims.(jwt.MapClaims)
-	if !ok {
-		return NilID, fmt.Errorf("parse user JWT claims: %s", err)
+	if claims.Id == "" || claims.Subject == "" || claims.SessionID == NilSessionID ||
+		claims.IssuedAt == 0 || claims.ExpiresAt == 0 {
+		return nil, fmt.Errorf("parse user JWT claims: missing required claims")
 	}
 
-	return IDFromString(claims["account_id"].(string)), nil
+	return &claims, nil
 }
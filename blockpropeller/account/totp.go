@@ -0,0 +1,178 @@
+package account
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"database/sql/driver"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"blockpropeller.dev/blockpropeller/encryption"
+	"github.com/pkg/errors"
+)
+
+const (
+	// TOTPIssuer is the issuer shown by authenticator apps next to the Account email.
+	TOTPIssuer = "BlockPropeller"
+
+	totpSecretSize = 20
+	totpDigits     = 6
+	totpModulo     = 1000000
+	totpPeriod     = 30 * time.Second
+	// totpSkew is the number of periods before and after the current one a code is accepted for,
+	// tolerating clock drift between the server and the authenticator app.
+	totpSkew = 1
+)
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// TOTPSecret is the shared secret an authenticator app generates time-based one-time codes from,
+// as specified by RFC 6238.
+//
+// TOTPSecrets are encrypted before being stored.
+type TOTPSecret string
+
+// NewTOTPSecret returns a new random TOTPSecret.
+func NewTOTPSecret() (TOTPSecret, error) {
+	raw := make([]byte, totpSecretSize)
+
+	_, err := rand.Read(raw)
+	if err != nil {
+		return "", errors.Wrap(err, "generate totp secret")
+	}
+
+	return TOTPSecret(totpEncoding.EncodeToString(raw)), nil
+}
+
+// ProvisioningURI returns the `otpauth://` URI authenticator apps are enrolled with, usually through a QR code.
+func (s TOTPSecret) ProvisioningURI(email Email) string {
+	query := url.Values{}
+	query.Set("secret", s.String())
+	query.Set("issuer", TOTPIssuer)
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", fmt.Sprint(totpDigits))
+	query.Set("period", fmt.Sprint(int(totpPeriod.Seconds())))
+
+	uri := url.URL{
+		Scheme:   "otpauth",
+		Host:     "totp",
+		Path:     "/" + TOTPIssuer + ":" + email.String(),
+		RawQuery: query.Encode(),
+	}
+
+	return uri.String()
+}
+
+// GenerateTOTPCode generates the code for the provided TOTPSecret at the given time,
+// the same way an authenticator app would.
+func GenerateTOTPCode(secret TOTPSecret, at time.Time) (string, error) {
+	return secret.code(totpStep(at))
+}
+
+// Validate checks whether the code was generated within the allowed skew from the given time,
+// returning the step the code was generated for.
+//
+// Codes generated for the lastStep or before it are rejected, so that each code can only be used once.
+func (s TOTPSecret) Validate(code string, at time.Time, lastStep int64) (int64, bool) {
+	code = strings.TrimSpace(code)
+	if len(code) != totpDigits {
+		return 0, false
+	}
+
+	current := totpStep(at)
+	for step := current - totpSkew; step <= current+totpSkew; step++ {
+		if step <= lastStep {
+			continue
+		}
+
+		expected, err := s.code(step)
+		if err != nil {
+			return 0, false
+		}
+
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+
+	return 0, false
+}
+
+func (s TOTPSecret) code(step int64) (string, error) {
+	key, err := totpEncoding.DecodeString(strings.ToUpper(s.String()))
+	if err != nil {
+		return "", errors.Wrap(err, "decode totp secret")
+	}
+
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(step))
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	// Dynamic truncation, as specified by RFC 4226.
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	return fmt.Sprintf("%0*d", totpDigits, value%totpModulo), nil
+}
+
+func totpStep(at time.Time) int64 {
+	return at.Unix() / int64(totpPeriod.Seconds())
+}
+
+// String satisfies the Stringer interface.
+func (s TOTPSecret) String() string {
+	return string(s)
+}
+
+// Value satisfies the driver.Valuer interface.
+func (s TOTPSecret) Value() (driver.Value, error) {
+	if s == "" {
+		return "", nil
+	}
+
+	encrypted, err := encryption.Encrypt([]byte(s))
+	if err != nil {
+		return nil, errors.Wrap(err, "encrypt totp secret")
+	}
+
+	return string(encrypted), nil
+}
+
+// Scan satisfies the sql.Scanner interface.
+func (s *TOTPSecret) Scan(src interface{}) error {
+	var data []byte
+	switch src := src.(type) {
+	case nil:
+		*s = ""
+		return nil
+	case string:
+		data = []byte(src)
+	case []byte:
+		data = src
+	default:
+		return errors.Errorf("invalid totp secret type: %T", src)
+	}
+
+	if len(data) == 0 {
+		*s = ""
+		return nil
+	}
+
+	decrypted, err := encryption.Decrypt(data)
+	if err != nil {
+		return errors.Wrap(err, "decrypt totp secret")
+	}
+
+	*s = TOTPSecret(decrypted)
+
+	return nil
+}
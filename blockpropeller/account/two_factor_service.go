@@ -0,0 +1,227 @@
+package account
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrTwoFactorRequired is returned when logging into an Account with two-factor authentication without a code.
+	ErrTwoFactorRequired = errors.New("two-factor code required")
+	// ErrInvalidTwoFactorCode is returned when the provided code is neither a valid TOTP code,
+	// nor an unused recovery code.
+	ErrInvalidTwoFactorCode = errors.New("invalid two-factor code")
+	// ErrTwoFactorAlreadyEnabled is returned when enrolling an Account that already has two-factor authentication.
+	ErrTwoFactorAlreadyEnabled = errors.New("two-factor authentication already enabled")
+	// ErrTwoFactorNotEnrolled is returned when enabling two-factor authentication before enrolling.
+	ErrTwoFactorNotEnrolled = errors.New("two-factor authentication not enrolled")
+	// ErrTwoFactorNotEnabled is returned when verifying a second factor of an Account without one.
+	ErrTwoFactorNotEnabled = errors.New("two-factor authentication not enabled")
+)
+
+// TwoFactorService is responsible for enrolling Accounts into TOTP two-factor authentication
+// and verifying their second factor.
+type TwoFactorService struct {
+	accRepo     Repository
+	codeRepo    RecoveryCodeRepository
+	sessionRepo SessionRepository
+}
+
+// NewTwoFactorService returns a new TwoFactorService instance.
+func NewTwoFactorService(
+	accRepo Repository,
+	codeRepo RecoveryCodeRepository,
+	sessionRepo SessionRepository,
+) *TwoFactorService {
+	return &TwoFactorService{accRepo: accRepo, codeRepo: codeRepo, sessionRepo: sessionRepo}
+}
+
+// Enroll generates a new TOTPSecret for the Account, returning it along with its provisioning URI.
+//
+// Two-factor authentication is not enabled until a code generated from the secret is verified,
+// so enrolling again replaces the secret of an unfinished enrollment.
+func (s *TwoFactorService) Enroll(ctx context.Context, acc *Account) (TOTPSecret, string, error) {
+	if acc.IsTwoFactorEnabled() {
+		return "", "", ErrTwoFactorAlreadyEnabled
+	}
+
+	secret, err := NewTOTPSecret()
+	if err != nil {
+		return "", "", err
+	}
+
+	acc.TOTPSecret = secret
+	acc.TOTPLastStep = 0
+
+	err = s.accRepo.Update(ctx, acc)
+	if err != nil {
+		return "", "", errors.Wrap(err, "update account")
+	}
+
+	return secret, secret.ProvisioningURI(acc.Email), nil
+}
+
+// Enable two-factor authentication for an enrolled Account, given a code generated from its TOTPSecret.
+//
+// The returned recovery secrets are not stored, and cannot be retrieved again.
+func (s *TwoFactorService) Enable(ctx context.Context, acc *Account, session *Session, code string) ([]RecoverySecret, error) {
+	if acc.IsTwoFactorEnabled() {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+	if acc.TOTPSecret == "" {
+		return nil, ErrTwoFactorNotEnrolled
+	}
+
+	step, ok := acc.TOTPSecret.Validate(code, time.Now(), acc.TOTPLastStep)
+	if !ok {
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	now := time.Now()
+	acc.TOTPLastStep = step
+	acc.TwoFactorEnabledAt = &now
+
+	err := s.accRepo.Update(ctx, acc)
+	if err != nil {
+		return nil, errors.Wrap(err, "update account")
+	}
+
+	secrets, err := s.RegenerateRecoveryCodes(ctx, acc)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.markVerified(ctx, session)
+	if err != nil {
+		return nil, err
+	}
+
+	return secrets, nil
+}
+
+// Verify the second factor of an Account, which is either a TOTP code or an unused recovery secret.
+//
+// Both can only be used once.
+func (s *TwoFactorService) Verify(ctx context.Context, acc *Account, code string) error {
+	if !acc.IsTwoFactorEnabled() {
+		return ErrTwoFactorNotEnabled
+	}
+
+	step, ok := acc.TOTPSecret.Validate(code, time.Now(), acc.TOTPLastStep)
+	if ok {
+		acc.TOTPLastStep = step
+
+		err := s.accRepo.Update(ctx, acc)
+		if err != nil {
+			return errors.Wrap(err, "update account")
+		}
+
+		return nil
+	}
+
+	recoveryCode, err := s.codeRepo.FindUnused(ctx, acc.ID, RecoverySecret(code))
+	if errors.Cause(err) == ErrRecoveryCodeNotFound {
+		return ErrInvalidTwoFactorCode
+	}
+	if err != nil {
+		return errors.Wrap(err, "find recovery code")
+	}
+
+	recoveryCode.Use()
+
+	err = s.codeRepo.Update(ctx, recoveryCode)
+	if err != nil {
+		return errors.Wrap(err, "use recovery code")
+	}
+
+	return nil
+}
+
+// Confirm the second factor within a Session, allowing it to perform sensitive operations
+// for the TwoFactorFreshness.
+func (s *TwoFactorService) Confirm(ctx context.Context, acc *Account, session *Session, code string) error {
+	err := s.Verify(ctx, acc, code)
+	if err != nil {
+		return err
+	}
+
+	return s.markVerified(ctx, session)
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes of an Account, returning the new recovery secrets.
+func (s *TwoFactorService) RegenerateRecoveryCodes(ctx context.Context, acc *Account) ([]RecoverySecret, error) {
+	if !acc.IsTwoFactorEnabled() {
+		return nil, ErrTwoFactorNotEnabled
+	}
+
+	codes, secrets, err := NewRecoveryCodes(acc.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.codeRepo.DeleteByAccount(ctx, acc.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "delete previous recovery codes")
+	}
+
+	for _, code := range codes {
+		err = s.codeRepo.Create(ctx, code)
+		if err != nil {
+			return nil, errors.Wrap(err, "create recovery code")
+		}
+	}
+
+	return secrets, nil
+}
+
+// RecoveryCodesLeft returns the number of recovery codes the Account can still use.
+func (s *TwoFactorService) RecoveryCodesLeft(ctx context.Context, acc *Account) (int, error) {
+	count, err := s.codeRepo.CountUnused(ctx, acc.ID)
+	if err != nil {
+		return 0, errors.Wrap(err, "count unused recovery codes")
+	}
+
+	return count, nil
+}
+
+// Disable two-factor authentication for an Account, given its second factor.
+func (s *TwoFactorService) Disable(ctx context.Context, acc *Account, code string) error {
+	err := s.Verify(ctx, acc, code)
+	if err != nil {
+		return err
+	}
+
+	return s.reset(ctx, acc)
+}
+
+// reset removes the second factor of an Account along with its recovery codes.
+func (s *TwoFactorService) reset(ctx context.Context, acc *Account) error {
+	acc.TOTPSecret = ""
+	acc.TOTPLastStep = 0
+	acc.TwoFactorEnabledAt = nil
+
+	err := s.accRepo.Update(ctx, acc)
+	if err != nil {
+		return errors.Wrap(err, "update account")
+	}
+
+	err = s.codeRepo.DeleteByAccount(ctx, acc.ID)
+	if err != nil {
+		return errors.Wrap(err, "delete recovery codes")
+	}
+
+	return nil
+}
+
+func (s *TwoFactorService) markVerified(ctx context.Context, session *Session) error {
+	session.VerifyTwoFactor()
+
+	err := s.sessionRepo.Update(ctx, session)
+	if err != nil {
+		return errors.Wrap(err, "update session")
+	}
+
+	return nil
+}
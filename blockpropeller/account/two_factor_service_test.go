@@ -0,0 +1,170 @@
+package account_test
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"blockpropeller.dev/blockpropeller/account"
+	"blockpropeller.dev/lib/test"
+	"github.com/pkg/errors"
+)
+
+func TestTOTPCode(t *testing.T) {
+	// Test vectors from RFC 6238, truncated to six digits.
+	secret := account.TOTPSecret("GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ")
+	for unix, expected := range map[int64]string{
+		59:         "287082",
+		1111111109: "081804",
+		1234567890: "005924",
+		2000000000: "279037",
+	} {
+		code, err := account.GenerateTOTPCode(secret, time.Unix(unix, 0))
+		test.CheckErr(t, "generate totp code", err)
+		test.AssertStringsEqual(t, "totp code", code, expected)
+	}
+
+	now := time.Unix(1234567890, 0)
+	step, ok := secret.Validate("005924", now, 0)
+	test.AssertBoolEqual(t, "current code valid", ok, true)
+
+	_, ok = secret.Validate("005924", now, step)
+	test.AssertBoolEqual(t, "reused code valid", ok, false)
+
+	_, ok = secret.Validate("005924", now.Add(30*time.Second), 0)
+	test.AssertBoolEqual(t, "previous period code valid", ok, true)
+
+	_, ok = secret.Validate("005924", now.Add(90*time.Second), 0)
+	test.AssertBoolEqual(t, "stale code valid", ok, false)
+
+	_, ok = secret.Validate("00592", now, 0)
+	test.AssertBoolEqual(t, "short code valid", ok, false)
+
+	uri, err := url.Parse(secret.ProvisioningURI("user@example.com"))
+	test.CheckErr(t, "parse provisioning uri", err)
+	test.AssertStringsEqual(t, "uri scheme", uri.Scheme, "otpauth")
+	test.AssertStringsEqual(t, "uri label", uri.Path, "/BlockPropeller:user@example.com")
+	test.AssertStringsEqual(t, "uri secret", uri.Query().Get("secret"), secret.String())
+	test.AssertStringsEqual(t, "uri issuer", uri.Query().Get("issuer"), account.TOTPIssuer)
+}
+
+type twoFactorFixture struct {
+	accSvc       *account.Service
+	twoFactorSvc *account.TwoFactorService
+}
+
+func newTwoFactorFixture() *twoFactorFixture {
+	accRepo := account.NewInMemoryRepository()
+	sessionRepo := account.NewInMemorySessionRepository()
+	twoFactorSvc := account.NewTwoFactorService(accRepo, account.NewInMemoryRecoveryCodeRepository(), sessionRepo)
+
+	return &twoFactorFixture{
+		accSvc: account.NewService(
+			accRepo,
+			sessionRepo,
+			account.NewTokenService("secret", time.Minute, time.Hour),
+			twoFactorSvc,
+		),
+		twoFactorSvc: twoFactorSvc,
+	}
+}
+
+// enable registers a new Account with two-factor authentication,
+// returning it along with its recovery secrets.
+func (f *twoFactorFixture) enable(t *testing.T) (*account.Account, []account.RecoverySecret) {
+	ctx := context.Background()
+
+	acc, tokens, err := f.accSvc.Register("user@example.com", "password")
+	test.CheckErr(t, "register account", err)
+
+	_, session, err := f.accSvc.Authenticate(tokens.AccessToken)
+	test.CheckErr(t, "authenticate account", err)
+
+	secret, _, err := f.twoFactorSvc.Enroll(ctx, acc)
+	test.CheckErr(t, "enroll account", err)
+
+	_, err = f.twoFactorSvc.Enable(ctx, acc, session, "000000")
+	test.AssertBoolEqual(t, "enable with invalid code", errors.Cause(err) == account.ErrInvalidTwoFactorCode, true)
+
+	code, err := account.GenerateTOTPCode(secret, time.Now())
+	test.CheckErr(t, "generate totp code", err)
+
+	recoverySecrets, err := f.twoFactorSvc.Enable(ctx, acc, session, code)
+	test.CheckErr(t, "enable two-factor authentication", err)
+	test.AssertIntsEqual(t, "recovery secrets", len(recoverySecrets), account.RecoveryCodeCount)
+	test.AssertBoolEqual(t, "two-factor enabled", acc.IsTwoFactorEnabled(), true)
+	test.AssertBoolEqual(t, "enabling session verified", session.HasFreshTwoFactor(), true)
+
+	return acc, recoverySecrets
+}
+
+func TestTwoFactorLogin(t *testing.T) {
+	ctx := context.Background()
+	f := newTwoFactorFixture()
+
+	acc, recoverySecrets := f.enable(t)
+
+	_, _, err := f.twoFactorSvc.Enroll(ctx, acc)
+	test.AssertBoolEqual(t, "enroll enabled account", errors.Cause(err) == account.ErrTwoFactorAlreadyEnabled, true)
+
+	_, err = f.accSvc.Login(acc.Email, "password", "")
+	test.AssertBoolEqual(t, "login without code", errors.Cause(err) == account.ErrTwoFactorRequired, true)
+
+	_, err = f.accSvc.Login(acc.Email, "wrong", "")
+	test.AssertBoolEqual(t, "login with invalid password", errors.Cause(err) == account.ErrInvalidCredentials, true)
+
+	_, err = f.accSvc.Login(acc.Email, "password", "000000")
+	test.AssertBoolEqual(t, "login with invalid code", errors.Cause(err) == account.ErrInvalidTwoFactorCode, true)
+
+	// The current code was already used for enabling two-factor authentication.
+	code, err := account.GenerateTOTPCode(acc.TOTPSecret, time.Now())
+	test.CheckErr(t, "generate totp code", err)
+
+	_, err = f.accSvc.Login(acc.Email, "password", code)
+	test.AssertBoolEqual(t, "login with reused code", errors.Cause(err) == account.ErrInvalidTwoFactorCode, true)
+
+	code, err = account.GenerateTOTPCode(acc.TOTPSecret, time.Now().Add(30*time.Second))
+	test.CheckErr(t, "generate next totp code", err)
+
+	tokens, err := f.accSvc.Login(acc.Email, "password", code)
+	test.CheckErr(t, "login with totp code", err)
+
+	_, session, err := f.accSvc.Authenticate(tokens.AccessToken)
+	test.CheckErr(t, "authenticate session", err)
+	test.AssertBoolEqual(t, "login session verified", session.HasFreshTwoFactor(), true)
+
+	_, err = f.accSvc.Login(acc.Email, "password", recoverySecrets[0].String())
+	test.CheckErr(t, "login with recovery secret", err)
+
+	_, err = f.accSvc.Login(acc.Email, "password", recoverySecrets[0].String())
+	test.AssertBoolEqual(t, "login with used recovery secret", errors.Cause(err) == account.ErrInvalidTwoFactorCode, true)
+
+	left, err := f.twoFactorSvc.RecoveryCodesLeft(ctx, acc)
+	test.CheckErr(t, "count recovery codes", err)
+	test.AssertIntsEqual(t, "recovery codes left", left, account.RecoveryCodeCount-1)
+}
+
+func TestTwoFactorDisable(t *testing.T) {
+	ctx := context.Background()
+	f := newTwoFactorFixture()
+
+	acc, recoverySecrets := f.enable(t)
+
+	err := f.twoFactorSvc.Disable(ctx, acc, "000000")
+	test.AssertBoolEqual(t, "disable with invalid code", errors.Cause(err) == account.ErrInvalidTwoFactorCode, true)
+
+	err = f.twoFactorSvc.Disable(ctx, acc, recoverySecrets[1].String())
+	test.CheckErr(t, "disable with recovery secret", err)
+	test.AssertBoolEqual(t, "two-factor enabled", acc.IsTwoFactorEnabled(), false)
+
+	left, err := f.twoFactorSvc.RecoveryCodesLeft(ctx, acc)
+	test.CheckErr(t, "count recovery codes", err)
+	test.AssertIntsEqual(t, "recovery codes left", left, 0)
+
+	_, err = f.accSvc.Login(acc.Email, "password", "")
+	test.CheckErr(t, "login without code", err)
+
+	err = f.twoFactorSvc.Verify(ctx, acc, recoverySecrets[2].String())
+	test.AssertBoolEqual(t, "verify disabled account", errors.Cause(err) == account.ErrTwoFactorNotEnabled, true)
+}
@@ -2,6 +2,7 @@ package account
 
 import (
 	"strings"
+	"sync"
 
 	"github.com/badoux/checkmail"
 	"github.com/pkg/errors"
@@ -55,6 +56,21 @@ func (p Password) String() string {
 	return string(p)
 }
 
+var (
+	unknownAccountPasswordOnce sync.Once
+	unknownAccountPasswordHash Password
+)
+
+// unknownAccountPassword returns a Password hash to compare against when an Account is not found,
+// so it takes as long as comparing against the Password of an existing Account.
+func unknownAccountPassword() Password {
+	unknownAccountPasswordOnce.Do(func() {
+		unknownAccountPasswordHash, _ = GeneratePassword("unknown account")
+	})
+
+	return unknownAccountPasswordHash
+}
+
 // Email is an Accounts email.
 type Email string
 
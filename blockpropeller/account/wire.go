@@ -7,4 +7,9 @@ import "github.com/google/wire"
 var Set = wire.NewSet(
 	ConfigureTokenService,
 	NewService,
+	NewOrganizationService,
+	NewAPIKeyService,
+	NewCredentialService,
+	NewTwoFactorService,
+	NewSSOService,
 )
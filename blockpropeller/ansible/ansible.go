@@ -1,11 +1,14 @@
 package ansible
 
 import (
+	"context"
 	"io/ioutil"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
 	"blockpropeller.dev/blockpropeller/infrastructure"
 	"blockpropeller.dev/lib/log"
@@ -16,6 +19,8 @@ import (
 var (
 	// ErrServerUnreachable indicates that the server we are trying to access is not reachable.
 	ErrServerUnreachable = errors.New("server unreachable")
+	// ErrTimeout is returned when a playbook does not finish in the configured time.
+	ErrTimeout = errors.New("ansible timed out")
 )
 
 // Ansible is a wrapper around an ansible-playbook command line utility
@@ -25,11 +30,13 @@ type Ansible struct {
 
 	playbooksDir string
 	keysDir      string
+
+	timeout time.Duration
 }
 
 // ConfigureAnsible returns a configured Terraform instance.
 func ConfigureAnsible(cfg *Config) *Ansible {
-	return New(cfg.Path, cfg.PlaybooksDir, cfg.KeysDir)
+	return New(cfg.Path, cfg.PlaybooksDir, cfg.KeysDir).WithTimeout(cfg.Timeout)
 }
 
 // New returns a new Terraform instance.
@@ -41,6 +48,15 @@ func New(path string, playbooksDir string, keysDir string) *Ansible {
 	}
 }
 
+// WithTimeout limits the duration of every playbook run.
+//
+// Playbooks running longer than the timeout are killed. A zero timeout disables the limit.
+func (ans *Ansible) WithTimeout(timeout time.Duration) *Ansible {
+	ans.timeout = timeout
+
+	return ans
+}
+
 // ProvisionServer executes the playbook on a specified Server
 // and applying the provided deployment configuration.
 func (ans *Ansible) ProvisionServer(srv *infrastructure.Server, deployment *infrastructure.Deployment) error {
@@ -54,6 +70,25 @@ func (ans *Ansible) AddAuthorizedKey(srv *infrastructure.Server, pubKey string)
 	})
 }
 
+// DeprovisionServer removes the deployment from a specified Server
+// while leaving the rest of the machine intact.
+func (ans *Ansible) DeprovisionServer(srv *infrastructure.Server, deployment *infrastructure.Deployment) error {
+	return ans.runPlaybook(srv, "deprovision.yaml", deployment.Configuration.MarshalMap())
+}
+
+// GrowVolume grows the filesystem on the data volume of a specified Server
+// to fill the whole volume after it has been resized.
+func (ans *Ansible) GrowVolume(srv *infrastructure.Server) error {
+	return ans.runPlaybook(srv, "grow_volume.yaml", nil)
+}
+
+// RemoveAuthorizedKey revokes access to the server for the provided key.
+func (ans *Ansible) RemoveAuthorizedKey(srv *infrastructure.Server, pubKey string) error {
+	return ans.runPlaybook(srv, "remove_authorized_key.yaml", map[string]string{
+		"revoked_authorized_key": pubKey,
+	})
+}
+
 func (ans *Ansible) runPlaybook(srv *infrastructure.Server, playbook string, vars map[string]string) error {
 	keyPath, err := ans.setupSSHKey(srv.SSHKey)
 	if err != nil {
@@ -65,6 +100,9 @@ func (ans *Ansible) runPlaybook(srv *infrastructure.Server, playbook string, var
 	for key, value := range vars {
 		extraVars = append(extraVars, key+"='"+value+"'")
 	}
+	if srv.SSHPort != 0 {
+		extraVars = append(extraVars, "ansible_port='"+strconv.Itoa(srv.SSHPort)+"'")
+	}
 
 	out, err := ans.exec(
 		ans.playbooksDir,
@@ -105,12 +143,23 @@ func (ans *Ansible) Version() (string, error) {
 
 // exec wraps the interaction with the underlying binary.
 func (ans *Ansible) exec(dir string, args ...string) ([]byte, error) {
-	cmd := exec.Command(ans.path, args...)
+	ctx := context.Background()
+	if ans.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, ans.timeout)
+		defer cancel()
+	}
+
+	cmd := exec.CommandContext(ctx, ans.path, args...)
 	if dir != "" {
 		cmd.Dir = dir
 	}
 
 	output, err := cmd.Output()
+	if ctx.Err() == context.DeadlineExceeded {
+		return output, errors.Wrapf(ErrTimeout, "execution timeout for [ansible-playbook %s] after %s",
+			strings.Join(args, " "), ans.timeout)
+	}
 	if execErr, ok := err.(*exec.ExitError); ok {
 		if execErr.ExitCode() == 4 {
 			return output, ErrServerUnreachable
@@ -1,16 +1,27 @@
 package ansible_test
 
 import (
+	"io/ioutil"
+	"os"
 	"testing"
 
 	"blockpropeller.dev/blockpropeller/ansible"
+	"blockpropeller.dev/blockpropeller/binance"
+	"blockpropeller.dev/blockpropeller/infrastructure"
 	"blockpropeller.dev/lib/test"
+	"blockpropeller.dev/lib/test/fakebin"
 	"github.com/blang/semver"
+	"github.com/pkg/errors"
 )
 
 func TestAnsibleIsExecutable(t *testing.T) {
+	const binPath = "/usr/local/bin/ansible-playbook"
+	if _, err := os.Stat(binPath); os.IsNotExist(err) {
+		t.Skip("ansible is not installed")
+	}
+
 	ans := ansible.New(
-		"/usr/local/bin/ansible-playbook",
+		binPath,
 		"../../playbooks",
 		"/tmp/blockpropeller/ansible/keys",
 	)
@@ -21,3 +32,40 @@ func TestAnsibleIsExecutable(t *testing.T) {
 	_, err = semver.New(version)
 	test.CheckErr(t, "invalid ansible version format", err)
 }
+
+func TestAnsibleServerUnreachable(t *testing.T) {
+	bin := fakebin.New(t, "ansible-playbook")
+	defer test.Close(t, bin)
+
+	bin.On("site.yaml").Stderr("UNREACHABLE!").Exit(4)
+	bin.On("add_authorized_key.yaml").Stderr("FAILED!").Exit(2)
+
+	keysDir, err := ioutil.TempDir(os.TempDir(), "ansible-keys-")
+	test.CheckErr(t, "create keys dir", err)
+	defer os.RemoveAll(keysDir)
+
+	ans := ansible.New(bin.Path(), "", keysDir)
+
+	sshKey, err := infrastructure.GenerateNewSSHKey("test")
+	test.CheckErr(t, "generate ssh key", err)
+
+	srv := infrastructure.NewServer("", "test", infrastructure.ProviderDigitalOcean, infrastructure.ServerSizeTest, sshKey)
+	srv.IPAddress = "203.0.113.10"
+
+	deployment := binance.NewNodeDeployment(binance.NetworkTest, binance.TypeLightNode, semver.MustParse("0.6.1"))
+
+	err = ans.ProvisionServer(srv, deployment)
+	if errors.Cause(err) != ansible.ErrServerUnreachable {
+		t.Fatalf("ProvisionServer(): got %v, want unreachable", err)
+	}
+
+	err = ans.AddAuthorizedKey(srv, "ssh-rsa example")
+	test.CheckErrExists(t, "AddAuthorizedKey()", err)
+	if errors.Cause(err) == ansible.ErrServerUnreachable {
+		t.Fatalf("AddAuthorizedKey(): got unreachable, want execution error")
+	}
+
+	keys, err := ioutil.ReadDir(keysDir)
+	test.CheckErr(t, "read keys dir", err)
+	test.AssertIntsEqual(t, "leftover keys", len(keys), 0)
+}
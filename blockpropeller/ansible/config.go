@@ -1,6 +1,10 @@
 package ansible
 
-import "github.com/pkg/errors"
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
 
 // Config object for working with Ansible.
 type Config struct {
@@ -8,6 +12,9 @@ type Config struct {
 
 	PlaybooksDir string `yaml:"playbooks_dir"`
 	KeysDir      string `yaml:"keys_dir"`
+
+	// Timeout limits the duration of a single playbook run.
+	Timeout time.Duration `yaml:"timeout"`
 }
 
 // Validate conforms to the config.Config interface.
@@ -24,5 +31,9 @@ func (cfg *Config) Validate() error {
 		cfg.KeysDir = "/blockpropeller/ansible/keys"
 	}
 
+	if cfg.Timeout == 0 {
+		cfg.Timeout = 30 * time.Minute
+	}
+
 	return nil
 }
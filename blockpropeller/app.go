@@ -4,11 +4,14 @@ import (
 	"context"
 
 	"blockpropeller.dev/blockpropeller/account"
+	"blockpropeller.dev/blockpropeller/audit"
 	"blockpropeller.dev/blockpropeller/encryption"
 	"blockpropeller.dev/blockpropeller/infrastructure"
 	"blockpropeller.dev/blockpropeller/provision"
+	"blockpropeller.dev/blockpropeller/terraform/cloudprovider/local"
 	"blockpropeller.dev/lib/log"
 	"blockpropeller.dev/lib/server"
+	"github.com/pkg/errors"
 )
 
 // App is a container that holds all necessary dependencies
@@ -20,7 +23,13 @@ type App struct {
 
 	AccountRepository account.Repository
 	AccountService    *account.Service
+	CredentialService *account.CredentialService
+	TwoFactorService  *account.TwoFactorService
 
+	OrganizationService *account.OrganizationService
+	AuditService        *audit.Service
+
+	ProjectRepository          infrastructure.ProjectRepository
 	ProviderSettingsRepository infrastructure.ProviderSettingsRepository
 	ServerRepository           infrastructure.ServerRepository
 	JobRepository              provision.JobRepository
@@ -28,6 +37,8 @@ type App struct {
 	JobScheduler *provision.JobScheduler
 	Provisioner  *provision.Provisioner
 
+	EncryptionRotator encryption.Rotator
+
 	Logger log.Logger
 }
 
@@ -36,47 +47,87 @@ func NewApp(
 	config *Config,
 	accRepo account.Repository,
 	accSvc *account.Service,
+	credSvc *account.CredentialService,
+	twoFactorSvc *account.TwoFactorService,
+	orgSvc *account.OrganizationService,
+	auditSvc *audit.Service,
+	projectRepo infrastructure.ProjectRepository,
 	providerSettingsRepo infrastructure.ProviderSettingsRepository,
 	serverRepo infrastructure.ServerRepository,
 	jobRepo provision.JobRepository,
 	jobScheduler *provision.JobScheduler,
 	provisioner *provision.Provisioner,
+	encryptionRotator encryption.Rotator,
 	logger log.Logger,
 ) *App {
 	return &App{
 		Config:                     config,
 		AccountRepository:          accRepo,
 		AccountService:             accSvc,
+		CredentialService:          credSvc,
+		TwoFactorService:           twoFactorSvc,
+		OrganizationService:        orgSvc,
+		AuditService:               auditSvc,
+		ProjectRepository:          projectRepo,
 		ProviderSettingsRepository: providerSettingsRepo,
 		ServerRepository:           serverRepo,
 		JobRepository:              jobRepo,
 		JobScheduler:               jobScheduler,
 		Provisioner:                provisioner,
+		EncryptionRotator:          encryptionRotator,
 		Logger:                     logger,
 	}
 }
 
 // InitGlobal configures the global dependencies of the App.
-func (app *App) InitGlobal() {
+func (app *App) InitGlobal() error {
 	log.SetGlobal(app.Logger)
-	encryption.Init(app.Config.Encryption.Secret)
+
+	err := encryption.Configure(app.Config.Encryption)
+	if err != nil {
+		return errors.Wrap(err, "configure encryption")
+	}
+
+	if app.Config.Local != nil && app.Config.Local.DockerHost != "" {
+		local.Register(app.Config.Local.DockerHost)
+	}
+
+	return nil
 }
 
-// AppServer is a wrapper around an App that also serves traffic and processes provisioning jobs through a worker pool.
+// AppServer is a wrapper around an App that also serves traffic, processes provisioning jobs
+// through a worker pool, monitors the health of provisioned servers and takes volume snapshots.
 type AppServer struct {
-	App        *App
-	srv        *server.Server
-	workerPool *provision.WorkerPool
+	App               *App
+	srv               *server.Server
+	workerPool        *provision.WorkerPool
+	healthMonitor     *provision.HealthMonitor
+	snapshotScheduler *provision.SnapshotScheduler
 }
 
 // NewAppServer returns a new AppServer instance.
-func NewAppServer(app *App, srv *server.Server, workerPool *provision.WorkerPool) *AppServer {
-	return &AppServer{App: app, srv: srv, workerPool: workerPool}
+func NewAppServer(
+	app *App,
+	srv *server.Server,
+	workerPool *provision.WorkerPool,
+	healthMonitor *provision.HealthMonitor,
+	snapshotScheduler *provision.SnapshotScheduler,
+) *AppServer {
+	return &AppServer{
+		App:               app,
+		srv:               srv,
+		workerPool:        workerPool,
+		healthMonitor:     healthMonitor,
+		snapshotScheduler: snapshotScheduler,
+	}
 }
 
-// Start runs the worker pool in the background and a HTTP server in the foreground.
+// Start runs the worker pool, health monitor and snapshot scheduler in the background
+// and a HTTP server in the foreground.
 func (app *AppServer) Start(ctx context.Context) error {
 	go app.workerPool.Start(ctx)
+	go app.healthMonitor.Start(ctx)
+	go app.snapshotScheduler.Start(ctx)
 
 	return app.srv.Start()
 }
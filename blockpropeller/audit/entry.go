@@ -0,0 +1,218 @@
+// Package audit records the security relevant actions taken by Accounts,
+// whether through the API or the CLI.
+package audit
+
+import (
+	"strings"
+	"time"
+
+	"blockpropeller.dev/blockpropeller/account"
+	uuid "github.com/satori/go.uuid"
+)
+
+// EntryID is a unique audit entry identifier.
+type EntryID string
+
+// NewEntryID returns a new unique EntryID.
+func NewEntryID() EntryID {
+	return EntryID(uuid.NewV4().String())
+}
+
+// String satisfies the Stringer interface.
+func (id EntryID) String() string {
+	return string(id)
+}
+
+// Action is a security relevant action, in the form of `<target type>.<verb>`.
+type Action string
+
+// Actions recorded in the audit log.
+const (
+	ActionAccountChangePassword Action = "account.change_password"
+
+	// Authentication actions target the email the attempt was made for, rather than the ID of an Account.
+	ActionAccountLogin                Action = "account.login"
+	ActionAccountSSOLogin             Action = "account.sso_login"
+	ActionAccountRefreshSession       Action = "account.refresh_session"
+	ActionAccountRequestPasswordReset Action = "account.request_password_reset"
+	ActionAccountResetPassword        Action = "account.reset_password"
+
+	ActionAPIKeyCreate Action = "api_key.create"
+	ActionAPIKeyRevoke Action = "api_key.revoke"
+
+	ActionTwoFactorEnable             Action = "two_factor.enable"
+	ActionTwoFactorDisable            Action = "two_factor.disable"
+	ActionTwoFactorRegenerateRecovery Action = "two_factor.regenerate_recovery_codes"
+
+	ActionOrganizationUpdate Action = "organization.update"
+	ActionMemberUpdate       Action = "member.update"
+	ActionMemberRemove       Action = "member.remove"
+	ActionInvitationCreate   Action = "invitation.create"
+	ActionInvitationDelete   Action = "invitation.delete"
+
+	ActionProviderSettingsCreate   Action = "provider_settings.create"
+	ActionProviderSettingsUpdate   Action = "provider_settings.update"
+	ActionProviderSettingsDelete   Action = "provider_settings.delete"
+	ActionProviderSettingsValidate Action = "provider_settings.validate"
+
+	ActionServerDelete               Action = "server.delete"
+	ActionServerDumpKey              Action = "server.dump_key"
+	ActionServerAddAuthorizedKey     Action = "server.add_authorized_key"
+	ActionServerUpdateFirewall       Action = "server.update_firewall"
+	ActionServerUpdateLabels         Action = "server.update_labels"
+	ActionServerUpdateDiskThresholds Action = "server.update_disk_thresholds"
+	ActionServerUpdateSnapshotPolicy Action = "server.update_snapshot_policy"
+	ActionServerResizeVolume         Action = "server.resize_volume"
+
+	ActionDeploymentUpdateLabels Action = "deployment.update_labels"
+
+	ActionReservedIPCreate Action = "reserved_ip.create"
+	ActionReservedIPDelete Action = "reserved_ip.delete"
+
+	ActionVolumeSnapshotCreate Action = "volume_snapshot.create"
+	ActionVolumeSnapshotDelete Action = "volume_snapshot.delete"
+
+	ActionEncryptionRotate Action = "encryption.rotate"
+)
+
+// TargetType returns the type of resources the Action targets.
+func (a Action) TargetType() string {
+	return strings.SplitN(string(a), ".", 2)[0]
+}
+
+// String satisfies the Stringer interface.
+func (a Action) String() string {
+	return string(a)
+}
+
+// Source is the entry point an action was taken through.
+type Source string
+
+// Sources of audited actions.
+const (
+	SourceAPI Source = "api"
+	SourceCLI Source = "cli"
+)
+
+// Outcome of an audited action.
+type Outcome string
+
+// Outcomes of audited actions.
+const (
+	// OutcomeSuccess is recorded for actions that were taken.
+	OutcomeSuccess Outcome = "success"
+	// OutcomeDenied is recorded for actions the actor was not allowed to take.
+	OutcomeDenied Outcome = "denied"
+	// OutcomeFailure is recorded for actions that were allowed, but failed.
+	OutcomeFailure Outcome = "failure"
+)
+
+// Entry records who took an action on which resource, from where, and whether it succeeded.
+//
+// Entries are scoped to the Organization owning the target resource,
+// while actions on the Account itself are scoped to its personal Organization.
+type Entry struct {
+	ID             EntryID                `json:"id" gorm:"type:varchar(36) not null"`
+	OrganizationID account.OrganizationID `json:"organization_id" gorm:"type:varchar(36);index"`
+
+	// ActorID is the Account which took the action, with its email as it was at the time.
+	// Both are empty for actions taken without authenticating, such as failed logins.
+	ActorID    account.ID       `json:"actor_id" gorm:"type:varchar(36) not null;index"`
+	ActorEmail account.Email    `json:"actor_email" gorm:"type:varchar(255) not null"`
+	APIKeyID   account.APIKeyID `json:"api_key_id,omitempty" gorm:"type:varchar(36)"`
+
+	Action     Action `json:"action" gorm:"type:varchar(64) not null;index"`
+	TargetType string `json:"target_type" gorm:"type:varchar(64) not null"`
+	TargetID   string `json:"target_id,omitempty" gorm:"type:varchar(255);index"`
+
+	Source    Source `json:"source" gorm:"type:varchar(10) not null"`
+	IP        string `json:"ip,omitempty" gorm:"type:varchar(45)"`
+	UserAgent string `json:"user_agent,omitempty" gorm:"type:varchar(255)"`
+
+	Outcome Outcome `json:"outcome" gorm:"type:varchar(10) not null"`
+	Error   string  `json:"error,omitempty" gorm:"type:text"`
+
+	CreatedAt time.Time `json:"created_at" gorm:"type:timestamp not null;default:CURRENT_TIMESTAMP;index"`
+}
+
+// NewEntry returns a new Entry of an action the Account took on the target resource.
+//
+// The actor is nil for actions taken by clients which are not authenticated, such as failed logins.
+// Entries start out as successful, until they are finished with an error.
+func NewEntry(source Source, action Action, targetID string, actor *account.Account) *Entry {
+	entry := &Entry{
+		ID: NewEntryID(),
+
+		Action:     action,
+		TargetType: action.TargetType(),
+		TargetID:   targetID,
+
+		Source:  source,
+		Outcome: OutcomeSuccess,
+
+		CreatedAt: time.Now(),
+	}
+	if actor != nil {
+		entry.ActorID = actor.ID
+		entry.ActorEmail = actor.Email
+	}
+
+	return entry
+}
+
+// TableName keeps the audit entries apart from other tables.
+func (Entry) TableName() string {
+	return "audit_entries"
+}
+
+// Fail marks the action as failed with the provided error.
+func (e *Entry) Fail(err error) {
+	e.Outcome = OutcomeFailure
+	e.Error = err.Error()
+}
+
+// Deny marks the action as denied with the provided error.
+func (e *Entry) Deny(err error) {
+	e.Outcome = OutcomeDenied
+	e.Error = err.Error()
+}
+
+// Filter narrows down the audit entries to list. Zero values match all entries.
+type Filter struct {
+	OrganizationID account.OrganizationID
+	ActorID        account.ID
+	Action         Action
+	TargetType     string
+	TargetID       string
+	Outcome        Outcome
+
+	Since time.Time
+	Until time.Time
+
+	// Limit the number of entries returned, from the most recent ones.
+	Limit int
+}
+
+// Matches checks whether the Entry matches the Filter, disregarding its Limit.
+func (f Filter) Matches(e *Entry) bool {
+	switch {
+	case f.OrganizationID != account.NilOrganizationID && e.OrganizationID != f.OrganizationID:
+		return false
+	case f.ActorID != "" && e.ActorID != f.ActorID:
+		return false
+	case f.Action != "" && e.Action != f.Action:
+		return false
+	case f.TargetType != "" && e.TargetType != f.TargetType:
+		return false
+	case f.TargetID != "" && e.TargetID != f.TargetID:
+		return false
+	case f.Outcome != "" && e.Outcome != f.Outcome:
+		return false
+	case !f.Since.IsZero() && e.CreatedAt.Before(f.Since):
+		return false
+	case !f.Until.IsZero() && !e.CreatedAt.Before(f.Until):
+		return false
+	}
+
+	return true
+}
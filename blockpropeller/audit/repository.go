@@ -0,0 +1,64 @@
+package audit
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+// Repository defines an interface for storing and retrieving audit entries.
+//
+// Entries are only ever appended, never updated nor deleted.
+type Repository interface {
+	// List the entries matching the Filter, from the most recent ones.
+	List(ctx context.Context, filter Filter) ([]*Entry, error)
+
+	// Create a new Entry.
+	Create(ctx context.Context, entry *Entry) error
+}
+
+// InMemoryRepository holds the audit entries inside an in-memory slice.
+//
+// Entries are not persisted on disk and won't survive program restarts.
+type InMemoryRepository struct {
+	mu      sync.RWMutex
+	entries []*Entry
+}
+
+// NewInMemoryRepository returns a new InMemoryRepository instance.
+func NewInMemoryRepository() *InMemoryRepository {
+	return &InMemoryRepository{}
+}
+
+// List the entries matching the Filter, from the most recent ones.
+func (repo *InMemoryRepository) List(ctx context.Context, filter Filter) ([]*Entry, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	var entries []*Entry
+	for _, entry := range repo.entries {
+		if filter.Matches(entry) {
+			entries = append(entries, entry)
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].CreatedAt.After(entries[j].CreatedAt)
+	})
+
+	if filter.Limit > 0 && len(entries) > filter.Limit {
+		entries = entries[:filter.Limit]
+	}
+
+	return entries, nil
+}
+
+// Create a new Entry.
+func (repo *InMemoryRepository) Create(ctx context.Context, entry *Entry) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	repo.entries = append(repo.entries, entry)
+
+	return nil
+}
@@ -0,0 +1,58 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+
+	"blockpropeller.dev/lib/log"
+	"github.com/pkg/errors"
+)
+
+// Service is responsible for recording and querying the audit log.
+type Service struct {
+	repo Repository
+}
+
+// NewService returns a new Service instance.
+func NewService(repo Repository) *Service {
+	return &Service{repo: repo}
+}
+
+// Record an Entry in the audit log.
+//
+// Failing to record an Entry does not fail the audited action, so the Entry is logged instead.
+func (s *Service) Record(ctx context.Context, entry *Entry) {
+	err := s.repo.Create(ctx, entry)
+	if err != nil {
+		log.ErrorErr(err, "failed recording audit entry", log.Fields{
+			"action":    entry.Action,
+			"actor_id":  entry.ActorID,
+			"target_id": entry.TargetID,
+			"outcome":   entry.Outcome,
+		})
+	}
+}
+
+// List the entries matching the Filter, from the most recent ones.
+func (s *Service) List(ctx context.Context, filter Filter) ([]*Entry, error) {
+	entries, err := s.repo.List(ctx, filter)
+	if err != nil {
+		return nil, errors.Wrap(err, "list audit entries")
+	}
+
+	return entries, nil
+}
+
+// WriteJSONLines writes the entries as JSON lines, one Entry per line.
+func WriteJSONLines(w io.Writer, entries []*Entry) error {
+	enc := json.NewEncoder(w)
+	for _, entry := range entries {
+		err := enc.Encode(entry)
+		if err != nil {
+			return errors.Wrap(err, "encode audit entry")
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,104 @@
+package audit_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"blockpropeller.dev/blockpropeller/account"
+	"blockpropeller.dev/blockpropeller/audit"
+	"blockpropeller.dev/lib/test"
+	"github.com/pkg/errors"
+)
+
+func TestAuditEntry(t *testing.T) {
+	acc := account.NewAccount("user@example.com", "")
+
+	entry := audit.NewEntry(audit.SourceAPI, audit.ActionServerDumpKey, "server-1", acc)
+	test.AssertStringsEqual(t, "actor", entry.ActorID.String(), acc.ID.String())
+	test.AssertStringsEqual(t, "actor email", entry.ActorEmail.String(), "user@example.com")
+	test.AssertStringsEqual(t, "target type", entry.TargetType, "server")
+	test.AssertStringsEqual(t, "outcome", string(entry.Outcome), string(audit.OutcomeSuccess))
+
+	entry.Deny(errors.New("two-factor required"))
+	test.AssertStringsEqual(t, "denied outcome", string(entry.Outcome), string(audit.OutcomeDenied))
+	test.AssertStringsEqual(t, "denied error", entry.Error, "two-factor required")
+
+	entry.Fail(errors.New("disk full"))
+	test.AssertStringsEqual(t, "failed outcome", string(entry.Outcome), string(audit.OutcomeFailure))
+}
+
+func TestAuditList(t *testing.T) {
+	svc := audit.NewService(audit.NewInMemoryRepository())
+	ctx := context.Background()
+
+	alice := account.NewAccount("alice@example.com", "")
+	bob := account.NewAccount("bob@example.com", "")
+	orgID := account.NewOrganizationID()
+	start := time.Now()
+
+	record := func(actor *account.Account, orgID account.OrganizationID, action audit.Action, targetID string, at time.Duration) *audit.Entry {
+		entry := audit.NewEntry(audit.SourceAPI, action, targetID, actor)
+		entry.OrganizationID = orgID
+		entry.CreatedAt = start.Add(at)
+
+		svc.Record(ctx, entry)
+
+		return entry
+	}
+
+	first := record(alice, orgID, audit.ActionProviderSettingsCreate, "settings-1", time.Second)
+	second := record(bob, orgID, audit.ActionServerDelete, "server-1", 2*time.Second)
+	third := record(alice, orgID, audit.ActionServerDelete, "server-2", 3*time.Second)
+	record(alice, account.NewOrganizationID(), audit.ActionServerDelete, "server-3", 4*time.Second)
+
+	assertEntries := func(name string, filter audit.Filter, expected ...*audit.Entry) {
+		entries, err := svc.List(ctx, filter)
+		test.CheckErr(t, name, err)
+		test.AssertIntsEqual(t, name+" count", len(entries), len(expected))
+
+		for i := range expected {
+			test.AssertStringsEqual(t, name+" entry", entries[i].ID.String(), expected[i].ID.String())
+		}
+	}
+
+	assertEntries("organization entries", audit.Filter{OrganizationID: orgID}, third, second, first)
+	assertEntries("limited entries", audit.Filter{OrganizationID: orgID, Limit: 2}, third, second)
+	assertEntries("actor entries", audit.Filter{OrganizationID: orgID, ActorID: alice.ID}, third, first)
+	assertEntries("action entries", audit.Filter{OrganizationID: orgID, Action: audit.ActionServerDelete}, third, second)
+	assertEntries("target entries", audit.Filter{OrganizationID: orgID, TargetType: "server", TargetID: "server-1"}, second)
+	assertEntries("denied entries", audit.Filter{OrganizationID: orgID, Outcome: audit.OutcomeDenied})
+	assertEntries("time range entries", audit.Filter{
+		OrganizationID: orgID,
+		Since:          start.Add(2 * time.Second),
+		Until:          start.Add(3 * time.Second),
+	}, second)
+}
+
+func TestAuditWriteJSONLines(t *testing.T) {
+	acc := account.NewAccount("user@example.com", "")
+	entries := []*audit.Entry{
+		audit.NewEntry(audit.SourceCLI, audit.ActionServerDumpKey, "server-1", acc),
+		audit.NewEntry(audit.SourceAPI, audit.ActionServerDelete, "server-1", acc),
+	}
+
+	var buf bytes.Buffer
+	err := audit.WriteJSONLines(&buf, entries)
+	test.CheckErr(t, "write json lines", err)
+
+	scanner := bufio.NewScanner(&buf)
+	var lines int
+	for scanner.Scan() {
+		var entry audit.Entry
+		err = json.Unmarshal(scanner.Bytes(), &entry)
+		test.CheckErr(t, "decode json line", err)
+		test.AssertStringsEqual(t, "entry action", entry.Action.String(), entries[lines].Action.String())
+		test.AssertStringsEqual(t, "entry source", string(entry.Source), string(entries[lines].Source))
+
+		lines++
+	}
+	test.AssertIntsEqual(t, "json lines", lines, len(entries))
+}
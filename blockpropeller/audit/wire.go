@@ -0,0 +1,9 @@
+package audit
+
+import "github.com/google/wire"
+
+// Set is the Wire provider set for the audit package
+// that does not depend on any underlying dependencies.
+var Set = wire.NewSet(
+	NewService,
+)
@@ -12,6 +12,10 @@ var (
 	configNetwork  = "binance_node_network"
 	configNodeType = "binance_node_type"
 	configVersion  = "binance_node_version"
+
+	rpcPort     = infrastructure.Port{Number: 27147, Protocol: infrastructure.ProtocolTCP}
+	p2pPort     = infrastructure.Port{Number: 27146, Protocol: infrastructure.ProtocolTCP}
+	metricsPort = infrastructure.Port{Number: 28660, Protocol: infrastructure.ProtocolTCP}
 )
 
 func init() {
@@ -48,11 +52,29 @@ func (nodeDeploymentSpec) UnmarshalConfig(raw map[string]string) (infrastructure
 
 // HealthCheck returns a HealthCheck to be used to determine Deployment health.
 func (nodeDeploymentSpec) HealthCheck(srv *infrastructure.Server, deployment *infrastructure.Deployment) (infrastructure.HealthCheck, error) {
-	url := fmt.Sprintf("http://%s:27147/status", srv.IPAddress)
+	url := fmt.Sprintf("http://%s:%d/status", srv.IPAddress, rpcPort.Number)
 
 	return infrastructure.NewHTTPHealthCheck("GET", url, 200), nil
 }
 
+// Ports returns the ports the Binance Chain Node needs to be reachable on.
+//
+// Full nodes accept peer connections publicly, while the RPC and metrics
+// endpoints are only exposed to the Server allow-list.
+func (nodeDeploymentSpec) Ports(deployment *infrastructure.Deployment) infrastructure.DeploymentPorts {
+	ports := infrastructure.DeploymentPorts{
+		Restricted: []infrastructure.Port{rpcPort},
+	}
+
+	cfg, ok := deployment.Configuration.(*NodeConfig)
+	if ok && cfg.NodeType == TypeFullNode {
+		ports.Public = append(ports.Public, p2pPort)
+		ports.Restricted = append(ports.Restricted, metricsPort)
+	}
+
+	return ports
+}
+
 // NodeConfig holds the configuration for the Binance Chain Node.
 type NodeConfig struct {
 	Network  Network        `json:"node_network"`
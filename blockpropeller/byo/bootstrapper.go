@@ -0,0 +1,94 @@
+package byo
+
+import (
+	"bytes"
+	"strings"
+	"time"
+
+	"blockpropeller.dev/blockpropeller/infrastructure"
+	"blockpropeller.dev/lib/log"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/ssh"
+)
+
+const dialTimeout = 30 * time.Second
+
+// Bootstrapper prepares an existing host for provisioning by installing
+// the BlockPropeller generated SSHKey as an authorized key for root.
+//
+// Once bootstrapped, the host is managed through Ansible in the same way
+// as the servers created through cloud providers.
+type Bootstrapper struct {
+	dialTimeout time.Duration
+}
+
+// NewBootstrapper returns a new Bootstrapper instance.
+func NewBootstrapper() *Bootstrapper {
+	return &Bootstrapper{dialTimeout: dialTimeout}
+}
+
+// Bootstrap connects to the host described by Credentials and authorizes the provided SSHKeys.
+//
+// Users other than root need passwordless sudo when authenticating with a private key,
+// while the bootstrap password is passed on to sudo otherwise.
+func (b *Bootstrapper) Bootstrap(creds *Credentials, sshKeys ...*infrastructure.SSHKey) error {
+	auth, err := creds.authMethods()
+	if err != nil {
+		return errors.Wrap(err, "prepare ssh auth")
+	}
+
+	client, err := ssh.Dial("tcp", creds.Address(), &ssh.ClientConfig{
+		User: creds.User,
+		Auth: auth,
+		// Host keys are not verified, same as with Ansible provisioning.
+		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+		Timeout:         b.dialTimeout,
+	})
+	if err != nil {
+		return errors.Wrap(err, "connect to host")
+	}
+	defer log.Closer(client)
+
+	session, err := client.NewSession()
+	if err != nil {
+		return errors.Wrap(err, "open ssh session")
+	}
+
+	cmds := make([]string, 0, len(sshKeys))
+	for _, sshKey := range sshKeys {
+		cmds = append(cmds, AuthorizeKeyCommand(sshKey.EncodedPublicKey()))
+	}
+
+	cmd := strings.Join(cmds, " && ")
+	if creds.User != defaultUser {
+		if creds.PrivateKey != "" {
+			cmd = "sudo -n sh -c " + shellQuote(cmd)
+		} else {
+			cmd = "sudo -S -p '' sh -c " + shellQuote(cmd)
+			session.Stdin = strings.NewReader(creds.Password + "\n")
+		}
+	}
+
+	var stderr bytes.Buffer
+	session.Stderr = &stderr
+
+	err = session.Run(cmd)
+	if err != nil {
+		return errors.Wrapf(err, "authorize ssh key: %s", strings.TrimSpace(stderr.String()))
+	}
+
+	return nil
+}
+
+// AuthorizeKeyCommand returns an idempotent shell command adding
+// the public key to the authorized keys of the root user.
+func AuthorizeKeyCommand(pubKey string) string {
+	key := shellQuote(strings.TrimSpace(pubKey))
+
+	return "umask 077 && mkdir -p /root/.ssh && touch /root/.ssh/authorized_keys && " +
+		"(grep -qxF " + key + " /root/.ssh/authorized_keys || echo " + key + " >> /root/.ssh/authorized_keys)"
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.Replace(s, "'", `'"'"'`, -1) + "'"
+}
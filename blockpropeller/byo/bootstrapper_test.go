@@ -0,0 +1,159 @@
+package byo_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/binary"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+
+	"blockpropeller.dev/blockpropeller/byo"
+	"blockpropeller.dev/blockpropeller/infrastructure"
+	"blockpropeller.dev/lib/test"
+	"golang.org/x/crypto/ssh"
+)
+
+func TestBootstrapper_Bootstrap(t *testing.T) {
+	tests := []struct {
+		name    string
+		user    string
+		wantCmd string
+		wantIn  string
+	}{
+		{"root user", "root", "umask 077", ""},
+		{"sudo user", "ubuntu", "sudo -S -p '' sh -c ", "secret\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newSSHServer(t, tt.user, "secret")
+			defer test.Close(t, srv.listener)
+
+			sshKey, err := infrastructure.GenerateNewSSHKey("test")
+			test.CheckErr(t, "generate ssh key", err)
+
+			creds := &byo.Credentials{Host: "127.0.0.1", Port: srv.port(), User: tt.user, Password: "secret"}
+
+			err = byo.NewBootstrapper().Bootstrap(creds, sshKey)
+			test.CheckErr(t, "Bootstrap()", err)
+
+			exec := <-srv.execs
+			if !strings.HasPrefix(exec.cmd, tt.wantCmd) {
+				t.Errorf("Bootstrap() command = %s, want prefix %s", exec.cmd, tt.wantCmd)
+			}
+			if !strings.Contains(exec.cmd, strings.TrimSpace(sshKey.EncodedPublicKey())) {
+				t.Errorf("Bootstrap() command = %s, missing public key", exec.cmd)
+			}
+			test.AssertStringsEqual(t, "Bootstrap() stdin", exec.stdin, tt.wantIn)
+		})
+	}
+}
+
+func TestBootstrapper_BootstrapWrongPassword(t *testing.T) {
+	srv := newSSHServer(t, "root", "secret")
+	defer test.Close(t, srv.listener)
+
+	sshKey, err := infrastructure.GenerateNewSSHKey("test")
+	test.CheckErr(t, "generate ssh key", err)
+
+	creds := &byo.Credentials{Host: "127.0.0.1", Port: srv.port(), User: "root", Password: "wrong"}
+
+	err = byo.NewBootstrapper().Bootstrap(creds, sshKey)
+	test.CheckErrExists(t, "Bootstrap()", err)
+}
+
+type sshExec struct {
+	cmd   string
+	stdin string
+}
+
+// sshServer is a minimal SSH server recording executed commands.
+type sshServer struct {
+	listener net.Listener
+	execs    chan sshExec
+}
+
+func newSSHServer(t *testing.T, user string, password string) *sshServer {
+	hostKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	test.CheckErr(t, "generate host key", err)
+
+	signer, err := ssh.NewSignerFromKey(hostKey)
+	test.CheckErr(t, "create host key signer", err)
+
+	cfg := &ssh.ServerConfig{
+		PasswordCallback: func(conn ssh.ConnMetadata, pass []byte) (*ssh.Permissions, error) {
+			if conn.User() != user || string(pass) != password {
+				return nil, ssh.ErrNoAuth
+			}
+
+			return nil, nil
+		},
+	}
+	cfg.AddHostKey(signer)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	test.CheckErr(t, "listen", err)
+
+	srv := &sshServer{listener: listener, execs: make(chan sshExec, 1)}
+	go srv.serve(cfg)
+
+	return srv
+}
+
+func (srv *sshServer) port() int {
+	port, _ := strconv.Atoi(strings.Split(srv.listener.Addr().String(), ":")[1])
+
+	return port
+}
+
+func (srv *sshServer) serve(cfg *ssh.ServerConfig) {
+	for {
+		conn, err := srv.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		_, chans, reqs, err := ssh.NewServerConn(conn, cfg)
+		if err != nil {
+			continue
+		}
+		go ssh.DiscardRequests(reqs)
+
+		for newChan := range chans {
+			ch, chReqs, err := newChan.Accept()
+			if err != nil {
+				continue
+			}
+
+			go srv.handleSession(ch, chReqs)
+		}
+	}
+}
+
+func (srv *sshServer) handleSession(ch ssh.Channel, reqs <-chan *ssh.Request) {
+	for req := range reqs {
+		if req.Type != "exec" {
+			_ = req.Reply(false, nil)
+			continue
+		}
+		_ = req.Reply(true, nil)
+
+		cmdLen := binary.BigEndian.Uint32(req.Payload[:4])
+		cmd := string(req.Payload[4 : 4+cmdLen])
+
+		var stdin string
+		if strings.HasPrefix(cmd, "sudo -S") {
+			buf := make([]byte, 64)
+			n, _ := ch.Read(buf)
+			stdin = string(buf[:n])
+		}
+
+		_, _ = ch.SendRequest("exit-status", false, []byte{0, 0, 0, 0})
+		_ = ch.Close()
+
+		srv.execs <- sshExec{cmd: cmd, stdin: stdin}
+
+		return
+	}
+}
@@ -0,0 +1,123 @@
+package byo
+
+import (
+	"encoding/json"
+	"net"
+	"strconv"
+
+	"blockpropeller.dev/blockpropeller/infrastructure"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	defaultPort = 22
+	defaultUser = "root"
+)
+
+// Credentials hold the access information for an existing host
+// registered by the user.
+//
+// Either a PrivateKey or a one-time bootstrap Password must be provided.
+// The Password is only used for installing the BlockPropeller SSHKey,
+// after which it is replaced by a management key authorized for root.
+//
+// Managed marks a PrivateKey generated by BlockPropeller as the management key,
+// which is revoked when the host is released. Keys provided by the user are left authorized.
+type Credentials struct {
+	Host       string `json:"host"`
+	Port       int    `json:"port,omitempty"`
+	User       string `json:"user,omitempty"`
+	PrivateKey string `json:"private_key,omitempty"`
+	Password   string `json:"password,omitempty"`
+	Managed    bool   `json:"managed,omitempty"`
+}
+
+// ParseCredentials extracts the host access information from ProviderSettings.
+func ParseCredentials(settings *infrastructure.ProviderSettings) (*Credentials, error) {
+	var creds Credentials
+	err := json.Unmarshal([]byte(settings.Credentials), &creds)
+	if err != nil {
+		return nil, errors.Wrap(err, "unmarshal host credentials")
+	}
+
+	if creds.Host == "" {
+		return nil, errors.New("missing host")
+	}
+	if creds.Port == 0 {
+		creds.Port = defaultPort
+	}
+	if creds.Port < 0 || creds.Port > 65535 {
+		return nil, errors.Errorf("invalid port: %d", creds.Port)
+	}
+	if creds.User == "" {
+		creds.User = defaultUser
+	}
+	if creds.PrivateKey == "" && creds.Password == "" {
+		return nil, errors.New("missing private key or password")
+	}
+	if creds.PrivateKey != "" {
+		_, err = ssh.ParsePrivateKey([]byte(creds.PrivateKey))
+		if err != nil {
+			return nil, errors.Wrap(err, "parse private key")
+		}
+	}
+
+	return &creds, nil
+}
+
+// ReplacePassword switches the Credentials from the bootstrap password
+// to the management key, which has to be authorized for root.
+func (creds *Credentials) ReplacePassword(managementKey *infrastructure.SSHKey) {
+	creds.User = defaultUser
+	creds.PrivateKey = managementKey.EncodedPrivateKey()
+	creds.Password = ""
+	creds.Managed = true
+}
+
+// ManagementPublicKey returns the public key of the management key in authorized_keys format,
+// or an empty string when the Credentials do not hold a management key.
+func (creds *Credentials) ManagementPublicKey() (string, error) {
+	if !creds.Managed {
+		return "", nil
+	}
+
+	signer, err := ssh.ParsePrivateKey([]byte(creds.PrivateKey))
+	if err != nil {
+		return "", errors.Wrap(err, "parse private key")
+	}
+
+	return string(ssh.MarshalAuthorizedKey(signer.PublicKey())), nil
+}
+
+// Address returns the host:port pair used to connect to the host.
+func (creds *Credentials) Address() string {
+	return net.JoinHostPort(creds.Host, strconv.Itoa(creds.Port))
+}
+
+// Marshal encodes the Credentials so they can be stored back into ProviderSettings.
+func (creds *Credentials) Marshal() (string, error) {
+	data, err := json.Marshal(creds)
+	if err != nil {
+		return "", errors.Wrap(err, "marshal host credentials")
+	}
+
+	return string(data), nil
+}
+
+func (creds *Credentials) authMethods() ([]ssh.AuthMethod, error) {
+	var methods []ssh.AuthMethod
+	if creds.PrivateKey != "" {
+		signer, err := ssh.ParsePrivateKey([]byte(creds.PrivateKey))
+		if err != nil {
+			return nil, errors.Wrap(err, "parse private key")
+		}
+
+		methods = append(methods, ssh.PublicKeys(signer))
+	}
+	if creds.Password != "" {
+		methods = append(methods, ssh.Password(creds.Password))
+	}
+
+	return methods, nil
+}
@@ -0,0 +1,68 @@
+package byo_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"testing"
+
+	"blockpropeller.dev/blockpropeller/byo"
+	"blockpropeller.dev/blockpropeller/infrastructure"
+	"blockpropeller.dev/lib/test"
+)
+
+func TestParseCredentials(t *testing.T) {
+	privateKey := generatePrivateKey(t)
+
+	tests := []struct {
+		name        string
+		credentials string
+		address     string
+		user        string
+		valid       bool
+	}{
+		{"password with defaults", `{"host":"10.0.0.1","password":"secret"}`, "10.0.0.1:22", "root", true},
+		{"explicit port and user", `{"host":"10.0.0.1","port":2222,"user":"ubuntu","password":"secret"}`, "10.0.0.1:2222", "ubuntu", true},
+		{"private key", marshal(t, map[string]interface{}{"host": "node.example.com", "private_key": privateKey}), "node.example.com:22", "root", true},
+		{"ipv6 host", `{"host":"::1","password":"secret"}`, "[::1]:22", "root", true},
+		{"invalid private key", `{"host":"10.0.0.1","private_key":"not a key"}`, "", "", false},
+		{"missing auth", `{"host":"10.0.0.1"}`, "", "", false},
+		{"missing host", `{"password":"secret"}`, "", "", false},
+		{"invalid port", `{"host":"10.0.0.1","port":70000,"password":"secret"}`, "", "", false},
+		{"not json", "root@10.0.0.1", "", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings := infrastructure.NewProviderSettings("", "", infrastructure.ProviderBYO, tt.credentials)
+
+			creds, err := byo.ParseCredentials(settings)
+			if !tt.valid {
+				test.CheckErrExists(t, "ParseCredentials()", err)
+				return
+			}
+
+			test.CheckErr(t, "ParseCredentials()", err)
+			test.AssertStringsEqual(t, "Credentials.Address()", creds.Address(), tt.address)
+			test.AssertStringsEqual(t, "Credentials.User", creds.User, tt.user)
+		})
+	}
+}
+
+func generatePrivateKey(t *testing.T) string {
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	test.CheckErr(t, "generate private key", err)
+
+	return string(pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(key),
+	}))
+}
+
+func marshal(t *testing.T, v interface{}) string {
+	data, err := json.Marshal(v)
+	test.CheckErr(t, "marshal credentials", err)
+
+	return string(data)
+}
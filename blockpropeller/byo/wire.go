@@ -0,0 +1,9 @@
+package byo
+
+import "github.com/google/wire"
+
+// Set is the Wire provider set for the bring-your-own-server package
+// that does not depend on any underlying dependencies.
+var Set = wire.NewSet(
+	NewBootstrapper,
+)
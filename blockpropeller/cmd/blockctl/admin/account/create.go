@@ -1,8 +1,11 @@
 package account
 
 import (
+	"context"
+
 	"blockpropeller.dev/blockpropeller"
 	"blockpropeller.dev/blockpropeller/account"
+	"blockpropeller.dev/blockpropeller/infrastructure"
 	"blockpropeller.dev/lib/log"
 	"github.com/urfave/cli"
 )
@@ -33,6 +36,18 @@ func createCmd(app *blockpropeller.App) cli.Command {
 				return
 			}
 
+			org, err := app.OrganizationService.Personal(context.Background(), acc)
+			if err != nil {
+				log.ErrorErr(err, "create personal organization")
+				return
+			}
+
+			_, err = infrastructure.DefaultProject(context.Background(), app.ProjectRepository, org.ID, acc.ID)
+			if err != nil {
+				log.ErrorErr(err, "create default project")
+				return
+			}
+
 			log.Info("created new account", log.Fields{
 				"id":    acc.ID,
 				"email": acc.Email,
@@ -0,0 +1,17 @@
+package audit
+
+import (
+	"blockpropeller.dev/blockpropeller"
+	"github.com/urfave/cli"
+)
+
+// Cmd is an umbrella command for operations on the audit log.
+func Cmd(app *blockpropeller.App) cli.Command {
+	return cli.Command{
+		Name:  "audit",
+		Usage: "Audit log related commands",
+		Subcommands: []cli.Command{
+			exportCmd(app),
+		},
+	}
+}
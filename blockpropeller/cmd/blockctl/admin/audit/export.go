@@ -0,0 +1,99 @@
+package audit
+
+import (
+	"context"
+	"io"
+	"os"
+	"time"
+
+	"blockpropeller.dev/blockpropeller"
+	"blockpropeller.dev/blockpropeller/account"
+	"blockpropeller.dev/blockpropeller/audit"
+	"blockpropeller.dev/blockpropeller/cmd/blockctl/util/localauth"
+	"blockpropeller.dev/lib/log"
+	"github.com/urfave/cli"
+)
+
+func exportCmd(app *blockpropeller.App) cli.Command {
+	return cli.Command{
+		Name:  "export",
+		Usage: "Export the audit log of an organization as JSON lines",
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  "organization",
+				Usage: "Organization to export the audit log of, defaults to the personal organization",
+			},
+			cli.StringFlag{
+				Name:  "action",
+				Usage: "Only export entries of the action, such as server.delete",
+			},
+			cli.StringFlag{
+				Name:  "since",
+				Usage: "Only export entries recorded since the RFC 3339 timestamp",
+			},
+			cli.StringFlag{
+				Name:  "output",
+				Usage: "File to export the entries to, defaults to the standard output",
+			},
+		},
+		Action: func(c *cli.Context) {
+			ctx := context.Background()
+			acc := localauth.Account
+
+			orgID := account.OrganizationID(c.String("organization"))
+			if orgID == account.NilOrganizationID {
+				org, err := app.OrganizationService.Personal(ctx, acc)
+				if err != nil {
+					log.ErrorErr(err, "failed finding personal organization")
+					return
+				}
+
+				orgID = org.ID
+			}
+
+			_, err := app.OrganizationService.Authorize(ctx, acc.ID, orgID, account.PermissionViewAudit)
+			if err != nil {
+				log.ErrorErr(err, "access denied to the audit log", log.Fields{
+					"organization_id": orgID,
+				})
+				return
+			}
+
+			filter := audit.Filter{
+				OrganizationID: orgID,
+				Action:         audit.Action(c.String("action")),
+			}
+			if since := c.String("since"); since != "" {
+				filter.Since, err = time.Parse(time.RFC3339, since)
+				if err != nil {
+					log.ErrorErr(err, "invalid since timestamp")
+					return
+				}
+			}
+
+			entries, err := app.AuditService.List(ctx, filter)
+			if err != nil {
+				log.ErrorErr(err, "failed listing audit entries")
+				return
+			}
+
+			var out io.Writer = os.Stdout
+			if path := c.String("output"); path != "" {
+				f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+				if err != nil {
+					log.ErrorErr(err, "failed creating output file")
+					return
+				}
+				defer log.Closer(f)
+
+				out = f
+			}
+
+			err = audit.WriteJSONLines(out, entries)
+			if err != nil {
+				log.ErrorErr(err, "failed exporting audit entries")
+				return
+			}
+		},
+	}
+}
@@ -3,6 +3,8 @@ package admin
 import (
 	"blockpropeller.dev/blockpropeller"
 	"blockpropeller.dev/blockpropeller/cmd/blockctl/admin/account"
+	"blockpropeller.dev/blockpropeller/cmd/blockctl/admin/audit"
+	"blockpropeller.dev/blockpropeller/cmd/blockctl/admin/encryption"
 	"blockpropeller.dev/blockpropeller/cmd/blockctl/admin/job"
 	"blockpropeller.dev/blockpropeller/cmd/blockctl/admin/server"
 	"blockpropeller.dev/blockpropeller/cmd/blockctl/util/localauth"
@@ -25,6 +27,8 @@ func Cmd(app *blockpropeller.App) cli.Command {
 		},
 		Subcommands: []cli.Command{
 			account.Cmd(app),
+			audit.Cmd(app),
+			encryption.Cmd(app),
 			server.Cmd(app),
 			job.Cmd(app),
 		},
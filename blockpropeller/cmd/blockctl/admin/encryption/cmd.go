@@ -0,0 +1,17 @@
+package encryption
+
+import (
+	"blockpropeller.dev/blockpropeller"
+	"github.com/urfave/cli"
+)
+
+// Cmd is an umbrella command for operations on the encryption keys.
+func Cmd(app *blockpropeller.App) cli.Command {
+	return cli.Command{
+		Name:  "encryption",
+		Usage: "Encryption key related commands",
+		Subcommands: []cli.Command{
+			rotateCmd(app),
+		},
+	}
+}
@@ -0,0 +1,64 @@
+package encryption
+
+import (
+	"context"
+
+	"blockpropeller.dev/blockpropeller"
+	"blockpropeller.dev/blockpropeller/audit"
+	"blockpropeller.dev/blockpropeller/cmd/blockctl/util/localauth"
+	"blockpropeller.dev/blockpropeller/encryption"
+	"blockpropeller.dev/lib/log"
+	"github.com/urfave/cli"
+)
+
+func rotateCmd(app *blockpropeller.App) cli.Command {
+	return cli.Command{
+		Name:  "rotate",
+		Usage: "Re-encrypt all stored data with the active encryption key",
+		Description: "Data encrypted with the previous keys or before envelope encryption was introduced " +
+			"is re-encrypted in batches, each in its own transaction. An interrupted rotation " +
+			"is resumed by running the command again, skipping the data already encrypted with the active key.",
+		Flags: []cli.Flag{
+			cli.IntFlag{
+				Name:  "batch-size",
+				Usage: "Number of records re-encrypted in a single transaction",
+				Value: encryption.DefaultRotationBatchSize,
+			},
+		},
+		Action: func(c *cli.Context) {
+			ctx := context.Background()
+			acc := localauth.Account
+
+			keyID := encryption.ActiveKeyID()
+
+			entry := localauth.NewAuditEntry(audit.ActionEncryptionRotate, keyID)
+			org, err := app.OrganizationService.Personal(ctx, acc)
+			if err != nil {
+				log.ErrorErr(err, "failed finding personal organization")
+			} else {
+				entry.OrganizationID = org.ID
+			}
+			defer app.AuditService.Record(ctx, entry)
+
+			log.Info("Rotating encryption key", log.Fields{"key_id": keyID})
+
+			err = app.EncryptionRotator.Rotate(ctx, c.Int("batch-size"), func(progress encryption.RotationProgress) {
+				log.Info("Re-encrypted batch", log.Fields{
+					"table":   progress.Table,
+					"last_id": progress.LastID,
+					"checked": progress.Checked,
+					"rotated": progress.Rotated,
+				})
+			})
+			if err != nil {
+				entry.Fail(err)
+				log.ErrorErr(err, "failed rotating encryption key, run the command again to resume")
+				return
+			}
+
+			log.Info("All data is encrypted with the active key, previous keys can be removed.", log.Fields{
+				"key_id": keyID,
+			})
+		},
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"blockpropeller.dev/blockpropeller/cmd/blockctl/util/localauth"
 	"blockpropeller.dev/blockpropeller/infrastructure"
 	"blockpropeller.dev/blockpropeller/provision"
+	"blockpropeller.dev/blockpropeller/terraform/cloudprovider"
 	"blockpropeller.dev/lib/log"
 	"github.com/blang/semver"
 	"github.com/urfave/cli"
@@ -36,6 +37,22 @@ func runCmd(app *blockpropeller.App) cli.Command {
 				Usage: "Cloud provider to use for provisioning infrastructure.",
 				Value: infrastructure.ProviderDigitalOcean.String(),
 			},
+			cli.StringFlag{
+				Name:  "region",
+				Usage: "Cloud provider region to create the server in. Defaults to the provider default region.",
+			},
+			cli.StringFlag{
+				Name:  "instance-type",
+				Usage: "Cloud provider instance type of the server. Defaults to the provider default for the node mode.",
+			},
+			cli.StringSliceFlag{
+				Name:  "allowed-ip",
+				Usage: "IP address or CIDR range allowed to reach the restricted ports of the server. Can be repeated.",
+			},
+			cli.StringSliceFlag{
+				Name:  "label",
+				Usage: "Label of the server in the key=value format, applied as a cloud resource tag. Can be repeated.",
+			},
 			cli.StringFlag{
 				Name:  "key",
 				Usage: "Cloud provider access key to use for provisioning infrastructure.",
@@ -69,6 +86,9 @@ func runCmd(app *blockpropeller.App) cli.Command {
 			}
 
 			providerKey := app.Config.DigitalOcean.AccessToken
+			if providerType == infrastructure.ProviderLocal && app.Config.Local != nil {
+				providerKey = app.Config.Local.DockerHost
+			}
 			if c.String("key") != "" {
 				providerKey = c.String("key")
 			}
@@ -77,22 +97,71 @@ func runCmd(app *blockpropeller.App) cli.Command {
 				return
 			}
 
+			labels, err := infrastructure.ParseLabels(c.StringSlice("label"))
+			if err != nil {
+				log.ErrorErr(err, "Invalid label flag.")
+				return
+			}
+
 			log.Info("Starting provisioning process...", log.Fields{
 				"network":       network.String(),
 				"node_type":     nodeType.String(),
 				"provider_type": providerType.String(),
 			})
 
+			org, err := app.OrganizationService.Personal(context.TODO(), acc)
+			if err != nil {
+				log.ErrorErr(err, "Failed finding personal organization.")
+				return
+			}
+
+			project, err := infrastructure.DefaultProject(context.TODO(), app.ProjectRepository, org.ID, acc.ID)
+			if err != nil {
+				log.ErrorErr(err, "Failed finding default project.")
+				return
+			}
+
 			provider := infrastructure.NewProviderSettings(acc.ID, "Provider Settings", providerType, providerKey)
-			err := app.ProviderSettingsRepository.Create(context.TODO(), provider)
+			provider.OrganizationID = org.ID
+			provider.ProjectID = project.ID
+			err = app.ProviderSettingsRepository.Create(context.TODO(), provider)
 			if err != nil {
 				log.ErrorErr(err, "Failed saving provider settings.")
 				return
 			}
 
 			srv, err := infrastructure.NewServerBuilder(acc.ID).
+				Project(project).
 				Provider(provider.Type).
+				Region(c.String("region")).
+				InstanceType(c.String("instance-type")).
+				AllowedIPs(c.StringSlice("allowed-ip")...).
+				Labels(labels).
 				Build()
+			if err != nil {
+				log.ErrorErr(err, "Failed building server")
+				return
+			}
+
+			if providerType != infrastructure.ProviderBYO {
+				cloudProvider, err := cloudprovider.GetProvider(providerType)
+				if err != nil {
+					log.ErrorErr(err, "Failed getting cloud provider")
+					return
+				}
+
+				err = cloudProvider.Catalog().Validate(srv)
+				if err != nil {
+					log.ErrorErr(err, "Invalid region or instance type flag.")
+					return
+				}
+
+				err = cloudProvider.ValidateCredentials(context.TODO(), provider)
+				if err != nil {
+					log.ErrorErr(err, "Invalid provider key flag.")
+					return
+				}
+			}
 
 			job, err := provision.NewJobBuilder(acc.ID).
 				Provider(provider).
@@ -4,6 +4,8 @@ import (
 	"context"
 
 	"blockpropeller.dev/blockpropeller"
+	"blockpropeller.dev/blockpropeller/audit"
+	"blockpropeller.dev/blockpropeller/cmd/blockctl/util/localauth"
 	"blockpropeller.dev/blockpropeller/infrastructure"
 	"blockpropeller.dev/lib/log"
 	"github.com/urfave/cli"
@@ -30,8 +32,13 @@ func deleteCmd(app *blockpropeller.App) cli.Command {
 				return
 			}
 
+			entry := localauth.NewAuditEntry(audit.ActionServerDelete, srv.ID.String())
+			entry.OrganizationID = srv.OrganizationID
+			defer app.AuditService.Record(ctx, entry)
+
 			err = app.Provisioner.ServerDestroyer.Destroy(ctx, srv)
 			if err != nil {
+				entry.Fail(err)
 				log.ErrorErr(err, "could not destroy server")
 				return
 			}
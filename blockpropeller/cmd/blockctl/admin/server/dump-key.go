@@ -8,6 +8,9 @@ import (
 	"path/filepath"
 
 	"blockpropeller.dev/blockpropeller"
+	"blockpropeller.dev/blockpropeller/account"
+	"blockpropeller.dev/blockpropeller/audit"
+	"blockpropeller.dev/blockpropeller/cmd/blockctl/util/localauth"
 	"blockpropeller.dev/blockpropeller/infrastructure"
 	"blockpropeller.dev/lib/log"
 	uuid "github.com/satori/go.uuid"
@@ -18,6 +21,12 @@ func dumpKeyCmd(app *blockpropeller.App) cli.Command {
 	return cli.Command{
 		Name:  "dump-key",
 		Usage: "Dump the private key used for accessing the server",
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  "code",
+				Usage: "Two-factor code, required unless the second factor was verified recently",
+			},
+		},
 		Action: func(c *cli.Context) {
 			if !c.Args().Present() {
 				log.Error("please enter a server ID")
@@ -25,7 +34,6 @@ func dumpKeyCmd(app *blockpropeller.App) cli.Command {
 			}
 
 			ctx := context.Background()
-
 			srvID := infrastructure.ServerIDFromString(c.Args().First())
 
 			srv, err := app.ServerRepository.Find(ctx, srvID)
@@ -34,17 +42,40 @@ func dumpKeyCmd(app *blockpropeller.App) cli.Command {
 				return
 			}
 
+			entry := localauth.NewAuditEntry(audit.ActionServerDumpKey, srv.ID.String())
+			entry.OrganizationID = srv.OrganizationID
+			defer app.AuditService.Record(ctx, entry)
+
+			// Private keys grant access to the servers, so they require a fresh second factor.
+			acc, session := localauth.Account, localauth.Session
+			if acc.IsTwoFactorEnabled() && !session.HasFreshTwoFactor() {
+				if c.String("code") == "" {
+					entry.Deny(account.ErrTwoFactorRequired)
+					log.Error("fresh two-factor verification required, please provide a code with --code")
+					return
+				}
+
+				err = app.TwoFactorService.Confirm(ctx, acc, session, c.String("code"))
+				if err != nil {
+					entry.Deny(err)
+					log.ErrorErr(err, "failed verifying second factor")
+					return
+				}
+			}
+
 			privKey := srv.SSHKey.EncodedPrivateKey()
 
 			keyPath := filepath.Join(os.TempDir(), "keys", uuid.NewV4().String())
 			err = os.MkdirAll(filepath.Dir(keyPath), 0755)
 			if err != nil {
+				entry.Fail(err)
 				log.ErrorErr(err, "failed creating key dir")
 				return
 			}
 
 			err = ioutil.WriteFile(keyPath, []byte(privKey), 0400)
 			if err != nil {
+				entry.Fail(err)
 				log.ErrorErr(err, "failed saving private key")
 				return
 			}
@@ -5,9 +5,12 @@ import (
 	"os"
 
 	"blockpropeller.dev/blockpropeller"
+	"blockpropeller.dev/blockpropeller/account"
 	"blockpropeller.dev/blockpropeller/cmd/blockctl/util/localauth"
+	"blockpropeller.dev/blockpropeller/infrastructure"
 	"blockpropeller.dev/lib/log"
 	"github.com/olekukonko/tablewriter"
+	"github.com/pkg/errors"
 	"github.com/urfave/cli"
 )
 
@@ -15,25 +18,43 @@ func listCmd(app *blockpropeller.App) cli.Command {
 	return cli.Command{
 		Name:  "list",
 		Usage: "List all servers",
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  "project",
+				Usage: "Only list servers within the project with the provided ID.",
+			},
+			cli.StringFlag{
+				Name:  "selector, l",
+				Usage: "Only list servers matching the label selector, such as env=prod,team!=infra.",
+			},
+		},
 		Action: func(c *cli.Context) {
 			acc := localauth.Account
 
-			servers, err := app.ServerRepository.List(context.Background(), acc.ID)
+			selector, err := infrastructure.ParseLabelSelector(c.String("selector"))
+			if err != nil {
+				log.ErrorErr(err, "Invalid selector flag.")
+				return
+			}
+
+			servers, err := listServers(app, acc.ID, infrastructure.ProjectID(c.String("project")))
 			if err != nil {
 				log.ErrorErr(err, "failed listing servers")
 				return
 			}
 
 			table := tablewriter.NewWriter(os.Stdout)
-			table.SetHeader([]string{"ID", "Name", "State", "Provider", "IP"})
+			table.SetHeader([]string{"ID", "Project", "Name", "State", "Provider", "IP", "Labels"})
 
-			for _, srv := range servers {
+			for _, srv := range selector.FilterServers(servers) {
 				table.Append([]string{
 					srv.ID.String(),
+					srv.ProjectID.String(),
 					srv.Name,
 					srv.State.String(),
 					srv.Provider.String(),
 					srv.IPAddress,
+					srv.Labels.String(),
 				})
 			}
 
@@ -41,3 +62,21 @@ func listCmd(app *blockpropeller.App) cli.Command {
 		},
 	}
 }
+
+// listServers lists the servers of the account, or only the ones within a project the account can view.
+func listServers(app *blockpropeller.App, accountID account.ID, projectID infrastructure.ProjectID) ([]*infrastructure.Server, error) {
+	if projectID == infrastructure.NilProjectID {
+		return app.ServerRepository.List(context.Background(), accountID)
+	}
+
+	project, err := app.ProjectRepository.Find(context.Background(), projectID)
+	if err != nil {
+		return nil, errors.Wrap(err, "find project")
+	}
+	_, err = app.OrganizationService.Authorize(context.Background(), accountID, project.OrganizationID, account.PermissionView)
+	if err != nil {
+		return nil, errors.Wrapf(err, "access project %s", projectID)
+	}
+
+	return app.ServerRepository.ListByProject(context.Background(), project.ID)
+}
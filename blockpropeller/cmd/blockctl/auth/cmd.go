@@ -14,7 +14,7 @@ func Cmd(app *blockpropeller.App) cli.Command {
 			registerCmd(app),
 			loginCmd(app),
 			whoamiCmd(app),
-			logoutCmd(),
+			logoutCmd(app),
 		},
 	}
 }
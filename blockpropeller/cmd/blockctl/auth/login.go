@@ -5,6 +5,7 @@ import (
 	"blockpropeller.dev/blockpropeller/account"
 	"blockpropeller.dev/blockpropeller/cmd/blockctl/util/localauth"
 	"blockpropeller.dev/lib/log"
+	"github.com/pkg/errors"
 	"github.com/urfave/cli"
 )
 
@@ -23,25 +24,34 @@ func loginCmd(app *blockpropeller.App) cli.Command {
 				Usage:    "Account password",
 				Required: true,
 			},
+			cli.StringFlag{
+				Name:  "code",
+				Usage: "Two-factor code or recovery code, required for accounts with two-factor authentication",
+			},
 		},
 		Action: func(c *cli.Context) {
 			email := account.NewEmail(c.String("email"))
 			password := account.NewClearPassword(c.String("password"))
 
-			token, err := app.AccountService.Login(email, password)
+			tokens, err := app.AccountService.Login(email, password, c.String("code"))
+			if errors.Cause(err) == account.ErrTwoFactorRequired {
+				log.Error("two-factor authentication enabled, please provide a code with --code")
+				return
+			}
 			if err != nil {
 				log.ErrorErr(err, "register new account")
 				return
 			}
 
-			err = localauth.SetToken(token)
+			err = localauth.SetTokens(tokens)
 			if err != nil {
 				log.ErrorErr(err, "failed saving token")
 				return
 			}
 
 			log.Info("successfully logged in", log.Fields{
-				"token": token,
+				"token":      tokens.AccessToken,
+				"expires_at": tokens.AccessTokenExpiresAt,
 			})
 		},
 	}
@@ -1,17 +1,37 @@
 package auth
 
 import (
+	"blockpropeller.dev/blockpropeller"
 	"blockpropeller.dev/blockpropeller/cmd/blockctl/util/localauth"
 	"blockpropeller.dev/lib/log"
 	"github.com/pkg/errors"
 	"github.com/urfave/cli"
 )
 
-func logoutCmd() cli.Command {
+func logoutCmd(app *blockpropeller.App) cli.Command {
 	return cli.Command{
 		Name:  "logout",
 		Usage: "Logout from a BlockPropeller account.",
+		Flags: []cli.Flag{
+			cli.BoolFlag{
+				Name:  "all",
+				Usage: "Logout from all sessions of the account",
+			},
+		},
 		Action: func(c *cli.Context) {
+			if localauth.Session != nil {
+				var err error
+				if c.Bool("all") {
+					err = app.AccountService.LogoutAll(localauth.Account)
+				} else {
+					err = app.AccountService.Logout(localauth.Session)
+				}
+				if err != nil {
+					log.ErrorErr(err, "failed revoking session")
+					return
+				}
+			}
+
 			err := localauth.DeleteToken()
 			if errors.Cause(err) == localauth.ErrTokenNotFound {
 				log.Info("already logged out")
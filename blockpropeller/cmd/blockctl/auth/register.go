@@ -1,9 +1,12 @@
 package auth
 
 import (
+	"context"
+
 	"blockpropeller.dev/blockpropeller"
 	"blockpropeller.dev/blockpropeller/account"
 	"blockpropeller.dev/blockpropeller/cmd/blockctl/util/localauth"
+	"blockpropeller.dev/blockpropeller/infrastructure"
 	"blockpropeller.dev/lib/log"
 	"github.com/urfave/cli"
 )
@@ -28,13 +31,30 @@ func registerCmd(app *blockpropeller.App) cli.Command {
 			email := account.NewEmail(c.String("email"))
 			password := account.NewClearPassword(c.String("password"))
 
-			acc, token, err := app.AccountService.Register(email, password)
+			acc, tokens, err := app.AccountService.Register(email, password)
 			if err != nil {
 				log.ErrorErr(err, "register new account")
 				return
 			}
 
-			err = localauth.SetToken(token)
+			org, err := app.OrganizationService.Personal(context.Background(), acc)
+			if err != nil {
+				log.ErrorErr(err, "create personal organization")
+				return
+			}
+
+			_, err = infrastructure.DefaultProject(context.Background(), app.ProjectRepository, org.ID, acc.ID)
+			if err != nil {
+				log.ErrorErr(err, "create default project")
+				return
+			}
+
+			err = app.CredentialService.SendVerification(context.Background(), acc)
+			if err != nil {
+				log.ErrorErr(err, "failed sending email verification")
+			}
+
+			err = localauth.SetTokens(tokens)
 			if err != nil {
 				log.ErrorErr(err, "failed saving token")
 				return
@@ -23,7 +23,7 @@ func whoamiCmd(app *blockpropeller.App) cli.Command {
 				return
 			}
 
-			acc, err := app.AccountService.Authenticate(token)
+			acc, _, err := app.AccountService.Authenticate(token)
 			if err != nil {
 				log.ErrorErr(err, "failed authenticating user")
 				return
@@ -12,7 +12,10 @@ import (
 	"blockpropeller.dev/lib/log"
 	"github.com/urfave/cli"
 
+	_ "blockpropeller.dev/blockpropeller/terraform/cloudprovider/aws"
 	_ "blockpropeller.dev/blockpropeller/terraform/cloudprovider/digitalocean"
+	_ "blockpropeller.dev/blockpropeller/terraform/cloudprovider/google"
+	_ "blockpropeller.dev/blockpropeller/terraform/cloudprovider/hcloud"
 )
 
 // AppCmd is the top level command wrapping all CLI capabilities of BlockPropeller.
@@ -41,7 +44,12 @@ func main() {
 	defer closeFn()
 
 	log.SetGlobal(app.Logger)
-	encryption.Init(app.Config.Encryption.Secret)
+
+	err = encryption.Configure(app.Config.Encryption)
+	if err != nil {
+		log.ErrorErr(err, "Failed configuring encryption")
+		os.Exit(1)
+	}
 
 	cmd := AppCmd(app)
 
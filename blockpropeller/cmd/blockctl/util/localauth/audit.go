@@ -0,0 +1,16 @@
+package localauth
+
+import (
+	"blockpropeller.dev/blockpropeller/audit"
+)
+
+// userAgent identifies the actions taken through the CLI in the audit log.
+const userAgent = "blockctl"
+
+// NewAuditEntry returns a new audit.Entry of an action the authenticated Account takes through the CLI.
+func NewAuditEntry(action audit.Action, targetID string) *audit.Entry {
+	entry := audit.NewEntry(audit.SourceCLI, action, targetID, Account)
+	entry.UserAgent = userAgent
+
+	return entry
+}
@@ -6,11 +6,17 @@ import (
 	"blockpropeller.dev/lib/log"
 )
 
-// Account is an authenticated account based on locally stored token.
-var Account *account.Account
+var (
+	// Account is an authenticated account based on locally stored token.
+	Account *account.Account
+	// Session is the session the Account is authenticated within.
+	Session *account.Session
+)
 
 // Authenticate checks if there is an authenticated token and if so,
 // sets the authenticated user to be globally accessible for future callers.
+//
+// Expired tokens are refreshed using the locally stored refresh token.
 func Authenticate(app *blockpropeller.App) {
 	token, err := GetToken()
 	if err != nil {
@@ -20,14 +26,40 @@ func Authenticate(app *blockpropeller.App) {
 		return
 	}
 
-	acc, err := app.AccountService.Authenticate(token)
+	acc, session, err := app.AccountService.Authenticate(token)
 	if err != nil {
-		log.Debug("failed authenticating account from token", log.Fields{
-			"token": token.String(),
-			"err":   err,
+		log.Debug("failed authenticating account from token, refreshing session", log.Fields{
+			"err": err,
 		})
-		return
+
+		acc, session, err = refresh(app)
+		if err != nil {
+			log.Debug("failed refreshing session", log.Fields{
+				"err": err,
+			})
+			return
+		}
 	}
 
 	Account = acc
+	Session = session
+}
+
+func refresh(app *blockpropeller.App) (*account.Account, *account.Session, error) {
+	refreshToken, err := GetRefreshToken()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tokens, err := app.AccountService.Refresh(refreshToken)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = SetTokens(tokens)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return app.AccountService.Authenticate(tokens.AccessToken)
 }
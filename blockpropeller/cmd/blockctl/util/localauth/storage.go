@@ -14,47 +14,89 @@ var (
 	ErrTokenNotFound = errors.New("token not found")
 )
 
+const (
+	accessTokenFile  = "jwt_token"
+	refreshTokenFile = "refresh_token"
+)
+
 // GetToken returns an account token stored locally if it exists.
 func GetToken() (account.Token, error) {
-	tokenFile, err := getTokenFile()
+	data, err := readFile(accessTokenFile)
+	if err != nil {
+		return account.NilToken, err
+	}
+
+	return account.NewToken(data), nil
+}
+
+// GetRefreshToken returns the refresh token of the local session if it exists.
+func GetRefreshToken() (account.RefreshToken, error) {
+	data, err := readFile(refreshTokenFile)
+	if err != nil {
+		return "", err
+	}
+
+	return account.RefreshToken(data), nil
+}
+
+// SetTokens sets the provided Tokens to local storage for future authentication.
+func SetTokens(tokens *account.Tokens) error {
+	err := writeFile(accessTokenFile, tokens.AccessToken.String())
+	if err != nil {
+		return errors.Wrap(err, "write JWT token to config dir")
+	}
+
+	err = writeFile(refreshTokenFile, tokens.RefreshToken.String())
+	if err != nil {
+		return errors.Wrap(err, "write refresh token to config dir")
+	}
+
+	return nil
+}
+
+// DeleteToken removes the Tokens from local storage if they exist.
+func DeleteToken() error {
+	err := deleteFile(refreshTokenFile)
+	if err != nil && errors.Cause(err) != ErrTokenNotFound {
+		return err
+	}
+
+	return deleteFile(accessTokenFile)
+}
+
+func readFile(name string) (string, error) {
+	tokenFile, err := getTokenFile(name)
 	if err != nil {
-		return account.NilToken, errors.Wrap(err, "get token file")
+		return "", errors.Wrap(err, "get token file")
 	}
 
 	_, err = os.Stat(tokenFile)
 	if os.IsNotExist(err) {
-		return account.NilToken, ErrTokenNotFound
+		return "", ErrTokenNotFound
 	}
 	if err != nil {
-		return account.NilToken, errors.Wrap(err, "stat token file")
+		return "", errors.Wrap(err, "stat token file")
 	}
 
 	data, err := ioutil.ReadFile(tokenFile)
 	if err != nil {
-		return account.NilToken, errors.Wrap(err, "read token file")
+		return "", errors.Wrap(err, "read token file")
 	}
 
-	return account.NewToken(string(data)), nil
+	return string(data), nil
 }
 
-// SetToken sets the provided Token to local storage for future authentication.
-func SetToken(token account.Token) error {
-	tokenFile, err := getTokenFile()
+func writeFile(name string, data string) error {
+	tokenFile, err := getTokenFile(name)
 	if err != nil {
 		return errors.Wrap(err, "get token file")
 	}
 
-	err = ioutil.WriteFile(tokenFile, []byte(token.String()), 0644)
-	if err != nil {
-		return errors.Wrap(err, "write JWT token to config dir")
-	}
-
-	return nil
+	return ioutil.WriteFile(tokenFile, []byte(data), 0600)
 }
 
-// DeleteToken removes the Token from local storage if it exists.
-func DeleteToken() error {
-	tokenFile, err := getTokenFile()
+func deleteFile(name string) error {
+	tokenFile, err := getTokenFile(name)
 	if err != nil {
 		return errors.Wrap(err, "get token file")
 	}
@@ -75,7 +117,7 @@ func DeleteToken() error {
 	return nil
 }
 
-func getTokenFile() (string, error) {
+func getTokenFile(name string) (string, error) {
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
 		return "", errors.Wrap(err, "get user home dir")
@@ -88,7 +130,5 @@ func getTokenFile() (string, error) {
 		return "", errors.Wrap(err, "create BlockPropeller config dir")
 	}
 
-	tokenFile := filepath.Join(configDir, "jwt_token")
-
-	return tokenFile, nil
+	return filepath.Join(configDir, name), nil
 }
@@ -7,7 +7,10 @@ import (
 	"blockpropeller.dev/blockpropeller"
 	"blockpropeller.dev/lib/log"
 
+	_ "blockpropeller.dev/blockpropeller/terraform/cloudprovider/aws"
 	_ "blockpropeller.dev/blockpropeller/terraform/cloudprovider/digitalocean"
+	_ "blockpropeller.dev/blockpropeller/terraform/cloudprovider/google"
+	_ "blockpropeller.dev/blockpropeller/terraform/cloudprovider/hcloud"
 )
 
 func main() {
@@ -18,7 +21,11 @@ func main() {
 	}
 	defer closeFn()
 
-	appSrv.App.InitGlobal()
+	err = appSrv.App.InitGlobal()
+	if err != nil {
+		log.ErrorErr(err, "Failed initializing application")
+		os.Exit(1)
+	}
 
 	err = appSrv.Start(context.Background())
 	if err != nil {
@@ -5,6 +5,8 @@ import (
 	"blockpropeller.dev/blockpropeller/ansible"
 	"blockpropeller.dev/blockpropeller/database"
 	"blockpropeller.dev/blockpropeller/encryption"
+	"blockpropeller.dev/blockpropeller/mail"
+	"blockpropeller.dev/blockpropeller/oidc"
 	"blockpropeller.dev/blockpropeller/provision"
 	"blockpropeller.dev/blockpropeller/terraform"
 	"blockpropeller.dev/lib/log"
@@ -13,15 +15,21 @@ import (
 
 // Config is the root config structure declaring all possible configuration parameters.
 type Config struct {
-	Log        *log.Config                 `yaml:"log"`
-	Server     *server.Config              `yaml:"server"`
-	WorkerPool *provision.WorkerPoolConfig `yaml:"worker_pool"`
+	Log         *log.Config                  `yaml:"log"`
+	Server      *server.Config               `yaml:"server"`
+	WorkerPool  *provision.WorkerPoolConfig  `yaml:"worker_pool"`
+	HealthCheck *provision.HealthCheckConfig `yaml:"health_check"`
+	Snapshots   *provision.SnapshotConfig    `yaml:"snapshots"`
+	Firewall    *provision.FirewallConfig    `yaml:"firewall"`
 
 	Database   *database.Config   `yaml:"database"`
 	JWT        *account.JWTConfig `yaml:"jwt"`
 	Encryption *encryption.Config `yaml:"encryption"`
+	Mail       *mail.Config       `yaml:"mail"`
+	OIDC       *oidc.Config       `yaml:"oidc"`
 
 	DigitalOcean *DigitalOceanConfig `yaml:"digital_ocean"`
+	Local        *LocalConfig        `yaml:"local"`
 
 	Terraform *terraform.Config `yaml:"terraform"`
 	Ansible   *ansible.Config   `yaml:"ansible"`
@@ -36,3 +44,10 @@ func (cfg *Config) Validate() error {
 type DigitalOceanConfig struct {
 	AccessToken string `yaml:"access_token"`
 }
+
+// LocalConfig specifies all configuration parameters for the local Docker provider.
+//
+// The provider is only enabled when DockerHost is set.
+type LocalConfig struct {
+	DockerHost string `yaml:"docker_host"`
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"blockpropeller.dev/blockpropeller/account"
+	"github.com/jinzhu/gorm"
 	"github.com/pkg/errors"
 )
 
@@ -21,6 +22,9 @@ func NewAccountRepository(db *DB) *AccountRepository {
 func (repo *AccountRepository) FindByID(ctx context.Context, id account.ID) (*account.Account, error) {
 	var acc account.Account
 	err := repo.db.Model(ctx, &acc).Where("id = ?", id).First(&acc).Error
+	if gorm.IsRecordNotFoundError(err) {
+		return nil, account.ErrAccountNotFound
+	}
 	if err != nil {
 		return nil, errors.Wrap(err, "find account by ID")
 	}
@@ -32,6 +36,9 @@ func (repo *AccountRepository) FindByID(ctx context.Context, id account.ID) (*ac
 func (repo *AccountRepository) FindByEmail(ctx context.Context, email account.Email) (*account.Account, error) {
 	var acc account.Account
 	err := repo.db.Model(ctx, &acc).Where("email = ?", email).First(&acc).Error
+	if gorm.IsRecordNotFoundError(err) {
+		return nil, account.ErrAccountNotFound
+	}
 	if err != nil {
 		return nil, errors.Wrap(err, "find account by email")
 	}
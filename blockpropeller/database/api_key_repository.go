@@ -0,0 +1,92 @@
+package database
+
+import (
+	"context"
+
+	"blockpropeller.dev/blockpropeller/account"
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+)
+
+// APIKeyRepository is a databased backed implementation of a account.APIKeyRepository.
+type APIKeyRepository struct {
+	db *DB
+}
+
+// NewAPIKeyRepository returns a new APIKeyRepository instance.
+func NewAPIKeyRepository(db *DB) *APIKeyRepository {
+	return &APIKeyRepository{db: db}
+}
+
+// Find an APIKey given an APIKeyID.
+func (repo *APIKeyRepository) Find(ctx context.Context, id account.APIKeyID) (*account.APIKey, error) {
+	var key account.APIKey
+	err := repo.db.Model(ctx, &key).Where("id = ?", id).First(&key).Error
+	if gorm.IsRecordNotFoundError(err) {
+		return nil, account.ErrAPIKeyNotFound
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "find api key by ID")
+	}
+
+	return &key, nil
+}
+
+// FindByToken returns the APIKey authenticated by the provided token.
+func (repo *APIKeyRepository) FindByToken(ctx context.Context, token account.APIKeyToken) (*account.APIKey, error) {
+	var key account.APIKey
+	err := repo.db.Model(ctx, &key).Where("token_hash = ?", token.Hash()).First(&key).Error
+	if gorm.IsRecordNotFoundError(err) {
+		return nil, account.ErrAPIKeyNotFound
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "find api key by token")
+	}
+
+	return &key, nil
+}
+
+// ListByAccount lists all the API keys of an Account.
+func (repo *APIKeyRepository) ListByAccount(ctx context.Context, accountID account.ID) ([]*account.APIKey, error) {
+	var keys []*account.APIKey
+	err := repo.db.Model(ctx, &keys).
+		Where("account_id = ?", accountID).
+		Order("created_at").
+		Find(&keys).
+		Error
+	if err != nil {
+		return nil, errors.Wrap(err, "list account api keys")
+	}
+
+	return keys, nil
+}
+
+// Create a new APIKey.
+func (repo *APIKeyRepository) Create(ctx context.Context, key *account.APIKey) error {
+	err := repo.db.Model(ctx, key).Create(key).Error
+	if err != nil {
+		return errors.Wrap(err, "create api key")
+	}
+
+	return nil
+}
+
+// Update an existing APIKey.
+func (repo *APIKeyRepository) Update(ctx context.Context, key *account.APIKey) error {
+	err := repo.db.Model(ctx, key).Save(key).Error
+	if err != nil {
+		return errors.Wrap(err, "update api key")
+	}
+
+	return nil
+}
+
+// Delete an existing APIKey.
+func (repo *APIKeyRepository) Delete(ctx context.Context, key *account.APIKey) error {
+	err := repo.db.Model(ctx, key).Delete(key).Error
+	if err != nil {
+		return errors.Wrap(err, "delete api key")
+	}
+
+	return nil
+}
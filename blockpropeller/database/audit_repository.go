@@ -0,0 +1,69 @@
+package database
+
+import (
+	"context"
+
+	"blockpropeller.dev/blockpropeller/account"
+	"blockpropeller.dev/blockpropeller/audit"
+	"github.com/pkg/errors"
+)
+
+// AuditRepository is a databased backed implementation of a audit.Repository.
+type AuditRepository struct {
+	db *DB
+}
+
+// NewAuditRepository returns a new AuditRepository instance.
+func NewAuditRepository(db *DB) *AuditRepository {
+	return &AuditRepository{db: db}
+}
+
+// List the entries matching the Filter, from the most recent ones.
+func (repo *AuditRepository) List(ctx context.Context, filter audit.Filter) ([]*audit.Entry, error) {
+	query := repo.db.Model(ctx, &audit.Entry{})
+	if filter.OrganizationID != account.NilOrganizationID {
+		query = query.Where("organization_id = ?", filter.OrganizationID)
+	}
+	if filter.ActorID != "" {
+		query = query.Where("actor_id = ?", filter.ActorID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.TargetType != "" {
+		query = query.Where("target_type = ?", filter.TargetType)
+	}
+	if filter.TargetID != "" {
+		query = query.Where("target_id = ?", filter.TargetID)
+	}
+	if filter.Outcome != "" {
+		query = query.Where("outcome = ?", filter.Outcome)
+	}
+	if !filter.Since.IsZero() {
+		query = query.Where("created_at >= ?", filter.Since)
+	}
+	if !filter.Until.IsZero() {
+		query = query.Where("created_at < ?", filter.Until)
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+
+	var entries []*audit.Entry
+	err := query.Order("created_at desc").Find(&entries).Error
+	if err != nil {
+		return nil, errors.Wrap(err, "list audit entries")
+	}
+
+	return entries, nil
+}
+
+// Create a new Entry.
+func (repo *AuditRepository) Create(ctx context.Context, entry *audit.Entry) error {
+	err := repo.db.Model(ctx, entry).Create(entry).Error
+	if err != nil {
+		return errors.Wrap(err, "create audit entry")
+	}
+
+	return nil
+}
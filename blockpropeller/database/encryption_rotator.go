@@ -0,0 +1,170 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"blockpropeller.dev/blockpropeller/encryption"
+	"github.com/pkg/errors"
+)
+
+// encryptedTable lists the columns of a table holding data encrypted by the encryption package.
+type encryptedTable struct {
+	name    string
+	columns []string
+	// where excludes the records holding no encrypted data.
+	where string
+}
+
+var encryptedTables = []encryptedTable{
+	{name: "accounts", columns: []string{"totp_secret"}},
+	{name: "provider_settings", columns: []string{"credentials"}, where: "credentials <> '[DELETED]'"},
+	{name: "servers", columns: []string{
+		"ssh_key_private_key", "terraform_definitions", "terraform_plan", "terraform_state",
+	}},
+	{name: "reserved_ips", columns: []string{"terraform_definitions", "terraform_plan", "terraform_state"}},
+	{name: "volume_snapshots", columns: []string{"terraform_definitions", "terraform_plan", "terraform_state"}},
+}
+
+// EncryptionRotator is a database backed implementation of an encryption.Rotator.
+type EncryptionRotator struct {
+	db *DB
+}
+
+// NewEncryptionRotator returns a new EncryptionRotator instance.
+func NewEncryptionRotator(db *DB) *EncryptionRotator {
+	return &EncryptionRotator{db: db}
+}
+
+// Rotate satisfies the encryption.Rotator interface.
+//
+// Records are checked in the order of their IDs, including the soft deleted ones.
+func (r *EncryptionRotator) Rotate(ctx context.Context, batchSize int, progress func(encryption.RotationProgress)) error {
+	if batchSize <= 0 {
+		batchSize = encryption.DefaultRotationBatchSize
+	}
+
+	for _, table := range encryptedTables {
+		state := encryption.RotationProgress{Table: table.name}
+
+		for {
+			checked, err := r.rotateBatch(ctx, table, batchSize, &state)
+			if err != nil {
+				return errors.Wrapf(err, "rotate %s", table.name)
+			}
+
+			// The progress of an empty batch is reported only for empty tables.
+			if progress != nil && (checked > 0 || state.Checked == 0) {
+				progress(state)
+			}
+			if checked < batchSize {
+				break
+			}
+		}
+	}
+
+	return nil
+}
+
+// rotateBatch re-encrypts a batch of records following the last checked one in a single transaction,
+// returning the number of records checked.
+func (r *EncryptionRotator) rotateBatch(
+	ctx context.Context,
+	table encryptedTable,
+	batchSize int,
+	state *encryption.RotationProgress,
+) (int, error) {
+	var checked int
+
+	err := r.db.RunInTransaction(ctx, func(ctx context.Context) error {
+		query := r.db.Table(ctx, table.name).
+			Select("id, "+strings.Join(table.columns, ", ")).
+			Where("id > ?", state.LastID)
+		if table.where != "" {
+			query = query.Where(table.where)
+		}
+
+		rows, err := query.Order("id").Limit(batchSize).Rows()
+		if err != nil {
+			return errors.Wrap(err, "select batch")
+		}
+
+		var records [][]sql.NullString
+		for rows.Next() {
+			record := make([]sql.NullString, len(table.columns)+1)
+			dest := make([]interface{}, len(record))
+			for i := range record {
+				dest[i] = &record[i]
+			}
+
+			if err := rows.Scan(dest...); err != nil {
+				_ = rows.Close()
+				return errors.Wrap(err, "scan record")
+			}
+
+			records = append(records, record)
+		}
+		// The batch has to be read entirely before updating, as the transaction holds a single connection.
+		if err := rows.Close(); err != nil {
+			return errors.Wrap(err, "read batch")
+		}
+
+		rotated := 0
+		for _, record := range records {
+			id := record[0].String
+
+			updates := make(map[string]interface{})
+			for i, column := range table.columns {
+				value := []byte(record[i+1].String)
+				if len(value) == 0 || encryption.IsCurrent(value) {
+					continue
+				}
+
+				reencrypted, err := reencrypt(value)
+				if err != nil {
+					return errors.Wrapf(err, "re-encrypt %s of %s", column, id)
+				}
+
+				updates[column] = string(reencrypted)
+			}
+
+			if len(updates) > 0 {
+				err := r.db.Table(ctx, table.name).Where("id = ?", id).UpdateColumns(updates).Error
+				if err != nil {
+					return errors.Wrapf(err, "update %s", id)
+				}
+
+				rotated++
+			}
+		}
+
+		checked = len(records)
+		if checked > 0 {
+			state.LastID = records[checked-1][0].String
+		}
+		state.Checked += checked
+		state.Rotated += rotated
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return checked, nil
+}
+
+func reencrypt(data []byte) ([]byte, error) {
+	plaintext, err := encryption.Decrypt(data)
+	if err != nil {
+		return nil, errors.Wrap(err, "decrypt")
+	}
+
+	encrypted, err := encryption.Encrypt(plaintext)
+	if err != nil {
+		return nil, errors.Wrap(err, "encrypt")
+	}
+
+	return encrypted, nil
+}
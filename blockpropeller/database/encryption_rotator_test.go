@@ -0,0 +1,123 @@
+package database_test
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"blockpropeller.dev/blockpropeller/account"
+	"blockpropeller.dev/blockpropeller/database"
+	"blockpropeller.dev/blockpropeller/encryption"
+	"blockpropeller.dev/blockpropeller/infrastructure"
+	"blockpropeller.dev/lib/log"
+	"blockpropeller.dev/lib/test"
+)
+
+func configureEncryption(t *testing.T, cfg *encryption.Config) {
+	test.CheckErr(t, "validate encryption config", cfg.Validate())
+	test.CheckErr(t, "configure encryption", encryption.Configure(cfg))
+}
+
+// rawColumns returns the stored values of a column, as they are before decryption.
+func rawColumns(t *testing.T, db *database.DB, table, column string) []string {
+	rows, err := db.Table(context.Background(), table).Select(column).Rows()
+	test.CheckErr(t, "select raw "+column, err)
+	defer log.Closer(rows)
+
+	var values []string
+	for rows.Next() {
+		var value string
+		test.CheckErr(t, "scan raw "+column, rows.Scan(&value))
+		values = append(values, value)
+	}
+
+	return values
+}
+
+func TestEncryptionRotator(t *testing.T) {
+	dir, err := ioutil.TempDir("", "database")
+	test.CheckErr(t, "create temp dir", err)
+	defer os.RemoveAll(dir)
+
+	db, closeFn, err := database.ProvideDB(
+		&database.Config{Dialect: "sqlite3", File: filepath.Join(dir, "blockpropeller.db")},
+		&log.Config{Level: "info"},
+	)
+	test.CheckErr(t, "provide db", err)
+	defer closeFn()
+
+	ctx := context.Background()
+	accRepo := database.NewAccountRepository(db)
+	settingsRepo := database.NewProviderSettingsRepository(db)
+
+	configureEncryption(t, &encryption.Config{KeyConfig: encryption.KeyConfig{Secret: "OldSecret"}})
+
+	var settings []*infrastructure.ProviderSettings
+	for i := 0; i < 3; i++ {
+		acc := account.NewAccount(account.Email(fmt.Sprintf("user%d@example.com", i)), "")
+		acc.TOTPSecret = account.TOTPSecret(fmt.Sprintf("totp-secret-%d", i))
+		test.CheckErr(t, "create account", accRepo.Create(ctx, acc))
+
+		ps := infrastructure.NewProviderSettings(acc.ID, "Provider", infrastructure.ProviderDigitalOcean, fmt.Sprintf("token-%d", i))
+		test.CheckErr(t, "create provider settings", settingsRepo.Create(ctx, ps))
+		settings = append(settings, ps)
+	}
+
+	configureEncryption(t, &encryption.Config{
+		KeyConfig:    encryption.KeyConfig{ID: "2019-10", Secret: "NewSecret"},
+		PreviousKeys: []*encryption.KeyConfig{{ID: encryption.DefaultKeyID, Secret: "OldSecret"}},
+	})
+
+	// Credentials encrypted with a key which is not configured stop the rotation midway.
+	err = db.Table(ctx, "provider_settings").
+		Where("id = ?", settings[1].ID).
+		UpdateColumn("credentials", "v1:lost:AAAA").Error
+	test.CheckErr(t, "corrupt credentials", err)
+
+	rotator := database.NewEncryptionRotator(db)
+	rotated := make(map[string]int)
+	rotate := func(progress encryption.RotationProgress) {
+		rotated[progress.Table] = progress.Rotated
+	}
+
+	err = rotator.Rotate(ctx, 1, rotate)
+	test.CheckErrExists(t, "rotate with lost key", err)
+	test.AssertIntsEqual(t, "rotated accounts", rotated["accounts"], 3)
+
+	for _, value := range rawColumns(t, db, "accounts", "totp_secret") {
+		test.AssertBoolEqual(t, "account rotated before failure", strings.HasPrefix(value, "v1:2019-10:"), true)
+	}
+
+	settings[1].Credentials = "token-1"
+	test.CheckErr(t, "restore credentials", settingsRepo.Update(ctx, settings[1]))
+
+	// Running the rotation again resumes it, skipping the already rotated records.
+	previouslyRotated := rotated["provider_settings"]
+	rotated = make(map[string]int)
+
+	err = rotator.Rotate(ctx, 1, rotate)
+	test.CheckErr(t, "resume rotation", err)
+	test.AssertIntsEqual(t, "accounts rotated again", rotated["accounts"], 0)
+	test.AssertIntsEqual(t, "rotated provider settings", previouslyRotated+rotated["provider_settings"], 2)
+
+	// Previous keys can be removed once the rotation is complete.
+	configureEncryption(t, &encryption.Config{KeyConfig: encryption.KeyConfig{ID: "2019-10", Secret: "NewSecret"}})
+
+	for _, value := range rawColumns(t, db, "provider_settings", "credentials") {
+		test.AssertBoolEqual(t, "credentials rotated", strings.HasPrefix(value, "v1:2019-10:"), true)
+	}
+
+	for i, ps := range settings {
+		found, err := settingsRepo.Find(ctx, ps.ID)
+		test.CheckErr(t, "find provider settings", err)
+		test.AssertStringsEqual(t, "decrypted credentials", found.Credentials, fmt.Sprintf("token-%d", i))
+
+		acc, err := accRepo.FindByID(ctx, ps.AccountID)
+		test.CheckErr(t, "find account", err)
+		test.AssertStringsEqual(t, "decrypted totp secret", string(acc.TOTPSecret), fmt.Sprintf("totp-secret-%d", i))
+	}
+}
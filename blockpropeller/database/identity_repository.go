@@ -0,0 +1,117 @@
+package database
+
+import (
+	"context"
+
+	"blockpropeller.dev/blockpropeller/account"
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+)
+
+// IdentityRepository is a databased backed implementation of a account.IdentityRepository.
+type IdentityRepository struct {
+	db *DB
+}
+
+// NewIdentityRepository returns a new IdentityRepository instance.
+func NewIdentityRepository(db *DB) *IdentityRepository {
+	return &IdentityRepository{db: db}
+}
+
+// FindBySubject returns the Identity of the subject at the issuer.
+func (repo *IdentityRepository) FindBySubject(ctx context.Context, issuer, subject string) (*account.Identity, error) {
+	var identity account.Identity
+	err := repo.db.Model(ctx, &identity).
+		Where("issuer = ? AND subject = ?", issuer, subject).
+		First(&identity).
+		Error
+	if gorm.IsRecordNotFoundError(err) {
+		return nil, account.ErrIdentityNotFound
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "find identity by subject")
+	}
+
+	return &identity, nil
+}
+
+// ListByAccount lists all the identities linked to an Account.
+func (repo *IdentityRepository) ListByAccount(ctx context.Context, accountID account.ID) ([]*account.Identity, error) {
+	var identities []*account.Identity
+	err := repo.db.Model(ctx, &identities).
+		Where("account_id = ?", accountID).
+		Find(&identities).
+		Error
+	if err != nil {
+		return nil, errors.Wrap(err, "list identities by account")
+	}
+
+	return identities, nil
+}
+
+// Create a new Identity.
+func (repo *IdentityRepository) Create(ctx context.Context, identity *account.Identity) error {
+	err := repo.db.Model(ctx, identity).Create(identity).Error
+	if err != nil {
+		return errors.Wrap(err, "create identity")
+	}
+
+	return nil
+}
+
+// Update an existing Identity.
+func (repo *IdentityRepository) Update(ctx context.Context, identity *account.Identity) error {
+	err := repo.db.Model(ctx, identity).Save(identity).Error
+	if err != nil {
+		return errors.Wrap(err, "update identity")
+	}
+
+	return nil
+}
+
+// SSOAuthorizationRepository is a databased backed implementation of a account.SSOAuthorizationRepository.
+type SSOAuthorizationRepository struct {
+	db *DB
+}
+
+// NewSSOAuthorizationRepository returns a new SSOAuthorizationRepository instance.
+func NewSSOAuthorizationRepository(db *DB) *SSOAuthorizationRepository {
+	return &SSOAuthorizationRepository{db: db}
+}
+
+// FindByState returns the SSOAuthorization started with the provided state.
+func (repo *SSOAuthorizationRepository) FindByState(ctx context.Context, state account.SSOState) (*account.SSOAuthorization, error) {
+	var auth account.SSOAuthorization
+	err := repo.db.Model(ctx, &auth).
+		Where("state_hash = ?", state.Hash()).
+		First(&auth).
+		Error
+	if gorm.IsRecordNotFoundError(err) {
+		return nil, account.ErrSSOAuthorizationNotFound
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "find sso authorization by state")
+	}
+
+	return &auth, nil
+}
+
+// Create a new SSOAuthorization.
+func (repo *SSOAuthorizationRepository) Create(ctx context.Context, auth *account.SSOAuthorization) error {
+	err := repo.db.Model(ctx, auth).Create(auth).Error
+	if err != nil {
+		return errors.Wrap(err, "create sso authorization")
+	}
+
+	return nil
+}
+
+// Update an existing SSOAuthorization.
+func (repo *SSOAuthorizationRepository) Update(ctx context.Context, auth *account.SSOAuthorization) error {
+	err := repo.db.Model(ctx, auth).Save(auth).Error
+	if err != nil {
+		return errors.Wrap(err, "update sso authorization")
+	}
+
+	return nil
+}
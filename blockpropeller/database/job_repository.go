@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"blockpropeller.dev/blockpropeller/account"
+	"blockpropeller.dev/blockpropeller/infrastructure"
 	"blockpropeller.dev/blockpropeller/provision"
 	"github.com/pkg/errors"
 )
@@ -25,6 +26,8 @@ func (repo *JobRepository) FindIncomplete(ctx context.Context, excl ...provision
 	query := repo.db.Model(ctx, &jobs).
 		Preload("ProviderSettings").
 		Preload("Server").
+		Preload("Server.Deployments").
+		Preload("Server.ReservedIP").
 		Preload("Deployment").
 		Where("finished_at IS NULL")
 
@@ -46,6 +49,8 @@ func (repo *JobRepository) Find(ctx context.Context, id provision.JobID) (*provi
 	err := repo.db.Model(ctx, &job).
 		Preload("ProviderSettings").
 		Preload("Server").
+		Preload("Server.Deployments").
+		Preload("Server.ReservedIP").
 		Preload("Deployment").
 		Where("id = ?", id).
 		First(&job).
@@ -63,6 +68,8 @@ func (repo *JobRepository) List(ctx context.Context, accountID account.ID) ([]*p
 	err := repo.db.Model(ctx, &jobs).
 		Preload("ProviderSettings").
 		Preload("Server").
+		Preload("Server.Deployments").
+		Preload("Server.ReservedIP").
 		Preload("Deployment").
 		Where("account_id = ?", accountID).
 		Find(&jobs).Error
@@ -73,6 +80,42 @@ func (repo *JobRepository) List(ctx context.Context, accountID account.ID) ([]*p
 	return jobs, nil
 }
 
+// ListByOrganization lists all jobs owned by a particular Organization.
+func (repo *JobRepository) ListByOrganization(ctx context.Context, orgID account.OrganizationID) ([]*provision.Job, error) {
+	var jobs []*provision.Job
+	err := repo.db.Model(ctx, &jobs).
+		Preload("ProviderSettings").
+		Preload("Server").
+		Preload("Server.Deployments").
+		Preload("Server.ReservedIP").
+		Preload("Deployment").
+		Where("organization_id = ?", orgID).
+		Find(&jobs).Error
+	if err != nil {
+		return nil, errors.Wrap(err, "find jobs by organization")
+	}
+
+	return jobs, nil
+}
+
+// ListByProject lists all jobs for a particular Project.
+func (repo *JobRepository) ListByProject(ctx context.Context, projectID infrastructure.ProjectID) ([]*provision.Job, error) {
+	var jobs []*provision.Job
+	err := repo.db.Model(ctx, &jobs).
+		Preload("ProviderSettings").
+		Preload("Server").
+		Preload("Server.Deployments").
+		Preload("Server.ReservedIP").
+		Preload("Deployment").
+		Where("project_id = ?", projectID).
+		Find(&jobs).Error
+	if err != nil {
+		return nil, errors.Wrap(err, "find jobs by project")
+	}
+
+	return jobs, nil
+}
+
 // Create a new Job.
 func (repo *JobRepository) Create(ctx context.Context, job *provision.Job) error {
 	err := repo.db.Model(ctx, job).Create(job).Error
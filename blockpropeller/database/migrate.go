@@ -2,22 +2,122 @@ package database
 
 import (
 	"blockpropeller.dev/blockpropeller/account"
+	"blockpropeller.dev/blockpropeller/audit"
 	"blockpropeller.dev/blockpropeller/infrastructure"
 	"blockpropeller.dev/blockpropeller/provision"
+	"blockpropeller.dev/lib/server"
 	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
 )
 
 func migrate(db *gorm.DB) error {
 	err := db.AutoMigrate(
 		&account.Account{},
+		&account.Organization{},
+		&account.Membership{},
+		&account.Invitation{},
+		&account.APIKey{},
+		&account.Session{},
+		&account.OneTimeToken{},
+		&account.RecoveryCode{},
+		&account.Identity{},
+		&account.SSOAuthorization{},
+		&infrastructure.Project{},
 		&infrastructure.ProviderSettings{},
+		&infrastructure.ReservedIP{},
 		&infrastructure.Server{},
+		&infrastructure.VolumeSnapshot{},
 		&infrastructure.Deployment{},
 		&provision.Job{},
+		&audit.Entry{},
+		&server.ThrottleState{},
 	).Error
 	if err != nil {
 		return err
 	}
 
+	// Servers created before they referenced their provider settings
+	// take them over from the job that created them.
+	err = db.Exec(`UPDATE servers SET provider_settings_id = (
+		SELECT jobs.provider_settings_id FROM jobs WHERE jobs.server_id = servers.id LIMIT 1
+	) WHERE provider_settings_id IS NULL OR provider_settings_id = ''`).Error
+	if err != nil {
+		return err
+	}
+
+	err = migrateOrganizations(db)
+	if err != nil {
+		return err
+	}
+
+	return migrateDefaultProjects(db)
+}
+
+// migrateOrganizations creates a personal organization for every account without one,
+// and hands the resources created before organizations existed over to it.
+func migrateOrganizations(db *gorm.DB) error {
+	var accounts []*account.Account
+	err := db.Raw(`SELECT * FROM accounts WHERE id NOT IN (
+		SELECT account_id FROM organizations WHERE personal = ?
+	)`, true).Scan(&accounts).Error
+	if err != nil {
+		return errors.Wrap(err, "find accounts without personal organization")
+	}
+
+	for _, acc := range accounts {
+		org := account.NewPersonalOrganization(acc)
+
+		err = db.Create(org).Error
+		if err != nil {
+			return errors.Wrapf(err, "create personal organization for account %s", acc.ID)
+		}
+
+		err = db.Create(account.NewMembership(org.ID, acc.ID, account.RoleOwner)).Error
+		if err != nil {
+			return errors.Wrapf(err, "add owner to personal organization of account %s", acc.ID)
+		}
+	}
+
+	for _, table := range []string{"projects", "provider_settings", "reserved_ips", "servers", "volume_snapshots", "jobs"} {
+		err = db.Exec(`UPDATE `+table+` SET organization_id = (
+			SELECT organizations.id FROM organizations
+			WHERE organizations.account_id = `+table+`.account_id AND organizations.personal = ? LIMIT 1
+		) WHERE organization_id IS NULL OR organization_id = ''`, true).Error
+		if err != nil {
+			return errors.Wrapf(err, "move %s to personal organization", table)
+		}
+	}
+
+	return nil
+}
+
+// migrateDefaultProjects creates a default project for every organization without one,
+// and moves the resources created before projects existed into it.
+func migrateDefaultProjects(db *gorm.DB) error {
+	var orgs []*account.Organization
+	err := db.Raw(`SELECT * FROM organizations WHERE id NOT IN (
+		SELECT organization_id FROM projects WHERE is_default = ? AND organization_id IS NOT NULL
+	)`, true).Scan(&orgs).Error
+	if err != nil {
+		return errors.Wrap(err, "find organizations without default project")
+	}
+
+	for _, org := range orgs {
+		err = db.Create(infrastructure.NewDefaultProject(org.ID, org.AccountID)).Error
+		if err != nil {
+			return errors.Wrapf(err, "create default project for organization %s", org.ID)
+		}
+	}
+
+	for _, table := range []string{"provider_settings", "servers", "jobs"} {
+		err = db.Exec(`UPDATE `+table+` SET project_id = (
+			SELECT projects.id FROM projects
+			WHERE projects.organization_id = `+table+`.organization_id AND projects.is_default = ? LIMIT 1
+		) WHERE project_id IS NULL OR project_id = ''`, true).Error
+		if err != nil {
+			return errors.Wrapf(err, "move %s to default project", table)
+		}
+	}
+
 	return nil
 }
@@ -0,0 +1,100 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"blockpropeller.dev/blockpropeller/account"
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+)
+
+// OneTimeTokenRepository is a databased backed implementation of a account.OneTimeTokenRepository.
+type OneTimeTokenRepository struct {
+	db *DB
+}
+
+// NewOneTimeTokenRepository returns a new OneTimeTokenRepository instance.
+func NewOneTimeTokenRepository(db *DB) *OneTimeTokenRepository {
+	return &OneTimeTokenRepository{db: db}
+}
+
+// FindBySecret returns the OneTimeToken with the given purpose that can be used with the provided secret.
+func (repo *OneTimeTokenRepository) FindBySecret(
+	ctx context.Context,
+	purpose account.TokenPurpose,
+	secret account.OneTimeSecret,
+) (*account.OneTimeToken, error) {
+	var token account.OneTimeToken
+	err := repo.db.Model(ctx, &token).
+		Where("purpose = ? AND secret_hash = ?", purpose, secret.Hash()).
+		First(&token).
+		Error
+	if gorm.IsRecordNotFoundError(err) {
+		return nil, account.ErrOneTimeTokenNotFound
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "find one-time token by secret")
+	}
+
+	return &token, nil
+}
+
+// ListUsable lists the tokens of an Account with the given purpose that can still be used.
+func (repo *OneTimeTokenRepository) ListUsable(
+	ctx context.Context,
+	accountID account.ID,
+	purpose account.TokenPurpose,
+) ([]*account.OneTimeToken, error) {
+	var tokens []*account.OneTimeToken
+	err := repo.db.Model(ctx, &tokens).
+		Where("account_id = ? AND purpose = ? AND used_at IS NULL AND expires_at > ?", accountID, purpose, time.Now()).
+		Find(&tokens).
+		Error
+	if err != nil {
+		return nil, errors.Wrap(err, "list usable one-time tokens")
+	}
+
+	return tokens, nil
+}
+
+// Create a new OneTimeToken.
+func (repo *OneTimeTokenRepository) Create(ctx context.Context, token *account.OneTimeToken) error {
+	err := repo.db.Model(ctx, token).Create(token).Error
+	if err != nil {
+		return errors.Wrap(err, "create one-time token")
+	}
+
+	return nil
+}
+
+// Update an existing OneTimeToken.
+func (repo *OneTimeTokenRepository) Update(ctx context.Context, token *account.OneTimeToken) error {
+	err := repo.db.Model(ctx, token).Save(token).Error
+	if err != nil {
+		return errors.Wrap(err, "update one-time token")
+	}
+
+	return nil
+}
+
+// Use marks an existing OneTimeToken as used, failing with account.ErrOneTimeTokenNotFound if it already was.
+//
+// The token is only updated while still unused, so concurrent requests cannot both use it.
+func (repo *OneTimeTokenRepository) Use(ctx context.Context, token *account.OneTimeToken) error {
+	now := time.Now()
+
+	result := repo.db.Model(ctx, &account.OneTimeToken{}).
+		Where("id = ? AND used_at IS NULL", token.ID).
+		UpdateColumn("used_at", now)
+	if result.Error != nil {
+		return errors.Wrap(result.Error, "use one-time token")
+	}
+	if result.RowsAffected == 0 {
+		return account.ErrOneTimeTokenNotFound
+	}
+
+	token.UsedAt = &now
+
+	return nil
+}
@@ -0,0 +1,247 @@
+package database
+
+import (
+	"context"
+
+	"blockpropeller.dev/blockpropeller/account"
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+)
+
+// OrganizationRepository is a databased backed implementation of a account.OrganizationRepository.
+type OrganizationRepository struct {
+	db *DB
+}
+
+// NewOrganizationRepository returns a new OrganizationRepository instance.
+func NewOrganizationRepository(db *DB) *OrganizationRepository {
+	return &OrganizationRepository{db: db}
+}
+
+// Find an Organization given an OrganizationID.
+func (repo *OrganizationRepository) Find(ctx context.Context, id account.OrganizationID) (*account.Organization, error) {
+	var org account.Organization
+	err := repo.db.Model(ctx, &org).Where("id = ?", id).First(&org).Error
+	if gorm.IsRecordNotFoundError(err) {
+		return nil, account.ErrOrganizationNotFound
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "find organization by ID")
+	}
+
+	return &org, nil
+}
+
+// FindPersonal returns the personal Organization of an Account.
+func (repo *OrganizationRepository) FindPersonal(ctx context.Context, accountID account.ID) (*account.Organization, error) {
+	var org account.Organization
+	err := repo.db.Model(ctx, &org).
+		Where("account_id = ? AND personal = ?", accountID, true).
+		First(&org).
+		Error
+	if gorm.IsRecordNotFoundError(err) {
+		return nil, account.ErrOrganizationNotFound
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "find personal organization")
+	}
+
+	return &org, nil
+}
+
+// Create a new Organization.
+func (repo *OrganizationRepository) Create(ctx context.Context, org *account.Organization) error {
+	err := repo.db.Model(ctx, org).Create(org).Error
+	if err != nil {
+		return errors.Wrap(err, "create organization")
+	}
+
+	return nil
+}
+
+// Update an existing Organization.
+func (repo *OrganizationRepository) Update(ctx context.Context, org *account.Organization) error {
+	err := repo.db.Model(ctx, org).Save(org).Error
+	if err != nil {
+		return errors.Wrap(err, "update organization")
+	}
+
+	return nil
+}
+
+// MembershipRepository is a databased backed implementation of a account.MembershipRepository.
+type MembershipRepository struct {
+	db *DB
+}
+
+// NewMembershipRepository returns a new MembershipRepository instance.
+func NewMembershipRepository(db *DB) *MembershipRepository {
+	return &MembershipRepository{db: db}
+}
+
+// Find the Membership of an Account within an Organization.
+func (repo *MembershipRepository) Find(ctx context.Context, orgID account.OrganizationID, accountID account.ID) (*account.Membership, error) {
+	var membership account.Membership
+	err := repo.db.Model(ctx, &membership).
+		Where("organization_id = ? AND account_id = ?", orgID, accountID).
+		First(&membership).
+		Error
+	if gorm.IsRecordNotFoundError(err) {
+		return nil, account.ErrMembershipNotFound
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "find membership")
+	}
+
+	return &membership, nil
+}
+
+// ListByOrganization lists all the members of an Organization.
+func (repo *MembershipRepository) ListByOrganization(ctx context.Context, orgID account.OrganizationID) ([]*account.Membership, error) {
+	var memberships []*account.Membership
+	err := repo.db.Model(ctx, &memberships).
+		Where("organization_id = ?", orgID).
+		Order("created_at").
+		Find(&memberships).
+		Error
+	if err != nil {
+		return nil, errors.Wrap(err, "list organization memberships")
+	}
+
+	return memberships, nil
+}
+
+// ListByAccount lists all the memberships of an Account.
+func (repo *MembershipRepository) ListByAccount(ctx context.Context, accountID account.ID) ([]*account.Membership, error) {
+	var memberships []*account.Membership
+	err := repo.db.Model(ctx, &memberships).
+		Where("account_id = ?", accountID).
+		Order("created_at").
+		Find(&memberships).
+		Error
+	if err != nil {
+		return nil, errors.Wrap(err, "list account memberships")
+	}
+
+	return memberships, nil
+}
+
+// Create a new Membership.
+func (repo *MembershipRepository) Create(ctx context.Context, membership *account.Membership) error {
+	err := repo.db.Model(ctx, membership).Create(membership).Error
+	if err != nil {
+		return errors.Wrap(err, "create membership")
+	}
+
+	return nil
+}
+
+// Update an existing Membership.
+func (repo *MembershipRepository) Update(ctx context.Context, membership *account.Membership) error {
+	err := repo.db.Model(ctx, membership).Save(membership).Error
+	if err != nil {
+		return errors.Wrap(err, "update membership")
+	}
+
+	return nil
+}
+
+// Delete an existing Membership.
+func (repo *MembershipRepository) Delete(ctx context.Context, membership *account.Membership) error {
+	err := repo.db.Model(ctx, membership).
+		Where("organization_id = ? AND account_id = ?", membership.OrganizationID, membership.AccountID).
+		Delete(&account.Membership{}).
+		Error
+	if err != nil {
+		return errors.Wrap(err, "delete membership")
+	}
+
+	return nil
+}
+
+// InvitationRepository is a databased backed implementation of a account.InvitationRepository.
+type InvitationRepository struct {
+	db *DB
+}
+
+// NewInvitationRepository returns a new InvitationRepository instance.
+func NewInvitationRepository(db *DB) *InvitationRepository {
+	return &InvitationRepository{db: db}
+}
+
+// Find an Invitation given an InvitationID.
+func (repo *InvitationRepository) Find(ctx context.Context, id account.InvitationID) (*account.Invitation, error) {
+	var inv account.Invitation
+	err := repo.db.Model(ctx, &inv).Where("id = ?", id).First(&inv).Error
+	if gorm.IsRecordNotFoundError(err) {
+		return nil, account.ErrInvitationNotFound
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "find invitation by ID")
+	}
+
+	return &inv, nil
+}
+
+// FindByToken returns the Invitation that can be accepted with the provided token.
+func (repo *InvitationRepository) FindByToken(ctx context.Context, token account.InvitationToken) (*account.Invitation, error) {
+	var inv account.Invitation
+	err := repo.db.Model(ctx, &inv).Where("token_hash = ?", token.Hash()).First(&inv).Error
+	if gorm.IsRecordNotFoundError(err) {
+		return nil, account.ErrInvitationNotFound
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "find invitation by token")
+	}
+
+	return &inv, nil
+}
+
+// ListByOrganization lists all the invitations to an Organization.
+func (repo *InvitationRepository) ListByOrganization(ctx context.Context, orgID account.OrganizationID) ([]*account.Invitation, error) {
+	var invitations []*account.Invitation
+	err := repo.db.Model(ctx, &invitations).
+		Where("organization_id = ?", orgID).
+		Order("created_at").
+		Find(&invitations).
+		Error
+	if err != nil {
+		return nil, errors.Wrap(err, "list invitations")
+	}
+
+	return invitations, nil
+}
+
+// Create a new Invitation.
+func (repo *InvitationRepository) Create(ctx context.Context, inv *account.Invitation) error {
+	err := repo.db.Model(ctx, inv).Create(inv).Error
+	if err != nil {
+		return errors.Wrap(err, "create invitation")
+	}
+
+	return nil
+}
+
+// Update an existing Invitation.
+func (repo *InvitationRepository) Update(ctx context.Context, inv *account.Invitation) error {
+	err := repo.db.Model(ctx, inv).Save(inv).Error
+	if err != nil {
+		return errors.Wrap(err, "update invitation")
+	}
+
+	return nil
+}
+
+// Delete an existing Invitation.
+func (repo *InvitationRepository) Delete(ctx context.Context, inv *account.Invitation) error {
+	if inv.ID == account.NilInvitationID {
+		return errors.New("invitation missing ID")
+	}
+
+	err := repo.db.Model(ctx, inv).Delete(inv).Error
+	if err != nil {
+		return errors.Wrap(err, "delete invitation")
+	}
+
+	return nil
+}
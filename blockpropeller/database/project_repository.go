@@ -0,0 +1,100 @@
+package database
+
+import (
+	"context"
+
+	"blockpropeller.dev/blockpropeller/account"
+	"blockpropeller.dev/blockpropeller/infrastructure"
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+)
+
+// ProjectRepository is a databased backed implementation of a infrastructure.ProjectRepository.
+type ProjectRepository struct {
+	db *DB
+}
+
+// NewProjectRepository returns a new ProjectRepository instance.
+func NewProjectRepository(db *DB) *ProjectRepository {
+	return &ProjectRepository{db: db}
+}
+
+// Find a Project given a ProjectID.
+func (repo *ProjectRepository) Find(ctx context.Context, id infrastructure.ProjectID) (*infrastructure.Project, error) {
+	var project infrastructure.Project
+	err := repo.db.Model(ctx, &project).Where("id = ?", id).First(&project).Error
+	if gorm.IsRecordNotFoundError(err) {
+		return nil, infrastructure.ErrProjectNotFound
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "find project by ID")
+	}
+
+	return &project, nil
+}
+
+// FindDefault returns the default Project of an Organization.
+func (repo *ProjectRepository) FindDefault(ctx context.Context, orgID account.OrganizationID) (*infrastructure.Project, error) {
+	var project infrastructure.Project
+	err := repo.db.Model(ctx, &project).
+		Where("organization_id = ? AND is_default = ?", orgID, true).
+		First(&project).
+		Error
+	if gorm.IsRecordNotFoundError(err) {
+		return nil, infrastructure.ErrProjectNotFound
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "find default project")
+	}
+
+	return &project, nil
+}
+
+// List all projects for a particular Organization.
+func (repo *ProjectRepository) List(ctx context.Context, orgID account.OrganizationID) ([]*infrastructure.Project, error) {
+	var projects []*infrastructure.Project
+	err := repo.db.Model(ctx, &projects).
+		Where("organization_id = ?", orgID).
+		Order("created_at").
+		Find(&projects).
+		Error
+	if err != nil {
+		return nil, errors.Wrap(err, "list projects")
+	}
+
+	return projects, nil
+}
+
+// Create a new Project.
+func (repo *ProjectRepository) Create(ctx context.Context, project *infrastructure.Project) error {
+	err := repo.db.Model(ctx, project).Create(project).Error
+	if err != nil {
+		return errors.Wrap(err, "create project")
+	}
+
+	return nil
+}
+
+// Update an existing Project.
+func (repo *ProjectRepository) Update(ctx context.Context, project *infrastructure.Project) error {
+	err := repo.db.Model(ctx, project).Save(project).Error
+	if err != nil {
+		return errors.Wrap(err, "update project")
+	}
+
+	return nil
+}
+
+// Delete an existing Project.
+func (repo *ProjectRepository) Delete(ctx context.Context, project *infrastructure.Project) error {
+	if project.ID == infrastructure.NilProjectID {
+		return errors.New("project missing ID")
+	}
+
+	err := repo.db.Model(ctx, project).Delete(project).Error
+	if err != nil {
+		return errors.Wrap(err, "delete project")
+	}
+
+	return nil
+}
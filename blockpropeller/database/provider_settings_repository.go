@@ -32,6 +32,34 @@ func (repo *ProviderSettingsRepository) List(ctx context.Context, ownerID accoun
 	return settings, nil
 }
 
+// ListByOrganization lists all provider settings owned by a particular Organization.
+func (repo *ProviderSettingsRepository) ListByOrganization(ctx context.Context, orgID account.OrganizationID) ([]*infrastructure.ProviderSettings, error) {
+	var settings []*infrastructure.ProviderSettings
+	err := repo.db.Model(ctx, &settings).
+		Where("organization_id = ?", orgID).
+		Find(&settings).
+		Error
+	if err != nil {
+		return nil, errors.Wrap(err, "list provider settings by organization")
+	}
+
+	return settings, nil
+}
+
+// ListByProject lists all provider settings organized under a particular Project.
+func (repo *ProviderSettingsRepository) ListByProject(ctx context.Context, projectID infrastructure.ProjectID) ([]*infrastructure.ProviderSettings, error) {
+	var settings []*infrastructure.ProviderSettings
+	err := repo.db.Model(ctx, &settings).
+		Where("project_id = ?", projectID).
+		Find(&settings).
+		Error
+	if err != nil {
+		return nil, errors.Wrap(err, "list provider settings by project")
+	}
+
+	return settings, nil
+}
+
 // Find a ProviderSettings given a ProviderSettingsID.
 func (repo *ProviderSettingsRepository) Find(ctx context.Context, id infrastructure.ProviderSettingsID) (*infrastructure.ProviderSettings, error) {
 	var settings infrastructure.ProviderSettings
@@ -0,0 +1,87 @@
+package database
+
+import (
+	"context"
+
+	"blockpropeller.dev/blockpropeller/account"
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+)
+
+// RecoveryCodeRepository is a databased backed implementation of a account.RecoveryCodeRepository.
+type RecoveryCodeRepository struct {
+	db *DB
+}
+
+// NewRecoveryCodeRepository returns a new RecoveryCodeRepository instance.
+func NewRecoveryCodeRepository(db *DB) *RecoveryCodeRepository {
+	return &RecoveryCodeRepository{db: db}
+}
+
+// FindUnused returns the unused RecoveryCode of an Account that matches the provided secret.
+func (repo *RecoveryCodeRepository) FindUnused(
+	ctx context.Context,
+	accountID account.ID,
+	secret account.RecoverySecret,
+) (*account.RecoveryCode, error) {
+	var code account.RecoveryCode
+	err := repo.db.Model(ctx, &code).
+		Where("account_id = ? AND secret_hash = ? AND used_at IS NULL", accountID, secret.Hash()).
+		First(&code).
+		Error
+	if gorm.IsRecordNotFoundError(err) {
+		return nil, account.ErrRecoveryCodeNotFound
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "find unused recovery code")
+	}
+
+	return &code, nil
+}
+
+// CountUnused returns the number of RecoveryCodes an Account has left.
+func (repo *RecoveryCodeRepository) CountUnused(ctx context.Context, accountID account.ID) (int, error) {
+	var count int
+	err := repo.db.Model(ctx, &account.RecoveryCode{}).
+		Where("account_id = ? AND used_at IS NULL", accountID).
+		Count(&count).
+		Error
+	if err != nil {
+		return 0, errors.Wrap(err, "count unused recovery codes")
+	}
+
+	return count, nil
+}
+
+// Create a new RecoveryCode.
+func (repo *RecoveryCodeRepository) Create(ctx context.Context, code *account.RecoveryCode) error {
+	err := repo.db.Model(ctx, code).Create(code).Error
+	if err != nil {
+		return errors.Wrap(err, "create recovery code")
+	}
+
+	return nil
+}
+
+// Update an existing RecoveryCode.
+func (repo *RecoveryCodeRepository) Update(ctx context.Context, code *account.RecoveryCode) error {
+	err := repo.db.Model(ctx, code).Save(code).Error
+	if err != nil {
+		return errors.Wrap(err, "update recovery code")
+	}
+
+	return nil
+}
+
+// DeleteByAccount deletes all the RecoveryCodes of an Account.
+func (repo *RecoveryCodeRepository) DeleteByAccount(ctx context.Context, accountID account.ID) error {
+	err := repo.db.Model(ctx, &account.RecoveryCode{}).
+		Where("account_id = ?", accountID).
+		Delete(&account.RecoveryCode{}).
+		Error
+	if err != nil {
+		return errors.Wrap(err, "delete recovery codes")
+	}
+
+	return nil
+}
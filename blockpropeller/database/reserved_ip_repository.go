@@ -0,0 +1,92 @@
+package database
+
+import (
+	"context"
+
+	"blockpropeller.dev/blockpropeller/account"
+	"blockpropeller.dev/blockpropeller/infrastructure"
+	"github.com/pkg/errors"
+)
+
+// ReservedIPRepository is a databased backed implementation of a infrastructure.ReservedIPRepository.
+type ReservedIPRepository struct {
+	db *DB
+}
+
+// NewReservedIPRepository returns a new ReservedIPRepository instance.
+func NewReservedIPRepository(db *DB) *ReservedIPRepository {
+	return &ReservedIPRepository{db: db}
+}
+
+// Find a ReservedIP given a ReservedIPID.
+func (repo *ReservedIPRepository) Find(ctx context.Context, id infrastructure.ReservedIPID) (*infrastructure.ReservedIP, error) {
+	var ip infrastructure.ReservedIP
+	err := repo.db.Model(ctx, &ip).Where("id = ?", id).First(&ip).Error
+	if err != nil {
+		return nil, errors.Wrap(err, "find reserved ip by ID")
+	}
+
+	return &ip, nil
+}
+
+// List all reserved IPs for a particular Account.
+func (repo *ReservedIPRepository) List(ctx context.Context, accountID account.ID) ([]*infrastructure.ReservedIP, error) {
+	var ips []*infrastructure.ReservedIP
+	err := repo.db.Model(ctx, &ips).
+		Where("account_id = ?", accountID).
+		Find(&ips).
+		Error
+	if err != nil {
+		return nil, errors.Wrap(err, "list reserved ips")
+	}
+
+	return ips, nil
+}
+
+// ListByOrganization lists all reserved IPs owned by a particular Organization.
+func (repo *ReservedIPRepository) ListByOrganization(ctx context.Context, orgID account.OrganizationID) ([]*infrastructure.ReservedIP, error) {
+	var ips []*infrastructure.ReservedIP
+	err := repo.db.Model(ctx, &ips).
+		Where("organization_id = ?", orgID).
+		Find(&ips).
+		Error
+	if err != nil {
+		return nil, errors.Wrap(err, "list reserved ips by organization")
+	}
+
+	return ips, nil
+}
+
+// Create a new ReservedIP.
+func (repo *ReservedIPRepository) Create(ctx context.Context, ip *infrastructure.ReservedIP) error {
+	err := repo.db.Model(ctx, ip).Create(ip).Error
+	if err != nil {
+		return errors.Wrap(err, "create reserved ip")
+	}
+
+	return nil
+}
+
+// Update an existing ReservedIP.
+func (repo *ReservedIPRepository) Update(ctx context.Context, ip *infrastructure.ReservedIP) error {
+	err := repo.db.Model(ctx, ip).Save(ip).Error
+	if err != nil {
+		return errors.Wrap(err, "update reserved ip")
+	}
+
+	return nil
+}
+
+// Delete an existing ReservedIP.
+func (repo *ReservedIPRepository) Delete(ctx context.Context, ip *infrastructure.ReservedIP) error {
+	if ip.ID == infrastructure.NilReservedIPID {
+		return errors.New("reserved ip missing ID")
+	}
+
+	err := repo.db.Model(ctx, ip).Delete(ip).Error
+	if err != nil {
+		return errors.Wrap(err, "delete reserved ip")
+	}
+
+	return nil
+}
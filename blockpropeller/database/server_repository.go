@@ -5,6 +5,7 @@ import (
 
 	"blockpropeller.dev/blockpropeller/account"
 	"blockpropeller.dev/blockpropeller/infrastructure"
+	"github.com/jinzhu/gorm"
 	"github.com/pkg/errors"
 )
 
@@ -23,6 +24,8 @@ func (repo *ServerRepository) Find(ctx context.Context, id infrastructure.Server
 	var server infrastructure.Server
 	err := repo.db.Model(ctx, &server).
 		Preload("Deployments").
+		Preload("ReservedIP").
+		Preload("VolumeSnapshot", unscoped).
 		Where("id = ?", id).
 		First(&server).
 		Error
@@ -38,6 +41,8 @@ func (repo *ServerRepository) List(ctx context.Context, accountID account.ID) ([
 	var servers []*infrastructure.Server
 	err := repo.db.Model(ctx, &servers).
 		Preload("Deployments").
+		Preload("ReservedIP").
+		Preload("VolumeSnapshot", unscoped).
 		Where("account_id = ?", accountID).
 		Find(&servers).Error
 	if err != nil {
@@ -47,6 +52,54 @@ func (repo *ServerRepository) List(ctx context.Context, accountID account.ID) ([
 	return servers, nil
 }
 
+// ListByOrganization lists all servers owned by a particular Organization.
+func (repo *ServerRepository) ListByOrganization(ctx context.Context, orgID account.OrganizationID) ([]*infrastructure.Server, error) {
+	var servers []*infrastructure.Server
+	err := repo.db.Model(ctx, &servers).
+		Preload("Deployments").
+		Preload("ReservedIP").
+		Preload("VolumeSnapshot", unscoped).
+		Where("organization_id = ?", orgID).
+		Find(&servers).Error
+	if err != nil {
+		return nil, errors.Wrap(err, "find servers by organization")
+	}
+
+	return servers, nil
+}
+
+// ListByProject lists all servers organized under a particular Project.
+func (repo *ServerRepository) ListByProject(ctx context.Context, projectID infrastructure.ProjectID) ([]*infrastructure.Server, error) {
+	var servers []*infrastructure.Server
+	err := repo.db.Model(ctx, &servers).
+		Preload("Deployments").
+		Preload("ReservedIP").
+		Preload("VolumeSnapshot", unscoped).
+		Where("project_id = ?", projectID).
+		Find(&servers).Error
+	if err != nil {
+		return nil, errors.Wrap(err, "find servers by project")
+	}
+
+	return servers, nil
+}
+
+// ListByState lists the servers in the provided state across all Accounts.
+func (repo *ServerRepository) ListByState(ctx context.Context, state infrastructure.ServerState) ([]*infrastructure.Server, error) {
+	var servers []*infrastructure.Server
+	err := repo.db.Model(ctx, &servers).
+		Preload("Deployments").
+		Preload("ReservedIP").
+		Preload("VolumeSnapshot", unscoped).
+		Where("state = ?", state).
+		Find(&servers).Error
+	if err != nil {
+		return nil, errors.Wrap(err, "find servers by state")
+	}
+
+	return servers, nil
+}
+
 // Create a new Server.
 func (repo *ServerRepository) Create(ctx context.Context, server *infrastructure.Server) error {
 	err := repo.db.Model(ctx, server).Create(server).Error
@@ -87,3 +140,11 @@ func (repo *ServerRepository) Delete(ctx context.Context, srv *infrastructure.Se
 
 	return nil
 }
+
+// unscoped includes deleted records when preloading associations.
+//
+// Servers keep referencing the volume snapshots their data volume was created from,
+// as the volume is managed with the snapshot ID even after the snapshot is deleted.
+func unscoped(db *gorm.DB) *gorm.DB {
+	return db.Unscoped()
+}
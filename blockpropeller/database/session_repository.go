@@ -0,0 +1,84 @@
+package database
+
+import (
+	"context"
+
+	"blockpropeller.dev/blockpropeller/account"
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+)
+
+// SessionRepository is a databased backed implementation of a account.SessionRepository.
+type SessionRepository struct {
+	db *DB
+}
+
+// NewSessionRepository returns a new SessionRepository instance.
+func NewSessionRepository(db *DB) *SessionRepository {
+	return &SessionRepository{db: db}
+}
+
+// Find a Session given a SessionID.
+func (repo *SessionRepository) Find(ctx context.Context, id account.SessionID) (*account.Session, error) {
+	var session account.Session
+	err := repo.db.Model(ctx, &session).Where("id = ?", id).First(&session).Error
+	if gorm.IsRecordNotFoundError(err) {
+		return nil, account.ErrSessionNotFound
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "find session by ID")
+	}
+
+	return &session, nil
+}
+
+// FindByRefreshToken returns the Session the provided token is the current or the previous refresh token of.
+func (repo *SessionRepository) FindByRefreshToken(ctx context.Context, token account.RefreshToken) (*account.Session, error) {
+	var session account.Session
+	err := repo.db.Model(ctx, &session).
+		Where("refresh_token_hash = ? OR previous_refresh_token_hash = ?", token.Hash(), token.Hash()).
+		First(&session).
+		Error
+	if gorm.IsRecordNotFoundError(err) {
+		return nil, account.ErrSessionNotFound
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "find session by refresh token")
+	}
+
+	return &session, nil
+}
+
+// ListActiveByAccount lists the sessions of an Account that were not revoked.
+func (repo *SessionRepository) ListActiveByAccount(ctx context.Context, accountID account.ID) ([]*account.Session, error) {
+	var sessions []*account.Session
+	err := repo.db.Model(ctx, &sessions).
+		Where("account_id = ? AND revoked_at IS NULL", accountID).
+		Find(&sessions).
+		Error
+	if err != nil {
+		return nil, errors.Wrap(err, "list active account sessions")
+	}
+
+	return sessions, nil
+}
+
+// Create a new Session.
+func (repo *SessionRepository) Create(ctx context.Context, session *account.Session) error {
+	err := repo.db.Model(ctx, session).Create(session).Error
+	if err != nil {
+		return errors.Wrap(err, "create session")
+	}
+
+	return nil
+}
+
+// Update an existing Session.
+func (repo *SessionRepository) Update(ctx context.Context, session *account.Session) error {
+	err := repo.db.Model(ctx, session).Save(session).Error
+	if err != nil {
+		return errors.Wrap(err, "update session")
+	}
+
+	return nil
+}
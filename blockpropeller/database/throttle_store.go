@@ -0,0 +1,58 @@
+package database
+
+import (
+	"context"
+
+	"blockpropeller.dev/lib/server"
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+)
+
+// ThrottleStore is a databased backed implementation of a server.ThrottleStore.
+type ThrottleStore struct {
+	db *DB
+}
+
+// NewThrottleStore returns a new ThrottleStore instance.
+func NewThrottleStore(db *DB) *ThrottleStore {
+	return &ThrottleStore{db: db}
+}
+
+// Find the ThrottleState of a key.
+func (store *ThrottleStore) Find(ctx context.Context, key string) (*server.ThrottleState, error) {
+	var state server.ThrottleState
+	err := store.db.Model(ctx, &state).
+		Where(&server.ThrottleState{Key: key}).
+		First(&state).
+		Error
+	if gorm.IsRecordNotFoundError(err) {
+		return nil, server.ErrThrottleStateNotFound
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "find throttle state")
+	}
+
+	return &state, nil
+}
+
+// Save the ThrottleState of a key, creating it if it does not exist yet.
+func (store *ThrottleStore) Save(ctx context.Context, state *server.ThrottleState) error {
+	err := store.db.Model(ctx, state).Save(state).Error
+	if err != nil {
+		return errors.Wrap(err, "save throttle state")
+	}
+
+	return nil
+}
+
+// Delete the ThrottleState of a key, forgetting all its failed attempts.
+func (store *ThrottleStore) Delete(ctx context.Context, key string) error {
+	state := &server.ThrottleState{Key: key}
+
+	err := store.db.Model(ctx, state).Delete(state).Error
+	if err != nil {
+		return errors.Wrap(err, "delete throttle state")
+	}
+
+	return nil
+}
@@ -0,0 +1,120 @@
+package database
+
+import (
+	"context"
+
+	"blockpropeller.dev/blockpropeller/account"
+	"blockpropeller.dev/blockpropeller/infrastructure"
+	"github.com/pkg/errors"
+)
+
+// VolumeSnapshotRepository is a databased backed implementation of a infrastructure.VolumeSnapshotRepository.
+type VolumeSnapshotRepository struct {
+	db *DB
+}
+
+// NewVolumeSnapshotRepository returns a new VolumeSnapshotRepository instance.
+func NewVolumeSnapshotRepository(db *DB) *VolumeSnapshotRepository {
+	return &VolumeSnapshotRepository{db: db}
+}
+
+// Find a VolumeSnapshot given a VolumeSnapshotID.
+func (repo *VolumeSnapshotRepository) Find(ctx context.Context, id infrastructure.VolumeSnapshotID) (*infrastructure.VolumeSnapshot, error) {
+	var snap infrastructure.VolumeSnapshot
+	err := repo.db.Model(ctx, &snap).Where("id = ?", id).First(&snap).Error
+	if err != nil {
+		return nil, errors.Wrap(err, "find volume snapshot by ID")
+	}
+
+	return &snap, nil
+}
+
+// List all volume snapshots for a particular Account.
+func (repo *VolumeSnapshotRepository) List(ctx context.Context, accountID account.ID) ([]*infrastructure.VolumeSnapshot, error) {
+	var snapshots []*infrastructure.VolumeSnapshot
+	err := repo.db.Model(ctx, &snapshots).
+		Where("account_id = ?", accountID).
+		Find(&snapshots).
+		Error
+	if err != nil {
+		return nil, errors.Wrap(err, "list volume snapshots")
+	}
+
+	return snapshots, nil
+}
+
+// ListByOrganization lists all volume snapshots owned by a particular Organization.
+func (repo *VolumeSnapshotRepository) ListByOrganization(ctx context.Context, orgID account.OrganizationID) ([]*infrastructure.VolumeSnapshot, error) {
+	var snapshots []*infrastructure.VolumeSnapshot
+	err := repo.db.Model(ctx, &snapshots).
+		Where("organization_id = ?", orgID).
+		Find(&snapshots).
+		Error
+	if err != nil {
+		return nil, errors.Wrap(err, "list volume snapshots by organization")
+	}
+
+	return snapshots, nil
+}
+
+// ListByServer lists all volume snapshots taken of a particular Server.
+func (repo *VolumeSnapshotRepository) ListByServer(ctx context.Context, srvID infrastructure.ServerID) ([]*infrastructure.VolumeSnapshot, error) {
+	var snapshots []*infrastructure.VolumeSnapshot
+	err := repo.db.Model(ctx, &snapshots).
+		Where("server_id = ?", srvID).
+		Find(&snapshots).
+		Error
+	if err != nil {
+		return nil, errors.Wrap(err, "list server volume snapshots")
+	}
+
+	return snapshots, nil
+}
+
+// ListByState lists the volume snapshots in the provided state across all Accounts.
+func (repo *VolumeSnapshotRepository) ListByState(ctx context.Context, state infrastructure.VolumeSnapshotState) ([]*infrastructure.VolumeSnapshot, error) {
+	var snapshots []*infrastructure.VolumeSnapshot
+	err := repo.db.Model(ctx, &snapshots).
+		Where("state = ?", state).
+		Find(&snapshots).
+		Error
+	if err != nil {
+		return nil, errors.Wrap(err, "list volume snapshots by state")
+	}
+
+	return snapshots, nil
+}
+
+// Create a new VolumeSnapshot.
+func (repo *VolumeSnapshotRepository) Create(ctx context.Context, snap *infrastructure.VolumeSnapshot) error {
+	err := repo.db.Model(ctx, snap).Create(snap).Error
+	if err != nil {
+		return errors.Wrap(err, "create volume snapshot")
+	}
+
+	return nil
+}
+
+// Update an existing VolumeSnapshot.
+func (repo *VolumeSnapshotRepository) Update(ctx context.Context, snap *infrastructure.VolumeSnapshot) error {
+	err := repo.db.Model(ctx, snap).Save(snap).Error
+	if err != nil {
+		return errors.Wrap(err, "update volume snapshot")
+	}
+
+	return nil
+}
+
+// Delete an existing VolumeSnapshot.
+func (repo *VolumeSnapshotRepository) Delete(ctx context.Context, snap *infrastructure.VolumeSnapshot) error {
+	if snap.ID == infrastructure.NilVolumeSnapshotID {
+		return errors.New("volume snapshot missing ID")
+	}
+
+	err := repo.db.Model(ctx, snap).Delete(snap).Error
+	if err != nil {
+		return errors.Wrap(err, "delete volume snapshot")
+	}
+
+	return nil
+}
@@ -1,16 +1,138 @@
 package encryption
 
-import "github.com/pkg/errors"
+import (
+	"os"
+	"regexp"
+
+	"github.com/pkg/errors"
+)
+
+// Key providers supported by the encryption module.
+const (
+	ProviderLocal = "local"
+	ProviderVault = "vault"
+)
+
+// DefaultKeyID identifies the active key when no ID is configured.
+const DefaultKeyID = "default"
+
+// keyIDPattern restricts the key IDs to characters that can be stored in the ciphertext header.
+var keyIDPattern = regexp.MustCompile(`^[A-Za-z0-9_.-]{1,64}$`)
 
 // Config for the encryption module.
 type Config struct {
-	Secret string `yaml:"secret"`
+	// KeyConfig of the active key, which encrypts all new data.
+	KeyConfig `yaml:",inline"`
+
+	// PreviousKeys only decrypt the data encrypted before the active key was rotated in,
+	// until `blockctl admin encryption rotate` re-encrypts it with the active key.
+	PreviousKeys []*KeyConfig `yaml:"previous_keys"`
 }
 
 // Validate satisfies the Config interface.
 func (cfg *Config) Validate() error {
-	if cfg.Secret == "" {
-		return errors.New("missing encryption secret key")
+	if cfg.ID == "" {
+		cfg.ID = DefaultKeyID
+	}
+
+	err := cfg.KeyConfig.Validate()
+	if err != nil {
+		return errors.Wrap(err, "invalid active key")
+	}
+
+	ids := map[string]bool{cfg.ID: true}
+	for _, key := range cfg.PreviousKeys {
+		if key.ID == "" {
+			return errors.New("missing previous key id")
+		}
+		if ids[key.ID] {
+			return errors.Errorf("duplicate key id: %s", key.ID)
+		}
+		ids[key.ID] = true
+
+		err := key.Validate()
+		if err != nil {
+			return errors.Wrapf(err, "invalid previous key %s", key.ID)
+		}
+	}
+
+	return nil
+}
+
+// KeyConfig configures a key-encryption key.
+type KeyConfig struct {
+	// ID of the key, stored in the header of every ciphertext it encrypts.
+	ID string `yaml:"id"`
+
+	// Provider of the key-encryption key, either "local" or "vault".
+	Provider string `yaml:"provider"`
+
+	// Secret is used to derive the local key-encryption key when no keyfile is configured,
+	// as well as to decrypt the data encrypted before envelope encryption was introduced.
+	Secret string `yaml:"secret"`
+	// KeyFile holds the key material of the local key-encryption key.
+	KeyFile string `yaml:"keyfile"`
+
+	Vault *VaultConfig `yaml:"vault"`
+}
+
+// Validate satisfies the Config interface.
+func (cfg *KeyConfig) Validate() error {
+	if !keyIDPattern.MatchString(cfg.ID) {
+		return errors.Errorf("invalid key id: %q", cfg.ID)
+	}
+
+	if cfg.Provider == "" {
+		cfg.Provider = ProviderLocal
+	}
+
+	switch cfg.Provider {
+	case ProviderLocal:
+		if cfg.Secret == "" && cfg.KeyFile == "" {
+			return errors.New("missing encryption secret key or keyfile")
+		}
+	case ProviderVault:
+		if cfg.Vault == nil {
+			return errors.New("missing vault config")
+		}
+		if err := cfg.Vault.Validate(); err != nil {
+			return err
+		}
+		if cfg.Vault.Address == "" || cfg.Vault.Key == "" {
+			return errors.New("missing vault address or transit key name")
+		}
+		if cfg.Vault.Token == "" {
+			return errors.New("missing vault token")
+		}
+	default:
+		return errors.Errorf("unsupported encryption provider: %s", cfg.Provider)
+	}
+
+	return nil
+}
+
+// VaultConfig for wrapping data keys with a HashiCorp Vault transit key.
+type VaultConfig struct {
+	Address string `yaml:"address"`
+	// Token authenticating to Vault, read from the VAULT_TOKEN environment variable if left empty.
+	Token     string `yaml:"token"`
+	Namespace string `yaml:"namespace"`
+
+	// Mount path of the transit secrets engine.
+	Mount string `yaml:"mount"`
+	// Key is the name of the transit key wrapping the data keys.
+	Key string `yaml:"key"`
+}
+
+// Validate satisfies the Config interface.
+//
+// Required values are checked by the parent KeyConfig, only when Vault is the configured provider.
+func (cfg *VaultConfig) Validate() error {
+	if cfg.Token == "" {
+		cfg.Token = os.Getenv("VAULT_TOKEN")
+	}
+	if cfg.Mount == "" {
+		cfg.Mount = "transit"
 	}
 
 	return nil
@@ -1,86 +1,273 @@
+// Package encryption encrypts the sensitive data stored by BlockPropeller, such as provider credentials,
+// SSH keys and Terraform state.
+//
+// Data is encrypted using envelope encryption. Every record is encrypted with its own random data key,
+// which is in turn wrapped by a key-encryption key from a KeyProvider and stored alongside the record.
+//
+// Ciphertexts carry the ID of their key-encryption key in a versioned header, so several keys
+// can decrypt data at the same time, while only the active key encrypts new data.
 package encryption
 
 import (
-	"crypto/aes"
-	"crypto/cipher"
+	"bytes"
+	"context"
 	"crypto/md5"
 	"crypto/rand"
 	"encoding/base64"
+	"encoding/binary"
 	"encoding/hex"
 	"io"
+	"math"
 
 	"github.com/pkg/errors"
 )
 
-var secretKey string
+// versionPrefix marks the ciphertexts, followed by the key ID and a colon.
+// Legacy ciphertexts cannot start with it, as they are plain base64.
+const versionPrefix = "v1:"
 
-// Init the encryption module.
+// ErrUnknownKey is returned when decrypting data encrypted with a key which is not configured.
+var ErrUnknownKey = errors.New("unknown encryption key")
+
+var std *Encrypter
+
+// Init the encryption module with a key-encryption key derived from the secret.
 func Init(s string) {
-	secretKey = s
+	provider, err := NewSecretKeyProvider(s)
+	if err != nil {
+		panic(errors.Wrap(err, "init encryption module"))
+	}
+
+	std = NewEncrypter(DefaultKeyID, provider).WithLegacySecret(s)
+}
+
+// Configure the encryption module with the keys set up by the Config.
+func Configure(cfg *Config) error {
+	enc, err := NewEncrypterFromConfig(cfg)
+	if err != nil {
+		return err
+	}
+
+	std = enc
+
+	return nil
 }
 
 // Encrypt sensitive data and return the encrypted result.
 func Encrypt(data []byte) ([]byte, error) {
-	block, err := aes.NewCipher(getKey())
+	return encrypter().Encrypt(context.Background(), data)
+}
+
+// Decrypt sensitive data from an encrypted result.
+func Decrypt(data []byte) ([]byte, error) {
+	return encrypter().Decrypt(context.Background(), data)
+}
+
+// IsCurrent checks whether the encrypted data is encrypted with the active key.
+func IsCurrent(data []byte) bool {
+	return encrypter().IsCurrent(data)
+}
+
+// ActiveKeyID returns the ID of the key encrypting new data.
+func ActiveKeyID() string {
+	return encrypter().ActiveKeyID()
+}
+
+func encrypter() *Encrypter {
+	if std == nil {
+		panic("encryption module not initialized")
+	}
+
+	return std
+}
+
+// Encrypter encrypts data with per-record data keys wrapped by the active KeyProvider,
+// and decrypts data wrapped by any of its KeyProviders.
+type Encrypter struct {
+	activeID string
+
+	providers  map[string]KeyProvider
+	legacyKeys [][]byte
+}
+
+// NewEncrypter returns a new Encrypter instance encrypting data with the provided active key.
+func NewEncrypter(keyID string, provider KeyProvider) *Encrypter {
+	e := &Encrypter{
+		activeID:  keyID,
+		providers: make(map[string]KeyProvider),
+	}
+
+	return e.WithDecryptionKey(keyID, provider)
+}
+
+// NewEncrypterFromConfig returns a new Encrypter with the active and previous keys of the Config.
+func NewEncrypterFromConfig(cfg *Config) (*Encrypter, error) {
+	provider, err := NewKeyProvider(&cfg.KeyConfig)
 	if err != nil {
-		return nil, errors.Wrap(err, "new cypher")
+		return nil, errors.Wrapf(err, "new key provider %s", cfg.ID)
+	}
+
+	enc := NewEncrypter(cfg.ID, provider).WithLegacySecret(cfg.Secret)
+
+	for _, key := range cfg.PreviousKeys {
+		provider, err := NewKeyProvider(key)
+		if err != nil {
+			return nil, errors.Wrapf(err, "new key provider %s", key.ID)
+		}
+
+		enc.WithDecryptionKey(key.ID, provider).WithLegacySecret(key.Secret)
+	}
+
+	return enc, nil
+}
+
+// WithDecryptionKey allows the Encrypter to decrypt data encrypted with another key.
+func (e *Encrypter) WithDecryptionKey(keyID string, provider KeyProvider) *Encrypter {
+	e.providers[keyID] = provider
+
+	return e
+}
+
+// WithLegacySecret allows the Encrypter to decrypt data encrypted
+// directly with the secret, before envelope encryption was introduced.
+func (e *Encrypter) WithLegacySecret(secret string) *Encrypter {
+	if secret == "" {
+		return e
+	}
+
+	hash := md5.Sum([]byte(secret))
+	e.legacyKeys = append(e.legacyKeys, []byte(hex.EncodeToString(hash[:])))
+
+	return e
+}
+
+// ActiveKeyID returns the ID of the key encrypting new data.
+func (e *Encrypter) ActiveKeyID() string {
+	return e.activeID
+}
+
+// KeyID returns the ID of the key the data is encrypted with,
+// or an empty string for data encrypted before ciphertexts carried their key ID.
+func (e *Encrypter) KeyID(data []byte) string {
+	if !bytes.HasPrefix(data, []byte(versionPrefix)) {
+		return ""
+	}
+
+	header := data[len(versionPrefix):]
+	end := bytes.IndexByte(header, ':')
+	if end < 0 {
+		return ""
+	}
+
+	return string(header[:end])
+}
+
+// IsCurrent checks whether the data is encrypted with the active key.
+func (e *Encrypter) IsCurrent(data []byte) bool {
+	return e.KeyID(data) == e.activeID
+}
+
+// Encrypt the data with a new data key and return the encoded envelope, prefixed by a header with the key ID.
+//
+// The envelope holds the length of the wrapped data key, the wrapped data key itself,
+// and the data sealed with AES-256-GCM, authenticating the header along with it.
+func (e *Encrypter) Encrypt(ctx context.Context, data []byte) ([]byte, error) {
+	dataKey := make([]byte, keySize)
+	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+		return nil, errors.Wrap(err, "generate data key")
 	}
 
-	gcm, err := cipher.NewGCM(block)
+	wrapped, err := e.providers[e.activeID].WrapKey(ctx, dataKey)
 	if err != nil {
-		return nil, errors.Wrap(err, "new gcm cypher")
+		return nil, errors.Wrap(err, "wrap data key")
+	}
+	if len(wrapped) > math.MaxUint16 {
+		return nil, errors.New("wrapped data key too long")
 	}
 
-	nonce := make([]byte, gcm.NonceSize())
-	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
-		return nil, errors.Wrap(err, "read nonce")
+	header := versionPrefix + e.activeID + ":"
+
+	sealed, err := seal(dataKey, data, []byte(header))
+	if err != nil {
+		return nil, errors.Wrap(err, "seal data")
 	}
 
-	ciphertext := gcm.Seal(nonce, nonce, data, nil)
+	var envelope bytes.Buffer
+	_ = binary.Write(&envelope, binary.BigEndian, uint16(len(wrapped)))
+	envelope.Write(wrapped)
+	envelope.Write(sealed)
 
-	encoded := base64.StdEncoding.EncodeToString(ciphertext)
+	encoded := header + base64.StdEncoding.EncodeToString(envelope.Bytes())
 
 	return []byte(encoded), nil
 }
 
-// Decrypt sensitive data from an encrypted result.
-func Decrypt(data []byte) ([]byte, error) {
+// Decrypt the data from an encoded envelope, or from a legacy ciphertext if a legacy secret is set.
+func (e *Encrypter) Decrypt(ctx context.Context, data []byte) ([]byte, error) {
+	switch {
+	case bytes.HasPrefix(data, []byte(versionPrefix)):
+		keyID := e.KeyID(data)
+
+		provider, ok := e.providers[keyID]
+		if !ok {
+			return nil, errors.Wrapf(ErrUnknownKey, "key %q", keyID)
+		}
+
+		header := versionPrefix + keyID + ":"
+
+		return openEnvelope(ctx, provider, data[len(header):], []byte(header))
+	default:
+		return e.decryptLegacy(data)
+	}
+}
+
+// decryptLegacy decrypts data encrypted directly with the MD5 digest of a secret, trying every secret in turn.
+func (e *Encrypter) decryptLegacy(data []byte) ([]byte, error) {
+	if len(e.legacyKeys) == 0 {
+		return nil, errors.New("legacy ciphertext requires an encryption secret")
+	}
+
 	data, err := base64.StdEncoding.DecodeString(string(data))
 	if err != nil {
 		return nil, errors.Wrap(err, "base64 decode data")
 	}
 
-	block, err := aes.NewCipher(getKey())
-	if err != nil {
-		return nil, errors.Wrap(err, "new cypher")
+	for _, key := range e.legacyKeys {
+		var plaintext []byte
+		plaintext, err = open(key, data, nil)
+		if err == nil {
+			return plaintext, nil
+		}
 	}
 
-	gcm, err := cipher.NewGCM(block)
+	return nil, err
+}
+
+// openEnvelope decodes the envelope, unwraps its data key and opens the sealed data.
+func openEnvelope(ctx context.Context, provider KeyProvider, data, additionalData []byte) ([]byte, error) {
+	envelope, err := base64.StdEncoding.DecodeString(string(data))
 	if err != nil {
-		return nil, errors.Wrap(err, "new gcm cypher")
+		return nil, errors.Wrap(err, "base64 decode data")
+	}
+	if len(envelope) < 2 {
+		return nil, errors.New("envelope too short")
 	}
 
-	nonceSize := gcm.NonceSize()
-	nonce, ciphertext := data[:nonceSize], data[nonceSize:]
+	wrappedLen := int(binary.BigEndian.Uint16(envelope))
+	envelope = envelope[2:]
+	if len(envelope) < wrappedLen {
+		return nil, errors.New("envelope too short")
+	}
 
-	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	dataKey, err := provider.UnwrapKey(ctx, envelope[:wrappedLen])
 	if err != nil {
-		return nil, errors.Wrap(err, "get plaintext")
+		return nil, errors.Wrap(err, "unwrap data key")
 	}
 
-	return plaintext, nil
-}
-
-func getKey() []byte {
-	if secretKey == "" {
-		panic("encryption module not initialized")
+	plaintext, err := open(dataKey, envelope[wrappedLen:], additionalData)
+	if err != nil {
+		return nil, errors.Wrap(err, "open data")
 	}
 
-	return []byte(createHash(secretKey))
-}
-
-func createHash(key string) string {
-	hasher := md5.New()
-	hasher.Write([]byte(key))
-	return hex.EncodeToString(hasher.Sum(nil))
+	return plaintext, nil
 }
@@ -0,0 +1,226 @@
+package encryption_test
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/md5"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"blockpropeller.dev/blockpropeller/encryption"
+	"blockpropeller.dev/lib/test"
+	"github.com/pkg/errors"
+)
+
+// legacyEncrypt encrypts the data the way it was encrypted before envelope encryption was introduced.
+func legacyEncrypt(t *testing.T, secret string, data []byte) []byte {
+	hash := md5.Sum([]byte(secret))
+
+	block, err := aes.NewCipher([]byte(hex.EncodeToString(hash[:])))
+	test.CheckErr(t, "new legacy cipher", err)
+	gcm, err := cipher.NewGCM(block)
+	test.CheckErr(t, "new legacy gcm", err)
+
+	nonce := make([]byte, gcm.NonceSize())
+	_, err = io.ReadFull(rand.Reader, nonce)
+	test.CheckErr(t, "read legacy nonce", err)
+
+	return []byte(base64.StdEncoding.EncodeToString(gcm.Seal(nonce, nonce, data, nil)))
+}
+
+func newKeyFile(t *testing.T, dir, name, material string) string {
+	path := filepath.Join(dir, name)
+	err := ioutil.WriteFile(path, []byte(material), 0600)
+	test.CheckErr(t, "write keyfile", err)
+
+	return path
+}
+
+func TestEncrypterRoundTrip(t *testing.T) {
+	provider, err := encryption.NewSecretKeyProvider("SuperSecret")
+	test.CheckErr(t, "new secret key provider", err)
+
+	enc := encryption.NewEncrypter("primary", provider)
+	ctx := context.Background()
+
+	first, err := enc.Encrypt(ctx, []byte("provider credentials"))
+	test.CheckErr(t, "encrypt", err)
+	second, err := enc.Encrypt(ctx, []byte("provider credentials"))
+	test.CheckErr(t, "encrypt again", err)
+
+	test.AssertBoolEqual(t, "versioned header", strings.HasPrefix(string(first), "v1:primary:"), true)
+	test.AssertBoolEqual(t, "unique ciphertexts", string(first) == string(second), false)
+
+	decrypted, err := enc.Decrypt(ctx, first)
+	test.CheckErr(t, "decrypt", err)
+	test.AssertStringsEqual(t, "decrypted", string(decrypted), "provider credentials")
+
+	// The same secret derives the same key-encryption key.
+	provider, err = encryption.NewSecretKeyProvider("SuperSecret")
+	test.CheckErr(t, "new secret key provider", err)
+	decrypted, err = encryption.NewEncrypter("primary", provider).Decrypt(ctx, second)
+	test.CheckErr(t, "decrypt with derived key", err)
+	test.AssertStringsEqual(t, "decrypted with derived key", string(decrypted), "provider credentials")
+
+	provider, err = encryption.NewSecretKeyProvider("OtherSecret")
+	test.CheckErr(t, "new secret key provider", err)
+	_, err = encryption.NewEncrypter("primary", provider).Decrypt(ctx, first)
+	test.CheckErrExists(t, "decrypt with other secret", err)
+
+	tampered := []byte(string(first[:len(first)-6]) + "AAAA==")
+	_, err = enc.Decrypt(ctx, tampered)
+	test.CheckErrExists(t, "decrypt tampered", err)
+}
+
+func TestEncrypterLegacyCiphertext(t *testing.T) {
+	dir, err := ioutil.TempDir("", "encryption")
+	test.CheckErr(t, "create temp dir", err)
+	defer os.RemoveAll(dir)
+
+	legacy := legacyEncrypt(t, "SuperSecret", []byte("terraform state"))
+	ctx := context.Background()
+
+	provider, err := encryption.NewKeyFileProvider(newKeyFile(t, dir, "keyfile", strings.Repeat("k", 32)))
+	test.CheckErr(t, "new keyfile provider", err)
+
+	_, err = encryption.NewEncrypter("primary", provider).Decrypt(ctx, legacy)
+	test.CheckErrExists(t, "decrypt legacy without secret", err)
+
+	decrypted, err := encryption.NewEncrypter("primary", provider).WithLegacySecret("SuperSecret").Decrypt(ctx, legacy)
+	test.CheckErr(t, "decrypt legacy", err)
+	test.AssertStringsEqual(t, "decrypted legacy", string(decrypted), "terraform state")
+}
+
+func TestKeyFileProvider(t *testing.T) {
+	dir, err := ioutil.TempDir("", "encryption")
+	test.CheckErr(t, "create temp dir", err)
+	defer os.RemoveAll(dir)
+
+	_, err = encryption.NewKeyFileProvider(newKeyFile(t, dir, "short", "too short"))
+	test.CheckErrExists(t, "short keyfile", err)
+
+	_, err = encryption.NewKeyFileProvider(filepath.Join(dir, "missing"))
+	test.CheckErrExists(t, "missing keyfile", err)
+
+	material := strings.Repeat("0123456789abcdef", 3)
+	provider, err := encryption.NewKeyFileProvider(newKeyFile(t, dir, "keyfile", material))
+	test.CheckErr(t, "new keyfile provider", err)
+
+	encrypted, err := encryption.NewEncrypter("primary", provider).Encrypt(context.Background(), []byte("ssh key"))
+	test.CheckErr(t, "encrypt", err)
+
+	// Surrounding whitespace does not change the derived key.
+	provider, err = encryption.NewKeyFileProvider(newKeyFile(t, dir, "keyfile-newline", material+"\n"))
+	test.CheckErr(t, "new keyfile provider", err)
+
+	decrypted, err := encryption.NewEncrypter("primary", provider).Decrypt(context.Background(), encrypted)
+	test.CheckErr(t, "decrypt", err)
+	test.AssertStringsEqual(t, "decrypted", string(decrypted), "ssh key")
+}
+
+func TestConfigValidate(t *testing.T) {
+	cfg := &encryption.Config{KeyConfig: encryption.KeyConfig{Secret: "SuperSecret"}}
+	test.CheckErr(t, "local config", cfg.Validate())
+	test.AssertStringsEqual(t, "default provider", cfg.Provider, encryption.ProviderLocal)
+	test.AssertStringsEqual(t, "default key id", cfg.ID, encryption.DefaultKeyID)
+
+	cfg = &encryption.Config{}
+	test.CheckErrExists(t, "missing secret", cfg.Validate())
+
+	cfg = &encryption.Config{KeyConfig: encryption.KeyConfig{
+		Provider: encryption.ProviderVault,
+		Vault:    &encryption.VaultConfig{Address: "http://vault"},
+	}}
+	test.CheckErrExists(t, "missing vault key", cfg.Validate())
+
+	cfg = &encryption.Config{KeyConfig: encryption.KeyConfig{
+		Provider: encryption.ProviderVault,
+		Vault:    &encryption.VaultConfig{Address: "http://vault", Key: "blockpropeller", Token: "token"},
+	}}
+	test.CheckErr(t, "vault config", cfg.Validate())
+	test.AssertStringsEqual(t, "default mount", cfg.Vault.Mount, "transit")
+
+	cfg = &encryption.Config{KeyConfig: encryption.KeyConfig{Provider: "kms", Secret: "SuperSecret"}}
+	test.CheckErrExists(t, "unsupported provider", cfg.Validate())
+
+	cfg = &encryption.Config{KeyConfig: encryption.KeyConfig{ID: "2019:10", Secret: "SuperSecret"}}
+	test.CheckErrExists(t, "invalid key id", cfg.Validate())
+
+	cfg = &encryption.Config{
+		KeyConfig:    encryption.KeyConfig{ID: "2019-10", Secret: "NewSecret"},
+		PreviousKeys: []*encryption.KeyConfig{{Secret: "SuperSecret"}},
+	}
+	test.CheckErrExists(t, "missing previous key id", cfg.Validate())
+
+	cfg.PreviousKeys[0].ID = "2019-10"
+	test.CheckErrExists(t, "duplicate key id", cfg.Validate())
+
+	cfg.PreviousKeys[0].ID = encryption.DefaultKeyID
+	test.CheckErr(t, "previous keys config", cfg.Validate())
+	test.AssertStringsEqual(t, "previous key provider", cfg.PreviousKeys[0].Provider, encryption.ProviderLocal)
+}
+
+func TestEncrypterKeyRotation(t *testing.T) {
+	dir, err := ioutil.TempDir("", "encryption")
+	test.CheckErr(t, "create temp dir", err)
+	defer os.RemoveAll(dir)
+
+	ctx := context.Background()
+	legacy := legacyEncrypt(t, "SuperSecret", []byte("legacy"))
+
+	oldCfg := &encryption.Config{KeyConfig: encryption.KeyConfig{Secret: "SuperSecret"}}
+	test.CheckErr(t, "validate old config", oldCfg.Validate())
+
+	oldEnc, err := encryption.NewEncrypterFromConfig(oldCfg)
+	test.CheckErr(t, "new old encrypter", err)
+
+	old, err := oldEnc.Encrypt(ctx, []byte("old"))
+	test.CheckErr(t, "encrypt with old key", err)
+	test.AssertStringsEqual(t, "old key id", oldEnc.KeyID(old), encryption.DefaultKeyID)
+
+	oldProvider, err := encryption.NewSecretKeyProvider("SuperSecret")
+	test.CheckErr(t, "new old key provider", err)
+
+	newCfg := &encryption.Config{
+		KeyConfig: encryption.KeyConfig{
+			ID:      "2019-10",
+			KeyFile: newKeyFile(t, dir, "keyfile", strings.Repeat("0123456789abcdef", 3)),
+		},
+		PreviousKeys: []*encryption.KeyConfig{{ID: encryption.DefaultKeyID, Secret: "SuperSecret"}},
+	}
+	test.CheckErr(t, "validate new config", newCfg.Validate())
+
+	newEnc, err := encryption.NewEncrypterFromConfig(newCfg)
+	test.CheckErr(t, "new encrypter", err)
+	test.AssertStringsEqual(t, "active key id", newEnc.ActiveKeyID(), "2019-10")
+
+	current, err := newEnc.Encrypt(ctx, []byte("new"))
+	test.CheckErr(t, "encrypt with new key", err)
+	test.AssertStringsEqual(t, "new key id", newEnc.KeyID(current), "2019-10")
+
+	test.AssertBoolEqual(t, "new data is current", newEnc.IsCurrent(current), true)
+	test.AssertBoolEqual(t, "old data is not current", newEnc.IsCurrent(old), false)
+	test.AssertBoolEqual(t, "legacy data is not current", newEnc.IsCurrent(legacy), false)
+
+	for name, data := range map[string][]byte{"old": old, "new": current, "legacy": legacy} {
+		decrypted, err := newEnc.Decrypt(ctx, data)
+		test.CheckErr(t, "decrypt "+name, err)
+		test.AssertStringsEqual(t, "decrypted "+name, string(decrypted), name)
+	}
+
+	_, err = oldEnc.Decrypt(ctx, current)
+	test.AssertBoolEqual(t, "unknown key", errors.Cause(err) == encryption.ErrUnknownKey, true)
+
+	// The key ID in the header cannot be swapped for another key with the same key material.
+	swapped := []byte(strings.Replace(string(old), "v1:default:", "v1:other:", 1))
+	_, err = encryption.NewEncrypter("other", oldProvider).Decrypt(ctx, swapped)
+	test.CheckErrExists(t, "decrypt swapped key id", err)
+}
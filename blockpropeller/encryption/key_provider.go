@@ -0,0 +1,174 @@
+package encryption
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"io"
+	"io/ioutil"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/hkdf"
+	"golang.org/x/crypto/scrypt"
+)
+
+// keySize is the size of the AES-256 data and key-encryption keys.
+const keySize = 32
+
+// minKeyFileSize is the minimum amount of key material a keyfile must hold.
+const minKeyFileSize = 32
+
+var (
+	// kdfSalt separates the keys derived by BlockPropeller from any other use of the same key material.
+	kdfSalt = []byte("blockpropeller.dev/encryption")
+	// kdfInfo binds the derived keys to their purpose.
+	kdfInfo = []byte("key-encryption key")
+)
+
+// KeyProvider wraps the per-record data keys with a key-encryption key, which it never exposes.
+type KeyProvider interface {
+	// WrapKey encrypts a data key.
+	WrapKey(ctx context.Context, dataKey []byte) ([]byte, error)
+	// UnwrapKey decrypts a data key previously encrypted with WrapKey.
+	UnwrapKey(ctx context.Context, wrapped []byte) ([]byte, error)
+}
+
+// NewKeyProvider returns the KeyProvider set up by the KeyConfig.
+func NewKeyProvider(cfg *KeyConfig) (KeyProvider, error) {
+	switch cfg.Provider {
+	case ProviderVault:
+		return NewVaultKeyProvider(cfg.Vault), nil
+	case ProviderLocal, "":
+		if cfg.KeyFile != "" {
+			return NewKeyFileProvider(cfg.KeyFile)
+		}
+
+		return NewSecretKeyProvider(cfg.Secret)
+	default:
+		return nil, errors.Errorf("unsupported encryption provider: %s", cfg.Provider)
+	}
+}
+
+// LocalKeyProvider wraps data keys with a key-encryption key held in memory.
+type LocalKeyProvider struct {
+	kek []byte
+}
+
+// NewLocalKeyProvider returns a new LocalKeyProvider wrapping data keys with the provided AES-256 key.
+func NewLocalKeyProvider(kek []byte) (*LocalKeyProvider, error) {
+	if len(kek) != keySize {
+		return nil, errors.Errorf("invalid key-encryption key size: %d", len(kek))
+	}
+
+	return &LocalKeyProvider{kek: kek}, nil
+}
+
+// NewKeyFileProvider returns a new LocalKeyProvider with a key-encryption key
+// derived from the contents of a keyfile using HKDF-SHA256.
+//
+// The keyfile should hold at least 32 bytes of random data, such as the output of
+// `openssl rand -base64 48`. Surrounding whitespace is ignored.
+func NewKeyFileProvider(path string) (*LocalKeyProvider, error) {
+	material, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "read keyfile")
+	}
+
+	material = bytes.TrimSpace(material)
+	if len(material) < minKeyFileSize {
+		return nil, errors.Errorf("keyfile must hold at least %d bytes of key material", minKeyFileSize)
+	}
+
+	kek := make([]byte, keySize)
+	_, err = io.ReadFull(hkdf.New(sha256.New, material, kdfSalt, kdfInfo), kek)
+	if err != nil {
+		return nil, errors.Wrap(err, "derive key-encryption key")
+	}
+
+	return NewLocalKeyProvider(kek)
+}
+
+// NewSecretKeyProvider returns a new LocalKeyProvider with a key-encryption key
+// derived from a secret using scrypt, as the secret may be a low entropy passphrase.
+func NewSecretKeyProvider(secret string) (*LocalKeyProvider, error) {
+	if secret == "" {
+		return nil, errors.New("missing encryption secret key")
+	}
+
+	kek, err := scrypt.Key([]byte(secret), kdfSalt, 1<<15, 8, 1, keySize)
+	if err != nil {
+		return nil, errors.Wrap(err, "derive key-encryption key")
+	}
+
+	return NewLocalKeyProvider(kek)
+}
+
+// WrapKey satisfies the KeyProvider interface.
+func (p *LocalKeyProvider) WrapKey(ctx context.Context, dataKey []byte) ([]byte, error) {
+	return seal(p.kek, dataKey, nil)
+}
+
+// UnwrapKey satisfies the KeyProvider interface.
+func (p *LocalKeyProvider) UnwrapKey(ctx context.Context, wrapped []byte) ([]byte, error) {
+	dataKey, err := open(p.kek, wrapped, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "unwrap data key")
+	}
+
+	return dataKey, nil
+}
+
+// seal encrypts the data with AES-GCM, prepending the random nonce to the ciphertext.
+// The additional data is authenticated, but not encrypted.
+func seal(key, data, additionalData []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, errors.Wrap(err, "read nonce")
+	}
+
+	return gcm.Seal(nonce, nonce, data, additionalData), nil
+}
+
+// open decrypts the data encrypted with seal.
+func open(key, data, additionalData []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonceSize := gcm.NonceSize()
+	if len(data) < nonceSize {
+		return nil, errors.New("ciphertext too short")
+	}
+
+	nonce, ciphertext := data[:nonceSize], data[nonceSize:]
+
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, additionalData)
+	if err != nil {
+		return nil, errors.Wrap(err, "get plaintext")
+	}
+
+	return plaintext, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "new cypher")
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, errors.Wrap(err, "new gcm cypher")
+	}
+
+	return gcm, nil
+}
@@ -0,0 +1,40 @@
+package encryption
+
+import "context"
+
+// DefaultRotationBatchSize is the number of records re-encrypted in a single transaction by default.
+const DefaultRotationBatchSize = 100
+
+// RotationProgress counts the records of a table checked and re-encrypted so far.
+type RotationProgress struct {
+	Table string
+	// LastID is the ID of the last record checked, in the order the records are checked in.
+	LastID string
+
+	Checked int
+	Rotated int
+}
+
+// Rotator re-encrypts the stored data which is not encrypted with the active key.
+//
+// Every batch of records is re-encrypted in its own transaction, and records already encrypted
+// with the active key are skipped, so an interrupted rotation can be resumed by running it again.
+type Rotator interface {
+	Rotate(ctx context.Context, batchSize int, progress func(RotationProgress)) error
+}
+
+// InMemoryRotator is an in-memory implementation of a Rotator.
+//
+// In-memory repositories hold their records unencrypted, so there is nothing to re-encrypt.
+type InMemoryRotator struct {
+}
+
+// NewInMemoryRotator returns a new InMemoryRotator instance.
+func NewInMemoryRotator() *InMemoryRotator {
+	return &InMemoryRotator{}
+}
+
+// Rotate satisfies the Rotator interface.
+func (*InMemoryRotator) Rotate(ctx context.Context, batchSize int, progress func(RotationProgress)) error {
+	return nil
+}
@@ -0,0 +1,124 @@
+package encryption
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// vaultTimeout limits the time spent on a single request to Vault.
+const vaultTimeout = 10 * time.Second
+
+// VaultKeyProvider wraps data keys with a HashiCorp Vault transit key,
+// so the key-encryption key never leaves Vault.
+type VaultKeyProvider struct {
+	cfg    *VaultConfig
+	client *http.Client
+}
+
+// NewVaultKeyProvider returns a new VaultKeyProvider instance.
+func NewVaultKeyProvider(cfg *VaultConfig) *VaultKeyProvider {
+	return &VaultKeyProvider{
+		cfg:    cfg,
+		client: &http.Client{Timeout: vaultTimeout},
+	}
+}
+
+type vaultEncryptRequest struct {
+	Plaintext string `json:"plaintext"`
+}
+
+type vaultDecryptRequest struct {
+	Ciphertext string `json:"ciphertext"`
+}
+
+type vaultResponse struct {
+	Data struct {
+		Ciphertext string `json:"ciphertext"`
+		Plaintext  string `json:"plaintext"`
+	} `json:"data"`
+	Errors []string `json:"errors"`
+}
+
+// WrapKey satisfies the KeyProvider interface.
+func (p *VaultKeyProvider) WrapKey(ctx context.Context, dataKey []byte) ([]byte, error) {
+	req := vaultEncryptRequest{Plaintext: base64.StdEncoding.EncodeToString(dataKey)}
+
+	resp, err := p.do(ctx, "encrypt", req)
+	if err != nil {
+		return nil, errors.Wrap(err, "vault encrypt")
+	}
+	if resp.Data.Ciphertext == "" {
+		return nil, errors.New("vault returned no ciphertext")
+	}
+
+	return []byte(resp.Data.Ciphertext), nil
+}
+
+// UnwrapKey satisfies the KeyProvider interface.
+func (p *VaultKeyProvider) UnwrapKey(ctx context.Context, wrapped []byte) ([]byte, error) {
+	req := vaultDecryptRequest{Ciphertext: string(wrapped)}
+
+	resp, err := p.do(ctx, "decrypt", req)
+	if err != nil {
+		return nil, errors.Wrap(err, "vault decrypt")
+	}
+
+	dataKey, err := base64.StdEncoding.DecodeString(resp.Data.Plaintext)
+	if err != nil {
+		return nil, errors.Wrap(err, "decode vault plaintext")
+	}
+
+	return dataKey, nil
+}
+
+// do sends a request to a transit endpoint of the configured key.
+func (p *VaultKeyProvider) do(ctx context.Context, operation string, body interface{}) (*vaultResponse, error) {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal request")
+	}
+
+	url := fmt.Sprintf("%s/v1/%s/%s/%s",
+		strings.TrimRight(p.cfg.Address, "/"), strings.Trim(p.cfg.Mount, "/"), operation, p.cfg.Key)
+
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return nil, errors.Wrap(err, "new request")
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Vault-Token", p.cfg.Token)
+	if p.cfg.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", p.cfg.Namespace)
+	}
+
+	httpResp, err := p.client.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "send request")
+	}
+	defer httpResp.Body.Close()
+
+	var resp vaultResponse
+	err = json.NewDecoder(httpResp.Body).Decode(&resp)
+	if err != nil && httpResp.StatusCode == http.StatusOK {
+		return nil, errors.Wrap(err, "decode response")
+	}
+
+	if httpResp.StatusCode != http.StatusOK {
+		if len(resp.Errors) > 0 {
+			return nil, errors.Errorf("vault responded with %d: %s", httpResp.StatusCode, strings.Join(resp.Errors, "; "))
+		}
+
+		return nil, errors.Errorf("vault responded with %d", httpResp.StatusCode)
+	}
+
+	return &resp, nil
+}
@@ -0,0 +1,167 @@
+package encryption_test
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"blockpropeller.dev/blockpropeller/encryption"
+	"blockpropeller.dev/lib/test"
+)
+
+// vaultStub emulates the encrypt and decrypt endpoints of a Vault transit secrets engine,
+// keeping the plaintexts it has seen instead of encrypting them.
+type vaultStub struct {
+	token string
+
+	mu          sync.Mutex
+	plaintexts  map[string]string
+	namespaces  []string
+	decryptions int
+}
+
+func newVaultStub(token string) (*vaultStub, *httptest.Server) {
+	stub := &vaultStub{token: token, plaintexts: make(map[string]string)}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/transit/encrypt/blockpropeller", stub.encrypt)
+	mux.HandleFunc("/v1/transit/decrypt/blockpropeller", stub.decrypt)
+
+	return stub, httptest.NewServer(stub.authenticate(mux))
+}
+
+func (s *vaultStub) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			s.fail(w, http.StatusMethodNotAllowed, "unsupported operation")
+			return
+		}
+		if r.Header.Get("X-Vault-Token") != s.token {
+			s.fail(w, http.StatusForbidden, "permission denied")
+			return
+		}
+
+		s.mu.Lock()
+		s.namespaces = append(s.namespaces, r.Header.Get("X-Vault-Namespace"))
+		s.mu.Unlock()
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *vaultStub) encrypt(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Plaintext string `json:"plaintext"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.fail(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+	ciphertext := "vault:v1:" + base64.StdEncoding.EncodeToString(id)
+
+	s.mu.Lock()
+	s.plaintexts[ciphertext] = req.Plaintext
+	s.mu.Unlock()
+
+	s.respond(w, map[string]string{"ciphertext": ciphertext})
+}
+
+func (s *vaultStub) decrypt(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Ciphertext string `json:"ciphertext"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.fail(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	plaintext, ok := s.plaintexts[req.Ciphertext]
+	s.decryptions++
+	s.mu.Unlock()
+
+	if !ok {
+		s.fail(w, http.StatusBadRequest, "cipher: message authentication failed")
+		return
+	}
+
+	s.respond(w, map[string]string{"plaintext": plaintext})
+}
+
+func (s *vaultStub) respond(w http.ResponseWriter, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": data})
+}
+
+func (s *vaultStub) fail(w http.ResponseWriter, status int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"errors": []string{msg}})
+}
+
+func TestVaultKeyProvider(t *testing.T) {
+	stub, srv := newVaultStub("root-token")
+	defer srv.Close()
+
+	cfg := &encryption.Config{KeyConfig: encryption.KeyConfig{
+		Provider: encryption.ProviderVault,
+		Vault: &encryption.VaultConfig{
+			Address:   srv.URL + "/",
+			Token:     "root-token",
+			Namespace: "ops",
+			Key:       "blockpropeller",
+		},
+	}}
+	test.CheckErr(t, "validate config", cfg.Validate())
+
+	enc, err := encryption.NewEncrypterFromConfig(cfg)
+	test.CheckErr(t, "new encrypter", err)
+	ctx := context.Background()
+
+	encrypted, err := enc.Encrypt(ctx, []byte("provider credentials"))
+	test.CheckErr(t, "encrypt", err)
+	test.AssertBoolEqual(t, "plaintext not in ciphertext", strings.Contains(string(encrypted), "provider"), false)
+
+	decrypted, err := enc.Decrypt(ctx, encrypted)
+	test.CheckErr(t, "decrypt", err)
+	test.AssertStringsEqual(t, "decrypted", string(decrypted), "provider credentials")
+	test.AssertIntsEqual(t, "vault decryptions", stub.decryptions, 1)
+	test.AssertStringsEqual(t, "vault namespace", stub.namespaces[0], "ops")
+
+	// Data keys wrapped by another Vault cannot be unwrapped.
+	_, otherSrv := newVaultStub("root-token")
+	defer otherSrv.Close()
+
+	cfg.Vault.Address = otherSrv.URL
+	provider, err := encryption.NewKeyProvider(&cfg.KeyConfig)
+	test.CheckErr(t, "new other key provider", err)
+
+	_, err = encryption.NewEncrypter(cfg.ID, provider).Decrypt(ctx, encrypted)
+	test.CheckErrExists(t, "decrypt with other vault", err)
+	test.AssertBoolEqual(t, "vault error reported", strings.Contains(err.Error(), "message authentication failed"), true)
+}
+
+func TestVaultKeyProviderPermissionDenied(t *testing.T) {
+	_, srv := newVaultStub("root-token")
+	defer srv.Close()
+
+	provider := encryption.NewVaultKeyProvider(&encryption.VaultConfig{
+		Address: srv.URL,
+		Token:   "wrong-token",
+		Mount:   "transit",
+		Key:     "blockpropeller",
+	})
+
+	_, err := encryption.NewEncrypter("vault", provider).Encrypt(context.Background(), []byte("ssh key"))
+	test.CheckErrExists(t, "encrypt with wrong token", err)
+	test.AssertBoolEqual(t, "vault status reported", strings.Contains(err.Error(), "403"), true)
+}
@@ -0,0 +1,208 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"blockpropeller.dev/blockpropeller/account"
+	"blockpropeller.dev/blockpropeller/audit"
+	"blockpropeller.dev/blockpropeller/httpserver/request"
+	"blockpropeller.dev/lib/log"
+	"blockpropeller.dev/lib/server"
+	"github.com/go-playground/validator"
+	"github.com/labstack/echo"
+	"github.com/pkg/errors"
+)
+
+// maxUserAgentLength caps the user agents stored in the audit log.
+const maxUserAgentLength = 255
+
+// AuditMiddleware records the actions taken through the API in the audit log.
+type AuditMiddleware struct {
+	auditSvc *audit.Service
+	orgSvc   *account.OrganizationService
+	accRepo  account.Repository
+}
+
+// NewAuditMiddleware returns a new AuditMiddleware.
+func NewAuditMiddleware(
+	auditSvc *audit.Service,
+	orgSvc *account.OrganizationService,
+	accRepo account.Repository,
+) *AuditMiddleware {
+	return &AuditMiddleware{auditSvc: auditSvc, orgSvc: orgSvc, accRepo: accRepo}
+}
+
+// Record returns a middleware recording the action once the request is handled.
+//
+// The target resource is identified by the provided path parameter, unless the handler
+// provides one through request.WithAuditTarget. Requests rejected with 401 Unauthorized
+// or 403 Forbidden are recorded as denied, so the middleware must precede the authorization checks.
+func (m *AuditMiddleware) Record(action audit.Action, targetParam string) echo.MiddlewareFunc {
+	return m.record(action, func(c echo.Context, acc *account.Account) string {
+		if targetParam == "" {
+			return ""
+		}
+
+		return c.Param(targetParam)
+	})
+}
+
+// RecordAccount returns a middleware recording an action on the authenticated Account itself.
+func (m *AuditMiddleware) RecordAccount(action audit.Action) echo.MiddlewareFunc {
+	return m.record(action, func(c echo.Context, acc *account.Account) string {
+		return acc.ID.String()
+	})
+}
+
+// RecordAuthentication returns a middleware recording an attempt to authenticate or to recover an Account,
+// made by a client which is not authenticated.
+//
+// The target is the email the attempt was made for, which the handler provides through request.WithAuditTarget.
+// Attempts are scoped to the personal Organization of the Account with the email, if there is one,
+// and only the successful ones are recorded as taken by that Account. Successful requests without a target,
+// such as refreshing a Session, are not recorded, while failed ones are recorded whether or not they have one.
+func (m *AuditMiddleware) RecordAuthentication(action audit.Action) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			err := next(c)
+
+			email := request.AuditTargetFromContext(c)
+			if err == nil && email == "" {
+				return nil
+			}
+
+			acc, findErr := m.findAccount(email)
+			if findErr != nil {
+				log.ErrorErr(findErr, "failed resolving audit account")
+			}
+
+			var actor *account.Account
+			if err == nil {
+				actor = acc
+			}
+
+			entry := newEntry(c, action, email, actor, err)
+			if acc != nil {
+				org, orgErr := m.orgSvc.Personal(context.Background(), acc)
+				if orgErr != nil {
+					log.ErrorErr(orgErr, "failed resolving audit organization")
+				} else {
+					entry.OrganizationID = org.ID
+				}
+			}
+
+			m.auditSvc.Record(context.Background(), entry)
+
+			return err
+		}
+	}
+}
+
+func (m *AuditMiddleware) record(action audit.Action, target func(c echo.Context, acc *account.Account) string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			err := next(c)
+
+			// Requests without an authenticated Account have no actor to record.
+			acc := request.AuthFromContext(c)
+			if acc == nil {
+				return err
+			}
+
+			targetID := request.AuditTargetFromContext(c)
+			if targetID == "" {
+				targetID = target(c, acc)
+			}
+
+			entry := newEntry(c, action, targetID, acc, err)
+
+			orgID, orgErr := m.resolveOrganization(c, acc)
+			if orgErr != nil {
+				log.ErrorErr(orgErr, "failed resolving audit organization")
+			}
+			entry.OrganizationID = orgID
+
+			m.auditSvc.Record(context.Background(), entry)
+
+			return err
+		}
+	}
+}
+
+// findAccount returns the Account with the provided email, or nil if there is none.
+func (m *AuditMiddleware) findAccount(email string) (*account.Account, error) {
+	if email == "" {
+		return nil, nil
+	}
+
+	acc, err := m.accRepo.FindByEmail(context.Background(), account.NewEmail(email))
+	if errors.Cause(err) == account.ErrAccountNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "find account by email")
+	}
+
+	return acc, nil
+}
+
+// newEntry returns an Entry of the request, finished with the error the request was handled with.
+func newEntry(c echo.Context, action audit.Action, targetID string, actor *account.Account, err error) *audit.Entry {
+	entry := audit.NewEntry(audit.SourceAPI, action, targetID, actor)
+	entry.IP = server.ClientIP(c)
+	entry.UserAgent = c.Request().UserAgent()
+	if len(entry.UserAgent) > maxUserAgentLength {
+		entry.UserAgent = entry.UserAgent[:maxUserAgentLength]
+	}
+	if key := request.APIKeyFromContext(c); key != nil {
+		entry.APIKeyID = key.ID
+	}
+
+	if err != nil {
+		if isDenied(err) {
+			entry.Deny(publicError(err))
+		} else {
+			entry.Fail(publicError(err))
+		}
+	}
+
+	return entry
+}
+
+// resolveOrganization returns the Organization of the accessed resources,
+// falling back to the personal Organization of the Account for actions on the Account itself.
+func (m *AuditMiddleware) resolveOrganization(c echo.Context, acc *account.Account) (account.OrganizationID, error) {
+	if orgID := request.OrganizationFromContext(c); orgID != account.NilOrganizationID {
+		return orgID, nil
+	}
+
+	org, err := m.orgSvc.Personal(context.Background(), acc)
+	if err != nil {
+		return account.NilOrganizationID, errors.Wrap(err, "find personal organization")
+	}
+
+	return org.ID, nil
+}
+
+func isDenied(err error) bool {
+	he, ok := errors.Cause(err).(*echo.HTTPError)
+	if !ok {
+		return false
+	}
+
+	return he.Code == http.StatusUnauthorized || he.Code == http.StatusForbidden
+}
+
+// publicError returns the error as it was presented to the client, leaving out internal details.
+func publicError(err error) error {
+	switch cause := errors.Cause(err).(type) {
+	case *echo.HTTPError:
+		return errors.New(fmt.Sprint(cause.Message))
+	case validator.ValidationErrors:
+		return errors.New(http.StatusText(http.StatusBadRequest))
+	default:
+		return errors.New(http.StatusText(http.StatusInternalServerError))
+	}
+}
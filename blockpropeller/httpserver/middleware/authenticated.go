@@ -1,6 +1,9 @@
 package middleware
 
 import (
+	"context"
+	"net/http"
+
 	"blockpropeller.dev/blockpropeller/account"
 	"blockpropeller.dev/blockpropeller/httpserver/request"
 	"github.com/labstack/echo"
@@ -8,13 +11,16 @@ import (
 )
 
 // AuthenticationMiddleware ensures that a request is properly authenticated.
+//
+// Requests are authenticated either with a session token, or with an API key.
 type AuthenticationMiddleware struct {
-	accSvc *account.Service
+	accSvc    *account.Service
+	apiKeySvc *account.APIKeyService
 }
 
 // NewAuthenticationMiddleware returns a new AuthenticationMiddleware.
-func NewAuthenticationMiddleware(accSvc *account.Service) *AuthenticationMiddleware {
-	return &AuthenticationMiddleware{accSvc: accSvc}
+func NewAuthenticationMiddleware(accSvc *account.Service, apiKeySvc *account.APIKeyService) *AuthenticationMiddleware {
+	return &AuthenticationMiddleware{accSvc: accSvc, apiKeySvc: apiKeySvc}
 }
 
 // Middleware satisfies the echo.MiddlewareFunc interface.
@@ -30,14 +36,92 @@ func (s *AuthenticationMiddleware) Middleware(next echo.HandlerFunc) echo.Handle
 
 		token := account.NewToken(authHeader[prefixLen:])
 
-		acc, err := s.accSvc.Authenticate(token)
+		if account.IsAPIKeyToken(token) {
+			acc, key, err := s.apiKeySvc.Authenticate(context.Background(), account.APIKeyToken(token))
+			if err != nil {
+				return echo.ErrUnauthorized.
+					SetInternal(errors.Wrap(err, "authenticate api key"))
+			}
+
+			request.WithAuth(c, acc)
+			request.WithAPIKey(c, key)
+
+			return next(c)
+		}
+
+		acc, session, err := s.accSvc.Authenticate(token)
 		if err != nil {
 			return echo.ErrUnauthorized.
 				SetInternal(errors.Wrap(err, "authenticate token"))
 		}
 
 		request.WithAuth(c, acc)
+		request.WithSession(c, session)
+
+		return next(c)
+	}
+}
+
+// RequireScope returns a middleware that only lets through requests authenticated with an API key
+// if the key was granted the provided Scope.
+//
+// Requests authenticated with a session token are not restricted by scopes.
+func (s *AuthenticationMiddleware) RequireScope(scope account.Scope) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			key := request.APIKeyFromContext(c)
+			if key != nil && !key.Scopes.Has(scope) {
+				return echo.ErrForbidden.
+					SetInternal(errors.Errorf("api key %s missing scope %s", key.ID, scope))
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// RequireSession is a middleware that only lets through requests authenticated with a session token,
+// keeping API keys away from managing accounts, organizations and other API keys.
+func (s *AuthenticationMiddleware) RequireSession(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if session := request.SessionFromContext(c); session == nil {
+			return echo.ErrForbidden.
+				SetInternal(errors.New("session only action used without a session"))
+		}
 
 		return next(c)
 	}
 }
+
+// RequireFreshTwoFactor is a middleware that guards sensitive operations of Accounts with two-factor
+// authentication, letting through only the sessions that verified the second factor recently.
+//
+// Sensitive operations are session only. API keys are refused, since they
+// may have been created before two-factor authentication was enabled.
+func (s *AuthenticationMiddleware) RequireFreshTwoFactor(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		acc := request.AuthFromContext(c)
+		session := request.SessionFromContext(c)
+		if session == nil {
+			return echo.ErrForbidden.
+				SetInternal(errors.New("session only action used without a session"))
+		}
+		if acc == nil || !acc.IsTwoFactorEnabled() || session.HasFreshTwoFactor() {
+			return next(c)
+		}
+
+		return echo.NewHTTPError(http.StatusForbidden, "fresh two-factor verification required").
+			SetInternal(errors.Errorf("session %s did not verify second factor recently", session.ID))
+	}
+}
+
+// AccountThrottleKey identifies the authenticated Account for throttling the failed attempts
+// of sensitive operations, such as verifying the second factor.
+func AccountThrottleKey(c echo.Context) string {
+	acc := request.AuthFromContext(c)
+	if acc == nil {
+		return ""
+	}
+
+	return acc.ID.String()
+}
@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"context"
+
+	"blockpropeller.dev/blockpropeller/account"
+	"blockpropeller.dev/blockpropeller/httpserver/request"
+	"github.com/labstack/echo"
+	"github.com/pkg/errors"
+)
+
+// OrganizationHeader selects the Organization a request acts within.
+const OrganizationHeader = "X-Organization-ID"
+
+// AuthorizationMiddleware ensures that the authenticated Account is allowed
+// to take an action within the Organization a request acts within.
+type AuthorizationMiddleware struct {
+	orgSvc *account.OrganizationService
+}
+
+// NewAuthorizationMiddleware returns a new AuthorizationMiddleware.
+func NewAuthorizationMiddleware(orgSvc *account.OrganizationService) *AuthorizationMiddleware {
+	return &AuthorizationMiddleware{orgSvc: orgSvc}
+}
+
+// Require returns a middleware that only lets through members holding the provided Permission.
+//
+// The Organization is resolved from, in order of precedence:
+//   - the resource loaded by a preceding middleware,
+//   - the `organization_id` path parameter,
+//   - the X-Organization-ID header or `organization_id` query parameter,
+//   - the personal Organization of the authenticated Account.
+func (m *AuthorizationMiddleware) Require(perm account.Permission) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			acc := request.AuthFromContext(c)
+			if acc == nil {
+				return echo.ErrForbidden.SetInternal(errors.New("missing authenticated account"))
+			}
+
+			orgID, err := m.resolveOrganization(c, acc)
+			if err != nil {
+				return echo.ErrInternalServerError.SetInternal(err)
+			}
+
+			membership, err := m.orgSvc.Authorize(context.Background(), acc.ID, orgID, perm)
+			if err != nil {
+				return echo.ErrForbidden.SetInternal(err)
+			}
+
+			request.WithOrganization(c, orgID)
+			request.WithMembership(c, membership)
+
+			return next(c)
+		}
+	}
+}
+
+func (m *AuthorizationMiddleware) resolveOrganization(c echo.Context, acc *account.Account) (account.OrganizationID, error) {
+	if orgID := request.OrganizationFromContext(c); orgID != account.NilOrganizationID {
+		return orgID, nil
+	}
+	if orgID := c.Param("organization_id"); orgID != "" {
+		return account.OrganizationID(orgID), nil
+	}
+	if orgID := c.Request().Header.Get(OrganizationHeader); orgID != "" {
+		return account.OrganizationID(orgID), nil
+	}
+	if orgID := c.QueryParam("organization_id"); orgID != "" {
+		return account.OrganizationID(orgID), nil
+	}
+
+	org, err := m.orgSvc.Personal(context.Background(), acc)
+	if err != nil {
+		return account.NilOrganizationID, errors.Wrap(err, "find personal organization")
+	}
+
+	return org.ID, nil
+}
@@ -81,3 +81,128 @@ func ServerFromContext(c echo.Context) *infrastructure.Server {
 
 	return srv.(*infrastructure.Server)
 }
+
+// WithReservedIP adds a ReservedIP resource to echo.Context.
+func WithReservedIP(c echo.Context, ip *infrastructure.ReservedIP) {
+	c.Set("_reserved_ip", ip)
+}
+
+// ReservedIPFromContext returns a ReservedIP from echo.Context.
+func ReservedIPFromContext(c echo.Context) *infrastructure.ReservedIP {
+	ip := c.Get("_reserved_ip")
+	if ip == nil {
+		return nil
+	}
+
+	return ip.(*infrastructure.ReservedIP)
+}
+
+// WithVolumeSnapshot adds a VolumeSnapshot resource to echo.Context.
+func WithVolumeSnapshot(c echo.Context, snap *infrastructure.VolumeSnapshot) {
+	c.Set("_volume_snapshot", snap)
+}
+
+// VolumeSnapshotFromContext returns a VolumeSnapshot from echo.Context.
+func VolumeSnapshotFromContext(c echo.Context) *infrastructure.VolumeSnapshot {
+	snap := c.Get("_volume_snapshot")
+	if snap == nil {
+		return nil
+	}
+
+	return snap.(*infrastructure.VolumeSnapshot)
+}
+
+// WithProject adds a Project resource to echo.Context.
+func WithProject(c echo.Context, project *infrastructure.Project) {
+	c.Set("_project", project)
+}
+
+// ProjectFromContext returns a Project from echo.Context.
+func ProjectFromContext(c echo.Context) *infrastructure.Project {
+	project := c.Get("_project")
+	if project == nil {
+		return nil
+	}
+
+	return project.(*infrastructure.Project)
+}
+
+// WithOrganization adds the OrganizationID of the accessed resources to echo.Context.
+func WithOrganization(c echo.Context, orgID account.OrganizationID) {
+	c.Set("_organization", orgID)
+}
+
+// OrganizationFromContext returns the OrganizationID of the accessed resources from echo.Context.
+func OrganizationFromContext(c echo.Context) account.OrganizationID {
+	orgID := c.Get("_organization")
+	if orgID == nil {
+		return account.NilOrganizationID
+	}
+
+	return orgID.(account.OrganizationID)
+}
+
+// WithMembership adds the Membership of the authenticated Account to echo.Context.
+func WithMembership(c echo.Context, membership *account.Membership) {
+	c.Set("_membership", membership)
+}
+
+// MembershipFromContext returns the Membership of the authenticated Account from echo.Context.
+func MembershipFromContext(c echo.Context) *account.Membership {
+	membership := c.Get("_membership")
+	if membership == nil {
+		return nil
+	}
+
+	return membership.(*account.Membership)
+}
+
+// WithAPIKey adds the APIKey a request was authenticated with to echo.Context.
+func WithAPIKey(c echo.Context, key *account.APIKey) {
+	c.Set("_api_key", key)
+}
+
+// APIKeyFromContext returns the APIKey a request was authenticated with from echo.Context.
+//
+// Requests authenticated with a session token have no APIKey.
+func APIKeyFromContext(c echo.Context) *account.APIKey {
+	key := c.Get("_api_key")
+	if key == nil {
+		return nil
+	}
+
+	return key.(*account.APIKey)
+}
+
+// WithSession adds the Session a request was authenticated within to echo.Context.
+func WithSession(c echo.Context, session *account.Session) {
+	c.Set("_session", session)
+}
+
+// SessionFromContext returns the Session a request was authenticated within from echo.Context.
+//
+// Requests authenticated with an API key have no Session.
+func SessionFromContext(c echo.Context) *account.Session {
+	session := c.Get("_session")
+	if session == nil {
+		return nil
+	}
+
+	return session.(*account.Session)
+}
+
+// WithAuditTarget adds the ID of the resource targeted by an audited action to echo.Context,
+// for resources which are not identified by a path parameter, such as created ones.
+func WithAuditTarget(c echo.Context, targetID string) {
+	c.Set("_audit_target", targetID)
+}
+
+// AuditTargetFromContext returns the ID of the resource targeted by an audited action from echo.Context.
+func AuditTargetFromContext(c echo.Context) string {
+	targetID := c.Get("_audit_target")
+	if targetID == nil {
+		return ""
+	}
+
+	return targetID.(string)
+}
@@ -3,21 +3,34 @@ package httpserver
 import (
 	"net/http"
 
+	"blockpropeller.dev/blockpropeller/account"
+	"blockpropeller.dev/blockpropeller/audit"
 	"blockpropeller.dev/blockpropeller/httpserver/middleware"
 	"blockpropeller.dev/blockpropeller/httpserver/routes"
+	"blockpropeller.dev/lib/server"
 	"github.com/labstack/echo"
 )
 
 // Router registers all the routes with the HTTP server.
 type Router struct {
 	AuthenticatedMiddleware *middleware.AuthenticationMiddleware
+	AuthorizationMiddleware *middleware.AuthorizationMiddleware
+	AuditMiddleware         *middleware.AuditMiddleware
+	Throttle                *server.Throttle
 
 	AuthRoutes             *routes.Authentication
 	AccountRoutes          *routes.Account
+	APIKeyRoutes           *routes.APIKey
+	TwoFactorRoutes        *routes.TwoFactor
+	OrganizationRoutes     *routes.Organization
+	ProjectRoutes          *routes.Project
 	ProviderSettingsRoutes *routes.ProviderSettings
 	ProvisionRoutes        *routes.Provision
 	ServerRoutes           *routes.Server
+	ReservedIPRoutes       *routes.ReservedIP
+	VolumeSnapshotRoutes   *routes.VolumeSnapshot
 	DeploymentRoutes       *routes.Deployment
+	AuditRoutes            *routes.Audit
 }
 
 // RegisterRoutes satisfies the server.Router interface.
@@ -26,38 +39,262 @@ func (r *Router) RegisterRoutes(e *echo.Echo) error {
 		return c.String(http.StatusOK, http.StatusText(http.StatusOK))
 	})
 	e.POST("/register", r.AuthRoutes.Register)
-	e.POST("/login", r.AuthRoutes.Login)
+	e.POST("/login", r.AuthRoutes.Login,
+		r.Throttle.Middleware(r.Throttle.ByIP(), r.Throttle.ByAccount("email")),
+		r.AuditMiddleware.RecordAuthentication(audit.ActionAccountLogin))
+	e.POST("/refresh", r.AuthRoutes.Refresh,
+		r.AuditMiddleware.RecordAuthentication(audit.ActionAccountRefreshSession))
+	e.POST("/verify_email", r.AuthRoutes.VerifyEmail)
+	e.POST("/forgot_password", r.AuthRoutes.ForgotPassword,
+		r.AuditMiddleware.RecordAuthentication(audit.ActionAccountRequestPasswordReset))
+	e.POST("/reset_password", r.AuthRoutes.ResetPassword,
+		r.AuditMiddleware.RecordAuthentication(audit.ActionAccountResetPassword))
+	e.GET("/oidc/login", r.AuthRoutes.SSOLogin)
+	e.POST("/oidc/callback", r.AuthRoutes.SSOCallback,
+		r.AuditMiddleware.RecordAuthentication(audit.ActionAccountSSOLogin))
+	e.POST("/logout", r.AuthRoutes.Logout,
+		r.AuthenticatedMiddleware.Middleware,
+		r.AuthenticatedMiddleware.RequireSession)
+	e.POST("/logout/all", r.AuthRoutes.LogoutAll,
+		r.AuthenticatedMiddleware.Middleware,
+		r.AuthenticatedMiddleware.RequireSession)
 
 	protectedAPI := e.Group("/api/v1",
 		r.AuthenticatedMiddleware.Middleware)
 
 	protectedAPI.GET("/account/:account_id", r.AccountRoutes.Get,
 		r.AccountRoutes.LoadAccount)
+	protectedAPI.POST("/account/password", r.AccountRoutes.ChangePassword,
+		r.AuditMiddleware.RecordAccount(audit.ActionAccountChangePassword),
+		r.AuthenticatedMiddleware.RequireSession,
+		r.AuthenticatedMiddleware.RequireFreshTwoFactor)
+	protectedAPI.POST("/account/verification", r.AccountRoutes.SendVerification,
+		r.AuthenticatedMiddleware.RequireSession)
+
+	protectedAPI.GET("/account/2fa", r.TwoFactorRoutes.Status,
+		r.AuthenticatedMiddleware.RequireSession)
+	protectedAPI.POST("/account/2fa", r.TwoFactorRoutes.Enroll,
+		r.AuthenticatedMiddleware.RequireSession)
+	protectedAPI.POST("/account/2fa/enable", r.TwoFactorRoutes.Enable,
+		r.AuditMiddleware.RecordAccount(audit.ActionTwoFactorEnable),
+		r.AuthenticatedMiddleware.RequireSession)
+	protectedAPI.POST("/account/2fa/verify", r.TwoFactorRoutes.Verify,
+		r.AuthenticatedMiddleware.RequireSession,
+		r.Throttle.Middleware(r.Throttle.ByIP(), r.Throttle.ByAccountKey(middleware.AccountThrottleKey)))
+	protectedAPI.POST("/account/2fa/recovery_codes", r.TwoFactorRoutes.RegenerateRecoveryCodes,
+		r.AuditMiddleware.RecordAccount(audit.ActionTwoFactorRegenerateRecovery),
+		r.AuthenticatedMiddleware.RequireSession,
+		r.AuthenticatedMiddleware.RequireFreshTwoFactor)
+	protectedAPI.POST("/account/2fa/disable", r.TwoFactorRoutes.Disable,
+		r.AuditMiddleware.RecordAccount(audit.ActionTwoFactorDisable),
+		r.AuthenticatedMiddleware.RequireSession,
+		r.Throttle.Middleware(r.Throttle.ByIP(), r.Throttle.ByAccountKey(middleware.AccountThrottleKey)))
+
+	// API keys cannot manage API keys, so a leaked key cannot be used to issue others.
+	protectedAPI.GET("/account/keys", r.APIKeyRoutes.List,
+		r.AuthenticatedMiddleware.RequireSession)
+	protectedAPI.POST("/account/keys", r.APIKeyRoutes.Create,
+		r.AuditMiddleware.Record(audit.ActionAPIKeyCreate, ""),
+		r.AuthenticatedMiddleware.RequireSession,
+		r.AuthenticatedMiddleware.RequireFreshTwoFactor)
+	protectedAPI.DELETE("/account/keys/:key_id", r.APIKeyRoutes.Revoke,
+		r.AuditMiddleware.Record(audit.ActionAPIKeyRevoke, "key_id"),
+		r.AuthenticatedMiddleware.RequireSession)
+
+	protectedAPI.GET("/organization", r.OrganizationRoutes.List,
+		r.AuthenticatedMiddleware.RequireScope(account.ScopeOrganizationsRead))
+	protectedAPI.POST("/organization", r.OrganizationRoutes.Create,
+		r.AuthenticatedMiddleware.RequireSession)
+	protectedAPI.GET("/organization/:organization_id", r.OrganizationRoutes.Get,
+		r.AuthenticatedMiddleware.RequireScope(account.ScopeOrganizationsRead),
+		r.AuthorizationMiddleware.Require(account.PermissionView))
+	protectedAPI.PUT("/organization/:organization_id", r.OrganizationRoutes.Update,
+		r.AuditMiddleware.Record(audit.ActionOrganizationUpdate, "organization_id"),
+		r.AuthenticatedMiddleware.RequireSession,
+		r.AuthorizationMiddleware.Require(account.PermissionManageOrganization))
+	protectedAPI.GET("/organization/:organization_id/member", r.OrganizationRoutes.ListMembers,
+		r.AuthenticatedMiddleware.RequireScope(account.ScopeOrganizationsRead),
+		r.AuthorizationMiddleware.Require(account.PermissionView))
+	protectedAPI.PUT("/organization/:organization_id/member/:account_id", r.OrganizationRoutes.UpdateMember,
+		r.AuditMiddleware.Record(audit.ActionMemberUpdate, "account_id"),
+		r.AuthenticatedMiddleware.RequireSession,
+		r.AuthorizationMiddleware.Require(account.PermissionManageMembers))
+	// Members can always leave an organization, removing others is checked by the organization service.
+	protectedAPI.DELETE("/organization/:organization_id/member/:account_id", r.OrganizationRoutes.RemoveMember,
+		r.AuditMiddleware.Record(audit.ActionMemberRemove, "account_id"),
+		r.AuthenticatedMiddleware.RequireSession,
+		r.AuthorizationMiddleware.Require(account.PermissionView))
+	protectedAPI.GET("/organization/:organization_id/invitation", r.OrganizationRoutes.ListInvitations,
+		r.AuthenticatedMiddleware.RequireScope(account.ScopeOrganizationsRead),
+		r.AuthorizationMiddleware.Require(account.PermissionManageMembers))
+	protectedAPI.POST("/organization/:organization_id/invitation", r.OrganizationRoutes.CreateInvitation,
+		r.AuditMiddleware.Record(audit.ActionInvitationCreate, ""),
+		r.AuthenticatedMiddleware.RequireSession,
+		r.AuthorizationMiddleware.Require(account.PermissionManageMembers))
+	protectedAPI.DELETE("/organization/:organization_id/invitation/:invitation_id", r.OrganizationRoutes.DeleteInvitation,
+		r.AuditMiddleware.Record(audit.ActionInvitationDelete, "invitation_id"),
+		r.AuthenticatedMiddleware.RequireSession,
+		r.AuthorizationMiddleware.Require(account.PermissionManageMembers))
+	protectedAPI.POST("/invitation/accept", r.OrganizationRoutes.AcceptInvitation,
+		r.AuthenticatedMiddleware.RequireSession)
+
+	protectedAPI.GET("/project", r.ProjectRoutes.List,
+		r.AuthenticatedMiddleware.RequireScope(account.ScopeProjectsRead),
+		r.AuthorizationMiddleware.Require(account.PermissionView))
+	protectedAPI.GET("/project/:project_id", r.ProjectRoutes.Get,
+		r.AuthenticatedMiddleware.RequireScope(account.ScopeProjectsRead),
+		r.ProjectRoutes.LoadProject,
+		r.AuthorizationMiddleware.Require(account.PermissionView))
+	protectedAPI.POST("/project", r.ProjectRoutes.Create,
+		r.AuthenticatedMiddleware.RequireScope(account.ScopeProjectsWrite),
+		r.AuthorizationMiddleware.Require(account.PermissionManage))
+	protectedAPI.PUT("/project/:project_id", r.ProjectRoutes.Update,
+		r.AuthenticatedMiddleware.RequireScope(account.ScopeProjectsWrite),
+		r.ProjectRoutes.LoadProject,
+		r.AuthorizationMiddleware.Require(account.PermissionManage))
+	protectedAPI.DELETE("/project/:project_id", r.ProjectRoutes.Delete,
+		r.AuthenticatedMiddleware.RequireScope(account.ScopeProjectsWrite),
+		r.ProjectRoutes.LoadProject,
+		r.AuthorizationMiddleware.Require(account.PermissionManage))
 
 	protectedAPI.GET("/provider/types", r.ProviderSettingsRoutes.GetProviderTypes)
-	protectedAPI.GET("/provider/settings", r.ProviderSettingsRoutes.List)
+	protectedAPI.GET("/provider/types/:type/catalog", r.ProviderSettingsRoutes.GetCatalog)
+	protectedAPI.GET("/provider/settings", r.ProviderSettingsRoutes.List,
+		r.AuthenticatedMiddleware.RequireScope(account.ScopeProvidersRead),
+		r.AuthorizationMiddleware.Require(account.PermissionView))
 	protectedAPI.GET("/provider/settings/:settings_id", r.ProviderSettingsRoutes.Get,
-		r.ProviderSettingsRoutes.LoadProviderSettings)
-	protectedAPI.POST("/provider/settings", r.ProviderSettingsRoutes.Create)
+		r.AuthenticatedMiddleware.RequireScope(account.ScopeProvidersRead),
+		r.ProviderSettingsRoutes.LoadProviderSettings,
+		r.AuthorizationMiddleware.Require(account.PermissionView))
+	protectedAPI.POST("/provider/settings", r.ProviderSettingsRoutes.Create,
+		r.AuditMiddleware.Record(audit.ActionProviderSettingsCreate, ""),
+		r.AuthenticatedMiddleware.RequireScope(account.ScopeProvidersWrite),
+		r.AuthorizationMiddleware.Require(account.PermissionManage))
+	protectedAPI.PUT("/provider/settings/:settings_id", r.ProviderSettingsRoutes.Update,
+		r.AuditMiddleware.Record(audit.ActionProviderSettingsUpdate, "settings_id"),
+		r.AuthenticatedMiddleware.RequireScope(account.ScopeProvidersWrite),
+		r.AuthenticatedMiddleware.RequireFreshTwoFactor,
+		r.ProviderSettingsRoutes.LoadProviderSettings,
+		r.AuthorizationMiddleware.Require(account.PermissionManage))
+	protectedAPI.POST("/provider/settings/:settings_id/validate", r.ProviderSettingsRoutes.Validate,
+		r.AuditMiddleware.Record(audit.ActionProviderSettingsValidate, "settings_id"),
+		r.AuthenticatedMiddleware.RequireScope(account.ScopeProvidersWrite),
+		r.ProviderSettingsRoutes.LoadProviderSettings,
+		r.AuthorizationMiddleware.Require(account.PermissionManage))
 	protectedAPI.DELETE("/provider/settings/:settings_id", r.ProviderSettingsRoutes.Delete,
-		r.ProviderSettingsRoutes.LoadProviderSettings)
+		r.AuditMiddleware.Record(audit.ActionProviderSettingsDelete, "settings_id"),
+		r.AuthenticatedMiddleware.RequireScope(account.ScopeProvidersWrite),
+		r.ProviderSettingsRoutes.LoadProviderSettings,
+		r.AuthorizationMiddleware.Require(account.PermissionManage))
 
-	protectedAPI.GET("/provision/job", r.ProvisionRoutes.ListJobs)
+	protectedAPI.GET("/provision/job", r.ProvisionRoutes.ListJobs,
+		r.AuthenticatedMiddleware.RequireScope(account.ScopeJobsRead),
+		r.AuthorizationMiddleware.Require(account.PermissionView))
 	protectedAPI.GET("/provision/job/:job_id", r.ProvisionRoutes.GetJob,
-		r.ProvisionRoutes.LoadJob)
-	protectedAPI.POST("/provision/job", r.ProvisionRoutes.CreateJob)
+		r.AuthenticatedMiddleware.RequireScope(account.ScopeJobsRead),
+		r.ProvisionRoutes.LoadJob,
+		r.AuthorizationMiddleware.Require(account.PermissionView))
+	protectedAPI.POST("/provision/job", r.ProvisionRoutes.CreateJob,
+		r.AuthenticatedMiddleware.RequireScope(account.ScopeJobsWrite),
+		r.AuthorizationMiddleware.Require(account.PermissionOperate))
 
-	protectedAPI.GET("/server", r.ServerRoutes.List)
+	protectedAPI.GET("/server", r.ServerRoutes.List,
+		r.AuthenticatedMiddleware.RequireScope(account.ScopeServersRead),
+		r.AuthorizationMiddleware.Require(account.PermissionView))
 	protectedAPI.GET("/server/:server_id", r.ServerRoutes.Get,
-		r.ServerRoutes.LoadServer)
+		r.AuthenticatedMiddleware.RequireScope(account.ScopeServersRead),
+		r.ServerRoutes.LoadServer,
+		r.AuthorizationMiddleware.Require(account.PermissionView))
 	protectedAPI.DELETE("/server/:server_id", r.ServerRoutes.Delete,
-		r.ServerRoutes.LoadServer)
+		r.AuditMiddleware.Record(audit.ActionServerDelete, "server_id"),
+		r.AuthenticatedMiddleware.RequireScope(account.ScopeServersWrite),
+		r.AuthenticatedMiddleware.RequireFreshTwoFactor,
+		r.ServerRoutes.LoadServer,
+		r.AuthorizationMiddleware.Require(account.PermissionOperate))
 
 	protectedAPI.POST("/server/:server_id/key", r.ServerRoutes.AddAuthorizedKey,
-		r.ServerRoutes.LoadServer)
+		r.AuditMiddleware.Record(audit.ActionServerAddAuthorizedKey, "server_id"),
+		r.AuthenticatedMiddleware.RequireScope(account.ScopeServersWrite),
+		r.AuthenticatedMiddleware.RequireFreshTwoFactor,
+		r.ServerRoutes.LoadServer,
+		r.AuthorizationMiddleware.Require(account.PermissionOperate))
+	protectedAPI.PUT("/server/:server_id/firewall", r.ServerRoutes.UpdateFirewall,
+		r.AuditMiddleware.Record(audit.ActionServerUpdateFirewall, "server_id"),
+		r.AuthenticatedMiddleware.RequireScope(account.ScopeServersWrite),
+		r.ServerRoutes.LoadServer,
+		r.AuthorizationMiddleware.Require(account.PermissionOperate))
+	protectedAPI.PUT("/server/:server_id/labels", r.ServerRoutes.UpdateLabels,
+		r.AuditMiddleware.Record(audit.ActionServerUpdateLabels, "server_id"),
+		r.AuthenticatedMiddleware.RequireScope(account.ScopeServersWrite),
+		r.ServerRoutes.LoadServer,
+		r.AuthorizationMiddleware.Require(account.PermissionOperate))
+	protectedAPI.PUT("/server/:server_id/disk_thresholds", r.ServerRoutes.UpdateDiskThresholds,
+		r.AuditMiddleware.Record(audit.ActionServerUpdateDiskThresholds, "server_id"),
+		r.AuthenticatedMiddleware.RequireScope(account.ScopeServersWrite),
+		r.ServerRoutes.LoadServer,
+		r.AuthorizationMiddleware.Require(account.PermissionOperate))
+	protectedAPI.POST("/server/:server_id/volume/resize", r.ServerRoutes.ResizeVolume,
+		r.AuditMiddleware.Record(audit.ActionServerResizeVolume, "server_id"),
+		r.AuthenticatedMiddleware.RequireScope(account.ScopeServersWrite),
+		r.ServerRoutes.LoadServer,
+		r.AuthorizationMiddleware.Require(account.PermissionOperate))
+	protectedAPI.POST("/server/:server_id/volume_snapshot", r.VolumeSnapshotRoutes.Create,
+		r.AuditMiddleware.Record(audit.ActionVolumeSnapshotCreate, ""),
+		r.AuthenticatedMiddleware.RequireScope(account.ScopeVolumeSnapshotsWrite),
+		r.ServerRoutes.LoadServer,
+		r.AuthorizationMiddleware.Require(account.PermissionOperate))
+	protectedAPI.PUT("/server/:server_id/snapshot_policy", r.ServerRoutes.UpdateSnapshotPolicy,
+		r.AuditMiddleware.Record(audit.ActionServerUpdateSnapshotPolicy, "server_id"),
+		r.AuthenticatedMiddleware.RequireScope(account.ScopeServersWrite),
+		r.ServerRoutes.LoadServer,
+		r.AuthorizationMiddleware.Require(account.PermissionOperate))
 
 	protectedAPI.GET("/server/:server_id/deployment", r.DeploymentRoutes.List,
-		r.ServerRoutes.LoadServer)
+		r.AuthenticatedMiddleware.RequireScope(account.ScopeServersRead),
+		r.ServerRoutes.LoadServer,
+		r.AuthorizationMiddleware.Require(account.PermissionView))
+	protectedAPI.PUT("/server/:server_id/deployment/:deployment_id/labels", r.DeploymentRoutes.UpdateLabels,
+		r.AuditMiddleware.Record(audit.ActionDeploymentUpdateLabels, "deployment_id"),
+		r.AuthenticatedMiddleware.RequireScope(account.ScopeServersWrite),
+		r.ServerRoutes.LoadServer,
+		r.AuthorizationMiddleware.Require(account.PermissionOperate))
+
+	protectedAPI.GET("/reserved_ip", r.ReservedIPRoutes.List,
+		r.AuthenticatedMiddleware.RequireScope(account.ScopeReservedIPsRead),
+		r.AuthorizationMiddleware.Require(account.PermissionView))
+	protectedAPI.GET("/reserved_ip/:reserved_ip_id", r.ReservedIPRoutes.Get,
+		r.AuthenticatedMiddleware.RequireScope(account.ScopeReservedIPsRead),
+		r.ReservedIPRoutes.LoadReservedIP,
+		r.AuthorizationMiddleware.Require(account.PermissionView))
+	protectedAPI.POST("/reserved_ip", r.ReservedIPRoutes.Create,
+		r.AuditMiddleware.Record(audit.ActionReservedIPCreate, ""),
+		r.AuthenticatedMiddleware.RequireScope(account.ScopeReservedIPsWrite),
+		r.AuthorizationMiddleware.Require(account.PermissionOperate))
+	protectedAPI.DELETE("/reserved_ip/:reserved_ip_id", r.ReservedIPRoutes.Delete,
+		r.AuditMiddleware.Record(audit.ActionReservedIPDelete, "reserved_ip_id"),
+		r.AuthenticatedMiddleware.RequireScope(account.ScopeReservedIPsWrite),
+		r.ReservedIPRoutes.LoadReservedIP,
+		r.AuthorizationMiddleware.Require(account.PermissionOperate))
+
+	protectedAPI.GET("/volume_snapshot", r.VolumeSnapshotRoutes.List,
+		r.AuthenticatedMiddleware.RequireScope(account.ScopeVolumeSnapshotsRead),
+		r.AuthorizationMiddleware.Require(account.PermissionView))
+	protectedAPI.GET("/volume_snapshot/:volume_snapshot_id", r.VolumeSnapshotRoutes.Get,
+		r.AuthenticatedMiddleware.RequireScope(account.ScopeVolumeSnapshotsRead),
+		r.VolumeSnapshotRoutes.LoadVolumeSnapshot,
+		r.AuthorizationMiddleware.Require(account.PermissionView))
+	protectedAPI.DELETE("/volume_snapshot/:volume_snapshot_id", r.VolumeSnapshotRoutes.Delete,
+		r.AuditMiddleware.Record(audit.ActionVolumeSnapshotDelete, "volume_snapshot_id"),
+		r.AuthenticatedMiddleware.RequireScope(account.ScopeVolumeSnapshotsWrite),
+		r.VolumeSnapshotRoutes.LoadVolumeSnapshot,
+		r.AuthorizationMiddleware.Require(account.PermissionOperate))
+
+	protectedAPI.GET("/audit", r.AuditRoutes.List,
+		r.AuthenticatedMiddleware.RequireScope(account.ScopeAuditRead),
+		r.AuthorizationMiddleware.Require(account.PermissionViewAudit))
+	protectedAPI.GET("/audit/export", r.AuditRoutes.Export,
+		r.AuthenticatedMiddleware.RequireScope(account.ScopeAuditRead),
+		r.AuthorizationMiddleware.Require(account.PermissionViewAudit))
 
 	return nil
 }
@@ -2,6 +2,7 @@ package routes
 
 import (
 	"context"
+	"net/http"
 
 	"blockpropeller.dev/blockpropeller/account"
 	"blockpropeller.dev/blockpropeller/httpserver/request"
@@ -14,14 +15,26 @@ type GetAccountResponse struct {
 	Account *account.Account `json:"account"`
 }
 
+// ChangePasswordRequest holds the request payload for the change password endpoint.
+type ChangePasswordRequest struct {
+	OldPassword account.ClearPassword `json:"old_password" form:"old_password" validate:"required"`
+	NewPassword account.ClearPassword `json:"new_password" form:"new_password" validate:"required,min=6"`
+}
+
 // Account REST Resource for accessing account information.
 type Account struct {
+	accSvc  *account.Service
+	credSvc *account.CredentialService
 	accRepo account.Repository
 }
 
 // NewAccountRoutes returns a new Account REST resource.
-func NewAccountRoutes(accRepo account.Repository) *Account {
-	return &Account{accRepo: accRepo}
+func NewAccountRoutes(
+	accSvc *account.Service,
+	credSvc *account.CredentialService,
+	accRepo account.Repository,
+) *Account {
+	return &Account{accSvc: accSvc, credSvc: credSvc, accRepo: accRepo}
 }
 
 // LoadAccount is a middleware for loading Accounts into request context
@@ -64,3 +77,52 @@ func (a *Account) Get(c echo.Context) error {
 
 	return c.JSON(200, &GetAccountResponse{Account: acc})
 }
+
+// ChangePassword of the authenticated Account.
+//
+// Changing the password logs the Account out of all of its other sessions.
+func (a *Account) ChangePassword(c echo.Context) error {
+	var req ChangePasswordRequest
+	if err := request.Parse(c, &req); err != nil {
+		return err
+	}
+
+	acc := request.AuthFromContext(c)
+	session := request.SessionFromContext(c)
+	if acc == nil || session == nil {
+		return echo.ErrForbidden.SetInternal(errors.New("missing authenticated session"))
+	}
+
+	err := a.accSvc.ChangePassword(acc, req.OldPassword, req.NewPassword)
+	if errors.Cause(err) == account.ErrInvalidPassword {
+		return echo.ErrForbidden.SetInternal(err)
+	}
+	if err != nil {
+		return echo.ErrBadRequest.SetInternal(errors.Wrap(err, "change password"))
+	}
+
+	err = a.accSvc.LogoutOthers(acc, session)
+	if err != nil {
+		return errors.Wrap(err, "logout other sessions")
+	}
+
+	return c.NoContent(204)
+}
+
+// SendVerification sends the email verification link to the authenticated Account again.
+func (a *Account) SendVerification(c echo.Context) error {
+	acc := request.AuthFromContext(c)
+	if acc == nil {
+		return echo.ErrForbidden.SetInternal(errors.New("missing authenticated account"))
+	}
+
+	err := a.credSvc.SendVerification(context.Background(), acc)
+	if errors.Cause(err) == account.ErrEmailAlreadyVerified {
+		return echo.NewHTTPError(http.StatusConflict, "email already verified").SetInternal(err)
+	}
+	if err != nil {
+		return errors.Wrap(err, "send email verification")
+	}
+
+	return c.NoContent(202)
+}
@@ -0,0 +1,113 @@
+package routes
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"blockpropeller.dev/blockpropeller/account"
+	"blockpropeller.dev/blockpropeller/httpserver/request"
+	"github.com/labstack/echo"
+	"github.com/pkg/errors"
+)
+
+// ListAPIKeysResponse is a response to the list API keys request.
+type ListAPIKeysResponse struct {
+	APIKeys []*account.APIKey `json:"api_keys"`
+}
+
+// CreateAPIKeyRequest holds the request payload for the create API key endpoint.
+type CreateAPIKeyRequest struct {
+	Name   string         `json:"name" form:"name" validate:"required,max=255"`
+	Scopes account.Scopes `json:"scopes" form:"scopes" validate:"required,valid"`
+	// ExpiresAt is optional, keys without an expiry are valid until they are revoked.
+	ExpiresAt *time.Time `json:"expires_at" form:"expires_at"`
+}
+
+// CreateAPIKeyResponse is a response to the create API key request.
+//
+// The Token is returned only once, and cannot be retrieved afterwards.
+type CreateAPIKeyResponse struct {
+	APIKey *account.APIKey     `json:"api_key"`
+	Token  account.APIKeyToken `json:"token"`
+}
+
+// APIKey REST Resource for managing the API keys of the authenticated Account.
+type APIKey struct {
+	apiKeySvc *account.APIKeyService
+	keyRepo   account.APIKeyRepository
+}
+
+// NewAPIKeyRoutes returns a new APIKey routes instance.
+func NewAPIKeyRoutes(apiKeySvc *account.APIKeyService, keyRepo account.APIKeyRepository) *APIKey {
+	return &APIKey{apiKeySvc: apiKeySvc, keyRepo: keyRepo}
+}
+
+// List all the API keys of the authenticated Account.
+func (k *APIKey) List(c echo.Context) error {
+	acc := request.AuthFromContext(c)
+	if acc == nil {
+		return echo.ErrForbidden
+	}
+
+	keys, err := k.keyRepo.ListByAccount(context.Background(), acc.ID)
+	if err != nil {
+		return errors.Wrap(err, "list api keys")
+	}
+
+	return c.JSON(200, &ListAPIKeysResponse{APIKeys: keys})
+}
+
+// Create an API key for the authenticated Account.
+func (k *APIKey) Create(c echo.Context) error {
+	var req CreateAPIKeyRequest
+	if err := request.Parse(c, &req); err != nil {
+		return err
+	}
+
+	acc := request.AuthFromContext(c)
+	if acc == nil {
+		return echo.ErrInternalServerError.SetInternal(errors.New("missing authenticated user"))
+	}
+
+	key, token, err := k.apiKeySvc.Create(context.Background(), acc, req.Name, req.Scopes, req.ExpiresAt)
+	if errors.Cause(err) == account.ErrTooManyAPIKeys {
+		return echo.NewHTTPError(http.StatusConflict, "too many api keys").SetInternal(err)
+	}
+	if err != nil {
+		return echo.ErrBadRequest.SetInternal(err)
+	}
+
+	request.WithAuditTarget(c, key.ID.String())
+
+	return c.JSON(201, &CreateAPIKeyResponse{APIKey: key, Token: token})
+}
+
+// Revoke an API key of the authenticated Account.
+func (k *APIKey) Revoke(c echo.Context) error {
+	acc := request.AuthFromContext(c)
+	if acc == nil {
+		return echo.ErrForbidden
+	}
+
+	keyID := account.APIKeyID(c.Param("key_id"))
+
+	key, err := k.keyRepo.Find(context.Background(), keyID)
+	if errors.Cause(err) == account.ErrAPIKeyNotFound {
+		return echo.ErrNotFound.SetInternal(err)
+	}
+	if err != nil {
+		return errors.Wrap(err, "find api key")
+	}
+	if key.AccountID != acc.ID {
+		return echo.ErrNotFound.
+			SetInternal(errors.Errorf("api key %s of another account", key.ID))
+	}
+
+	err = k.apiKeySvc.Revoke(context.Background(), key)
+	if err != nil {
+		return err
+	}
+
+	return c.NoContent(204)
+}
@@ -0,0 +1,122 @@
+package routes
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"blockpropeller.dev/blockpropeller/account"
+	"blockpropeller.dev/blockpropeller/audit"
+	"blockpropeller.dev/blockpropeller/httpserver/request"
+	"github.com/labstack/echo"
+	"github.com/pkg/errors"
+)
+
+const (
+	// defaultAuditLimit is the number of entries listed when no limit is requested.
+	defaultAuditLimit = 100
+	// maxAuditLimit caps the number of entries listed at once, larger sets should be exported.
+	maxAuditLimit = 1000
+
+	// mimeJSONLines is the content type of exported audit logs.
+	mimeJSONLines = "application/x-ndjson"
+)
+
+// ListAuditEntriesResponse is a response to the list audit entries request.
+type ListAuditEntriesResponse struct {
+	Entries []*audit.Entry `json:"entries"`
+}
+
+// Audit REST Resource for querying the audit log of an Organization.
+type Audit struct {
+	auditSvc *audit.Service
+}
+
+// NewAuditRoutes returns a new Audit routes instance.
+func NewAuditRoutes(auditSvc *audit.Service) *Audit {
+	return &Audit{auditSvc: auditSvc}
+}
+
+// List the audit entries of the Organization, from the most recent ones.
+//
+// Entries can be filtered by the `actor_id`, `action`, `target_type`, `target_id` and `outcome`
+// query parameters, and by their time with the `since` and `until` RFC 3339 timestamps.
+func (a *Audit) List(c echo.Context) error {
+	filter, err := parseAuditFilter(c)
+	if err != nil {
+		return err
+	}
+	if filter.Limit == 0 {
+		filter.Limit = defaultAuditLimit
+	}
+	if filter.Limit > maxAuditLimit {
+		filter.Limit = maxAuditLimit
+	}
+
+	entries, err := a.auditSvc.List(context.Background(), filter)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(200, &ListAuditEntriesResponse{Entries: entries})
+}
+
+// Export the audit entries of the Organization as JSON lines, accepting the same filters as List.
+//
+// Exports are not limited, unless a limit is requested.
+func (a *Audit) Export(c echo.Context) error {
+	filter, err := parseAuditFilter(c)
+	if err != nil {
+		return err
+	}
+
+	entries, err := a.auditSvc.List(context.Background(), filter)
+	if err != nil {
+		return err
+	}
+
+	c.Response().Header().Set(echo.HeaderContentType, mimeJSONLines)
+	c.Response().Header().Set(echo.HeaderContentDisposition, `attachment; filename="audit.jsonl"`)
+	c.Response().WriteHeader(http.StatusOK)
+
+	return audit.WriteJSONLines(c.Response(), entries)
+}
+
+func parseAuditFilter(c echo.Context) (audit.Filter, error) {
+	orgID := request.OrganizationFromContext(c)
+	if orgID == account.NilOrganizationID {
+		return audit.Filter{}, echo.ErrForbidden.SetInternal(errors.New("missing organization"))
+	}
+
+	filter := audit.Filter{
+		OrganizationID: orgID,
+		ActorID:        account.ID(c.QueryParam("actor_id")),
+		Action:         audit.Action(c.QueryParam("action")),
+		TargetType:     c.QueryParam("target_type"),
+		TargetID:       c.QueryParam("target_id"),
+		Outcome:        audit.Outcome(c.QueryParam("outcome")),
+	}
+
+	var err error
+	if since := c.QueryParam("since"); since != "" {
+		filter.Since, err = time.Parse(time.RFC3339, since)
+		if err != nil {
+			return audit.Filter{}, echo.NewHTTPError(http.StatusBadRequest, "invalid since timestamp").SetInternal(err)
+		}
+	}
+	if until := c.QueryParam("until"); until != "" {
+		filter.Until, err = time.Parse(time.RFC3339, until)
+		if err != nil {
+			return audit.Filter{}, echo.NewHTTPError(http.StatusBadRequest, "invalid until timestamp").SetInternal(err)
+		}
+	}
+	if limit := c.QueryParam("limit"); limit != "" {
+		filter.Limit, err = strconv.Atoi(limit)
+		if err != nil || filter.Limit < 0 {
+			return audit.Filter{}, echo.NewHTTPError(http.StatusBadRequest, "invalid limit").SetInternal(err)
+		}
+	}
+
+	return filter, nil
+}
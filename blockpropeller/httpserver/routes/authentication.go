@@ -1,8 +1,16 @@
 package routes
 
 import (
+	"context"
+	"net/http"
+	"time"
+
 	"blockpropeller.dev/blockpropeller/account"
 	"blockpropeller.dev/blockpropeller/httpserver/request"
+	"blockpropeller.dev/blockpropeller/infrastructure"
+	"blockpropeller.dev/blockpropeller/oidc"
+	"blockpropeller.dev/lib/log"
+	"blockpropeller.dev/lib/server"
 	"github.com/labstack/echo"
 	"github.com/pkg/errors"
 )
@@ -16,38 +24,119 @@ type RegisterRequest struct {
 // RegisterResponse holds the response returned to the caller upon successful registration.
 type RegisterResponse struct {
 	Account *account.Account `json:"account"`
-	Token   account.Token    `json:"token"`
+
+	Token          account.Token        `json:"token"`
+	TokenExpiresAt time.Time            `json:"token_expires_at"`
+	RefreshToken   account.RefreshToken `json:"refresh_token"`
 }
 
 // LoginRequest holds the request payload for the login endpoint.
 type LoginRequest struct {
 	Email    account.Email         `json:"email" form:"email" validate:"required,email"`
 	Password account.ClearPassword `json:"password" form:"password" validate:"required"`
+	// Code is the second factor of accounts with two-factor authentication,
+	// either a TOTP code or a recovery code.
+	Code string `json:"code" form:"code"`
 }
 
 // LoginResponse holds the response payload for the login endpoint.
 type LoginResponse struct {
-	Token account.Token `json:"token"`
+	Token          account.Token        `json:"token"`
+	TokenExpiresAt time.Time            `json:"token_expires_at"`
+	RefreshToken   account.RefreshToken `json:"refresh_token"`
+}
+
+// RefreshRequest holds the request payload for the refresh endpoint.
+type RefreshRequest struct {
+	RefreshToken account.RefreshToken `json:"refresh_token" form:"refresh_token" validate:"required"`
+}
+
+// RefreshResponse holds the response payload for the refresh endpoint.
+//
+// The provided refresh token is rotated, and cannot be used again.
+type RefreshResponse struct {
+	Token          account.Token        `json:"token"`
+	TokenExpiresAt time.Time            `json:"token_expires_at"`
+	RefreshToken   account.RefreshToken `json:"refresh_token"`
+}
+
+// VerifyEmailRequest holds the request payload for the verify email endpoint.
+type VerifyEmailRequest struct {
+	Token account.OneTimeSecret `json:"token" form:"token" validate:"required"`
+}
+
+// VerifyEmailResponse holds the response payload for the verify email endpoint.
+type VerifyEmailResponse struct {
+	Account *account.Account `json:"account"`
+}
+
+// ForgotPasswordRequest holds the request payload for the forgot password endpoint.
+type ForgotPasswordRequest struct {
+	Email account.Email `json:"email" form:"email" validate:"required,email"`
+}
+
+// ResetPasswordRequest holds the request payload for the reset password endpoint.
+type ResetPasswordRequest struct {
+	Token    account.OneTimeSecret `json:"token" form:"token" validate:"required"`
+	Password account.ClearPassword `json:"password" form:"password" validate:"required,min=6"`
+}
+
+// SSOLoginResponse holds the response payload for the single sign-on login endpoint.
+type SSOLoginResponse struct {
+	// AuthorizationURL is where the user authenticates with the identity provider.
+	AuthorizationURL string `json:"authorization_url"`
+}
+
+// SSOCallbackRequest holds the request payload for the single sign-on callback endpoint,
+// which is forwarded from the redirect of the identity provider.
+type SSOCallbackRequest struct {
+	State account.SSOState `json:"state" form:"state" validate:"required"`
+	Code  string           `json:"code" form:"code" validate:"required"`
+	// TwoFactorCode is the second factor of accounts with two-factor authentication,
+	// either a TOTP code or a recovery code.
+	TwoFactorCode string `json:"two_factor_code" form:"two_factor_code"`
+}
+
+// SSOCallbackResponse holds the response payload for the single sign-on callback endpoint.
+type SSOCallbackResponse struct {
+	Account *account.Account `json:"account"`
+
+	Token          account.Token        `json:"token"`
+	TokenExpiresAt time.Time            `json:"token_expires_at"`
+	RefreshToken   account.RefreshToken `json:"refresh_token"`
 }
 
 // Authentication routes define how a user authenticates with the system.
 type Authentication struct {
-	accSvc *account.Service
+	accSvc      *account.Service
+	credSvc     *account.CredentialService
+	ssoSvc      *account.SSOService
+	orgSvc      *account.OrganizationService
+	projectRepo infrastructure.ProjectRepository
 }
 
 // NewAuthenticationRoutes returns a new Authentication instance.
-func NewAuthenticationRoutes(accSvc *account.Service) *Authentication {
-	return &Authentication{accSvc: accSvc}
+func NewAuthenticationRoutes(
+	accSvc *account.Service,
+	credSvc *account.CredentialService,
+	ssoSvc *account.SSOService,
+	orgSvc *account.OrganizationService,
+	projectRepo infrastructure.ProjectRepository,
+) *Authentication {
+	return &Authentication{accSvc: accSvc, credSvc: credSvc, ssoSvc: ssoSvc, orgSvc: orgSvc, projectRepo: projectRepo}
 }
 
 // Register an account with BlockPropeller.
+//
+// New accounts start with a personal organization with a default project,
+// which hold the infrastructure created without selecting an organization or a project.
 func (a *Authentication) Register(c echo.Context) error {
 	var req RegisterRequest
 	if err := request.Parse(c, &req); err != nil {
 		return err
 	}
 
-	acc, token, err := a.accSvc.Register(req.Email, req.Password)
+	acc, tokens, err := a.accSvc.Register(req.Email, req.Password)
 	if errors.Cause(err) == account.ErrEmailAlreadyExists {
 		return echo.ErrBadRequest.SetInternal(err)
 	}
@@ -55,9 +144,24 @@ func (a *Authentication) Register(c echo.Context) error {
 		return errors.Wrap(err, "register account")
 	}
 
+	err = a.setupAccount(context.Background(), acc)
+	if err != nil {
+		return err
+	}
+
+	// The account is usable without a verified email, and the verification can be sent again.
+	err = a.credSvc.SendVerification(context.Background(), acc)
+	if err != nil {
+		log.ErrorErr(err, "failed sending email verification", log.Fields{
+			"account_id": acc.ID,
+		})
+	}
+
 	return c.JSON(201, &RegisterResponse{
-		Account: acc,
-		Token:   token,
+		Account:        acc,
+		Token:          tokens.AccessToken,
+		TokenExpiresAt: tokens.AccessTokenExpiresAt,
+		RefreshToken:   tokens.RefreshToken,
 	})
 }
 
@@ -68,10 +172,219 @@ func (a *Authentication) Login(c echo.Context) error {
 		return err
 	}
 
-	token, err := a.accSvc.Login(req.Email, req.Password)
+	request.WithAuditTarget(c, account.NewEmail(req.Email.String()).String())
+
+	tokens, err := a.accSvc.Login(req.Email, req.Password, req.Code)
+	switch errors.Cause(err) {
+	case nil:
+	case account.ErrTwoFactorRequired:
+		// The password was correct, so the attempt is neither failed nor successful until the code is provided.
+		server.MarkIncompleteAttempt(c)
+
+		return echo.NewHTTPError(http.StatusUnauthorized, errors.Cause(err).Error()).SetInternal(err)
+	case account.ErrInvalidTwoFactorCode:
+		return echo.NewHTTPError(http.StatusUnauthorized, errors.Cause(err).Error()).SetInternal(err)
+	case account.ErrInvalidCredentials:
+		return echo.NewHTTPError(http.StatusForbidden, errors.Cause(err).Error()).SetInternal(err)
+	default:
+		return errors.Wrap(err, "login account")
+	}
+
+	return c.JSON(200, &LoginResponse{
+		Token:          tokens.AccessToken,
+		TokenExpiresAt: tokens.AccessTokenExpiresAt,
+		RefreshToken:   tokens.RefreshToken,
+	})
+}
+
+// Refresh a session, exchanging a refresh token for new tokens.
+func (a *Authentication) Refresh(c echo.Context) error {
+	var req RefreshRequest
+	if err := request.Parse(c, &req); err != nil {
+		return err
+	}
+
+	tokens, err := a.accSvc.Refresh(req.RefreshToken)
+	if errors.Cause(err) == account.ErrRefreshTokenReused {
+		// Reused refresh tokens were likely stolen, so the attempt is recorded for the owner of the Session.
+		acc, findErr := a.accSvc.FindByRefreshToken(req.RefreshToken)
+		if findErr != nil {
+			log.ErrorErr(findErr, "failed finding account of reused refresh token")
+		} else {
+			request.WithAuditTarget(c, acc.Email.String())
+		}
+
+		return echo.NewHTTPError(http.StatusUnauthorized, errors.Cause(err).Error()).SetInternal(err)
+	}
+	if err != nil {
+		return echo.ErrUnauthorized.SetInternal(errors.Wrap(err, "refresh session"))
+	}
+
+	return c.JSON(200, &RefreshResponse{
+		Token:          tokens.AccessToken,
+		TokenExpiresAt: tokens.AccessTokenExpiresAt,
+		RefreshToken:   tokens.RefreshToken,
+	})
+}
+
+// Logout from the authenticated session.
+func (a *Authentication) Logout(c echo.Context) error {
+	session := request.SessionFromContext(c)
+	if session == nil {
+		return echo.ErrForbidden.SetInternal(errors.New("missing authenticated session"))
+	}
+
+	err := a.accSvc.Logout(session)
+	if err != nil {
+		return errors.Wrap(err, "logout session")
+	}
+
+	return c.NoContent(204)
+}
+
+// LogoutAll logs the authenticated account out of all of its sessions.
+func (a *Authentication) LogoutAll(c echo.Context) error {
+	acc := request.AuthFromContext(c)
+	if acc == nil {
+		return echo.ErrForbidden.SetInternal(errors.New("missing authenticated account"))
+	}
+
+	err := a.accSvc.LogoutAll(acc)
+	if err != nil {
+		return errors.Wrap(err, "logout all sessions")
+	}
+
+	return c.NoContent(204)
+}
+
+// VerifyEmail verifies the email of an account using the token sent to it.
+func (a *Authentication) VerifyEmail(c echo.Context) error {
+	var req VerifyEmailRequest
+	if err := request.Parse(c, &req); err != nil {
+		return err
+	}
+
+	acc, err := a.credSvc.VerifyEmail(context.Background(), req.Token)
+	if errors.Cause(err) == account.ErrOneTimeTokenNotFound {
+		return echo.ErrNotFound.SetInternal(err)
+	}
+	if err != nil {
+		return errors.Wrap(err, "verify email")
+	}
+
+	return c.JSON(200, &VerifyEmailResponse{Account: acc})
+}
+
+// ForgotPassword sends a password reset link to the provided email.
+//
+// The request is accepted whether or not an account with the email exists.
+func (a *Authentication) ForgotPassword(c echo.Context) error {
+	var req ForgotPasswordRequest
+	if err := request.Parse(c, &req); err != nil {
+		return err
+	}
+
+	email := account.NewEmail(req.Email.String())
+	request.WithAuditTarget(c, email.String())
+
+	// The reset is requested in the background, so the response takes the same time
+	// whether or not the email is sent.
+	go func() {
+		if err := a.credSvc.RequestPasswordReset(context.Background(), email); err != nil {
+			log.ErrorErr(err, "failed requesting password reset")
+		}
+	}()
+
+	return c.NoContent(202)
+}
+
+// ResetPassword sets a new password for an account using the token sent to it.
+func (a *Authentication) ResetPassword(c echo.Context) error {
+	var req ResetPasswordRequest
+	if err := request.Parse(c, &req); err != nil {
+		return err
+	}
+
+	acc, err := a.credSvc.ResetPassword(context.Background(), req.Token, req.Password)
+	if errors.Cause(err) == account.ErrOneTimeTokenNotFound {
+		return echo.ErrNotFound.SetInternal(err)
+	}
+	if err != nil {
+		return echo.ErrBadRequest.SetInternal(errors.Wrap(err, "reset password"))
+	}
+
+	request.WithAuditTarget(c, acc.Email.String())
+
+	return c.NoContent(204)
+}
+
+// SSOLogin starts a single sign-on login through the configured identity provider.
+func (a *Authentication) SSOLogin(c echo.Context) error {
+	if !a.ssoSvc.Enabled() {
+		return echo.ErrNotFound.SetInternal(oidc.ErrDisabled)
+	}
+
+	authURL, err := a.ssoSvc.Begin(context.Background())
+	if err != nil {
+		return errors.Wrap(err, "begin sso login")
+	}
+
+	return c.JSON(200, &SSOLoginResponse{AuthorizationURL: authURL})
+}
+
+// SSOCallback completes a single sign-on login, exchanging the authorization code for tokens.
+//
+// Accounts provisioned through single sign-on start with a personal organization and a default project,
+// the same as registered accounts.
+func (a *Authentication) SSOCallback(c echo.Context) error {
+	if !a.ssoSvc.Enabled() {
+		return echo.ErrNotFound.SetInternal(oidc.ErrDisabled)
+	}
+
+	var req SSOCallbackRequest
+	if err := request.Parse(c, &req); err != nil {
+		return err
+	}
+
+	acc, tokens, err := a.ssoSvc.Complete(context.Background(), req.State, req.Code, req.TwoFactorCode)
+	switch errors.Cause(err) {
+	case nil:
+	case account.ErrTwoFactorRequired, account.ErrInvalidTwoFactorCode:
+		return echo.NewHTTPError(http.StatusUnauthorized, errors.Cause(err).Error()).SetInternal(err)
+	case account.ErrSSOAuthorizationNotFound:
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid or expired sso state").SetInternal(err)
+	case account.ErrAccountNotFound, account.ErrEmailNotVerified:
+		return echo.NewHTTPError(http.StatusForbidden, errors.Cause(err).Error()).SetInternal(err)
+	default:
+		return echo.ErrUnauthorized.SetInternal(errors.Wrap(err, "complete sso login"))
+	}
+
+	request.WithAuditTarget(c, acc.Email.String())
+
+	err = a.setupAccount(context.Background(), acc)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(200, &SSOCallbackResponse{
+		Account:        acc,
+		Token:          tokens.AccessToken,
+		TokenExpiresAt: tokens.AccessTokenExpiresAt,
+		RefreshToken:   tokens.RefreshToken,
+	})
+}
+
+// setupAccount makes sure the account has a personal organization with a default project.
+func (a *Authentication) setupAccount(ctx context.Context, acc *account.Account) error {
+	org, err := a.orgSvc.Personal(ctx, acc)
+	if err != nil {
+		return errors.Wrap(err, "create personal organization")
+	}
+
+	_, err = infrastructure.DefaultProject(ctx, a.projectRepo, org.ID, acc.ID)
 	if err != nil {
-		return echo.ErrForbidden.SetInternal(errors.Wrap(err, "login account"))
+		return errors.Wrap(err, "create default project")
 	}
 
-	return c.JSON(200, &LoginResponse{Token: token})
+	return nil
 }
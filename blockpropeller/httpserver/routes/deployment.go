@@ -2,9 +2,11 @@ package routes
 
 import (
 	"context"
+	"net/http"
 
 	"blockpropeller.dev/blockpropeller/httpserver/request"
 	"blockpropeller.dev/blockpropeller/infrastructure"
+	"blockpropeller.dev/blockpropeller/provision"
 	"github.com/labstack/echo"
 	"github.com/pkg/errors"
 )
@@ -14,14 +16,26 @@ type ListDeploymentsResponse struct {
 	Deployments []*infrastructure.Deployment `json:"deployments"`
 }
 
+// GetDeploymentResponse is a response to the get deployment request.
+type GetDeploymentResponse struct {
+	Deployment *infrastructure.Deployment `json:"deployment"`
+}
+
 // Deployment REST Resource for accessing deployment information.
 type Deployment struct {
+	jobScheduler *provision.JobScheduler
+
 	deploymentRepo infrastructure.DeploymentRepository
+	settingsRepo   infrastructure.ProviderSettingsRepository
 }
 
 // NewDeploymentRoutes returns a new Deployment routes instance.
-func NewDeploymentRoutes(deploymentRepo infrastructure.DeploymentRepository) *Deployment {
-	return &Deployment{deploymentRepo: deploymentRepo}
+func NewDeploymentRoutes(
+	jobScheduler *provision.JobScheduler,
+	deploymentRepo infrastructure.DeploymentRepository,
+	settingsRepo infrastructure.ProviderSettingsRepository,
+) *Deployment {
+	return &Deployment{jobScheduler: jobScheduler, deploymentRepo: deploymentRepo, settingsRepo: settingsRepo}
 }
 
 // List all Deployments for a Server.
@@ -40,3 +54,51 @@ func (s *Deployment) List(c echo.Context) error {
 		Deployments: deployments,
 	})
 }
+
+// UpdateLabels schedules a Job replacing the labels of a Deployment and applying them
+// as tags of the infrastructure of the Server it runs on.
+func (s *Deployment) UpdateLabels(c echo.Context) error {
+	srv := request.ServerFromContext(c)
+	if srv == nil {
+		return echo.ErrNotFound.SetInternal(errors.New("server not found in context"))
+	}
+
+	var deployment *infrastructure.Deployment
+	for _, candidate := range srv.Deployments {
+		if candidate.ID.String() == c.Param("deployment_id") {
+			deployment = candidate
+		}
+	}
+	if deployment == nil {
+		return echo.ErrNotFound.SetInternal(errors.Errorf("deployment not found on server %s", srv.ID))
+	}
+
+	var req UpdateLabelsRequest
+	if err := request.Parse(c, &req); err != nil {
+		return err
+	}
+
+	err := req.Labels.Validate()
+	if err != nil {
+		return echo.ErrBadRequest.SetInternal(err)
+	}
+
+	if srv.Provider != infrastructure.ProviderBYO && srv.WorkspaceSnapshot == nil {
+		return echo.ErrBadRequest.SetInternal(provision.ErrServerNotManaged)
+	}
+
+	settings, err := s.settingsRepo.Find(context.Background(), srv.ProviderSettingsID)
+	if err != nil {
+		return errors.Wrap(err, "find provider settings")
+	}
+
+	job, err := s.jobScheduler.ScheduleDeploymentLabelsUpdate(context.Background(), settings, srv, deployment, req.Labels)
+	if errors.Cause(err) == provision.ErrServerUpdateInProgress {
+		return echo.NewHTTPError(http.StatusConflict, "server update already in progress").SetInternal(err)
+	}
+	if err != nil {
+		return errors.Wrap(err, "schedule deployment labels update")
+	}
+
+	return c.JSON(202, &CreateJobResponse{Job: job})
+}
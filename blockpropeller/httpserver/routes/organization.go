@@ -0,0 +1,421 @@
+package routes
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"blockpropeller.dev/blockpropeller/account"
+	"blockpropeller.dev/blockpropeller/httpserver/request"
+	"github.com/labstack/echo"
+	"github.com/pkg/errors"
+)
+
+// MemberOrganization is an Organization along with the Role the authenticated Account holds within it.
+type MemberOrganization struct {
+	*account.Organization
+
+	Role account.Role `json:"role"`
+}
+
+// ListOrganizationsResponse is a response to the list organizations request.
+type ListOrganizationsResponse struct {
+	Organizations []*MemberOrganization `json:"organizations"`
+}
+
+// GetOrganizationResponse is a response to the get organization request.
+type GetOrganizationResponse struct {
+	Organization *MemberOrganization `json:"organization"`
+}
+
+// CreateOrganizationRequest holds the request payload for the create organization endpoint.
+type CreateOrganizationRequest struct {
+	Name string `json:"name" form:"name" validate:"required,max=255"`
+}
+
+// CreateOrganizationResponse is a response to the create organization request.
+type CreateOrganizationResponse struct {
+	Organization *MemberOrganization `json:"organization"`
+}
+
+// UpdateOrganizationRequest holds the request payload for the update organization endpoint.
+type UpdateOrganizationRequest struct {
+	Name string `json:"name" form:"name" validate:"required,max=255"`
+}
+
+// UpdateOrganizationResponse is a response to the update organization request.
+type UpdateOrganizationResponse struct {
+	Organization *account.Organization `json:"organization"`
+}
+
+// Member is an Account holding a Role within an Organization.
+type Member struct {
+	AccountID account.ID    `json:"account_id"`
+	Email     account.Email `json:"email"`
+	Role      account.Role  `json:"role"`
+	CreatedAt time.Time     `json:"created_at"`
+}
+
+// ListMembersResponse is a response to the list organization members request.
+type ListMembersResponse struct {
+	Members []*Member `json:"members"`
+}
+
+// UpdateMemberRequest holds the request payload for the update organization member endpoint.
+type UpdateMemberRequest struct {
+	Role account.Role `json:"role" form:"role" validate:"required,valid"`
+}
+
+// UpdateMemberResponse is a response to the update organization member request.
+type UpdateMemberResponse struct {
+	Membership *account.Membership `json:"membership"`
+}
+
+// ListInvitationsResponse is a response to the list organization invitations request.
+type ListInvitationsResponse struct {
+	Invitations []*account.Invitation `json:"invitations"`
+}
+
+// CreateInvitationRequest holds the request payload for the create invitation endpoint.
+type CreateInvitationRequest struct {
+	Email account.Email `json:"email" form:"email" validate:"required,email"`
+	Role  account.Role  `json:"role" form:"role" validate:"required,valid"`
+}
+
+// CreateInvitationResponse is a response to the create invitation request.
+//
+// The token is only ever returned here, and has to be handed to the invitee for accepting the Invitation.
+type CreateInvitationResponse struct {
+	Invitation *account.Invitation     `json:"invitation"`
+	Token      account.InvitationToken `json:"token"`
+}
+
+// AcceptInvitationRequest holds the request payload for the accept invitation endpoint.
+type AcceptInvitationRequest struct {
+	Token account.InvitationToken `json:"token" form:"token" validate:"required"`
+}
+
+// AcceptInvitationResponse is a response to the accept invitation request.
+type AcceptInvitationResponse struct {
+	Membership *account.Membership `json:"membership"`
+}
+
+// Organization REST Resource for managing organizations, their members and invitations.
+type Organization struct {
+	orgSvc *account.OrganizationService
+
+	accRepo    account.Repository
+	orgRepo    account.OrganizationRepository
+	memberRepo account.MembershipRepository
+	inviteRepo account.InvitationRepository
+}
+
+// NewOrganizationRoutes returns a new Organization routes instance.
+func NewOrganizationRoutes(
+	orgSvc *account.OrganizationService,
+	accRepo account.Repository,
+	orgRepo account.OrganizationRepository,
+	memberRepo account.MembershipRepository,
+	inviteRepo account.InvitationRepository,
+) *Organization {
+	return &Organization{
+		orgSvc:     orgSvc,
+		accRepo:    accRepo,
+		orgRepo:    orgRepo,
+		memberRepo: memberRepo,
+		inviteRepo: inviteRepo,
+	}
+}
+
+// List all Organizations the authenticated Account is a member of.
+//
+// Accounts are always a member of at least their personal Organization.
+func (o *Organization) List(c echo.Context) error {
+	acc := request.AuthFromContext(c)
+	if acc == nil {
+		return echo.ErrForbidden
+	}
+
+	_, err := o.orgSvc.Personal(context.Background(), acc)
+	if err != nil {
+		return errors.Wrap(err, "ensure personal organization")
+	}
+
+	memberships, err := o.memberRepo.ListByAccount(context.Background(), acc.ID)
+	if err != nil {
+		return errors.Wrap(err, "list memberships")
+	}
+
+	orgs := make([]*MemberOrganization, 0, len(memberships))
+	for _, membership := range memberships {
+		org, err := o.orgRepo.Find(context.Background(), membership.OrganizationID)
+		if err != nil {
+			return errors.Wrapf(err, "find organization %s", membership.OrganizationID)
+		}
+
+		orgs = append(orgs, &MemberOrganization{Organization: org, Role: membership.Role})
+	}
+
+	return c.JSON(200, &ListOrganizationsResponse{Organizations: orgs})
+}
+
+// Get an Organization.
+func (o *Organization) Get(c echo.Context) error {
+	member := request.MembershipFromContext(c)
+	if member == nil {
+		return echo.ErrForbidden
+	}
+
+	org, err := o.orgRepo.Find(context.Background(), member.OrganizationID)
+	if err != nil {
+		return echo.ErrNotFound.SetInternal(errors.Wrap(err, "find organization"))
+	}
+
+	return c.JSON(200, &GetOrganizationResponse{
+		Organization: &MemberOrganization{Organization: org, Role: member.Role},
+	})
+}
+
+// Create an Organization, owned by the authenticated Account.
+func (o *Organization) Create(c echo.Context) error {
+	var req CreateOrganizationRequest
+	if err := request.Parse(c, &req); err != nil {
+		return err
+	}
+
+	acc := request.AuthFromContext(c)
+	if acc == nil {
+		return echo.ErrInternalServerError.SetInternal(errors.New("missing authenticated user"))
+	}
+
+	org, err := o.orgSvc.Create(context.Background(), acc, req.Name)
+	if err != nil {
+		return organizationError(err)
+	}
+
+	return c.JSON(201, &CreateOrganizationResponse{
+		Organization: &MemberOrganization{Organization: org, Role: account.RoleOwner},
+	})
+}
+
+// Update changes the name of an Organization.
+func (o *Organization) Update(c echo.Context) error {
+	member := request.MembershipFromContext(c)
+	if member == nil {
+		return echo.ErrForbidden
+	}
+
+	var req UpdateOrganizationRequest
+	if err := request.Parse(c, &req); err != nil {
+		return err
+	}
+
+	org, err := o.orgRepo.Find(context.Background(), member.OrganizationID)
+	if err != nil {
+		return echo.ErrNotFound.SetInternal(errors.Wrap(err, "find organization"))
+	}
+
+	org.Name = req.Name
+
+	err = org.Validate()
+	if err != nil {
+		return echo.ErrBadRequest.SetInternal(err)
+	}
+
+	org.UpdatedAt = time.Now()
+
+	err = o.orgRepo.Update(context.Background(), org)
+	if err != nil {
+		return errors.Wrap(err, "update organization")
+	}
+
+	return c.JSON(200, &UpdateOrganizationResponse{Organization: org})
+}
+
+// ListMembers lists all the members of an Organization.
+func (o *Organization) ListMembers(c echo.Context) error {
+	member := request.MembershipFromContext(c)
+	if member == nil {
+		return echo.ErrForbidden
+	}
+
+	memberships, err := o.memberRepo.ListByOrganization(context.Background(), member.OrganizationID)
+	if err != nil {
+		return errors.Wrap(err, "list memberships")
+	}
+
+	members := make([]*Member, 0, len(memberships))
+	for _, membership := range memberships {
+		acc, err := o.accRepo.FindByID(context.Background(), membership.AccountID)
+		if err != nil {
+			return errors.Wrapf(err, "find member account %s", membership.AccountID)
+		}
+
+		members = append(members, &Member{
+			AccountID: acc.ID,
+			Email:     acc.Email,
+			Role:      membership.Role,
+			CreatedAt: membership.CreatedAt,
+		})
+	}
+
+	return c.JSON(200, &ListMembersResponse{Members: members})
+}
+
+// UpdateMember changes the Role of a member of an Organization.
+func (o *Organization) UpdateMember(c echo.Context) error {
+	member := request.MembershipFromContext(c)
+	if member == nil {
+		return echo.ErrForbidden
+	}
+
+	var req UpdateMemberRequest
+	if err := request.Parse(c, &req); err != nil {
+		return err
+	}
+
+	target, err := o.findMember(c, member.OrganizationID)
+	if err != nil {
+		return err
+	}
+
+	err = o.orgSvc.ChangeRole(context.Background(), member, target, req.Role)
+	if err != nil {
+		return organizationError(err)
+	}
+
+	return c.JSON(200, &UpdateMemberResponse{Membership: target})
+}
+
+// RemoveMember removes a member from an Organization.
+//
+// Every member can leave an Organization, while removing others requires managing members.
+func (o *Organization) RemoveMember(c echo.Context) error {
+	member := request.MembershipFromContext(c)
+	if member == nil {
+		return echo.ErrForbidden
+	}
+
+	target, err := o.findMember(c, member.OrganizationID)
+	if err != nil {
+		return err
+	}
+
+	err = o.orgSvc.RemoveMember(context.Background(), member, target)
+	if err != nil {
+		return organizationError(err)
+	}
+
+	return c.NoContent(204)
+}
+
+// ListInvitations lists all the invitations to an Organization.
+func (o *Organization) ListInvitations(c echo.Context) error {
+	member := request.MembershipFromContext(c)
+	if member == nil {
+		return echo.ErrForbidden
+	}
+
+	invitations, err := o.inviteRepo.ListByOrganization(context.Background(), member.OrganizationID)
+	if err != nil {
+		return errors.Wrap(err, "list invitations")
+	}
+
+	return c.JSON(200, &ListInvitationsResponse{Invitations: invitations})
+}
+
+// CreateInvitation invites the owner of an email to join an Organization.
+func (o *Organization) CreateInvitation(c echo.Context) error {
+	member := request.MembershipFromContext(c)
+	if member == nil {
+		return echo.ErrForbidden
+	}
+
+	var req CreateInvitationRequest
+	if err := request.Parse(c, &req); err != nil {
+		return err
+	}
+
+	inv, token, err := o.orgSvc.Invite(context.Background(), member, account.NewEmail(req.Email.String()), req.Role)
+	if err != nil {
+		return organizationError(err)
+	}
+
+	request.WithAuditTarget(c, inv.ID.String())
+
+	return c.JSON(201, &CreateInvitationResponse{Invitation: inv, Token: token})
+}
+
+// DeleteInvitation revokes an Invitation to an Organization.
+func (o *Organization) DeleteInvitation(c echo.Context) error {
+	member := request.MembershipFromContext(c)
+	if member == nil {
+		return echo.ErrForbidden
+	}
+
+	inv, err := o.inviteRepo.Find(context.Background(), account.InvitationID(c.Param("invitation_id")))
+	if err != nil {
+		return echo.ErrNotFound.SetInternal(errors.Wrap(err, "find invitation"))
+	}
+	if inv.OrganizationID != member.OrganizationID {
+		return echo.ErrNotFound.
+			SetInternal(errors.Errorf("invitation %s does not belong to organization %s", inv.ID, member.OrganizationID))
+	}
+
+	err = o.inviteRepo.Delete(context.Background(), inv)
+	if err != nil {
+		return errors.Wrap(err, "delete invitation")
+	}
+
+	return c.NoContent(204)
+}
+
+// AcceptInvitation adds the authenticated Account to the Organization it was invited to.
+func (o *Organization) AcceptInvitation(c echo.Context) error {
+	var req AcceptInvitationRequest
+	if err := request.Parse(c, &req); err != nil {
+		return err
+	}
+
+	acc := request.AuthFromContext(c)
+	if acc == nil {
+		return echo.ErrInternalServerError.SetInternal(errors.New("missing authenticated user"))
+	}
+
+	membership, err := o.orgSvc.AcceptInvitation(context.Background(), acc, req.Token)
+	if err != nil {
+		return organizationError(err)
+	}
+
+	return c.JSON(201, &AcceptInvitationResponse{Membership: membership})
+}
+
+// findMember returns the Membership of the Account in the `account_id` path parameter.
+func (o *Organization) findMember(c echo.Context, orgID account.OrganizationID) (*account.Membership, error) {
+	accountID := account.IDFromString(c.Param("account_id"))
+
+	membership, err := o.memberRepo.Find(context.Background(), orgID, accountID)
+	if err != nil {
+		return nil, echo.ErrNotFound.SetInternal(errors.Wrap(err, "find member"))
+	}
+
+	return membership, nil
+}
+
+// organizationError maps the errors of the account.OrganizationService to HTTP errors.
+func organizationError(err error) error {
+	switch errors.Cause(err) {
+	case account.ErrPermissionDenied:
+		return echo.ErrForbidden.SetInternal(err)
+	case account.ErrInvitationNotFound, account.ErrMembershipNotFound:
+		return echo.ErrNotFound.SetInternal(err)
+	case account.ErrAlreadyMember:
+		return echo.NewHTTPError(http.StatusConflict, "account is already a member").SetInternal(err)
+	case account.ErrLastOwner:
+		return echo.NewHTTPError(http.StatusConflict, "organization must keep at least one owner").SetInternal(err)
+	case account.ErrInvalidRole:
+		return echo.ErrBadRequest.SetInternal(err)
+	default:
+		return err
+	}
+}
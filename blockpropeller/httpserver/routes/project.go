@@ -0,0 +1,314 @@
+package routes
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"blockpropeller.dev/blockpropeller/account"
+	"blockpropeller.dev/blockpropeller/httpserver/request"
+	"blockpropeller.dev/blockpropeller/infrastructure"
+	"blockpropeller.dev/blockpropeller/terraform/cloudprovider"
+	"github.com/labstack/echo"
+	"github.com/pkg/errors"
+)
+
+// ListProjectsResponse is a response to the list projects request.
+type ListProjectsResponse struct {
+	Projects []*infrastructure.Project `json:"projects"`
+}
+
+// GetProjectResponse is a response to the get project request.
+type GetProjectResponse struct {
+	Project *infrastructure.Project `json:"project"`
+}
+
+// CreateProjectRequest holds the request payload for the create project endpoint.
+type CreateProjectRequest struct {
+	Name        string `json:"name" form:"name" validate:"required"`
+	Environment string `json:"environment" form:"environment" validate:"required"`
+
+	// DefaultProviderSettingsID and DefaultRegion are used for servers created without their own.
+	DefaultProviderSettingsID infrastructure.ProviderSettingsID `json:"default_provider_id" form:"default_provider_id"`
+	DefaultRegion             string                            `json:"default_region" form:"default_region"`
+
+	// MaxServers limits the number of servers in the project, zero for no limit.
+	MaxServers int `json:"max_servers" form:"max_servers"`
+}
+
+// CreateProjectResponse is a response to the create project request.
+type CreateProjectResponse struct {
+	Project *infrastructure.Project `json:"project"`
+}
+
+// UpdateProjectRequest holds the request payload for the update project endpoint.
+//
+// Empty fields are left unchanged.
+type UpdateProjectRequest struct {
+	Name        string `json:"name" form:"name"`
+	Environment string `json:"environment" form:"environment"`
+
+	DefaultProviderSettingsID infrastructure.ProviderSettingsID `json:"default_provider_id" form:"default_provider_id"`
+	DefaultRegion             string                            `json:"default_region" form:"default_region"`
+
+	MaxServers *int `json:"max_servers" form:"max_servers"`
+}
+
+// UpdateProjectResponse is a response to the update project request.
+type UpdateProjectResponse struct {
+	Project *infrastructure.Project `json:"project"`
+}
+
+// Project REST Resource for organizing infrastructure into projects.
+type Project struct {
+	projectRepo  infrastructure.ProjectRepository
+	settingsRepo infrastructure.ProviderSettingsRepository
+	srvRepo      infrastructure.ServerRepository
+}
+
+// NewProjectRoutes returns a new Project routes instance.
+func NewProjectRoutes(
+	projectRepo infrastructure.ProjectRepository,
+	settingsRepo infrastructure.ProviderSettingsRepository,
+	srvRepo infrastructure.ServerRepository,
+) *Project {
+	return &Project{projectRepo: projectRepo, settingsRepo: settingsRepo, srvRepo: srvRepo}
+}
+
+// LoadProject is a middleware for loading Project into request context,
+// along with the Organization whose permissions an authenticated user is checked against.
+func (p *Project) LoadProject(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		projectID := infrastructure.ProjectID(c.Param("project_id"))
+		project, err := p.projectRepo.Find(context.Background(), projectID)
+		if err != nil {
+			return echo.ErrNotFound.SetInternal(errors.Wrap(err, "find project"))
+		}
+
+		request.WithProject(c, project)
+		request.WithOrganization(c, project.OrganizationID)
+
+		return next(c)
+	}
+}
+
+// List all Projects for an Organization.
+//
+// Organizations always have at least their default Project.
+func (p *Project) List(c echo.Context) error {
+	member := request.MembershipFromContext(c)
+	if member == nil {
+		return echo.ErrForbidden
+	}
+
+	_, err := infrastructure.DefaultProject(context.Background(), p.projectRepo, member.OrganizationID, member.AccountID)
+	if err != nil {
+		return errors.Wrap(err, "ensure default project")
+	}
+
+	projects, err := p.projectRepo.List(context.Background(), member.OrganizationID)
+	if err != nil {
+		return errors.Wrap(err, "list projects")
+	}
+
+	return c.JSON(200, &ListProjectsResponse{
+		Projects: projects,
+	})
+}
+
+// Get a Project.
+func (p *Project) Get(c echo.Context) error {
+	project := request.ProjectFromContext(c)
+	if project == nil {
+		return echo.ErrNotFound.SetInternal(errors.New("project not found in context"))
+	}
+
+	return c.JSON(200, &GetProjectResponse{Project: project})
+}
+
+// Create a Project.
+func (p *Project) Create(c echo.Context) error {
+	var req CreateProjectRequest
+	if err := request.Parse(c, &req); err != nil {
+		return err
+	}
+
+	member := request.MembershipFromContext(c)
+	if member == nil {
+		return echo.ErrInternalServerError.SetInternal(errors.New("missing organization membership"))
+	}
+
+	project := infrastructure.NewProject(member.OrganizationID, member.AccountID, req.Name, req.Environment)
+	project.DefaultProviderSettingsID = req.DefaultProviderSettingsID
+	project.DefaultRegion = req.DefaultRegion
+	project.MaxServers = req.MaxServers
+
+	err := p.validateProject(project)
+	if err != nil {
+		return err
+	}
+
+	err = p.projectRepo.Create(context.Background(), project)
+	if err != nil {
+		return errors.Wrap(err, "create project")
+	}
+
+	return c.JSON(201, &CreateProjectResponse{Project: project})
+}
+
+// Update changes the name, environment, defaults and quota of a Project.
+func (p *Project) Update(c echo.Context) error {
+	project := request.ProjectFromContext(c)
+	if project == nil {
+		return echo.ErrNotFound.SetInternal(errors.New("project not found in context"))
+	}
+
+	var req UpdateProjectRequest
+	if err := request.Parse(c, &req); err != nil {
+		return err
+	}
+
+	if req.Name != "" {
+		project.Name = req.Name
+	}
+	if req.Environment != "" {
+		project.Environment = req.Environment
+	}
+	if req.DefaultProviderSettingsID != infrastructure.NilProviderSettingsID {
+		project.DefaultProviderSettingsID = req.DefaultProviderSettingsID
+	}
+	if req.DefaultRegion != "" {
+		project.DefaultRegion = req.DefaultRegion
+	}
+	if req.MaxServers != nil {
+		project.MaxServers = *req.MaxServers
+	}
+
+	err := p.validateProject(project)
+	if err != nil {
+		return err
+	}
+
+	project.UpdatedAt = time.Now()
+
+	err = p.projectRepo.Update(context.Background(), project)
+	if err != nil {
+		return errors.Wrap(err, "update project")
+	}
+
+	return c.JSON(200, &UpdateProjectResponse{Project: project})
+}
+
+// Delete a Project.
+//
+// Only empty Projects can be deleted, and the default Project of an Organization is always kept.
+func (p *Project) Delete(c echo.Context) error {
+	project := request.ProjectFromContext(c)
+	if project == nil {
+		return echo.ErrNotFound.SetInternal(errors.New("project not found in context"))
+	}
+
+	if project.IsDefault {
+		return echo.NewHTTPError(http.StatusConflict, "default project cannot be deleted").
+			SetInternal(errors.Errorf("delete default project %s", project.ID))
+	}
+
+	servers, err := p.srvRepo.ListByProject(context.Background(), project.ID)
+	if err != nil {
+		return errors.Wrap(err, "list project servers")
+	}
+	settings, err := p.settingsRepo.ListByProject(context.Background(), project.ID)
+	if err != nil {
+		return errors.Wrap(err, "list project provider settings")
+	}
+	if len(servers) > 0 || len(settings) > 0 {
+		return echo.NewHTTPError(http.StatusConflict, "project is not empty").
+			SetInternal(errors.Errorf("project %s holds %d servers and %d provider settings",
+				project.ID, len(servers), len(settings)))
+	}
+
+	err = p.projectRepo.Delete(context.Background(), project)
+	if err != nil {
+		return errors.Wrap(err, "delete project")
+	}
+
+	return c.NoContent(204)
+}
+
+// validateProject checks that the Project is well formed,
+// and that its defaults can be used for provisioning servers within it.
+func (p *Project) validateProject(project *infrastructure.Project) error {
+	err := project.Validate()
+	if err != nil {
+		return echo.ErrBadRequest.SetInternal(err)
+	}
+
+	if project.DefaultProviderSettingsID == infrastructure.NilProviderSettingsID {
+		if project.DefaultRegion != "" {
+			return echo.ErrBadRequest.SetInternal(errors.New("default region requires default provider settings"))
+		}
+
+		return nil
+	}
+
+	settings, err := p.settingsRepo.Find(context.Background(), project.DefaultProviderSettingsID)
+	if err != nil {
+		return echo.ErrBadRequest.SetInternal(errors.Wrap(err, "find default provider settings"))
+	}
+	if settings.OrganizationID != project.OrganizationID || settings.ProjectID != project.ID {
+		return echo.ErrBadRequest.
+			SetInternal(errors.Errorf("provider settings %s do not belong to project %s", settings.ID, project.ID))
+	}
+
+	if project.DefaultRegion == "" {
+		return nil
+	}
+	if settings.Type == infrastructure.ProviderBYO {
+		return echo.ErrBadRequest.SetInternal(errors.New("existing hosts have no regions"))
+	}
+
+	provider, err := cloudprovider.GetProvider(settings.Type)
+	if err != nil {
+		return errors.Wrap(err, "get provider")
+	}
+
+	_, err = provider.Catalog().FindRegion(project.DefaultRegion)
+	if err != nil {
+		return echo.ErrBadRequest.SetInternal(err)
+	}
+
+	return nil
+}
+
+// findProject returns the Project with the provided ProjectID, if it belongs to the Organization of the member.
+func findProject(
+	projectRepo infrastructure.ProjectRepository,
+	member *account.Membership,
+	id infrastructure.ProjectID,
+) (*infrastructure.Project, error) {
+	project, err := projectRepo.Find(context.Background(), id)
+	if err != nil {
+		return nil, echo.ErrNotFound.SetInternal(errors.Wrap(err, "find project"))
+	}
+	if project.OrganizationID != member.OrganizationID {
+		return nil, echo.ErrForbidden.
+			SetInternal(errors.Errorf("unauthorized project access: organization %s, project %s",
+				member.OrganizationID, id))
+	}
+
+	return project, nil
+}
+
+// findProjectOrDefault returns the requested Project,
+// or the default Project of the Organization of the member if none is requested.
+func findProjectOrDefault(
+	projectRepo infrastructure.ProjectRepository,
+	member *account.Membership,
+	id infrastructure.ProjectID,
+) (*infrastructure.Project, error) {
+	if id == infrastructure.NilProjectID {
+		return infrastructure.DefaultProject(context.Background(), projectRepo, member.OrganizationID, member.AccountID)
+	}
+
+	return findProject(projectRepo, member, id)
+}
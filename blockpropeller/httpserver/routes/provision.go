@@ -2,11 +2,14 @@ package routes
 
 import (
 	"context"
+	"net/http"
 
+	"blockpropeller.dev/blockpropeller/account"
 	"blockpropeller.dev/blockpropeller/binance"
 	"blockpropeller.dev/blockpropeller/httpserver/request"
 	"blockpropeller.dev/blockpropeller/infrastructure"
 	"blockpropeller.dev/blockpropeller/provision"
+	"blockpropeller.dev/blockpropeller/terraform/cloudprovider"
 	"github.com/blang/semver"
 	"github.com/jinzhu/gorm"
 	"github.com/labstack/echo"
@@ -24,12 +27,33 @@ type GetJobResponse struct {
 }
 
 // CreateJobRequest holds the request payload for the create job endpoint.
+//
+// Jobs without provider settings use the default provider settings and region of the project.
 type CreateJobRequest struct {
-	ProviderSettingsID infrastructure.ProviderSettingsID `json:"provider_id" form:"provider_id" validate:"required"`
+	// ProjectID the server is organized under, the project of the provider settings if empty.
+	ProjectID          infrastructure.ProjectID          `json:"project_id" form:"project_id"`
+	ProviderSettingsID infrastructure.ProviderSettingsID `json:"provider_id" form:"provider_id"`
 
 	NodeNetwork binance.Network  `json:"node_network" form:"node_network" validate:"required,valid"`
 	NodeType    binance.NodeType `json:"node_type" form:"node_type" validate:"required,valid"`
 	NodeVersion string           `json:"node_version" form:"node_version" validate:"required"`
+
+	Region       string `json:"region" form:"region"`
+	InstanceType string `json:"instance_type" form:"instance_type"`
+
+	AllowedIPs []string `json:"allowed_ips" form:"allowed_ips"`
+
+	// Labels of the server and the node deployment, which are applied as tags of the server infrastructure.
+	Labels           infrastructure.Labels `json:"labels" form:"labels"`
+	DeploymentLabels infrastructure.Labels `json:"deployment_labels" form:"deployment_labels"`
+
+	// ReservedIPID attaches a reserved IP to the server, which must not be attached to another server.
+	ReservedIPID infrastructure.ReservedIPID `json:"reserved_ip_id" form:"reserved_ip_id"`
+	// VolumeSnapshotID creates the data volume of the server from a snapshot, instead of syncing the node from scratch.
+	VolumeSnapshotID infrastructure.VolumeSnapshotID `json:"volume_snapshot_id" form:"volume_snapshot_id"`
+	// DNSZone and DNSName give the node a hostname inside a user-owned DNS zone.
+	DNSZone string `json:"dns_zone" form:"dns_zone"`
+	DNSName string `json:"dns_name" form:"dns_name"`
 }
 
 // CreateJobResponse is a response to the create job request.
@@ -39,26 +63,41 @@ type CreateJobResponse struct {
 
 // Provision routes define ways to provision infrastructure via BlockPropeller.
 type Provision struct {
-	jobScheduler *provision.JobScheduler
+	jobScheduler  *provision.JobScheduler
+	ipProvisioner *provision.ReservedIPProvisioner
 
 	jobRepo      provision.JobRepository
 	settingsRepo infrastructure.ProviderSettingsRepository
+	projectRepo  infrastructure.ProjectRepository
+	ipRepo       infrastructure.ReservedIPRepository
+	snapRepo     infrastructure.VolumeSnapshotRepository
 }
 
 // NewProvisionRoutes returns a new Provision routes instance.
-func NewProvisionRoutes(jobScheduler *provision.JobScheduler, jobRepo provision.JobRepository, settingsRepo infrastructure.ProviderSettingsRepository) *Provision {
-	return &Provision{jobScheduler: jobScheduler, jobRepo: jobRepo, settingsRepo: settingsRepo}
+func NewProvisionRoutes(
+	jobScheduler *provision.JobScheduler,
+	ipProvisioner *provision.ReservedIPProvisioner,
+	jobRepo provision.JobRepository,
+	settingsRepo infrastructure.ProviderSettingsRepository,
+	projectRepo infrastructure.ProjectRepository,
+	ipRepo infrastructure.ReservedIPRepository,
+	snapRepo infrastructure.VolumeSnapshotRepository,
+) *Provision {
+	return &Provision{
+		jobScheduler:  jobScheduler,
+		ipProvisioner: ipProvisioner,
+		jobRepo:       jobRepo,
+		settingsRepo:  settingsRepo,
+		projectRepo:   projectRepo,
+		ipRepo:        ipRepo,
+		snapRepo:      snapRepo,
+	}
 }
 
-// LoadJob is a middleware for loading Jobs into request context
-// as well as checking for correct permissions of an authenticated user.
+// LoadJob is a middleware for loading Jobs into request context,
+// along with the Organization whose permissions an authenticated user is checked against.
 func (p *Provision) LoadJob(next echo.HandlerFunc) echo.HandlerFunc {
 	return func(c echo.Context) error {
-		authAcc := request.AuthFromContext(c)
-		if authAcc == nil {
-			return echo.ErrForbidden.SetInternal(errors.New("missing authenticated account"))
-		}
-
 		jobID := provision.JobID(c.Param("job_id"))
 		if jobID.String() == "" {
 			return next(c)
@@ -69,28 +108,38 @@ func (p *Provision) LoadJob(next echo.HandlerFunc) echo.HandlerFunc {
 			return echo.ErrInternalServerError.SetInternal(err)
 		}
 
-		if authAcc.ID.String() != job.AccountID.String() {
-			return echo.ErrForbidden.
-				SetInternal(errors.Errorf("unauthorized job access: authenticated %s, job %s",
-					authAcc.ID, jobID))
-		}
-
 		request.WithJob(c, job)
+		request.WithOrganization(c, job.OrganizationID)
 
 		return next(c)
 	}
 }
 
-// ListJobs all Jobs for an Account.
+// ListJobs all Jobs for an Organization, optionally only the ones within the Project in the `project_id` query parameter.
 func (p *Provision) ListJobs(c echo.Context) error {
-	acc := request.AuthFromContext(c)
-	if acc == nil {
+	member := request.MembershipFromContext(c)
+	if member == nil {
 		return echo.ErrForbidden
 	}
 
-	jobs, err := p.jobRepo.List(context.Background(), acc.ID)
-	if err != nil {
-		return errors.Wrap(err, "list jobs")
+	var jobs []*provision.Job
+	projectID := infrastructure.ProjectID(c.QueryParam("project_id"))
+	if projectID != infrastructure.NilProjectID {
+		project, err := findProject(p.projectRepo, member, projectID)
+		if err != nil {
+			return err
+		}
+
+		jobs, err = p.jobRepo.ListByProject(context.Background(), project.ID)
+		if err != nil {
+			return errors.Wrap(err, "list project jobs")
+		}
+	} else {
+		var err error
+		jobs, err = p.jobRepo.ListByOrganization(context.Background(), member.OrganizationID)
+		if err != nil {
+			return errors.Wrap(err, "list jobs")
+		}
 	}
 
 	return c.JSON(200, &ListJobsResponse{
@@ -115,22 +164,65 @@ func (p *Provision) CreateJob(c echo.Context) error {
 		return err
 	}
 
-	acc := request.AuthFromContext(c)
-	if acc == nil {
-		return echo.ErrInternalServerError.SetInternal(errors.New("missing authenticated user"))
+	member := request.MembershipFromContext(c)
+	if member == nil {
+		return echo.ErrInternalServerError.SetInternal(errors.New("missing organization membership"))
+	}
+
+	var (
+		project *infrastructure.Project
+		err     error
+	)
+	if req.ProjectID != infrastructure.NilProjectID {
+		project, err = findProject(p.projectRepo, member, req.ProjectID)
+		if err != nil {
+			return err
+		}
+	}
+
+	settingsID := req.ProviderSettingsID
+	if settingsID == infrastructure.NilProviderSettingsID {
+		if project == nil {
+			project, err = infrastructure.DefaultProject(context.Background(), p.projectRepo, member.OrganizationID, member.AccountID)
+			if err != nil {
+				return errors.Wrap(err, "find default project")
+			}
+		}
+		if project.DefaultProviderSettingsID == infrastructure.NilProviderSettingsID {
+			return echo.ErrBadRequest.
+				SetInternal(errors.Errorf("missing provider settings: project %s has no default", project.ID))
+		}
+
+		settingsID = project.DefaultProviderSettingsID
 	}
 
-	settings, err := p.settingsRepo.Find(context.Background(), req.ProviderSettingsID)
+	settings, err := p.settingsRepo.Find(context.Background(), settingsID)
 	if errors.Cause(err) == gorm.ErrRecordNotFound {
 		return echo.ErrBadRequest.SetInternal(err)
 	}
 	if err != nil {
 		return errors.Wrap(err, "find provider settings")
 	}
-	if settings.AccountID.String() != acc.ID.String() {
+	if settings.OrganizationID != member.OrganizationID {
 		return echo.ErrForbidden.
-			SetInternal(errors.Errorf("unauthorized job access: authenticated %s, provider settings %s",
-				acc.ID, req.ProviderSettingsID))
+			SetInternal(errors.Errorf("unauthorized job access: organization %s, provider settings %s",
+				member.OrganizationID, settingsID))
+	}
+
+	if project == nil {
+		project, err = findProjectOrDefault(p.projectRepo, member, settings.ProjectID)
+		if err != nil {
+			return err
+		}
+	}
+	if settings.ProjectID != project.ID {
+		return echo.ErrBadRequest.
+			SetInternal(errors.Errorf("provider settings %s do not belong to project %s", settings.ID, project.ID))
+	}
+
+	region := req.Region
+	if region == "" && settings.ID == project.DefaultProviderSettingsID {
+		region = project.DefaultRegion
 	}
 
 	nodeVersion, err := semver.Parse(req.NodeVersion)
@@ -140,20 +232,63 @@ func (p *Provision) CreateJob(c echo.Context) error {
 
 	deployment := binance.NewNodeDeployment(req.NodeNetwork, req.NodeType, nodeVersion)
 
+	err = deployment.SetLabels(req.DeploymentLabels)
+	if err != nil {
+		return echo.ErrBadRequest.SetInternal(err)
+	}
+
+	if req.DNSZone != "" || req.DNSName != "" {
+		err = deployment.SetHostname(req.DNSZone, req.DNSName)
+		if err != nil {
+			return echo.ErrBadRequest.SetInternal(err)
+		}
+	}
+
+	var reservedIP *infrastructure.ReservedIP
+	if req.ReservedIPID != infrastructure.NilReservedIPID {
+		reservedIP, err = p.findAvailableReservedIP(member.OrganizationID, settings, req.ReservedIPID)
+		if err != nil {
+			return err
+		}
+	}
+
+	var snapshot *infrastructure.VolumeSnapshot
+	if req.VolumeSnapshotID != infrastructure.NilVolumeSnapshotID {
+		snapshot, err = p.findVolumeSnapshot(member.OrganizationID, settings, req.VolumeSnapshotID)
+		if err != nil {
+			return err
+		}
+	}
+
 	size := infrastructure.ServerSizeTest
 	if req.NodeType == binance.TypeFullNode {
 		size = infrastructure.ServerSizeProd
 	}
 
-	srv, err := infrastructure.NewServerBuilder(acc.ID).
+	srv, err := infrastructure.NewServerBuilder(member.AccountID).
+		Project(project).
 		Provider(settings.Type).
 		Size(size).
+		Region(region).
+		InstanceType(req.InstanceType).
+		ReservedIP(reservedIP).
+		VolumeSnapshot(snapshot).
 		Build()
 	if err != nil {
 		return errors.Wrap(err, "build server")
 	}
 
-	job, err := provision.NewJobBuilder(acc.ID).
+	err = srv.SetAllowedIPs(req.AllowedIPs)
+	if err != nil {
+		return echo.ErrBadRequest.SetInternal(err)
+	}
+
+	err = srv.SetLabels(req.Labels)
+	if err != nil {
+		return echo.ErrBadRequest.SetInternal(err)
+	}
+
+	job, err := provision.NewJobBuilder(member.AccountID).
 		Provider(settings).
 		Server(srv).
 		Deployment(deployment).
@@ -162,10 +297,102 @@ func (p *Provision) CreateJob(c echo.Context) error {
 		return errors.Wrap(err, "build job")
 	}
 
-	err = p.jobScheduler.Schedule(context.Background(), job)
+	// Servers are validated once the job attaches the deployment,
+	// as deployment hostnames depend on provider support for DNS records.
+	err = validateServer(settings, srv)
+	if err != nil {
+		return echo.ErrBadRequest.SetInternal(errors.Wrap(err, "validate server"))
+	}
+
+	err = p.jobScheduler.ScheduleWithinQuota(context.Background(), job, project)
+	if errors.Cause(err) == infrastructure.ErrProjectQuotaExceeded {
+		return echo.NewHTTPError(http.StatusForbidden, "project server quota exceeded").SetInternal(err)
+	}
 	if err != nil {
 		return errors.Wrap(err, "create job")
 	}
 
 	return c.JSON(201, &CreateJobResponse{Job: job})
 }
+
+// findAvailableReservedIP returns the requested ReservedIP if it can be attached to a new server.
+func (p *Provision) findAvailableReservedIP(
+	orgID account.OrganizationID,
+	settings *infrastructure.ProviderSettings,
+	id infrastructure.ReservedIPID,
+) (*infrastructure.ReservedIP, error) {
+	ip, err := p.ipRepo.Find(context.Background(), id)
+	if err != nil {
+		return nil, echo.ErrBadRequest.SetInternal(errors.Wrap(err, "find reserved ip"))
+	}
+	if ip.OrganizationID != orgID {
+		return nil, echo.ErrForbidden.
+			SetInternal(errors.Errorf("unauthorized reserved ip access: organization %s, reserved ip %s",
+				orgID, id))
+	}
+	if ip.ProviderSettingsID != settings.ID {
+		return nil, echo.ErrBadRequest.
+			SetInternal(errors.Errorf("reserved ip %s belongs to other provider settings", id))
+	}
+
+	srv, err := p.ipProvisioner.AttachedServer(context.Background(), ip)
+	if err != nil {
+		return nil, errors.Wrap(err, "find attached server")
+	}
+	if srv != nil {
+		return nil, echo.NewHTTPError(http.StatusConflict, "reserved ip is attached to a server").
+			SetInternal(errors.Wrapf(provision.ErrReservedIPInUse, "attached to server %s", srv.ID))
+	}
+
+	return ip, nil
+}
+
+// findVolumeSnapshot returns the requested VolumeSnapshot if it belongs to the Organization and provider settings.
+//
+// Snapshots are validated to be available together with the rest of the server.
+func (p *Provision) findVolumeSnapshot(
+	orgID account.OrganizationID,
+	settings *infrastructure.ProviderSettings,
+	id infrastructure.VolumeSnapshotID,
+) (*infrastructure.VolumeSnapshot, error) {
+	snap, err := p.snapRepo.Find(context.Background(), id)
+	if err != nil {
+		return nil, echo.ErrBadRequest.SetInternal(errors.Wrap(err, "find volume snapshot"))
+	}
+	if snap.OrganizationID != orgID {
+		return nil, echo.ErrForbidden.
+			SetInternal(errors.Errorf("unauthorized volume snapshot access: organization %s, volume snapshot %s",
+				orgID, id))
+	}
+	if snap.ProviderSettingsID != settings.ID {
+		return nil, echo.ErrBadRequest.
+			SetInternal(errors.Errorf("volume snapshot %s belongs to other provider settings", id))
+	}
+
+	return snap, nil
+}
+
+// validateServer checks that the Server can be provisioned with the provider.
+func validateServer(settings *infrastructure.ProviderSettings, srv *infrastructure.Server) error {
+	// Existing hosts are used as they are.
+	if settings.Type == infrastructure.ProviderBYO {
+		if srv.ReservedIPID != infrastructure.NilReservedIPID {
+			return cloudprovider.ErrReservedIPsNotSupported
+		}
+		if len(cloudprovider.ServerDNSRecords(srv)) > 0 {
+			return cloudprovider.ErrDNSRecordsNotSupported
+		}
+		if srv.VolumeSnapshotID != infrastructure.NilVolumeSnapshotID {
+			return cloudprovider.ErrVolumeSnapshotsNotSupported
+		}
+
+		return nil
+	}
+
+	provider, err := cloudprovider.GetProvider(settings.Type)
+	if err != nil {
+		return err
+	}
+
+	return provider.Catalog().Validate(srv)
+}
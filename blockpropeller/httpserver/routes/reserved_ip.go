@@ -0,0 +1,170 @@
+package routes
+
+import (
+	"context"
+	"net/http"
+
+	"blockpropeller.dev/blockpropeller/httpserver/request"
+	"blockpropeller.dev/blockpropeller/infrastructure"
+	"blockpropeller.dev/blockpropeller/provision"
+	"blockpropeller.dev/blockpropeller/terraform/cloudprovider"
+	"github.com/labstack/echo"
+	"github.com/pkg/errors"
+)
+
+// ListReservedIPsResponse is a response to the list reserved IPs request.
+type ListReservedIPsResponse struct {
+	ReservedIPs []*infrastructure.ReservedIP `json:"reserved_ips"`
+}
+
+// GetReservedIPResponse is a response to the get reserved IP request.
+type GetReservedIPResponse struct {
+	ReservedIP *infrastructure.ReservedIP `json:"reserved_ip"`
+}
+
+// CreateReservedIPRequest is a request for allocating a new reserved IP.
+//
+// Reserved IPs without a region are allocated in the default region of the provider.
+type CreateReservedIPRequest struct {
+	ProviderSettingsID infrastructure.ProviderSettingsID `json:"provider_id" form:"provider_id" validate:"required"`
+	Region             string                            `json:"region" form:"region"`
+}
+
+// CreateReservedIPResponse is a response to the create reserved IP request.
+type CreateReservedIPResponse struct {
+	ReservedIP *infrastructure.ReservedIP `json:"reserved_ip"`
+}
+
+// ReservedIP REST Resource for managing reserved IP addresses.
+type ReservedIP struct {
+	ipProvisioner *provision.ReservedIPProvisioner
+
+	ipRepo       infrastructure.ReservedIPRepository
+	settingsRepo infrastructure.ProviderSettingsRepository
+}
+
+// NewReservedIPRoutes returns a new ReservedIP routes instance.
+func NewReservedIPRoutes(
+	ipProvisioner *provision.ReservedIPProvisioner,
+	ipRepo infrastructure.ReservedIPRepository,
+	settingsRepo infrastructure.ProviderSettingsRepository,
+) *ReservedIP {
+	return &ReservedIP{ipProvisioner: ipProvisioner, ipRepo: ipRepo, settingsRepo: settingsRepo}
+}
+
+// LoadReservedIP is a middleware for loading ReservedIP into request context,
+// along with the Organization whose permissions an authenticated user is checked against.
+func (r *ReservedIP) LoadReservedIP(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ipID := infrastructure.ReservedIPID(c.Param("reserved_ip_id"))
+		ip, err := r.ipRepo.Find(context.Background(), ipID)
+		if err != nil {
+			return echo.ErrNotFound.SetInternal(errors.Wrap(err, "find reserved ip"))
+		}
+
+		request.WithReservedIP(c, ip)
+		request.WithOrganization(c, ip.OrganizationID)
+
+		return next(c)
+	}
+}
+
+// List all ReservedIPs for an Organization.
+func (r *ReservedIP) List(c echo.Context) error {
+	member := request.MembershipFromContext(c)
+	if member == nil {
+		return echo.ErrForbidden
+	}
+
+	ips, err := r.ipRepo.ListByOrganization(context.Background(), member.OrganizationID)
+	if err != nil {
+		return errors.Wrap(err, "list reserved ips")
+	}
+
+	return c.JSON(200, &ListReservedIPsResponse{
+		ReservedIPs: ips,
+	})
+}
+
+// Get a ReservedIP.
+func (r *ReservedIP) Get(c echo.Context) error {
+	ip := request.ReservedIPFromContext(c)
+	if ip == nil {
+		return echo.ErrNotFound.SetInternal(errors.New("reserved ip not found in context"))
+	}
+
+	return c.JSON(200, &GetReservedIPResponse{ReservedIP: ip})
+}
+
+// Create allocates a new ReservedIP with the provider.
+func (r *ReservedIP) Create(c echo.Context) error {
+	var req CreateReservedIPRequest
+	if err := request.Parse(c, &req); err != nil {
+		return err
+	}
+
+	member := request.MembershipFromContext(c)
+	if member == nil {
+		return echo.ErrInternalServerError.SetInternal(errors.New("missing organization membership"))
+	}
+
+	settings, err := r.settingsRepo.Find(context.Background(), req.ProviderSettingsID)
+	if err != nil {
+		return echo.ErrBadRequest.SetInternal(errors.Wrap(err, "find provider settings"))
+	}
+	if settings.OrganizationID != member.OrganizationID {
+		return echo.ErrForbidden.
+			SetInternal(errors.Errorf("unauthorized provider settings access: organization %s, provider settings %s",
+				member.OrganizationID, req.ProviderSettingsID))
+	}
+
+	provider, err := cloudprovider.GetProvider(settings.Type)
+	if err != nil {
+		return echo.ErrBadRequest.SetInternal(err)
+	}
+
+	catalog := provider.Catalog()
+	if !catalog.ReservedIPs {
+		return echo.ErrBadRequest.SetInternal(cloudprovider.ErrReservedIPsNotSupported)
+	}
+
+	region := req.Region
+	if region == "" {
+		region = catalog.DefaultRegion
+	}
+
+	_, err = catalog.FindRegion(region)
+	if err != nil {
+		return echo.ErrBadRequest.SetInternal(err)
+	}
+
+	ip := infrastructure.NewReservedIP(member.AccountID, settings, region)
+	request.WithAuditTarget(c, ip.ID.String())
+
+	err = r.ipProvisioner.Provision(context.Background(), settings, ip)
+	if err != nil {
+		return errors.Wrap(err, "provision reserved ip")
+	}
+
+	return c.JSON(201, &CreateReservedIPResponse{ReservedIP: ip})
+}
+
+// Delete releases a ReservedIP back to the provider.
+//
+// Reserved IPs attached to a Server cannot be deleted.
+func (r *ReservedIP) Delete(c echo.Context) error {
+	ip := request.ReservedIPFromContext(c)
+	if ip == nil {
+		return echo.ErrNotFound.SetInternal(errors.New("reserved ip not found in context"))
+	}
+
+	err := r.ipProvisioner.Release(context.Background(), ip)
+	if errors.Cause(err) == provision.ErrReservedIPInUse {
+		return echo.NewHTTPError(http.StatusConflict, "reserved ip is attached to a server").SetInternal(err)
+	}
+	if err != nil {
+		return errors.Wrap(err, "release reserved ip")
+	}
+
+	return c.NoContent(204)
+}
@@ -2,10 +2,12 @@ package routes
 
 import (
 	"context"
+	"net/http"
 
 	"blockpropeller.dev/blockpropeller/httpserver/request"
 	"blockpropeller.dev/blockpropeller/infrastructure"
 	"blockpropeller.dev/blockpropeller/provision"
+	"blockpropeller.dev/blockpropeller/terraform/cloudprovider"
 	"github.com/labstack/echo"
 	"github.com/pkg/errors"
 )
@@ -25,59 +27,118 @@ type AddAuthorizedKeyRequest struct {
 	PublicKey string `json:"public_key" form:"public_key" validate:"required"`
 }
 
+// UpdateFirewallRequest is a request for replacing the IP allow-list of a server firewall.
+type UpdateFirewallRequest struct {
+	AllowedIPs []string `json:"allowed_ips" form:"allowed_ips"`
+}
+
+// UpdateLabelsRequest is a request for replacing the labels of a server or a deployment.
+type UpdateLabelsRequest struct {
+	Labels infrastructure.Labels `json:"labels" form:"labels"`
+}
+
+// UpdateDiskThresholdsRequest is a request for configuring the disk thresholds of a server.
+type UpdateDiskThresholdsRequest struct {
+	WarnPercent   int `json:"warn_percent" form:"warn_percent"`
+	ExpandPercent int `json:"expand_percent" form:"expand_percent"`
+	ExpandBy      int `json:"expand_by_gb" form:"expand_by_gb"`
+}
+
+// UpdateSnapshotPolicyRequest is a request for configuring scheduled snapshots of the data volume of a server.
+type UpdateSnapshotPolicyRequest struct {
+	IntervalHours int `json:"interval_hours" form:"interval_hours"`
+	Retain        int `json:"retain" form:"retain"`
+}
+
+// ResizeVolumeRequest is a request for growing the data volume of a server.
+type ResizeVolumeRequest struct {
+	Size int `json:"size_gb" form:"size_gb" validate:"required"`
+}
+
 // Server REST Resource for accessing server information.
 type Server struct {
+	srvProvisioner  *provision.ServerProvisioner
 	srvDestroyer    *provision.ServerDestroyer
 	deplProvisioner *provision.DeploymentProvisioner
+	jobScheduler    *provision.JobScheduler
 
-	srvRepo infrastructure.ServerRepository
+	srvRepo      infrastructure.ServerRepository
+	settingsRepo infrastructure.ProviderSettingsRepository
+	projectRepo  infrastructure.ProjectRepository
 }
 
 // NewServerRoutes returns a new Server routes instance.
-func NewServerRoutes(srvDestroyer *provision.ServerDestroyer, deplProvisioner *provision.DeploymentProvisioner, srvRepo infrastructure.ServerRepository) *Server {
-	return &Server{srvDestroyer: srvDestroyer, deplProvisioner: deplProvisioner, srvRepo: srvRepo}
+func NewServerRoutes(
+	srvProvisioner *provision.ServerProvisioner,
+	srvDestroyer *provision.ServerDestroyer,
+	deplProvisioner *provision.DeploymentProvisioner,
+	jobScheduler *provision.JobScheduler,
+	srvRepo infrastructure.ServerRepository,
+	settingsRepo infrastructure.ProviderSettingsRepository,
+	projectRepo infrastructure.ProjectRepository,
+) *Server {
+	return &Server{
+		srvProvisioner:  srvProvisioner,
+		srvDestroyer:    srvDestroyer,
+		deplProvisioner: deplProvisioner,
+		jobScheduler:    jobScheduler,
+		srvRepo:         srvRepo,
+		settingsRepo:    settingsRepo,
+		projectRepo:     projectRepo,
+	}
 }
 
-// LoadServer is a middleware for loading Server into request context
-// as well as checking for correct permissions of an authenticated user.
+// LoadServer is a middleware for loading Server into request context,
+// along with the Organization whose permissions an authenticated user is checked against.
 func (s *Server) LoadServer(next echo.HandlerFunc) echo.HandlerFunc {
 	return func(c echo.Context) error {
-		authAcc := request.AuthFromContext(c)
-		if authAcc == nil {
-			return echo.ErrForbidden.SetInternal(errors.New("missing authenticated account"))
-		}
-
 		srvID := infrastructure.ServerID(c.Param("server_id"))
 		srv, err := s.srvRepo.Find(context.Background(), srvID)
 		if err != nil {
 			return echo.ErrInternalServerError.SetInternal(err)
 		}
-		if authAcc.ID != srv.AccountID {
-			return echo.ErrForbidden.
-				SetInternal(errors.Errorf("unauthorized server access: authenticated %s, server %s",
-					authAcc.ID, srvID))
-		}
 
 		request.WithServer(c, srv)
+		request.WithOrganization(c, srv.OrganizationID)
 
 		return next(c)
 	}
 }
 
-// List all Servers for an Account.
+// List all Servers for an Organization, optionally only the ones within the Project in the `project_id` query parameter
+// and matching the label selector in the `selector` query parameter.
 func (s *Server) List(c echo.Context) error {
-	acc := request.AuthFromContext(c)
-	if acc == nil {
+	member := request.MembershipFromContext(c)
+	if member == nil {
 		return echo.ErrForbidden
 	}
 
-	servers, err := s.srvRepo.List(context.Background(), acc.ID)
+	selector, err := infrastructure.ParseLabelSelector(c.QueryParam("selector"))
 	if err != nil {
-		return errors.Wrap(err, "list servers")
+		return echo.ErrBadRequest.SetInternal(err)
+	}
+
+	var servers []*infrastructure.Server
+	projectID := infrastructure.ProjectID(c.QueryParam("project_id"))
+	if projectID != infrastructure.NilProjectID {
+		project, err := findProject(s.projectRepo, member, projectID)
+		if err != nil {
+			return err
+		}
+
+		servers, err = s.srvRepo.ListByProject(context.Background(), project.ID)
+		if err != nil {
+			return errors.Wrap(err, "list project servers")
+		}
+	} else {
+		servers, err = s.srvRepo.ListByOrganization(context.Background(), member.OrganizationID)
+		if err != nil {
+			return errors.Wrap(err, "list servers")
+		}
 	}
 
 	return c.JSON(200, &ListServersResponse{
-		Servers: servers,
+		Servers: selector.FilterServers(servers),
 	})
 }
 
@@ -111,6 +172,209 @@ func (s *Server) AddAuthorizedKey(c echo.Context) error {
 	return c.NoContent(201)
 }
 
+// UpdateFirewall schedules a Job replacing the IP allow-list of a Server firewall.
+func (s *Server) UpdateFirewall(c echo.Context) error {
+	srv := request.ServerFromContext(c)
+	if srv == nil {
+		return echo.ErrNotFound.SetInternal(errors.New("server not found in context"))
+	}
+
+	var req UpdateFirewallRequest
+	if err := request.Parse(c, &req); err != nil {
+		return err
+	}
+
+	allowedIPs, err := infrastructure.ParseAllowedIPs(req.AllowedIPs)
+	if err != nil {
+		return echo.ErrBadRequest.SetInternal(err)
+	}
+
+	if srv.Provider == infrastructure.ProviderBYO || srv.WorkspaceSnapshot == nil {
+		return echo.ErrBadRequest.SetInternal(provision.ErrServerNotManaged)
+	}
+
+	settings, err := s.settingsRepo.Find(context.Background(), srv.ProviderSettingsID)
+	if err != nil {
+		return errors.Wrap(err, "find provider settings")
+	}
+
+	job, err := s.jobScheduler.ScheduleFirewallUpdate(context.Background(), settings, srv, allowedIPs)
+	if errors.Cause(err) == provision.ErrServerUpdateInProgress {
+		return echo.NewHTTPError(http.StatusConflict, "server update already in progress").SetInternal(err)
+	}
+	if err != nil {
+		return errors.Wrap(err, "schedule firewall update")
+	}
+
+	return c.JSON(202, &CreateJobResponse{Job: job})
+}
+
+// UpdateLabels schedules a Job replacing the labels of a Server and applying them as tags of the Server infrastructure.
+func (s *Server) UpdateLabels(c echo.Context) error {
+	srv := request.ServerFromContext(c)
+	if srv == nil {
+		return echo.ErrNotFound.SetInternal(errors.New("server not found in context"))
+	}
+
+	var req UpdateLabelsRequest
+	if err := request.Parse(c, &req); err != nil {
+		return err
+	}
+
+	err := req.Labels.Validate()
+	if err != nil {
+		return echo.ErrBadRequest.SetInternal(err)
+	}
+
+	if srv.Provider != infrastructure.ProviderBYO && srv.WorkspaceSnapshot == nil {
+		return echo.ErrBadRequest.SetInternal(provision.ErrServerNotManaged)
+	}
+
+	settings, err := s.settingsRepo.Find(context.Background(), srv.ProviderSettingsID)
+	if err != nil {
+		return errors.Wrap(err, "find provider settings")
+	}
+
+	job, err := s.jobScheduler.ScheduleServerLabelsUpdate(context.Background(), settings, srv, req.Labels)
+	if errors.Cause(err) == provision.ErrServerUpdateInProgress {
+		return echo.NewHTTPError(http.StatusConflict, "server update already in progress").SetInternal(err)
+	}
+	if err != nil {
+		return errors.Wrap(err, "schedule server labels update")
+	}
+
+	return c.JSON(202, &CreateJobResponse{Job: job})
+}
+
+// UpdateDiskThresholds configures when a Server warns about or expands its nearly full data volume.
+func (s *Server) UpdateDiskThresholds(c echo.Context) error {
+	srv := request.ServerFromContext(c)
+	if srv == nil {
+		return echo.ErrNotFound.SetInternal(errors.New("server not found in context"))
+	}
+
+	var req UpdateDiskThresholdsRequest
+	if err := request.Parse(c, &req); err != nil {
+		return err
+	}
+
+	thresholds := infrastructure.DiskThresholds{
+		WarnPercent:   req.WarnPercent,
+		ExpandPercent: req.ExpandPercent,
+		ExpandBy:      req.ExpandBy,
+	}
+
+	err := thresholds.Validate()
+	if err != nil {
+		return echo.ErrBadRequest.SetInternal(err)
+	}
+	if thresholds.ExpandPercent > 0 && srv.VolumeSize == 0 {
+		return echo.ErrBadRequest.SetInternal(infrastructure.ErrServerWithoutVolume)
+	}
+
+	srv.DiskThresholds = thresholds
+
+	err = s.srvRepo.Update(context.Background(), srv)
+	if err != nil {
+		return errors.Wrap(err, "update server")
+	}
+
+	return c.JSON(200, &GetServerResponse{Server: srv})
+}
+
+// UpdateSnapshotPolicy configures how often the data volume of a Server is snapshotted
+// and how many of the scheduled snapshots are retained.
+func (s *Server) UpdateSnapshotPolicy(c echo.Context) error {
+	srv := request.ServerFromContext(c)
+	if srv == nil {
+		return echo.ErrNotFound.SetInternal(errors.New("server not found in context"))
+	}
+
+	var req UpdateSnapshotPolicyRequest
+	if err := request.Parse(c, &req); err != nil {
+		return err
+	}
+
+	policy := infrastructure.SnapshotPolicy{
+		IntervalHours: req.IntervalHours,
+		Retain:        req.Retain,
+	}
+
+	err := policy.Validate()
+	if err != nil {
+		return echo.ErrBadRequest.SetInternal(err)
+	}
+
+	if policy.IsEnabled() {
+		provider, err := cloudprovider.GetProvider(srv.Provider)
+		if err != nil {
+			return echo.ErrBadRequest.SetInternal(err)
+		}
+		if !provider.Catalog().VolumeSnapshots {
+			return echo.ErrBadRequest.SetInternal(cloudprovider.ErrVolumeSnapshotsNotSupported)
+		}
+		if srv.VolumeSize == 0 {
+			return echo.ErrBadRequest.SetInternal(infrastructure.ErrServerWithoutVolume)
+		}
+	}
+
+	srv.SnapshotPolicy = policy
+
+	err = s.srvRepo.Update(context.Background(), srv)
+	if err != nil {
+		return errors.Wrap(err, "update server")
+	}
+
+	return c.JSON(200, &GetServerResponse{Server: srv})
+}
+
+// ResizeVolume schedules a Job growing the data volume of a Server.
+func (s *Server) ResizeVolume(c echo.Context) error {
+	srv := request.ServerFromContext(c)
+	if srv == nil {
+		return echo.ErrNotFound.SetInternal(errors.New("server not found in context"))
+	}
+
+	var req ResizeVolumeRequest
+	if err := request.Parse(c, &req); err != nil {
+		return err
+	}
+
+	if srv.Provider == infrastructure.ProviderBYO || srv.WorkspaceSnapshot == nil {
+		return echo.ErrBadRequest.SetInternal(provision.ErrServerNotManaged)
+	}
+
+	err := srv.ValidateVolumeSize(req.Size)
+	if err != nil {
+		return echo.ErrBadRequest.SetInternal(err)
+	}
+
+	provider, err := cloudprovider.GetProvider(srv.Provider)
+	if err != nil {
+		return echo.ErrBadRequest.SetInternal(err)
+	}
+
+	err = provider.Catalog().ValidateVolumeSize(req.Size)
+	if err != nil {
+		return echo.ErrBadRequest.SetInternal(err)
+	}
+
+	settings, err := s.settingsRepo.Find(context.Background(), srv.ProviderSettingsID)
+	if err != nil {
+		return errors.Wrap(err, "find provider settings")
+	}
+
+	job, err := s.jobScheduler.ScheduleVolumeResize(context.Background(), settings, srv, req.Size)
+	if errors.Cause(err) == provision.ErrVolumeResizeInProgress || errors.Cause(err) == provision.ErrServerUpdateInProgress {
+		return echo.NewHTTPError(http.StatusConflict, errors.Cause(err).Error()).SetInternal(err)
+	}
+	if err != nil {
+		return errors.Wrap(err, "schedule volume resize")
+	}
+
+	return c.JSON(201, &CreateJobResponse{Job: job})
+}
+
 // Delete issues a delete request for a specific Server.
 func (s *Server) Delete(c echo.Context) error {
 	srv := request.ServerFromContext(c)
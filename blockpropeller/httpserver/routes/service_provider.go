@@ -2,9 +2,12 @@ package routes
 
 import (
 	"context"
+	"time"
 
+	"blockpropeller.dev/blockpropeller/byo"
 	"blockpropeller.dev/blockpropeller/httpserver/request"
 	"blockpropeller.dev/blockpropeller/infrastructure"
+	"blockpropeller.dev/blockpropeller/terraform/cloudprovider"
 	"github.com/labstack/echo"
 	"github.com/pkg/errors"
 )
@@ -14,6 +17,11 @@ type ListProviderTypesResponse struct {
 	ProviderTypes []infrastructure.ProviderType `json:"provider_types"`
 }
 
+// GetCatalogResponse is a response to the get provider catalog request.
+type GetCatalogResponse struct {
+	Catalog *cloudprovider.Catalog `json:"catalog"`
+}
+
 // ListProviderSettingsResponse is a response to the list provider settings request.
 type ListProviderSettingsResponse struct {
 	ProviderSettings []*infrastructure.ProviderSettings `json:"provider_settings"`
@@ -29,6 +37,9 @@ type CreateProviderSettingsRequest struct {
 	Label        string                      `json:"label" form:"label" validate:"required"`
 	ProviderType infrastructure.ProviderType `json:"provider_type" form:"provider_type" validate:"required,valid"`
 	Credentials  string                      `json:"credentials" form:"credentials" validate:"required"`
+
+	// ProjectID the provider settings are organized under, the default project of the organization if empty.
+	ProjectID infrastructure.ProjectID `json:"project_id" form:"project_id"`
 }
 
 // CreateProviderSettingsResponse is a response to the create provider settings request.
@@ -36,25 +47,42 @@ type CreateProviderSettingsResponse struct {
 	ProviderSettings *infrastructure.ProviderSettings `json:"provider_settings"`
 }
 
+// UpdateProviderSettingsRequest holds the request payload for the update provider settings endpoint.
+//
+// Empty fields are left unchanged.
+type UpdateProviderSettingsRequest struct {
+	Label       string `json:"label" form:"label"`
+	Credentials string `json:"credentials" form:"credentials"`
+}
+
+// UpdateProviderSettingsResponse is a response to the update provider settings request.
+type UpdateProviderSettingsResponse struct {
+	ProviderSettings *infrastructure.ProviderSettings `json:"provider_settings"`
+}
+
+// ValidateProviderSettingsResponse is a response to the validate provider settings request.
+type ValidateProviderSettingsResponse struct {
+	ProviderSettings *infrastructure.ProviderSettings `json:"provider_settings"`
+}
+
 // ProviderSettings REST Resource for accessing ProviderSettings resource.
 type ProviderSettings struct {
 	settingsRepo infrastructure.ProviderSettingsRepository
+	projectRepo  infrastructure.ProjectRepository
 }
 
 // NewProviderSettingsRoutes returns a new ProviderSettings routes instance.
-func NewProviderSettingsRoutes(settingsRepo infrastructure.ProviderSettingsRepository) *ProviderSettings {
-	return &ProviderSettings{settingsRepo: settingsRepo}
+func NewProviderSettingsRoutes(
+	settingsRepo infrastructure.ProviderSettingsRepository,
+	projectRepo infrastructure.ProjectRepository,
+) *ProviderSettings {
+	return &ProviderSettings{settingsRepo: settingsRepo, projectRepo: projectRepo}
 }
 
-// LoadProviderSettings is a middleware for loading ProviderSettings into request context
-// as well as checking for correct permissions of an authenticated user.
+// LoadProviderSettings is a middleware for loading ProviderSettings into request context,
+// along with the Organization whose permissions an authenticated user is checked against.
 func (ps *ProviderSettings) LoadProviderSettings(next echo.HandlerFunc) echo.HandlerFunc {
 	return func(c echo.Context) error {
-		auth := request.AuthFromContext(c)
-		if auth == nil {
-			return echo.ErrUnauthorized.SetInternal(errors.New("missing authenticated account"))
-		}
-
 		settingsID := infrastructure.ProviderSettingsID(c.Param("settings_id"))
 		if settingsID == infrastructure.NilProviderSettingsID {
 			return next(c)
@@ -65,36 +93,67 @@ func (ps *ProviderSettings) LoadProviderSettings(next echo.HandlerFunc) echo.Han
 			return echo.ErrNotFound.SetInternal(errors.Wrap(err, "find settings"))
 		}
 
-		if settings.AccountID != auth.ID {
-			return echo.ErrForbidden.
-				SetInternal(errors.Errorf(
-					"unauthorized provider settings access: authenticated %s, settings %s",
-					auth.ID, settings.ID))
-		}
-
 		request.WithProviderSettings(c, settings)
+		request.WithOrganization(c, settings.OrganizationID)
 
 		return next(c)
 	}
 }
 
 // GetProviderTypes returns all available provider types.
+//
+// Provider types which are not enabled on this server are left out.
 func (ps *ProviderSettings) GetProviderTypes(c echo.Context) error {
+	var types []infrastructure.ProviderType
+	for _, typ := range infrastructure.ValidProviders {
+		if typ == infrastructure.ProviderBYO || cloudprovider.IsRegistered(typ) {
+			types = append(types, typ)
+		}
+	}
+
 	return c.JSON(200, &ListProviderTypesResponse{
-		ProviderTypes: infrastructure.ValidProviders,
+		ProviderTypes: types,
 	})
 }
 
-// List all ProviderSettings for an Account.
+// GetCatalog returns the regions, images and instance types offered by a provider type.
+func (ps *ProviderSettings) GetCatalog(c echo.Context) error {
+	typ := infrastructure.ProviderType(c.Param("type"))
+
+	provider, err := cloudprovider.GetProvider(typ)
+	if err != nil {
+		return echo.ErrNotFound.SetInternal(errors.Wrap(err, "get provider"))
+	}
+
+	return c.JSON(200, &GetCatalogResponse{Catalog: provider.Catalog()})
+}
+
+// List all ProviderSettings for an Organization,
+// optionally only the ones within the Project in the `project_id` query parameter.
 func (ps *ProviderSettings) List(c echo.Context) error {
-	acc := request.AuthFromContext(c)
-	if acc == nil {
+	member := request.MembershipFromContext(c)
+	if member == nil {
 		return echo.ErrForbidden
 	}
 
-	settings, err := ps.settingsRepo.List(context.Background(), acc.ID)
-	if err != nil {
-		return errors.Wrap(err, "list provider settings")
+	var settings []*infrastructure.ProviderSettings
+	projectID := infrastructure.ProjectID(c.QueryParam("project_id"))
+	if projectID != infrastructure.NilProjectID {
+		project, err := findProject(ps.projectRepo, member, projectID)
+		if err != nil {
+			return err
+		}
+
+		settings, err = ps.settingsRepo.ListByProject(context.Background(), project.ID)
+		if err != nil {
+			return errors.Wrap(err, "list project provider settings")
+		}
+	} else {
+		var err error
+		settings, err = ps.settingsRepo.ListByOrganization(context.Background(), member.OrganizationID)
+		if err != nil {
+			return errors.Wrap(err, "list provider settings")
+		}
 	}
 
 	return c.JSON(200, &ListProviderSettingsResponse{
@@ -119,18 +178,94 @@ func (ps *ProviderSettings) Create(c echo.Context) error {
 		return err
 	}
 
-	acc := request.AuthFromContext(c)
+	member := request.MembershipFromContext(c)
+	if member == nil {
+		return echo.ErrForbidden
+	}
+
+	project, err := findProjectOrDefault(ps.projectRepo, member, req.ProjectID)
+	if err != nil {
+		return err
+	}
+
+	settings := infrastructure.NewProviderSettings(member.AccountID, req.Label, req.ProviderType, req.Credentials)
+	settings.OrganizationID = project.OrganizationID
+	settings.ProjectID = project.ID
 
-	settings := infrastructure.NewProviderSettings(acc.ID, req.Label, req.ProviderType, req.Credentials)
+	// Credentials rejected by the provider are refused outright,
+	// while a provider that could not be reached only has its failure recorded.
+	err = validateCredentials(context.Background(), settings)
+	if cloudprovider.IsInvalidCredentials(err) {
+		return echo.ErrBadRequest.SetInternal(errors.Wrap(err, "validate credentials"))
+	}
+	settings.SetValidationResult(err)
 
-	err := ps.settingsRepo.Create(context.Background(), settings)
+	err = ps.settingsRepo.Create(context.Background(), settings)
 	if err != nil {
 		return errors.Wrap(err, "create provider settings")
 	}
 
+	request.WithAuditTarget(c, settings.ID.String())
+
 	return c.JSON(201, &CreateProviderSettingsResponse{ProviderSettings: settings})
 }
 
+// Update changes the label and credentials of a ProviderSettings.
+//
+// New credentials are validated the same way as on create, and take effect
+// for every Server provisioned with the ProviderSettings.
+func (ps *ProviderSettings) Update(c echo.Context) error {
+	settings := request.ProviderSettingsFromContext(c)
+	if settings == nil {
+		return echo.ErrNotFound.SetInternal(errors.New("settings not found in context"))
+	}
+
+	var req UpdateProviderSettingsRequest
+	if err := request.Parse(c, &req); err != nil {
+		return err
+	}
+
+	if req.Label != "" {
+		settings.Label = req.Label
+	}
+
+	if req.Credentials != "" {
+		settings.Credentials = req.Credentials
+
+		err := validateCredentials(context.Background(), settings)
+		if cloudprovider.IsInvalidCredentials(err) {
+			return echo.ErrBadRequest.SetInternal(errors.Wrap(err, "validate credentials"))
+		}
+		settings.SetValidationResult(err)
+	}
+
+	settings.UpdatedAt = time.Now()
+
+	err := ps.settingsRepo.Update(context.Background(), settings)
+	if err != nil {
+		return errors.Wrap(err, "update provider settings")
+	}
+
+	return c.JSON(200, &UpdateProviderSettingsResponse{ProviderSettings: settings})
+}
+
+// Validate checks the ProviderSettings credentials with the provider and stores the result.
+func (ps *ProviderSettings) Validate(c echo.Context) error {
+	settings := request.ProviderSettingsFromContext(c)
+	if settings == nil {
+		return echo.ErrNotFound.SetInternal(errors.New("settings not found in context"))
+	}
+
+	settings.SetValidationResult(validateCredentials(context.Background(), settings))
+
+	err := ps.settingsRepo.Update(context.Background(), settings)
+	if err != nil {
+		return errors.Wrap(err, "update provider settings")
+	}
+
+	return c.JSON(200, &ValidateProviderSettingsResponse{ProviderSettings: settings})
+}
+
 // Delete ProviderSettings.
 func (ps *ProviderSettings) Delete(c echo.Context) error {
 	settings := request.ProviderSettingsFromContext(c)
@@ -145,3 +280,25 @@ func (ps *ProviderSettings) Delete(c echo.Context) error {
 
 	return c.NoContent(204)
 }
+
+// validateCredentials checks the credentials with the provider the ProviderSettings are meant for.
+//
+// Existing hosts are not managed by a CloudProvider, so only their credentials format is checked.
+// Provider types which are not enabled on this server are refused like invalid credentials.
+func validateCredentials(ctx context.Context, settings *infrastructure.ProviderSettings) error {
+	if settings.Type == infrastructure.ProviderBYO {
+		_, err := byo.ParseCredentials(settings)
+		if err != nil {
+			return cloudprovider.InvalidCredentials(err)
+		}
+
+		return nil
+	}
+
+	provider, err := cloudprovider.GetProvider(settings.Type)
+	if err != nil {
+		return cloudprovider.InvalidCredentials(errors.Wrap(err, "get provider"))
+	}
+
+	return provider.ValidateCredentials(ctx, settings)
+}
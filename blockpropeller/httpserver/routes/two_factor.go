@@ -0,0 +1,189 @@
+package routes
+
+import (
+	"context"
+	"net/http"
+
+	"blockpropeller.dev/blockpropeller/account"
+	"blockpropeller.dev/blockpropeller/httpserver/request"
+	"blockpropeller.dev/lib/server"
+	"github.com/labstack/echo"
+	"github.com/pkg/errors"
+)
+
+// TwoFactorStatusResponse is a response to the two-factor status request.
+type TwoFactorStatusResponse struct {
+	Enabled           bool `json:"enabled"`
+	RecoveryCodesLeft int  `json:"recovery_codes_left"`
+}
+
+// EnrollTwoFactorResponse is a response to the two-factor enrollment request.
+//
+// The secret is added to an authenticator app, either directly or through the provisioning URI.
+type EnrollTwoFactorResponse struct {
+	Secret          account.TOTPSecret `json:"secret"`
+	ProvisioningURI string             `json:"provisioning_uri"`
+}
+
+// TwoFactorCodeRequest holds the request payload for the endpoints that verify a second factor.
+//
+// The code is either a TOTP code or a recovery code.
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" form:"code" validate:"required"`
+}
+
+// RecoveryCodesResponse is a response holding newly generated recovery codes.
+//
+// The recovery codes are returned only once, and cannot be retrieved afterwards.
+type RecoveryCodesResponse struct {
+	RecoveryCodes []account.RecoverySecret `json:"recovery_codes"`
+}
+
+// TwoFactor routes manage the two-factor authentication of the authenticated Account.
+type TwoFactor struct {
+	twoFactorSvc *account.TwoFactorService
+}
+
+// NewTwoFactorRoutes returns a new TwoFactor routes instance.
+func NewTwoFactorRoutes(twoFactorSvc *account.TwoFactorService) *TwoFactor {
+	return &TwoFactor{twoFactorSvc: twoFactorSvc}
+}
+
+// Status returns whether the authenticated Account has two-factor authentication enabled.
+func (tf *TwoFactor) Status(c echo.Context) error {
+	acc := request.AuthFromContext(c)
+	if acc == nil {
+		return echo.ErrForbidden
+	}
+
+	left, err := tf.twoFactorSvc.RecoveryCodesLeft(context.Background(), acc)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(200, &TwoFactorStatusResponse{
+		Enabled:           acc.IsTwoFactorEnabled(),
+		RecoveryCodesLeft: left,
+	})
+}
+
+// Enroll the authenticated Account into two-factor authentication.
+//
+// Two-factor authentication is enabled once a code generated from the returned secret is verified.
+func (tf *TwoFactor) Enroll(c echo.Context) error {
+	acc := request.AuthFromContext(c)
+	if acc == nil {
+		return echo.ErrForbidden
+	}
+
+	secret, uri, err := tf.twoFactorSvc.Enroll(context.Background(), acc)
+	if errors.Cause(err) == account.ErrTwoFactorAlreadyEnabled {
+		return echo.NewHTTPError(http.StatusConflict, "two-factor authentication already enabled").SetInternal(err)
+	}
+	if err != nil {
+		return errors.Wrap(err, "enroll two-factor authentication")
+	}
+
+	return c.JSON(200, &EnrollTwoFactorResponse{
+		Secret:          secret,
+		ProvisioningURI: uri,
+	})
+}
+
+// Enable two-factor authentication for the authenticated Account, returning its recovery codes.
+func (tf *TwoFactor) Enable(c echo.Context) error {
+	var req TwoFactorCodeRequest
+	if err := request.Parse(c, &req); err != nil {
+		return err
+	}
+
+	acc := request.AuthFromContext(c)
+	session := request.SessionFromContext(c)
+	if acc == nil || session == nil {
+		return echo.ErrForbidden
+	}
+
+	recoveryCodes, err := tf.twoFactorSvc.Enable(context.Background(), acc, session, req.Code)
+	switch errors.Cause(err) {
+	case nil:
+	case account.ErrTwoFactorAlreadyEnabled:
+		return echo.NewHTTPError(http.StatusConflict, "two-factor authentication already enabled").SetInternal(err)
+	case account.ErrTwoFactorNotEnrolled, account.ErrInvalidTwoFactorCode:
+		return echo.NewHTTPError(http.StatusBadRequest, errors.Cause(err).Error()).SetInternal(err)
+	default:
+		return errors.Wrap(err, "enable two-factor authentication")
+	}
+
+	return c.JSON(200, &RecoveryCodesResponse{RecoveryCodes: recoveryCodes})
+}
+
+// Verify the second factor within the authenticated session, allowing it to perform sensitive operations.
+func (tf *TwoFactor) Verify(c echo.Context) error {
+	var req TwoFactorCodeRequest
+	if err := request.Parse(c, &req); err != nil {
+		return err
+	}
+
+	acc := request.AuthFromContext(c)
+	session := request.SessionFromContext(c)
+	if acc == nil || session == nil {
+		return echo.ErrForbidden
+	}
+
+	err := tf.twoFactorSvc.Confirm(context.Background(), acc, session, req.Code)
+	if err != nil {
+		return twoFactorError(c, err, "verify second factor")
+	}
+
+	return c.NoContent(204)
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes of the authenticated Account.
+func (tf *TwoFactor) RegenerateRecoveryCodes(c echo.Context) error {
+	acc := request.AuthFromContext(c)
+	if acc == nil {
+		return echo.ErrForbidden
+	}
+
+	recoveryCodes, err := tf.twoFactorSvc.RegenerateRecoveryCodes(context.Background(), acc)
+	if err != nil {
+		return twoFactorError(c, err, "regenerate recovery codes")
+	}
+
+	return c.JSON(200, &RecoveryCodesResponse{RecoveryCodes: recoveryCodes})
+}
+
+// Disable two-factor authentication for the authenticated Account.
+func (tf *TwoFactor) Disable(c echo.Context) error {
+	var req TwoFactorCodeRequest
+	if err := request.Parse(c, &req); err != nil {
+		return err
+	}
+
+	acc := request.AuthFromContext(c)
+	if acc == nil {
+		return echo.ErrForbidden
+	}
+
+	err := tf.twoFactorSvc.Disable(context.Background(), acc, req.Code)
+	if err != nil {
+		return twoFactorError(c, err, "disable two-factor authentication")
+	}
+
+	return c.NoContent(204)
+}
+
+// twoFactorError converts the errors of the TwoFactorService into HTTP errors,
+// marking invalid codes as failed attempts for throttling.
+func twoFactorError(c echo.Context, err error, msg string) error {
+	switch errors.Cause(err) {
+	case account.ErrTwoFactorNotEnabled:
+		return echo.NewHTTPError(http.StatusConflict, "two-factor authentication not enabled").SetInternal(err)
+	case account.ErrInvalidTwoFactorCode:
+		server.MarkFailedAttempt(c)
+
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid two-factor code").SetInternal(err)
+	default:
+		return errors.Wrap(err, msg)
+	}
+}
@@ -0,0 +1,151 @@
+package routes
+
+import (
+	"context"
+	"net/http"
+
+	"blockpropeller.dev/blockpropeller/httpserver/request"
+	"blockpropeller.dev/blockpropeller/infrastructure"
+	"blockpropeller.dev/blockpropeller/provision"
+	"blockpropeller.dev/blockpropeller/terraform/cloudprovider"
+	"github.com/labstack/echo"
+	"github.com/pkg/errors"
+)
+
+// ListVolumeSnapshotsResponse is a response to the list volume snapshots request.
+type ListVolumeSnapshotsResponse struct {
+	VolumeSnapshots []*infrastructure.VolumeSnapshot `json:"volume_snapshots"`
+}
+
+// GetVolumeSnapshotResponse is a response to the get volume snapshot request.
+type GetVolumeSnapshotResponse struct {
+	VolumeSnapshot *infrastructure.VolumeSnapshot `json:"volume_snapshot"`
+}
+
+// VolumeSnapshot REST Resource for managing snapshots of server data volumes.
+type VolumeSnapshot struct {
+	snapProvisioner *provision.VolumeSnapshotProvisioner
+
+	srvRepo  infrastructure.ServerRepository
+	snapRepo infrastructure.VolumeSnapshotRepository
+}
+
+// NewVolumeSnapshotRoutes returns a new VolumeSnapshot routes instance.
+func NewVolumeSnapshotRoutes(
+	snapProvisioner *provision.VolumeSnapshotProvisioner,
+	srvRepo infrastructure.ServerRepository,
+	snapRepo infrastructure.VolumeSnapshotRepository,
+) *VolumeSnapshot {
+	return &VolumeSnapshot{snapProvisioner: snapProvisioner, srvRepo: srvRepo, snapRepo: snapRepo}
+}
+
+// LoadVolumeSnapshot is a middleware for loading VolumeSnapshot into request context,
+// along with the Organization whose permissions an authenticated user is checked against.
+func (r *VolumeSnapshot) LoadVolumeSnapshot(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		snapID := infrastructure.VolumeSnapshotID(c.Param("volume_snapshot_id"))
+		snap, err := r.snapRepo.Find(context.Background(), snapID)
+		if err != nil {
+			return echo.ErrNotFound.SetInternal(errors.Wrap(err, "find volume snapshot"))
+		}
+
+		request.WithVolumeSnapshot(c, snap)
+		request.WithOrganization(c, snap.OrganizationID)
+
+		return next(c)
+	}
+}
+
+// List all VolumeSnapshots for an Organization,
+// optionally only the ones taken of the Server in the `server_id` query parameter.
+func (r *VolumeSnapshot) List(c echo.Context) error {
+	member := request.MembershipFromContext(c)
+	if member == nil {
+		return echo.ErrForbidden
+	}
+
+	srvID := infrastructure.ServerIDFromString(c.QueryParam("server_id"))
+	if srvID == infrastructure.NilServerID {
+		snapshots, err := r.snapRepo.ListByOrganization(context.Background(), member.OrganizationID)
+		if err != nil {
+			return errors.Wrap(err, "list volume snapshots")
+		}
+
+		return c.JSON(200, &ListVolumeSnapshotsResponse{VolumeSnapshots: snapshots})
+	}
+
+	srv, err := r.srvRepo.Find(context.Background(), srvID)
+	if err != nil {
+		return echo.ErrNotFound.SetInternal(errors.Wrap(err, "find server"))
+	}
+	if srv.OrganizationID != member.OrganizationID {
+		return echo.ErrForbidden.
+			SetInternal(errors.Errorf("unauthorized server access: organization %s, server %s",
+				member.OrganizationID, srvID))
+	}
+
+	snapshots, err := r.snapRepo.ListByServer(context.Background(), srvID)
+	if err != nil {
+		return errors.Wrap(err, "list server volume snapshots")
+	}
+
+	return c.JSON(200, &ListVolumeSnapshotsResponse{VolumeSnapshots: snapshots})
+}
+
+// Get a VolumeSnapshot.
+func (r *VolumeSnapshot) Get(c echo.Context) error {
+	snap := request.VolumeSnapshotFromContext(c)
+	if snap == nil {
+		return echo.ErrNotFound.SetInternal(errors.New("volume snapshot not found in context"))
+	}
+
+	return c.JSON(200, &GetVolumeSnapshotResponse{VolumeSnapshot: snap})
+}
+
+// Create requests an on-demand snapshot of the Server data volume.
+//
+// The snapshot is taken in the background, so it is returned in the requested state.
+func (r *VolumeSnapshot) Create(c echo.Context) error {
+	srv := request.ServerFromContext(c)
+	if srv == nil {
+		return echo.ErrNotFound.SetInternal(errors.New("server not found in context"))
+	}
+	if srv.State != infrastructure.ServerStateOk {
+		return echo.ErrBadRequest.SetInternal(errors.Errorf("server in %s state cannot be snapshotted", srv.State))
+	}
+
+	snap, err := r.snapProvisioner.Request(context.Background(), srv, false)
+	switch errors.Cause(err) {
+	case nil:
+	case provision.ErrVolumeSnapshotInProgress:
+		return echo.NewHTTPError(http.StatusConflict, "volume snapshot already in progress").SetInternal(err)
+	case provision.ErrServerNotManaged, cloudprovider.ErrVolumeSnapshotsNotSupported, infrastructure.ErrServerWithoutVolume:
+		return echo.ErrBadRequest.SetInternal(err)
+	default:
+		return errors.Wrap(err, "request volume snapshot")
+	}
+
+	request.WithAuditTarget(c, snap.ID.String())
+
+	return c.JSON(202, &GetVolumeSnapshotResponse{VolumeSnapshot: snap})
+}
+
+// Delete destroys a VolumeSnapshot with the provider.
+//
+// Snapshots that are still being taken cannot be deleted.
+func (r *VolumeSnapshot) Delete(c echo.Context) error {
+	snap := request.VolumeSnapshotFromContext(c)
+	if snap == nil {
+		return echo.ErrNotFound.SetInternal(errors.New("volume snapshot not found in context"))
+	}
+
+	err := r.snapProvisioner.Delete(context.Background(), snap)
+	if errors.Cause(err) == provision.ErrVolumeSnapshotInProgress {
+		return echo.NewHTTPError(http.StatusConflict, "volume snapshot is still being taken").SetInternal(err)
+	}
+	if err != nil {
+		return errors.Wrap(err, "delete volume snapshot")
+	}
+
+	return c.NoContent(204)
+}
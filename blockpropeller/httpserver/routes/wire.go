@@ -7,8 +7,15 @@ import "github.com/google/wire"
 var Set = wire.NewSet(
 	NewAuthenticationRoutes,
 	NewAccountRoutes,
+	NewAPIKeyRoutes,
+	NewTwoFactorRoutes,
+	NewProjectRoutes,
+	NewOrganizationRoutes,
 	NewProviderSettingsRoutes,
 	NewProvisionRoutes,
 	NewServerRoutes,
+	NewReservedIPRoutes,
+	NewVolumeSnapshotRoutes,
 	NewDeploymentRoutes,
+	NewAuditRoutes,
 )
@@ -14,6 +14,8 @@ var Set = wire.NewSet(
 	wire.Bind(new(server.Router), new(*Router)),
 
 	middleware.NewAuthenticationMiddleware,
+	middleware.NewAuthorizationMiddleware,
+	middleware.NewAuditMiddleware,
 
 	routes.Set,
 	server.Set,
@@ -3,6 +3,8 @@ package infrastructure
 import (
 	"context"
 	"encoding/json"
+	"regexp"
+	"strings"
 	"sync"
 	"time"
 
@@ -17,6 +19,8 @@ var (
 	ErrDeploymentAlreadyExists = errors.New("deployment already exists")
 )
 
+var hostnameLabel = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
 // DeploymentID is a unique server identifier.
 type DeploymentID string
 
@@ -41,6 +45,11 @@ type Deployment struct {
 
 	State DeploymentState `json:"state" gorm:"type:varchar(100) not null"`
 
+	Labels Labels `json:"labels,omitempty" gorm:"type:text"`
+
+	DNSZone string `json:"dns_zone,omitempty" gorm:"type:varchar(255)"`
+	DNSName string `json:"dns_name,omitempty" gorm:"type:varchar(255)"`
+
 	CreatedAt time.Time  `json:"created_at" gorm:"type:timestamp not null;default:CURRENT_TIMESTAMP"`
 	UpdatedAt time.Time  `json:"updated_at" gorm:"type:timestamp not null;default:CURRENT_TIMESTAMP"`
 	DeletedAt *time.Time `json:"-" gorm:"type:timestamp"`
@@ -61,6 +70,52 @@ func NewDeployment(typ DeploymentType, config DeploymentConfig) *Deployment {
 	}
 }
 
+// SetHostname assigns the Deployment a hostname inside a user-owned DNS zone,
+// which points to the Server the Deployment runs on.
+//
+// A name such as "fullnode-1" in the "nodes.example.com" zone results in
+// the "fullnode-1.nodes.example.com" hostname.
+func (d *Deployment) SetHostname(zone string, name string) error {
+	zone = strings.TrimSuffix(strings.ToLower(zone), ".")
+	name = strings.ToLower(name)
+
+	if !isValidHostname(zone) || !strings.Contains(zone, ".") {
+		return errors.Errorf("invalid dns zone: %q", zone)
+	}
+	if strings.Contains(name, ".") || !isValidHostname(name) {
+		return errors.Errorf("invalid dns name: %q", name)
+	}
+
+	d.DNSZone = zone
+	d.DNSName = name
+
+	return nil
+}
+
+// SetLabels replaces the user-defined labels of the Deployment.
+//
+// Deployment labels are applied as tags to the cloud resources of the Server it runs on.
+func (d *Deployment) SetLabels(labels Labels) error {
+	err := labels.Validate()
+	if err != nil {
+		return err
+	}
+
+	d.Labels = labels
+
+	return nil
+}
+
+// Hostname returns the fully qualified hostname of the Deployment,
+// or an empty string if it has none.
+func (d *Deployment) Hostname() string {
+	if d.DNSZone == "" {
+		return ""
+	}
+
+	return d.DNSName + "." + d.DNSZone
+}
+
 // AfterFind is a GORM hook that parses Deployment Config information into a structured format.
 func (d *Deployment) AfterFind() error {
 	if d.RawConfiguration == "" {
@@ -91,6 +146,21 @@ func (d *Deployment) parseConfig() error {
 	return nil
 }
 
+// isValidHostname checks whether each dot separated label of the hostname is valid.
+func isValidHostname(hostname string) bool {
+	if hostname == "" || len(hostname) > 253 {
+		return false
+	}
+
+	for _, label := range strings.Split(hostname, ".") {
+		if !hostnameLabel.MatchString(label) {
+			return false
+		}
+	}
+
+	return true
+}
+
 // DeploymentRepository defines an interface for storing and retrieving deployments.
 //
 // @TODO: Consider moving deployments under the Server repository.
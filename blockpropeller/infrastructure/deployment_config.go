@@ -17,6 +17,8 @@ type DeploymentSpec interface {
 	UnmarshalConfig(map[string]string) (DeploymentConfig, error)
 
 	HealthCheck(*Server, *Deployment) (HealthCheck, error)
+
+	Ports(*Deployment) DeploymentPorts
 }
 
 // DeploymentConfig represents custom configuration options for each deployment.
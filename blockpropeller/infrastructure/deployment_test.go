@@ -0,0 +1,43 @@
+package infrastructure_test
+
+import (
+	"testing"
+
+	"blockpropeller.dev/blockpropeller/infrastructure"
+	"blockpropeller.dev/lib/test"
+)
+
+func TestDeploymentSetHostname(t *testing.T) {
+	tests := []struct {
+		zone    string
+		name    string
+		want    string
+		wantErr bool
+	}{
+		{zone: "nodes.example.com", name: "fullnode-1", want: "fullnode-1.nodes.example.com"},
+		{zone: "Nodes.Example.com.", name: "FullNode-1", want: "fullnode-1.nodes.example.com"},
+		{zone: "example.com", name: "bnb", want: "bnb.example.com"},
+		{zone: "", name: "fullnode-1", wantErr: true},
+		{zone: "localhost", name: "fullnode-1", wantErr: true},
+		{zone: "nodes.example.com", name: "", wantErr: true},
+		{zone: "nodes.example.com", name: "fullnode.1", wantErr: true},
+		{zone: "nodes.example.com", name: "-fullnode", wantErr: true},
+		{zone: "nodes..example.com", name: "fullnode-1", wantErr: true},
+		{zone: "nodes.example.com", name: "full_node", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name+"."+tt.zone, func(t *testing.T) {
+			deployment := infrastructure.NewDeployment("", nil)
+
+			err := deployment.SetHostname(tt.zone, tt.name)
+			if tt.wantErr {
+				test.CheckErrExists(t, "SetHostname()", err)
+				test.AssertStringsEqual(t, "Hostname()", deployment.Hostname(), "")
+				return
+			}
+
+			test.CheckErr(t, "SetHostname()", err)
+			test.AssertStringsEqual(t, "Hostname()", deployment.Hostname(), tt.want)
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package infrastructure
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrServerWithoutVolume is returned when resizing the data volume of a Server that has none.
+	ErrServerWithoutVolume = errors.New("server has no data volume")
+	// ErrVolumeShrink is returned when a data volume is requested to be resized to a smaller or equal size.
+	ErrVolumeShrink = errors.New("volume can only be grown")
+)
+
+// DiskAction is an action to be taken based on the disk usage of a Server.
+type DiskAction string
+
+var (
+	// DiskActionNone requires no action, the disk has enough free space.
+	DiskActionNone = DiskAction("none")
+	// DiskActionWarn signals that the disk is running out of free space.
+	DiskActionWarn = DiskAction("warn")
+	// DiskActionExpand signals that the data volume should be expanded.
+	DiskActionExpand = DiskAction("expand")
+)
+
+// DiskUsage holds the usage of the data volume of a Server, as last collected by a health check.
+type DiskUsage struct {
+	TotalBytes int64      `json:"total_bytes" gorm:"type:bigint not null;default:0"`
+	UsedBytes  int64      `json:"used_bytes" gorm:"type:bigint not null;default:0"`
+	CheckedAt  *time.Time `json:"checked_at,omitempty" gorm:"type:timestamp"`
+}
+
+// ParseDiskUsage parses the output of `df -P -k` for a single filesystem.
+func ParseDiskUsage(out string) (DiskUsage, error) {
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) < 2 {
+		return DiskUsage{}, errors.Errorf("unexpected df output: %s", out)
+	}
+
+	fields := strings.Fields(lines[len(lines)-1])
+	if len(fields) < 6 {
+		return DiskUsage{}, errors.Errorf("unexpected df output: %s", out)
+	}
+
+	total, err := strconv.ParseInt(fields[1], 10, 64)
+	if err != nil {
+		return DiskUsage{}, errors.Wrap(err, "parse total size")
+	}
+
+	used, err := strconv.ParseInt(fields[2], 10, 64)
+	if err != nil {
+		return DiskUsage{}, errors.Wrap(err, "parse used size")
+	}
+
+	now := time.Now()
+
+	return DiskUsage{
+		TotalBytes: total * 1024,
+		UsedBytes:  used * 1024,
+		CheckedAt:  &now,
+	}, nil
+}
+
+// Percent returns the percentage of the disk in use.
+func (u DiskUsage) Percent() float64 {
+	if u.TotalBytes == 0 {
+		return 0
+	}
+
+	return float64(u.UsedBytes) / float64(u.TotalBytes) * 100
+}
+
+// DiskThresholds configure what happens once the data volume of a Server is nearly full.
+//
+// A zero threshold is disabled. Volumes are expanded by ExpandBy GB,
+// or by half of their current size if ExpandBy is not set.
+type DiskThresholds struct {
+	WarnPercent   int `json:"warn_percent" gorm:"type:integer not null;default:0"`
+	ExpandPercent int `json:"expand_percent" gorm:"type:integer not null;default:0"`
+	ExpandBy      int `json:"expand_by_gb" gorm:"type:integer not null;default:0"`
+}
+
+// Validate checks that the thresholds are valid percentages, with warnings issued before expansion.
+func (t DiskThresholds) Validate() error {
+	if t.WarnPercent < 0 || t.WarnPercent > 100 {
+		return errors.New("warn threshold must be between 0 and 100")
+	}
+	if t.ExpandPercent < 0 || t.ExpandPercent > 100 {
+		return errors.New("expand threshold must be between 0 and 100")
+	}
+	if t.WarnPercent > 0 && t.ExpandPercent > 0 && t.WarnPercent >= t.ExpandPercent {
+		return errors.New("warn threshold must be below expand threshold")
+	}
+	if t.ExpandBy < 0 {
+		return errors.New("volume expansion must be positive")
+	}
+
+	return nil
+}
+
+// Evaluate returns the action to be taken for the provided disk usage.
+func (t DiskThresholds) Evaluate(usage DiskUsage) DiskAction {
+	if usage.TotalBytes == 0 {
+		return DiskActionNone
+	}
+
+	percent := usage.Percent()
+	if t.ExpandPercent > 0 && percent >= float64(t.ExpandPercent) {
+		return DiskActionExpand
+	}
+	if t.WarnPercent > 0 && percent >= float64(t.WarnPercent) {
+		return DiskActionWarn
+	}
+
+	return DiskActionNone
+}
+
+// ExpandedSize returns the size in GB a volume of the provided size should be expanded to.
+func (t DiskThresholds) ExpandedSize(size int) int {
+	if t.ExpandBy > 0 {
+		return size + t.ExpandBy
+	}
+
+	expandBy := size / 2
+	if expandBy == 0 {
+		expandBy = 1
+	}
+
+	return size + expandBy
+}
@@ -0,0 +1,88 @@
+package infrastructure_test
+
+import (
+	"testing"
+
+	"blockpropeller.dev/blockpropeller/infrastructure"
+	"blockpropeller.dev/lib/test"
+	"github.com/pkg/errors"
+)
+
+func TestParseDiskUsage(t *testing.T) {
+	out := `Filesystem     1024-blocks      Used Available Capacity Mounted on
+/dev/sda         515010816 463509734  51501082      91% /mnt/volume
+`
+
+	usage, err := infrastructure.ParseDiskUsage(out)
+	test.CheckErr(t, "ParseDiskUsage()", err)
+
+	if usage.TotalBytes != 515010816*1024 {
+		t.Errorf("ParseDiskUsage() total = %d, want %d", usage.TotalBytes, 515010816*1024)
+	}
+	if usage.UsedBytes != 463509734*1024 {
+		t.Errorf("ParseDiskUsage() used = %d, want %d", usage.UsedBytes, 463509734*1024)
+	}
+	if usage.CheckedAt == nil {
+		t.Errorf("ParseDiskUsage() missing checked at")
+	}
+	test.AssertIntsEqual(t, "Percent()", int(usage.Percent()), 89)
+
+	for _, invalid := range []string{"", "Filesystem 1024-blocks Used", "Filesystem\n/dev/sda a b c 1% /mnt/volume"} {
+		_, err = infrastructure.ParseDiskUsage(invalid)
+		test.CheckErrExists(t, "ParseDiskUsage("+invalid+")", err)
+	}
+}
+
+func TestDiskThresholds(t *testing.T) {
+	thresholds := infrastructure.DiskThresholds{WarnPercent: 80, ExpandPercent: 90}
+	test.CheckErr(t, "Validate()", thresholds.Validate())
+
+	tests := []struct {
+		used int64
+		want infrastructure.DiskAction
+	}{
+		{used: 0, want: infrastructure.DiskActionNone},
+		{used: 79, want: infrastructure.DiskActionNone},
+		{used: 80, want: infrastructure.DiskActionWarn},
+		{used: 90, want: infrastructure.DiskActionExpand},
+		{used: 100, want: infrastructure.DiskActionExpand},
+	}
+	for _, tt := range tests {
+		got := thresholds.Evaluate(infrastructure.DiskUsage{TotalBytes: 100, UsedBytes: tt.used})
+		test.AssertStringsEqual(t, "Evaluate()", string(got), string(tt.want))
+	}
+
+	unknown := thresholds.Evaluate(infrastructure.DiskUsage{})
+	test.AssertStringsEqual(t, "Evaluate() without usage", string(unknown), string(infrastructure.DiskActionNone))
+
+	test.AssertIntsEqual(t, "ExpandedSize() by half", thresholds.ExpandedSize(500), 750)
+	thresholds.ExpandBy = 100
+	test.AssertIntsEqual(t, "ExpandedSize() by fixed amount", thresholds.ExpandedSize(500), 600)
+
+	for _, invalid := range []infrastructure.DiskThresholds{
+		{WarnPercent: -1},
+		{ExpandPercent: 101},
+		{WarnPercent: 90, ExpandPercent: 80},
+		{ExpandPercent: 90, ExpandBy: -10},
+	} {
+		test.CheckErrExists(t, "Validate()", invalid.Validate())
+	}
+}
+
+func TestServerValidateVolumeSize(t *testing.T) {
+	srv := infrastructure.NewServer("", "test", infrastructure.ProviderDigitalOcean, infrastructure.ServerSizeProd, nil)
+
+	err := srv.ValidateVolumeSize(600)
+	if errors.Cause(err) != infrastructure.ErrServerWithoutVolume {
+		t.Errorf("ValidateVolumeSize() without volume = %v, want %v", err, infrastructure.ErrServerWithoutVolume)
+	}
+
+	srv.VolumeSize = 500
+
+	err = srv.ValidateVolumeSize(500)
+	if errors.Cause(err) != infrastructure.ErrVolumeShrink {
+		t.Errorf("ValidateVolumeSize() same size = %v, want %v", err, infrastructure.ErrVolumeShrink)
+	}
+
+	test.CheckErr(t, "ValidateVolumeSize()", srv.ValidateVolumeSize(600))
+}
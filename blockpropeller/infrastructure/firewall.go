@@ -0,0 +1,137 @@
+package infrastructure
+
+import (
+	"net"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	// ProtocolTCP is the protocol of ports accepting TCP connections.
+	ProtocolTCP = "tcp"
+	// ProtocolUDP is the protocol of ports accepting UDP traffic.
+	ProtocolUDP = "udp"
+
+	// SSHPort is the port BlockPropeller manages Servers through.
+	SSHPort = Port{Number: 22, Protocol: ProtocolTCP}
+
+	// AnySource allows traffic coming from any IPv4 or IPv6 address.
+	AnySource = []string{"0.0.0.0/0", "::/0"}
+)
+
+// Port is a network port a Deployment accepts traffic on.
+type Port struct {
+	Number   int    `json:"number"`
+	Protocol string `json:"protocol"`
+}
+
+// DeploymentPorts declares the ports a Deployment needs to be reachable on.
+//
+// Public ports are reachable from anywhere, while restricted ports
+// are only reachable from the allow-list of the Server.
+type DeploymentPorts struct {
+	Public     []Port `json:"public"`
+	Restricted []Port `json:"restricted"`
+}
+
+// FirewallRule allows inbound traffic on a port coming from a set of networks in CIDR notation.
+type FirewallRule struct {
+	Port    Port
+	Sources []string
+}
+
+// ParseAllowedIP parses an IP address or a network in CIDR notation
+// into a network in CIDR notation, suitable for use in FirewallRules.
+func ParseAllowedIP(raw string) (string, error) {
+	_, network, err := net.ParseCIDR(raw)
+	if err == nil {
+		return network.String(), nil
+	}
+
+	ip := net.ParseIP(raw)
+	if ip == nil {
+		return "", errors.Errorf("invalid ip address or network: %q", raw)
+	}
+
+	if ip.To4() != nil {
+		return ip.String() + "/32", nil
+	}
+
+	return ip.String() + "/128", nil
+}
+
+// ParseAllowedIPs parses a list of IP addresses or networks in CIDR notation
+// into networks in CIDR notation.
+func ParseAllowedIPs(raw []string) ([]string, error) {
+	var parsed []string
+	for _, allowedIP := range raw {
+		network, err := ParseAllowedIP(allowedIP)
+		if err != nil {
+			return nil, err
+		}
+
+		parsed = append(parsed, network)
+	}
+
+	return parsed, nil
+}
+
+// ServerFirewallRules returns the FirewallRules that allow reaching the Server
+// and the Deployments running on it. All other inbound traffic is denied.
+//
+// SSH is always public, as BlockPropeller manages Servers over it.
+// Restricted ports are reachable from the Server allow-list and the provided
+// management networks, and stay closed if there are none.
+// A port declared public by any Deployment is public.
+func ServerFirewallRules(srv *Server, managementIPs []string) ([]FirewallRule, error) {
+	public := map[Port]bool{SSHPort: true}
+	restricted := make(map[Port]bool)
+
+	for _, deployment := range srv.Deployments {
+		spec, err := GetDeploymentSpec(deployment.Type)
+		if err != nil {
+			return nil, errors.Wrap(err, "get deployment spec")
+		}
+
+		ports := spec.Ports(deployment)
+		for _, port := range ports.Public {
+			public[port] = true
+		}
+		for _, port := range ports.Restricted {
+			restricted[port] = true
+		}
+	}
+
+	var restrictedSources []string
+	for _, raw := range append(append([]string{}, srv.AllowedIPs...), managementIPs...) {
+		source, err := ParseAllowedIP(raw)
+		if err != nil {
+			return nil, err
+		}
+
+		restrictedSources = append(restrictedSources, source)
+	}
+
+	var rules []FirewallRule
+	for port := range public {
+		rules = append(rules, FirewallRule{Port: port, Sources: AnySource})
+	}
+	for port := range restricted {
+		if public[port] || len(restrictedSources) == 0 {
+			continue
+		}
+
+		rules = append(rules, FirewallRule{Port: port, Sources: restrictedSources})
+	}
+
+	sort.Slice(rules, func(i, j int) bool {
+		if rules[i].Port.Number != rules[j].Port.Number {
+			return rules[i].Port.Number < rules[j].Port.Number
+		}
+
+		return rules[i].Port.Protocol < rules[j].Port.Protocol
+	})
+
+	return rules, nil
+}
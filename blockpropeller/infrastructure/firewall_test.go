@@ -0,0 +1,109 @@
+package infrastructure_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"blockpropeller.dev/blockpropeller/binance"
+	"blockpropeller.dev/blockpropeller/infrastructure"
+	"blockpropeller.dev/lib/test"
+	"github.com/blang/semver"
+)
+
+func TestParseAllowedIP(t *testing.T) {
+	tests := []struct {
+		raw     string
+		want    string
+		wantErr bool
+	}{
+		{raw: "203.0.113.10", want: "203.0.113.10/32"},
+		{raw: "203.0.113.10/24", want: "203.0.113.0/24"},
+		{raw: "2001:db8::1", want: "2001:db8::1/128"},
+		{raw: "2001:db8::/32", want: "2001:db8::/32"},
+		{raw: "", wantErr: true},
+		{raw: "203.0.113", wantErr: true},
+		{raw: "203.0.113.10/33", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			got, err := infrastructure.ParseAllowedIP(tt.raw)
+			if tt.wantErr {
+				test.CheckErrExists(t, "ParseAllowedIP()", err)
+				return
+			}
+
+			test.CheckErr(t, "ParseAllowedIP()", err)
+			test.AssertStringsEqual(t, "ParseAllowedIP()", got, tt.want)
+		})
+	}
+}
+
+func TestServerFirewallRules(t *testing.T) {
+	tests := []struct {
+		name          string
+		nodeType      binance.NodeType
+		allowedIPs    []string
+		managementIPs []string
+		want          []string
+	}{
+		{
+			name:     "light node without allow-list",
+			nodeType: binance.TypeLightNode,
+			want:     []string{"22/tcp from 0.0.0.0/0,::/0"},
+		},
+		{
+			name:          "light node",
+			nodeType:      binance.TypeLightNode,
+			allowedIPs:    []string{"203.0.113.0/24"},
+			managementIPs: []string{"198.51.100.7"},
+			want: []string{
+				"22/tcp from 0.0.0.0/0,::/0",
+				"27147/tcp from 203.0.113.0/24,198.51.100.7/32",
+			},
+		},
+		{
+			name:          "full node",
+			nodeType:      binance.TypeFullNode,
+			managementIPs: []string{"198.51.100.7"},
+			want: []string{
+				"22/tcp from 0.0.0.0/0,::/0",
+				"27146/tcp from 0.0.0.0/0,::/0",
+				"27147/tcp from 198.51.100.7/32",
+				"28660/tcp from 198.51.100.7/32",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, err := infrastructure.NewServerBuilder("").
+				Provider(infrastructure.ProviderDigitalOcean).
+				AllowedIPs(tt.allowedIPs...).
+				Build()
+			test.CheckErr(t, "build server", err)
+
+			srv.AddDeployment(binance.NewNodeDeployment(binance.NetworkTest, tt.nodeType, semver.MustParse("0.6.1")))
+
+			rules, err := infrastructure.ServerFirewallRules(srv, tt.managementIPs)
+			test.CheckErr(t, "ServerFirewallRules()", err)
+
+			var got []string
+			for _, rule := range rules {
+				got = append(got, fmt.Sprintf("%d/%s from %s",
+					rule.Port.Number, rule.Port.Protocol, strings.Join(rule.Sources, ",")))
+			}
+
+			test.AssertStringsEqual(t, "ServerFirewallRules()", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+		})
+	}
+}
+
+func TestServerFirewallRulesInvalidManagementIP(t *testing.T) {
+	srv, err := infrastructure.NewServerBuilder("").
+		Provider(infrastructure.ProviderDigitalOcean).
+		Build()
+	test.CheckErr(t, "build server", err)
+
+	_, err = infrastructure.ServerFirewallRules(srv, []string{"not-an-ip"})
+	test.CheckErrExists(t, "ServerFirewallRules()", err)
+}
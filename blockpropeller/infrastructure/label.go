@@ -0,0 +1,291 @@
+package infrastructure
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// MaxLabels is the maximum number of labels a single resource can hold.
+	MaxLabels = 32
+	// MaxLabelLength is the maximum length of both label keys and values.
+	MaxLabelLength = 63
+)
+
+var (
+	// Labels are propagated as tags to every cloud provider, so their format
+	// is restricted to the subset accepted by all of them.
+	labelKeyPattern   = regexp.MustCompile(`^[a-z][a-z0-9_-]*$`)
+	labelValuePattern = regexp.MustCompile(`^[a-z0-9_-]*$`)
+)
+
+// Labels are user-defined key/value pairs used for grouping and filtering resources.
+//
+// Labels are propagated to the cloud providers as tags of the underlying infrastructure.
+type Labels map[string]string
+
+// ParseLabels parses Labels from a list of `key=value` pairs.
+func ParseLabels(pairs []string) (Labels, error) {
+	labels := make(Labels, len(pairs))
+	for _, pair := range pairs {
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 {
+			return nil, errors.Errorf("invalid label %q, expected key=value", pair)
+		}
+
+		labels[parts[0]] = parts[1]
+	}
+
+	err := labels.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	return labels, nil
+}
+
+// Validate checks that the Labels can be applied as tags with every cloud provider.
+func (l Labels) Validate() error {
+	if len(l) > MaxLabels {
+		return errors.Errorf("too many labels: %d, at most %d allowed", len(l), MaxLabels)
+	}
+
+	for key, value := range l {
+		if len(key) > MaxLabelLength || !labelKeyPattern.MatchString(key) {
+			return errors.Errorf("invalid label key: %q", key)
+		}
+		if len(value) > MaxLabelLength || !labelValuePattern.MatchString(value) {
+			return errors.Errorf("invalid value for label %s: %q", key, value)
+		}
+	}
+
+	return nil
+}
+
+// Merge returns new Labels holding the labels of both Labels,
+// where the provided labels take precedence.
+func (l Labels) Merge(other Labels) Labels {
+	merged := make(Labels, len(l)+len(other))
+	for key, value := range l {
+		merged[key] = value
+	}
+	for key, value := range other {
+		merged[key] = value
+	}
+
+	return merged
+}
+
+// Tags returns the Labels in the `key:value` format, sorted by key,
+// for providers that only support plain string tags.
+func (l Labels) Tags() []string {
+	var keys []string
+	for key := range l {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var tags []string
+	for _, key := range keys {
+		tags = append(tags, key+":"+l[key])
+	}
+
+	return tags
+}
+
+// String returns the Labels in the `key=value` format, sorted by key and delimited with a comma.
+func (l Labels) String() string {
+	var keys []string
+	for key := range l {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var pairs []string
+	for _, key := range keys {
+		pairs = append(pairs, key+"="+l[key])
+	}
+
+	return strings.Join(pairs, ",")
+}
+
+// Value satisfies the driver.Valuer interface.
+func (l Labels) Value() (driver.Value, error) {
+	if len(l) == 0 {
+		return "{}", nil
+	}
+
+	raw, err := json.Marshal(map[string]string(l))
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal labels")
+	}
+
+	return string(raw), nil
+}
+
+// Scan satisfies the sql.Scanner interface.
+func (l *Labels) Scan(src interface{}) error {
+	var raw []byte
+	switch src := src.(type) {
+	case nil:
+		*l = nil
+		return nil
+	case string:
+		raw = []byte(src)
+	case []byte:
+		raw = src
+	default:
+		return errors.Errorf("invalid labels type: %T", src)
+	}
+
+	var labels map[string]string
+	err := json.Unmarshal(raw, &labels)
+	if err != nil {
+		return errors.Wrap(err, "unmarshal labels")
+	}
+
+	*l = labels
+	if len(labels) == 0 {
+		*l = nil
+	}
+
+	return nil
+}
+
+// labelOperator is the way a LabelSelector requirement matches the value of a label.
+type labelOperator string
+
+const (
+	labelEquals    labelOperator = "="
+	labelNotEquals labelOperator = "!="
+	labelExists    labelOperator = "exists"
+	labelNotExists labelOperator = "!exists"
+)
+
+// labelRequirement is a single condition of a LabelSelector.
+type labelRequirement struct {
+	key      string
+	operator labelOperator
+	value    string
+}
+
+func (r labelRequirement) matches(labels Labels) bool {
+	value, ok := labels[r.key]
+
+	switch r.operator {
+	case labelEquals:
+		return ok && value == r.value
+	case labelNotEquals:
+		return !ok || value != r.value
+	case labelExists:
+		return ok
+	case labelNotExists:
+		return !ok
+	default:
+		return false
+	}
+}
+
+// LabelSelector filters resources by their Labels.
+//
+// Selectors are a comma delimited list of requirements, all of which must be satisfied:
+//
+//	`env=prod`   the label `env` has the value `prod`,
+//	`env!=prod`  the label `env` is missing or has a value other than `prod`,
+//	`env`        the label `env` is set,
+//	`!env`       the label `env` is not set.
+//
+// An empty LabelSelector matches every resource.
+type LabelSelector struct {
+	requirements []labelRequirement
+}
+
+// ParseLabelSelector parses a LabelSelector from its string representation.
+func ParseLabelSelector(raw string) (*LabelSelector, error) {
+	selector := &LabelSelector{}
+
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return selector, nil
+	}
+
+	for _, part := range strings.Split(raw, ",") {
+		req, err := parseLabelRequirement(strings.TrimSpace(part))
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid label selector %q", raw)
+		}
+
+		selector.requirements = append(selector.requirements, req)
+	}
+
+	return selector, nil
+}
+
+func parseLabelRequirement(raw string) (labelRequirement, error) {
+	var req labelRequirement
+
+	switch {
+	case strings.Contains(raw, "!="):
+		parts := strings.SplitN(raw, "!=", 2)
+		req = labelRequirement{key: parts[0], operator: labelNotEquals, value: parts[1]}
+	case strings.Contains(raw, "=="):
+		parts := strings.SplitN(raw, "==", 2)
+		req = labelRequirement{key: parts[0], operator: labelEquals, value: parts[1]}
+	case strings.Contains(raw, "="):
+		parts := strings.SplitN(raw, "=", 2)
+		req = labelRequirement{key: parts[0], operator: labelEquals, value: parts[1]}
+	case strings.HasPrefix(raw, "!"):
+		req = labelRequirement{key: strings.TrimPrefix(raw, "!"), operator: labelNotExists}
+	default:
+		req = labelRequirement{key: raw, operator: labelExists}
+	}
+
+	req.key = strings.TrimSpace(req.key)
+	req.value = strings.TrimSpace(req.value)
+
+	if !labelKeyPattern.MatchString(req.key) {
+		return req, errors.Errorf("invalid label key: %q", req.key)
+	}
+	if !labelValuePattern.MatchString(req.value) {
+		return req, errors.Errorf("invalid value for label %s: %q", req.key, req.value)
+	}
+
+	return req, nil
+}
+
+// Matches checks whether the Labels satisfy all the requirements of the LabelSelector.
+func (s *LabelSelector) Matches(labels Labels) bool {
+	for _, req := range s.requirements {
+		if !req.matches(labels) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// IsEmpty checks whether the LabelSelector matches every resource.
+func (s *LabelSelector) IsEmpty() bool {
+	return len(s.requirements) == 0
+}
+
+// FilterServers returns the Servers whose Labels match the LabelSelector.
+func (s *LabelSelector) FilterServers(servers []*Server) []*Server {
+	if s.IsEmpty() {
+		return servers
+	}
+
+	filtered := make([]*Server, 0, len(servers))
+	for _, srv := range servers {
+		if s.Matches(srv.Labels) {
+			filtered = append(filtered, srv)
+		}
+	}
+
+	return filtered
+}
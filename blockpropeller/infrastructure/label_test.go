@@ -0,0 +1,114 @@
+package infrastructure_test
+
+import (
+	"testing"
+
+	"blockpropeller.dev/blockpropeller/infrastructure"
+	"blockpropeller.dev/lib/test"
+)
+
+func TestLabelsValidate(t *testing.T) {
+	valid := infrastructure.Labels{"env": "prod", "team": "", "cost-center": "node_ops-1"}
+	test.CheckErr(t, "Validate()", valid.Validate())
+
+	for _, invalid := range []infrastructure.Labels{
+		{"Env": "prod"},
+		{"1env": "prod"},
+		{"env": "Prod"},
+		{"env": "prod/eu"},
+		{"": "prod"},
+	} {
+		test.CheckErrExists(t, "Validate() "+invalid.String(), invalid.Validate())
+	}
+
+	tooMany := infrastructure.Labels{}
+	for i := 0; i <= infrastructure.MaxLabels; i++ {
+		tooMany["label-"+string(rune('a'+i%26))+string(rune('a'+i/26))] = ""
+	}
+	test.CheckErrExists(t, "Validate() too many labels", tooMany.Validate())
+}
+
+func TestParseLabels(t *testing.T) {
+	labels, err := infrastructure.ParseLabels([]string{"env=prod", "team="})
+	test.CheckErr(t, "ParseLabels()", err)
+	test.AssertStringsEqual(t, "parsed labels", labels.String(), "env=prod,team=")
+
+	for _, invalid := range []string{"env", "Env=prod", "env=prod=eu"} {
+		_, err = infrastructure.ParseLabels([]string{invalid})
+		test.CheckErrExists(t, "ParseLabels("+invalid+")", err)
+	}
+}
+
+func TestLabelsFormatting(t *testing.T) {
+	labels := infrastructure.Labels{"team": "nodes", "env": "prod"}
+
+	test.AssertStringsEqual(t, "String()", labels.String(), "env=prod,team=nodes")
+	test.AssertStringsEqual(t, "Tags()", labels.Tags()[0]+" "+labels.Tags()[1], "env:prod team:nodes")
+
+	merged := labels.Merge(infrastructure.Labels{"env": "staging", "tier": "archive"})
+	test.AssertStringsEqual(t, "Merge()", merged.String(), "env=staging,team=nodes,tier=archive")
+	test.AssertStringsEqual(t, "Merge() leaves labels intact", labels.String(), "env=prod,team=nodes")
+}
+
+func TestLabelsScan(t *testing.T) {
+	labels := infrastructure.Labels{"env": "prod"}
+
+	value, err := labels.Value()
+	test.CheckErr(t, "Value()", err)
+
+	var scanned infrastructure.Labels
+	err = scanned.Scan(value)
+	test.CheckErr(t, "Scan()", err)
+	test.AssertStringsEqual(t, "scanned labels", scanned.String(), "env=prod")
+
+	err = scanned.Scan(nil)
+	test.CheckErr(t, "Scan() NULL", err)
+	test.AssertIntsEqual(t, "scanned NULL labels", len(scanned), 0)
+}
+
+func TestLabelSelector(t *testing.T) {
+	labels := infrastructure.Labels{"env": "prod", "team": "nodes"}
+
+	tests := []struct {
+		selector string
+		want     bool
+	}{
+		{selector: "", want: true},
+		{selector: "env=prod", want: true},
+		{selector: "env==prod", want: true},
+		{selector: "env=staging", want: false},
+		{selector: "env!=staging", want: true},
+		{selector: "tier!=archive", want: true},
+		{selector: "env=prod, team=nodes", want: true},
+		{selector: "env=prod,team=infra", want: false},
+		{selector: "team", want: true},
+		{selector: "tier", want: false},
+		{selector: "!tier", want: true},
+		{selector: "!team", want: false},
+	}
+	for _, tt := range tests {
+		selector, err := infrastructure.ParseLabelSelector(tt.selector)
+		test.CheckErr(t, "ParseLabelSelector("+tt.selector+")", err)
+
+		test.AssertBoolEqual(t, "Matches("+tt.selector+")", selector.Matches(labels), tt.want)
+	}
+
+	for _, invalid := range []string{"=prod", "env=Prod", "env,", "!", "Env"} {
+		_, err := infrastructure.ParseLabelSelector(invalid)
+		test.CheckErrExists(t, "ParseLabelSelector("+invalid+")", err)
+	}
+}
+
+func TestLabelSelectorFilterServers(t *testing.T) {
+	prod := &infrastructure.Server{Name: "prod", Labels: infrastructure.Labels{"env": "prod"}}
+	staging := &infrastructure.Server{Name: "staging", Labels: infrastructure.Labels{"env": "staging"}}
+	unlabeled := &infrastructure.Server{Name: "unlabeled"}
+
+	selector, err := infrastructure.ParseLabelSelector("env!=prod")
+	test.CheckErr(t, "ParseLabelSelector()", err)
+
+	filtered := selector.FilterServers([]*infrastructure.Server{prod, staging, unlabeled})
+	test.AssertIntsEqual(t, "filtered servers", len(filtered), 2)
+	test.AssertStringsEqual(t, "first server", filtered[0].Name, "staging")
+	test.AssertStringsEqual(t, "second server", filtered[1].Name, "unlabeled")
+}
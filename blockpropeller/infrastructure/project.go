@@ -0,0 +1,269 @@
+package infrastructure
+
+import (
+	"context"
+	"database/sql/driver"
+	"regexp"
+	"sync"
+	"time"
+
+	"blockpropeller.dev/blockpropeller/account"
+	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+)
+
+var (
+	// ErrProjectNotFound is returned when a ProjectRepository does not find a project to return.
+	ErrProjectNotFound = errors.New("project not found")
+	// ErrProjectAlreadyExists is returned when a Project creation is attempted with an existing ProjectID.
+	ErrProjectAlreadyExists = errors.New("project already exists")
+	// ErrProjectQuotaExceeded is returned when a Project is not allowed to hold any more Servers.
+	ErrProjectQuotaExceeded = errors.New("project server quota exceeded")
+
+	projectEnvironmentPattern = regexp.MustCompile(`^[a-z][a-z0-9-]*$`)
+)
+
+const (
+	// DefaultProjectName is the name of the Project every Account starts with.
+	DefaultProjectName = "Default"
+	// DefaultProjectEnvironment is the environment of the Project every Account starts with.
+	DefaultProjectEnvironment = "production"
+)
+
+// NilProjectID is an empty ProjectID.
+var NilProjectID ProjectID
+
+// ProjectID is a unique project identifier.
+type ProjectID string
+
+// NewProjectID returns a new unique ProjectID.
+func NewProjectID() ProjectID {
+	return ProjectID(uuid.NewV4().String())
+}
+
+// String satisfies the Stringer interface.
+func (id ProjectID) String() string {
+	return string(id)
+}
+
+// Value implements the sql.Valuer interface.
+//
+// A NilProjectID is stored as NULL, so resources created before projects
+// do not violate the foreign key constraint until they are migrated.
+func (id ProjectID) Value() (driver.Value, error) {
+	if id == NilProjectID {
+		return nil, nil
+	}
+
+	return string(id), nil
+}
+
+// Scan implements the sql.Scanner interface.
+func (id *ProjectID) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*id = NilProjectID
+	case string:
+		*id = ProjectID(v)
+	case []byte:
+		*id = ProjectID(v)
+	default:
+		return errors.New("invalid project id type")
+	}
+
+	return nil
+}
+
+// Project organizes the infrastructure of an Organization, such as the staging or production
+// environment of a product, and owns the Servers and ProviderSettings created within it.
+//
+// Every Organization has a default Project, which holds the resources created without a Project.
+type Project struct {
+	ID             ProjectID              `json:"id" gorm:"type:varchar(36) not null"`
+	OrganizationID account.OrganizationID `json:"organization_id,omitempty" gorm:"type:varchar(36) references organizations(id)"`
+	// AccountID of the Account that created the Project.
+	AccountID account.ID `json:"-" gorm:"type:varchar(36) not null references accounts(id)"`
+
+	Name        string `json:"name" gorm:"type:varchar(255) not null"`
+	Environment string `json:"environment" gorm:"type:varchar(100) not null"`
+	IsDefault   bool   `json:"is_default" gorm:"type:boolean not null;default:false"`
+
+	// DefaultProviderSettingsID and DefaultRegion are used for new Servers that do not specify their own.
+	DefaultProviderSettingsID ProviderSettingsID `json:"default_provider_settings_id,omitempty" gorm:"type:varchar(36)"`
+	DefaultRegion             string             `json:"default_region,omitempty" gorm:"type:varchar(100)"`
+
+	// MaxServers limits the number of Servers in the Project, zero for no limit.
+	MaxServers int `json:"max_servers" gorm:"type:integer not null;default:0"`
+
+	CreatedAt time.Time  `json:"created_at" gorm:"type:timestamp not null;default:CURRENT_TIMESTAMP"`
+	UpdatedAt time.Time  `json:"updated_at" gorm:"type:timestamp not null;default:CURRENT_TIMESTAMP"`
+	DeletedAt *time.Time `json:"-" gorm:"type:timestamp"`
+}
+
+// NewProject returns a new Project instance, created within an Organization by the provided Account.
+func NewProject(orgID account.OrganizationID, accountID account.ID, name string, environment string) *Project {
+	return &Project{
+		ID:             NewProjectID(),
+		OrganizationID: orgID,
+		AccountID:      accountID,
+
+		Name:        name,
+		Environment: environment,
+
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+}
+
+// NewDefaultProject returns the Project every Organization starts with.
+func NewDefaultProject(orgID account.OrganizationID, accountID account.ID) *Project {
+	project := NewProject(orgID, accountID, DefaultProjectName, DefaultProjectEnvironment)
+	project.IsDefault = true
+
+	return project
+}
+
+// Validate checks that the Project is well formed.
+func (p *Project) Validate() error {
+	if p.Name == "" || len(p.Name) > 255 {
+		return errors.Errorf("invalid project name: %q", p.Name)
+	}
+	if len(p.Environment) > 63 || !projectEnvironmentPattern.MatchString(p.Environment) {
+		return errors.Errorf("invalid project environment: %q", p.Environment)
+	}
+	if p.MaxServers < 0 {
+		return errors.New("project server quota must be positive")
+	}
+
+	return nil
+}
+
+// CheckServerQuota checks whether another Server can be added to a Project holding the provided number of Servers.
+func (p *Project) CheckServerQuota(servers int) error {
+	if p.MaxServers > 0 && servers >= p.MaxServers {
+		return errors.Wrapf(ErrProjectQuotaExceeded, "project %s holds %d of %d servers", p.ID, servers, p.MaxServers)
+	}
+
+	return nil
+}
+
+// DefaultProject returns the default Project of an Organization, creating it for Organizations that have none yet.
+//
+// Missing default Projects are created on behalf of the provided Account.
+func DefaultProject(ctx context.Context, repo ProjectRepository, orgID account.OrganizationID, accountID account.ID) (*Project, error) {
+	project, err := repo.FindDefault(ctx, orgID)
+	if err == nil {
+		return project, nil
+	}
+	if errors.Cause(err) != ErrProjectNotFound {
+		return nil, errors.Wrap(err, "find default project")
+	}
+
+	project = NewDefaultProject(orgID, accountID)
+
+	err = repo.Create(ctx, project)
+	if err != nil {
+		return nil, errors.Wrap(err, "create default project")
+	}
+
+	return project, nil
+}
+
+// ProjectRepository defines an interface for storing and retrieving projects.
+type ProjectRepository interface {
+	// Find a Project given a ProjectID.
+	Find(ctx context.Context, id ProjectID) (*Project, error)
+	// FindDefault returns the default Project of an Organization.
+	FindDefault(ctx context.Context, orgID account.OrganizationID) (*Project, error)
+	// List all projects for a particular Organization.
+	List(ctx context.Context, orgID account.OrganizationID) ([]*Project, error)
+
+	// Create a new Project.
+	Create(ctx context.Context, project *Project) error
+
+	// Update an existing Project.
+	Update(ctx context.Context, project *Project) error
+
+	// Delete an existing Project.
+	Delete(ctx context.Context, project *Project) error
+}
+
+// InMemoryProjectRepository holds the projects inside an in-memory map.
+//
+// Projects are not persisted on disk and won't survive program restarts.
+type InMemoryProjectRepository struct {
+	projects sync.Map
+}
+
+// NewInMemoryProjectRepository returns a new InMemoryProjectRepository instance.
+func NewInMemoryProjectRepository() *InMemoryProjectRepository {
+	return &InMemoryProjectRepository{}
+}
+
+// Find a Project given a ProjectID.
+func (repo *InMemoryProjectRepository) Find(ctx context.Context, id ProjectID) (*Project, error) {
+	project, ok := repo.projects.Load(id)
+	if !ok {
+		return nil, ErrProjectNotFound
+	}
+
+	return project.(*Project), nil
+}
+
+// FindDefault returns the default Project of an Organization.
+func (repo *InMemoryProjectRepository) FindDefault(ctx context.Context, orgID account.OrganizationID) (*Project, error) {
+	projects := repo.filter(func(project *Project) bool {
+		return project.OrganizationID == orgID && project.IsDefault
+	})
+	if len(projects) == 0 {
+		return nil, ErrProjectNotFound
+	}
+
+	return projects[0], nil
+}
+
+// List all projects for a particular Organization.
+func (repo *InMemoryProjectRepository) List(ctx context.Context, orgID account.OrganizationID) ([]*Project, error) {
+	return repo.filter(func(project *Project) bool {
+		return project.OrganizationID == orgID
+	}), nil
+}
+
+// Create a new Project.
+func (repo *InMemoryProjectRepository) Create(ctx context.Context, project *Project) error {
+	_, loaded := repo.projects.LoadOrStore(project.ID, project)
+	if loaded {
+		return ErrProjectAlreadyExists
+	}
+
+	return nil
+}
+
+// Update an existing Project.
+func (repo *InMemoryProjectRepository) Update(ctx context.Context, project *Project) error {
+	repo.projects.Store(project.ID, project)
+
+	return nil
+}
+
+// Delete an existing Project.
+func (repo *InMemoryProjectRepository) Delete(ctx context.Context, project *Project) error {
+	repo.projects.Delete(project.ID)
+
+	return nil
+}
+
+func (repo *InMemoryProjectRepository) filter(match func(project *Project) bool) []*Project {
+	var projects []*Project
+
+	repo.projects.Range(func(key, v interface{}) bool {
+		project := v.(*Project)
+		if match(project) {
+			projects = append(projects, project)
+		}
+
+		return true
+	})
+
+	return projects
+}
@@ -0,0 +1,64 @@
+package infrastructure_test
+
+import (
+	"context"
+	"testing"
+
+	"blockpropeller.dev/blockpropeller/account"
+	"blockpropeller.dev/blockpropeller/infrastructure"
+	"blockpropeller.dev/lib/test"
+	"github.com/pkg/errors"
+)
+
+func TestProjectValidate(t *testing.T) {
+	org := account.NewOrganization(account.NewID(), "Acme")
+
+	test.CheckErr(t, "Validate() default project", infrastructure.NewDefaultProject(org.ID, org.AccountID).Validate())
+	test.CheckErr(t, "Validate()", infrastructure.NewProject(org.ID, org.AccountID, "Nodes", "staging-eu").Validate())
+
+	for _, invalid := range []*infrastructure.Project{
+		infrastructure.NewProject(org.ID, org.AccountID, "", "staging"),
+		infrastructure.NewProject(org.ID, org.AccountID, "Nodes", ""),
+		infrastructure.NewProject(org.ID, org.AccountID, "Nodes", "Staging"),
+		infrastructure.NewProject(org.ID, org.AccountID, "Nodes", "1staging"),
+		infrastructure.NewProject(org.ID, org.AccountID, "Nodes", "staging/eu"),
+	} {
+		test.CheckErrExists(t, "Validate() "+invalid.Name+" "+invalid.Environment, invalid.Validate())
+	}
+
+	negativeQuota := infrastructure.NewProject(org.ID, org.AccountID, "Nodes", "staging")
+	negativeQuota.MaxServers = -1
+	test.CheckErrExists(t, "Validate() negative quota", negativeQuota.Validate())
+}
+
+func TestProjectCheckServerQuota(t *testing.T) {
+	project := infrastructure.NewProject(account.NewOrganizationID(), account.NewID(), "Nodes", "staging")
+	test.CheckErr(t, "CheckServerQuota() unlimited", project.CheckServerQuota(100))
+
+	project.MaxServers = 2
+	test.CheckErr(t, "CheckServerQuota() below quota", project.CheckServerQuota(1))
+
+	err := project.CheckServerQuota(2)
+	test.AssertBoolEqual(t, "quota exceeded", errors.Cause(err) == infrastructure.ErrProjectQuotaExceeded, true)
+}
+
+func TestDefaultProject(t *testing.T) {
+	ctx := context.Background()
+	repo := infrastructure.NewInMemoryProjectRepository()
+	org := account.NewOrganization(account.NewID(), "Acme")
+
+	err := repo.Create(ctx, infrastructure.NewProject(org.ID, org.AccountID, "Nodes", "staging"))
+	test.CheckErr(t, "create project", err)
+
+	project, err := infrastructure.DefaultProject(ctx, repo, org.ID, org.AccountID)
+	test.CheckErr(t, "DefaultProject() creates project", err)
+	test.AssertBoolEqual(t, "project is default", project.IsDefault, true)
+
+	existing, err := infrastructure.DefaultProject(ctx, repo, org.ID, org.AccountID)
+	test.CheckErr(t, "DefaultProject() finds project", err)
+	test.AssertStringsEqual(t, "same default project", existing.ID.String(), project.ID.String())
+
+	projects, err := repo.List(ctx, org.ID)
+	test.CheckErr(t, "list projects", err)
+	test.AssertIntsEqual(t, "organization projects", len(projects), 2)
+}
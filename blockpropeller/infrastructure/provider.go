@@ -3,9 +3,19 @@ package infrastructure
 var (
 	// ProviderDigitalOcean is the ProviderType for DigitalOcean cloud provider.
 	ProviderDigitalOcean ProviderType = "digitalocean"
+	// ProviderAWS is the ProviderType for Amazon Web Services cloud provider.
+	ProviderAWS ProviderType = "aws"
+	// ProviderHetzner is the ProviderType for Hetzner Cloud provider.
+	ProviderHetzner ProviderType = "hcloud"
+	// ProviderGoogleCloud is the ProviderType for Google Cloud Platform provider.
+	ProviderGoogleCloud ProviderType = "gcp"
+	// ProviderBYO is the ProviderType for existing hosts brought in by the user.
+	ProviderBYO ProviderType = "byo"
+	// ProviderLocal is the ProviderType for containers on a Docker daemon, meant for development and testing.
+	ProviderLocal ProviderType = "local"
 
 	// ValidProviders that are recognized by BlockPropeller.
-	ValidProviders = []ProviderType{ProviderDigitalOcean}
+	ValidProviders = []ProviderType{ProviderDigitalOcean, ProviderAWS, ProviderHetzner, ProviderGoogleCloud, ProviderBYO, ProviderLocal}
 )
 
 // ProviderType that is able to provision new infrastructure.
@@ -39,8 +39,10 @@ func (id ProviderSettingsID) String() string {
 // has setup for his account. Only providers with valid settings can be used
 // to provision new infrastructure.
 type ProviderSettings struct {
-	ID        ProviderSettingsID `json:"id" gorm:"varchar(36) not null"`
-	AccountID account.ID         `json:"-" gorm:"type:varchar(36) not null references accounts(id)" `
+	ID             ProviderSettingsID     `json:"id" gorm:"varchar(36) not null"`
+	AccountID      account.ID             `json:"-" gorm:"type:varchar(36) not null references accounts(id)" `
+	OrganizationID account.OrganizationID `json:"organization_id,omitempty" gorm:"type:varchar(36) references organizations(id)"`
+	ProjectID      ProjectID              `json:"project_id,omitempty" gorm:"type:varchar(36) references projects(id)"`
 
 	Label string `json:"label" gorm:"type:varchar(255) not null"`
 
@@ -48,6 +50,10 @@ type ProviderSettings struct {
 
 	Credentials string `json:"-" gorm:"type:text not null"`
 
+	CredentialsValid bool       `json:"credentials_valid" gorm:"type:boolean not null;default:false"`
+	ValidationError  string     `json:"validation_error,omitempty" gorm:"type:text"`
+	ValidatedAt      *time.Time `json:"validated_at,omitempty" gorm:"type:timestamp"`
+
 	CreatedAt time.Time  `json:"created_at" gorm:"type:timestamp not null;default:CURRENT_TIMESTAMP"`
 	UpdatedAt time.Time  `json:"updated_at" gorm:"type:timestamp not null;default:CURRENT_TIMESTAMP"`
 	DeletedAt *time.Time `json:"-" gorm:"type:timestamp"`
@@ -69,6 +75,20 @@ func NewProviderSettings(accountID account.ID, label string, providerType Provid
 	}
 }
 
+// SetValidationResult records the outcome of validating the credentials with the provider.
+//
+// A nil error marks the credentials as valid.
+func (ps *ProviderSettings) SetValidationResult(err error) {
+	now := time.Now()
+
+	ps.CredentialsValid = err == nil
+	ps.ValidationError = ""
+	if err != nil {
+		ps.ValidationError = err.Error()
+	}
+	ps.ValidatedAt = &now
+}
+
 // BeforeSave encrypts the sensitive information before inserting them to the database.
 func (ps *ProviderSettings) BeforeSave() error {
 	encrypted, err := encryption.Encrypt([]byte(ps.Credentials))
@@ -111,6 +131,12 @@ type ProviderSettingsRepository interface {
 	// List all provider settings for a particular owner.
 	List(ctx context.Context, ownerID account.ID) ([]*ProviderSettings, error)
 
+	// ListByOrganization lists all provider settings owned by a particular Organization.
+	ListByOrganization(ctx context.Context, orgID account.OrganizationID) ([]*ProviderSettings, error)
+
+	// ListByProject lists all provider settings organized under a particular Project.
+	ListByProject(ctx context.Context, projectID ProjectID) ([]*ProviderSettings, error)
+
 	// Find a ProviderSettings given a ProviderSettingsID.
 	Find(ctx context.Context, id ProviderSettingsID) (*ProviderSettings, error)
 
@@ -153,6 +179,40 @@ func (repo *InMemoryProviderSettingsRepository) List(ctx context.Context, ownerI
 	return settings, nil
 }
 
+// ListByOrganization lists all provider settings owned by a particular Organization.
+func (repo *InMemoryProviderSettingsRepository) ListByOrganization(ctx context.Context, orgID account.OrganizationID) ([]*ProviderSettings, error) {
+	var settings []*ProviderSettings
+	repo.providerSettings.Range(func(k, v interface{}) bool {
+		setting := v.(*ProviderSettings)
+		if setting.OrganizationID != orgID {
+			return true
+		}
+
+		settings = append(settings, setting)
+
+		return true
+	})
+
+	return settings, nil
+}
+
+// ListByProject lists all provider settings organized under a particular Project.
+func (repo *InMemoryProviderSettingsRepository) ListByProject(ctx context.Context, projectID ProjectID) ([]*ProviderSettings, error) {
+	var settings []*ProviderSettings
+	repo.providerSettings.Range(func(k, v interface{}) bool {
+		setting := v.(*ProviderSettings)
+		if setting.ProjectID != projectID {
+			return true
+		}
+
+		settings = append(settings, setting)
+
+		return true
+	})
+
+	return settings, nil
+}
+
 // Find a ProviderSettings given a ProviderSettingsID.
 func (repo *InMemoryProviderSettingsRepository) Find(ctx context.Context, id ProviderSettingsID) (*ProviderSettings, error) {
 	req, ok := repo.providerSettings.Load(id)
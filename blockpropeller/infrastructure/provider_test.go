@@ -13,6 +13,11 @@ func TestProviderTypeIsValid(t *testing.T) {
 		valid bool
 	}{
 		{"digitalocean", true},
+		{"aws", true},
+		{"hcloud", true},
+		{"gcp", true},
+		{"byo", true},
+		{"local", true},
 		{"", false},
 		{"superprovider", false},
 	}
@@ -0,0 +1,224 @@
+package infrastructure
+
+import (
+	"context"
+	"database/sql/driver"
+	"sync"
+	"time"
+
+	"blockpropeller.dev/blockpropeller/account"
+	"blockpropeller.dev/blockpropeller/terraform"
+	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+)
+
+var (
+	// ErrReservedIPNotFound is returned when a ReservedIPRepository does not find a reserved IP to return.
+	ErrReservedIPNotFound = errors.New("reserved ip not found")
+	// ErrReservedIPAlreadyExists is returned when a ReservedIP creation is attempted with an existing ReservedIPID.
+	ErrReservedIPAlreadyExists = errors.New("reserved ip already exists")
+)
+
+// NilReservedIPID is an empty ReservedIPID.
+var NilReservedIPID ReservedIPID
+
+// ReservedIPID is a unique reserved IP identifier.
+type ReservedIPID string
+
+// NewReservedIPID returns a new unique ReservedIPID.
+func NewReservedIPID() ReservedIPID {
+	return ReservedIPID(uuid.NewV4().String())
+}
+
+// String satisfies the Stringer interface.
+func (id ReservedIPID) String() string {
+	return string(id)
+}
+
+// Value implements the sql.Valuer interface.
+//
+// A NilReservedIPID is stored as NULL, so Servers without a reserved IP
+// do not violate the foreign key constraint.
+func (id ReservedIPID) Value() (driver.Value, error) {
+	if id == NilReservedIPID {
+		return nil, nil
+	}
+
+	return string(id), nil
+}
+
+// Scan implements the sql.Scanner interface.
+func (id *ReservedIPID) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*id = NilReservedIPID
+	case string:
+		*id = ReservedIPID(v)
+	case []byte:
+		*id = ReservedIPID(v)
+	default:
+		return errors.New("invalid reserved ip id type")
+	}
+
+	return nil
+}
+
+// ReservedIP is a static IP address allocated with a cloud provider,
+// such as a DigitalOcean floating IP or an AWS Elastic IP.
+//
+// A ReservedIP outlives the Servers it is attached to, so a replaced Server
+// can take over the address of the Server it replaces.
+type ReservedIP struct {
+	ID             ReservedIPID           `json:"id" gorm:"type:varchar(36) not null"`
+	AccountID      account.ID             `json:"-" gorm:"type:varchar(36) not null references accounts(id)"`
+	OrganizationID account.OrganizationID `json:"organization_id,omitempty" gorm:"type:varchar(36) references organizations(id)"`
+
+	Provider           ProviderType       `json:"provider" gorm:"type:varchar(100) not null"`
+	ProviderSettingsID ProviderSettingsID `json:"provider_settings_id" gorm:"type:varchar(36) not null references provider_settings(id)"`
+	Region             string             `json:"region" gorm:"type:varchar(100) not null"`
+
+	// Address is the allocated IP address.
+	Address string `json:"ip_address,omitempty" gorm:"type:varchar(255)"`
+	// ProviderID identifies the address with the cloud provider when attaching it to a Server.
+	ProviderID string `json:"-" gorm:"type:varchar(255)"`
+
+	WorkspaceSnapshot *terraform.WorkspaceSnapshot `json:"-" gorm:"embedded;embedded_prefix:terraform_"`
+
+	CreatedAt time.Time  `json:"created_at" gorm:"type:timestamp not null;default:CURRENT_TIMESTAMP"`
+	UpdatedAt time.Time  `json:"updated_at" gorm:"type:timestamp not null;default:CURRENT_TIMESTAMP"`
+	DeletedAt *time.Time `json:"-" gorm:"type:timestamp"`
+}
+
+// NewReservedIP returns a new ReservedIP instance, to be allocated in the given region
+// and owned by the Organization of the provider settings.
+func NewReservedIP(accountID account.ID, settings *ProviderSettings, region string) *ReservedIP {
+	return &ReservedIP{
+		ID:             NewReservedIPID(),
+		AccountID:      accountID,
+		OrganizationID: settings.OrganizationID,
+
+		Provider:           settings.Type,
+		ProviderSettingsID: settings.ID,
+		Region:             region,
+
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+}
+
+// IsAllocated checks whether the ReservedIP has been allocated with the cloud provider.
+func (ip *ReservedIP) IsAllocated() bool {
+	return ip.Address != ""
+}
+
+// AfterFind GORM Hook.
+func (ip *ReservedIP) AfterFind() error {
+	if ip.WorkspaceSnapshot != nil && ip.WorkspaceSnapshot.WorkspacePath == "" {
+		ip.WorkspaceSnapshot = nil
+	}
+
+	return nil
+}
+
+// ReservedIPRepository defines an interface for storing and retrieving reserved IPs.
+type ReservedIPRepository interface {
+	// Find a ReservedIP given a ReservedIPID.
+	Find(ctx context.Context, id ReservedIPID) (*ReservedIP, error)
+
+	// List all reserved IPs for a particular Account.
+	List(ctx context.Context, accountID account.ID) ([]*ReservedIP, error)
+
+	// ListByOrganization lists all reserved IPs owned by a particular Organization.
+	ListByOrganization(ctx context.Context, orgID account.OrganizationID) ([]*ReservedIP, error)
+
+	// Create a new ReservedIP.
+	Create(ctx context.Context, ip *ReservedIP) error
+
+	// Update an existing ReservedIP.
+	Update(ctx context.Context, ip *ReservedIP) error
+
+	// Delete an existing ReservedIP.
+	Delete(ctx context.Context, ip *ReservedIP) error
+}
+
+// InMemoryReservedIPRepository holds the reserved IPs inside an in-memory map.
+//
+// Reserved IPs are not persisted on disk and won't survive program restarts.
+type InMemoryReservedIPRepository struct {
+	ips sync.Map
+}
+
+// NewInMemoryReservedIPRepository returns a new InMemoryReservedIPRepository instance.
+func NewInMemoryReservedIPRepository() *InMemoryReservedIPRepository {
+	return &InMemoryReservedIPRepository{}
+}
+
+// Find a ReservedIP given a ReservedIPID.
+func (repo *InMemoryReservedIPRepository) Find(ctx context.Context, id ReservedIPID) (*ReservedIP, error) {
+	ip, ok := repo.ips.Load(id)
+	if !ok {
+		return nil, ErrReservedIPNotFound
+	}
+
+	return ip.(*ReservedIP), nil
+}
+
+// List all reserved IPs for a particular Account.
+func (repo *InMemoryReservedIPRepository) List(ctx context.Context, accountID account.ID) ([]*ReservedIP, error) {
+	var ips []*ReservedIP
+
+	repo.ips.Range(func(key, v interface{}) bool {
+		ip := v.(*ReservedIP)
+		if ip.AccountID != accountID {
+			return true
+		}
+
+		ips = append(ips, ip)
+
+		return true
+	})
+
+	return ips, nil
+}
+
+// ListByOrganization lists all reserved IPs owned by a particular Organization.
+func (repo *InMemoryReservedIPRepository) ListByOrganization(ctx context.Context, orgID account.OrganizationID) ([]*ReservedIP, error) {
+	var ips []*ReservedIP
+
+	repo.ips.Range(func(key, v interface{}) bool {
+		ip := v.(*ReservedIP)
+		if ip.OrganizationID != orgID {
+			return true
+		}
+
+		ips = append(ips, ip)
+
+		return true
+	})
+
+	return ips, nil
+}
+
+// Create a new ReservedIP.
+func (repo *InMemoryReservedIPRepository) Create(ctx context.Context, ip *ReservedIP) error {
+	_, loaded := repo.ips.LoadOrStore(ip.ID, ip)
+	if loaded {
+		return ErrReservedIPAlreadyExists
+	}
+
+	return nil
+}
+
+// Update an existing ReservedIP.
+func (repo *InMemoryReservedIPRepository) Update(ctx context.Context, ip *ReservedIP) error {
+	repo.ips.Store(ip.ID, ip)
+
+	return nil
+}
+
+// Delete an existing ReservedIP.
+func (repo *InMemoryReservedIPRepository) Delete(ctx context.Context, ip *ReservedIP) error {
+	repo.ips.Delete(ip.ID)
+
+	return nil
+}
@@ -2,6 +2,7 @@ package infrastructure
 
 import (
 	"context"
+	"strings"
 	"sync"
 	"time"
 
@@ -45,11 +46,19 @@ func (id ServerID) String() string {
 // ServerBuilder helps construct provisioning servers by providing
 // a fluent interface for configuring server details.
 type ServerBuilder struct {
-	accountID account.ID
-	name      string
-	provider  ProviderType
-	size      ServerSize
-	sshKey    *SSHKey
+	accountID    account.ID
+	orgID        account.OrganizationID
+	projectID    ProjectID
+	name         string
+	provider     ProviderType
+	size         ServerSize
+	region       string
+	instanceType string
+	allowedIPs   []string
+	labels       Labels
+	reservedIP   *ReservedIP
+	snapshot     *VolumeSnapshot
+	sshKey       *SSHKey
 }
 
 // NewServerBuilder starts the process of building a server.
@@ -80,6 +89,64 @@ func (b *ServerBuilder) Size(size ServerSize) *ServerBuilder {
 	return b
 }
 
+// Region configures the provider region in which to provision the server.
+//
+// Servers without a region are provisioned in the default region of the provider.
+func (b *ServerBuilder) Region(region string) *ServerBuilder {
+	b.region = region
+
+	return b
+}
+
+// InstanceType configures the provider specific instance type of the server,
+// taking precedence over the instance type derived from the server size.
+func (b *ServerBuilder) InstanceType(instanceType string) *ServerBuilder {
+	b.instanceType = instanceType
+
+	return b
+}
+
+// AllowedIPs configures the addresses allowed to reach the restricted ports of the server.
+func (b *ServerBuilder) AllowedIPs(allowedIPs ...string) *ServerBuilder {
+	b.allowedIPs = allowedIPs
+
+	return b
+}
+
+// Project configures the project the server is organized under,
+// making the server owned by the Organization of the project.
+func (b *ServerBuilder) Project(project *Project) *ServerBuilder {
+	b.orgID = project.OrganizationID
+	b.projectID = project.ID
+
+	return b
+}
+
+// Labels configures the user-defined labels of the server.
+func (b *ServerBuilder) Labels(labels Labels) *ServerBuilder {
+	b.labels = labels
+
+	return b
+}
+
+// ReservedIP configures the reserved IP address attached to the server.
+//
+// Servers without a region are provisioned in the region of the reserved IP.
+func (b *ServerBuilder) ReservedIP(ip *ReservedIP) *ServerBuilder {
+	b.reservedIP = ip
+
+	return b
+}
+
+// VolumeSnapshot configures the snapshot the data volume of the server is created from.
+//
+// Servers without a region are provisioned in the region of the snapshot.
+func (b *ServerBuilder) VolumeSnapshot(snap *VolumeSnapshot) *ServerBuilder {
+	b.snapshot = snap
+
+	return b
+}
+
 // SSHKey configures the SSHKey used to access the server after provisioning.
 func (b *ServerBuilder) SSHKey(sshKey *SSHKey) *ServerBuilder {
 	b.sshKey = sshKey
@@ -107,24 +174,83 @@ func (b *ServerBuilder) Build() (*Server, error) {
 		b.sshKey = sshKey
 	}
 
-	return NewServer(b.accountID, b.name, b.provider, b.size, b.sshKey), nil
+	srv := NewServer(b.accountID, b.name, b.provider, b.size, b.sshKey)
+	srv.OrganizationID = b.orgID
+	srv.ProjectID = b.projectID
+	srv.Region = b.region
+	srv.InstanceType = b.instanceType
+
+	err := srv.SetAllowedIPs(b.allowedIPs)
+	if err != nil {
+		return nil, errors.Wrap(err, "set allowed ips")
+	}
+
+	err = srv.SetLabels(b.labels)
+	if err != nil {
+		return nil, errors.Wrap(err, "set labels")
+	}
+
+	if b.reservedIP != nil {
+		srv.AttachReservedIP(b.reservedIP)
+
+		if srv.Region == "" {
+			srv.Region = b.reservedIP.Region
+		}
+	}
+
+	if b.snapshot != nil {
+		srv.RestoreVolumeSnapshot(b.snapshot)
+
+		if srv.Region == "" {
+			srv.Region = b.snapshot.Region
+		}
+	}
+
+	return srv, nil
 }
 
 // Server holds all the configuration values for a single provisioning server.
 type Server struct {
-	ID        ServerID   `json:"id" gorm:"type:varchar(36) not null"`
-	AccountID account.ID `json:"-" gorm:"type:varchar(36) not null references accounts(id)" `
+	ID             ServerID               `json:"id" gorm:"type:varchar(36) not null"`
+	AccountID      account.ID             `json:"-" gorm:"type:varchar(36) not null references accounts(id)" `
+	OrganizationID account.OrganizationID `json:"organization_id,omitempty" gorm:"type:varchar(36) references organizations(id)"`
+	ProjectID      ProjectID              `json:"project_id,omitempty" gorm:"type:varchar(36) references projects(id)"`
 
 	State ServerState `json:"state" gorm:"type:varchar(20) not null"`
 
-	Name string `json:"name" gorm:"type:varchar(255) not null"`
+	Name   string `json:"name" gorm:"type:varchar(255) not null"`
+	Labels Labels `json:"labels,omitempty" gorm:"type:text"`
+
+	Provider           ProviderType       `json:"provider" gorm:"type:varchar(100) not null"`
+	ProviderSettingsID ProviderSettingsID `json:"provider_settings_id,omitempty" gorm:"type:varchar(36) references provider_settings(id)"`
+	Size               ServerSize         `json:"size" gorm:"type:varchar(100) not null"`
+
+	Region       string `json:"region,omitempty" gorm:"type:varchar(100)"`
+	InstanceType string `json:"instance_type,omitempty" gorm:"type:varchar(100)"`
+
+	// VolumeSize of the data volume in GB, zero for Servers without a data volume.
+	VolumeSize     int            `json:"volume_size,omitempty" gorm:"type:integer not null;default:0"`
+	DiskUsage      DiskUsage      `json:"disk_usage" gorm:"embedded;embedded_prefix:disk_"`
+	DiskThresholds DiskThresholds `json:"disk_thresholds" gorm:"embedded;embedded_prefix:disk_"`
+	SnapshotPolicy SnapshotPolicy `json:"snapshot_policy" gorm:"embedded;embedded_prefix:snapshot_"`
 
-	Provider ProviderType `json:"provider" gorm:"type:varchar(100) not null"`
-	Size     ServerSize   `json:"size" gorm:"type:varchar(100) not null"`
+	// VolumeID identifies the data volume with the cloud provider when taking snapshots of it.
+	VolumeID string `json:"-" gorm:"type:varchar(255)"`
+
+	// VolumeSnapshotID references the snapshot the data volume was created from.
+	VolumeSnapshotID VolumeSnapshotID `json:"volume_snapshot_id,omitempty" gorm:"type:varchar(36)"`
+	VolumeSnapshot   *VolumeSnapshot  `json:"-" gorm:"foreignkey:VolumeSnapshotID;association_autoupdate:false;association_autocreate:false"`
 
 	SSHKey *SSHKey `json:"ssh_key" gorm:"embedded;embedded_prefix:ssh_key_"`
 
 	IPAddress string `json:"ip_address,omitempty" gorm:"type:varchar(255)"`
+	SSHPort   int    `json:"ssh_port,omitempty" gorm:"type:integer"`
+
+	AllowedIPs    []string `json:"allowed_ips" gorm:"-"`
+	RawAllowedIPs string   `json:"-" gorm:"column:allowed_ips;type:text"`
+
+	ReservedIPID ReservedIPID `json:"reserved_ip_id,omitempty" gorm:"type:varchar(36) references reserved_ips(id)"`
+	ReservedIP   *ReservedIP  `json:"reserved_ip,omitempty" gorm:"association_autoupdate:false;association_autocreate:false"`
 
 	Deployments []*Deployment `json:"deployments,omitempty"`
 
@@ -157,12 +283,94 @@ func NewServer(accountID account.ID, name string, provider ProviderType, size Se
 	}
 }
 
+// BeforeSave GORM Hook.
+func (srv *Server) BeforeSave() error {
+	srv.RawAllowedIPs = strings.Join(srv.AllowedIPs, ",")
+
+	return nil
+}
+
 // AfterFind GORM Hook.
 func (srv *Server) AfterFind() error {
 	if srv.WorkspaceSnapshot != nil && srv.WorkspaceSnapshot.WorkspacePath == "" {
 		srv.WorkspaceSnapshot = nil
 	}
 
+	srv.AllowedIPs = nil
+	if srv.RawAllowedIPs != "" {
+		srv.AllowedIPs = strings.Split(srv.RawAllowedIPs, ",")
+	}
+
+	return nil
+}
+
+// SetAllowedIPs replaces the allow-list of addresses able to reach the restricted ports of the Server.
+//
+// Addresses are IPs or networks in CIDR notation, and are stored in CIDR notation.
+func (srv *Server) SetAllowedIPs(allowedIPs []string) error {
+	parsed, err := ParseAllowedIPs(allowedIPs)
+	if err != nil {
+		return err
+	}
+
+	srv.AllowedIPs = parsed
+
+	return nil
+}
+
+// SetLabels replaces the user-defined labels of the Server.
+func (srv *Server) SetLabels(labels Labels) error {
+	err := labels.Validate()
+	if err != nil {
+		return err
+	}
+
+	srv.Labels = labels
+
+	return nil
+}
+
+// ResourceTags returns the labels applied as tags to the cloud resources of the Server.
+//
+// Labels of the Deployments running on the Server are included,
+// while labels of the Server itself take precedence.
+func (srv *Server) ResourceTags() Labels {
+	tags := Labels{}
+	for _, deployment := range srv.Deployments {
+		tags = tags.Merge(deployment.Labels)
+	}
+
+	return tags.Merge(srv.Labels)
+}
+
+// AttachReservedIP routes the traffic for a reserved IP address to the Server.
+func (srv *Server) AttachReservedIP(ip *ReservedIP) {
+	srv.ReservedIPID = ip.ID
+	srv.ReservedIP = ip
+}
+
+// RestoreVolumeSnapshot creates the data volume of the Server from the provided snapshot.
+//
+// The data volume is at least as large as the volume the snapshot was taken of.
+func (srv *Server) RestoreVolumeSnapshot(snap *VolumeSnapshot) {
+	srv.VolumeSnapshotID = snap.ID
+	srv.VolumeSnapshot = snap
+
+	if srv.VolumeSize < snap.SizeGB {
+		srv.VolumeSize = snap.SizeGB
+	}
+}
+
+// ValidateVolumeSize checks that the data volume of the Server can be resized to the provided size in GB.
+func (srv *Server) ValidateVolumeSize(size int) error {
+	if srv.VolumeSize == 0 {
+		return ErrServerWithoutVolume
+	}
+
+	if size <= srv.VolumeSize {
+		return errors.Wrapf(ErrVolumeShrink, "requested %d GB, current %d GB", size, srv.VolumeSize)
+	}
+
 	return nil
 }
 
@@ -180,6 +388,15 @@ type ServerRepository interface {
 	// List all servers for a particular Account.
 	List(ctx context.Context, accountID account.ID) ([]*Server, error)
 
+	// ListByOrganization lists all servers owned by a particular Organization.
+	ListByOrganization(ctx context.Context, orgID account.OrganizationID) ([]*Server, error)
+
+	// ListByProject lists all servers organized under a particular Project.
+	ListByProject(ctx context.Context, projectID ProjectID) ([]*Server, error)
+
+	// ListByState lists the servers in the provided state across all Accounts.
+	ListByState(ctx context.Context, state ServerState) ([]*Server, error)
+
 	// Create a new Server.
 	Create(ctx context.Context, server *Server) error
 
@@ -230,6 +447,60 @@ func (repo *InMemoryServerRepository) List(ctx context.Context, accountID accoun
 	return servers, nil
 }
 
+// ListByOrganization lists all servers owned by a particular Organization.
+func (repo *InMemoryServerRepository) ListByOrganization(ctx context.Context, orgID account.OrganizationID) ([]*Server, error) {
+	var servers []*Server
+
+	repo.servers.Range(func(key, v interface{}) bool {
+		srv := v.(*Server)
+		if srv.OrganizationID != orgID {
+			return true
+		}
+
+		servers = append(servers, srv)
+
+		return true
+	})
+
+	return servers, nil
+}
+
+// ListByProject lists all servers organized under a particular Project.
+func (repo *InMemoryServerRepository) ListByProject(ctx context.Context, projectID ProjectID) ([]*Server, error) {
+	var servers []*Server
+
+	repo.servers.Range(func(key, v interface{}) bool {
+		srv := v.(*Server)
+		if srv.ProjectID != projectID {
+			return true
+		}
+
+		servers = append(servers, srv)
+
+		return true
+	})
+
+	return servers, nil
+}
+
+// ListByState lists the servers in the provided state across all Accounts.
+func (repo *InMemoryServerRepository) ListByState(ctx context.Context, state ServerState) ([]*Server, error) {
+	var servers []*Server
+
+	repo.servers.Range(func(key, v interface{}) bool {
+		srv := v.(*Server)
+		if srv.State != state {
+			return true
+		}
+
+		servers = append(servers, srv)
+
+		return true
+	})
+
+	return servers, nil
+}
+
 // Create a new Server.
 func (repo *InMemoryServerRepository) Create(ctx context.Context, server *Server) error {
 	_, loaded := repo.servers.LoadOrStore(server.ID, server)
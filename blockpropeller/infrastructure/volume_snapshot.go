@@ -0,0 +1,328 @@
+package infrastructure
+
+import (
+	"context"
+	"database/sql/driver"
+	"sort"
+	"sync"
+	"time"
+
+	"blockpropeller.dev/blockpropeller/account"
+	"blockpropeller.dev/blockpropeller/terraform"
+	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+)
+
+var (
+	// ErrVolumeSnapshotNotFound is returned when a VolumeSnapshotRepository does not find a snapshot to return.
+	ErrVolumeSnapshotNotFound = errors.New("volume snapshot not found")
+	// ErrVolumeSnapshotAlreadyExists is returned when a VolumeSnapshot creation is attempted with an existing VolumeSnapshotID.
+	ErrVolumeSnapshotAlreadyExists = errors.New("volume snapshot already exists")
+)
+
+// NilVolumeSnapshotID is an empty VolumeSnapshotID.
+var NilVolumeSnapshotID VolumeSnapshotID
+
+// VolumeSnapshotID is a unique volume snapshot identifier.
+type VolumeSnapshotID string
+
+// NewVolumeSnapshotID returns a new unique VolumeSnapshotID.
+func NewVolumeSnapshotID() VolumeSnapshotID {
+	return VolumeSnapshotID(uuid.NewV4().String())
+}
+
+// String satisfies the Stringer interface.
+func (id VolumeSnapshotID) String() string {
+	return string(id)
+}
+
+// Value implements the sql.Valuer interface.
+//
+// A NilVolumeSnapshotID is stored as NULL, for Servers created with an empty data volume.
+func (id VolumeSnapshotID) Value() (driver.Value, error) {
+	if id == NilVolumeSnapshotID {
+		return nil, nil
+	}
+
+	return string(id), nil
+}
+
+// Scan implements the sql.Scanner interface.
+func (id *VolumeSnapshotID) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*id = NilVolumeSnapshotID
+	case string:
+		*id = VolumeSnapshotID(v)
+	case []byte:
+		*id = VolumeSnapshotID(v)
+	default:
+		return errors.New("invalid volume snapshot id type")
+	}
+
+	return nil
+}
+
+// VolumeSnapshotState represents the state a VolumeSnapshot is in.
+type VolumeSnapshotState string
+
+var (
+	// VolumeSnapshotStateRequested is the state of a snapshot that is yet to be taken.
+	VolumeSnapshotStateRequested = VolumeSnapshotState("requested")
+	// VolumeSnapshotStateOk is the state of a snapshot that has been taken and can be restored.
+	VolumeSnapshotStateOk = VolumeSnapshotState("ok")
+	// VolumeSnapshotStateFailed is the state of a snapshot that could not be taken.
+	VolumeSnapshotStateFailed = VolumeSnapshotState("failed")
+)
+
+// VolumeSnapshot is a point-in-time copy of the data volume of a Server, taken through the cloud provider,
+// such as a DigitalOcean volume snapshot or an AWS EBS snapshot.
+//
+// A VolumeSnapshot outlives the Server it was taken of, so new Servers can start
+// with the node data already in place instead of syncing it from scratch.
+type VolumeSnapshot struct {
+	ID             VolumeSnapshotID       `json:"id" gorm:"type:varchar(36) not null"`
+	AccountID      account.ID             `json:"-" gorm:"type:varchar(36) not null references accounts(id)"`
+	OrganizationID account.OrganizationID `json:"organization_id,omitempty" gorm:"type:varchar(36) references organizations(id)"`
+	ServerID       ServerID               `json:"server_id" gorm:"type:varchar(36) not null references servers(id)"`
+
+	State VolumeSnapshotState `json:"state" gorm:"type:varchar(20) not null"`
+	Error string              `json:"error,omitempty" gorm:"type:text"`
+
+	Provider           ProviderType       `json:"provider" gorm:"type:varchar(100) not null"`
+	ProviderSettingsID ProviderSettingsID `json:"provider_settings_id" gorm:"type:varchar(36) not null references provider_settings(id)"`
+	Region             string             `json:"region" gorm:"type:varchar(100) not null"`
+
+	// SizeGB of the snapshotted volume, the minimal size of volumes created from the snapshot.
+	SizeGB int `json:"size_gb" gorm:"type:integer not null;default:0"`
+	// Scheduled snapshots are taken according to the SnapshotPolicy of the Server,
+	// and pruned once they fall out of its retention.
+	Scheduled bool `json:"scheduled" gorm:"type:boolean not null;default:false"`
+
+	// ProviderID identifies the snapshot with the cloud provider when creating volumes from it.
+	ProviderID string `json:"-" gorm:"type:varchar(255)"`
+
+	WorkspaceSnapshot *terraform.WorkspaceSnapshot `json:"-" gorm:"embedded;embedded_prefix:terraform_"`
+
+	CreatedAt   time.Time  `json:"created_at" gorm:"type:timestamp not null;default:CURRENT_TIMESTAMP"`
+	UpdatedAt   time.Time  `json:"updated_at" gorm:"type:timestamp not null;default:CURRENT_TIMESTAMP"`
+	CompletedAt *time.Time `json:"completed_at,omitempty" gorm:"type:timestamp"`
+	DeletedAt   *time.Time `json:"-" gorm:"type:timestamp"`
+}
+
+// NewVolumeSnapshot returns a new VolumeSnapshot instance of the data volume of the provided Server,
+// living in the region the Server is provisioned in.
+func NewVolumeSnapshot(srv *Server, region string, scheduled bool) *VolumeSnapshot {
+	return &VolumeSnapshot{
+		ID:             NewVolumeSnapshotID(),
+		AccountID:      srv.AccountID,
+		OrganizationID: srv.OrganizationID,
+		ServerID:       srv.ID,
+
+		State: VolumeSnapshotStateRequested,
+
+		Provider:           srv.Provider,
+		ProviderSettingsID: srv.ProviderSettingsID,
+		Region:             region,
+
+		SizeGB:    srv.VolumeSize,
+		Scheduled: scheduled,
+
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+}
+
+// IsAvailable checks whether the VolumeSnapshot has been taken and volumes can be created from it.
+func (snap *VolumeSnapshot) IsAvailable() bool {
+	return snap.State == VolumeSnapshotStateOk && snap.ProviderID != ""
+}
+
+// AfterFind GORM Hook.
+func (snap *VolumeSnapshot) AfterFind() error {
+	if snap.WorkspaceSnapshot != nil && snap.WorkspaceSnapshot.WorkspacePath == "" {
+		snap.WorkspaceSnapshot = nil
+	}
+
+	return nil
+}
+
+// SnapshotPolicy configures scheduled snapshots of the data volume of a Server.
+//
+// A zero interval disables scheduled snapshots. Only the Retain most recent
+// scheduled snapshots are kept, on-demand snapshots are never pruned.
+type SnapshotPolicy struct {
+	IntervalHours int `json:"interval_hours" gorm:"type:integer not null;default:0"`
+	Retain        int `json:"retain" gorm:"type:integer not null;default:0"`
+}
+
+// Validate checks that the policy takes snapshots periodically and retains at least one of them.
+func (p SnapshotPolicy) Validate() error {
+	if p.IntervalHours < 0 {
+		return errors.New("snapshot interval must be positive")
+	}
+	if p.IntervalHours > 0 && p.Retain < 1 {
+		return errors.New("at least one snapshot must be retained")
+	}
+	if p.Retain < 0 {
+		return errors.New("snapshot retention must be positive")
+	}
+
+	return nil
+}
+
+// IsEnabled checks whether scheduled snapshots should be taken.
+func (p SnapshotPolicy) IsEnabled() bool {
+	return p.IntervalHours > 0
+}
+
+// IsDue checks whether a scheduled snapshot should be taken at the provided time,
+// given the time the latest scheduled snapshot was taken at.
+func (p SnapshotPolicy) IsDue(latest *time.Time, now time.Time) bool {
+	if !p.IsEnabled() {
+		return false
+	}
+	if latest == nil {
+		return true
+	}
+
+	return !now.Before(latest.Add(time.Duration(p.IntervalHours) * time.Hour))
+}
+
+// Expired returns the scheduled snapshots which fall out of the retention of the policy, oldest first.
+//
+// Only snapshots that have been taken are considered, failed snapshots are left for inspection.
+func (p SnapshotPolicy) Expired(snapshots []*VolumeSnapshot) []*VolumeSnapshot {
+	var scheduled []*VolumeSnapshot
+	for _, snap := range snapshots {
+		if snap.Scheduled && snap.State == VolumeSnapshotStateOk {
+			scheduled = append(scheduled, snap)
+		}
+	}
+
+	if len(scheduled) <= p.Retain {
+		return nil
+	}
+
+	sort.Slice(scheduled, func(i, j int) bool {
+		return scheduled[i].CreatedAt.Before(scheduled[j].CreatedAt)
+	})
+
+	return scheduled[:len(scheduled)-p.Retain]
+}
+
+// VolumeSnapshotRepository defines an interface for storing and retrieving volume snapshots.
+type VolumeSnapshotRepository interface {
+	// Find a VolumeSnapshot given a VolumeSnapshotID.
+	Find(ctx context.Context, id VolumeSnapshotID) (*VolumeSnapshot, error)
+
+	// List all volume snapshots for a particular Account.
+	List(ctx context.Context, accountID account.ID) ([]*VolumeSnapshot, error)
+
+	// ListByOrganization lists all volume snapshots owned by a particular Organization.
+	ListByOrganization(ctx context.Context, orgID account.OrganizationID) ([]*VolumeSnapshot, error)
+
+	// ListByServer lists all volume snapshots taken of a particular Server.
+	ListByServer(ctx context.Context, srvID ServerID) ([]*VolumeSnapshot, error)
+
+	// ListByState lists the volume snapshots in the provided state across all Accounts.
+	ListByState(ctx context.Context, state VolumeSnapshotState) ([]*VolumeSnapshot, error)
+
+	// Create a new VolumeSnapshot.
+	Create(ctx context.Context, snap *VolumeSnapshot) error
+
+	// Update an existing VolumeSnapshot.
+	Update(ctx context.Context, snap *VolumeSnapshot) error
+
+	// Delete an existing VolumeSnapshot.
+	Delete(ctx context.Context, snap *VolumeSnapshot) error
+}
+
+// InMemoryVolumeSnapshotRepository holds the volume snapshots inside an in-memory map.
+//
+// Volume snapshots are not persisted on disk and won't survive program restarts.
+type InMemoryVolumeSnapshotRepository struct {
+	snapshots sync.Map
+}
+
+// NewInMemoryVolumeSnapshotRepository returns a new InMemoryVolumeSnapshotRepository instance.
+func NewInMemoryVolumeSnapshotRepository() *InMemoryVolumeSnapshotRepository {
+	return &InMemoryVolumeSnapshotRepository{}
+}
+
+// Find a VolumeSnapshot given a VolumeSnapshotID.
+func (repo *InMemoryVolumeSnapshotRepository) Find(ctx context.Context, id VolumeSnapshotID) (*VolumeSnapshot, error) {
+	snap, ok := repo.snapshots.Load(id)
+	if !ok {
+		return nil, ErrVolumeSnapshotNotFound
+	}
+
+	return snap.(*VolumeSnapshot), nil
+}
+
+// List all volume snapshots for a particular Account.
+func (repo *InMemoryVolumeSnapshotRepository) List(ctx context.Context, accountID account.ID) ([]*VolumeSnapshot, error) {
+	return repo.filter(func(snap *VolumeSnapshot) bool {
+		return snap.AccountID == accountID
+	}), nil
+}
+
+// ListByOrganization lists all volume snapshots owned by a particular Organization.
+func (repo *InMemoryVolumeSnapshotRepository) ListByOrganization(ctx context.Context, orgID account.OrganizationID) ([]*VolumeSnapshot, error) {
+	return repo.filter(func(snap *VolumeSnapshot) bool {
+		return snap.OrganizationID == orgID
+	}), nil
+}
+
+// ListByServer lists all volume snapshots taken of a particular Server.
+func (repo *InMemoryVolumeSnapshotRepository) ListByServer(ctx context.Context, srvID ServerID) ([]*VolumeSnapshot, error) {
+	return repo.filter(func(snap *VolumeSnapshot) bool {
+		return snap.ServerID == srvID
+	}), nil
+}
+
+// ListByState lists the volume snapshots in the provided state across all Accounts.
+func (repo *InMemoryVolumeSnapshotRepository) ListByState(ctx context.Context, state VolumeSnapshotState) ([]*VolumeSnapshot, error) {
+	return repo.filter(func(snap *VolumeSnapshot) bool {
+		return snap.State == state
+	}), nil
+}
+
+// Create a new VolumeSnapshot.
+func (repo *InMemoryVolumeSnapshotRepository) Create(ctx context.Context, snap *VolumeSnapshot) error {
+	_, loaded := repo.snapshots.LoadOrStore(snap.ID, snap)
+	if loaded {
+		return ErrVolumeSnapshotAlreadyExists
+	}
+
+	return nil
+}
+
+// Update an existing VolumeSnapshot.
+func (repo *InMemoryVolumeSnapshotRepository) Update(ctx context.Context, snap *VolumeSnapshot) error {
+	repo.snapshots.Store(snap.ID, snap)
+
+	return nil
+}
+
+// Delete an existing VolumeSnapshot.
+func (repo *InMemoryVolumeSnapshotRepository) Delete(ctx context.Context, snap *VolumeSnapshot) error {
+	repo.snapshots.Delete(snap.ID)
+
+	return nil
+}
+
+func (repo *InMemoryVolumeSnapshotRepository) filter(match func(snap *VolumeSnapshot) bool) []*VolumeSnapshot {
+	var snapshots []*VolumeSnapshot
+
+	repo.snapshots.Range(func(key, v interface{}) bool {
+		snap := v.(*VolumeSnapshot)
+		if match(snap) {
+			snapshots = append(snapshots, snap)
+		}
+
+		return true
+	})
+
+	return snapshots
+}
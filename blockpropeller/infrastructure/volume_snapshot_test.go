@@ -0,0 +1,59 @@
+package infrastructure_test
+
+import (
+	"testing"
+	"time"
+
+	"blockpropeller.dev/blockpropeller/infrastructure"
+	"blockpropeller.dev/lib/test"
+)
+
+func TestSnapshotPolicy(t *testing.T) {
+	policy := infrastructure.SnapshotPolicy{IntervalHours: 24, Retain: 2}
+	test.CheckErr(t, "Validate()", policy.Validate())
+
+	for _, invalid := range []infrastructure.SnapshotPolicy{
+		{IntervalHours: -1},
+		{IntervalHours: 24},
+		{Retain: -1},
+	} {
+		test.CheckErrExists(t, "Validate() invalid policy", invalid.Validate())
+	}
+
+	now := time.Now()
+	latest := now.Add(-23 * time.Hour)
+
+	test.AssertBoolEqual(t, "IsDue() without snapshots", policy.IsDue(nil, now), true)
+	test.AssertBoolEqual(t, "IsDue() before interval", policy.IsDue(&latest, now), false)
+	test.AssertBoolEqual(t, "IsDue() after interval", policy.IsDue(&latest, now.Add(time.Hour)), true)
+	test.AssertBoolEqual(t, "IsDue() disabled", infrastructure.SnapshotPolicy{}.IsDue(nil, now), false)
+}
+
+func TestSnapshotPolicyExpired(t *testing.T) {
+	policy := infrastructure.SnapshotPolicy{IntervalHours: 24, Retain: 2}
+
+	now := time.Now()
+	snapshot := func(age time.Duration, scheduled bool, state infrastructure.VolumeSnapshotState) *infrastructure.VolumeSnapshot {
+		return &infrastructure.VolumeSnapshot{
+			ID:        infrastructure.NewVolumeSnapshotID(),
+			Scheduled: scheduled,
+			State:     state,
+			CreatedAt: now.Add(-age),
+		}
+	}
+
+	oldest := snapshot(72*time.Hour, true, infrastructure.VolumeSnapshotStateOk)
+	snapshots := []*infrastructure.VolumeSnapshot{
+		snapshot(24*time.Hour, true, infrastructure.VolumeSnapshotStateOk),
+		oldest,
+		snapshot(96*time.Hour, false, infrastructure.VolumeSnapshotStateOk),
+		snapshot(120*time.Hour, true, infrastructure.VolumeSnapshotStateFailed),
+		snapshot(48*time.Hour, true, infrastructure.VolumeSnapshotStateOk),
+	}
+
+	expired := policy.Expired(snapshots)
+	test.AssertIntsEqual(t, "expired snapshots", len(expired), 1)
+	test.AssertStringsEqual(t, "expired snapshot", expired[0].ID.String(), oldest.ID.String())
+
+	test.AssertIntsEqual(t, "expired within retention", len(policy.Expired(snapshots[:2])), 0)
+}
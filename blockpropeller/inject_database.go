@@ -4,11 +4,15 @@ package blockpropeller
 
 import (
 	"blockpropeller.dev/blockpropeller/account"
+	"blockpropeller.dev/blockpropeller/audit"
 	"blockpropeller.dev/blockpropeller/database"
+	"blockpropeller.dev/blockpropeller/encryption"
 	"blockpropeller.dev/blockpropeller/httpserver"
 	"blockpropeller.dev/blockpropeller/infrastructure"
+	"blockpropeller.dev/blockpropeller/mail"
 	"blockpropeller.dev/blockpropeller/provision"
 	"blockpropeller.dev/lib/log"
+	"blockpropeller.dev/lib/server"
 	"github.com/google/wire"
 )
 
@@ -35,6 +39,53 @@ var dbAppSet = wire.NewSet(
 	database.NewProviderSettingsRepository,
 	wire.Bind(new(infrastructure.ProviderSettingsRepository), new(*database.ProviderSettingsRepository)),
 
+	database.NewProjectRepository,
+	wire.Bind(new(infrastructure.ProjectRepository), new(*database.ProjectRepository)),
+
+	database.NewOrganizationRepository,
+	wire.Bind(new(account.OrganizationRepository), new(*database.OrganizationRepository)),
+
+	database.NewMembershipRepository,
+	wire.Bind(new(account.MembershipRepository), new(*database.MembershipRepository)),
+
+	database.NewInvitationRepository,
+	wire.Bind(new(account.InvitationRepository), new(*database.InvitationRepository)),
+
+	database.NewAPIKeyRepository,
+	wire.Bind(new(account.APIKeyRepository), new(*database.APIKeyRepository)),
+
+	database.NewSessionRepository,
+	wire.Bind(new(account.SessionRepository), new(*database.SessionRepository)),
+
+	database.NewOneTimeTokenRepository,
+	wire.Bind(new(account.OneTimeTokenRepository), new(*database.OneTimeTokenRepository)),
+
+	database.NewRecoveryCodeRepository,
+	wire.Bind(new(account.RecoveryCodeRepository), new(*database.RecoveryCodeRepository)),
+
+	database.NewIdentityRepository,
+	wire.Bind(new(account.IdentityRepository), new(*database.IdentityRepository)),
+
+	database.NewSSOAuthorizationRepository,
+	wire.Bind(new(account.SSOAuthorizationRepository), new(*database.SSOAuthorizationRepository)),
+
+	database.NewAuditRepository,
+	wire.Bind(new(audit.Repository), new(*database.AuditRepository)),
+
+	database.NewThrottleStore,
+	wire.Bind(new(server.ThrottleStore), new(*database.ThrottleStore)),
+
+	database.NewEncryptionRotator,
+	wire.Bind(new(encryption.Rotator), new(*database.EncryptionRotator)),
+
+	mail.ConfigureMailer,
+
+	database.NewReservedIPRepository,
+	wire.Bind(new(infrastructure.ReservedIPRepository), new(*database.ReservedIPRepository)),
+
+	database.NewVolumeSnapshotRepository,
+	wire.Bind(new(infrastructure.VolumeSnapshotRepository), new(*database.VolumeSnapshotRepository)),
+
 	AppSet,
 )
 
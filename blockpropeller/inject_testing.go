@@ -6,11 +6,15 @@ import (
 	"testing"
 
 	"blockpropeller.dev/blockpropeller/account"
+	"blockpropeller.dev/blockpropeller/audit"
 	"blockpropeller.dev/blockpropeller/database/transaction"
+	"blockpropeller.dev/blockpropeller/encryption"
 	"blockpropeller.dev/blockpropeller/httpserver"
 	"blockpropeller.dev/blockpropeller/infrastructure"
+	"blockpropeller.dev/blockpropeller/mail"
 	"blockpropeller.dev/blockpropeller/provision"
 	"blockpropeller.dev/lib/log"
+	"blockpropeller.dev/lib/server"
 	"github.com/google/wire"
 )
 
@@ -37,6 +41,54 @@ var testAppSet = wire.NewSet(
 	infrastructure.NewInMemoryProviderSettingsRepository,
 	wire.Bind(new(infrastructure.ProviderSettingsRepository), new(*infrastructure.InMemoryProviderSettingsRepository)),
 
+	infrastructure.NewInMemoryProjectRepository,
+	wire.Bind(new(infrastructure.ProjectRepository), new(*infrastructure.InMemoryProjectRepository)),
+
+	account.NewInMemoryOrganizationRepository,
+	wire.Bind(new(account.OrganizationRepository), new(*account.InMemoryOrganizationRepository)),
+
+	account.NewInMemoryMembershipRepository,
+	wire.Bind(new(account.MembershipRepository), new(*account.InMemoryMembershipRepository)),
+
+	account.NewInMemoryInvitationRepository,
+	wire.Bind(new(account.InvitationRepository), new(*account.InMemoryInvitationRepository)),
+
+	account.NewInMemoryAPIKeyRepository,
+	wire.Bind(new(account.APIKeyRepository), new(*account.InMemoryAPIKeyRepository)),
+
+	account.NewInMemorySessionRepository,
+	wire.Bind(new(account.SessionRepository), new(*account.InMemorySessionRepository)),
+
+	account.NewInMemoryOneTimeTokenRepository,
+	wire.Bind(new(account.OneTimeTokenRepository), new(*account.InMemoryOneTimeTokenRepository)),
+
+	account.NewInMemoryRecoveryCodeRepository,
+	wire.Bind(new(account.RecoveryCodeRepository), new(*account.InMemoryRecoveryCodeRepository)),
+
+	account.NewInMemoryIdentityRepository,
+	wire.Bind(new(account.IdentityRepository), new(*account.InMemoryIdentityRepository)),
+
+	account.NewInMemorySSOAuthorizationRepository,
+	wire.Bind(new(account.SSOAuthorizationRepository), new(*account.InMemorySSOAuthorizationRepository)),
+
+	audit.NewInMemoryRepository,
+	wire.Bind(new(audit.Repository), new(*audit.InMemoryRepository)),
+
+	server.NewInMemoryThrottleStore,
+	wire.Bind(new(server.ThrottleStore), new(*server.InMemoryThrottleStore)),
+
+	encryption.NewInMemoryRotator,
+	wire.Bind(new(encryption.Rotator), new(*encryption.InMemoryRotator)),
+
+	mail.NewInMemoryMailer,
+	wire.Bind(new(mail.Mailer), new(*mail.InMemoryMailer)),
+
+	infrastructure.NewInMemoryReservedIPRepository,
+	wire.Bind(new(infrastructure.ReservedIPRepository), new(*infrastructure.InMemoryReservedIPRepository)),
+
+	infrastructure.NewInMemoryVolumeSnapshotRepository,
+	wire.Bind(new(infrastructure.VolumeSnapshotRepository), new(*infrastructure.InMemoryVolumeSnapshotRepository)),
+
 	AppSet,
 )
 
@@ -0,0 +1,60 @@
+package mail
+
+import "github.com/pkg/errors"
+
+// Drivers available for sending emails.
+const (
+	// DriverSMTP sends emails through an SMTP server.
+	DriverSMTP = "smtp"
+	// DriverFile writes emails to a local directory instead of sending them, for local development.
+	DriverFile = "file"
+)
+
+// Config for the mail module.
+type Config struct {
+	Driver string `yaml:"driver"`
+	// From is the sender address of all emails.
+	From string `yaml:"from"`
+	// BaseURL is the URL of the BlockPropeller web application, used for links within emails.
+	BaseURL string `yaml:"base_url"`
+
+	// SMTP Options
+	Host     string `yaml:"host"`
+	Port     int    `yaml:"port"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+
+	// File Options
+	Dir string `yaml:"dir"`
+}
+
+// Validate satisfies the config.Config interface.
+func (cfg *Config) Validate() error {
+	if cfg.Driver == "" {
+		cfg.Driver = DriverFile
+	}
+	if cfg.Driver != DriverSMTP && cfg.Driver != DriverFile {
+		return errors.Errorf(
+			"invalid mail driver: '%s'. Valid drivers: '%s', '%s'", cfg.Driver, DriverSMTP, DriverFile)
+	}
+
+	if cfg.From == "" {
+		cfg.From = "BlockPropeller <noreply@blockpropeller.dev>"
+	}
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = "http://localhost:8000"
+	}
+
+	if cfg.Driver == DriverSMTP && cfg.Host == "" {
+		return errors.New("missing SMTP host")
+	}
+	if cfg.Port == 0 {
+		cfg.Port = 587
+	}
+
+	if cfg.Dir == "" {
+		cfg.Dir = ".blockpropeller/mail"
+	}
+
+	return nil
+}
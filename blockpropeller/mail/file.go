@@ -0,0 +1,52 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"blockpropeller.dev/lib/log"
+	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+)
+
+// FileMailer writes emails to a local directory and logs them, instead of sending them.
+//
+// FileMailer is meant for local development, where the emails can be read from the logs or the directory.
+type FileMailer struct {
+	dir  string
+	from string
+}
+
+// NewFileMailer returns a new FileMailer instance.
+func NewFileMailer(dir string, from string) *FileMailer {
+	return &FileMailer{dir: dir, from: from}
+}
+
+// Send the Message to its recipient.
+func (m *FileMailer) Send(ctx context.Context, msg *Message) error {
+	err := os.MkdirAll(m.dir, 0700)
+	if err != nil {
+		return errors.Wrap(err, "create mail dir")
+	}
+
+	name := fmt.Sprintf("%s-%s.eml", time.Now().UTC().Format("20060102T150405"), uuid.NewV4().String())
+	path := filepath.Join(m.dir, name)
+
+	err = ioutil.WriteFile(path, encode(m.from, msg), 0600)
+	if err != nil {
+		return errors.Wrap(err, "write mail file")
+	}
+
+	log.Info("mail written to file", log.Fields{
+		"to":      msg.To,
+		"subject": msg.Subject,
+		"body":    msg.Body,
+		"path":    path,
+	})
+
+	return nil
+}
@@ -0,0 +1,71 @@
+package mail
+
+import (
+	"context"
+	"sync"
+
+	"blockpropeller.dev/lib/log"
+)
+
+// Message is a plain text email sent to a single recipient.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer sends emails.
+type Mailer interface {
+	// Send the Message to its recipient.
+	Send(ctx context.Context, msg *Message) error
+}
+
+// ConfigureMailer returns the Mailer selected by the configured driver.
+func ConfigureMailer(cfg *Config) Mailer {
+	if cfg.Driver == DriverSMTP {
+		return NewSMTPMailer(cfg.Host, cfg.Port, cfg.Username, cfg.Password, cfg.From)
+	}
+
+	return NewFileMailer(cfg.Dir, cfg.From)
+}
+
+// InMemoryMailer keeps the sent messages in memory, instead of sending them.
+type InMemoryMailer struct {
+	mu       sync.Mutex
+	messages []*Message
+}
+
+// NewInMemoryMailer returns a new InMemoryMailer instance.
+func NewInMemoryMailer() *InMemoryMailer {
+	return &InMemoryMailer{}
+}
+
+// Send the Message to its recipient.
+func (m *InMemoryMailer) Send(ctx context.Context, msg *Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.messages = append(m.messages, msg)
+
+	log.Debug("mail sent", log.Fields{
+		"to":      msg.To,
+		"subject": msg.Subject,
+	})
+
+	return nil
+}
+
+// Messages returns all the messages sent to the provided recipient.
+func (m *InMemoryMailer) Messages(to string) []*Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var messages []*Message
+	for _, msg := range m.messages {
+		if msg.To == to {
+			messages = append(messages, msg)
+		}
+	}
+
+	return messages
+}
@@ -0,0 +1,66 @@
+package mail_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"blockpropeller.dev/blockpropeller/mail"
+	"blockpropeller.dev/lib/test"
+)
+
+func TestConfigValidate(t *testing.T) {
+	cfg := &mail.Config{}
+	test.CheckErr(t, "validate default config", cfg.Validate())
+	test.AssertStringsEqual(t, "default driver", cfg.Driver, mail.DriverFile)
+
+	_, ok := mail.ConfigureMailer(cfg).(*mail.FileMailer)
+	test.AssertBoolEqual(t, "file mailer configured", ok, true)
+
+	err := (&mail.Config{Driver: "carrier-pigeon"}).Validate()
+	test.CheckErrExists(t, "validate unknown driver", err)
+
+	err = (&mail.Config{Driver: mail.DriverSMTP}).Validate()
+	test.CheckErrExists(t, "validate smtp without host", err)
+
+	cfg = &mail.Config{Driver: mail.DriverSMTP, Host: "smtp.example.com"}
+	test.CheckErr(t, "validate smtp config", cfg.Validate())
+	test.AssertIntsEqual(t, "default smtp port", cfg.Port, 587)
+
+	_, ok = mail.ConfigureMailer(cfg).(*mail.SMTPMailer)
+	test.AssertBoolEqual(t, "smtp mailer configured", ok, true)
+}
+
+func TestFileMailer(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mail")
+	test.CheckErr(t, "create temp dir", err)
+	defer os.RemoveAll(dir)
+
+	mailer := mail.NewFileMailer(filepath.Join(dir, "outbox"), "BlockPropeller <noreply@example.com>")
+
+	err = mailer.Send(context.Background(), &mail.Message{
+		To:      "user@example.com",
+		Subject: "Hello",
+		Body:    "Hello from BlockPropeller.",
+	})
+	test.CheckErr(t, "send mail", err)
+
+	files, err := ioutil.ReadDir(filepath.Join(dir, "outbox"))
+	test.CheckErr(t, "read outbox", err)
+	test.AssertIntsEqual(t, "mail files", len(files), 1)
+
+	raw, err := ioutil.ReadFile(filepath.Join(dir, "outbox", files[0].Name()))
+	test.CheckErr(t, "read mail file", err)
+
+	for _, expected := range []string{
+		"From: BlockPropeller <noreply@example.com>\r\n",
+		"To: user@example.com\r\n",
+		"Subject: Hello\r\n",
+		"\r\n\r\nHello from BlockPropeller.",
+	} {
+		test.AssertBoolEqual(t, "mail contains "+strings.TrimSpace(expected), strings.Contains(string(raw), expected), true)
+	}
+}
@@ -0,0 +1,157 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"time"
+
+	"blockpropeller.dev/lib/log"
+	"github.com/pkg/errors"
+)
+
+// smtpTimeout bounds the whole exchange with the SMTP server, including connecting to it.
+const smtpTimeout = 30 * time.Second
+
+// SMTPMailer sends emails through an SMTP server.
+type SMTPMailer struct {
+	host    string
+	addr    string
+	auth    smtp.Auth
+	from    string
+	timeout time.Duration
+}
+
+// NewSMTPMailer returns a new SMTPMailer instance.
+//
+// Servers are authenticated against only if the username is provided.
+func NewSMTPMailer(host string, port int, username, password, from string) *SMTPMailer {
+	var auth smtp.Auth
+	if username != "" {
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+
+	return &SMTPMailer{
+		host:    host,
+		addr:    net.JoinHostPort(host, strconv.Itoa(port)),
+		auth:    auth,
+		from:    from,
+		timeout: smtpTimeout,
+	}
+}
+
+// WithTimeout returns the SMTPMailer with the exchange with the SMTP server bound by the provided timeout.
+func (m *SMTPMailer) WithTimeout(timeout time.Duration) *SMTPMailer {
+	m.timeout = timeout
+
+	return m
+}
+
+// Send the Message to its recipient.
+//
+// STARTTLS is used if the server supports it, same as with smtp.SendMail.
+func (m *SMTPMailer) Send(ctx context.Context, msg *Message) error {
+	from, err := mail.ParseAddress(m.from)
+	if err != nil {
+		return errors.Wrap(err, "parse sender address")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, m.timeout)
+	defer cancel()
+
+	conn, err := (&net.Dialer{Timeout: m.timeout}).DialContext(ctx, "tcp", m.addr)
+	if err != nil {
+		return errors.Wrap(err, "connect to smtp server")
+	}
+
+	deadline, _ := ctx.Deadline()
+	err = conn.SetDeadline(deadline)
+	if err != nil {
+		log.Closer(conn)
+		return errors.Wrap(err, "set smtp deadline")
+	}
+
+	client, err := smtp.NewClient(conn, m.host)
+	if err != nil {
+		log.Closer(conn)
+		return errors.Wrap(err, "start smtp session")
+	}
+
+	err = m.send(client, from.Address, msg)
+	if err != nil {
+		log.Closer(client)
+		return errors.Wrapf(err, "send mail to %s", msg.To)
+	}
+
+	return nil
+}
+
+func (m *SMTPMailer) send(client *smtp.Client, from string, msg *Message) error {
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		err := client.StartTLS(&tls.Config{ServerName: m.host})
+		if err != nil {
+			return errors.Wrap(err, "start tls")
+		}
+	}
+
+	if m.auth != nil {
+		err := client.Auth(m.auth)
+		if err != nil {
+			return errors.Wrap(err, "authenticate")
+		}
+	}
+
+	err := client.Mail(from)
+	if err != nil {
+		return errors.Wrap(err, "set sender")
+	}
+
+	err = client.Rcpt(msg.To)
+	if err != nil {
+		return errors.Wrap(err, "set recipient")
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return errors.Wrap(err, "start data")
+	}
+
+	_, err = w.Write(encode(m.from, msg))
+	if err != nil {
+		return errors.Wrap(err, "write message")
+	}
+
+	err = w.Close()
+	if err != nil {
+		return errors.Wrap(err, "finish data")
+	}
+
+	// Quitting closes the connection to the server.
+	err = client.Quit()
+	if err != nil {
+		return errors.Wrap(err, "quit")
+	}
+
+	return nil
+}
+
+// encode the Message in the RFC 5322 format.
+func encode(from string, msg *Message) []byte {
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", msg.Subject)
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=\"utf-8\"\r\n")
+	buf.WriteString("\r\n")
+	buf.WriteString(msg.Body)
+
+	return buf.Bytes()
+}
@@ -0,0 +1,126 @@
+package mail_test
+
+import (
+	"context"
+	"net"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"blockpropeller.dev/blockpropeller/mail"
+	"blockpropeller.dev/lib/test"
+)
+
+// smtpServer is a minimal SMTP server accepting every message,
+// or never greeting the clients if stalled.
+type smtpServer struct {
+	listener net.Listener
+	stalled  bool
+	messages chan string
+}
+
+func newSMTPServer(t *testing.T, stalled bool) *smtpServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	test.CheckErr(t, "listen", err)
+
+	srv := &smtpServer{listener: listener, stalled: stalled, messages: make(chan string, 1)}
+	go srv.serve()
+
+	return srv
+}
+
+func (srv *smtpServer) port() int {
+	port, _ := strconv.Atoi(strings.Split(srv.listener.Addr().String(), ":")[1])
+
+	return port
+}
+
+func (srv *smtpServer) serve() {
+	for {
+		conn, err := srv.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		go srv.handleConn(textproto.NewConn(conn))
+	}
+}
+
+func (srv *smtpServer) handleConn(conn *textproto.Conn) {
+	defer conn.Close()
+
+	if srv.stalled {
+		// Wait for the client to give up.
+		_, _ = conn.ReadLine()
+		return
+	}
+
+	_ = conn.PrintfLine("220 localhost ESMTP")
+	for {
+		line, err := conn.ReadLine()
+		if err != nil {
+			return
+		}
+
+		switch cmd := strings.ToUpper(strings.Fields(line)[0]); cmd {
+		case "EHLO", "HELO", "MAIL", "RCPT":
+			_ = conn.PrintfLine("250 OK")
+		case "DATA":
+			_ = conn.PrintfLine("354 Go ahead")
+
+			data, err := conn.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			srv.messages <- string(data)
+
+			_ = conn.PrintfLine("250 OK")
+		case "QUIT":
+			_ = conn.PrintfLine("221 Bye")
+			return
+		default:
+			_ = conn.PrintfLine("502 Unsupported")
+		}
+	}
+}
+
+func TestSMTPMailer(t *testing.T) {
+	srv := newSMTPServer(t, false)
+	defer test.Close(t, srv.listener)
+
+	mailer := mail.NewSMTPMailer("127.0.0.1", srv.port(), "", "", "BlockPropeller <noreply@example.com>")
+
+	err := mailer.Send(context.Background(), &mail.Message{
+		To:      "user@example.com",
+		Subject: "Hello",
+		Body:    "Hello from BlockPropeller.",
+	})
+	test.CheckErr(t, "send mail", err)
+
+	msg := <-srv.messages
+	test.AssertBoolEqual(t, "message subject", strings.Contains(msg, "Subject: Hello\n"), true)
+	test.AssertBoolEqual(t, "message body", strings.Contains(msg, "Hello from BlockPropeller."), true)
+}
+
+func TestSMTPMailerTimeout(t *testing.T) {
+	srv := newSMTPServer(t, true)
+	defer test.Close(t, srv.listener)
+
+	mailer := mail.NewSMTPMailer("127.0.0.1", srv.port(), "", "", "BlockPropeller <noreply@example.com>").
+		WithTimeout(100 * time.Millisecond)
+
+	start := time.Now()
+	err := mailer.Send(context.Background(), &mail.Message{To: "user@example.com", Subject: "Hello"})
+	test.CheckErrExists(t, "send mail to stalled server", err)
+	test.AssertBoolEqual(t, "gave up on stalled server", time.Since(start) < 5*time.Second, true)
+
+	// Cancelling the context stops sending as well.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err = mail.NewSMTPMailer("127.0.0.1", srv.port(), "", "", "noreply@example.com").
+		Send(ctx, &mail.Message{To: "user@example.com", Subject: "Hello"})
+	test.CheckErrExists(t, "send mail with cancelled context", err)
+}
@@ -0,0 +1,66 @@
+package oidc
+
+import "github.com/pkg/errors"
+
+// Config for the OpenID Connect single sign-on.
+//
+// Single sign-on is disabled unless an Issuer is configured.
+type Config struct {
+	// Issuer is the URL of the identity provider, under which its discovery document is served.
+	Issuer       string `yaml:"issuer"`
+	ClientID     string `yaml:"client_id"`
+	ClientSecret string `yaml:"client_secret"`
+	// RedirectURL is where the identity provider sends users back to with an authorization code,
+	// usually a page of the BlockPropeller web application that forwards the code to the API.
+	RedirectURL string   `yaml:"redirect_url"`
+	Scopes      []string `yaml:"scopes"`
+
+	// AutoProvision creates accounts for identities whose email does not belong to any account yet.
+	AutoProvision bool `yaml:"auto_provision"`
+	// Organization is the ID of the organization every account logging in through the identity provider joins,
+	// with the configured Role.
+	Organization string `yaml:"organization"`
+	Role         string `yaml:"role"`
+}
+
+// Validate satisfies the config.Config interface.
+func (cfg *Config) Validate() error {
+	if !cfg.Enabled() {
+		return nil
+	}
+
+	if cfg.ClientID == "" {
+		return errors.New("missing OIDC client ID")
+	}
+	if cfg.RedirectURL == "" {
+		return errors.New("missing OIDC redirect URL")
+	}
+
+	if len(cfg.Scopes) == 0 {
+		cfg.Scopes = []string{"openid", "email", "profile"}
+	}
+	if !contains(cfg.Scopes, "openid") {
+		cfg.Scopes = append([]string{"openid"}, cfg.Scopes...)
+	}
+
+	if cfg.Organization != "" && cfg.Role == "" {
+		cfg.Role = "viewer"
+	}
+
+	return nil
+}
+
+// Enabled checks whether the single sign-on is configured.
+func (cfg *Config) Enabled() bool {
+	return cfg.Issuer != ""
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
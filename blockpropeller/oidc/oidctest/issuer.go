@@ -0,0 +1,275 @@
+// Package oidctest provides an in-process OpenID Connect identity provider for tests.
+package oidctest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"time"
+
+	"blockpropeller.dev/blockpropeller/oidc"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/pkg/errors"
+)
+
+const (
+	// ClientID is the only client registered with the Issuer.
+	ClientID = "blockpropeller"
+	// ClientSecret authenticates the ClientID with the Issuer.
+	ClientSecret = "blockpropeller-secret"
+	// RedirectURL is the only redirect URL registered for the ClientID.
+	RedirectURL = "https://app.blockpropeller.dev/oidc/callback"
+
+	keyID = "test-key"
+)
+
+// User is the identity the Issuer authenticates.
+type User struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+// authorization is an authorization code issued by the Issuer, waiting to be exchanged.
+type authorization struct {
+	user          User
+	nonce         string
+	codeChallenge string
+}
+
+// Issuer is a mock OpenID Connect identity provider, served by an httptest.Server.
+//
+// The Issuer supports the authorization code flow with S256 PKCE only, and authorizes
+// the configured User without any interaction.
+type Issuer struct {
+	srv *httptest.Server
+	key *rsa.PrivateKey
+
+	mu             sync.Mutex
+	user           User
+	authorizations map[string]*authorization
+	nonce          string
+}
+
+// NewIssuer starts a new Issuer, which should be closed once the test is done.
+func NewIssuer() *Issuer {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		panic(errors.Wrap(err, "generate issuer key"))
+	}
+
+	iss := &Issuer{
+		key:            key,
+		authorizations: make(map[string]*authorization),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", iss.discovery)
+	mux.HandleFunc("/authorize", iss.authorize)
+	mux.HandleFunc("/token", iss.token)
+	mux.HandleFunc("/jwks", iss.jwks)
+
+	iss.srv = httptest.NewServer(mux)
+
+	return iss
+}
+
+// URL returns the issuer identifier of the Issuer.
+func (iss *Issuer) URL() string {
+	return iss.srv.URL
+}
+
+// Config returns the oidc.Config for logging in through the Issuer.
+func (iss *Issuer) Config() *oidc.Config {
+	cfg := &oidc.Config{
+		Issuer:       iss.URL(),
+		ClientID:     ClientID,
+		ClientSecret: ClientSecret,
+		RedirectURL:  RedirectURL,
+	}
+	if err := cfg.Validate(); err != nil {
+		panic(err)
+	}
+
+	return cfg
+}
+
+// SetUser sets the User authenticated by the Issuer.
+func (iss *Issuer) SetUser(user User) {
+	iss.mu.Lock()
+	defer iss.mu.Unlock()
+
+	iss.user = user
+}
+
+// OverrideNonce makes the Issuer issue ID tokens with the provided nonce,
+// instead of the one they were requested with.
+func (iss *Issuer) OverrideNonce(nonce string) {
+	iss.mu.Lock()
+	defer iss.mu.Unlock()
+
+	iss.nonce = nonce
+}
+
+// Authorize follows the authorization URL as the authenticated User,
+// returning the authorization code and the state the Issuer redirected back with.
+func (iss *Issuer) Authorize(authURL string) (code string, state string, err error) {
+	client := &http.Client{
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	resp, err := client.Get(authURL)
+	if err != nil {
+		return "", "", errors.Wrap(err, "authorize")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusFound {
+		return "", "", errors.Errorf("authorize: unexpected status %d", resp.StatusCode)
+	}
+
+	location, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		return "", "", errors.Wrap(err, "parse redirect location")
+	}
+
+	return location.Query().Get("code"), location.Query().Get("state"), nil
+}
+
+// Close shuts the Issuer down.
+func (iss *Issuer) Close() {
+	iss.srv.Close()
+}
+
+func (iss *Issuer) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, &oidc.Metadata{
+		Issuer:                        iss.URL(),
+		AuthorizationEndpoint:         iss.URL() + "/authorize",
+		TokenEndpoint:                 iss.URL() + "/token",
+		JWKSURI:                       iss.URL() + "/jwks",
+		CodeChallengeMethodsSupported: []string{"S256"},
+	})
+}
+
+func (iss *Issuer) authorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if query.Get("client_id") != ClientID || query.Get("redirect_uri") != RedirectURL {
+		http.Error(w, "unknown client", http.StatusBadRequest)
+		return
+	}
+	if query.Get("response_type") != "code" || query.Get("code_challenge_method") != "S256" {
+		http.Error(w, "unsupported authorization request", http.StatusBadRequest)
+		return
+	}
+
+	code := randomString()
+
+	iss.mu.Lock()
+	iss.authorizations[code] = &authorization{
+		user:          iss.user,
+		nonce:         query.Get("nonce"),
+		codeChallenge: query.Get("code_challenge"),
+	}
+	iss.mu.Unlock()
+
+	redirect, _ := url.Parse(RedirectURL)
+	redirectQuery := redirect.Query()
+	redirectQuery.Set("code", code)
+	redirectQuery.Set("state", query.Get("state"))
+	redirect.RawQuery = redirectQuery.Encode()
+
+	http.Redirect(w, r, redirect.String(), http.StatusFound)
+}
+
+func (iss *Issuer) token(w http.ResponseWriter, r *http.Request) {
+	clientID, clientSecret, ok := r.BasicAuth()
+	if !ok || clientID != ClientID || clientSecret != ClientSecret {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+
+	code := r.PostFormValue("code")
+
+	iss.mu.Lock()
+	auth, ok := iss.authorizations[code]
+	delete(iss.authorizations, code)
+	nonce := iss.nonce
+	iss.mu.Unlock()
+
+	if !ok || r.PostFormValue("grant_type") != "authorization_code" || r.PostFormValue("redirect_uri") != RedirectURL {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+	if oidc.CodeChallenge(r.PostFormValue("code_verifier")) != auth.codeChallenge {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error":             "invalid_grant",
+			"error_description": "code verifier does not match the code challenge",
+		})
+		return
+	}
+	if nonce == "" {
+		nonce = auth.nonce
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":            iss.URL(),
+		"sub":            auth.user.Subject,
+		"aud":            ClientID,
+		"exp":            time.Now().Add(time.Minute).Unix(),
+		"iat":            time.Now().Unix(),
+		"nonce":          nonce,
+		"email":          auth.user.Email,
+		"email_verified": auth.user.EmailVerified,
+		"name":           auth.user.Name,
+	})
+	token.Header["kid"] = keyID
+
+	idToken, err := token.SignedString(iss.key)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{
+		"access_token": randomString(),
+		"token_type":   "Bearer",
+		"id_token":     idToken,
+	})
+}
+
+func (iss *Issuer) jwks(w http.ResponseWriter, r *http.Request) {
+	pub := iss.key.PublicKey
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": keyID,
+			"use": "sig",
+			"alg": "RS256",
+			"n":   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		}},
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func randomString() string {
+	raw := make([]byte, 16)
+	_, _ = rand.Read(raw)
+
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
@@ -0,0 +1,40 @@
+package oidc
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+
+	"github.com/pkg/errors"
+)
+
+// NewCodeVerifier returns a new random PKCE code verifier, as specified by RFC 7636.
+//
+// The verifier is kept secret until the authorization code is exchanged,
+// proving that the exchange is made by whoever started the authorization.
+func NewCodeVerifier() (string, error) {
+	return randomString(32)
+}
+
+// CodeChallenge derives the S256 code challenge sent along with the authorization request from a code verifier.
+func CodeChallenge(verifier string) string {
+	hash := sha256.Sum256([]byte(verifier))
+
+	return base64.RawURLEncoding.EncodeToString(hash[:])
+}
+
+// NewNonce returns a new random nonce, which binds an ID token to the authorization request it was issued for.
+func NewNonce() (string, error) {
+	return randomString(16)
+}
+
+func randomString(size int) (string, error) {
+	raw := make([]byte, size)
+
+	_, err := rand.Read(raw)
+	if err != nil {
+		return "", errors.Wrap(err, "generate random string")
+	}
+
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
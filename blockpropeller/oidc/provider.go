@@ -0,0 +1,387 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/pkg/errors"
+)
+
+const (
+	discoveryPath = "/.well-known/openid-configuration"
+
+	// clockSkew is the clock difference tolerated between BlockPropeller and the identity provider.
+	clockSkew = time.Minute
+)
+
+var (
+	// ErrDisabled is returned when using single sign-on without configuring an identity provider.
+	ErrDisabled = errors.New("single sign-on is not configured")
+	// ErrInvalidIDToken is returned when the ID token issued by the identity provider cannot be trusted.
+	ErrInvalidIDToken = errors.New("invalid id token")
+)
+
+// Metadata is the subset of the identity provider discovery document used by BlockPropeller.
+type Metadata struct {
+	Issuer                        string   `json:"issuer"`
+	AuthorizationEndpoint         string   `json:"authorization_endpoint"`
+	TokenEndpoint                 string   `json:"token_endpoint"`
+	JWKSURI                       string   `json:"jwks_uri"`
+	CodeChallengeMethodsSupported []string `json:"code_challenge_methods_supported"`
+}
+
+// Claims hold the identity of the user authenticated by the identity provider.
+type Claims struct {
+	Issuer        string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+// Provider authenticates users with an OpenID Connect identity provider
+// through the authorization code flow with PKCE.
+//
+// The discovery document and signing keys of the identity provider are fetched on first use.
+type Provider struct {
+	cfg    *Config
+	client *http.Client
+
+	mu       sync.Mutex
+	metadata *Metadata
+	keys     map[string]*rsa.PublicKey
+}
+
+// NewProvider returns a new Provider instance.
+func NewProvider(cfg *Config) *Provider {
+	return &Provider{
+		cfg:    cfg,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Enabled checks whether an identity provider is configured.
+func (p *Provider) Enabled() bool {
+	return p.cfg.Enabled()
+}
+
+// AuthorizationURL returns the URL users are sent to for authenticating with the identity provider.
+func (p *Provider) AuthorizationURL(ctx context.Context, state, nonce, codeChallenge string) (string, error) {
+	metadata, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	authURL, err := url.Parse(metadata.AuthorizationEndpoint)
+	if err != nil {
+		return "", errors.Wrap(err, "parse authorization endpoint")
+	}
+
+	query := authURL.Query()
+	query.Set("response_type", "code")
+	query.Set("client_id", p.cfg.ClientID)
+	query.Set("redirect_uri", p.cfg.RedirectURL)
+	query.Set("scope", strings.Join(p.cfg.Scopes, " "))
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", codeChallenge)
+	query.Set("code_challenge_method", "S256")
+	authURL.RawQuery = query.Encode()
+
+	return authURL.String(), nil
+}
+
+// tokenResponse is the response of the identity provider token endpoint.
+type tokenResponse struct {
+	IDToken string `json:"id_token"`
+
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// Exchange an authorization code for an ID token, returning the Claims of the verified ID token.
+//
+// The codeVerifier and nonce must be the ones the authorization was started with.
+func (p *Provider) Exchange(ctx context.Context, code, codeVerifier, nonce string) (*Claims, error) {
+	metadata, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.cfg.RedirectURL)
+	form.Set("client_id", p.cfg.ClientID)
+	form.Set("code_verifier", codeVerifier)
+
+	req, err := http.NewRequest(http.MethodPost, metadata.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, errors.Wrap(err, "create token request")
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.cfg.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(p.cfg.ClientID), url.QueryEscape(p.cfg.ClientSecret))
+	}
+
+	var tokens tokenResponse
+	status, err := p.doJSON(req, &tokens)
+	if err != nil {
+		return nil, errors.Wrap(err, "exchange authorization code")
+	}
+	if status != http.StatusOK || tokens.Error != "" {
+		return nil, errors.Errorf("exchange authorization code: %d %s: %s", status, tokens.Error, tokens.ErrorDescription)
+	}
+	if tokens.IDToken == "" {
+		return nil, errors.Wrap(ErrInvalidIDToken, "missing id token")
+	}
+
+	return p.verify(ctx, metadata, tokens.IDToken, nonce)
+}
+
+// idTokenClaims are the claims of an ID token, as specified by OpenID Connect Core.
+type idTokenClaims struct {
+	Issuer    string   `json:"iss"`
+	Subject   string   `json:"sub"`
+	Audience  audience `json:"aud"`
+	AZP       string   `json:"azp"`
+	ExpiresAt int64    `json:"exp"`
+	IssuedAt  int64    `json:"iat"`
+	Nonce     string   `json:"nonce"`
+
+	Email         string       `json:"email"`
+	EmailVerified flexibleBool `json:"email_verified"`
+	Name          string       `json:"name"`
+}
+
+// Valid satisfies the jwt.Claims interface.
+func (c *idTokenClaims) Valid() error {
+	now := time.Now()
+	if c.ExpiresAt == 0 || now.After(time.Unix(c.ExpiresAt, 0).Add(clockSkew)) {
+		return errors.New("token is expired")
+	}
+	if c.IssuedAt != 0 && now.Add(clockSkew).Before(time.Unix(c.IssuedAt, 0)) {
+		return errors.New("token used before issued")
+	}
+
+	return nil
+}
+
+func (p *Provider) verify(ctx context.Context, metadata *Metadata, rawToken, nonce string) (*Claims, error) {
+	var claims idTokenClaims
+	parser := &jwt.Parser{ValidMethods: []string{jwt.SigningMethodRS256.Alg()}}
+	_, err := parser.ParseWithClaims(rawToken, &claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+
+		return p.key(ctx, metadata, kid)
+	})
+	if err != nil {
+		return nil, errors.Wrap(ErrInvalidIDToken, err.Error())
+	}
+
+	switch {
+	case claims.Issuer != metadata.Issuer:
+		return nil, errors.Wrapf(ErrInvalidIDToken, "unexpected issuer %s", claims.Issuer)
+	case !claims.Audience.contains(p.cfg.ClientID):
+		return nil, errors.Wrap(ErrInvalidIDToken, "token issued for another client")
+	case len(claims.Audience) > 1 && claims.AZP != p.cfg.ClientID:
+		return nil, errors.Wrap(ErrInvalidIDToken, "token authorized for another party")
+	case claims.Nonce != nonce:
+		return nil, errors.Wrap(ErrInvalidIDToken, "nonce mismatch")
+	case claims.Subject == "":
+		return nil, errors.Wrap(ErrInvalidIDToken, "missing subject")
+	}
+
+	return &Claims{
+		Issuer:        claims.Issuer,
+		Subject:       claims.Subject,
+		Email:         claims.Email,
+		EmailVerified: bool(claims.EmailVerified),
+		Name:          claims.Name,
+	}, nil
+}
+
+// key returns the signing key with the given ID, refreshing the keys once in case the key was rotated.
+func (p *Provider) key(ctx context.Context, metadata *Metadata, kid string) (*rsa.PublicKey, error) {
+	p.mu.Lock()
+	key, ok := p.keys[kid]
+	p.mu.Unlock()
+	if ok {
+		return key, nil
+	}
+
+	keys, err := p.fetchKeys(ctx, metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	p.keys = keys
+	p.mu.Unlock()
+
+	key, ok = keys[kid]
+	if !ok {
+		return nil, errors.Errorf("unknown signing key %q", kid)
+	}
+
+	return key, nil
+}
+
+// jwk is a single RSA key of a JSON Web Key Set, as specified by RFC 7517.
+type jwk struct {
+	KeyType string `json:"kty"`
+	KeyID   string `json:"kid"`
+	Use     string `json:"use"`
+	N       string `json:"n"`
+	E       string `json:"e"`
+}
+
+func (p *Provider) fetchKeys(ctx context.Context, metadata *Metadata) (map[string]*rsa.PublicKey, error) {
+	req, err := http.NewRequest(http.MethodGet, metadata.JWKSURI, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "create jwks request")
+	}
+
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	status, err := p.doJSON(req.WithContext(ctx), &set)
+	if err != nil {
+		return nil, errors.Wrap(err, "fetch jwks")
+	}
+	if status != http.StatusOK {
+		return nil, errors.Errorf("fetch jwks: unexpected status %d", status)
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(set.Keys))
+	for _, key := range set.Keys {
+		if key.KeyType != "RSA" || (key.Use != "" && key.Use != "sig") {
+			continue
+		}
+
+		n, err := base64.RawURLEncoding.DecodeString(key.N)
+		if err != nil {
+			return nil, errors.Wrapf(err, "decode modulus of key %s", key.KeyID)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(key.E)
+		if err != nil {
+			return nil, errors.Wrapf(err, "decode exponent of key %s", key.KeyID)
+		}
+
+		keys[key.KeyID] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+
+	return keys, nil
+}
+
+func (p *Provider) discover(ctx context.Context) (*Metadata, error) {
+	if !p.Enabled() {
+		return nil, ErrDisabled
+	}
+
+	p.mu.Lock()
+	metadata := p.metadata
+	p.mu.Unlock()
+	if metadata != nil {
+		return metadata, nil
+	}
+
+	req, err := http.NewRequest(http.MethodGet, strings.TrimSuffix(p.cfg.Issuer, "/")+discoveryPath, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "create discovery request")
+	}
+
+	metadata = &Metadata{}
+	status, err := p.doJSON(req.WithContext(ctx), metadata)
+	if err != nil {
+		return nil, errors.Wrap(err, "discover identity provider")
+	}
+	if status != http.StatusOK {
+		return nil, errors.Errorf("discover identity provider: unexpected status %d", status)
+	}
+	if metadata.Issuer != p.cfg.Issuer {
+		return nil, errors.Errorf("discovered issuer %s does not match configured issuer %s", metadata.Issuer, p.cfg.Issuer)
+	}
+	if len(metadata.CodeChallengeMethodsSupported) > 0 && !contains(metadata.CodeChallengeMethodsSupported, "S256") {
+		return nil, errors.New("identity provider does not support S256 PKCE code challenges")
+	}
+
+	p.mu.Lock()
+	p.metadata = metadata
+	p.mu.Unlock()
+
+	return metadata, nil
+}
+
+func (p *Provider) doJSON(req *http.Request, dest interface{}) (int, error) {
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	err = json.NewDecoder(resp.Body).Decode(dest)
+	if err != nil && resp.StatusCode == http.StatusOK {
+		return resp.StatusCode, errors.Wrap(err, "decode response")
+	}
+
+	return resp.StatusCode, nil
+}
+
+// audience is the `aud` claim, which is either a single string or an array of strings.
+type audience []string
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+
+	var multiple []string
+	if err := json.Unmarshal(data, &multiple); err != nil {
+		return errors.Wrap(err, "unmarshal audience")
+	}
+	*a = multiple
+
+	return nil
+}
+
+func (a audience) contains(clientID string) bool {
+	return contains(a, clientID)
+}
+
+// flexibleBool is a boolean claim, which some identity providers encode as a string.
+type flexibleBool bool
+
+func (b *flexibleBool) UnmarshalJSON(data []byte) error {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return errors.Wrap(err, "unmarshal boolean claim")
+	}
+
+	switch value := value.(type) {
+	case bool:
+		*b = flexibleBool(value)
+	case string:
+		*b = flexibleBool(value == "true")
+	default:
+		*b = false
+	}
+
+	return nil
+}
@@ -0,0 +1,107 @@
+package oidc_test
+
+import (
+	"context"
+	"net/url"
+	"testing"
+
+	"blockpropeller.dev/blockpropeller/oidc"
+	"blockpropeller.dev/blockpropeller/oidc/oidctest"
+	"blockpropeller.dev/lib/test"
+	"github.com/pkg/errors"
+)
+
+// authorize starts an authorization with the Provider and completes it with the Issuer,
+// returning the authorization code along with the code verifier and nonce it was started with.
+func authorize(t *testing.T, iss *oidctest.Issuer, provider *oidc.Provider) (code, verifier, nonce string) {
+	verifier, err := oidc.NewCodeVerifier()
+	test.CheckErr(t, "generate code verifier", err)
+
+	nonce, err = oidc.NewNonce()
+	test.CheckErr(t, "generate nonce", err)
+
+	authURL, err := provider.AuthorizationURL(context.Background(), "state", nonce, oidc.CodeChallenge(verifier))
+	test.CheckErr(t, "build authorization url", err)
+
+	parsed, err := url.Parse(authURL)
+	test.CheckErr(t, "parse authorization url", err)
+	test.AssertStringsEqual(t, "code challenge method", parsed.Query().Get("code_challenge_method"), "S256")
+	test.AssertStringsEqual(t, "scope", parsed.Query().Get("scope"), "openid email profile")
+
+	code, state, err := iss.Authorize(authURL)
+	test.CheckErr(t, "authorize", err)
+	test.AssertStringsEqual(t, "returned state", state, "state")
+
+	return code, verifier, nonce
+}
+
+func TestProviderExchange(t *testing.T) {
+	iss := oidctest.NewIssuer()
+	defer iss.Close()
+
+	iss.SetUser(oidctest.User{Subject: "user-1", Email: "user@example.com", EmailVerified: true, Name: "User"})
+
+	provider := oidc.NewProvider(iss.Config())
+	ctx := context.Background()
+
+	code, verifier, nonce := authorize(t, iss, provider)
+
+	claims, err := provider.Exchange(ctx, code, verifier, nonce)
+	test.CheckErr(t, "exchange authorization code", err)
+	test.AssertStringsEqual(t, "issuer", claims.Issuer, iss.URL())
+	test.AssertStringsEqual(t, "subject", claims.Subject, "user-1")
+	test.AssertStringsEqual(t, "email", claims.Email, "user@example.com")
+	test.AssertBoolEqual(t, "email verified", claims.EmailVerified, true)
+
+	_, err = provider.Exchange(ctx, code, verifier, nonce)
+	test.CheckErrExists(t, "exchange authorization code twice", err)
+
+	code, _, nonce = authorize(t, iss, provider)
+
+	otherVerifier, err := oidc.NewCodeVerifier()
+	test.CheckErr(t, "generate other code verifier", err)
+
+	_, err = provider.Exchange(ctx, code, otherVerifier, nonce)
+	test.CheckErrExists(t, "exchange authorization code with another code verifier", err)
+
+	code, verifier, _ = authorize(t, iss, provider)
+
+	_, err = provider.Exchange(ctx, code, verifier, "other-nonce")
+	test.AssertBoolEqual(t, "exchange with another nonce", errors.Cause(err) == oidc.ErrInvalidIDToken, true)
+}
+
+func TestProviderRejectsForeignTokens(t *testing.T) {
+	iss := oidctest.NewIssuer()
+	defer iss.Close()
+
+	iss.SetUser(oidctest.User{Subject: "user-1", Email: "user@example.com", EmailVerified: true})
+
+	ctx := context.Background()
+
+	// Authorization codes cannot be exchanged by another client.
+	cfg := iss.Config()
+	provider := oidc.NewProvider(cfg)
+
+	code, verifier, nonce := authorize(t, iss, provider)
+
+	cfg.ClientID = "other-client"
+
+	_, err := provider.Exchange(ctx, code, verifier, nonce)
+	test.CheckErrExists(t, "exchange authorization code as another client", err)
+
+	// Tokens issued for a replayed authorization carry the nonce of the original one.
+	provider = oidc.NewProvider(iss.Config())
+	iss.OverrideNonce("replayed-nonce")
+
+	code, verifier, nonce = authorize(t, iss, provider)
+
+	_, err = provider.Exchange(ctx, code, verifier, nonce)
+	test.AssertBoolEqual(t, "exchange token with replayed nonce", errors.Cause(err) == oidc.ErrInvalidIDToken, true)
+}
+
+func TestProviderDisabled(t *testing.T) {
+	provider := oidc.NewProvider(&oidc.Config{})
+
+	_, err := provider.AuthorizationURL(context.Background(), "state", "nonce", "challenge")
+	test.AssertBoolEqual(t, "authorization url without issuer", errors.Cause(err) == oidc.ErrDisabled, true)
+}
@@ -0,0 +1,9 @@
+package oidc
+
+import "github.com/google/wire"
+
+// Set is the Wire provider set for the oidc package
+// that does not depend on any underlying dependencies.
+var Set = wire.NewSet(
+	NewProvider,
+)
@@ -23,11 +23,28 @@ type DeploymentProvisioner struct {
 	ans *ansible.Ansible
 
 	deploymentRepo infrastructure.DeploymentRepository
+
+	tries         int
+	retryInterval time.Duration
 }
 
 // NewDeploymentProvisioner returns a new DeploymentProvisioner instance.
 func NewDeploymentProvisioner(ans *ansible.Ansible, deploymentRepo infrastructure.DeploymentRepository) *DeploymentProvisioner {
-	return &DeploymentProvisioner{ans: ans, deploymentRepo: deploymentRepo}
+	return &DeploymentProvisioner{
+		ans:            ans,
+		deploymentRepo: deploymentRepo,
+		tries:          60,
+		retryInterval:  10 * time.Second,
+	}
+}
+
+// WithRetry configures how many times the playbook is attempted while waiting
+// for the Server to become available, and how long to wait before each attempt.
+func (dp *DeploymentProvisioner) WithRetry(tries int, interval time.Duration) *DeploymentProvisioner {
+	dp.tries = tries
+	dp.retryInterval = interval
+
+	return dp
 }
 
 // Provision configures the specified Deployment on a target Server.
@@ -51,11 +68,11 @@ func (dp *DeploymentProvisioner) Provision(ctx context.Context, srv *infrastruct
 
 	log.Debug("running playbook...")
 
-	for tries := 60; tries > 0; tries-- {
+	for tries := dp.tries; tries > 0; tries-- {
 		log.Debug("waiting for server to become available", log.Fields{
-			"seconds": 10,
+			"seconds": dp.retryInterval.Seconds(),
 		})
-		time.Sleep(10 * time.Second)
+		time.Sleep(dp.retryInterval)
 
 		err = dp.ans.ProvisionServer(srv, deployment)
 		if err != nil {
@@ -81,6 +98,56 @@ func (dp *DeploymentProvisioner) Provision(ctx context.Context, srv *infrastruct
 	return nil
 }
 
+// Deprovision removes all Deployments from a target Server and revokes
+// the BlockPropeller SSHKey, leaving the rest of the machine intact.
+//
+// Additional revoked public keys are removed before the SSHKey, which is used for connecting to the Server.
+func (dp *DeploymentProvisioner) Deprovision(ctx context.Context, srv *infrastructure.Server, revokedKeys ...string) error {
+	deployments, err := dp.deploymentRepo.FindByServer(ctx, srv.ID)
+	if err != nil {
+		return errors.Wrap(err, "find server deployments")
+	}
+
+	for _, deployment := range deployments {
+		log.Debug("removing deployment", log.Fields{
+			"deployment": deployment.ID,
+		})
+
+		err = dp.ans.DeprovisionServer(srv, deployment)
+		if err != nil {
+			return errors.Wrapf(err, "deprovision deployment %s", deployment.ID)
+		}
+	}
+
+	for _, pubKey := range revokedKeys {
+		err = dp.ans.RemoveAuthorizedKey(srv, pubKey)
+		if err != nil {
+			return errors.Wrap(err, "remove revoked key")
+		}
+	}
+
+	err = dp.ans.RemoveAuthorizedKey(srv, srv.SSHKey.EncodedPublicKey())
+	if err != nil {
+		return errors.Wrap(err, "remove ssh key")
+	}
+
+	return nil
+}
+
+// GrowVolume grows the filesystem on the data volume of a Server after the volume has been resized.
+func (dp *DeploymentProvisioner) GrowVolume(ctx context.Context, srv *infrastructure.Server) error {
+	if srv.State != infrastructure.ServerStateOk {
+		return ErrServerNotReadyForDeployments
+	}
+
+	err := dp.ans.GrowVolume(srv)
+	if err != nil {
+		return errors.Wrap(err, "failed running playbook on server")
+	}
+
+	return nil
+}
+
 // AddAuthorizedKey registers an additional authorized key so it can connect to the server.
 func (dp *DeploymentProvisioner) AddAuthorizedKey(srv *infrastructure.Server, pubKey string) error {
 	//@TODO: There is no need for this indirection. AddAuthorizedKey should be removed from Ansible and put instead of this proxy call.
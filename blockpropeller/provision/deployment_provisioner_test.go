@@ -0,0 +1,172 @@
+package provision_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"blockpropeller.dev/blockpropeller/ansible"
+	"blockpropeller.dev/blockpropeller/infrastructure"
+	"blockpropeller.dev/blockpropeller/provision"
+	"blockpropeller.dev/lib/test"
+)
+
+func TestDeploymentProvisioner_Provision(t *testing.T) {
+	f := newFixture(t)
+	defer test.Close(t, f)
+
+	// The server is not reachable right after it has been created.
+	f.ansible.OnCall("site.yaml", 1).Exit(4)
+
+	srv := f.newServer(t, infrastructure.ProviderDigitalOcean)
+	srv.State = infrastructure.ServerStateOk
+	srv.IPAddress = "203.0.113.10"
+	deployment := f.newDeployment(t, srv)
+
+	err := f.deploymentProvisioner.Provision(context.Background(), srv, deployment)
+	test.CheckErr(t, "Provision()", err)
+
+	calls := f.ansible.Calls()
+	test.AssertIntsEqual(t, "ansible-playbook calls", len(calls), 3)
+	test.AssertStringsEqual(t, "version call", calls[0][0], "--version")
+
+	playbookCall := strings.Join(calls[2], " ")
+	for _, want := range []string{"--inventory 203.0.113.10,", "binance_node_type='lightnode'", "site.yaml"} {
+		if !strings.Contains(playbookCall, want) {
+			t.Errorf("playbook call %q does not contain %q", playbookCall, want)
+		}
+	}
+
+	stored, err := f.deploymentRepo.Find(context.Background(), deployment.ID)
+	test.CheckErr(t, "find deployment", err)
+	test.AssertStringsEqual(t, "Deployment.State", stored.State.String(), infrastructure.DeploymentStateOk.String())
+}
+
+func TestDeploymentProvisioner_ProvisionCustomSSHPort(t *testing.T) {
+	f := newFixture(t)
+	defer test.Close(t, f)
+
+	srv := f.newServer(t, infrastructure.ProviderBYO)
+	srv.State = infrastructure.ServerStateOk
+	srv.IPAddress = "203.0.113.10"
+	srv.SSHPort = 2222
+	deployment := f.newDeployment(t, srv)
+
+	err := f.deploymentProvisioner.Provision(context.Background(), srv, deployment)
+	test.CheckErr(t, "Provision()", err)
+
+	calls := f.ansible.Calls()
+	playbookCall := strings.Join(calls[len(calls)-1], " ")
+	if !strings.Contains(playbookCall, "ansible_port='2222'") {
+		t.Errorf("playbook call %q does not set ansible_port", playbookCall)
+	}
+}
+
+func TestDeploymentProvisioner_ProvisionFailures(t *testing.T) {
+	tests := []struct {
+		name      string
+		setup     func(f *fixture)
+		wantCalls int
+		wantCause error
+	}{
+		{
+			name: "ansible not executable",
+			setup: func(f *fixture) {
+				f.ansible.On("--version").Exit(127)
+			},
+			wantCalls: 1,
+		},
+		{
+			name: "server never reachable",
+			setup: func(f *fixture) {
+				f.ansible.On("site.yaml").Exit(4)
+			},
+			wantCalls: 4,
+			wantCause: ansible.ErrServerUnreachable,
+		},
+		{
+			name: "playbook fails",
+			setup: func(f *fixture) {
+				f.ansible.On("site.yaml").Stderr("fatal: [203.0.113.10]: FAILED!").Exit(2)
+			},
+			wantCalls: 4,
+		},
+		{
+			name: "playbook hangs",
+			setup: func(f *fixture) {
+				f.ansible.On("site.yaml").Hang()
+			},
+			wantCalls: 4,
+			wantCause: ansible.ErrTimeout,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFixture(t)
+			defer test.Close(t, f)
+
+			tt.setup(f)
+
+			srv := f.newServer(t, infrastructure.ProviderDigitalOcean)
+			srv.State = infrastructure.ServerStateOk
+			srv.IPAddress = "203.0.113.10"
+			deployment := f.newDeployment(t, srv)
+
+			err := f.deploymentProvisioner.Provision(context.Background(), srv, deployment)
+			if tt.wantCause != nil {
+				assertErrCause(t, "Provision()", err, tt.wantCause)
+			} else {
+				test.CheckErrExists(t, "Provision()", err)
+			}
+
+			test.AssertIntsEqual(t, "ansible-playbook calls", len(f.ansible.Calls()), tt.wantCalls)
+			test.AssertStringsEqual(t, "Deployment.State",
+				deployment.State.String(), infrastructure.DeploymentStateRequested.String())
+		})
+	}
+}
+
+func TestDeploymentProvisioner_ProvisionInvalidState(t *testing.T) {
+	f := newFixture(t)
+	defer test.Close(t, f)
+
+	srv := f.newServer(t, infrastructure.ProviderDigitalOcean)
+	deployment := f.newDeployment(t, srv)
+
+	err := f.deploymentProvisioner.Provision(context.Background(), srv, deployment)
+	assertErrCause(t, "Provision() on requested server", err, provision.ErrServerNotReadyForDeployments)
+
+	srv.State = infrastructure.ServerStateOk
+	deployment.State = infrastructure.DeploymentStateOk
+
+	err = f.deploymentProvisioner.Provision(context.Background(), srv, deployment)
+	assertErrCause(t, "Provision() on provisioned deployment", err, provision.ErrDeploymentNotInRequestedState)
+
+	test.AssertIntsEqual(t, "ansible-playbook calls", len(f.ansible.Calls()), 0)
+}
+
+func TestDeploymentProvisioner_GrowVolume(t *testing.T) {
+	f := newFixture(t)
+	defer test.Close(t, f)
+
+	srv := f.newServer(t, infrastructure.ProviderDigitalOcean)
+	srv.IPAddress = "203.0.113.10"
+
+	err := f.deploymentProvisioner.GrowVolume(context.Background(), srv)
+	assertErrCause(t, "GrowVolume() requested server", err, provision.ErrServerNotReadyForDeployments)
+
+	srv.State = infrastructure.ServerStateOk
+
+	err = f.deploymentProvisioner.GrowVolume(context.Background(), srv)
+	test.CheckErr(t, "GrowVolume()", err)
+
+	calls := f.ansible.Calls()
+	test.AssertIntsEqual(t, "ansible-playbook calls", len(calls), 1)
+
+	playbookCall := strings.Join(calls[0], " ")
+	for _, want := range []string{"--inventory 203.0.113.10,", "grow_volume.yaml"} {
+		if !strings.Contains(playbookCall, want) {
+			t.Errorf("playbook call %q does not contain %q", playbookCall, want)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package provision
+
+import (
+	"blockpropeller.dev/blockpropeller/infrastructure"
+	"github.com/pkg/errors"
+)
+
+// FirewallConfig holds configuration for the firewalls of provisioned Servers.
+//
+// ManagementIPs are allowed to reach the restricted ports of every Server,
+// and must include the addresses BlockPropeller runs health checks from.
+type FirewallConfig struct {
+	ManagementIPs []string `yaml:"management_ips"`
+}
+
+// Validate satisfies the config.Config interface.
+//
+// ManagementIPs are required, as restricted ports without any allowed
+// sources are closed, failing the health checks of the Deployments.
+func (cfg *FirewallConfig) Validate() error {
+	if len(cfg.ManagementIPs) == 0 {
+		return errors.New("missing firewall management ips")
+	}
+
+	for _, raw := range cfg.ManagementIPs {
+		_, err := infrastructure.ParseAllowedIP(raw)
+		if err != nil {
+			return errors.Wrap(err, "invalid firewall management ip")
+		}
+	}
+
+	return nil
+}
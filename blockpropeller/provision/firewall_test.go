@@ -0,0 +1,31 @@
+package provision_test
+
+import (
+	"testing"
+
+	"blockpropeller.dev/blockpropeller/provision"
+	"blockpropeller.dev/lib/test"
+)
+
+func TestFirewallConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name          string
+		managementIPs []string
+		valid         bool
+	}{
+		{"management ips", []string{"198.51.100.7", "203.0.113.0/24"}, true},
+		{"missing management ips", nil, false},
+		{"invalid management ip", []string{"not-an-ip"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := (&provision.FirewallConfig{ManagementIPs: tt.managementIPs}).Validate()
+			if !tt.valid {
+				test.CheckErrExists(t, "Validate()", err)
+				return
+			}
+
+			test.CheckErr(t, "Validate()", err)
+		})
+	}
+}
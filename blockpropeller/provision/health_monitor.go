@@ -0,0 +1,245 @@
+package provision
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"blockpropeller.dev/blockpropeller/infrastructure"
+	"blockpropeller.dev/blockpropeller/terraform/cloudprovider"
+	"blockpropeller.dev/lib/log"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	defaultSSHPort   = 22
+	sshDialTimeout   = 30 * time.Second
+	diskUsageCommand = "df -P -k " + cloudprovider.VolumeMountPath
+)
+
+// HealthCheckConfig holds configuration for periodic Server health checks.
+type HealthCheckConfig struct {
+	Interval time.Duration `yaml:"interval"`
+}
+
+// Validate satisfies the config.Config interface.
+func (cfg *HealthCheckConfig) Validate() error {
+	if cfg.Interval == 0 {
+		cfg.Interval = 5 * time.Minute
+	}
+
+	return nil
+}
+
+// HealthMonitor periodically checks the health of provisioned Servers and their Deployments.
+//
+// Disk usage of the Server data volume is collected over SSH, warning about or scheduling
+// a volume resize once the disk thresholds configured for the Server are reached.
+type HealthMonitor struct {
+	interval    time.Duration
+	dialTimeout time.Duration
+
+	jobScheduler *JobScheduler
+
+	jobRepo      JobRepository
+	srvRepo      infrastructure.ServerRepository
+	settingsRepo infrastructure.ProviderSettingsRepository
+}
+
+// NewHealthMonitor returns a new HealthMonitor instance.
+func NewHealthMonitor(
+	cfg *HealthCheckConfig,
+	jobScheduler *JobScheduler,
+	jobRepo JobRepository,
+	srvRepo infrastructure.ServerRepository,
+	settingsRepo infrastructure.ProviderSettingsRepository,
+) *HealthMonitor {
+	return &HealthMonitor{
+		interval:     cfg.Interval,
+		dialTimeout:  sshDialTimeout,
+		jobScheduler: jobScheduler,
+		jobRepo:      jobRepo,
+		srvRepo:      srvRepo,
+		settingsRepo: settingsRepo,
+	}
+}
+
+// Start checking the health of all provisioned Servers periodically, until the Context is finished.
+func (hm *HealthMonitor) Start(ctx context.Context) {
+	for ctx.Err() == nil {
+		hm.checkAll(ctx)
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(hm.interval):
+		}
+	}
+}
+
+func (hm *HealthMonitor) checkAll(ctx context.Context) {
+	servers, err := hm.srvRepo.ListByState(ctx, infrastructure.ServerStateOk)
+	if err != nil {
+		log.ErrorErr(err, "failed listing servers for health checks")
+		return
+	}
+
+	jobs, err := hm.jobRepo.FindIncomplete(ctx)
+	if err != nil {
+		log.ErrorErr(err, "failed finding incomplete jobs for health checks")
+		return
+	}
+
+	busy := make(map[infrastructure.ServerID]bool)
+	for _, job := range jobs {
+		if job.FinishedAt == nil {
+			busy[job.ServerID] = true
+		}
+	}
+
+	for _, srv := range servers {
+		// Servers are updated by running jobs, so they are checked once the jobs finish.
+		if busy[srv.ID] {
+			continue
+		}
+
+		err = hm.Check(ctx, srv)
+		if err != nil {
+			log.ErrorErr(err, "server health check failed", log.Fields{
+				"server_id": srv.ID,
+			})
+		}
+	}
+}
+
+// Check runs the health checks of a single Server.
+//
+// Disk usage is only collected for Servers with a data volume.
+func (hm *HealthMonitor) Check(ctx context.Context, srv *infrastructure.Server) error {
+	if srv.VolumeSize > 0 {
+		err := hm.checkDiskUsage(ctx, srv)
+		if err != nil {
+			return errors.Wrap(err, "check disk usage")
+		}
+	}
+
+	for _, deployment := range srv.Deployments {
+		err := infrastructure.CheckHealth(srv, deployment)
+		if err != nil {
+			return errors.Wrapf(err, "check deployment %s health", deployment.ID)
+		}
+	}
+
+	return nil
+}
+
+func (hm *HealthMonitor) checkDiskUsage(ctx context.Context, srv *infrastructure.Server) error {
+	out, err := hm.runCommand(srv, diskUsageCommand)
+	if err != nil {
+		return errors.Wrap(err, "collect disk usage")
+	}
+
+	usage, err := infrastructure.ParseDiskUsage(out)
+	if err != nil {
+		return errors.Wrap(err, "parse disk usage")
+	}
+
+	srv.DiskUsage = usage
+
+	err = hm.srvRepo.Update(ctx, srv)
+	if err != nil {
+		return errors.Wrap(err, "update server disk usage")
+	}
+
+	switch srv.DiskThresholds.Evaluate(usage) {
+	case infrastructure.DiskActionWarn:
+		log.Warn("server disk is nearly full", log.Fields{
+			"server_id": srv.ID,
+			"percent":   usage.Percent(),
+		})
+	case infrastructure.DiskActionExpand:
+		return hm.expandVolume(ctx, srv)
+	}
+
+	return nil
+}
+
+func (hm *HealthMonitor) expandVolume(ctx context.Context, srv *infrastructure.Server) error {
+	size := srv.DiskThresholds.ExpandedSize(srv.VolumeSize)
+
+	cloudProvider, err := cloudprovider.GetProvider(srv.Provider)
+	if err != nil {
+		return errors.Wrap(err, "get cloud provider")
+	}
+
+	err = cloudProvider.Catalog().ValidateVolumeSize(size)
+	if err != nil {
+		return errors.Wrap(err, "expand volume")
+	}
+
+	provider, err := hm.settingsRepo.Find(ctx, srv.ProviderSettingsID)
+	if err != nil {
+		return errors.Wrap(err, "find provider settings")
+	}
+
+	job, err := hm.jobScheduler.ScheduleVolumeResize(ctx, provider, srv, size)
+	if errors.Cause(err) == ErrVolumeResizeInProgress || errors.Cause(err) == ErrServerUpdateInProgress {
+		// The volume is expanded on a later health check if it is still nearly full.
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "schedule volume resize")
+	}
+
+	log.Info("scheduled volume expansion", log.Fields{
+		"server_id": srv.ID,
+		"job_id":    job.ID,
+		"size":      size,
+	})
+
+	return nil
+}
+
+// runCommand executes a shell command on the Server as root, authenticating with the Server SSHKey.
+func (hm *HealthMonitor) runCommand(srv *infrastructure.Server, cmd string) (string, error) {
+	signer, err := ssh.NewSignerFromKey(&srv.SSHKey.PrivateKey.PrivateKey)
+	if err != nil {
+		return "", errors.Wrap(err, "prepare ssh key")
+	}
+
+	port := srv.SSHPort
+	if port == 0 {
+		port = defaultSSHPort
+	}
+
+	client, err := ssh.Dial("tcp", net.JoinHostPort(srv.IPAddress, strconv.Itoa(port)), &ssh.ClientConfig{
+		User: "root",
+		Auth: []ssh.AuthMethod{ssh.PublicKeys(signer)},
+		// Host keys are not verified, same as with Ansible provisioning.
+		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+		Timeout:         hm.dialTimeout,
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "connect to server")
+	}
+	defer log.Closer(client)
+
+	session, err := client.NewSession()
+	if err != nil {
+		return "", errors.Wrap(err, "open ssh session")
+	}
+
+	var stdout, stderr bytes.Buffer
+	session.Stdout = &stdout
+	session.Stderr = &stderr
+
+	err = session.Run(cmd)
+	if err != nil {
+		return "", errors.Wrapf(err, "run command: %s", strings.TrimSpace(stderr.String()))
+	}
+
+	return stdout.String(), nil
+}
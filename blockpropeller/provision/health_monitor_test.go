@@ -0,0 +1,255 @@
+package provision_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/binary"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+
+	"blockpropeller.dev/blockpropeller/infrastructure"
+	"blockpropeller.dev/lib/test"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/ssh"
+)
+
+// 90% of the data volume is used.
+const dfOutput = `Filesystem     1024-blocks      Used Available Capacity Mounted on
+/dev/sda         524288000 471859200  52428800      90% /mnt/volume
+`
+
+var (
+	deploymentTypeHealthy   = infrastructure.NewDeploymentType("healthy")
+	deploymentTypeUnhealthy = infrastructure.NewDeploymentType("unhealthy")
+)
+
+func init() {
+	infrastructure.RegisterDeploymentType(deploymentTypeHealthy, testDeploymentSpec{})
+	infrastructure.RegisterDeploymentType(deploymentTypeUnhealthy, testDeploymentSpec{err: errors.New("node not syncing")})
+}
+
+// testDeploymentSpec reports a fixed health, without reaching out to the Server.
+type testDeploymentSpec struct {
+	err error
+}
+
+func (testDeploymentSpec) UnmarshalConfig(map[string]string) (infrastructure.DeploymentConfig, error) {
+	return nil, nil
+}
+
+func (spec testDeploymentSpec) HealthCheck(*infrastructure.Server, *infrastructure.Deployment) (infrastructure.HealthCheck, error) {
+	return spec, nil
+}
+
+func (testDeploymentSpec) Ports(*infrastructure.Deployment) infrastructure.DeploymentPorts {
+	return infrastructure.DeploymentPorts{}
+}
+
+func (spec testDeploymentSpec) Health() error {
+	return spec.err
+}
+
+func TestHealthMonitor_Check(t *testing.T) {
+	tests := []struct {
+		name       string
+		thresholds infrastructure.DiskThresholds
+		wantJob    bool
+	}{
+		{"no thresholds", infrastructure.DiskThresholds{}, false},
+		{"warn threshold", infrastructure.DiskThresholds{WarnPercent: 80}, false},
+		{"expand threshold", infrastructure.DiskThresholds{WarnPercent: 80, ExpandPercent: 90}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFixture(t)
+			defer test.Close(t, f)
+
+			srv := f.monitoredServer(t)
+			srv.DiskThresholds = tt.thresholds
+
+			sshSrv := newSSHServer(t, srv.SSHKey, dfOutput)
+			defer test.Close(t, sshSrv.listener)
+			srv.SSHPort = sshSrv.port()
+
+			err := f.healthMonitor.Check(context.Background(), srv)
+			test.CheckErr(t, "Check()", err)
+
+			test.AssertStringsEqual(t, "ssh command", <-sshSrv.cmds, "df -P -k /mnt/volume")
+
+			stored, err := f.srvRepo.Find(context.Background(), srv.ID)
+			test.CheckErr(t, "find server", err)
+			test.AssertIntsEqual(t, "disk usage percent", int(stored.DiskUsage.Percent()), 90)
+			test.AssertBoolEqual(t, "disk usage checked", stored.DiskUsage.CheckedAt != nil, true)
+
+			jobs, err := f.jobRepo.List(context.Background(), srv.AccountID)
+			test.CheckErr(t, "list jobs", err)
+
+			if !tt.wantJob {
+				test.AssertIntsEqual(t, "scheduled jobs", len(jobs), 0)
+				return
+			}
+
+			test.AssertIntsEqual(t, "scheduled jobs", len(jobs), 1)
+			test.AssertIntsEqual(t, "Job.VolumeSize", jobs[0].VolumeSize, 750)
+
+			// Repeated checks do not schedule another resize while one is in progress.
+			err = f.healthMonitor.Check(context.Background(), srv)
+			test.CheckErr(t, "repeated Check()", err)
+
+			jobs, err = f.jobRepo.List(context.Background(), srv.AccountID)
+			test.CheckErr(t, "list jobs", err)
+			test.AssertIntsEqual(t, "scheduled jobs after repeated check", len(jobs), 1)
+		})
+	}
+}
+
+func TestHealthMonitor_CheckUnhealthyDeployment(t *testing.T) {
+	f := newFixture(t)
+	defer test.Close(t, f)
+
+	srv := f.monitoredServer(t)
+	srv.Deployments[0].Type = deploymentTypeUnhealthy
+
+	sshSrv := newSSHServer(t, srv.SSHKey, dfOutput)
+	defer test.Close(t, sshSrv.listener)
+	srv.SSHPort = sshSrv.port()
+
+	err := f.healthMonitor.Check(context.Background(), srv)
+	test.CheckErrExists(t, "Check()", err)
+
+	stored, err := f.srvRepo.Find(context.Background(), srv.ID)
+	test.CheckErr(t, "find server", err)
+	test.AssertBoolEqual(t, "disk usage checked", stored.DiskUsage.CheckedAt != nil, true)
+}
+
+func TestHealthMonitor_CheckUnreachable(t *testing.T) {
+	f := newFixture(t)
+	defer test.Close(t, f)
+
+	srv := f.monitoredServer(t)
+
+	// Reserve a port nobody listens on.
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	test.CheckErr(t, "listen", err)
+	srv.SSHPort = listener.Addr().(*net.TCPAddr).Port
+	test.Close(t, listener)
+
+	err = f.healthMonitor.Check(context.Background(), srv)
+	test.CheckErrExists(t, "Check()", err)
+
+	stored, err := f.srvRepo.Find(context.Background(), srv.ID)
+	test.CheckErr(t, "find server", err)
+	test.AssertBoolEqual(t, "disk usage checked", stored.DiskUsage.CheckedAt != nil, false)
+}
+
+// monitoredServer returns a provisioned Server with a 500 GB data volume reachable on localhost.
+func (f *fixture) monitoredServer(t *testing.T) *infrastructure.Server {
+	srv := f.provisionedServer(t)
+	srv.IPAddress = "127.0.0.1"
+	srv.VolumeSize = 500
+
+	f.newDeployment(t, srv).Type = deploymentTypeHealthy
+
+	return srv
+}
+
+// sshServer is a minimal SSH server accepting the Server SSHKey and
+// replying to every executed command with the same output.
+type sshServer struct {
+	listener net.Listener
+	output   string
+	cmds     chan string
+	// onExec, when set, is called with every executed command.
+	onExec func(cmd string)
+}
+
+func newSSHServer(t *testing.T, sshKey *infrastructure.SSHKey, output string) *sshServer {
+	hostKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	test.CheckErr(t, "generate host key", err)
+
+	signer, err := ssh.NewSignerFromKey(hostKey)
+	test.CheckErr(t, "create host key signer", err)
+
+	authorized, _, _, _, err := ssh.ParseAuthorizedKey([]byte(sshKey.EncodedPublicKey()))
+	test.CheckErr(t, "parse authorized key", err)
+
+	cfg := &ssh.ServerConfig{
+		PublicKeyCallback: func(conn ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if conn.User() != "root" || !bytes.Equal(key.Marshal(), authorized.Marshal()) {
+				return nil, ssh.ErrNoAuth
+			}
+
+			return nil, nil
+		},
+	}
+	cfg.AddHostKey(signer)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	test.CheckErr(t, "listen", err)
+
+	srv := &sshServer{listener: listener, output: output, cmds: make(chan string, 10)}
+	go srv.serve(cfg)
+
+	return srv
+}
+
+func (srv *sshServer) port() int {
+	port, _ := strconv.Atoi(strings.Split(srv.listener.Addr().String(), ":")[1])
+
+	return port
+}
+
+func (srv *sshServer) serve(cfg *ssh.ServerConfig) {
+	for {
+		conn, err := srv.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		go srv.handleConn(conn, cfg)
+	}
+}
+
+func (srv *sshServer) handleConn(conn net.Conn, cfg *ssh.ServerConfig) {
+	_, chans, reqs, err := ssh.NewServerConn(conn, cfg)
+	if err != nil {
+		return
+	}
+	go ssh.DiscardRequests(reqs)
+
+	for newChan := range chans {
+		ch, chReqs, err := newChan.Accept()
+		if err != nil {
+			continue
+		}
+
+		go srv.handleSession(ch, chReqs)
+	}
+}
+
+func (srv *sshServer) handleSession(ch ssh.Channel, reqs <-chan *ssh.Request) {
+	for req := range reqs {
+		if req.Type != "exec" {
+			_ = req.Reply(false, nil)
+			continue
+		}
+		_ = req.Reply(true, nil)
+
+		cmdLen := binary.BigEndian.Uint32(req.Payload[:4])
+		cmd := string(req.Payload[4 : 4+cmdLen])
+		if srv.onExec != nil {
+			srv.onExec(cmd)
+		}
+		srv.cmds <- cmd
+
+		_, _ = ch.Write([]byte(srv.output))
+		_, _ = ch.SendRequest("exit-status", false, []byte{0, 0, 0, 0})
+		_ = ch.Close()
+
+		return
+	}
+}
@@ -2,6 +2,7 @@ package provision
 
 import (
 	"context"
+	"strings"
 	"sync"
 	"time"
 
@@ -40,8 +41,10 @@ func (id JobID) String() string {
 // Once complete, a Job serves only for record keeping, and is not concerned with any other
 // actions on the created entities.
 type Job struct {
-	ID        JobID      `json:"id" gorm:"type:varchar(36) not null"`
-	AccountID account.ID `json:"-" gorm:"type:varchar(36) not null references accounts(id)"`
+	ID             JobID                    `json:"id" gorm:"type:varchar(36) not null"`
+	AccountID      account.ID               `json:"-" gorm:"type:varchar(36) not null references accounts(id)"`
+	OrganizationID account.OrganizationID   `json:"organization_id,omitempty" gorm:"type:varchar(36) references organizations(id)"`
+	ProjectID      infrastructure.ProjectID `json:"project_id,omitempty" gorm:"type:varchar(36) references projects(id)"`
 
 	statemachine.Resource `gorm:"embedded"`
 
@@ -54,6 +57,16 @@ type Job struct {
 	DeploymentID infrastructure.DeploymentID `json:"-" gorm:"type:varchar(36) references deployments(id)"`
 	Deployment   *infrastructure.Deployment  `json:"deployment"`
 
+	// VolumeSize in GB requested by a volume resize Job.
+	VolumeSize int `json:"volume_size,omitempty" gorm:"type:integer not null;default:0"`
+
+	// AllowedIPs requested by a firewall update Job.
+	AllowedIPs    []string `json:"allowed_ips,omitempty" gorm:"-"`
+	RawAllowedIPs string   `json:"-" gorm:"column:allowed_ips;type:text"`
+
+	// Labels requested by a labels update Job, for the Job Deployment or the Server.
+	Labels infrastructure.Labels `json:"labels,omitempty" gorm:"type:text"`
+
 	CreatedAt  time.Time  `json:"created_at" gorm:"type:timestamp not null;default:CURRENT_TIMESTAMP"`
 	UpdatedAt  time.Time  `json:"updated_at" gorm:"type:timestamp not null;default:CURRENT_TIMESTAMP"`
 	FinishedAt *time.Time `json:"finished_at,omitempty" gorm:"type:timestamp"`
@@ -68,8 +81,10 @@ func NewJob(
 	deployment *infrastructure.Deployment,
 ) *Job {
 	return &Job{
-		ID:        NewJobID(),
-		AccountID: accountID,
+		ID:             NewJobID(),
+		AccountID:      accountID,
+		OrganizationID: server.OrganizationID,
+		ProjectID:      server.ProjectID,
 
 		Resource: statemachine.NewResource(StateCreated),
 
@@ -87,6 +102,124 @@ func NewJob(
 	}
 }
 
+// NewVolumeResizeJob returns a new Job growing the data volume of an existing Server to the provided size in GB.
+//
+// The Job references the Server Deployment whose data is kept on the volume.
+func NewVolumeResizeJob(
+	accountID account.ID,
+	provider *infrastructure.ProviderSettings,
+	server *infrastructure.Server,
+	size int,
+) (*Job, error) {
+	if len(server.Deployments) == 0 {
+		return nil, errors.New("server has no deployments")
+	}
+
+	err := server.ValidateVolumeSize(size)
+	if err != nil {
+		return nil, err
+	}
+
+	job := NewJob(accountID, provider, server, server.Deployments[0])
+	job.SetState(StateVolumeResizeRequested)
+	job.VolumeSize = size
+
+	return job, nil
+}
+
+// NewFirewallUpdateJob returns a new Job replacing the firewall allow-list of an existing Server
+// with the provided addresses in CIDR notation.
+//
+// The Job references the first Server Deployment, as every Job references a Deployment.
+func NewFirewallUpdateJob(
+	accountID account.ID,
+	provider *infrastructure.ProviderSettings,
+	server *infrastructure.Server,
+	allowedIPs []string,
+) (*Job, error) {
+	if len(server.Deployments) == 0 {
+		return nil, errors.New("server has no deployments")
+	}
+
+	parsed, err := infrastructure.ParseAllowedIPs(allowedIPs)
+	if err != nil {
+		return nil, err
+	}
+
+	job := NewJob(accountID, provider, server, server.Deployments[0])
+	job.SetState(StateFirewallUpdateRequested)
+	job.AllowedIPs = parsed
+
+	return job, nil
+}
+
+// NewServerLabelsJob returns a new Job replacing the labels of an existing Server.
+//
+// The Job references the first Server Deployment, as every Job references a Deployment.
+func NewServerLabelsJob(
+	accountID account.ID,
+	provider *infrastructure.ProviderSettings,
+	server *infrastructure.Server,
+	labels infrastructure.Labels,
+) (*Job, error) {
+	if len(server.Deployments) == 0 {
+		return nil, errors.New("server has no deployments")
+	}
+
+	err := labels.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	job := NewJob(accountID, provider, server, server.Deployments[0])
+	job.SetState(StateServerLabelsRequested)
+	job.Labels = labels
+
+	return job, nil
+}
+
+// NewDeploymentLabelsJob returns a new Job replacing the labels of a Deployment running on an existing Server.
+func NewDeploymentLabelsJob(
+	accountID account.ID,
+	provider *infrastructure.ProviderSettings,
+	server *infrastructure.Server,
+	deployment *infrastructure.Deployment,
+	labels infrastructure.Labels,
+) (*Job, error) {
+	err := labels.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	job := NewJob(accountID, provider, server, deployment)
+	job.SetState(StateDeploymentLabelsRequested)
+	job.Labels = labels
+
+	return job, nil
+}
+
+// BeforeSave GORM Hook.
+func (job *Job) BeforeSave() error {
+	job.RawAllowedIPs = strings.Join(job.AllowedIPs, ",")
+
+	return nil
+}
+
+// AfterFind GORM Hook.
+func (job *Job) AfterFind() error {
+	job.AllowedIPs = nil
+	if job.RawAllowedIPs != "" {
+		job.AllowedIPs = strings.Split(job.RawAllowedIPs, ",")
+	}
+
+	return nil
+}
+
+// IsVolumeResize returns whether the Job resizes the data volume of an existing Server.
+func (job *Job) IsVolumeResize() bool {
+	return job.VolumeSize > 0
+}
+
 // JobBuilder allows for fluent job definition.
 type JobBuilder struct {
 	accountID     account.ID
@@ -137,6 +270,13 @@ func (b *JobBuilder) Build() (*Job, error) {
 	}
 
 	b.server.AddDeployment(b.deployment)
+	b.server.ProviderSettingsID = b.provider.ID
+	if b.server.OrganizationID == account.NilOrganizationID {
+		b.server.OrganizationID = b.provider.OrganizationID
+	}
+	if b.server.ProjectID == infrastructure.NilProjectID {
+		b.server.ProjectID = b.provider.ProjectID
+	}
 
 	return NewJob(b.accountID, b.provider, b.server, b.deployment), nil
 }
@@ -152,6 +292,12 @@ type JobRepository interface {
 	// List all jobs.
 	List(ctx context.Context, accountID account.ID) ([]*Job, error)
 
+	// ListByOrganization lists all jobs owned by a particular Organization.
+	ListByOrganization(ctx context.Context, orgID account.OrganizationID) ([]*Job, error)
+
+	// ListByProject lists all jobs for a particular Project.
+	ListByProject(ctx context.Context, projectID infrastructure.ProjectID) ([]*Job, error)
+
 	// Create a new Job.
 	Create(ctx context.Context, job *Job) error
 
@@ -228,6 +374,42 @@ func (repo *InMemoryJobRepository) List(ctx context.Context, accountID account.I
 	return jobs, nil
 }
 
+// ListByOrganization lists all jobs owned by a particular Organization.
+func (repo *InMemoryJobRepository) ListByOrganization(ctx context.Context, orgID account.OrganizationID) ([]*Job, error) {
+	var jobs []*Job
+
+	repo.jobs.Range(func(key, v interface{}) bool {
+		job := v.(*Job)
+		if job.OrganizationID != orgID {
+			return true
+		}
+
+		jobs = append(jobs, job)
+
+		return true
+	})
+
+	return jobs, nil
+}
+
+// ListByProject lists all jobs for a particular Project.
+func (repo *InMemoryJobRepository) ListByProject(ctx context.Context, projectID infrastructure.ProjectID) ([]*Job, error) {
+	var jobs []*Job
+
+	repo.jobs.Range(func(key, v interface{}) bool {
+		job := v.(*Job)
+		if job.ProjectID != projectID {
+			return true
+		}
+
+		jobs = append(jobs, job)
+
+		return true
+	})
+
+	return jobs, nil
+}
+
 // Create a new Job.
 func (repo *InMemoryJobRepository) Create(ctx context.Context, job *Job) error {
 	_, loaded := repo.jobs.LoadOrStore(job.ID, job)
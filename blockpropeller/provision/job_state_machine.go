@@ -4,6 +4,7 @@ import (
 	"context"
 	"time"
 
+	"blockpropeller.dev/blockpropeller/infrastructure"
 	"blockpropeller.dev/blockpropeller/statemachine"
 	"blockpropeller.dev/blockpropeller/statemachine/middleware"
 	"blockpropeller.dev/lib/log"
@@ -17,6 +18,21 @@ var (
 	// StateServerCreated is the state after terraform successfully creates the requested server.
 	StateServerCreated = statemachine.NewState("server_created")
 
+	// StateVolumeResizeRequested is the starting point for a job resizing the data volume of a server.
+	StateVolumeResizeRequested = statemachine.NewState("volume_resize_requested")
+
+	// StateVolumeResized is the state after terraform successfully resizes the data volume of a server.
+	StateVolumeResized = statemachine.NewState("volume_resized")
+
+	// StateFirewallUpdateRequested is the starting point for a job replacing the firewall allow-list of a server.
+	StateFirewallUpdateRequested = statemachine.NewState("firewall_update_requested")
+
+	// StateServerLabelsRequested is the starting point for a job replacing the labels of a server.
+	StateServerLabelsRequested = statemachine.NewState("server_labels_requested")
+
+	// StateDeploymentLabelsRequested is the starting point for a job replacing the labels of a deployment.
+	StateDeploymentLabelsRequested = statemachine.NewState("deployment_labels_requested")
+
 	// StateCompleted is the terminating state representing a successful provisioning job.
 	StateCompleted = statemachine.NewState("completed").Successful()
 
@@ -25,7 +41,17 @@ var (
 	StateFailed = statemachine.NewState("failed").Failure()
 
 	// ValidStates of a provision.Job.
-	ValidStates = []statemachine.State{StateCreated, StateServerCreated, StateCompleted, StateFailed}
+	ValidStates = []statemachine.State{
+		StateCreated,
+		StateServerCreated,
+		StateVolumeResizeRequested,
+		StateVolumeResized,
+		StateFirewallUpdateRequested,
+		StateServerLabelsRequested,
+		StateDeploymentLabelsRequested,
+		StateCompleted,
+		StateFailed,
+	}
 )
 
 // JobStateMachine defines the state machine for running provisioning jobs.
@@ -38,6 +64,10 @@ type JobStateMachine struct {
 func ConfigureJobStateMachine(
 	tfStep *StepProvisionServer,
 	ansibleStep *StepProvisionDeployment,
+	resizeStep *StepResizeVolume,
+	growStep *StepGrowVolume,
+	firewallStep *StepUpdateFirewall,
+	labelsStep *StepUpdateLabels,
 	failureMiddleware *FailureMiddleware,
 	txMiddleware *middleware.Transactional,
 ) *JobStateMachine {
@@ -47,6 +77,11 @@ func ConfigureJobStateMachine(
 			Middleware(txMiddleware).
 			Step(StateCreated, tfStep).
 			Step(StateServerCreated, ansibleStep).
+			Step(StateVolumeResizeRequested, resizeStep).
+			Step(StateVolumeResized, growStep).
+			Step(StateFirewallUpdateRequested, firewallStep).
+			Step(StateServerLabelsRequested, labelsStep).
+			Step(StateDeploymentLabelsRequested, labelsStep).
 			Build(),
 	}
 }
@@ -166,3 +201,160 @@ func (step *StepProvisionDeployment) Step(ctx context.Context, res statemachine.
 
 	return nil
 }
+
+// StepResizeVolume updates the data volume of a server to the size
+// requested by the job and applies it through Terraform.
+type StepResizeVolume struct {
+	serverProvisioner *ServerProvisioner
+
+	jobRepo JobRepository
+}
+
+// NewStepResizeVolume returns a new StepResizeVolume instance.
+func NewStepResizeVolume(serverProvisioner *ServerProvisioner, jobRepo JobRepository) *StepResizeVolume {
+	return &StepResizeVolume{serverProvisioner: serverProvisioner, jobRepo: jobRepo}
+}
+
+// Step satisfies the Step interface.
+func (step *StepResizeVolume) Step(ctx context.Context, res statemachine.StatefulResource) error {
+	job := res.(*Job)
+
+	err := step.serverProvisioner.ResizeVolume(ctx, job.Server, job.VolumeSize)
+	if err != nil {
+		return errors.Wrap(err, "resize server volume")
+	}
+
+	job.SetState(StateVolumeResized)
+
+	err = step.jobRepo.Update(ctx, job)
+	if err != nil {
+		return errors.Wrap(err, "update job")
+	}
+
+	return nil
+}
+
+// StepGrowVolume connects to a server with a resized data volume
+// and runs an Ansible playbook growing the filesystem on it.
+type StepGrowVolume struct {
+	deploymentProvisioner *DeploymentProvisioner
+
+	jobRepo JobRepository
+}
+
+// NewStepGrowVolume returns a new StepGrowVolume instance.
+func NewStepGrowVolume(deploymentProvisioner *DeploymentProvisioner, jobRepo JobRepository) *StepGrowVolume {
+	return &StepGrowVolume{deploymentProvisioner: deploymentProvisioner, jobRepo: jobRepo}
+}
+
+// Step satisfies the Step interface.
+func (step *StepGrowVolume) Step(ctx context.Context, res statemachine.StatefulResource) error {
+	job := res.(*Job)
+
+	err := step.deploymentProvisioner.GrowVolume(ctx, job.Server)
+	if err != nil {
+		return errors.Wrap(err, "grow volume filesystem")
+	}
+
+	job.SetState(StateCompleted)
+
+	finishedAt := time.Now()
+	job.FinishedAt = &finishedAt
+
+	err = step.jobRepo.Update(ctx, job)
+	if err != nil {
+		return errors.Wrap(err, "update job")
+	}
+
+	return nil
+}
+
+// StepUpdateFirewall replaces the firewall allow-list of a server with the one
+// requested by the job and applies it through Terraform.
+type StepUpdateFirewall struct {
+	serverProvisioner *ServerProvisioner
+
+	jobRepo JobRepository
+}
+
+// NewStepUpdateFirewall returns a new StepUpdateFirewall instance.
+func NewStepUpdateFirewall(serverProvisioner *ServerProvisioner, jobRepo JobRepository) *StepUpdateFirewall {
+	return &StepUpdateFirewall{serverProvisioner: serverProvisioner, jobRepo: jobRepo}
+}
+
+// Step satisfies the Step interface.
+func (step *StepUpdateFirewall) Step(ctx context.Context, res statemachine.StatefulResource) error {
+	job := res.(*Job)
+
+	err := step.serverProvisioner.UpdateFirewall(ctx, job.Server, job.AllowedIPs)
+	if err != nil {
+		return errors.Wrap(err, "update server firewall")
+	}
+
+	job.SetState(StateCompleted)
+
+	finishedAt := time.Now()
+	job.FinishedAt = &finishedAt
+
+	err = step.jobRepo.Update(ctx, job)
+	if err != nil {
+		return errors.Wrap(err, "update job")
+	}
+
+	return nil
+}
+
+// StepUpdateLabels replaces the labels of a server, or of the deployment referenced
+// by the job, and applies them as tags through Terraform.
+type StepUpdateLabels struct {
+	serverProvisioner *ServerProvisioner
+
+	jobRepo        JobRepository
+	deploymentRepo infrastructure.DeploymentRepository
+}
+
+// NewStepUpdateLabels returns a new StepUpdateLabels instance.
+func NewStepUpdateLabels(
+	serverProvisioner *ServerProvisioner,
+	jobRepo JobRepository,
+	deploymentRepo infrastructure.DeploymentRepository,
+) *StepUpdateLabels {
+	return &StepUpdateLabels{serverProvisioner: serverProvisioner, jobRepo: jobRepo, deploymentRepo: deploymentRepo}
+}
+
+// Step satisfies the Step interface.
+func (step *StepUpdateLabels) Step(ctx context.Context, res statemachine.StatefulResource) error {
+	job := res.(*Job)
+
+	if job.GetState() == StateDeploymentLabelsRequested {
+		deployment, err := step.serverProvisioner.UpdateDeploymentLabels(ctx, job.Server, job.DeploymentID, job.Labels)
+		if err != nil {
+			return errors.Wrap(err, "update deployment labels")
+		}
+
+		err = step.deploymentRepo.Update(ctx, deployment)
+		if err != nil {
+			return errors.Wrap(err, "update deployment")
+		}
+
+		// The Job Deployment is saved along with the Job, so it has to hold the new labels as well.
+		job.Deployment = deployment
+	} else {
+		err := step.serverProvisioner.UpdateServerLabels(ctx, job.Server, job.Labels)
+		if err != nil {
+			return errors.Wrap(err, "update server labels")
+		}
+	}
+
+	job.SetState(StateCompleted)
+
+	finishedAt := time.Now()
+	job.FinishedAt = &finishedAt
+
+	err := step.jobRepo.Update(ctx, job)
+	if err != nil {
+		return errors.Wrap(err, "update job")
+	}
+
+	return nil
+}
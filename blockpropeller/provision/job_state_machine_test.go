@@ -0,0 +1,178 @@
+package provision_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"blockpropeller.dev/blockpropeller/infrastructure"
+	"blockpropeller.dev/blockpropeller/provision"
+	"blockpropeller.dev/lib/test"
+)
+
+func TestVolumeResizeJob(t *testing.T) {
+	f := newFixture(t)
+	defer test.Close(t, f)
+
+	srv := f.provisionedServer(t)
+	srv.VolumeSize = 500
+	f.newDeployment(t, srv)
+
+	settings, err := f.settingsRepo.Find(context.Background(), srv.ProviderSettingsID)
+	test.CheckErr(t, "find provider settings", err)
+
+	job, err := f.jobScheduler.ScheduleVolumeResize(context.Background(), settings, srv, 750)
+	test.CheckErr(t, "ScheduleVolumeResize()", err)
+	test.AssertStringsEqual(t, "Job.State", job.GetState().Name, provision.StateVolumeResizeRequested.Name)
+
+	_, err = f.jobScheduler.ScheduleVolumeResize(context.Background(), settings, srv, 1000)
+	assertErrCause(t, "ScheduleVolumeResize() in progress", err, provision.ErrVolumeResizeInProgress)
+
+	apply := f.terraform.On("apply").Capture("main.tf")
+
+	err = provision.NewStepResizeVolume(f.serverProvisioner, f.jobRepo).Step(context.Background(), job)
+	test.CheckErr(t, "resize volume step", err)
+
+	test.AssertStringsEqual(t, "Job.State", job.GetState().Name, provision.StateVolumeResized.Name)
+	test.AssertBoolEqual(t, "resized volume", strings.Contains(apply.Captured(), "size=750"), true)
+	test.AssertIntsEqual(t, "Server.VolumeSize", srv.VolumeSize, 750)
+
+	err = provision.NewStepGrowVolume(f.deploymentProvisioner, f.jobRepo).Step(context.Background(), job)
+	test.CheckErr(t, "grow volume step", err)
+
+	test.AssertStringsEqual(t, "Job.State", job.GetState().Name, provision.StateCompleted.Name)
+	test.AssertBoolEqual(t, "job finished", job.FinishedAt != nil, true)
+
+	calls := f.ansible.Calls()
+	test.AssertIntsEqual(t, "ansible-playbook calls", len(calls), 1)
+	test.AssertBoolEqual(t, "grow volume playbook",
+		strings.Contains(strings.Join(calls[0], " "), "grow_volume.yaml"), true)
+}
+
+func TestVolumeResizeJobWithoutDeployments(t *testing.T) {
+	srv := infrastructure.NewServer("", "test", infrastructure.ProviderDigitalOcean, infrastructure.ServerSizeProd, nil)
+	srv.VolumeSize = 500
+	settings := infrastructure.NewProviderSettings("", "", infrastructure.ProviderDigitalOcean, "token")
+
+	_, err := provision.NewVolumeResizeJob("", settings, srv, 750)
+	test.CheckErrExists(t, "NewVolumeResizeJob()", err)
+}
+
+func TestFirewallUpdateJob(t *testing.T) {
+	f := newFixture(t)
+	defer test.Close(t, f)
+
+	srv := f.provisionedServer(t)
+	srv.VolumeSize = 500
+	f.newDeployment(t, srv)
+
+	settings, err := f.settingsRepo.Find(context.Background(), srv.ProviderSettingsID)
+	test.CheckErr(t, "find provider settings", err)
+
+	_, err = f.jobScheduler.ScheduleFirewallUpdate(context.Background(), settings, srv, []string{"not-an-ip"})
+	test.CheckErrExists(t, "ScheduleFirewallUpdate() invalid ip", err)
+
+	job, err := f.jobScheduler.ScheduleFirewallUpdate(context.Background(), settings, srv, []string{"203.0.113.7"})
+	test.CheckErr(t, "ScheduleFirewallUpdate()", err)
+	test.AssertStringsEqual(t, "Job.State", job.GetState().Name, provision.StateFirewallUpdateRequested.Name)
+	test.AssertStringsEqual(t, "Job.AllowedIPs", strings.Join(job.AllowedIPs, ","), "203.0.113.7/32")
+	test.AssertIntsEqual(t, "Server.AllowedIPs before the job runs", len(srv.AllowedIPs), 0)
+
+	_, err = f.jobScheduler.ScheduleVolumeResize(context.Background(), settings, srv, 750)
+	assertErrCause(t, "ScheduleVolumeResize() with update in progress", err, provision.ErrServerUpdateInProgress)
+
+	apply := f.terraform.On("apply").Capture("main.tf")
+
+	err = provision.NewStepUpdateFirewall(f.serverProvisioner, f.jobRepo).Step(context.Background(), job)
+	test.CheckErr(t, "update firewall step", err)
+
+	test.AssertStringsEqual(t, "Job.State", job.GetState().Name, provision.StateCompleted.Name)
+	test.AssertBoolEqual(t, "job finished", job.FinishedAt != nil, true)
+	test.AssertBoolEqual(t, "allowed ip rule", strings.Contains(apply.Captured(), `"203.0.113.7/32"`), true)
+	test.AssertStringsEqual(t, "Server.AllowedIPs", strings.Join(srv.AllowedIPs, ","), "203.0.113.7/32")
+}
+
+func TestFirewallUpdateJobFailure(t *testing.T) {
+	f := newFixture(t)
+	defer test.Close(t, f)
+
+	srv := f.provisionedServer(t)
+	f.newDeployment(t, srv)
+
+	settings, err := f.settingsRepo.Find(context.Background(), srv.ProviderSettingsID)
+	test.CheckErr(t, "find provider settings", err)
+
+	job, err := provision.NewFirewallUpdateJob("", settings, srv, []string{"203.0.113.7"})
+	test.CheckErr(t, "NewFirewallUpdateJob()", err)
+
+	f.terraform.On("apply").Exit(1)
+
+	err = provision.NewStepUpdateFirewall(f.serverProvisioner, f.jobRepo).Step(context.Background(), job)
+	test.CheckErrExists(t, "update firewall step", err)
+	test.AssertIntsEqual(t, "Server.AllowedIPs", len(srv.AllowedIPs), 0)
+}
+
+func TestLabelsUpdateJobs(t *testing.T) {
+	f := newFixture(t)
+	defer test.Close(t, f)
+
+	srv := f.provisionedServer(t)
+	deployment := f.newDeployment(t, srv)
+
+	settings, err := f.settingsRepo.Find(context.Background(), srv.ProviderSettingsID)
+	test.CheckErr(t, "find provider settings", err)
+
+	_, err = f.jobScheduler.ScheduleServerLabelsUpdate(context.Background(), settings, srv, infrastructure.Labels{"Env": "prod"})
+	test.CheckErrExists(t, "ScheduleServerLabelsUpdate() invalid labels", err)
+
+	job, err := f.jobScheduler.ScheduleDeploymentLabelsUpdate(
+		context.Background(), settings, srv, deployment, infrastructure.Labels{"team": "nodes"})
+	test.CheckErr(t, "ScheduleDeploymentLabelsUpdate()", err)
+	test.AssertStringsEqual(t, "Job.State", job.GetState().Name, provision.StateDeploymentLabelsRequested.Name)
+	test.AssertStringsEqual(t, "Deployment.Labels before the job runs", deployment.Labels.String(), "")
+
+	_, err = f.jobScheduler.ScheduleServerLabelsUpdate(context.Background(), settings, srv, infrastructure.Labels{"env": "prod"})
+	assertErrCause(t, "ScheduleServerLabelsUpdate() with update in progress", err, provision.ErrServerUpdateInProgress)
+
+	step := provision.NewStepUpdateLabels(f.serverProvisioner, f.jobRepo, f.deploymentRepo)
+	apply := f.terraform.On("apply").Capture("main.tf")
+
+	err = step.Step(context.Background(), job)
+	test.CheckErr(t, "update deployment labels step", err)
+
+	test.AssertStringsEqual(t, "Job.State", job.GetState().Name, provision.StateCompleted.Name)
+	test.AssertBoolEqual(t, "droplet tags", strings.Contains(apply.Captured(), `tags=["team:nodes"]`), true)
+	test.AssertStringsEqual(t, "Deployment.Labels", deployment.Labels.String(), "team=nodes")
+	test.AssertStringsEqual(t, "Job.Deployment.Labels", job.Deployment.Labels.String(), "team=nodes")
+
+	job, err = f.jobScheduler.ScheduleServerLabelsUpdate(context.Background(), settings, srv, infrastructure.Labels{"env": "prod"})
+	test.CheckErr(t, "ScheduleServerLabelsUpdate()", err)
+	test.AssertStringsEqual(t, "Job.State", job.GetState().Name, provision.StateServerLabelsRequested.Name)
+
+	err = step.Step(context.Background(), job)
+	test.CheckErr(t, "update server labels step", err)
+
+	test.AssertStringsEqual(t, "Job.State", job.GetState().Name, provision.StateCompleted.Name)
+	test.AssertBoolEqual(t, "droplet tags", strings.Contains(apply.Captured(), `tags=["env:prod", "team:nodes"]`), true)
+	test.AssertStringsEqual(t, "Server.Labels", srv.Labels.String(), "env=prod")
+}
+
+func TestLabelsUpdateJobFailure(t *testing.T) {
+	f := newFixture(t)
+	defer test.Close(t, f)
+
+	srv := f.provisionedServer(t)
+	deployment := f.newDeployment(t, srv)
+
+	settings, err := f.settingsRepo.Find(context.Background(), srv.ProviderSettingsID)
+	test.CheckErr(t, "find provider settings", err)
+
+	job, err := provision.NewDeploymentLabelsJob("", settings, srv, deployment, infrastructure.Labels{"team": "nodes"})
+	test.CheckErr(t, "NewDeploymentLabelsJob()", err)
+
+	f.terraform.On("apply").Exit(1)
+
+	err = provision.NewStepUpdateLabels(f.serverProvisioner, f.jobRepo, f.deploymentRepo).Step(context.Background(), job)
+	test.CheckErrExists(t, "update deployment labels step", err)
+	test.AssertStringsEqual(t, "Deployment.Labels", deployment.Labels.String(), "")
+}
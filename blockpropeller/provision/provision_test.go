@@ -0,0 +1,162 @@
+package provision_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"blockpropeller.dev/blockpropeller/ansible"
+	"blockpropeller.dev/blockpropeller/binance"
+	"blockpropeller.dev/blockpropeller/database/transaction"
+	"blockpropeller.dev/blockpropeller/encryption"
+	"blockpropeller.dev/blockpropeller/infrastructure"
+	"blockpropeller.dev/blockpropeller/provision"
+	"blockpropeller.dev/blockpropeller/terraform"
+	"blockpropeller.dev/lib/log"
+	"blockpropeller.dev/lib/test"
+	"blockpropeller.dev/lib/test/fakebin"
+	"github.com/blang/semver"
+	"github.com/pkg/errors"
+
+	_ "blockpropeller.dev/blockpropeller/terraform/cloudprovider/digitalocean"
+)
+
+const (
+	testTimeout      = 500 * time.Millisecond
+	testManagementIP = "198.51.100.7"
+)
+
+var (
+	sshKeyOnce sync.Once
+	sshKey     *infrastructure.SSHKey
+)
+
+// fixture holds the dependencies of provisioners backed by fake binaries and in-memory repositories.
+type fixture struct {
+	terraform *fakebin.Binary
+	ansible   *fakebin.Binary
+	keysDir   string
+
+	srvRepo        *infrastructure.InMemoryServerRepository
+	deploymentRepo *infrastructure.InMemoryDeploymentRepository
+	settingsRepo   *infrastructure.InMemoryProviderSettingsRepository
+	ipRepo         *infrastructure.InMemoryReservedIPRepository
+	snapRepo       *infrastructure.InMemoryVolumeSnapshotRepository
+	jobRepo        *provision.InMemoryJobRepository
+
+	serverProvisioner     *provision.ServerProvisioner
+	ipProvisioner         *provision.ReservedIPProvisioner
+	deploymentProvisioner *provision.DeploymentProvisioner
+	serverDestroyer       *provision.ServerDestroyer
+	jobScheduler          *provision.JobScheduler
+	healthMonitor         *provision.HealthMonitor
+	snapProvisioner       *provision.VolumeSnapshotProvisioner
+	snapshotScheduler     *provision.SnapshotScheduler
+}
+
+func newFixture(t *testing.T) *fixture {
+	log.SetGlobal(log.NewTestingLogger(t))
+	encryption.Init("test")
+
+	f := &fixture{
+		terraform: fakebin.New(t, "terraform"),
+		ansible:   fakebin.New(t, "ansible-playbook"),
+
+		srvRepo:        infrastructure.NewInMemoryServerRepository(),
+		deploymentRepo: infrastructure.NewInMemoryDeploymentRepository(),
+		settingsRepo:   infrastructure.NewInMemoryProviderSettingsRepository(),
+		ipRepo:         infrastructure.NewInMemoryReservedIPRepository(),
+		snapRepo:       infrastructure.NewInMemoryVolumeSnapshotRepository(),
+		jobRepo:        provision.NewInMemoryJobRepository(),
+	}
+
+	var err error
+	f.keysDir, err = ioutil.TempDir(os.TempDir(), "ansible-keys-")
+	test.CheckErr(t, "create keys dir", err)
+
+	tf := terraform.New(f.terraform.Path()).WithTimeout(testTimeout)
+	ans := ansible.New(f.ansible.Path(), "", f.keysDir).WithTimeout(testTimeout)
+
+	firewall := &provision.FirewallConfig{ManagementIPs: []string{testManagementIP}}
+
+	f.serverProvisioner = provision.NewServerProvisioner(firewall, tf, nil, f.srvRepo, f.settingsRepo)
+	f.ipProvisioner = provision.NewReservedIPProvisioner(tf, f.srvRepo, f.ipRepo, f.settingsRepo)
+	f.deploymentProvisioner = provision.NewDeploymentProvisioner(ans, f.deploymentRepo).WithRetry(3, 0)
+	f.serverDestroyer = provision.NewServerDestroyer(
+		tf, f.deploymentProvisioner, transaction.NewInMemoryTransactionContext(), f.srvRepo, f.deploymentRepo, f.settingsRepo)
+	f.jobScheduler = provision.NewJobScheduler(
+		transaction.NewInMemoryTransactionContext(), f.jobRepo, f.srvRepo, f.deploymentRepo)
+	f.healthMonitor = provision.NewHealthMonitor(
+		&provision.HealthCheckConfig{Interval: time.Minute}, f.jobScheduler, f.jobRepo, f.srvRepo, f.settingsRepo)
+	f.snapProvisioner = provision.NewVolumeSnapshotProvisioner(tf, f.srvRepo, f.snapRepo, f.settingsRepo)
+	f.snapshotScheduler = provision.NewSnapshotScheduler(
+		&provision.SnapshotConfig{Interval: time.Minute}, f.snapProvisioner, f.srvRepo, f.snapRepo)
+
+	return f
+}
+
+func (f *fixture) Close() error {
+	err := f.terraform.Close()
+	if err != nil {
+		return errors.Wrap(err, "close terraform")
+	}
+
+	err = f.ansible.Close()
+	if err != nil {
+		return errors.Wrap(err, "close ansible")
+	}
+
+	return os.RemoveAll(f.keysDir)
+}
+
+// newServer creates a Server in the requested state, reusing a single SSHKey
+// across tests since generating one is slow.
+func (f *fixture) newServer(t *testing.T, provider infrastructure.ProviderType) *infrastructure.Server {
+	sshKeyOnce.Do(func() {
+		var err error
+		sshKey, err = infrastructure.GenerateNewSSHKey("test")
+		test.CheckErr(t, "generate ssh key", err)
+	})
+
+	srv, err := infrastructure.NewServerBuilder("").
+		Provider(provider).
+		SSHKey(sshKey).
+		Build()
+	test.CheckErr(t, "build server", err)
+
+	err = f.srvRepo.Create(context.Background(), srv)
+	test.CheckErr(t, "create server", err)
+
+	return srv
+}
+
+// newDeployment adds a Binance light node Deployment to the Server.
+func (f *fixture) newDeployment(t *testing.T, srv *infrastructure.Server) *infrastructure.Deployment {
+	deployment := binance.NewNodeDeployment(binance.NetworkTest, binance.TypeLightNode, semver.MustParse("0.6.1"))
+	srv.AddDeployment(deployment)
+
+	err := f.deploymentRepo.Create(context.Background(), deployment)
+	test.CheckErr(t, "create deployment", err)
+
+	return deployment
+}
+
+func assertCommands(t *testing.T, name string, bin *fakebin.Binary, want ...string) {
+	got := bin.Commands()
+	if len(got) != len(want) {
+		t.Fatalf("%s: got commands %v, want %v", name, got, want)
+	}
+	for i := range got {
+		test.AssertStringsEqual(t, name, got[i], want[i])
+	}
+}
+
+func assertErrCause(t *testing.T, name string, err error, want error) {
+	test.CheckErrExists(t, name, err)
+	if errors.Cause(err) != want {
+		t.Fatalf("%s: got error %v, want cause %v", name, err, want)
+	}
+}
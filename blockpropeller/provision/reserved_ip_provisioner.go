@@ -0,0 +1,178 @@
+package provision
+
+import (
+	"context"
+	"net"
+
+	"blockpropeller.dev/blockpropeller/infrastructure"
+	"blockpropeller.dev/blockpropeller/terraform"
+	"blockpropeller.dev/blockpropeller/terraform/cloudprovider"
+	"blockpropeller.dev/lib/log"
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrReservedIPInUse is returned when releasing a reserved IP that is attached to a Server.
+	ErrReservedIPInUse = errors.New("reserved ip in use")
+)
+
+// ReservedIPProvisioner is responsible for allocating and releasing reserved IPs via Terraform.
+//
+// Each reserved IP is managed inside its own Terraform workspace,
+// so it is retained when the Servers it is attached to are destroyed.
+type ReservedIPProvisioner struct {
+	tf *terraform.Terraform
+
+	srvRepo      infrastructure.ServerRepository
+	ipRepo       infrastructure.ReservedIPRepository
+	settingsRepo infrastructure.ProviderSettingsRepository
+}
+
+// NewReservedIPProvisioner returns a new ReservedIPProvisioner instance.
+func NewReservedIPProvisioner(
+	tf *terraform.Terraform,
+	srvRepo infrastructure.ServerRepository,
+	ipRepo infrastructure.ReservedIPRepository,
+	settingsRepo infrastructure.ProviderSettingsRepository,
+) *ReservedIPProvisioner {
+	return &ReservedIPProvisioner{
+		tf:           tf,
+		srvRepo:      srvRepo,
+		ipRepo:       ipRepo,
+		settingsRepo: settingsRepo,
+	}
+}
+
+// Provision allocates the reserved IP with the provider and stores it once allocated.
+func (rp *ReservedIPProvisioner) Provision(ctx context.Context, provider *infrastructure.ProviderSettings, ip *infrastructure.ReservedIP) error {
+	if ip.IsAllocated() {
+		return errors.New("reserved ip already allocated")
+	}
+
+	cloudProvider, err := cloudprovider.GetProvider(provider.Type)
+	if err != nil {
+		return errors.Wrap(err, "get cloud provider")
+	}
+
+	workspace, err := terraform.NewWorkspace()
+	if err != nil {
+		return errors.Wrap(err, "create new workspace")
+	}
+	defer func() {
+		log.Debug("cleaning up Terraform workspace")
+		log.Closer(workspace)
+	}()
+
+	err = cloudProvider.Register(workspace, provider)
+	if err != nil {
+		return errors.Wrap(err, "register cloud provider in workspace")
+	}
+
+	err = cloudProvider.AddReservedIP(workspace, ip)
+	if err != nil {
+		return errors.Wrap(err, "add reserved ip to workspace")
+	}
+
+	err = workspace.Flush()
+	if err != nil {
+		return errors.Wrap(err, "flush workspace")
+	}
+
+	err = applyWorkspace(rp.tf, workspace)
+	if err != nil {
+		return err
+	}
+
+	rawIP, err := rp.tf.Output(workspace, "ip-address")
+	if err != nil {
+		return errors.Wrap(err, "get allocated ip address")
+	}
+
+	address := net.ParseIP(rawIP)
+	if address == nil {
+		return errors.Errorf("invalid reserved IP: %s", rawIP)
+	}
+
+	ip.ProviderID, err = rp.tf.Output(workspace, "reserved-ip-id")
+	if err != nil {
+		return errors.Wrap(err, "get reserved ip id")
+	}
+
+	ip.Address = address.String()
+
+	ip.WorkspaceSnapshot, err = workspace.Snapshot()
+	if err != nil {
+		return errors.Wrap(err, "take workspace snapshot")
+	}
+
+	err = rp.ipRepo.Create(ctx, ip)
+	if err != nil {
+		return errors.Wrap(err, "create reserved ip")
+	}
+
+	return nil
+}
+
+// AttachedServer returns the Server the reserved IP is attached to, or nil if it is not attached.
+func (rp *ReservedIPProvisioner) AttachedServer(ctx context.Context, ip *infrastructure.ReservedIP) (*infrastructure.Server, error) {
+	servers, err := rp.srvRepo.ListByOrganization(ctx, ip.OrganizationID)
+	if err != nil {
+		return nil, errors.Wrap(err, "list servers")
+	}
+
+	for _, srv := range servers {
+		if srv.ReservedIPID == ip.ID && srv.State != infrastructure.ServerStateDeleted {
+			return srv, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// Release destroys the reserved IP, returning the address to the provider.
+//
+// Reserved IPs attached to a Server cannot be released.
+func (rp *ReservedIPProvisioner) Release(ctx context.Context, ip *infrastructure.ReservedIP) error {
+	srv, err := rp.AttachedServer(ctx, ip)
+	if err != nil {
+		return err
+	}
+	if srv != nil {
+		return errors.Wrapf(ErrReservedIPInUse, "attached to server %s", srv.ID)
+	}
+
+	if ip.WorkspaceSnapshot == nil {
+		return errors.New("missing workspace snapshot")
+	}
+
+	provider, err := rp.settingsRepo.Find(ctx, ip.ProviderSettingsID)
+	if err != nil {
+		return errors.Wrap(err, "find provider settings")
+	}
+
+	workspace, err := restoreWorkspace(provider, ip.WorkspaceSnapshot)
+	if err != nil {
+		return errors.Wrap(err, "restore workspace")
+	}
+	defer func() {
+		log.Debug("cleaning up Terraform workspace")
+		log.Closer(workspace)
+	}()
+
+	err = rp.tf.Init(workspace)
+	if err != nil {
+		return errors.Wrap(err, "init workspace")
+	}
+
+	err = rp.tf.Destroy(workspace)
+	if err != nil {
+		return errors.Wrap(err, "destroy workspace")
+	}
+
+	err = rp.ipRepo.Delete(ctx, ip)
+	if err != nil {
+		return errors.Wrap(err, "delete reserved ip")
+	}
+
+	return nil
+}
@@ -0,0 +1,120 @@
+package provision_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"blockpropeller.dev/blockpropeller/infrastructure"
+	"blockpropeller.dev/blockpropeller/provision"
+	"blockpropeller.dev/lib/test"
+)
+
+func TestReservedIPProvisioner_Provision(t *testing.T) {
+	f := newFixture(t)
+	defer test.Close(t, f)
+
+	f.terraform.On("output").Stdout("203.0.113.20\n")
+
+	settings := infrastructure.NewProviderSettings("", "", infrastructure.ProviderDigitalOcean, "token")
+	ip := infrastructure.NewReservedIP("", settings, "fra1")
+
+	err := f.ipProvisioner.Provision(context.Background(), settings, ip)
+	test.CheckErr(t, "Provision()", err)
+
+	assertCommands(t, "terraform commands", f.terraform, "init", "plan", "apply", "output", "output")
+
+	stored, err := f.ipRepo.Find(context.Background(), ip.ID)
+	test.CheckErr(t, "find reserved ip", err)
+
+	test.AssertStringsEqual(t, "ReservedIP.Address", stored.Address, "203.0.113.20")
+	test.AssertStringsEqual(t, "ReservedIP.ProviderID", stored.ProviderID, "203.0.113.20")
+	test.AssertBoolEqual(t, "has workspace snapshot", stored.WorkspaceSnapshot != nil, true)
+}
+
+func TestReservedIPProvisioner_ProvisionFailure(t *testing.T) {
+	f := newFixture(t)
+	defer test.Close(t, f)
+
+	f.terraform.On("apply").Stderr("Error: floating ip limit exceeded").Exit(1)
+
+	settings := infrastructure.NewProviderSettings("", "", infrastructure.ProviderDigitalOcean, "token")
+	ip := infrastructure.NewReservedIP("", settings, "fra1")
+
+	err := f.ipProvisioner.Provision(context.Background(), settings, ip)
+	test.CheckErrExists(t, "Provision()", err)
+
+	_, err = f.ipRepo.Find(context.Background(), ip.ID)
+	assertErrCause(t, "find reserved ip", err, infrastructure.ErrReservedIPNotFound)
+}
+
+func TestReservedIPProvisioner_AttachToServer(t *testing.T) {
+	f := newFixture(t)
+	defer test.Close(t, f)
+
+	ip := f.reservedIP(t)
+
+	srv := f.newServer(t, infrastructure.ProviderDigitalOcean)
+	srv.ProviderSettingsID = ip.ProviderSettingsID
+	srv.AttachReservedIP(ip)
+
+	settings, err := f.settingsRepo.Find(context.Background(), ip.ProviderSettingsID)
+	test.CheckErr(t, "find provider settings", err)
+
+	apply := f.terraform.On("apply").Capture("main.tf")
+	f.terraform.On("output").Stdout("203.0.113.20\n")
+
+	err = f.serverProvisioner.Provision(context.Background(), settings, srv)
+	test.CheckErr(t, "provision server", err)
+
+	want := `resource "digitalocean_floating_ip_assignment" "` + srv.Name + `" {
+  ip_address="203.0.113.20"
+`
+	test.AssertBoolEqual(t, "assigns reserved ip", strings.Contains(apply.Captured(), want), true)
+
+	attached, err := f.ipProvisioner.AttachedServer(context.Background(), ip)
+	test.CheckErr(t, "AttachedServer()", err)
+	test.AssertStringsEqual(t, "attached server", string(attached.ID), string(srv.ID))
+
+	err = f.ipProvisioner.Release(context.Background(), ip)
+	assertErrCause(t, "Release()", err, provision.ErrReservedIPInUse)
+
+	err = f.serverDestroyer.Destroy(context.Background(), srv)
+	test.CheckErr(t, "destroy server", err)
+
+	_, err = f.ipRepo.Find(context.Background(), ip.ID)
+	test.CheckErr(t, "reserved ip must outlive the server", err)
+}
+
+func TestReservedIPProvisioner_Release(t *testing.T) {
+	f := newFixture(t)
+	defer test.Close(t, f)
+
+	ip := f.reservedIP(t)
+
+	err := f.ipProvisioner.Release(context.Background(), ip)
+	test.CheckErr(t, "Release()", err)
+
+	assertCommands(t, "terraform commands", f.terraform, "init", "destroy")
+
+	_, err = f.ipRepo.Find(context.Background(), ip.ID)
+	assertErrCause(t, "find released reserved ip", err, infrastructure.ErrReservedIPNotFound)
+}
+
+// reservedIP allocates a new reserved IP with stored provider settings.
+func (f *fixture) reservedIP(t *testing.T) *infrastructure.ReservedIP {
+	f.terraform.On("output").Stdout("203.0.113.20\n")
+
+	settings := infrastructure.NewProviderSettings("", "", infrastructure.ProviderDigitalOcean, "token")
+	err := f.settingsRepo.Create(context.Background(), settings)
+	test.CheckErr(t, "create provider settings", err)
+
+	ip := infrastructure.NewReservedIP("", settings, "fra1")
+	err = f.ipProvisioner.Provision(context.Background(), settings, ip)
+	test.CheckErr(t, "provision reserved ip", err)
+
+	// Only record calls made after provisioning.
+	f.terraform.Reset()
+
+	return ip
+}
@@ -8,6 +8,13 @@ import (
 	"github.com/pkg/errors"
 )
 
+var (
+	// ErrVolumeResizeInProgress is returned when scheduling a volume resize for a Server that is already being resized.
+	ErrVolumeResizeInProgress = errors.New("volume resize already in progress")
+	// ErrServerUpdateInProgress is returned when scheduling an update of a Server with another Job in progress.
+	ErrServerUpdateInProgress = errors.New("server update already in progress")
+)
+
 // JobScheduler is responsible for taking a Job request, persisting it
 // and queuing the job in order to be executed by the provisioner.
 type JobScheduler struct {
@@ -38,14 +45,171 @@ func NewJobScheduler(
 // The job will be picked up by the Provider by polling the JobRepository.
 func (js *JobScheduler) Schedule(ctx context.Context, job *Job) error {
 	err := js.txContext.RunInTransaction(ctx, func(ctx context.Context) error {
-		err := js.serverRepo.Create(ctx, job.Server)
+		return js.createJob(ctx, job)
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed scheduling job")
+	}
+
+	return nil
+}
+
+// ScheduleWithinQuota schedules a new Job same as Schedule, provided the Project can hold another Server.
+//
+// Counting the Servers of the Project and saving the Job happen in a single transaction.
+func (js *JobScheduler) ScheduleWithinQuota(ctx context.Context, job *Job, project *infrastructure.Project) error {
+	// In-memory transactions cannot fail, so an exceeded quota is reported after the transaction.
+	var quotaErr error
+
+	err := js.txContext.RunInTransaction(ctx, func(ctx context.Context) error {
+		servers, err := js.serverRepo.ListByProject(ctx, project.ID)
 		if err != nil {
-			return errors.Wrap(err, "create server request")
+			return errors.Wrap(err, "list project servers")
+		}
+
+		quotaErr = project.CheckServerQuota(len(servers))
+		if quotaErr != nil {
+			return nil
 		}
 
-		err = js.deploymentRepo.Create(ctx, job.Deployment)
+		return js.createJob(ctx, job)
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed scheduling job")
+	}
+
+	return quotaErr
+}
+
+func (js *JobScheduler) createJob(ctx context.Context, job *Job) error {
+	err := js.serverRepo.Create(ctx, job.Server)
+	if err != nil {
+		return errors.Wrap(err, "create server request")
+	}
+
+	err = js.deploymentRepo.Create(ctx, job.Deployment)
+	if err != nil {
+		return errors.Wrap(err, "create deployment request")
+	}
+
+	err = js.jobRepo.Create(ctx, job)
+	if err != nil {
+		return errors.Wrap(err, "create job request")
+	}
+
+	return nil
+}
+
+// ScheduleVolumeResize schedules a new Job growing the data volume of an existing Server
+// to the provided size in GB.
+//
+// Only a single volume resize can be in progress for a Server at a time.
+func (js *JobScheduler) ScheduleVolumeResize(
+	ctx context.Context,
+	provider *infrastructure.ProviderSettings,
+	srv *infrastructure.Server,
+	size int,
+) (*Job, error) {
+	job, err := NewVolumeResizeJob(srv.AccountID, provider, srv, size)
+	if err != nil {
+		return nil, errors.Wrap(err, "create volume resize job")
+	}
+
+	err = js.scheduleServerUpdate(ctx, job)
+	if err != nil {
+		return nil, err
+	}
+
+	return job, nil
+}
+
+// ScheduleFirewallUpdate schedules a new Job replacing the firewall allow-list of an existing Server.
+func (js *JobScheduler) ScheduleFirewallUpdate(
+	ctx context.Context,
+	provider *infrastructure.ProviderSettings,
+	srv *infrastructure.Server,
+	allowedIPs []string,
+) (*Job, error) {
+	job, err := NewFirewallUpdateJob(srv.AccountID, provider, srv, allowedIPs)
+	if err != nil {
+		return nil, errors.Wrap(err, "create firewall update job")
+	}
+
+	err = js.scheduleServerUpdate(ctx, job)
+	if err != nil {
+		return nil, err
+	}
+
+	return job, nil
+}
+
+// ScheduleServerLabelsUpdate schedules a new Job replacing the labels of an existing Server.
+func (js *JobScheduler) ScheduleServerLabelsUpdate(
+	ctx context.Context,
+	provider *infrastructure.ProviderSettings,
+	srv *infrastructure.Server,
+	labels infrastructure.Labels,
+) (*Job, error) {
+	job, err := NewServerLabelsJob(srv.AccountID, provider, srv, labels)
+	if err != nil {
+		return nil, errors.Wrap(err, "create server labels job")
+	}
+
+	err = js.scheduleServerUpdate(ctx, job)
+	if err != nil {
+		return nil, err
+	}
+
+	return job, nil
+}
+
+// ScheduleDeploymentLabelsUpdate schedules a new Job replacing the labels of a Deployment running on an existing Server.
+func (js *JobScheduler) ScheduleDeploymentLabelsUpdate(
+	ctx context.Context,
+	provider *infrastructure.ProviderSettings,
+	srv *infrastructure.Server,
+	deployment *infrastructure.Deployment,
+	labels infrastructure.Labels,
+) (*Job, error) {
+	job, err := NewDeploymentLabelsJob(srv.AccountID, provider, srv, deployment, labels)
+	if err != nil {
+		return nil, errors.Wrap(err, "create deployment labels job")
+	}
+
+	err = js.scheduleServerUpdate(ctx, job)
+	if err != nil {
+		return nil, err
+	}
+
+	return job, nil
+}
+
+// scheduleServerUpdate saves a Job updating an existing Server.
+//
+// Updates are applied against the Terraform state of the Server, so no other Job
+// may be in progress for the Server at the same time. Checking for Jobs in progress
+// and saving the Job happen in a single transaction.
+func (js *JobScheduler) scheduleServerUpdate(ctx context.Context, job *Job) error {
+	// In-memory transactions cannot fail, so a Job in progress is reported after the transaction.
+	var inProgress error
+
+	err := js.txContext.RunInTransaction(ctx, func(ctx context.Context) error {
+		incomplete, err := js.jobRepo.FindIncomplete(ctx)
 		if err != nil {
-			return errors.Wrap(err, "create deployment request")
+			return errors.Wrap(err, "find incomplete jobs")
+		}
+
+		for _, other := range incomplete {
+			if other.ServerID != job.ServerID || other.FinishedAt != nil {
+				continue
+			}
+
+			inProgress = ErrServerUpdateInProgress
+			if job.IsVolumeResize() && other.IsVolumeResize() {
+				inProgress = ErrVolumeResizeInProgress
+			}
+
+			return nil
 		}
 
 		err = js.jobRepo.Create(ctx, job)
@@ -59,5 +223,5 @@ func (js *JobScheduler) Schedule(ctx context.Context, job *Job) error {
 		return errors.Wrap(err, "failed scheduling job")
 	}
 
-	return nil
+	return inProgress
 }
@@ -0,0 +1,53 @@
+package provision_test
+
+import (
+	"context"
+	"testing"
+
+	"blockpropeller.dev/blockpropeller/binance"
+	"blockpropeller.dev/blockpropeller/infrastructure"
+	"blockpropeller.dev/blockpropeller/provision"
+	"blockpropeller.dev/lib/test"
+	"github.com/blang/semver"
+)
+
+func TestJobScheduler_ScheduleWithinQuota(t *testing.T) {
+	f := newFixture(t)
+	defer test.Close(t, f)
+
+	project := infrastructure.NewProject("", "", "Nodes", "production")
+	project.MaxServers = 1
+	settings := infrastructure.NewProviderSettings("", "", infrastructure.ProviderDigitalOcean, "token")
+
+	newJob := func() *provision.Job {
+		srv, err := infrastructure.NewServerBuilder("").
+			Project(project).
+			Provider(infrastructure.ProviderDigitalOcean).
+			SSHKey(sshKey).
+			Build()
+		test.CheckErr(t, "build server", err)
+
+		job, err := provision.NewJobBuilder("").
+			Provider(settings).
+			Server(srv).
+			Deployment(binance.NewNodeDeployment(binance.NetworkTest, binance.TypeLightNode, semver.MustParse("0.6.1"))).
+			Build()
+		test.CheckErr(t, "build job", err)
+
+		return job
+	}
+
+	err := f.jobScheduler.ScheduleWithinQuota(context.Background(), newJob(), project)
+	test.CheckErr(t, "ScheduleWithinQuota()", err)
+
+	job := newJob()
+	err = f.jobScheduler.ScheduleWithinQuota(context.Background(), job, project)
+	assertErrCause(t, "ScheduleWithinQuota() over quota", err, infrastructure.ErrProjectQuotaExceeded)
+
+	_, err = f.srvRepo.Find(context.Background(), job.Server.ID)
+	test.CheckErrExists(t, "find server over quota", err)
+
+	servers, err := f.srvRepo.ListByProject(context.Background(), project.ID)
+	test.CheckErr(t, "list project servers", err)
+	test.AssertIntsEqual(t, "project servers", len(servers), 1)
+}
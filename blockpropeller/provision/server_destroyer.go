@@ -3,6 +3,7 @@ package provision
 import (
 	"context"
 
+	"blockpropeller.dev/blockpropeller/byo"
 	"blockpropeller.dev/blockpropeller/database/transaction"
 	"blockpropeller.dev/blockpropeller/infrastructure"
 	"blockpropeller.dev/blockpropeller/terraform"
@@ -12,26 +13,60 @@ import (
 
 // ServerDestroyer is responsible for, given a server entity, destroying the
 // infrastructure associated with it via Terraform.
+//
+// Existing hosts brought in by the user are never destroyed, only
+// the deployments running on them are removed.
 type ServerDestroyer struct {
-	tf *terraform.Terraform
+	tf                *terraform.Terraform
+	deployProvisioner *DeploymentProvisioner
 
 	txContext      transaction.TxContext
 	srvRepo        infrastructure.ServerRepository
 	deploymentRepo infrastructure.DeploymentRepository
+	settingsRepo   infrastructure.ProviderSettingsRepository
 }
 
 // NewServerDestroyer returns a new ServerDestroyer instance.
-func NewServerDestroyer(tf *terraform.Terraform, txContext transaction.TxContext, srvRepo infrastructure.ServerRepository, deploymentRepo infrastructure.DeploymentRepository) *ServerDestroyer {
-	return &ServerDestroyer{tf: tf, txContext: txContext, srvRepo: srvRepo, deploymentRepo: deploymentRepo}
+func NewServerDestroyer(
+	tf *terraform.Terraform,
+	deployProvisioner *DeploymentProvisioner,
+	txContext transaction.TxContext,
+	srvRepo infrastructure.ServerRepository,
+	deploymentRepo infrastructure.DeploymentRepository,
+	settingsRepo infrastructure.ProviderSettingsRepository,
+) *ServerDestroyer {
+	return &ServerDestroyer{
+		tf:                tf,
+		deployProvisioner: deployProvisioner,
+		txContext:         txContext,
+		srvRepo:           srvRepo,
+		deploymentRepo:    deploymentRepo,
+		settingsRepo:      settingsRepo,
+	}
 }
 
 // Destroy runs the destruction of resources associated with the Server entity.
 func (sd *ServerDestroyer) Destroy(ctx context.Context, srv *infrastructure.Server) error {
+	if srv.Provider == infrastructure.ProviderBYO {
+		return sd.releaseHost(ctx, srv)
+	}
+
 	if srv.WorkspaceSnapshot == nil {
 		return errors.New("missing workspace snapshot")
 	}
 
-	workspace, err := terraform.RestoreWorkspace(srv.WorkspaceSnapshot)
+	// Servers created before referencing their provider settings
+	// are destroyed with the credentials they were created with.
+	var provider *infrastructure.ProviderSettings
+	if srv.ProviderSettingsID != infrastructure.NilProviderSettingsID {
+		var err error
+		provider, err = sd.settingsRepo.Find(ctx, srv.ProviderSettingsID)
+		if err != nil {
+			return errors.Wrap(err, "find provider settings")
+		}
+	}
+
+	workspace, err := restoreWorkspace(provider, srv.WorkspaceSnapshot)
 	if err != nil {
 		return errors.Wrap(err, "restore workspace")
 	}
@@ -55,8 +90,60 @@ func (sd *ServerDestroyer) Destroy(ctx context.Context, srv *infrastructure.Serv
 		return errors.Wrap(err, "snapshot workspace")
 	}
 
+	return sd.delete(ctx, srv)
+}
+
+// releaseHost removes the deployments from an existing host and forgets about it.
+//
+// The management key which replaced a bootstrap password is revoked as well,
+// so bringing the host in again requires fresh credentials.
+func (sd *ServerDestroyer) releaseHost(ctx context.Context, srv *infrastructure.Server) error {
+	// Hosts without an address were never bootstrapped, so there is nothing to clean up.
+	if srv.IPAddress != "" {
+		revokedKeys, err := sd.managementKeys(ctx, srv)
+		if err != nil {
+			return err
+		}
+
+		err = sd.deployProvisioner.Deprovision(ctx, srv, revokedKeys...)
+		if err != nil {
+			return errors.Wrap(err, "deprovision host")
+		}
+	}
+
+	return sd.delete(ctx, srv)
+}
+
+// managementKeys returns the public management key authorized for root on an existing host, if any.
+func (sd *ServerDestroyer) managementKeys(ctx context.Context, srv *infrastructure.Server) ([]string, error) {
+	if srv.ProviderSettingsID == infrastructure.NilProviderSettingsID {
+		return nil, nil
+	}
+
+	provider, err := sd.settingsRepo.Find(ctx, srv.ProviderSettingsID)
+	if err != nil {
+		return nil, errors.Wrap(err, "find provider settings")
+	}
+
+	creds, err := byo.ParseCredentials(provider)
+	if err != nil {
+		return nil, errors.Wrap(err, "parse host credentials")
+	}
+
+	pubKey, err := creds.ManagementPublicKey()
+	if err != nil {
+		return nil, errors.Wrap(err, "get management key")
+	}
+	if pubKey == "" {
+		return nil, nil
+	}
+
+	return []string{pubKey}, nil
+}
+
+func (sd *ServerDestroyer) delete(ctx context.Context, srv *infrastructure.Server) error {
 	return sd.txContext.RunInTransaction(ctx, func(ctx context.Context) error {
-		err = sd.deploymentRepo.DeleteForServer(ctx, srv)
+		err := sd.deploymentRepo.DeleteForServer(ctx, srv)
 		if err != nil {
 			return errors.Wrap(err, "delete deployments")
 		}
@@ -0,0 +1,245 @@
+package provision_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"blockpropeller.dev/blockpropeller/byo"
+	"blockpropeller.dev/blockpropeller/infrastructure"
+	"blockpropeller.dev/blockpropeller/terraform"
+	"blockpropeller.dev/lib/test"
+)
+
+func TestServerDestroyer_Destroy(t *testing.T) {
+	f := newFixture(t)
+	defer test.Close(t, f)
+
+	srv := f.provisionedServer(t)
+	f.newDeployment(t, srv)
+
+	err := f.serverDestroyer.Destroy(context.Background(), srv)
+	test.CheckErr(t, "Destroy()", err)
+
+	assertCommands(t, "terraform commands", f.terraform, "init", "destroy")
+
+	_, err = f.srvRepo.Find(context.Background(), srv.ID)
+	assertErrCause(t, "find destroyed server", err, infrastructure.ErrServerNotFound)
+
+	deployments, err := f.deploymentRepo.FindByServer(context.Background(), srv.ID)
+	test.CheckErr(t, "find deployments", err)
+	test.AssertIntsEqual(t, "remaining deployments", len(deployments), 0)
+}
+
+func TestServerDestroyer_DestroyRotatedCredentials(t *testing.T) {
+	f := newFixture(t)
+	defer test.Close(t, f)
+
+	srv := f.provisionedServer(t)
+
+	settings, err := f.settingsRepo.Find(context.Background(), srv.ProviderSettingsID)
+	test.CheckErr(t, "find provider settings", err)
+
+	settings.Credentials = "rotated-token"
+	err = f.settingsRepo.Update(context.Background(), settings)
+	test.CheckErr(t, "update provider settings", err)
+
+	destroy := f.terraform.On("destroy").Capture("override.tf")
+
+	err = f.serverDestroyer.Destroy(context.Background(), srv)
+	test.CheckErr(t, "Destroy()", err)
+
+	want := `provider "digitalocean" {
+  token="rotated-token"
+}
+
+`
+	test.AssertStringsEqual(t, "override.tf contents", destroy.Captured(), want)
+}
+
+func TestServerDestroyer_DestroyFailures(t *testing.T) {
+	tests := []struct {
+		name      string
+		setup     func(f *fixture)
+		wantCmds  []string
+		wantCause error
+	}{
+		{
+			name: "init fails",
+			setup: func(f *fixture) {
+				f.terraform.On("init").Exit(1)
+			},
+			wantCmds: []string{"init"},
+		},
+		{
+			name: "destroy fails",
+			setup: func(f *fixture) {
+				f.terraform.On("destroy").Stderr("Error: Error deleting droplet").Exit(1)
+			},
+			wantCmds: []string{"init", "destroy"},
+		},
+		{
+			name: "destroy hangs",
+			setup: func(f *fixture) {
+				f.terraform.On("destroy").Hang()
+			},
+			wantCmds:  []string{"init", "destroy"},
+			wantCause: terraform.ErrTimeout,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFixture(t)
+			defer test.Close(t, f)
+
+			srv := f.provisionedServer(t)
+			f.newDeployment(t, srv)
+
+			tt.setup(f)
+
+			err := f.serverDestroyer.Destroy(context.Background(), srv)
+			if tt.wantCause != nil {
+				assertErrCause(t, "Destroy()", err, tt.wantCause)
+			} else {
+				test.CheckErrExists(t, "Destroy()", err)
+			}
+
+			assertCommands(t, "terraform commands", f.terraform, tt.wantCmds...)
+
+			_, err = f.srvRepo.Find(context.Background(), srv.ID)
+			test.CheckErr(t, "server must not be deleted", err)
+
+			deployments, err := f.deploymentRepo.FindByServer(context.Background(), srv.ID)
+			test.CheckErr(t, "find deployments", err)
+			test.AssertIntsEqual(t, "remaining deployments", len(deployments), 1)
+		})
+	}
+}
+
+func TestServerDestroyer_DestroyMissingSnapshot(t *testing.T) {
+	f := newFixture(t)
+	defer test.Close(t, f)
+
+	srv := f.newServer(t, infrastructure.ProviderDigitalOcean)
+
+	err := f.serverDestroyer.Destroy(context.Background(), srv)
+	test.CheckErrExists(t, "Destroy()", err)
+
+	assertCommands(t, "terraform commands", f.terraform)
+}
+
+func TestServerDestroyer_DestroyExistingHost(t *testing.T) {
+	f := newFixture(t)
+	defer test.Close(t, f)
+
+	srv := f.newServer(t, infrastructure.ProviderBYO)
+	srv.State = infrastructure.ServerStateOk
+	srv.IPAddress = "203.0.113.10"
+	f.newDeployment(t, srv)
+
+	err := f.serverDestroyer.Destroy(context.Background(), srv)
+	test.CheckErr(t, "Destroy()", err)
+
+	assertCommands(t, "terraform commands", f.terraform)
+
+	calls := f.ansible.Calls()
+	test.AssertIntsEqual(t, "ansible-playbook calls", len(calls), 2)
+	test.AssertStringsEqual(t, "deprovision playbook", calls[0][len(calls[0])-1], "deprovision.yaml")
+	test.AssertStringsEqual(t, "remove key playbook", calls[1][len(calls[1])-1], "remove_authorized_key.yaml")
+
+	_, err = f.srvRepo.Find(context.Background(), srv.ID)
+	assertErrCause(t, "find released server", err, infrastructure.ErrServerNotFound)
+}
+
+func TestServerDestroyer_DestroyExistingHostManagementKey(t *testing.T) {
+	userKey, err := infrastructure.GenerateNewSSHKey("user")
+	test.CheckErr(t, "generate user key", err)
+	managementKey, err := infrastructure.GenerateNewSSHKey("management")
+	test.CheckErr(t, "generate management key", err)
+
+	userCreds := &byo.Credentials{Host: "203.0.113.10", PrivateKey: userKey.EncodedPrivateKey()}
+	managedCreds := &byo.Credentials{Host: "203.0.113.10", User: "ubuntu", Password: "secret"}
+	managedCreds.ReplacePassword(managementKey)
+
+	tests := []struct {
+		name        string
+		creds       *byo.Credentials
+		revokedKeys []string
+	}{
+		{"user key", userCreds, nil},
+		{"management key", managedCreds, []string{managementKey.EncodedPublicKey()}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFixture(t)
+			defer test.Close(t, f)
+
+			credentials, err := tt.creds.Marshal()
+			test.CheckErr(t, "marshal credentials", err)
+
+			settings := infrastructure.NewProviderSettings("", "", infrastructure.ProviderBYO, credentials)
+			err = f.settingsRepo.Create(context.Background(), settings)
+			test.CheckErr(t, "create provider settings", err)
+
+			srv := f.newServer(t, infrastructure.ProviderBYO)
+			srv.ProviderSettingsID = settings.ID
+			srv.State = infrastructure.ServerStateOk
+			srv.IPAddress = "203.0.113.10"
+			f.newDeployment(t, srv)
+
+			err = f.serverDestroyer.Destroy(context.Background(), srv)
+			test.CheckErr(t, "Destroy()", err)
+
+			// Revoked keys are removed while the Server SSHKey can still be used for connecting.
+			wantKeys := append(tt.revokedKeys, srv.SSHKey.EncodedPublicKey())
+
+			calls := f.ansible.Calls()
+			test.AssertIntsEqual(t, "ansible-playbook calls", len(calls), 1+len(wantKeys))
+			for i, wantKey := range wantKeys {
+				call := strings.Join(calls[1+i], " ")
+				if !strings.Contains(call, "revoked_authorized_key='"+wantKey+"'") {
+					t.Errorf("remove key call %d = %q, want key %q", i, call, wantKey)
+				}
+			}
+		})
+	}
+}
+
+func TestServerDestroyer_DestroyExistingHostUnreachable(t *testing.T) {
+	f := newFixture(t)
+	defer test.Close(t, f)
+
+	f.ansible.On("deprovision.yaml").Exit(4)
+
+	srv := f.newServer(t, infrastructure.ProviderBYO)
+	srv.State = infrastructure.ServerStateOk
+	srv.IPAddress = "203.0.113.10"
+	f.newDeployment(t, srv)
+
+	err := f.serverDestroyer.Destroy(context.Background(), srv)
+	test.CheckErrExists(t, "Destroy()", err)
+
+	_, err = f.srvRepo.Find(context.Background(), srv.ID)
+	test.CheckErr(t, "server must not be deleted", err)
+}
+
+// provisionedServer runs a successful provisioning of a new Server,
+// so it holds a Terraform workspace snapshot.
+func (f *fixture) provisionedServer(t *testing.T) *infrastructure.Server {
+	f.terraform.On("output").Stdout("203.0.113.10\n")
+
+	settings := infrastructure.NewProviderSettings("", "", infrastructure.ProviderDigitalOcean, "token")
+	err := f.settingsRepo.Create(context.Background(), settings)
+	test.CheckErr(t, "create provider settings", err)
+
+	srv := f.newServer(t, infrastructure.ProviderDigitalOcean)
+	srv.ProviderSettingsID = settings.ID
+
+	err = f.serverProvisioner.Provision(context.Background(), settings, srv)
+	test.CheckErr(t, "provision server", err)
+
+	// Only record calls made after provisioning.
+	f.terraform.Reset()
+
+	return srv
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"net"
 
+	"blockpropeller.dev/blockpropeller/byo"
 	"blockpropeller.dev/blockpropeller/infrastructure"
 	"blockpropeller.dev/blockpropeller/terraform"
 	"blockpropeller.dev/blockpropeller/terraform/cloudprovider"
@@ -14,19 +15,41 @@ import (
 var (
 	// ErrServerNotReadyForProvisioning is returned for Servers that are not in an appropriate state for provisioning.
 	ErrServerNotReadyForProvisioning = errors.New("server not ready for provisioning")
+	// ErrServerNotManaged is returned for Servers whose infrastructure is not managed through Terraform.
+	ErrServerNotManaged = errors.New("server infrastructure not managed by BlockPropeller")
 )
 
 // ServerProvisioner takes a Server and provisions desired infrastructure
 // to the defined provider using Terraform.
+//
+// Existing hosts brought in by the user skip Terraform and are only
+// bootstrapped with the Server SSHKey.
 type ServerProvisioner struct {
-	tf *terraform.Terraform
+	managementIPs []string
 
-	srvRepo infrastructure.ServerRepository
+	tf           *terraform.Terraform
+	bootstrapper *byo.Bootstrapper
+
+	srvRepo      infrastructure.ServerRepository
+	settingsRepo infrastructure.ProviderSettingsRepository
 }
 
 // NewServerProvisioner returns a new ServerProvisioner instance.
-func NewServerProvisioner(tf *terraform.Terraform, srvRepo infrastructure.ServerRepository) *ServerProvisioner {
-	return &ServerProvisioner{tf: tf, srvRepo: srvRepo}
+func NewServerProvisioner(
+	cfg *FirewallConfig,
+	tf *terraform.Terraform,
+	bootstrapper *byo.Bootstrapper,
+	srvRepo infrastructure.ServerRepository,
+	settingsRepo infrastructure.ProviderSettingsRepository,
+) *ServerProvisioner {
+	return &ServerProvisioner{
+		managementIPs: cfg.ManagementIPs,
+
+		tf:           tf,
+		bootstrapper: bootstrapper,
+		srvRepo:      srvRepo,
+		settingsRepo: settingsRepo,
+	}
 }
 
 // Provision runs the provisioning via Terraform for the provided provider and server spec.
@@ -35,13 +58,17 @@ func (sp *ServerProvisioner) Provision(ctx context.Context, provider *infrastruc
 		return ErrServerNotReadyForProvisioning
 	}
 
+	if provider.Type == infrastructure.ProviderBYO {
+		return sp.bootstrapHost(ctx, provider, srv)
+	}
+
 	var workspace *terraform.Workspace
 	var err error
 	if srv.WorkspaceSnapshot == nil {
 		workspace, err = sp.setupWorkspace(provider, srv)
 	} else {
 		//@TODO: Test out this code path.
-		workspace, err = terraform.RestoreWorkspace(srv.WorkspaceSnapshot)
+		workspace, err = restoreWorkspace(provider, srv.WorkspaceSnapshot)
 	}
 	if err != nil {
 		return errors.Wrap(err, "failed setting up workspace")
@@ -51,25 +78,9 @@ func (sp *ServerProvisioner) Provision(ctx context.Context, provider *infrastruc
 		log.Closer(workspace)
 	}()
 
-	log.Debug("running terraform init...")
-
-	err = sp.tf.Init(workspace)
+	err = applyWorkspace(sp.tf, workspace)
 	if err != nil {
-		return errors.Wrap(err, "init workspace")
-	}
-
-	log.Debug("running terraform plan...")
-
-	err = sp.tf.Plan(workspace)
-	if err != nil {
-		return errors.Wrap(err, "prepare execution plan")
-	}
-
-	log.Debug("running terraform apply...")
-
-	err = sp.tf.Apply(workspace)
-	if err != nil {
-		return errors.Wrap(err, "apply execution plan")
+		return err
 	}
 
 	log.Debug("running terraform output...")
@@ -91,6 +102,142 @@ func (sp *ServerProvisioner) Provision(ctx context.Context, provider *infrastruc
 	srv.IPAddress = ip.String()
 	srv.State = infrastructure.ServerStateOk
 
+	err = sp.readVolumeID(workspace, srv)
+	if err != nil {
+		return err
+	}
+
+	snap, err := workspace.Snapshot()
+	if err != nil {
+		return errors.Wrap(err, "take workspace snapshot")
+	}
+
+	srv.WorkspaceSnapshot = snap
+
+	err = sp.srvRepo.Update(ctx, srv)
+	if err != nil {
+		return errors.Wrap(err, "update server")
+	}
+
+	return nil
+}
+
+// UpdateLabels stores the current labels of the Server and applies them,
+// together with the labels of its Deployments, as tags of the Server infrastructure.
+//
+// Existing hosts have no cloud resources to tag, so their labels are only stored.
+func (sp *ServerProvisioner) UpdateLabels(ctx context.Context, srv *infrastructure.Server) error {
+	if srv.Provider == infrastructure.ProviderBYO {
+		err := sp.srvRepo.Update(ctx, srv)
+		if err != nil {
+			return errors.Wrap(err, "update server")
+		}
+
+		return nil
+	}
+
+	return sp.Reapply(ctx, srv)
+}
+
+// UpdateServerLabels replaces the labels of a Server and applies them.
+//
+// The new labels are only kept once applied.
+func (sp *ServerProvisioner) UpdateServerLabels(ctx context.Context, srv *infrastructure.Server, labels infrastructure.Labels) error {
+	previousLabels := srv.Labels
+
+	err := srv.SetLabels(labels)
+	if err != nil {
+		return err
+	}
+
+	err = sp.UpdateLabels(ctx, srv)
+	if err != nil {
+		srv.Labels = previousLabels
+		return err
+	}
+
+	return nil
+}
+
+// UpdateDeploymentLabels replaces the labels of a Deployment running on the Server and applies them,
+// returning the updated Deployment.
+//
+// The new labels are only kept once applied.
+func (sp *ServerProvisioner) UpdateDeploymentLabels(
+	ctx context.Context,
+	srv *infrastructure.Server,
+	deploymentID infrastructure.DeploymentID,
+	labels infrastructure.Labels,
+) (*infrastructure.Deployment, error) {
+	var deployment *infrastructure.Deployment
+	for _, candidate := range srv.Deployments {
+		if candidate.ID == deploymentID {
+			deployment = candidate
+		}
+	}
+	if deployment == nil {
+		return nil, errors.Errorf("deployment %s not found on server %s", deploymentID, srv.ID)
+	}
+
+	previousLabels := deployment.Labels
+
+	err := deployment.SetLabels(labels)
+	if err != nil {
+		return nil, err
+	}
+
+	err = sp.UpdateLabels(ctx, srv)
+	if err != nil {
+		deployment.Labels = previousLabels
+		return nil, err
+	}
+
+	return deployment, nil
+}
+
+// Reapply updates the infrastructure of a provisioned Server to match its current specification,
+// such as the firewall allow-list.
+//
+// Terraform definitions are generated anew with the current provider settings,
+// and applied against the state of the Server workspace.
+func (sp *ServerProvisioner) Reapply(ctx context.Context, srv *infrastructure.Server) error {
+	if srv.Provider == infrastructure.ProviderBYO || srv.WorkspaceSnapshot == nil {
+		return ErrServerNotManaged
+	}
+
+	if srv.State != infrastructure.ServerStateOk {
+		return errors.Errorf("server in %s state cannot be updated", srv.State)
+	}
+
+	provider, err := sp.settingsRepo.Find(ctx, srv.ProviderSettingsID)
+	if err != nil {
+		return errors.Wrap(err, "find provider settings")
+	}
+
+	workspace, err := sp.setupWorkspace(provider, srv)
+	if err != nil {
+		return errors.Wrap(err, "failed setting up workspace")
+	}
+	defer func() {
+		log.Debug("cleaning up Terraform workspace")
+		log.Closer(workspace)
+	}()
+
+	err = workspace.RestoreState(srv.WorkspaceSnapshot)
+	if err != nil {
+		return errors.Wrap(err, "restore workspace state")
+	}
+
+	err = applyWorkspace(sp.tf, workspace)
+	if err != nil {
+		return err
+	}
+
+	err = sp.readVolumeID(workspace, srv)
+	if err != nil {
+		return err
+	}
+
 	snap, err := workspace.Snapshot()
 	if err != nil {
 		return errors.Wrap(err, "take workspace snapshot")
@@ -106,6 +253,155 @@ func (sp *ServerProvisioner) Provision(ctx context.Context, provider *infrastruc
 	return nil
 }
 
+// ResizeVolume grows the data volume of a provisioned Server to the provided size in GB.
+//
+// The volume resource is updated in the Server workspace and re-applied, leaving the
+// filesystem on the volume to be grown separately. The new size is only kept once applied.
+func (sp *ServerProvisioner) ResizeVolume(ctx context.Context, srv *infrastructure.Server, size int) error {
+	err := srv.ValidateVolumeSize(size)
+	if err != nil {
+		return err
+	}
+
+	previousSize := srv.VolumeSize
+	srv.VolumeSize = size
+
+	err = sp.Reapply(ctx, srv)
+	if err != nil {
+		srv.VolumeSize = previousSize
+		return errors.Wrap(err, "reapply server infrastructure")
+	}
+
+	return nil
+}
+
+// UpdateFirewall replaces the firewall allow-list of a provisioned Server and applies it.
+//
+// The new allow-list is only kept once applied.
+func (sp *ServerProvisioner) UpdateFirewall(ctx context.Context, srv *infrastructure.Server, allowedIPs []string) error {
+	previousIPs := srv.AllowedIPs
+
+	err := srv.SetAllowedIPs(allowedIPs)
+	if err != nil {
+		return err
+	}
+
+	err = sp.Reapply(ctx, srv)
+	if err != nil {
+		srv.AllowedIPs = previousIPs
+		return errors.Wrap(err, "reapply server infrastructure")
+	}
+
+	return nil
+}
+
+// readVolumeID stores the provider ID of the Server data volume, used for taking snapshots of it.
+//
+// Only CloudProviders able to snapshot data volumes expose the ID.
+func (sp *ServerProvisioner) readVolumeID(workspace *terraform.Workspace, srv *infrastructure.Server) error {
+	cloudProvider, err := cloudprovider.GetProvider(srv.Provider)
+	if err != nil {
+		return errors.Wrap(err, "get cloud provider")
+	}
+
+	if srv.VolumeSize == 0 || !cloudProvider.Catalog().VolumeSnapshots {
+		return nil
+	}
+
+	srv.VolumeID, err = sp.tf.Output(workspace, "volume-id")
+	if err != nil {
+		return errors.Wrap(err, "get volume id of provisioned server")
+	}
+
+	return nil
+}
+
+// applyWorkspace runs the Terraform definitions of the workspace.
+func applyWorkspace(tf *terraform.Terraform, workspace *terraform.Workspace) error {
+	log.Debug("running terraform init...")
+
+	err := tf.Init(workspace)
+	if err != nil {
+		return errors.Wrap(err, "init workspace")
+	}
+
+	log.Debug("running terraform plan...")
+
+	err = tf.Plan(workspace)
+	if err != nil {
+		return errors.Wrap(err, "prepare execution plan")
+	}
+
+	log.Debug("running terraform apply...")
+
+	err = tf.Apply(workspace)
+	if err != nil {
+		return errors.Wrap(err, "apply execution plan")
+	}
+
+	return nil
+}
+
+// bootstrapHost authorizes the Server SSHKey on an existing host.
+//
+// A bootstrap password is replaced in the provider settings by a management key
+// authorized along with the Server SSHKey, so the host can be bootstrapped again
+// when retrying or re-provisioning it.
+func (sp *ServerProvisioner) bootstrapHost(ctx context.Context, provider *infrastructure.ProviderSettings, srv *infrastructure.Server) error {
+	creds, err := byo.ParseCredentials(provider)
+	if err != nil {
+		return errors.Wrap(err, "parse host credentials")
+	}
+
+	log.Debug("bootstrapping existing host...", log.Fields{
+		"host": creds.Host,
+		"port": creds.Port,
+		"user": creds.User,
+	})
+
+	sshKeys := []*infrastructure.SSHKey{srv.SSHKey}
+
+	var managementKey *infrastructure.SSHKey
+	if creds.Password != "" {
+		managementKey, err = infrastructure.GenerateNewSSHKey("BlockPropeller - Host Management")
+		if err != nil {
+			return errors.Wrap(err, "generate management key")
+		}
+
+		sshKeys = append(sshKeys, managementKey)
+	}
+
+	err = sp.bootstrapper.Bootstrap(creds, sshKeys...)
+	if err != nil {
+		return errors.Wrap(err, "bootstrap host")
+	}
+
+	if managementKey != nil {
+		creds.ReplacePassword(managementKey)
+
+		provider.Credentials, err = creds.Marshal()
+		if err != nil {
+			return errors.Wrap(err, "marshal host credentials")
+		}
+
+		err = sp.settingsRepo.Update(ctx, provider)
+		if err != nil {
+			return errors.Wrap(err, "replace bootstrap password")
+		}
+	}
+
+	srv.IPAddress = creds.Host
+	srv.SSHPort = creds.Port
+	srv.State = infrastructure.ServerStateOk
+
+	err = sp.srvRepo.Update(ctx, srv)
+	if err != nil {
+		return errors.Wrap(err, "update server")
+	}
+
+	return nil
+}
+
 func (sp *ServerProvisioner) setupWorkspace(provider *infrastructure.ProviderSettings, srv *infrastructure.Server) (*terraform.Workspace, error) {
 	// Prepare workspace in which to execute Terraform plan.
 	workspace, err := terraform.NewWorkspace()
@@ -132,7 +428,30 @@ func (sp *ServerProvisioner) setupWorkspace(provider *infrastructure.ProviderSet
 		"credentials": provider.Credentials,
 	})
 
-	err = cloudProvider.AddServer(workspace, srv)
+	if srv.ReservedIPID != infrastructure.NilReservedIPID {
+		if srv.ReservedIP == nil || !srv.ReservedIP.IsAllocated() {
+			return nil, errors.Errorf("reserved ip %s not allocated", srv.ReservedIPID)
+		}
+	}
+
+	// Volumes keep being managed with the ID of the snapshot they were created from, even once it is deleted.
+	if srv.VolumeSnapshotID != infrastructure.NilVolumeSnapshotID {
+		if srv.VolumeSnapshot == nil || srv.VolumeSnapshot.ProviderID == "" {
+			return nil, errors.Errorf("volume snapshot %s not available", srv.VolumeSnapshotID)
+		}
+	}
+
+	srv.VolumeSize, err = cloudProvider.Catalog().ServerVolumeSize(srv)
+	if err != nil {
+		return nil, errors.Wrap(err, "get server volume size")
+	}
+
+	firewall, err := infrastructure.ServerFirewallRules(srv, sp.managementIPs)
+	if err != nil {
+		return nil, errors.Wrap(err, "get server firewall rules")
+	}
+
+	err = cloudProvider.AddServer(workspace, srv, firewall)
 	if err != nil {
 		return nil, errors.Wrap(err, "add server to workspace")
 	}
@@ -144,3 +463,38 @@ func (sp *ServerProvisioner) setupWorkspace(provider *infrastructure.ProviderSet
 
 	return workspace, nil
 }
+
+// restoreWorkspace restores a workspace snapshot, overriding the provider
+// with the current settings in case the credentials were rotated in the meantime.
+func restoreWorkspace(provider *infrastructure.ProviderSettings, snap *terraform.WorkspaceSnapshot) (*terraform.Workspace, error) {
+	workspace, err := terraform.RestoreWorkspace(snap)
+	if err != nil {
+		return nil, errors.Wrap(err, "restore workspace")
+	}
+
+	if provider == nil {
+		return workspace, nil
+	}
+
+	cloudProvider, err := cloudprovider.GetProvider(provider.Type)
+	if err != nil {
+		log.Closer(workspace)
+		return nil, errors.Wrap(err, "get cloud provider")
+	}
+
+	override := workspace.Override()
+
+	err = cloudProvider.Register(override, provider)
+	if err != nil {
+		log.Closer(workspace)
+		return nil, errors.Wrap(err, "register cloud provider in workspace override")
+	}
+
+	err = override.Flush()
+	if err != nil {
+		log.Closer(workspace)
+		return nil, errors.Wrap(err, "flush workspace override")
+	}
+
+	return workspace, nil
+}
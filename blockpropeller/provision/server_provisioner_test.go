@@ -0,0 +1,478 @@
+package provision_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"net"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+
+	"blockpropeller.dev/blockpropeller/byo"
+	"blockpropeller.dev/blockpropeller/encryption"
+	"blockpropeller.dev/blockpropeller/infrastructure"
+	"blockpropeller.dev/blockpropeller/provision"
+	"blockpropeller.dev/blockpropeller/terraform"
+	"blockpropeller.dev/lib/config"
+	"blockpropeller.dev/lib/test"
+	"golang.org/x/crypto/ssh"
+)
+
+func TestServerProvisioner_Provision(t *testing.T) {
+	f := newFixture(t)
+	defer test.Close(t, f)
+
+	f.terraform.On("output").Stdout("203.0.113.10\n")
+
+	srv := f.newServer(t, infrastructure.ProviderDigitalOcean)
+	settings := infrastructure.NewProviderSettings("", "", infrastructure.ProviderDigitalOcean, "token")
+
+	err := f.serverProvisioner.Provision(context.Background(), settings, srv)
+	test.CheckErr(t, "Provision()", err)
+
+	assertCommands(t, "terraform commands", f.terraform, "init", "plan", "apply", "output")
+
+	stored, err := f.srvRepo.Find(context.Background(), srv.ID)
+	test.CheckErr(t, "find server", err)
+
+	test.AssertStringsEqual(t, "Server.State", stored.State.String(), infrastructure.ServerStateOk.String())
+	test.AssertStringsEqual(t, "Server.IPAddress", stored.IPAddress, "203.0.113.10")
+	test.AssertBoolEqual(t, "has workspace snapshot", stored.WorkspaceSnapshot != nil, true)
+}
+
+func TestServerProvisioner_ProvisionFailures(t *testing.T) {
+	tests := []struct {
+		name      string
+		setup     func(f *fixture)
+		wantCmds  []string
+		wantCause error
+	}{
+		{
+			name: "init fails",
+			setup: func(f *fixture) {
+				f.terraform.On("init").Stderr("Error: Failed to query available provider packages").Exit(1)
+			},
+			wantCmds: []string{"init"},
+		},
+		{
+			name: "plan fails",
+			setup: func(f *fixture) {
+				f.terraform.On("plan").Stderr("Error: Unable to authenticate you").Exit(1)
+			},
+			wantCmds: []string{"init", "plan"},
+		},
+		{
+			name: "apply fails",
+			setup: func(f *fixture) {
+				f.terraform.On("apply").Stderr("Error: droplet limit exceeded").Exit(1)
+			},
+			wantCmds: []string{"init", "plan", "apply"},
+		},
+		{
+			name: "apply hangs",
+			setup: func(f *fixture) {
+				f.terraform.On("apply").Hang()
+			},
+			wantCmds:  []string{"init", "plan", "apply"},
+			wantCause: terraform.ErrTimeout,
+		},
+		{
+			name: "output fails",
+			setup: func(f *fixture) {
+				f.terraform.On("output").Stderr("The output variable requested could not be found").Exit(1)
+			},
+			wantCmds: []string{"init", "plan", "apply", "output"},
+		},
+		{
+			name: "output is not an ip address",
+			setup: func(f *fixture) {
+				f.terraform.On("output").Stdout("not-an-ip\n")
+			},
+			wantCmds: []string{"init", "plan", "apply", "output"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFixture(t)
+			defer test.Close(t, f)
+
+			tt.setup(f)
+
+			srv := f.newServer(t, infrastructure.ProviderDigitalOcean)
+			settings := infrastructure.NewProviderSettings("", "", infrastructure.ProviderDigitalOcean, "token")
+
+			err := f.serverProvisioner.Provision(context.Background(), settings, srv)
+			if tt.wantCause != nil {
+				assertErrCause(t, "Provision()", err, tt.wantCause)
+			} else {
+				test.CheckErrExists(t, "Provision()", err)
+			}
+
+			assertCommands(t, "terraform commands", f.terraform, tt.wantCmds...)
+			test.AssertStringsEqual(t, "Server.State", srv.State.String(), infrastructure.ServerStateRequested.String())
+			test.AssertStringsEqual(t, "Server.IPAddress", srv.IPAddress, "")
+		})
+	}
+}
+
+func TestServerProvisioner_ProvisionInvalidState(t *testing.T) {
+	f := newFixture(t)
+	defer test.Close(t, f)
+
+	srv := f.newServer(t, infrastructure.ProviderDigitalOcean)
+	srv.State = infrastructure.ServerStateOk
+	settings := infrastructure.NewProviderSettings("", "", infrastructure.ProviderDigitalOcean, "token")
+
+	err := f.serverProvisioner.Provision(context.Background(), settings, srv)
+	assertErrCause(t, "Provision()", err, provision.ErrServerNotReadyForProvisioning)
+
+	assertCommands(t, "terraform commands", f.terraform)
+}
+
+func TestServerProvisioner_ProvisionUnknownProvider(t *testing.T) {
+	f := newFixture(t)
+	defer test.Close(t, f)
+
+	srv := f.newServer(t, "unknown")
+	settings := infrastructure.NewProviderSettings("", "", "unknown", "token")
+
+	err := f.serverProvisioner.Provision(context.Background(), settings, srv)
+	test.CheckErrExists(t, "Provision()", err)
+
+	assertCommands(t, "terraform commands", f.terraform)
+}
+
+type exampleConfig struct {
+	Firewall *provision.FirewallConfig `yaml:"firewall"`
+}
+
+func (cfg *exampleConfig) Validate() error {
+	return nil
+}
+
+func TestServerProvisioner_ProvisionWithExampleConfig(t *testing.T) {
+	f := newFixture(t)
+	defer test.Close(t, f)
+
+	var cfg exampleConfig
+	_, err := config.NewFileProvider(config.WithName("config.yaml.example"), config.SearchForPath()).Load(&cfg)
+	test.CheckErr(t, "load example config", err)
+
+	provisioner := provision.NewServerProvisioner(
+		cfg.Firewall, terraform.New(f.terraform.Path()).WithTimeout(testTimeout), nil, f.srvRepo, f.settingsRepo)
+
+	f.terraform.On("output").Stdout("203.0.113.10\n")
+	apply := f.terraform.On("apply").Capture("main.tf")
+
+	srv := f.newServer(t, infrastructure.ProviderDigitalOcean)
+	f.newDeployment(t, srv)
+	settings := infrastructure.NewProviderSettings("", "", infrastructure.ProviderDigitalOcean, "token")
+
+	err = provisioner.Provision(context.Background(), settings, srv)
+	test.CheckErr(t, "Provision()", err)
+
+	// The RPC port the health checks run against stays reachable from the management IPs.
+	rpcRule := `port_range="27147"
+    source_addresses=["127.0.0.1/32"]`
+	definitions := apply.Captured()
+	if !strings.Contains(definitions, rpcRule) {
+		t.Errorf("main.tf missing rpc rule %s:\n%s", rpcRule, definitions)
+	}
+}
+
+func TestServerProvisioner_Reapply(t *testing.T) {
+	f := newFixture(t)
+	defer test.Close(t, f)
+
+	srv := f.provisionedServer(t)
+	f.newDeployment(t, srv)
+
+	state, err := encryption.Encrypt([]byte(`{"version": 4}`))
+	test.CheckErr(t, "encrypt state", err)
+	srv.WorkspaceSnapshot.TerraformState = string(state)
+
+	err = srv.SetAllowedIPs([]string{"203.0.113.0/24"})
+	test.CheckErr(t, "set allowed ips", err)
+
+	init := f.terraform.On("init").Capture("terraform.tfstate")
+	apply := f.terraform.On("apply").Capture("main.tf")
+
+	err = f.serverProvisioner.Reapply(context.Background(), srv)
+	test.CheckErr(t, "Reapply()", err)
+
+	assertCommands(t, "terraform commands", f.terraform, "init", "plan", "apply")
+	test.AssertStringsEqual(t, "restored state", init.Captured(), `{"version": 4}`)
+
+	definitions := apply.Captured()
+	test.AssertBoolEqual(t, "firewall resource", strings.Contains(definitions, `resource "digitalocean_firewall"`), true)
+	test.AssertBoolEqual(t, "allowed ip rule", strings.Contains(definitions, `"203.0.113.0/24"`), true)
+	test.AssertBoolEqual(t, "management ip rule", strings.Contains(definitions, `"198.51.100.7/32"`), true)
+
+	stored, err := f.srvRepo.Find(context.Background(), srv.ID)
+	test.CheckErr(t, "find server", err)
+	test.AssertStringsEqual(t, "stored allowed ips", strings.Join(stored.AllowedIPs, ","), "203.0.113.0/24")
+}
+
+func TestServerProvisioner_ReapplyUnmanagedServer(t *testing.T) {
+	f := newFixture(t)
+	defer test.Close(t, f)
+
+	srv := f.newServer(t, infrastructure.ProviderDigitalOcean)
+	srv.State = infrastructure.ServerStateOk
+
+	err := f.serverProvisioner.Reapply(context.Background(), srv)
+	assertErrCause(t, "Reapply()", err, provision.ErrServerNotManaged)
+
+	assertCommands(t, "terraform commands", f.terraform)
+}
+
+func TestServerProvisioner_UpdateLabels(t *testing.T) {
+	f := newFixture(t)
+	defer test.Close(t, f)
+
+	srv := f.provisionedServer(t)
+	deployment := f.newDeployment(t, srv)
+
+	err := deployment.SetLabels(infrastructure.Labels{"team": "nodes"})
+	test.CheckErr(t, "set deployment labels", err)
+	err = srv.SetLabels(infrastructure.Labels{"env": "prod"})
+	test.CheckErr(t, "set server labels", err)
+
+	apply := f.terraform.On("apply").Capture("main.tf")
+
+	err = f.serverProvisioner.UpdateLabels(context.Background(), srv)
+	test.CheckErr(t, "UpdateLabels()", err)
+
+	assertCommands(t, "terraform commands", f.terraform, "init", "plan", "apply")
+	test.AssertBoolEqual(t, "droplet tags", strings.Contains(apply.Captured(), `tags=["env:prod", "team:nodes"]`), true)
+
+	stored, err := f.srvRepo.Find(context.Background(), srv.ID)
+	test.CheckErr(t, "find server", err)
+	test.AssertStringsEqual(t, "stored labels", stored.Labels.String(), "env=prod")
+}
+
+func TestServerProvisioner_UpdateLabelsExistingHost(t *testing.T) {
+	f := newFixture(t)
+	defer test.Close(t, f)
+
+	srv := f.newServer(t, infrastructure.ProviderBYO)
+
+	err := srv.SetLabels(infrastructure.Labels{"env": "prod"})
+	test.CheckErr(t, "set server labels", err)
+
+	err = f.serverProvisioner.UpdateLabels(context.Background(), srv)
+	test.CheckErr(t, "UpdateLabels()", err)
+
+	assertCommands(t, "terraform commands", f.terraform)
+
+	stored, err := f.srvRepo.Find(context.Background(), srv.ID)
+	test.CheckErr(t, "find server", err)
+	test.AssertStringsEqual(t, "stored labels", stored.Labels.String(), "env=prod")
+}
+
+func TestServerProvisioner_ProvisionExistingHostTwice(t *testing.T) {
+	f := newFixture(t)
+	defer test.Close(t, f)
+
+	host := newExistingHost(t, "ubuntu", "secret")
+	defer test.Close(t, host.listener)
+
+	ctx := context.Background()
+	provisioner := provision.NewServerProvisioner(
+		&provision.FirewallConfig{}, terraform.New(f.terraform.Path()), byo.NewBootstrapper(), f.srvRepo, f.settingsRepo)
+
+	creds := &byo.Credentials{Host: "127.0.0.1", Port: host.port(), User: "ubuntu", Password: "secret"}
+	credentials, err := creds.Marshal()
+	test.CheckErr(t, "marshal credentials", err)
+
+	settings := infrastructure.NewProviderSettings("", "", infrastructure.ProviderBYO, credentials)
+	err = f.settingsRepo.Create(ctx, settings)
+	test.CheckErr(t, "create provider settings", err)
+
+	wantCmdPrefixes := []string{"sudo -S", "umask 077"}
+	for i, wantCmdPrefix := range wantCmdPrefixes {
+		// Every run loads the provider settings again, same as a retried provisioning job.
+		stored, err := f.settingsRepo.Find(ctx, settings.ID)
+		test.CheckErr(t, "find provider settings", err)
+
+		srv := f.newServer(t, infrastructure.ProviderBYO)
+
+		err = provisioner.Provision(ctx, stored, srv)
+		test.CheckErr(t, "Provision()", err)
+		test.AssertStringsEqual(t, "Server.State", srv.State.String(), infrastructure.ServerStateOk.String())
+
+		cmd := <-host.cmds
+		if !strings.HasPrefix(cmd, wantCmdPrefix) {
+			t.Errorf("bootstrap %d: command = %s, want prefix %s", i, cmd, wantCmdPrefix)
+		}
+
+		stored, err = f.settingsRepo.Find(ctx, settings.ID)
+		test.CheckErr(t, "find provider settings", err)
+
+		storedCreds, err := byo.ParseCredentials(stored)
+		test.CheckErr(t, "parse stored credentials", err)
+		test.AssertStringsEqual(t, "stored password", storedCreds.Password, "")
+		test.AssertStringsEqual(t, "stored user", storedCreds.User, "root")
+	}
+}
+
+// newExistingHost returns an SSH server emulating an existing host, which accepts
+// the bootstrap password of the user and the keys authorized for root by executed commands.
+func newExistingHost(t *testing.T, user string, password string) *sshServer {
+	hostKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	test.CheckErr(t, "generate host key", err)
+
+	signer, err := ssh.NewSignerFromKey(hostKey)
+	test.CheckErr(t, "create host key signer", err)
+
+	keys := &authorizedKeys{keys: make(map[string]bool)}
+
+	cfg := &ssh.ServerConfig{
+		PasswordCallback: func(conn ssh.ConnMetadata, pass []byte) (*ssh.Permissions, error) {
+			if conn.User() != user || string(pass) != password {
+				return nil, ssh.ErrNoAuth
+			}
+
+			return nil, nil
+		},
+		PublicKeyCallback: func(conn ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if conn.User() != "root" || !keys.has(key) {
+				return nil, ssh.ErrNoAuth
+			}
+
+			return nil, nil
+		},
+	}
+	cfg.AddHostKey(signer)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	test.CheckErr(t, "listen", err)
+
+	srv := &sshServer{listener: listener, cmds: make(chan string, 10), onExec: keys.add}
+	go srv.serve(cfg)
+
+	return srv
+}
+
+var authorizedKeyPattern = regexp.MustCompile(`ssh-rsa [A-Za-z0-9+/=]+`)
+
+// authorizedKeys collects the public keys found in the executed commands.
+type authorizedKeys struct {
+	mu   sync.Mutex
+	keys map[string]bool
+}
+
+func (ak *authorizedKeys) add(cmd string) {
+	ak.mu.Lock()
+	defer ak.mu.Unlock()
+
+	for _, key := range authorizedKeyPattern.FindAllString(cmd, -1) {
+		ak.keys[key] = true
+	}
+}
+
+func (ak *authorizedKeys) has(key ssh.PublicKey) bool {
+	ak.mu.Lock()
+	defer ak.mu.Unlock()
+
+	return ak.keys[strings.TrimSpace(string(ssh.MarshalAuthorizedKey(key)))]
+}
+
+func TestServerProvisioner_ProvisionRecordsVolumeSize(t *testing.T) {
+	f := newFixture(t)
+	defer test.Close(t, f)
+
+	f.terraform.On("ip-address").Stdout("203.0.113.10\n")
+	f.terraform.On("volume-id").Stdout("506f78a4-e098-11e5-ad9f-000f53306ae1\n")
+	apply := f.terraform.On("apply").Capture("main.tf")
+
+	srv := f.newServer(t, infrastructure.ProviderDigitalOcean)
+	srv.Size = infrastructure.ServerSizeProd
+	settings := infrastructure.NewProviderSettings("", "", infrastructure.ProviderDigitalOcean, "token")
+
+	err := f.serverProvisioner.Provision(context.Background(), settings, srv)
+	test.CheckErr(t, "Provision()", err)
+
+	assertCommands(t, "terraform commands", f.terraform, "init", "plan", "apply", "output", "output")
+	test.AssertBoolEqual(t, "volume resource", strings.Contains(apply.Captured(), "size=500"), true)
+
+	stored, err := f.srvRepo.Find(context.Background(), srv.ID)
+	test.CheckErr(t, "find server", err)
+	test.AssertIntsEqual(t, "Server.VolumeSize", stored.VolumeSize, 500)
+	test.AssertStringsEqual(t, "Server.VolumeID", stored.VolumeID, "506f78a4-e098-11e5-ad9f-000f53306ae1")
+}
+
+func TestServerProvisioner_ProvisionFromVolumeSnapshot(t *testing.T) {
+	f := newFixture(t)
+	defer test.Close(t, f)
+
+	snap := f.takenSnapshot(t, f.provisionedServer(t))
+
+	f.terraform.On("output").Stdout("203.0.113.10\n")
+	apply := f.terraform.On("apply").Capture("main.tf")
+
+	srv := f.newServer(t, infrastructure.ProviderDigitalOcean)
+	srv.Size = infrastructure.ServerSizeProd
+	srv.RestoreVolumeSnapshot(snap)
+	settings := infrastructure.NewProviderSettings("", "", infrastructure.ProviderDigitalOcean, "token")
+
+	err := f.serverProvisioner.Provision(context.Background(), settings, srv)
+	test.CheckErr(t, "Provision()", err)
+
+	test.AssertBoolEqual(t, "volume from snapshot", strings.Contains(apply.Captured(), `snapshot_id="12345"`), true)
+	test.AssertBoolEqual(t, "volume size of snapshot", strings.Contains(apply.Captured(), "size=750"), true)
+
+	// Servers cannot be provisioned from snapshots that have not been taken.
+	srv = f.newServer(t, infrastructure.ProviderDigitalOcean)
+	srv.RestoreVolumeSnapshot(infrastructure.NewVolumeSnapshot(srv, "fra1", false))
+
+	err = f.serverProvisioner.Provision(context.Background(), settings, srv)
+	test.CheckErrExists(t, "Provision() from requested snapshot", err)
+}
+
+func TestServerProvisioner_ResizeVolume(t *testing.T) {
+	f := newFixture(t)
+	defer test.Close(t, f)
+
+	srv := f.provisionedServer(t)
+	srv.VolumeSize = 500
+
+	apply := f.terraform.On("apply").Capture("main.tf")
+	f.terraform.On("volume-id").Stdout("506f78a4-e098-11e5-ad9f-000f53306ae1\n")
+
+	err := f.serverProvisioner.ResizeVolume(context.Background(), srv, 750)
+	test.CheckErr(t, "ResizeVolume()", err)
+
+	assertCommands(t, "terraform commands", f.terraform, "init", "plan", "apply", "output")
+	test.AssertBoolEqual(t, "resized volume", strings.Contains(apply.Captured(), "size=750"), true)
+
+	stored, err := f.srvRepo.Find(context.Background(), srv.ID)
+	test.CheckErr(t, "find server", err)
+	test.AssertIntsEqual(t, "Server.VolumeSize", stored.VolumeSize, 750)
+	test.AssertStringsEqual(t, "Server.VolumeID", stored.VolumeID, "506f78a4-e098-11e5-ad9f-000f53306ae1")
+}
+
+func TestServerProvisioner_ResizeVolumeFailures(t *testing.T) {
+	f := newFixture(t)
+	defer test.Close(t, f)
+
+	srv := f.provisionedServer(t)
+
+	err := f.serverProvisioner.ResizeVolume(context.Background(), srv, 750)
+	assertErrCause(t, "ResizeVolume() without volume", err, infrastructure.ErrServerWithoutVolume)
+
+	srv.VolumeSize = 500
+
+	err = f.serverProvisioner.ResizeVolume(context.Background(), srv, 250)
+	assertErrCause(t, "ResizeVolume() shrinking", err, infrastructure.ErrVolumeShrink)
+
+	assertCommands(t, "terraform commands", f.terraform)
+
+	f.terraform.On("apply").Stderr("Error: volume resize failed").Exit(1)
+
+	err = f.serverProvisioner.ResizeVolume(context.Background(), srv, 750)
+	test.CheckErrExists(t, "ResizeVolume() apply fails", err)
+	test.AssertIntsEqual(t, "Server.VolumeSize", srv.VolumeSize, 500)
+}
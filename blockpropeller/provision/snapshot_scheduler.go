@@ -0,0 +1,181 @@
+package provision
+
+import (
+	"context"
+	"time"
+
+	"blockpropeller.dev/blockpropeller/infrastructure"
+	"blockpropeller.dev/lib/log"
+	"github.com/pkg/errors"
+)
+
+// SnapshotConfig holds configuration for taking volume snapshots in the background.
+type SnapshotConfig struct {
+	Interval time.Duration `yaml:"interval"`
+}
+
+// Validate satisfies the config.Config interface.
+func (cfg *SnapshotConfig) Validate() error {
+	if cfg.Interval == 0 {
+		cfg.Interval = time.Minute
+	}
+
+	return nil
+}
+
+// SnapshotScheduler periodically takes the requested volume snapshots.
+//
+// Servers with a SnapshotPolicy get snapshots requested once their latest scheduled
+// snapshot is older than the policy interval, and scheduled snapshots falling out
+// of the policy retention are deleted.
+type SnapshotScheduler struct {
+	interval time.Duration
+
+	snapProvisioner *VolumeSnapshotProvisioner
+
+	srvRepo  infrastructure.ServerRepository
+	snapRepo infrastructure.VolumeSnapshotRepository
+}
+
+// NewSnapshotScheduler returns a new SnapshotScheduler instance.
+func NewSnapshotScheduler(
+	cfg *SnapshotConfig,
+	snapProvisioner *VolumeSnapshotProvisioner,
+	srvRepo infrastructure.ServerRepository,
+	snapRepo infrastructure.VolumeSnapshotRepository,
+) *SnapshotScheduler {
+	return &SnapshotScheduler{
+		interval:        cfg.Interval,
+		snapProvisioner: snapProvisioner,
+		srvRepo:         srvRepo,
+		snapRepo:        snapRepo,
+	}
+}
+
+// Start taking volume snapshots periodically, until the Context is finished.
+func (ss *SnapshotScheduler) Start(ctx context.Context) {
+	for ctx.Err() == nil {
+		err := ss.Run(ctx, time.Now())
+		if err != nil {
+			log.ErrorErr(err, "failed taking volume snapshots")
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(ss.interval):
+		}
+	}
+}
+
+// Run requests the scheduled snapshots due at the provided time, takes all requested
+// snapshots and prunes the scheduled snapshots falling out of retention.
+//
+// Failures of individual snapshots are logged, without stopping the others.
+func (ss *SnapshotScheduler) Run(ctx context.Context, now time.Time) error {
+	servers, err := ss.srvRepo.ListByState(ctx, infrastructure.ServerStateOk)
+	if err != nil {
+		return errors.Wrap(err, "list servers")
+	}
+
+	for _, srv := range servers {
+		if !srv.SnapshotPolicy.IsEnabled() {
+			continue
+		}
+
+		err = ss.requestScheduled(ctx, srv, now)
+		if err != nil {
+			log.ErrorErr(err, "failed requesting scheduled volume snapshot", log.Fields{
+				"server_id": srv.ID,
+			})
+		}
+	}
+
+	requested, err := ss.snapRepo.ListByState(ctx, infrastructure.VolumeSnapshotStateRequested)
+	if err != nil {
+		return errors.Wrap(err, "list requested volume snapshots")
+	}
+
+	for _, snap := range requested {
+		err = ss.snapProvisioner.Take(ctx, snap)
+		if err != nil {
+			log.ErrorErr(err, "failed taking volume snapshot", log.Fields{
+				"snapshot_id": snap.ID,
+				"server_id":   snap.ServerID,
+			})
+		}
+	}
+
+	for _, srv := range servers {
+		if !srv.SnapshotPolicy.IsEnabled() {
+			continue
+		}
+
+		err = ss.prune(ctx, srv)
+		if err != nil {
+			log.ErrorErr(err, "failed pruning volume snapshots", log.Fields{
+				"server_id": srv.ID,
+			})
+		}
+	}
+
+	return nil
+}
+
+func (ss *SnapshotScheduler) requestScheduled(ctx context.Context, srv *infrastructure.Server, now time.Time) error {
+	snapshots, err := ss.snapRepo.ListByServer(ctx, srv.ID)
+	if err != nil {
+		return errors.Wrap(err, "list server volume snapshots")
+	}
+
+	var latest *time.Time
+	for _, snap := range snapshots {
+		if !snap.Scheduled {
+			continue
+		}
+
+		if latest == nil || snap.CreatedAt.After(*latest) {
+			createdAt := snap.CreatedAt
+			latest = &createdAt
+		}
+	}
+
+	if !srv.SnapshotPolicy.IsDue(latest, now) {
+		return nil
+	}
+
+	snap, err := ss.snapProvisioner.Request(ctx, srv, true)
+	if errors.Cause(err) == ErrVolumeSnapshotInProgress {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "request volume snapshot")
+	}
+
+	log.Info("scheduled volume snapshot", log.Fields{
+		"server_id":   srv.ID,
+		"snapshot_id": snap.ID,
+	})
+
+	return nil
+}
+
+func (ss *SnapshotScheduler) prune(ctx context.Context, srv *infrastructure.Server) error {
+	snapshots, err := ss.snapRepo.ListByServer(ctx, srv.ID)
+	if err != nil {
+		return errors.Wrap(err, "list server volume snapshots")
+	}
+
+	for _, snap := range srv.SnapshotPolicy.Expired(snapshots) {
+		err = ss.snapProvisioner.Delete(ctx, snap)
+		if err != nil {
+			return errors.Wrapf(err, "delete volume snapshot %s", snap.ID)
+		}
+
+		log.Info("pruned volume snapshot", log.Fields{
+			"server_id":   srv.ID,
+			"snapshot_id": snap.ID,
+		})
+	}
+
+	return nil
+}
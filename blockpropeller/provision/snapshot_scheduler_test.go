@@ -0,0 +1,66 @@
+package provision_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"blockpropeller.dev/blockpropeller/infrastructure"
+	"blockpropeller.dev/lib/test"
+)
+
+func TestSnapshotScheduler_Run(t *testing.T) {
+	f := newFixture(t)
+	defer test.Close(t, f)
+
+	srv := f.serverWithVolume(t)
+	srv.SnapshotPolicy = infrastructure.SnapshotPolicy{IntervalHours: 24, Retain: 1}
+	err := f.srvRepo.Update(context.Background(), srv)
+	test.CheckErr(t, "update server", err)
+
+	// On-demand snapshots are not subject to the policy.
+	manual := f.takenSnapshot(t, srv)
+
+	f.terraform.On("snapshot-id").Stdout("12345\n")
+
+	now := time.Now()
+	err = f.snapshotScheduler.Run(context.Background(), now)
+	test.CheckErr(t, "Run()", err)
+
+	first := listSnapshots(t, f, srv)
+	test.AssertIntsEqual(t, "snapshots after first run", len(first), 2)
+
+	var scheduled *infrastructure.VolumeSnapshot
+	for _, snap := range first {
+		if snap.Scheduled {
+			scheduled = snap
+		}
+	}
+	test.AssertBoolEqual(t, "takes scheduled snapshot", scheduled != nil, true)
+	test.AssertStringsEqual(t, "VolumeSnapshot.State", string(scheduled.State), string(infrastructure.VolumeSnapshotStateOk))
+
+	err = f.snapshotScheduler.Run(context.Background(), now.Add(time.Hour))
+	test.CheckErr(t, "Run() before interval", err)
+	test.AssertIntsEqual(t, "snapshots before interval", len(listSnapshots(t, f, srv)), 2)
+
+	err = f.snapshotScheduler.Run(context.Background(), now.Add(25*time.Hour))
+	test.CheckErr(t, "Run() after interval", err)
+
+	retained := listSnapshots(t, f, srv)
+	test.AssertIntsEqual(t, "snapshots after retention", len(retained), 2)
+	for _, snap := range retained {
+		if snap.ID == scheduled.ID {
+			t.Errorf("expired scheduled snapshot %s not pruned", snap.ID)
+		}
+	}
+
+	_, err = f.snapRepo.Find(context.Background(), manual.ID)
+	test.CheckErr(t, "on-demand snapshot must be retained", err)
+}
+
+func listSnapshots(t *testing.T, f *fixture, srv *infrastructure.Server) []*infrastructure.VolumeSnapshot {
+	snapshots, err := f.snapRepo.ListByServer(context.Background(), srv.ID)
+	test.CheckErr(t, "list server volume snapshots", err)
+
+	return snapshots
+}
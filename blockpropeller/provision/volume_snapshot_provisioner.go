@@ -0,0 +1,244 @@
+package provision
+
+import (
+	"context"
+	"time"
+
+	"blockpropeller.dev/blockpropeller/infrastructure"
+	"blockpropeller.dev/blockpropeller/terraform"
+	"blockpropeller.dev/blockpropeller/terraform/cloudprovider"
+	"blockpropeller.dev/lib/log"
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrVolumeSnapshotInProgress is returned when a snapshot is requested or deleted while one is being taken.
+	ErrVolumeSnapshotInProgress = errors.New("volume snapshot already in progress")
+)
+
+// VolumeSnapshotProvisioner is responsible for taking and deleting snapshots of Server data volumes via Terraform.
+//
+// Each snapshot is managed inside its own Terraform workspace,
+// so it is retained when the Server it was taken of is destroyed.
+type VolumeSnapshotProvisioner struct {
+	tf *terraform.Terraform
+
+	srvRepo      infrastructure.ServerRepository
+	snapRepo     infrastructure.VolumeSnapshotRepository
+	settingsRepo infrastructure.ProviderSettingsRepository
+}
+
+// NewVolumeSnapshotProvisioner returns a new VolumeSnapshotProvisioner instance.
+func NewVolumeSnapshotProvisioner(
+	tf *terraform.Terraform,
+	srvRepo infrastructure.ServerRepository,
+	snapRepo infrastructure.VolumeSnapshotRepository,
+	settingsRepo infrastructure.ProviderSettingsRepository,
+) *VolumeSnapshotProvisioner {
+	return &VolumeSnapshotProvisioner{
+		tf:           tf,
+		srvRepo:      srvRepo,
+		snapRepo:     snapRepo,
+		settingsRepo: settingsRepo,
+	}
+}
+
+// Request stores a new VolumeSnapshot of the Server data volume, to be taken in the background.
+//
+// Only a single snapshot of a Server can be in progress at a time.
+func (vp *VolumeSnapshotProvisioner) Request(ctx context.Context, srv *infrastructure.Server, scheduled bool) (*infrastructure.VolumeSnapshot, error) {
+	if srv.Provider == infrastructure.ProviderBYO || srv.WorkspaceSnapshot == nil {
+		return nil, ErrServerNotManaged
+	}
+
+	if srv.State != infrastructure.ServerStateOk {
+		return nil, errors.Errorf("server in %s state cannot be snapshotted", srv.State)
+	}
+
+	cloudProvider, err := cloudprovider.GetProvider(srv.Provider)
+	if err != nil {
+		return nil, errors.Wrap(err, "get cloud provider")
+	}
+
+	catalog := cloudProvider.Catalog()
+	if !catalog.VolumeSnapshots {
+		return nil, cloudprovider.ErrVolumeSnapshotsNotSupported
+	}
+
+	if srv.VolumeSize == 0 || srv.VolumeID == "" {
+		return nil, infrastructure.ErrServerWithoutVolume
+	}
+
+	snapshots, err := vp.snapRepo.ListByServer(ctx, srv.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "list server volume snapshots")
+	}
+
+	for _, snap := range snapshots {
+		if snap.State == infrastructure.VolumeSnapshotStateRequested {
+			return nil, errors.Wrapf(ErrVolumeSnapshotInProgress, "snapshot %s", snap.ID)
+		}
+	}
+
+	snap := infrastructure.NewVolumeSnapshot(srv, catalog.ServerRegion(srv), scheduled)
+
+	err = vp.snapRepo.Create(ctx, snap)
+	if err != nil {
+		return nil, errors.Wrap(err, "create volume snapshot")
+	}
+
+	return snap, nil
+}
+
+// Take snapshots the data volume with the provider and stores the snapshot once taken.
+//
+// Snapshots that cannot be taken are marked as failed.
+func (vp *VolumeSnapshotProvisioner) Take(ctx context.Context, snap *infrastructure.VolumeSnapshot) error {
+	if snap.State != infrastructure.VolumeSnapshotStateRequested {
+		return errors.Errorf("volume snapshot in %s state cannot be taken", snap.State)
+	}
+
+	err := vp.take(ctx, snap)
+	if err != nil {
+		snap.State = infrastructure.VolumeSnapshotStateFailed
+		snap.Error = err.Error()
+		snap.UpdatedAt = time.Now()
+
+		updateErr := vp.snapRepo.Update(ctx, snap)
+		if updateErr != nil {
+			log.ErrorErr(updateErr, "failed marking volume snapshot as failed", log.Fields{
+				"snapshot_id": snap.ID,
+			})
+		}
+
+		return err
+	}
+
+	now := time.Now()
+
+	snap.State = infrastructure.VolumeSnapshotStateOk
+	snap.UpdatedAt = now
+	snap.CompletedAt = &now
+
+	err = vp.snapRepo.Update(ctx, snap)
+	if err != nil {
+		return errors.Wrap(err, "update volume snapshot")
+	}
+
+	return nil
+}
+
+func (vp *VolumeSnapshotProvisioner) take(ctx context.Context, snap *infrastructure.VolumeSnapshot) error {
+	srv, err := vp.srvRepo.Find(ctx, snap.ServerID)
+	if err != nil {
+		return errors.Wrap(err, "find server")
+	}
+
+	if srv.VolumeID == "" {
+		return infrastructure.ErrServerWithoutVolume
+	}
+
+	provider, err := vp.settingsRepo.Find(ctx, snap.ProviderSettingsID)
+	if err != nil {
+		return errors.Wrap(err, "find provider settings")
+	}
+
+	cloudProvider, err := cloudprovider.GetProvider(provider.Type)
+	if err != nil {
+		return errors.Wrap(err, "get cloud provider")
+	}
+
+	workspace, err := terraform.NewWorkspace()
+	if err != nil {
+		return errors.Wrap(err, "create new workspace")
+	}
+	defer func() {
+		log.Debug("cleaning up Terraform workspace")
+		log.Closer(workspace)
+	}()
+
+	err = cloudProvider.Register(workspace, provider)
+	if err != nil {
+		return errors.Wrap(err, "register cloud provider in workspace")
+	}
+
+	err = cloudProvider.AddVolumeSnapshot(workspace, snap, srv.VolumeID)
+	if err != nil {
+		return errors.Wrap(err, "add volume snapshot to workspace")
+	}
+
+	err = workspace.Flush()
+	if err != nil {
+		return errors.Wrap(err, "flush workspace")
+	}
+
+	err = applyWorkspace(vp.tf, workspace)
+	if err != nil {
+		return err
+	}
+
+	snap.ProviderID, err = vp.tf.Output(workspace, "snapshot-id")
+	if err != nil {
+		return errors.Wrap(err, "get snapshot id")
+	}
+
+	snap.WorkspaceSnapshot, err = workspace.Snapshot()
+	if err != nil {
+		return errors.Wrap(err, "take workspace snapshot")
+	}
+
+	return nil
+}
+
+// Delete destroys the snapshot with the provider.
+//
+// Servers created from the snapshot keep their data volumes.
+// Snapshots that are still being taken cannot be deleted.
+func (vp *VolumeSnapshotProvisioner) Delete(ctx context.Context, snap *infrastructure.VolumeSnapshot) error {
+	if snap.State == infrastructure.VolumeSnapshotStateRequested {
+		return ErrVolumeSnapshotInProgress
+	}
+
+	// Failed snapshots might not have been created with the provider.
+	if snap.WorkspaceSnapshot != nil {
+		provider, err := vp.settingsRepo.Find(ctx, snap.ProviderSettingsID)
+		if err != nil {
+			return errors.Wrap(err, "find provider settings")
+		}
+
+		err = vp.destroy(provider, snap)
+		if err != nil {
+			return err
+		}
+	}
+
+	err := vp.snapRepo.Delete(ctx, snap)
+	if err != nil {
+		return errors.Wrap(err, "delete volume snapshot")
+	}
+
+	return nil
+}
+
+func (vp *VolumeSnapshotProvisioner) destroy(provider *infrastructure.ProviderSettings, snap *infrastructure.VolumeSnapshot) error {
+	workspace, err := restoreWorkspace(provider, snap.WorkspaceSnapshot)
+	if err != nil {
+		return errors.Wrap(err, "restore workspace")
+	}
+	defer func() {
+		log.Debug("cleaning up Terraform workspace")
+		log.Closer(workspace)
+	}()
+
+	err = vp.tf.Init(workspace)
+	if err != nil {
+		return errors.Wrap(err, "init workspace")
+	}
+
+	err = vp.tf.Destroy(workspace)
+	if err != nil {
+		return errors.Wrap(err, "destroy workspace")
+	}
+
+	return nil
+}
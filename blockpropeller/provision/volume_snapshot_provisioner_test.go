@@ -0,0 +1,137 @@
+package provision_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"blockpropeller.dev/blockpropeller/infrastructure"
+	"blockpropeller.dev/blockpropeller/provision"
+	"blockpropeller.dev/lib/test"
+)
+
+func TestVolumeSnapshotProvisioner_RequestAndTake(t *testing.T) {
+	f := newFixture(t)
+	defer test.Close(t, f)
+
+	srv := f.serverWithVolume(t)
+
+	snap, err := f.snapProvisioner.Request(context.Background(), srv, false)
+	test.CheckErr(t, "Request()", err)
+	test.AssertStringsEqual(t, "VolumeSnapshot.State", string(snap.State), string(infrastructure.VolumeSnapshotStateRequested))
+	test.AssertIntsEqual(t, "VolumeSnapshot.SizeGB", snap.SizeGB, 750)
+
+	_, err = f.snapProvisioner.Request(context.Background(), srv, false)
+	assertErrCause(t, "Request() while in progress", err, provision.ErrVolumeSnapshotInProgress)
+
+	apply := f.terraform.On("apply").Capture("main.tf")
+	f.terraform.On("snapshot-id").Stdout("12345\n")
+
+	err = f.snapProvisioner.Take(context.Background(), snap)
+	test.CheckErr(t, "Take()", err)
+
+	assertCommands(t, "terraform commands", f.terraform, "init", "plan", "apply", "output")
+	test.AssertBoolEqual(t, "snapshots server volume",
+		strings.Contains(apply.Captured(), `volume_id="506f78a4-e098-11e5-ad9f-000f53306ae1"`), true)
+
+	stored, err := f.snapRepo.Find(context.Background(), snap.ID)
+	test.CheckErr(t, "find volume snapshot", err)
+	test.AssertStringsEqual(t, "VolumeSnapshot.State", string(stored.State), string(infrastructure.VolumeSnapshotStateOk))
+	test.AssertStringsEqual(t, "VolumeSnapshot.ProviderID", stored.ProviderID, "12345")
+	test.AssertBoolEqual(t, "has workspace snapshot", stored.WorkspaceSnapshot != nil, true)
+	test.AssertBoolEqual(t, "is available", stored.IsAvailable(), true)
+}
+
+func TestVolumeSnapshotProvisioner_RequestFailures(t *testing.T) {
+	f := newFixture(t)
+	defer test.Close(t, f)
+
+	unmanaged := f.newServer(t, infrastructure.ProviderDigitalOcean)
+	_, err := f.snapProvisioner.Request(context.Background(), unmanaged, false)
+	assertErrCause(t, "Request() for unmanaged server", err, provision.ErrServerNotManaged)
+
+	withoutVolume := f.provisionedServer(t)
+	_, err = f.snapProvisioner.Request(context.Background(), withoutVolume, false)
+	assertErrCause(t, "Request() for server without volume", err, infrastructure.ErrServerWithoutVolume)
+}
+
+func TestVolumeSnapshotProvisioner_TakeFailure(t *testing.T) {
+	f := newFixture(t)
+	defer test.Close(t, f)
+
+	srv := f.serverWithVolume(t)
+
+	snap, err := f.snapProvisioner.Request(context.Background(), srv, false)
+	test.CheckErr(t, "Request()", err)
+
+	f.terraform.On("apply").Stderr("Error: snapshot limit exceeded").Exit(1)
+
+	err = f.snapProvisioner.Take(context.Background(), snap)
+	test.CheckErrExists(t, "Take()", err)
+
+	stored, err := f.snapRepo.Find(context.Background(), snap.ID)
+	test.CheckErr(t, "find volume snapshot", err)
+	test.AssertStringsEqual(t, "VolumeSnapshot.State", string(stored.State), string(infrastructure.VolumeSnapshotStateFailed))
+	test.AssertBoolEqual(t, "records error", stored.Error != "", true)
+
+	// Failed snapshots do not block new ones.
+	_, err = f.snapProvisioner.Request(context.Background(), srv, false)
+	test.CheckErr(t, "Request() after failure", err)
+}
+
+func TestVolumeSnapshotProvisioner_Delete(t *testing.T) {
+	f := newFixture(t)
+	defer test.Close(t, f)
+
+	srv := f.serverWithVolume(t)
+
+	snap, err := f.snapProvisioner.Request(context.Background(), srv, false)
+	test.CheckErr(t, "Request()", err)
+
+	err = f.snapProvisioner.Delete(context.Background(), snap)
+	assertErrCause(t, "Delete() while in progress", err, provision.ErrVolumeSnapshotInProgress)
+
+	f.terraform.On("snapshot-id").Stdout("12345\n")
+
+	err = f.snapProvisioner.Take(context.Background(), snap)
+	test.CheckErr(t, "Take()", err)
+
+	f.terraform.Reset()
+
+	err = f.snapProvisioner.Delete(context.Background(), snap)
+	test.CheckErr(t, "Delete()", err)
+
+	assertCommands(t, "terraform commands", f.terraform, "init", "destroy")
+
+	_, err = f.snapRepo.Find(context.Background(), snap.ID)
+	assertErrCause(t, "find deleted volume snapshot", err, infrastructure.ErrVolumeSnapshotNotFound)
+}
+
+// serverWithVolume provisions a new Server with a 750 GB data volume.
+func (f *fixture) serverWithVolume(t *testing.T) *infrastructure.Server {
+	srv := f.provisionedServer(t)
+	srv.VolumeSize = 750
+	srv.VolumeID = "506f78a4-e098-11e5-ad9f-000f53306ae1"
+
+	err := f.srvRepo.Update(context.Background(), srv)
+	test.CheckErr(t, "update server", err)
+
+	return srv
+}
+
+// takenSnapshot stores a snapshot of a 750 GB data volume of the Server, as if it was taken with the provider.
+func (f *fixture) takenSnapshot(t *testing.T, srv *infrastructure.Server) *infrastructure.VolumeSnapshot {
+	now := time.Now()
+
+	snap := infrastructure.NewVolumeSnapshot(srv, "fra1", false)
+	snap.SizeGB = 750
+	snap.State = infrastructure.VolumeSnapshotStateOk
+	snap.ProviderID = "12345"
+	snap.CompletedAt = &now
+
+	err := f.snapRepo.Create(context.Background(), snap)
+	test.CheckErr(t, "create volume snapshot", err)
+
+	return snap
+}
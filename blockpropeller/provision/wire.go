@@ -10,14 +10,22 @@ var Set = wire.NewSet(
 	NewServerProvisioner,
 	NewDeploymentProvisioner,
 	NewServerDestroyer,
+	NewReservedIPProvisioner,
+	NewVolumeSnapshotProvisioner,
 
 	NewFailureMiddleware,
 	NewStepProvisionServer,
 	NewStepProvisionDeployment,
+	NewStepResizeVolume,
+	NewStepGrowVolume,
+	NewStepUpdateFirewall,
+	NewStepUpdateLabels,
 	ConfigureJobStateMachine,
 
 	NewJobScheduler,
 	NewProvisioner,
 
 	NewWorkerPool,
+	NewHealthMonitor,
+	NewSnapshotScheduler,
 )
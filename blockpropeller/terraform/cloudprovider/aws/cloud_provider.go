@@ -0,0 +1,301 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"blockpropeller.dev/blockpropeller/infrastructure"
+	"blockpropeller.dev/blockpropeller/terraform"
+	"blockpropeller.dev/blockpropeller/terraform/cloudprovider"
+	"blockpropeller.dev/blockpropeller/terraform/resource"
+	"blockpropeller.dev/blockpropeller/terraform/resource/aws"
+	"github.com/pkg/errors"
+)
+
+func init() {
+	cloudprovider.RegisterProvider(infrastructure.ProviderAWS, NewCloudProvider(DefaultSTSURL))
+}
+
+// DefaultSTSURL is the base URL of the global AWS Security Token Service endpoint.
+const DefaultSTSURL = "https://sts.amazonaws.com"
+
+// Formats of the long-term access keys AWS issues. Temporary access keys
+// are refused, since they would also require a session token.
+var (
+	accessKeyIDFormat     = regexp.MustCompile(`^AKIA[A-Z0-9]{16}$`)
+	secretAccessKeyFormat = regexp.MustCompile(`^[A-Za-z0-9/+]{40}$`)
+)
+
+var (
+	catalog = &cloudprovider.Catalog{
+		Currency: "USD",
+		Regions: []*cloudprovider.Region{
+			{ID: "us-east-1", Name: "US East (N. Virginia)"},
+			{ID: "us-east-2", Name: "US East (Ohio)"},
+			{ID: "us-west-1", Name: "US West (N. California)"},
+			{ID: "us-west-2", Name: "US West (Oregon)"},
+			{ID: "eu-central-1", Name: "EU (Frankfurt)"},
+			{ID: "eu-west-1", Name: "EU (Ireland)"},
+			{ID: "eu-west-2", Name: "EU (London)"},
+			{ID: "eu-west-3", Name: "EU (Paris)"},
+			{ID: "eu-north-1", Name: "EU (Stockholm)"},
+			{ID: "ap-northeast-1", Name: "Asia Pacific (Tokyo)"},
+			{ID: "ap-northeast-2", Name: "Asia Pacific (Seoul)"},
+			{ID: "ap-southeast-1", Name: "Asia Pacific (Singapore)"},
+			{ID: "ap-southeast-2", Name: "Asia Pacific (Sydney)"},
+			{ID: "ap-south-1", Name: "Asia Pacific (Mumbai)"},
+			{ID: "ca-central-1", Name: "Canada (Central)"},
+			{ID: "sa-east-1", Name: "South America (Sao Paulo)"},
+		},
+		Images: []*cloudprovider.Image{
+			{ID: imagePattern, Name: "Ubuntu 18.04 LTS"},
+		},
+		// Instance types only have EBS storage, disk size is the size of the root volume.
+		InstanceTypes: []*cloudprovider.InstanceType{
+			{ID: "t3.micro", CPU: 2, MemoryMB: 1024, DiskGB: 8, PriceMonthly: 7.59},
+			{ID: "t3.small", CPU: 2, MemoryMB: 2048, DiskGB: 8, PriceMonthly: 15.18},
+			{ID: "t3.medium", CPU: 2, MemoryMB: 4096, DiskGB: 8, PriceMonthly: 30.37},
+			{ID: "t3.large", CPU: 2, MemoryMB: 8192, DiskGB: 8, PriceMonthly: 60.74},
+			{ID: "c5.large", CPU: 2, MemoryMB: 4096, DiskGB: 8, PriceMonthly: 62.05},
+			{ID: "c5.xlarge", CPU: 4, MemoryMB: 8192, DiskGB: 8, PriceMonthly: 124.10},
+			{ID: "c5.2xlarge", CPU: 8, MemoryMB: 16384, DiskGB: 8, PriceMonthly: 248.20},
+			{ID: "m5.xlarge", CPU: 4, MemoryMB: 16384, DiskGB: 8, PriceMonthly: 140.16},
+		},
+		DefaultRegion: "eu-central-1",
+		DefaultImage:  imagePattern,
+		DefaultInstanceTypes: map[infrastructure.ServerSize]string{
+			infrastructure.ServerSizeTest: "t3.micro",
+			infrastructure.ServerSizeProd: "c5.xlarge",
+		},
+		DefaultVolumeSizes: map[infrastructure.ServerSize]int{
+			infrastructure.ServerSizeTest: 0,
+			infrastructure.ServerSizeProd: 500,
+		},
+		MaxVolumeSizeGB: 16384,
+		VolumeSnapshots: true,
+		ReservedIPs:     true,
+		DNSRecords:      true,
+	}
+	imageOwner     = "099720109477" // Canonical
+	imagePattern   = "ubuntu/images/hvm-ssd/ubuntu-bionic-18.04-amd64-server-*"
+	volumeDevice   = "/dev/sdh"
+	regionVariable = "region"
+
+	// Nitro based instance types expose EBS volumes as NVMe devices,
+	// regardless of the device name requested in the attachment.
+	volumeDeviceCandidates = []string{"/dev/nvme1n1", "/dev/xvdh", volumeDevice}
+)
+
+// Credentials required for authenticating against the AWS API.
+//
+// Credentials are stored inside ProviderSettings as a JSON encoded object.
+// Region is optional, and falls back to the default region if omitted.
+// Servers are created in the credentials region unless they request a region themselves.
+type Credentials struct {
+	AccessKeyID     string `json:"access_key_id"`
+	SecretAccessKey string `json:"secret_access_key"`
+	Region          string `json:"region"`
+}
+
+// ParseCredentials parses the credentials stored inside the ProviderSettings.
+func ParseCredentials(settings *infrastructure.ProviderSettings) (*Credentials, error) {
+	var creds Credentials
+	err := json.Unmarshal([]byte(settings.Credentials), &creds)
+	if err != nil {
+		return nil, errors.Wrap(err, "unmarshal aws credentials")
+	}
+
+	if creds.AccessKeyID == "" || creds.SecretAccessKey == "" {
+		return nil, errors.New("aws credentials require access_key_id and secret_access_key")
+	}
+
+	if creds.Region == "" {
+		creds.Region = catalog.DefaultRegion
+	}
+
+	_, err = catalog.FindRegion(creds.Region)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid aws region")
+	}
+
+	return &creds, nil
+}
+
+// CloudProvider provisions servers as AWS EC2 instances.
+type CloudProvider struct {
+	stsURL string
+	client *http.Client
+}
+
+// NewCloudProvider returns a new CloudProvider instance, validating credentials against the STS endpoint at stsURL.
+func NewCloudProvider(stsURL string) *CloudProvider {
+	return &CloudProvider{
+		stsURL: stsURL,
+		client: &http.Client{Timeout: cloudprovider.DefaultValidationTimeout},
+	}
+}
+
+// Catalog satisfies the CloudProvider interface.
+func (c *CloudProvider) Catalog() *cloudprovider.Catalog {
+	return catalog
+}
+
+// ValidateCredentials satisfies the CloudProvider interface.
+//
+// Access keys are checked for the format AWS issues them in,
+// and then with a signed STS GetCallerIdentity request.
+func (c *CloudProvider) ValidateCredentials(ctx context.Context, settings *infrastructure.ProviderSettings) error {
+	creds, err := ParseCredentials(settings)
+	if err != nil {
+		return cloudprovider.InvalidCredentials(err)
+	}
+
+	if !accessKeyIDFormat.MatchString(creds.AccessKeyID) {
+		return cloudprovider.InvalidCredentials(errors.New("malformed access_key_id"))
+	}
+
+	if !secretAccessKeyFormat.MatchString(creds.SecretAccessKey) {
+		return cloudprovider.InvalidCredentials(errors.New("malformed secret_access_key"))
+	}
+
+	return getCallerIdentity(ctx, c.client, c.stsURL, creds)
+}
+
+// Register satisfies the CloudProvider interface.
+func (c *CloudProvider) Register(workspace *terraform.Workspace, settings *infrastructure.ProviderSettings) error {
+	creds, err := ParseCredentials(settings)
+	if err != nil {
+		return errors.Wrap(err, "parse credentials")
+	}
+
+	workspace.SetVariable(regionVariable, creds.Region)
+	workspace.Add(aws.NewProvider(resource.ToVariable(regionVariable), creds.AccessKeyID, creds.SecretAccessKey))
+
+	return nil
+}
+
+// AddServer satisfies the CloudProvider interface.
+func (c *CloudProvider) AddServer(workspace *terraform.Workspace, srv *infrastructure.Server, firewall []infrastructure.FirewallRule) error {
+	sshKey := srv.SSHKey
+
+	instanceType, err := catalog.ServerInstanceType(srv)
+	if err != nil {
+		return errors.Wrap(err, "get server size")
+	}
+
+	if srv.Region != "" {
+		workspace.SetVariable(regionVariable, srv.Region)
+	}
+
+	volumeSize, err := catalog.ServerVolumeSize(srv)
+	if err != nil {
+		return errors.Wrap(err, "get volume size")
+	}
+
+	ami := aws.NewAMI(srv.Name, imageOwner, imagePattern)
+	keyPair := aws.NewKeyPair(sshKey.Name, sshKey.EncodedPublicKey())
+	var securityGroupRules []aws.SecurityGroupRule
+	for _, rule := range firewall {
+		ipv4, ipv6 := cloudprovider.SplitSources(rule.Sources)
+
+		securityGroupRules = append(securityGroupRules, aws.SecurityGroupRule{
+			Protocol:       rule.Port.Protocol,
+			FromPort:       rule.Port.Number,
+			ToPort:         rule.Port.Number,
+			CIDRBlocks:     ipv4,
+			IPv6CIDRBlocks: ipv6,
+		})
+	}
+
+	securityGroup := aws.NewSecurityGroup(srv.Name, securityGroupRules)
+
+	tags := srv.ResourceTags()
+
+	instance := aws.NewInstance(srv.Name, ami, instanceType, keyPair, []*aws.SecurityGroup{securityGroup}).
+		SetTags(tags)
+	if volumeSize > 0 {
+		instance.SetUserData(cloudprovider.UserDataScript(
+			cloudprovider.MountVolumeCommands(volumeDeviceCandidates...),
+		))
+	}
+
+	workspace.Add(ami)
+	workspace.AddResource(keyPair, securityGroup, instance)
+
+	// Servers with a reserved IP take over its Elastic IP instead of allocating their own.
+	var address resource.Property
+	if srv.ReservedIP != nil {
+		association := aws.NewEIPAssociation(srv.Name, srv.ReservedIP.ProviderID, instance)
+		workspace.AddResource(association)
+
+		address = resource.ToPropSelector(association, "public_ip")
+	} else {
+		eip := aws.NewEIP(srv.Name, instance)
+		workspace.AddResource(eip)
+
+		address = resource.ToPropSelector(eip, "public_ip")
+	}
+
+	zones := make(map[string]*aws.Route53Zone)
+	for _, record := range cloudprovider.ServerDNSRecords(srv) {
+		zone, ok := zones[record.Zone]
+		if !ok {
+			zone = aws.NewRoute53Zone(strings.ReplaceAll(record.Zone, ".", "-"), record.Zone)
+			zones[record.Zone] = zone
+
+			workspace.Add(zone)
+		}
+
+		workspace.AddResource(aws.NewRoute53Record(srv.Name+"-"+record.Slug(), zone, record.Hostname(), address))
+	}
+
+	ipAddressOut := resource.NewOutput("ip-address", address)
+
+	workspace.Add(ipAddressOut)
+
+	if volumeSize > 0 {
+		volume := aws.NewEBSVolume(srv.Name, instance, volumeSize).SetTags(tags)
+		if srv.VolumeSnapshot != nil {
+			volume.SetSnapshot(srv.VolumeSnapshot.ProviderID)
+		}
+
+		volumeAttachment := aws.NewVolumeAttachment(srv.Name, volumeDevice, instance, volume)
+
+		workspace.AddResource(volume, volumeAttachment)
+		workspace.Add(resource.NewOutput("volume-id", resource.ToID(volume)))
+	}
+
+	return nil
+}
+
+// AddReservedIP satisfies the CloudProvider interface.
+//
+// Elastic IPs are identified by their allocation ID.
+func (c *CloudProvider) AddReservedIP(workspace *terraform.Workspace, ip *infrastructure.ReservedIP) error {
+	workspace.SetVariable(regionVariable, ip.Region)
+
+	eip := aws.NewEIP("reserved-ip", nil)
+
+	workspace.AddResource(eip)
+	workspace.Add(
+		resource.NewOutput("ip-address", resource.ToPropSelector(eip, "public_ip")),
+		resource.NewOutput("reserved-ip-id", resource.ToID(eip)),
+	)
+
+	return nil
+}
+
+// AddVolumeSnapshot satisfies the CloudProvider interface.
+func (c *CloudProvider) AddVolumeSnapshot(workspace *terraform.Workspace, snap *infrastructure.VolumeSnapshot, volumeID string) error {
+	workspace.SetVariable(regionVariable, snap.Region)
+
+	snapshot := aws.NewEBSSnapshot("snapshot-"+snap.ID.String(), volumeID)
+
+	workspace.AddResource(snapshot)
+	workspace.Add(resource.NewOutput("snapshot-id", resource.ToID(snapshot)))
+
+	return nil
+}
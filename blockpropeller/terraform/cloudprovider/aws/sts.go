@@ -0,0 +1,125 @@
+package aws
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"blockpropeller.dev/blockpropeller/terraform/cloudprovider"
+	"github.com/pkg/errors"
+)
+
+const (
+	// The global STS endpoint signs requests for us-east-1, whatever region the credentials are used in.
+	stsRegion  = "us-east-1"
+	stsService = "sts"
+
+	getCallerIdentityBody = "Action=GetCallerIdentity&Version=2011-06-15"
+)
+
+// getCallerIdentity calls the STS GetCallerIdentity action, which any valid access key may call.
+//
+// Requests denied by STS are reported as ErrInvalidCredentials.
+func getCallerIdentity(ctx context.Context, client *http.Client, stsURL string, creds *Credentials) error {
+	req, err := http.NewRequest(http.MethodPost, stsURL+"/", strings.NewReader(getCallerIdentityBody))
+	if err != nil {
+		return errors.Wrap(err, "create request")
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+
+	signRequest(req, getCallerIdentityBody, creds, stsRegion, stsService, time.Now())
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "send request")
+	}
+	defer resp.Body.Close()
+
+	// Drain the body so the connection can be reused.
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return cloudprovider.InvalidCredentials(errors.Errorf("access key rejected: %s", resp.Status))
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		return errors.Errorf("unexpected response: %s", resp.Status)
+	}
+
+	return nil
+}
+
+// signRequest signs the request with AWS Signature Version 4,
+// covering its host, date and content type headers.
+func signRequest(req *http.Request, body string, creds *Credentials, region, service string, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+
+	req.Header.Set("X-Amz-Date", amzDate)
+
+	var headers []string
+	if contentType := req.Header.Get("Content-Type"); contentType != "" {
+		headers = append(headers, "content-type:"+contentType)
+	}
+	headers = append(headers, "host:"+req.URL.Host, "x-amz-date:"+amzDate)
+
+	var names []string
+	for _, header := range headers {
+		names = append(names, header[:strings.Index(header, ":")])
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		canonicalPath(req.URL),
+		req.URL.RawQuery,
+		strings.Join(headers, "\n") + "\n",
+		signedHeaders,
+		hashHex(body),
+	}, "\n")
+
+	scope := date + "/" + region + "/" + service + "/aws4_request"
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		hashHex(canonicalRequest),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+creds.SecretAccessKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+creds.AccessKeyID+"/"+scope+
+		", SignedHeaders="+signedHeaders+", Signature="+signature)
+}
+
+func canonicalPath(u *url.URL) string {
+	if u.EscapedPath() == "" {
+		return "/"
+	}
+
+	return u.EscapedPath()
+}
+
+func hashHex(data string) string {
+	hash := sha256.Sum256([]byte(data))
+
+	return hex.EncodeToString(hash[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	_, _ = mac.Write([]byte(data))
+
+	return mac.Sum(nil)
+}
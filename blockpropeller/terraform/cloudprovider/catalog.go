@@ -0,0 +1,225 @@
+package cloudprovider
+
+import (
+	"blockpropeller.dev/blockpropeller/infrastructure"
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrUnknownRegion is returned for regions not offered by the CloudProvider.
+	ErrUnknownRegion = errors.New("unknown region")
+	// ErrUnknownInstanceType is returned for instance types not offered by the CloudProvider.
+	ErrUnknownInstanceType = errors.New("unknown instance type")
+	// ErrVolumeResizeNotSupported is returned for CloudProviders without resizable data volumes.
+	ErrVolumeResizeNotSupported = errors.New("volume resizing not supported by provider")
+	// ErrVolumeSnapshotsNotSupported is returned for CloudProviders unable to snapshot data volumes.
+	ErrVolumeSnapshotsNotSupported = errors.New("volume snapshots not supported by provider")
+)
+
+// Region is a location where a CloudProvider is able to run servers.
+type Region struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// Image is an operating system image servers are created from.
+type Image struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// InstanceType describes the resources of a server offered by a CloudProvider.
+//
+// Prices are indicative monthly on-demand prices, without attached volumes.
+type InstanceType struct {
+	ID           string  `json:"id"`
+	CPU          int     `json:"cpu"`
+	MemoryMB     int     `json:"memory_mb"`
+	DiskGB       int     `json:"disk_gb"`
+	PriceMonthly float64 `json:"price_monthly"`
+}
+
+// Catalog lists the regions, images and instance types available through a CloudProvider.
+//
+// Defaults are used for servers that do not request a specific region or instance type.
+type Catalog struct {
+	Currency string `json:"currency"`
+
+	Regions       []*Region       `json:"regions"`
+	Images        []*Image        `json:"images"`
+	InstanceTypes []*InstanceType `json:"instance_types"`
+
+	DefaultRegion        string                               `json:"default_region"`
+	DefaultImage         string                               `json:"default_image"`
+	DefaultInstanceTypes map[infrastructure.ServerSize]string `json:"default_instance_types"`
+
+	// DefaultVolumeSizes in GB of the data volume attached to servers of each size, zero for none.
+	DefaultVolumeSizes map[infrastructure.ServerSize]int `json:"default_volume_sizes,omitempty"`
+	// MaxVolumeSizeGB is the largest size a data volume can be resized to, zero if volumes cannot be resized.
+	MaxVolumeSizeGB int `json:"max_volume_size_gb"`
+	// VolumeSnapshots reports whether data volumes can be snapshotted, and servers created from the snapshots.
+	VolumeSnapshots bool `json:"volume_snapshots"`
+
+	// ReservedIPs reports whether reserved IP addresses can be attached to servers.
+	ReservedIPs bool `json:"reserved_ips"`
+	// DNSRecords reports whether hostnames of deployments can be managed as DNS records.
+	DNSRecords bool `json:"dns_records"`
+}
+
+// FindRegion returns the Region with the provided ID.
+func (c *Catalog) FindRegion(id string) (*Region, error) {
+	for _, region := range c.Regions {
+		if region.ID == id {
+			return region, nil
+		}
+	}
+
+	return nil, errors.Wrap(ErrUnknownRegion, id)
+}
+
+// FindInstanceType returns the InstanceType with the provided ID.
+func (c *Catalog) FindInstanceType(id string) (*InstanceType, error) {
+	for _, instanceType := range c.InstanceTypes {
+		if instanceType.ID == id {
+			return instanceType, nil
+		}
+	}
+
+	return nil, errors.Wrap(ErrUnknownInstanceType, id)
+}
+
+// ServerRegion returns the region requested by the Server, or the default region.
+func (c *Catalog) ServerRegion(srv *infrastructure.Server) string {
+	if srv.Region != "" {
+		return srv.Region
+	}
+
+	return c.DefaultRegion
+}
+
+// ServerInstanceType returns the instance type requested by the Server,
+// or the default instance type for the Server size.
+func (c *Catalog) ServerInstanceType(srv *infrastructure.Server) (string, error) {
+	if srv.InstanceType != "" {
+		return srv.InstanceType, nil
+	}
+
+	instanceType, ok := c.DefaultInstanceTypes[srv.Size]
+	if !ok {
+		return "", errors.Errorf("invalid server size: %s", srv.Size)
+	}
+
+	return instanceType, nil
+}
+
+// ServerVolumeSize returns the data volume size in GB of the Server,
+// or the default volume size for the Server size.
+//
+// CloudProviders without data volumes always return zero.
+func (c *Catalog) ServerVolumeSize(srv *infrastructure.Server) (int, error) {
+	if srv.VolumeSize > 0 {
+		return srv.VolumeSize, nil
+	}
+
+	if c.DefaultVolumeSizes == nil {
+		return 0, nil
+	}
+
+	volumeSize, ok := c.DefaultVolumeSizes[srv.Size]
+	if !ok {
+		return 0, errors.Errorf("invalid server size: %s", srv.Size)
+	}
+
+	return volumeSize, nil
+}
+
+// ValidateVolumeSize checks that a data volume can be resized to the provided size in GB.
+func (c *Catalog) ValidateVolumeSize(size int) error {
+	if c.MaxVolumeSizeGB == 0 {
+		return ErrVolumeResizeNotSupported
+	}
+
+	if size > c.MaxVolumeSizeGB {
+		return errors.Errorf("volume size %d GB exceeds the maximum of %d GB", size, c.MaxVolumeSizeGB)
+	}
+
+	return nil
+}
+
+// ValidateVolumeSnapshot checks that the data volume of the Server can be created from its VolumeSnapshot.
+//
+// Volumes can only be created from snapshots taken in the region of the Server.
+func (c *Catalog) ValidateVolumeSnapshot(srv *infrastructure.Server) error {
+	if srv.VolumeSnapshotID == infrastructure.NilVolumeSnapshotID {
+		return nil
+	}
+
+	if !c.VolumeSnapshots {
+		return ErrVolumeSnapshotsNotSupported
+	}
+
+	snap := srv.VolumeSnapshot
+	if snap == nil {
+		return nil
+	}
+
+	if !snap.IsAvailable() {
+		return errors.Errorf("volume snapshot %s is not available", snap.ID)
+	}
+
+	if snap.Region != c.ServerRegion(srv) {
+		return errors.Errorf("volume snapshot in region %s cannot be restored to a server in region %s",
+			snap.Region, c.ServerRegion(srv))
+	}
+
+	return nil
+}
+
+// Validate checks that the region, instance type, data volume and network features requested by the Server are offered.
+func (c *Catalog) Validate(srv *infrastructure.Server) error {
+	if srv.Region != "" {
+		_, err := c.FindRegion(srv.Region)
+		if err != nil {
+			return err
+		}
+	}
+
+	instanceType, err := c.ServerInstanceType(srv)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.FindInstanceType(instanceType)
+	if err != nil {
+		return err
+	}
+
+	err = c.ValidateVolumeSnapshot(srv)
+	if err != nil {
+		return err
+	}
+
+	return c.ValidateNetwork(srv)
+}
+
+// ValidateNetwork checks that the reserved IP address and DNS records requested by the Server are supported.
+//
+// A reserved IP address can only be attached to a Server in the region it was allocated in.
+func (c *Catalog) ValidateNetwork(srv *infrastructure.Server) error {
+	if srv.ReservedIPID != infrastructure.NilReservedIPID {
+		if !c.ReservedIPs {
+			return ErrReservedIPsNotSupported
+		}
+
+		if srv.ReservedIP != nil && srv.ReservedIP.Region != c.ServerRegion(srv) {
+			return errors.Errorf("reserved ip in region %s cannot be attached to a server in region %s",
+				srv.ReservedIP.Region, c.ServerRegion(srv))
+		}
+	}
+
+	if len(ServerDNSRecords(srv)) > 0 && !c.DNSRecords {
+		return ErrDNSRecordsNotSupported
+	}
+
+	return nil
+}
@@ -0,0 +1,240 @@
+package cloudprovider_test
+
+import (
+	"testing"
+
+	"blockpropeller.dev/blockpropeller/infrastructure"
+	"blockpropeller.dev/blockpropeller/terraform/cloudprovider"
+	"blockpropeller.dev/blockpropeller/terraform/cloudprovider/local"
+	"blockpropeller.dev/lib/test"
+	"github.com/pkg/errors"
+
+	_ "blockpropeller.dev/blockpropeller/terraform/cloudprovider/aws"
+	_ "blockpropeller.dev/blockpropeller/terraform/cloudprovider/digitalocean"
+	_ "blockpropeller.dev/blockpropeller/terraform/cloudprovider/google"
+	_ "blockpropeller.dev/blockpropeller/terraform/cloudprovider/hcloud"
+)
+
+func TestCatalogDefaultsAreOffered(t *testing.T) {
+	local.Register("unix:///var/run/docker.sock")
+
+	providers := []infrastructure.ProviderType{
+		infrastructure.ProviderDigitalOcean,
+		infrastructure.ProviderAWS,
+		infrastructure.ProviderHetzner,
+		infrastructure.ProviderGoogleCloud,
+		infrastructure.ProviderLocal,
+	}
+	for _, typ := range providers {
+		t.Run(typ.String(), func(t *testing.T) {
+			provider, err := cloudprovider.GetProvider(typ)
+			test.CheckErr(t, "GetProvider()", err)
+
+			catalog := provider.Catalog()
+
+			_, err = catalog.FindRegion(catalog.DefaultRegion)
+			test.CheckErr(t, "default region", err)
+
+			var foundImage bool
+			for _, image := range catalog.Images {
+				foundImage = foundImage || image.ID == catalog.DefaultImage
+			}
+			test.AssertBoolEqual(t, "default image offered", foundImage, true)
+
+			for _, size := range infrastructure.ValidServerSizes {
+				instanceType, ok := catalog.DefaultInstanceTypes[size]
+				test.AssertBoolEqual(t, "default instance type for "+size.String(), ok, true)
+
+				_, err = catalog.FindInstanceType(instanceType)
+				test.CheckErr(t, "default instance type for "+size.String(), err)
+			}
+		})
+	}
+}
+
+func TestCatalogValidate(t *testing.T) {
+	catalog := &cloudprovider.Catalog{
+		Regions:       []*cloudprovider.Region{{ID: "fra1"}, {ID: "ams3"}},
+		InstanceTypes: []*cloudprovider.InstanceType{{ID: "small"}, {ID: "large"}},
+		DefaultRegion: "fra1",
+		DefaultInstanceTypes: map[infrastructure.ServerSize]string{
+			infrastructure.ServerSizeTest: "small",
+		},
+	}
+
+	tests := []struct {
+		name             string
+		size             infrastructure.ServerSize
+		region           string
+		instanceType     string
+		wantRegion       string
+		wantInstanceType string
+		wantErr          error
+	}{
+		{"defaults", infrastructure.ServerSizeTest, "", "", "fra1", "small", nil},
+		{"explicit", infrastructure.ServerSizeTest, "ams3", "large", "ams3", "large", nil},
+		{"explicit instance type without default", infrastructure.ServerSizeProd, "", "large", "fra1", "large", nil},
+		{"unknown region", infrastructure.ServerSizeTest, "nyc1", "", "", "", cloudprovider.ErrUnknownRegion},
+		{"unknown instance type", infrastructure.ServerSizeTest, "", "huge", "", "", cloudprovider.ErrUnknownInstanceType},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := &infrastructure.Server{Size: tt.size, Region: tt.region, InstanceType: tt.instanceType}
+
+			err := catalog.Validate(srv)
+			if tt.wantErr != nil {
+				if errors.Cause(err) != tt.wantErr {
+					t.Fatalf("Validate(): got %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			test.CheckErr(t, "Validate()", err)
+
+			instanceType, err := catalog.ServerInstanceType(srv)
+			test.CheckErr(t, "ServerInstanceType()", err)
+
+			test.AssertStringsEqual(t, "ServerRegion()", catalog.ServerRegion(srv), tt.wantRegion)
+			test.AssertStringsEqual(t, "ServerInstanceType()", instanceType, tt.wantInstanceType)
+		})
+	}
+}
+
+func TestCatalogValidateNetwork(t *testing.T) {
+	settings := infrastructure.NewProviderSettings("", "", infrastructure.ProviderDigitalOcean, "token")
+
+	withReservedIP := func(region string) func(srv *infrastructure.Server) {
+		return func(srv *infrastructure.Server) {
+			srv.AttachReservedIP(infrastructure.NewReservedIP("", settings, region))
+		}
+	}
+	withHostname := func(srv *infrastructure.Server) {
+		deployment := infrastructure.NewDeployment("", nil)
+		err := deployment.SetHostname("nodes.example.com", "fullnode-1")
+		test.CheckErr(t, "set hostname", err)
+
+		srv.AddDeployment(deployment)
+	}
+
+	tests := []struct {
+		name      string
+		supported bool
+		setup     func(srv *infrastructure.Server)
+		wantErr   bool
+		wantCause error
+	}{
+		{"no network features", false, func(srv *infrastructure.Server) {}, false, nil},
+		{"reserved ip", true, withReservedIP("fra1"), false, nil},
+		{"reserved ip in another region", true, withReservedIP("ams3"), true, nil},
+		{"reserved ip not supported", false, withReservedIP("fra1"), true, cloudprovider.ErrReservedIPsNotSupported},
+		{"dns records", true, withHostname, false, nil},
+		{"dns records not supported", false, withHostname, true, cloudprovider.ErrDNSRecordsNotSupported},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			catalog := &cloudprovider.Catalog{
+				DefaultRegion: "fra1",
+				ReservedIPs:   tt.supported,
+				DNSRecords:    tt.supported,
+			}
+
+			srv := &infrastructure.Server{}
+			tt.setup(srv)
+
+			err := catalog.ValidateNetwork(srv)
+			if !tt.wantErr {
+				test.CheckErr(t, "ValidateNetwork()", err)
+				return
+			}
+
+			test.CheckErrExists(t, "ValidateNetwork()", err)
+			if tt.wantCause != nil && errors.Cause(err) != tt.wantCause {
+				t.Fatalf("ValidateNetwork(): got %v, want %v", err, tt.wantCause)
+			}
+		})
+	}
+}
+
+func TestCatalogVolumeSize(t *testing.T) {
+	catalog := &cloudprovider.Catalog{
+		DefaultVolumeSizes: map[infrastructure.ServerSize]int{
+			infrastructure.ServerSizeTest: 0,
+			infrastructure.ServerSizeProd: 500,
+		},
+		MaxVolumeSizeGB: 1000,
+	}
+
+	srv := &infrastructure.Server{Size: infrastructure.ServerSizeProd}
+
+	size, err := catalog.ServerVolumeSize(srv)
+	test.CheckErr(t, "ServerVolumeSize() default", err)
+	test.AssertIntsEqual(t, "ServerVolumeSize() default", size, 500)
+
+	srv.VolumeSize = 750
+
+	size, err = catalog.ServerVolumeSize(srv)
+	test.CheckErr(t, "ServerVolumeSize() resized", err)
+	test.AssertIntsEqual(t, "ServerVolumeSize() resized", size, 750)
+
+	_, err = catalog.ServerVolumeSize(&infrastructure.Server{Size: "huge"})
+	test.CheckErrExists(t, "ServerVolumeSize() invalid size", err)
+
+	size, err = (&cloudprovider.Catalog{}).ServerVolumeSize(&infrastructure.Server{Size: infrastructure.ServerSizeProd})
+	test.CheckErr(t, "ServerVolumeSize() without volumes", err)
+	test.AssertIntsEqual(t, "ServerVolumeSize() without volumes", size, 0)
+
+	test.CheckErr(t, "ValidateVolumeSize()", catalog.ValidateVolumeSize(1000))
+	test.CheckErrExists(t, "ValidateVolumeSize() over maximum", catalog.ValidateVolumeSize(1001))
+
+	err = (&cloudprovider.Catalog{}).ValidateVolumeSize(100)
+	if errors.Cause(err) != cloudprovider.ErrVolumeResizeNotSupported {
+		t.Errorf("ValidateVolumeSize() without volumes = %v, want %v", err, cloudprovider.ErrVolumeResizeNotSupported)
+	}
+}
+
+func TestCatalogValidateVolumeSnapshot(t *testing.T) {
+	snapshotIn := func(region string, state infrastructure.VolumeSnapshotState) func(srv *infrastructure.Server) {
+		return func(srv *infrastructure.Server) {
+			snap := infrastructure.NewVolumeSnapshot(&infrastructure.Server{VolumeSize: 500}, region, false)
+			snap.State = state
+			snap.ProviderID = "12345"
+
+			srv.RestoreVolumeSnapshot(snap)
+		}
+	}
+
+	tests := []struct {
+		name      string
+		supported bool
+		setup     func(srv *infrastructure.Server)
+		wantErr   bool
+		wantCause error
+	}{
+		{"no snapshot", false, func(srv *infrastructure.Server) {}, false, nil},
+		{"snapshot", true, snapshotIn("fra1", infrastructure.VolumeSnapshotStateOk), false, nil},
+		{"snapshot in another region", true, snapshotIn("ams3", infrastructure.VolumeSnapshotStateOk), true, nil},
+		{"snapshot not taken", true, snapshotIn("fra1", infrastructure.VolumeSnapshotStateRequested), true, nil},
+		{"snapshots not supported", false, snapshotIn("fra1", infrastructure.VolumeSnapshotStateOk), true, cloudprovider.ErrVolumeSnapshotsNotSupported},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			catalog := &cloudprovider.Catalog{
+				DefaultRegion:   "fra1",
+				VolumeSnapshots: tt.supported,
+			}
+
+			srv := &infrastructure.Server{}
+			tt.setup(srv)
+
+			err := catalog.ValidateVolumeSnapshot(srv)
+			if !tt.wantErr {
+				test.CheckErr(t, "ValidateVolumeSnapshot()", err)
+				return
+			}
+
+			test.CheckErrExists(t, "ValidateVolumeSnapshot()", err)
+			if tt.wantCause != nil && errors.Cause(err) != tt.wantCause {
+				t.Fatalf("ValidateVolumeSnapshot(): got %v, want %v", err, tt.wantCause)
+			}
+		})
+	}
+}
@@ -1,6 +1,8 @@
 package cloudprovider
 
 import (
+	"context"
+
 	"blockpropeller.dev/blockpropeller/infrastructure"
 	"blockpropeller.dev/blockpropeller/terraform"
 	"github.com/pkg/errors"
@@ -19,8 +21,12 @@ func init() {
 // providing a common interface of provisioning infrastructure over all of them
 // under a single interface.
 type CloudProvider interface {
+	Catalog() *Catalog
+	ValidateCredentials(ctx context.Context, settings *infrastructure.ProviderSettings) error
 	Register(workspace *terraform.Workspace, settings *infrastructure.ProviderSettings) error
-	AddServer(workspace *terraform.Workspace, srv *infrastructure.Server) error
+	AddServer(workspace *terraform.Workspace, srv *infrastructure.Server, firewall []infrastructure.FirewallRule) error
+	AddReservedIP(workspace *terraform.Workspace, ip *infrastructure.ReservedIP) error
+	AddVolumeSnapshot(workspace *terraform.Workspace, snap *infrastructure.VolumeSnapshot, volumeID string) error
 }
 
 // RegisterProvider is used to register a new type of cloud provider.
@@ -40,3 +46,10 @@ func GetProvider(typ infrastructure.ProviderType) (CloudProvider, error) {
 
 	return spec, nil
 }
+
+// IsRegistered reports whether a CloudProvider has been registered for the requested type.
+func IsRegistered(typ infrastructure.ProviderType) bool {
+	_, ok := registeredCloudProviders[typ]
+
+	return ok
+}
@@ -0,0 +1,63 @@
+package cloudprovider
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultValidationTimeout is the time allowed for a CloudProvider API to answer a credentials check.
+const DefaultValidationTimeout = 10 * time.Second
+
+// ErrInvalidCredentials is returned when ProviderSettings credentials are malformed or rejected by the CloudProvider.
+var ErrInvalidCredentials = errors.New("invalid credentials")
+
+// InvalidCredentials marks the provided reason as an ErrInvalidCredentials error.
+func InvalidCredentials(reason error) error {
+	return errors.Wrap(ErrInvalidCredentials, reason.Error())
+}
+
+// IsInvalidCredentials checks whether the error means the credentials are invalid,
+// as opposed to the CloudProvider being unreachable.
+func IsInvalidCredentials(err error) bool {
+	return errors.Cause(err) == ErrInvalidCredentials
+}
+
+// ValidateBearerToken performs an authenticated GET request against a CloudProvider API
+// using the token as a bearer token.
+//
+// Unauthorized and forbidden responses are reported as ErrInvalidCredentials.
+func ValidateBearerToken(ctx context.Context, client *http.Client, url string, token string) error {
+	if token == "" {
+		return InvalidCredentials(errors.New("empty token"))
+	}
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return errors.Wrap(err, "create request")
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "send request")
+	}
+	defer resp.Body.Close()
+
+	// Drain the body so the connection can be reused.
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return InvalidCredentials(errors.Errorf("token rejected: %s", resp.Status))
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		return errors.Errorf("unexpected response: %s", resp.Status)
+	}
+
+	return nil
+}
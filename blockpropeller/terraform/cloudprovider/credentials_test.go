@@ -0,0 +1,49 @@
+package cloudprovider_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"blockpropeller.dev/blockpropeller/terraform/cloudprovider"
+	"blockpropeller.dev/lib/test"
+)
+
+func TestValidateBearerToken(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Header.Get("Authorization") {
+		case "Bearer valid":
+			w.WriteHeader(http.StatusOK)
+		case "Bearer broken":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr bool
+		invalid bool
+	}{
+		{"valid token", "valid", false, false},
+		{"rejected token", "invalid", true, true},
+		{"empty token", "", true, true},
+		{"provider error", "broken", true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := cloudprovider.ValidateBearerToken(context.Background(), srv.Client(), srv.URL, tt.token)
+			if !tt.wantErr {
+				test.CheckErr(t, "ValidateBearerToken()", err)
+				return
+			}
+
+			test.CheckErrExists(t, "ValidateBearerToken()", err)
+			test.AssertBoolEqual(t, "IsInvalidCredentials()", cloudprovider.IsInvalidCredentials(err), tt.invalid)
+		})
+	}
+}
@@ -1,6 +1,10 @@
 package digitalocean
 
 import (
+	"context"
+	"net/http"
+	"strconv"
+
 	"blockpropeller.dev/blockpropeller/infrastructure"
 	"blockpropeller.dev/blockpropeller/terraform"
 	"blockpropeller.dev/blockpropeller/terraform/cloudprovider"
@@ -11,24 +15,79 @@ import (
 )
 
 func init() {
-	cloudprovider.RegisterProvider(infrastructure.ProviderDigitalOcean, &CloudProvider{})
+	cloudprovider.RegisterProvider(infrastructure.ProviderDigitalOcean, NewCloudProvider(DefaultAPIURL))
 }
 
+// DefaultAPIURL is the base URL of the DigitalOcean API.
+const DefaultAPIURL = "https://api.digitalocean.com"
+
 var (
-	image         = "ubuntu-18-04-x64"
-	region        = "fra1"
-	serverSizeMap = map[infrastructure.ServerSize]string{
-		infrastructure.ServerSizeTest: "s-1vcpu-1gb",
-		infrastructure.ServerSizeProd: "s-4vcpu-8gb",
-	}
-	volumeSizeMap = map[infrastructure.ServerSize]int{
-		infrastructure.ServerSizeTest: 0,
-		infrastructure.ServerSizeProd: 500,
+	catalog = &cloudprovider.Catalog{
+		Currency: "USD",
+		Regions: []*cloudprovider.Region{
+			{ID: "nyc1", Name: "New York 1"},
+			{ID: "nyc3", Name: "New York 3"},
+			{ID: "sfo2", Name: "San Francisco 2"},
+			{ID: "tor1", Name: "Toronto 1"},
+			{ID: "lon1", Name: "London 1"},
+			{ID: "ams3", Name: "Amsterdam 3"},
+			{ID: "fra1", Name: "Frankfurt 1"},
+			{ID: "sgp1", Name: "Singapore 1"},
+			{ID: "blr1", Name: "Bangalore 1"},
+		},
+		Images: []*cloudprovider.Image{
+			{ID: "ubuntu-18-04-x64", Name: "Ubuntu 18.04 x64"},
+		},
+		InstanceTypes: []*cloudprovider.InstanceType{
+			{ID: "s-1vcpu-1gb", CPU: 1, MemoryMB: 1024, DiskGB: 25, PriceMonthly: 5},
+			{ID: "s-1vcpu-2gb", CPU: 1, MemoryMB: 2048, DiskGB: 50, PriceMonthly: 10},
+			{ID: "s-2vcpu-2gb", CPU: 2, MemoryMB: 2048, DiskGB: 60, PriceMonthly: 15},
+			{ID: "s-2vcpu-4gb", CPU: 2, MemoryMB: 4096, DiskGB: 80, PriceMonthly: 20},
+			{ID: "s-4vcpu-8gb", CPU: 4, MemoryMB: 8192, DiskGB: 160, PriceMonthly: 40},
+			{ID: "s-6vcpu-16gb", CPU: 6, MemoryMB: 16384, DiskGB: 320, PriceMonthly: 80},
+			{ID: "s-8vcpu-32gb", CPU: 8, MemoryMB: 32768, DiskGB: 640, PriceMonthly: 160},
+		},
+		DefaultRegion: "fra1",
+		DefaultImage:  "ubuntu-18-04-x64",
+		DefaultInstanceTypes: map[infrastructure.ServerSize]string{
+			infrastructure.ServerSizeTest: "s-1vcpu-1gb",
+			infrastructure.ServerSizeProd: "s-4vcpu-8gb",
+		},
+		DefaultVolumeSizes: map[infrastructure.ServerSize]int{
+			infrastructure.ServerSizeTest: 0,
+			infrastructure.ServerSizeProd: 500,
+		},
+		MaxVolumeSizeGB: 16384,
+		VolumeSnapshots: true,
+		ReservedIPs:     true,
+		DNSRecords:      true,
 	}
 )
 
 // CloudProvider is a terraform
 type CloudProvider struct {
+	apiURL string
+	client *http.Client
+}
+
+// NewCloudProvider returns a new CloudProvider instance, validating credentials against the API at apiURL.
+func NewCloudProvider(apiURL string) *CloudProvider {
+	return &CloudProvider{
+		apiURL: apiURL,
+		client: &http.Client{Timeout: cloudprovider.DefaultValidationTimeout},
+	}
+}
+
+// Catalog satisfies the CloudProvider interface.
+func (c *CloudProvider) Catalog() *cloudprovider.Catalog {
+	return catalog
+}
+
+// ValidateCredentials satisfies the CloudProvider interface.
+//
+// The API token is checked with a cheap authenticated read request.
+func (c *CloudProvider) ValidateCredentials(ctx context.Context, settings *infrastructure.ProviderSettings) error {
+	return cloudprovider.ValidateBearerToken(ctx, c.client, c.apiURL+"/v2/account", settings.Credentials)
 }
 
 // Register satisfies the CloudProvider interface.
@@ -39,7 +98,7 @@ func (c *CloudProvider) Register(workspace *terraform.Workspace, settings *infra
 }
 
 // AddServer satisfies the CloudProvider interface.
-func (c *CloudProvider) AddServer(workspace *terraform.Workspace, srv *infrastructure.Server) error {
+func (c *CloudProvider) AddServer(workspace *terraform.Workspace, srv *infrastructure.Server, firewall []infrastructure.FirewallRule) error {
 	sshKey := srv.SSHKey
 
 	doSSHKey := digitalocean.NewSSHKey(sshKey.Name, sshKey.EncodedPublicKey())
@@ -48,49 +107,89 @@ func (c *CloudProvider) AddServer(workspace *terraform.Workspace, srv *infrastru
 		"priv": sshKey.EncodedPrivateKey(),
 	})
 
-	size, err := c.getDropletSize(srv.Size)
+	size, err := catalog.ServerInstanceType(srv)
 	if err != nil {
 		return errors.Wrap(err, "get server size")
 	}
 
-	doDroplet := digitalocean.NewDroplet(srv.Name, image, region, size, []*digitalocean.SSHKey{doSSHKey})
+	region := catalog.ServerRegion(srv)
+
+	tags := srv.ResourceTags().Tags()
+
+	doDroplet := digitalocean.NewDroplet(srv.Name, catalog.DefaultImage, region, size, []*digitalocean.SSHKey{doSSHKey}).
+		SetTags(tags)
+
+	var doFirewallRules []digitalocean.FirewallRule
+	for _, rule := range firewall {
+		doFirewallRules = append(doFirewallRules, digitalocean.FirewallRule{
+			Protocol:        rule.Port.Protocol,
+			PortRange:       strconv.Itoa(rule.Port.Number),
+			SourceAddresses: rule.Sources,
+		})
+	}
 
-	workspace.AddResource(doSSHKey, doDroplet)
+	doFirewall := digitalocean.NewFirewall(srv.Name, []*digitalocean.Droplet{doDroplet}, doFirewallRules)
 
-	ipAddressOut := resource.NewOutput("ip-address", resource.ToPropSelector(doDroplet, "ipv4_address"))
+	workspace.AddResource(doSSHKey, doDroplet, doFirewall)
+
+	address := resource.ToPropSelector(doDroplet, "ipv4_address")
+	if srv.ReservedIP != nil {
+		doAssignment := digitalocean.NewFloatingIPAssignment(srv.Name, srv.ReservedIP.Address, doDroplet)
+		workspace.AddResource(doAssignment)
+
+		address = resource.ToPropSelector(doAssignment, "ip_address")
+	}
+
+	for _, record := range cloudprovider.ServerDNSRecords(srv) {
+		workspace.AddResource(digitalocean.NewRecord(srv.Name+"-"+record.Slug(), record.Zone, record.Name, address))
+	}
+
+	ipAddressOut := resource.NewOutput("ip-address", address)
 
 	workspace.Add(ipAddressOut)
 
 	// Add volume if necessary.
-	volumeSize, err := c.getVolumeSize(srv.Size)
+	volumeSize, err := catalog.ServerVolumeSize(srv)
 	if err != nil {
 		return errors.Wrap(err, "get volume size")
 	}
 
 	if volumeSize > 0 {
-		doVolume := digitalocean.NewVolume(srv.Name, region, volumeSize)
+		doVolume := digitalocean.NewVolume(srv.Name, region, volumeSize).SetTags(tags)
+		if srv.VolumeSnapshot != nil {
+			doVolume.SetSnapshot(srv.VolumeSnapshot.ProviderID)
+		}
+
 		doVolumeAttachment := digitalocean.NewVolumeAttachment(srv.Name, doDroplet, doVolume)
 
 		workspace.AddResource(doVolume, doVolumeAttachment)
+		workspace.Add(resource.NewOutput("volume-id", resource.ToID(doVolume)))
 	}
 
 	return nil
 }
 
-func (c *CloudProvider) getDropletSize(serverSize infrastructure.ServerSize) (string, error) {
-	size, ok := serverSizeMap[serverSize]
-	if !ok {
-		return "", errors.Errorf("invalid server size: %s", serverSize)
-	}
+// AddReservedIP satisfies the CloudProvider interface.
+//
+// Floating IPs are identified by their address.
+func (c *CloudProvider) AddReservedIP(workspace *terraform.Workspace, ip *infrastructure.ReservedIP) error {
+	doFloatingIP := digitalocean.NewFloatingIP("reserved-ip", ip.Region)
+
+	workspace.AddResource(doFloatingIP)
+	workspace.Add(
+		resource.NewOutput("ip-address", resource.ToPropSelector(doFloatingIP, "ip_address")),
+		resource.NewOutput("reserved-ip-id", resource.ToPropSelector(doFloatingIP, "ip_address")),
+	)
 
-	return size, nil
+	return nil
 }
 
-func (c *CloudProvider) getVolumeSize(serverSize infrastructure.ServerSize) (int, error) {
-	volumeSize, ok := volumeSizeMap[serverSize]
-	if !ok {
-		return 0, errors.Errorf("invalid server size: %s", serverSize)
-	}
+// AddVolumeSnapshot satisfies the CloudProvider interface.
+func (c *CloudProvider) AddVolumeSnapshot(workspace *terraform.Workspace, snap *infrastructure.VolumeSnapshot, volumeID string) error {
+	doSnapshot := digitalocean.NewVolumeSnapshot("snapshot-"+snap.ID.String(), volumeID)
+
+	workspace.AddResource(doSnapshot)
+	workspace.Add(resource.NewOutput("snapshot-id", resource.ToID(doSnapshot)))
 
-	return volumeSize, nil
+	return nil
 }
@@ -0,0 +1,244 @@
+package digitalocean_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"blockpropeller.dev/blockpropeller/infrastructure"
+	"blockpropeller.dev/blockpropeller/terraform"
+	"blockpropeller.dev/blockpropeller/terraform/cloudprovider"
+	"blockpropeller.dev/blockpropeller/terraform/cloudprovider/digitalocean"
+	"blockpropeller.dev/lib/test"
+)
+
+func TestCloudProviderValidateCredentials(t *testing.T) {
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/account" || r.Header.Get("Authorization") != "Bearer valid-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer api.Close()
+
+	provider := digitalocean.NewCloudProvider(api.URL)
+
+	settings := infrastructure.NewProviderSettings("", "", infrastructure.ProviderDigitalOcean, "valid-token")
+	err := provider.ValidateCredentials(context.Background(), settings)
+	test.CheckErr(t, "validate valid token", err)
+
+	settings = infrastructure.NewProviderSettings("", "", infrastructure.ProviderDigitalOcean, "revoked-token")
+	err = provider.ValidateCredentials(context.Background(), settings)
+	test.AssertBoolEqual(t, "revoked token is invalid", cloudprovider.IsInvalidCredentials(err), true)
+}
+
+func TestCloudProviderAddServerFirewall(t *testing.T) {
+	srv, err := infrastructure.NewServerBuilder("").
+		Name("example").
+		Provider(infrastructure.ProviderDigitalOcean).
+		Build()
+	test.CheckErr(t, "build server", err)
+
+	workspace, err := terraform.NewWorkspace()
+	test.CheckErr(t, "create workspace", err)
+	defer test.Close(t, workspace)
+
+	err = digitalocean.NewCloudProvider(digitalocean.DefaultAPIURL).AddServer(workspace, srv, []infrastructure.FirewallRule{
+		{Port: infrastructure.SSHPort, Sources: infrastructure.AnySource},
+		{Port: infrastructure.Port{Number: 27147, Protocol: "tcp"}, Sources: []string{"192.168.1.1/32"}},
+	})
+	test.CheckErr(t, "AddServer()", err)
+
+	err = workspace.Flush()
+	test.CheckErr(t, "flush workspace", err)
+
+	main, err := ioutil.ReadFile(filepath.Join(workspace.WorkDir(), "main.tf"))
+	test.CheckErr(t, "read main.tf", err)
+
+	want := `resource "digitalocean_firewall" "example" {
+  name="example"
+  droplet_ids=[digitalocean_droplet.example.id]
+  inbound_rule {
+    protocol="tcp"
+    port_range="22"
+    source_addresses=["0.0.0.0/0", "::/0"]
+  }
+  inbound_rule {
+    protocol="tcp"
+    port_range="27147"
+    source_addresses=["192.168.1.1/32"]
+  }
+`
+	if !strings.Contains(string(main), want) {
+		t.Errorf("main.tf missing firewall %s:\n%s", want, main)
+	}
+}
+
+func TestCloudProviderAddServerReservedIPAndDNS(t *testing.T) {
+	settings := infrastructure.NewProviderSettings("", "", infrastructure.ProviderDigitalOcean, "token")
+	reservedIP := infrastructure.NewReservedIP("", settings, "fra1")
+	reservedIP.Address = "203.0.113.10"
+
+	srv, err := infrastructure.NewServerBuilder("").
+		Name("example").
+		Provider(infrastructure.ProviderDigitalOcean).
+		ReservedIP(reservedIP).
+		Build()
+	test.CheckErr(t, "build server", err)
+
+	deployment := infrastructure.NewDeployment("", nil)
+	err = deployment.SetHostname("nodes.example.com", "fullnode-1")
+	test.CheckErr(t, "set hostname", err)
+	srv.AddDeployment(deployment)
+
+	workspace, err := terraform.NewWorkspace()
+	test.CheckErr(t, "create workspace", err)
+	defer test.Close(t, workspace)
+
+	err = digitalocean.NewCloudProvider(digitalocean.DefaultAPIURL).AddServer(workspace, srv, nil)
+	test.CheckErr(t, "AddServer()", err)
+
+	err = workspace.Flush()
+	test.CheckErr(t, "flush workspace", err)
+
+	main, err := ioutil.ReadFile(filepath.Join(workspace.WorkDir(), "main.tf"))
+	test.CheckErr(t, "read main.tf", err)
+
+	for _, want := range []string{
+		`resource "digitalocean_floating_ip_assignment" "example" {
+  ip_address="203.0.113.10"
+  droplet_id=digitalocean_droplet.example.id
+}
+`,
+		`resource "digitalocean_record" "example-fullnode-1-nodes-example-com" {
+  domain="nodes.example.com"
+  type="A"
+  name="fullnode-1"
+  value=digitalocean_floating_ip_assignment.example.ip_address
+  ttl=300
+}
+`,
+		`output "ip-address" {
+  value=digitalocean_floating_ip_assignment.example.ip_address
+}
+`,
+	} {
+		if !strings.Contains(string(main), want) {
+			t.Errorf("main.tf missing %s:\n%s", want, main)
+		}
+	}
+}
+
+func TestCloudProviderVolumeSnapshots(t *testing.T) {
+	cloudProvider := digitalocean.NewCloudProvider(digitalocean.DefaultAPIURL)
+
+	snap := infrastructure.NewVolumeSnapshot(&infrastructure.Server{VolumeSize: 750}, "fra1", false)
+	snap.State = infrastructure.VolumeSnapshotStateOk
+	snap.ProviderID = "12345"
+
+	srv, err := infrastructure.NewServerBuilder("").
+		Name("example").
+		Provider(infrastructure.ProviderDigitalOcean).
+		Size(infrastructure.ServerSizeProd).
+		VolumeSnapshot(snap).
+		Build()
+	test.CheckErr(t, "build server", err)
+
+	workspace, err := terraform.NewWorkspace()
+	test.CheckErr(t, "create workspace", err)
+	defer test.Close(t, workspace)
+
+	err = cloudProvider.AddServer(workspace, srv, nil)
+	test.CheckErr(t, "AddServer()", err)
+
+	snapWorkspace, err := terraform.NewWorkspace()
+	test.CheckErr(t, "create snapshot workspace", err)
+	defer test.Close(t, snapWorkspace)
+
+	err = cloudProvider.AddVolumeSnapshot(snapWorkspace, snap, "506f78a4-e098-11e5-ad9f-000f53306ae1")
+	test.CheckErr(t, "AddVolumeSnapshot()", err)
+
+	for _, ws := range []*terraform.Workspace{workspace, snapWorkspace} {
+		err = ws.Flush()
+		test.CheckErr(t, "flush workspace", err)
+	}
+
+	main, err := ioutil.ReadFile(filepath.Join(workspace.WorkDir(), "main.tf"))
+	test.CheckErr(t, "read main.tf", err)
+
+	snapMain, err := ioutil.ReadFile(filepath.Join(snapWorkspace.WorkDir(), "main.tf"))
+	test.CheckErr(t, "read snapshot main.tf", err)
+
+	for _, want := range []string{
+		`resource "digitalocean_volume" "example" {
+  name="volume"
+  region="fra1"
+  size=750
+  snapshot_id="12345"
+}
+`,
+		`output "volume-id" {
+  value=digitalocean_volume.example.id
+}
+`,
+	} {
+		if !strings.Contains(string(main), want) {
+			t.Errorf("main.tf missing %s:\n%s", want, main)
+		}
+	}
+
+	for _, want := range []string{
+		`resource "digitalocean_volume_snapshot" "snapshot-` + snap.ID.String() + `" {
+  name="snapshot-` + snap.ID.String() + `"
+  volume_id="506f78a4-e098-11e5-ad9f-000f53306ae1"
+}
+`,
+		`output "snapshot-id" {
+  value=digitalocean_volume_snapshot.snapshot-` + snap.ID.String() + `.id
+}
+`,
+	} {
+		if !strings.Contains(string(snapMain), want) {
+			t.Errorf("snapshot main.tf missing %s:\n%s", want, snapMain)
+		}
+	}
+}
+
+func TestCloudProviderAddServerTags(t *testing.T) {
+	deployment := infrastructure.NewDeployment(infrastructure.DeploymentType("binance-node"), nil)
+	err := deployment.SetLabels(infrastructure.Labels{"team": "nodes", "env": "staging"})
+	test.CheckErr(t, "set deployment labels", err)
+
+	srv, err := infrastructure.NewServerBuilder("").
+		Name("example").
+		Provider(infrastructure.ProviderDigitalOcean).
+		Labels(infrastructure.Labels{"env": "prod"}).
+		Build()
+	test.CheckErr(t, "build server", err)
+	srv.AddDeployment(deployment)
+
+	workspace, err := terraform.NewWorkspace()
+	test.CheckErr(t, "create workspace", err)
+	defer test.Close(t, workspace)
+
+	err = digitalocean.NewCloudProvider(digitalocean.DefaultAPIURL).AddServer(workspace, srv, nil)
+	test.CheckErr(t, "AddServer()", err)
+
+	err = workspace.Flush()
+	test.CheckErr(t, "flush workspace", err)
+
+	main, err := ioutil.ReadFile(filepath.Join(workspace.WorkDir(), "main.tf"))
+	test.CheckErr(t, "read main.tf", err)
+
+	// Server labels take precedence over the labels of its deployments.
+	want := `tags=["env:prod", "team:nodes"]`
+	if !strings.Contains(string(main), want) {
+		t.Errorf("main.tf missing droplet tags %s:\n%s", want, main)
+	}
+}
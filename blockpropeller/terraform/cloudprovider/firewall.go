@@ -0,0 +1,24 @@
+package cloudprovider
+
+import (
+	"net"
+)
+
+// SplitSources splits FirewallRule sources into IPv4 and IPv6 networks,
+// for providers configuring the two address families separately.
+func SplitSources(sources []string) (ipv4 []string, ipv6 []string) {
+	for _, source := range sources {
+		ip, _, err := net.ParseCIDR(source)
+		if err != nil {
+			continue
+		}
+
+		if ip.To4() != nil {
+			ipv4 = append(ipv4, source)
+		} else {
+			ipv6 = append(ipv6, source)
+		}
+	}
+
+	return ipv4, ipv6
+}
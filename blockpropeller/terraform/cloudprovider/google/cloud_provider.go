@@ -0,0 +1,288 @@
+package google
+
+import (
+	"context"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"blockpropeller.dev/blockpropeller/infrastructure"
+	"blockpropeller.dev/blockpropeller/terraform"
+	"blockpropeller.dev/blockpropeller/terraform/cloudprovider"
+	"blockpropeller.dev/blockpropeller/terraform/resource"
+	"blockpropeller.dev/blockpropeller/terraform/resource/google"
+	"github.com/pkg/errors"
+)
+
+func init() {
+	cloudprovider.RegisterProvider(infrastructure.ProviderGoogleCloud, NewCloudProvider(DefaultTokenURL))
+}
+
+// DefaultTokenURL is the Google OAuth 2.0 token endpoint service account keys are exchanged at.
+const DefaultTokenURL = "https://oauth2.googleapis.com/token"
+
+var (
+	// Compute Engine resources are zonal, so zones are offered as regions.
+	catalog = &cloudprovider.Catalog{
+		Currency: "USD",
+		Regions: []*cloudprovider.Region{
+			{ID: "europe-west1-b", Name: "St. Ghislain, Belgium"},
+			{ID: "europe-west3-a", Name: "Frankfurt, Germany"},
+			{ID: "europe-west4-a", Name: "Eemshaven, Netherlands"},
+			{ID: "us-central1-a", Name: "Council Bluffs, Iowa"},
+			{ID: "us-east1-b", Name: "Moncks Corner, South Carolina"},
+			{ID: "us-west1-a", Name: "The Dalles, Oregon"},
+			{ID: "asia-east1-a", Name: "Changhua County, Taiwan"},
+		},
+		Images: []*cloudprovider.Image{
+			{ID: "ubuntu-os-cloud/ubuntu-1804-lts", Name: "Ubuntu 18.04 LTS"},
+		},
+		InstanceTypes: []*cloudprovider.InstanceType{
+			{ID: "e2-small", CPU: 2, MemoryMB: 2048, DiskGB: 10, PriceMonthly: 12.23},
+			{ID: "e2-medium", CPU: 2, MemoryMB: 4096, DiskGB: 10, PriceMonthly: 24.46},
+			{ID: "e2-standard-2", CPU: 2, MemoryMB: 8192, DiskGB: 10, PriceMonthly: 48.92},
+			{ID: "e2-standard-4", CPU: 4, MemoryMB: 16384, DiskGB: 10, PriceMonthly: 97.84},
+			{ID: "e2-standard-8", CPU: 8, MemoryMB: 32768, DiskGB: 10, PriceMonthly: 195.67},
+		},
+		DefaultRegion: "europe-west3-a",
+		DefaultImage:  "ubuntu-os-cloud/ubuntu-1804-lts",
+		DefaultInstanceTypes: map[infrastructure.ServerSize]string{
+			infrastructure.ServerSizeTest: "e2-small",
+			infrastructure.ServerSizeProd: "e2-standard-4",
+		},
+		DefaultVolumeSizes: map[infrastructure.ServerSize]int{
+			infrastructure.ServerSizeTest: 0,
+			infrastructure.ServerSizeProd: 500,
+		},
+		MaxVolumeSizeGB: 65536,
+		VolumeSnapshots: true,
+	}
+	sshUser          = "blockpropeller"
+	volumeDeviceName = "volume"
+
+	invalidNameChars = regexp.MustCompile("[^a-z0-9-]+")
+)
+
+// Credentials is a Google Cloud service account key.
+//
+// Credentials are stored inside ProviderSettings in the same JSON format
+// the Google Cloud Console uses when exporting service account keys.
+type Credentials struct {
+	Type        string `json:"type"`
+	ProjectID   string `json:"project_id"`
+	ClientEmail string `json:"client_email"`
+	PrivateKey  string `json:"private_key"`
+}
+
+// ParseCredentials parses the service account key stored inside the ProviderSettings.
+func ParseCredentials(settings *infrastructure.ProviderSettings) (*Credentials, error) {
+	var creds Credentials
+	err := json.Unmarshal([]byte(settings.Credentials), &creds)
+	if err != nil {
+		return nil, errors.Wrap(err, "unmarshal service account key")
+	}
+
+	if creds.Type != "service_account" {
+		return nil, errors.Errorf("invalid credentials type: %s", creds.Type)
+	}
+
+	if creds.ProjectID == "" || creds.ClientEmail == "" || creds.PrivateKey == "" {
+		return nil, errors.New("service account key requires project_id, client_email and private_key")
+	}
+
+	return &creds, nil
+}
+
+// CloudProvider provisions servers as Google Compute Engine instances.
+type CloudProvider struct {
+	tokenURL string
+	client   *http.Client
+}
+
+// NewCloudProvider returns a new CloudProvider instance, validating credentials against the token endpoint at tokenURL.
+//
+// The token_uri of service account keys is ignored, so credentials are never sent elsewhere.
+func NewCloudProvider(tokenURL string) *CloudProvider {
+	return &CloudProvider{
+		tokenURL: tokenURL,
+		client:   &http.Client{Timeout: cloudprovider.DefaultValidationTimeout},
+	}
+}
+
+// Catalog satisfies the CloudProvider interface.
+func (c *CloudProvider) Catalog() *cloudprovider.Catalog {
+	return catalog
+}
+
+// ValidateCredentials satisfies the CloudProvider interface.
+//
+// The private key of the service account key is checked offline first,
+// and then used to sign a JWT grant exchanged for an access token.
+func (c *CloudProvider) ValidateCredentials(ctx context.Context, settings *infrastructure.ProviderSettings) error {
+	creds, err := ParseCredentials(settings)
+	if err != nil {
+		return cloudprovider.InvalidCredentials(err)
+	}
+
+	block, _ := pem.Decode([]byte(creds.PrivateKey))
+	if block == nil {
+		return cloudprovider.InvalidCredentials(errors.New("private_key is not PEM encoded"))
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return cloudprovider.InvalidCredentials(errors.Wrap(err, "parse private_key"))
+	}
+
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return cloudprovider.InvalidCredentials(errors.New("private_key is not an RSA key"))
+	}
+
+	return exchangeToken(ctx, c.client, c.tokenURL, creds, key)
+}
+
+// Register satisfies the CloudProvider interface.
+func (c *CloudProvider) Register(workspace *terraform.Workspace, settings *infrastructure.ProviderSettings) error {
+	creds, err := ParseCredentials(settings)
+	if err != nil {
+		return errors.Wrap(err, "parse credentials")
+	}
+
+	workspace.Add(google.NewProvider(settings.Credentials, creds.ProjectID, zoneRegion(catalog.DefaultRegion)))
+
+	return nil
+}
+
+// AddServer satisfies the CloudProvider interface.
+//
+// Google Compute Engine images do not allow logging in as root, while our playbooks
+// expect to. The SSH key is therefore registered for a regular user through instance
+// metadata, and additionally authorized for root by the startup script.
+func (c *CloudProvider) AddServer(workspace *terraform.Workspace, srv *infrastructure.Server, firewall []infrastructure.FirewallRule) error {
+	err := catalog.ValidateNetwork(srv)
+	if err != nil {
+		return err
+	}
+
+	sshKey := srv.SSHKey
+
+	machineType, err := catalog.ServerInstanceType(srv)
+	if err != nil {
+		return errors.Wrap(err, "get server size")
+	}
+
+	zone := catalog.ServerRegion(srv)
+
+	volumeSize, err := catalog.ServerVolumeSize(srv)
+	if err != nil {
+		return errors.Wrap(err, "get volume size")
+	}
+
+	name := formatName(srv.Name)
+	startupCommands := []string{rootAccessCommands(sshKey.EncodedPublicKey())}
+
+	labels := srv.ResourceTags()
+
+	instance := google.NewComputeInstance(name, machineType, zone, catalog.DefaultImage, sshUser, sshKey.EncodedPublicKey()).
+		SetLabels(labels)
+
+	if volumeSize > 0 {
+		disk := google.NewComputeDisk(name, zone, volumeSize).SetLabels(labels)
+		if srv.VolumeSnapshot != nil {
+			disk.SetSnapshot(srv.VolumeSnapshot.ProviderID)
+		}
+
+		instance.AttachDisk(volumeDeviceName, disk)
+		startupCommands = append(startupCommands,
+			cloudprovider.MountVolumeCommands(google.DevicePath(volumeDeviceName)))
+
+		workspace.AddResource(disk)
+		workspace.Add(resource.NewOutput("volume-id", resource.ToPropSelector(disk, "self_link")))
+	}
+
+	instance.SetStartupScript(cloudprovider.UserDataScript(startupCommands...))
+
+	workspace.AddResource(instance)
+	workspace.AddResource(firewallResources(name, firewall)...)
+
+	ipAddressOut := resource.NewOutput("ip-address",
+		resource.ToPropSelector(instance, "network_interface.0.access_config.0.nat_ip"))
+
+	workspace.Add(ipAddressOut)
+
+	return nil
+}
+
+// AddReservedIP satisfies the CloudProvider interface.
+func (c *CloudProvider) AddReservedIP(workspace *terraform.Workspace, ip *infrastructure.ReservedIP) error {
+	return cloudprovider.ErrReservedIPsNotSupported
+}
+
+// AddVolumeSnapshot satisfies the CloudProvider interface.
+//
+// Disks and snapshots are identified by their self links.
+func (c *CloudProvider) AddVolumeSnapshot(workspace *terraform.Workspace, snap *infrastructure.VolumeSnapshot, volumeID string) error {
+	snapshot := google.NewComputeSnapshot("snapshot-"+snap.ID.String(), snap.Region, volumeID)
+
+	workspace.AddResource(snapshot)
+	workspace.Add(resource.NewOutput("snapshot-id", resource.ToPropSelector(snapshot, "self_link")))
+
+	return nil
+}
+
+// firewallResources converts FirewallRules into ComputeFirewalls targeting the instance tagged with the name.
+//
+// ComputeFirewalls share source ranges between all their ports, so a ComputeFirewall is created
+// for each rule. The default network only carries IPv4 traffic, so IPv6 sources are left out.
+func firewallResources(name string, firewall []infrastructure.FirewallRule) []resource.Resource {
+	var resources []resource.Resource
+	for _, rule := range firewall {
+		ipv4, _ := cloudprovider.SplitSources(rule.Sources)
+		if len(ipv4) == 0 {
+			continue
+		}
+
+		resources = append(resources, google.NewComputeFirewall(
+			fmt.Sprintf("%s-%s-%d", name, rule.Port.Protocol, rule.Port.Number),
+			name,
+			ipv4,
+			[]google.FirewallAllow{{Protocol: rule.Port.Protocol, Ports: []string{strconv.Itoa(rule.Port.Number)}}},
+		))
+	}
+
+	return resources
+}
+
+// zoneRegion returns the region a zone belongs to.
+func zoneRegion(zone string) string {
+	return zone[:strings.LastIndex(zone, "-")]
+}
+
+// formatName converts a server name into a format accepted by Google Cloud,
+// which requires resource names to be lowercase and start with a letter.
+func formatName(name string) string {
+	name = invalidNameChars.ReplaceAllString(strings.ToLower(name), "-")
+	name = strings.Trim(name, "-")
+
+	return "bp-" + name
+}
+
+// rootAccessCommands returns shell commands which authorize the provided key for root
+// and allow root to log in over SSH using keys only.
+func rootAccessCommands(pubKey string) string {
+	return fmt.Sprintf(`mkdir -p /root/.ssh
+chmod 700 /root/.ssh
+touch /root/.ssh/authorized_keys
+grep -q -F "%[1]s" /root/.ssh/authorized_keys || echo "%[1]s" >> /root/.ssh/authorized_keys
+chmod 600 /root/.ssh/authorized_keys
+sed -i -E 's/^#?PermitRootLogin .*/PermitRootLogin prohibit-password/' /etc/ssh/sshd_config
+systemctl reload ssh
+`, strings.TrimSpace(pubKey))
+}
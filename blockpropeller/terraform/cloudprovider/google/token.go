@@ -0,0 +1,72 @@
+package google
+
+import (
+	"context"
+	"crypto/rsa"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"blockpropeller.dev/blockpropeller/terraform/cloudprovider"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/pkg/errors"
+)
+
+const (
+	jwtBearerGrantType = "urn:ietf:params:oauth:grant-type:jwt-bearer"
+	computeScope       = "https://www.googleapis.com/auth/compute"
+)
+
+// exchangeToken exchanges a JWT grant signed with the service account key for an access token.
+//
+// Grants refused by the token endpoint are reported as ErrInvalidCredentials.
+func exchangeToken(ctx context.Context, client *http.Client, tokenURL string, creds *Credentials, key *rsa.PrivateKey) error {
+	now := time.Now()
+
+	assertion, err := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":   creds.ClientEmail,
+		"scope": computeScope,
+		"aud":   tokenURL,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	}).SignedString(key)
+	if err != nil {
+		return cloudprovider.InvalidCredentials(errors.Wrap(err, "sign jwt grant"))
+	}
+
+	form := url.Values{
+		"grant_type": {jwtBearerGrantType},
+		"assertion":  {assertion},
+	}
+
+	req, err := http.NewRequest(http.MethodPost, tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return errors.Wrap(err, "create request")
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "send request")
+	}
+	defer resp.Body.Close()
+
+	// Drain the body so the connection can be reused.
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+
+	// Unknown, disabled or deleted keys are refused as an invalid grant.
+	switch {
+	case resp.StatusCode == http.StatusBadRequest ||
+		resp.StatusCode == http.StatusUnauthorized ||
+		resp.StatusCode == http.StatusForbidden:
+		return cloudprovider.InvalidCredentials(errors.Errorf("jwt grant rejected: %s", resp.Status))
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		return errors.Errorf("unexpected response: %s", resp.Status)
+	}
+
+	return nil
+}
@@ -0,0 +1,158 @@
+package hcloud
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"blockpropeller.dev/blockpropeller/infrastructure"
+	"blockpropeller.dev/blockpropeller/terraform"
+	"blockpropeller.dev/blockpropeller/terraform/cloudprovider"
+	"blockpropeller.dev/blockpropeller/terraform/resource"
+	"blockpropeller.dev/blockpropeller/terraform/resource/hcloud"
+	"github.com/pkg/errors"
+)
+
+func init() {
+	cloudprovider.RegisterProvider(infrastructure.ProviderHetzner, NewCloudProvider(DefaultAPIURL))
+}
+
+// DefaultAPIURL is the base URL of the Hetzner Cloud API.
+const DefaultAPIURL = "https://api.hetzner.cloud"
+
+var (
+	catalog = &cloudprovider.Catalog{
+		Currency: "EUR",
+		Regions: []*cloudprovider.Region{
+			{ID: "fsn1", Name: "Falkenstein DC Park 1"},
+			{ID: "nbg1", Name: "Nuremberg DC Park 1"},
+			{ID: "hel1", Name: "Helsinki DC Park 1"},
+		},
+		Images: []*cloudprovider.Image{
+			{ID: "ubuntu-18.04", Name: "Ubuntu 18.04"},
+		},
+		InstanceTypes: []*cloudprovider.InstanceType{
+			{ID: "cx11", CPU: 1, MemoryMB: 2048, DiskGB: 20, PriceMonthly: 2.96},
+			{ID: "cx21", CPU: 2, MemoryMB: 4096, DiskGB: 40, PriceMonthly: 5.83},
+			{ID: "cx31", CPU: 2, MemoryMB: 8192, DiskGB: 80, PriceMonthly: 10.59},
+			{ID: "cx41", CPU: 4, MemoryMB: 16384, DiskGB: 160, PriceMonthly: 18.92},
+			{ID: "cx51", CPU: 8, MemoryMB: 32768, DiskGB: 240, PriceMonthly: 35.58},
+		},
+		DefaultRegion: "fsn1",
+		DefaultImage:  "ubuntu-18.04",
+		DefaultInstanceTypes: map[infrastructure.ServerSize]string{
+			infrastructure.ServerSizeTest: "cx11",
+			infrastructure.ServerSizeProd: "cx41",
+		},
+		DefaultVolumeSizes: map[infrastructure.ServerSize]int{
+			infrastructure.ServerSizeTest: 0,
+			infrastructure.ServerSizeProd: 500,
+		},
+		MaxVolumeSizeGB: 10240,
+	}
+)
+
+// CloudProvider provisions servers on Hetzner Cloud.
+type CloudProvider struct {
+	apiURL string
+	client *http.Client
+}
+
+// NewCloudProvider returns a new CloudProvider instance, validating credentials against the API at apiURL.
+func NewCloudProvider(apiURL string) *CloudProvider {
+	return &CloudProvider{
+		apiURL: apiURL,
+		client: &http.Client{Timeout: cloudprovider.DefaultValidationTimeout},
+	}
+}
+
+// Catalog satisfies the CloudProvider interface.
+func (c *CloudProvider) Catalog() *cloudprovider.Catalog {
+	return catalog
+}
+
+// ValidateCredentials satisfies the CloudProvider interface.
+//
+// The API token is checked with a cheap authenticated read request.
+func (c *CloudProvider) ValidateCredentials(ctx context.Context, settings *infrastructure.ProviderSettings) error {
+	return cloudprovider.ValidateBearerToken(ctx, c.client, c.apiURL+"/v1/locations", settings.Credentials)
+}
+
+// Register satisfies the CloudProvider interface.
+func (c *CloudProvider) Register(workspace *terraform.Workspace, settings *infrastructure.ProviderSettings) error {
+	workspace.Add(hcloud.NewProvider(settings.Credentials))
+
+	return nil
+}
+
+// AddServer satisfies the CloudProvider interface.
+func (c *CloudProvider) AddServer(workspace *terraform.Workspace, srv *infrastructure.Server, firewall []infrastructure.FirewallRule) error {
+	err := catalog.ValidateNetwork(srv)
+	if err != nil {
+		return err
+	}
+
+	sshKey := srv.SSHKey
+
+	serverType, err := catalog.ServerInstanceType(srv)
+	if err != nil {
+		return errors.Wrap(err, "get server size")
+	}
+
+	location := catalog.ServerRegion(srv)
+
+	volumeSize, err := catalog.ServerVolumeSize(srv)
+	if err != nil {
+		return errors.Wrap(err, "get volume size")
+	}
+
+	hcloudSSHKey := hcloud.NewSSHKey(sshKey.Name, sshKey.EncodedPublicKey())
+	var hcloudFirewallRules []hcloud.FirewallRule
+	for _, rule := range firewall {
+		hcloudFirewallRules = append(hcloudFirewallRules, hcloud.FirewallRule{
+			Protocol:  rule.Port.Protocol,
+			Port:      strconv.Itoa(rule.Port.Number),
+			SourceIPs: rule.Sources,
+		})
+	}
+
+	hcloudFirewall := hcloud.NewFirewall(srv.Name, hcloudFirewallRules)
+
+	hcloudServer := hcloud.NewServer(
+		srv.Name,
+		catalog.DefaultImage,
+		location,
+		serverType,
+		[]*hcloud.SSHKey{hcloudSSHKey},
+		[]*hcloud.Firewall{hcloudFirewall},
+	).SetLabels(srv.ResourceTags())
+
+	workspace.AddResource(hcloudSSHKey, hcloudFirewall, hcloudServer)
+
+	ipAddressOut := resource.NewOutput("ip-address", resource.ToPropSelector(hcloudServer, "ipv4_address"))
+
+	workspace.Add(ipAddressOut)
+
+	if volumeSize > 0 {
+		hcloudVolume := hcloud.NewVolume(srv.Name, location, volumeSize).SetLabels(srv.ResourceTags())
+		hcloudVolumeAttachment := hcloud.NewVolumeAttachment(srv.Name, hcloudServer, hcloudVolume)
+
+		hcloudServer.SetUserData(cloudprovider.UserDataScript(
+			cloudprovider.MountVolumeCommands(hcloudVolume.DevicePath()),
+		))
+
+		workspace.AddResource(hcloudVolume, hcloudVolumeAttachment)
+	}
+
+	return nil
+}
+
+// AddReservedIP satisfies the CloudProvider interface.
+func (c *CloudProvider) AddReservedIP(workspace *terraform.Workspace, ip *infrastructure.ReservedIP) error {
+	return cloudprovider.ErrReservedIPsNotSupported
+}
+
+// AddVolumeSnapshot satisfies the CloudProvider interface.
+func (c *CloudProvider) AddVolumeSnapshot(workspace *terraform.Workspace, snap *infrastructure.VolumeSnapshot, volumeID string) error {
+	return cloudprovider.ErrVolumeSnapshotsNotSupported
+}
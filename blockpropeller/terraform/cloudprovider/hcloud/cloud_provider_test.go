@@ -0,0 +1,35 @@
+package hcloud_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"blockpropeller.dev/blockpropeller/infrastructure"
+	"blockpropeller.dev/blockpropeller/terraform/cloudprovider"
+	"blockpropeller.dev/blockpropeller/terraform/cloudprovider/hcloud"
+	"blockpropeller.dev/lib/test"
+)
+
+func TestCloudProviderValidateCredentials(t *testing.T) {
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/locations" || r.Header.Get("Authorization") != "Bearer valid-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer api.Close()
+
+	provider := hcloud.NewCloudProvider(api.URL)
+
+	settings := infrastructure.NewProviderSettings("", "", infrastructure.ProviderHetzner, "valid-token")
+	err := provider.ValidateCredentials(context.Background(), settings)
+	test.CheckErr(t, "validate valid token", err)
+
+	settings = infrastructure.NewProviderSettings("", "", infrastructure.ProviderHetzner, "revoked-token")
+	err = provider.ValidateCredentials(context.Background(), settings)
+	test.AssertBoolEqual(t, "revoked token is invalid", cloudprovider.IsInvalidCredentials(err), true)
+}
@@ -0,0 +1,131 @@
+package local
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"blockpropeller.dev/blockpropeller/infrastructure"
+	"blockpropeller.dev/blockpropeller/terraform"
+	"blockpropeller.dev/blockpropeller/terraform/cloudprovider"
+	"blockpropeller.dev/blockpropeller/terraform/resource"
+	"blockpropeller.dev/blockpropeller/terraform/resource/docker"
+)
+
+// Register makes the local provider available, provisioning containers on the given Docker daemon.
+//
+// Unlike the cloud providers, it is not registered on import, since anyone allowed
+// to create ProviderSettings gets privileged containers on the Docker host.
+// It MUST only be called when the Docker daemon is explicitly configured.
+func Register(dockerHost string) {
+	cloudprovider.RegisterProvider(infrastructure.ProviderLocal, NewCloudProvider(dockerHost))
+}
+
+var (
+	// The image is built from docker/local-host, see `make local-host-image`.
+	// Containers share the resources of the Docker host, so there is a single instance type.
+	catalog = &cloudprovider.Catalog{
+		Currency: "USD",
+		Regions: []*cloudprovider.Region{
+			{ID: "local", Name: "Local Docker host"},
+		},
+		Images: []*cloudprovider.Image{
+			{ID: "blockpropeller/local-host:latest", Name: "Ubuntu 18.04 with SSH"},
+		},
+		InstanceTypes: []*cloudprovider.InstanceType{
+			{ID: "container"},
+		},
+		DefaultRegion: "local",
+		DefaultImage:  "blockpropeller/local-host:latest",
+		DefaultInstanceTypes: map[infrastructure.ServerSize]string{
+			infrastructure.ServerSizeTest: "container",
+			infrastructure.ServerSizeProd: "container",
+		},
+	}
+
+	invalidNameChars = regexp.MustCompile(`[^a-zA-Z0-9_.-]+`)
+)
+
+// CloudProvider provisions servers as SSH enabled containers on a Docker daemon.
+//
+// Containers run privileged with their own Docker daemon, so playbooks
+// can deploy nodes inside them just like on a regular server.
+// Container addresses must be reachable from BlockPropeller,
+// which holds for a local Docker daemon on Linux.
+//
+// The Docker daemon comes from the server configuration, ProviderSettings.Credentials are ignored.
+type CloudProvider struct {
+	dockerHost string
+}
+
+// NewCloudProvider returns a new CloudProvider for the Docker daemon at dockerHost.
+func NewCloudProvider(dockerHost string) *CloudProvider {
+	return &CloudProvider{dockerHost: dockerHost}
+}
+
+// Catalog satisfies the CloudProvider interface.
+func (c *CloudProvider) Catalog() *cloudprovider.Catalog {
+	return catalog
+}
+
+// ValidateCredentials satisfies the CloudProvider interface.
+//
+// There are no credentials to check, the configured Docker daemon
+// is contacted once the workspace is applied.
+func (c *CloudProvider) ValidateCredentials(ctx context.Context, settings *infrastructure.ProviderSettings) error {
+	return nil
+}
+
+// Register satisfies the CloudProvider interface.
+func (c *CloudProvider) Register(workspace *terraform.Workspace, settings *infrastructure.ProviderSettings) error {
+	workspace.Add(docker.NewProvider(c.dockerHost))
+
+	return nil
+}
+
+// AddServer satisfies the CloudProvider interface.
+//
+// Containers are only reachable from the Docker host, so firewall rules are not enforced.
+func (c *CloudProvider) AddServer(workspace *terraform.Workspace, srv *infrastructure.Server, firewall []infrastructure.FirewallRule) error {
+	err := catalog.ValidateNetwork(srv)
+	if err != nil {
+		return err
+	}
+
+	name := formatName(srv.Name)
+
+	dataVolume := docker.NewVolume(name + "-data")
+	dockerVolume := docker.NewVolume(name + "-docker")
+
+	container := docker.NewContainer(name, catalog.DefaultImage).
+		SetPrivileged(true).
+		AddEnv("SSH_AUTHORIZED_KEY", strings.TrimSpace(srv.SSHKey.EncodedPublicKey())).
+		MountVolume(dataVolume, cloudprovider.VolumeMountPath).
+		MountVolume(dockerVolume, "/var/lib/docker")
+
+	workspace.AddResource(dataVolume, dockerVolume, container)
+
+	ipAddressOut := resource.NewOutput("ip-address", resource.ToPropSelector(container, "ip_address"))
+
+	workspace.Add(ipAddressOut)
+
+	return nil
+}
+
+// AddReservedIP satisfies the CloudProvider interface.
+func (c *CloudProvider) AddReservedIP(workspace *terraform.Workspace, ip *infrastructure.ReservedIP) error {
+	return cloudprovider.ErrReservedIPsNotSupported
+}
+
+// AddVolumeSnapshot satisfies the CloudProvider interface.
+func (c *CloudProvider) AddVolumeSnapshot(workspace *terraform.Workspace, snap *infrastructure.VolumeSnapshot, volumeID string) error {
+	return cloudprovider.ErrVolumeSnapshotsNotSupported
+}
+
+// formatName converts a server name into a format accepted for Docker container names.
+func formatName(name string) string {
+	name = invalidNameChars.ReplaceAllString(name, "-")
+	name = strings.Trim(name, "-")
+
+	return "blockpropeller-" + name
+}
@@ -0,0 +1,55 @@
+package local_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"blockpropeller.dev/blockpropeller/infrastructure"
+	"blockpropeller.dev/blockpropeller/terraform"
+	"blockpropeller.dev/blockpropeller/terraform/cloudprovider/local"
+	"blockpropeller.dev/lib/test"
+)
+
+func TestCloudProviderAddServer(t *testing.T) {
+	srv, err := infrastructure.NewServerBuilder("").
+		Name("Example Node").
+		Provider(infrastructure.ProviderLocal).
+		Build()
+	test.CheckErr(t, "build server", err)
+
+	workspace, err := terraform.NewWorkspace()
+	test.CheckErr(t, "create workspace", err)
+	defer test.Close(t, workspace)
+
+	provider := local.NewCloudProvider("unix:///var/run/docker.sock")
+
+	err = provider.Register(workspace, infrastructure.NewProviderSettings("", "", infrastructure.ProviderLocal, "tcp://attacker.example.com:2375"))
+	test.CheckErr(t, "Register()", err)
+
+	err = provider.AddServer(workspace, srv, nil)
+	test.CheckErr(t, "AddServer()", err)
+
+	err = workspace.Flush()
+	test.CheckErr(t, "flush workspace", err)
+
+	main, err := ioutil.ReadFile(filepath.Join(workspace.WorkDir(), "main.tf"))
+	test.CheckErr(t, "read main.tf", err)
+
+	for _, want := range []string{
+		`host="unix:///var/run/docker.sock"`,
+		`resource "docker_container" "blockpropeller-Example-Node" {`,
+		`container_path="/mnt/volume"`,
+		`"SSH_AUTHORIZED_KEY=` + strings.TrimSpace(srv.SSHKey.EncodedPublicKey()) + `"`,
+		`value=docker_container.blockpropeller-Example-Node.ip_address`,
+	} {
+		if !strings.Contains(string(main), want) {
+			t.Errorf("main.tf missing %s:\n%s", want, main)
+		}
+	}
+
+	if strings.Contains(string(main), "attacker.example.com") {
+		t.Errorf("main.tf uses the docker host from the provider credentials:\n%s", main)
+	}
+}
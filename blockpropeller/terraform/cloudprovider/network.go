@@ -0,0 +1,58 @@
+package cloudprovider
+
+import (
+	"sort"
+	"strings"
+
+	"blockpropeller.dev/blockpropeller/infrastructure"
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrReservedIPsNotSupported is returned for CloudProviders unable to manage reserved IP addresses.
+	ErrReservedIPsNotSupported = errors.New("reserved ips not supported by provider")
+	// ErrDNSRecordsNotSupported is returned for CloudProviders unable to manage DNS records.
+	ErrDNSRecordsNotSupported = errors.New("dns records not supported by provider")
+)
+
+// DNSRecord points a hostname inside a user-owned DNS zone to a Server.
+type DNSRecord struct {
+	Zone string
+	Name string
+}
+
+// Hostname returns the fully qualified hostname of the DNSRecord.
+func (r DNSRecord) Hostname() string {
+	return r.Name + "." + r.Zone
+}
+
+// Slug returns the hostname of the DNSRecord in a format suitable for Terraform resource names.
+func (r DNSRecord) Slug() string {
+	return strings.ReplaceAll(r.Hostname(), ".", "-")
+}
+
+// ServerDNSRecords returns the DNSRecords for the hostnames of Deployments running on the Server.
+func ServerDNSRecords(srv *infrastructure.Server) []DNSRecord {
+	seen := make(map[DNSRecord]bool)
+
+	var records []DNSRecord
+	for _, deployment := range srv.Deployments {
+		if deployment.Hostname() == "" {
+			continue
+		}
+
+		record := DNSRecord{Zone: deployment.DNSZone, Name: deployment.DNSName}
+		if seen[record] {
+			continue
+		}
+
+		seen[record] = true
+		records = append(records, record)
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].Hostname() < records[j].Hostname()
+	})
+
+	return records
+}
@@ -0,0 +1,48 @@
+package cloudprovider
+
+import (
+	"fmt"
+	"strings"
+)
+
+// VolumeMountPath is the location where deployment playbooks expect
+// the data volume of a server to be mounted.
+const VolumeMountPath = "/mnt/volume"
+
+// UserDataScript assembles a list of shell commands into a script
+// suitable for passing to a server as user data.
+//
+// The script aborts on the first failing command.
+func UserDataScript(commands ...string) string {
+	return "#!/bin/sh\nset -e\n" + strings.Join(commands, "")
+}
+
+// MountVolumeCommands returns shell commands which wait for the first of the provided
+// block devices to be attached, create a filesystem on it unless one already exists,
+// and mount it under VolumeMountPath.
+//
+// This is used by providers which, unlike DigitalOcean, do not mount attached
+// volumes on their own. The commands are safe to run on every boot.
+func MountVolumeCommands(devices ...string) string {
+	return fmt.Sprintf(`mkdir -p %[1]s
+volume_device=""
+for attempt in $(seq 1 120); do
+  for dev in %[2]s; do
+    if [ -b "$dev" ]; then
+      volume_device="$dev"
+      break 2
+    fi
+  done
+  sleep 5
+done
+if [ -z "$volume_device" ]; then
+  echo "data volume was not attached in time" >&2
+  exit 1
+fi
+blkid "$volume_device" || mkfs.ext4 -F "$volume_device"
+if ! grep -q " %[1]s " /etc/fstab; then
+  echo "UUID=$(blkid -s UUID -o value "$volume_device") %[1]s ext4 defaults,nofail 0 2" >> /etc/fstab
+fi
+mountpoint -q %[1]s || mount %[1]s
+`, VolumeMountPath, strings.Join(devices, " "))
+}
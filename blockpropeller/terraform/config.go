@@ -1,8 +1,13 @@
 package terraform
 
+import "time"
+
 // Config object for working with Terraform.
 type Config struct {
 	Path string `yaml:"path"`
+
+	// Timeout limits the duration of a single Terraform command.
+	Timeout time.Duration `yaml:"timeout"`
 }
 
 // Validate satisfies the Config interface.
@@ -11,5 +16,9 @@ func (cfg *Config) Validate() error {
 		cfg.Path = "/usr/local/bin/terraform"
 	}
 
+	if cfg.Timeout == 0 {
+		cfg.Timeout = 30 * time.Minute
+	}
+
 	return nil
 }
@@ -0,0 +1,53 @@
+package aws
+
+import (
+	"blockpropeller.dev/blockpropeller/terraform/resource"
+)
+
+// AMI is a data source looking up the most recent Amazon Machine Image
+// published by an owner, with a name matching the provided pattern.
+//
+// Image IDs differ between regions, so instead of hard-coding them
+// we let Terraform resolve the image for the region it operates in.
+type AMI struct {
+	name        string
+	owner       string
+	namePattern string
+}
+
+// NewAMI returns a new AMI instance.
+func NewAMI(name string, owner string, namePattern string) *AMI {
+	return &AMI{
+		name:        name,
+		owner:       owner,
+		namePattern: namePattern,
+	}
+}
+
+// Type of the data source.
+func (a *AMI) Type() string {
+	return "aws_ami"
+}
+
+// Name of the data source.
+func (a *AMI) Name() string {
+	return a.name
+}
+
+// Properties associated with the data source.
+func (a *AMI) Properties() *resource.Properties {
+	return resource.NewProperties().
+		Prop("most_recent", resource.NewRawProperty("true")).
+		Prop("owners", resource.NewArrayProperty(resource.NewStringProperty(a.owner))).
+		Block("filter", resource.NewProperties().
+			Prop("name", resource.NewStringProperty("name")).
+			Prop("values", resource.NewArrayProperty(resource.NewStringProperty(a.namePattern)))).
+		Block("filter", resource.NewProperties().
+			Prop("name", resource.NewStringProperty("virtualization-type")).
+			Prop("values", resource.NewArrayProperty(resource.NewStringProperty("hvm"))))
+}
+
+// Render satisfies the terraform.Renderer interface.
+func (a *AMI) Render() string {
+	return resource.RenderDataSource(a)
+}
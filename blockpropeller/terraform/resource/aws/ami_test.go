@@ -0,0 +1,30 @@
+package aws_test
+
+import (
+	"testing"
+
+	"blockpropeller.dev/blockpropeller/terraform/resource/aws"
+	"blockpropeller.dev/lib/test"
+)
+
+func TestAMIRendering(t *testing.T) {
+	ami := aws.NewAMI("ubuntu", "099720109477", "ubuntu/images/hvm-ssd/ubuntu-bionic-18.04-amd64-server-*")
+
+	want := `data "aws_ami" "ubuntu" {
+  most_recent=true
+  owners=["099720109477"]
+  filter {
+    name="name"
+    values=["ubuntu/images/hvm-ssd/ubuntu-bionic-18.04-amd64-server-*"]
+  }
+  filter {
+    name="virtualization-type"
+    values=["hvm"]
+  }
+}
+`
+
+	got := ami.Render()
+
+	test.AssertStringsEqual(t, "AMI.Render()", got, want)
+}
@@ -0,0 +1,41 @@
+package aws
+
+import (
+	"blockpropeller.dev/blockpropeller/terraform/resource"
+)
+
+// EBSSnapshot is a point-in-time snapshot of an EBSVolume.
+//
+// The volume is referenced by its ID, as it is managed
+// independently from the snapshots taken of it.
+type EBSSnapshot struct {
+	name     string
+	volumeID string
+}
+
+// NewEBSSnapshot returns a new EBSSnapshot instance.
+func NewEBSSnapshot(name string, volumeID string) *EBSSnapshot {
+	return &EBSSnapshot{
+		name:     name,
+		volumeID: volumeID,
+	}
+}
+
+// Type of the resource.
+func (s *EBSSnapshot) Type() string {
+	return "aws_ebs_snapshot"
+}
+
+// Name of the resource.
+func (s *EBSSnapshot) Name() string {
+	return s.name
+}
+
+// Properties associated with the resource.
+func (s *EBSSnapshot) Properties() *resource.Properties {
+	return resource.NewProperties().
+		Prop("volume_id", resource.NewStringProperty(s.volumeID)).
+		Prop("tags", resource.NewMapProperty(map[string]resource.Property{
+			"Name": resource.NewStringProperty(s.name),
+		}))
+}
@@ -0,0 +1,23 @@
+package aws_test
+
+import (
+	"testing"
+
+	"blockpropeller.dev/blockpropeller/terraform/resource"
+	"blockpropeller.dev/blockpropeller/terraform/resource/aws"
+	"blockpropeller.dev/lib/test"
+)
+
+func TestEBSSnapshotRendering(t *testing.T) {
+	snapshot := aws.NewEBSSnapshot("example-snapshot", "vol-0123456789abcdef0")
+
+	want := `resource "aws_ebs_snapshot" "example-snapshot" {
+  volume_id="vol-0123456789abcdef0"
+  tags={"Name"="example-snapshot"}
+}
+`
+
+	got := resource.Render(snapshot)
+
+	test.AssertStringsEqual(t, "EBSSnapshot.Render()", got, want)
+}
@@ -0,0 +1,99 @@
+package aws
+
+import (
+	"blockpropeller.dev/blockpropeller/terraform/resource"
+)
+
+// EBSVolume is an Elastic Block Store volume that can be attached to an Instance.
+//
+// The volume is created in the same availability zone as the Instance it is meant for.
+type EBSVolume struct {
+	name     string
+	instance *Instance
+	size     int
+	snapshot string
+	tags     map[string]string
+}
+
+// NewEBSVolume returns a new EBSVolume instance.
+func NewEBSVolume(name string, instance *Instance, size int) *EBSVolume {
+	return &EBSVolume{
+		name:     name,
+		instance: instance,
+		size:     size,
+	}
+}
+
+// SetSnapshot creates the EBSVolume from an existing EBSSnapshot, identified by its ID.
+func (v *EBSVolume) SetSnapshot(snapshotID string) *EBSVolume {
+	v.snapshot = snapshotID
+
+	return v
+}
+
+// SetTags sets the tags applied to the EBSVolume, in addition to its `Name` tag.
+func (v *EBSVolume) SetTags(tags map[string]string) *EBSVolume {
+	v.tags = tags
+
+	return v
+}
+
+// Type of the resource.
+func (v *EBSVolume) Type() string {
+	return "aws_ebs_volume"
+}
+
+// Name of the resource.
+func (v *EBSVolume) Name() string {
+	return v.name
+}
+
+// Properties associated with the resource.
+func (v *EBSVolume) Properties() *resource.Properties {
+	props := resource.NewProperties().
+		Prop("availability_zone", resource.ToPropSelector(v.instance, "availability_zone")).
+		Prop("size", resource.NewIntegerProperty(v.size)).
+		Prop("type", resource.NewStringProperty("gp2"))
+
+	if v.snapshot != "" {
+		props.Prop("snapshot_id", resource.NewStringProperty(v.snapshot))
+	}
+
+	return props.Prop("tags", nameTags(v.name, v.tags))
+}
+
+// VolumeAttachment connects an EBSVolume with an EC2 Instance.
+type VolumeAttachment struct {
+	name       string
+	deviceName string
+	instance   *Instance
+	volume     *EBSVolume
+}
+
+// NewVolumeAttachment returns a new VolumeAttachment instance.
+func NewVolumeAttachment(name string, deviceName string, instance *Instance, volume *EBSVolume) *VolumeAttachment {
+	return &VolumeAttachment{
+		name:       name,
+		deviceName: deviceName,
+		instance:   instance,
+		volume:     volume,
+	}
+}
+
+// Type of the resource.
+func (a *VolumeAttachment) Type() string {
+	return "aws_volume_attachment"
+}
+
+// Name of the resource.
+func (a *VolumeAttachment) Name() string {
+	return a.name
+}
+
+// Properties associated with the resource.
+func (a *VolumeAttachment) Properties() *resource.Properties {
+	return resource.NewProperties().
+		Prop("device_name", resource.NewStringProperty(a.deviceName)).
+		Prop("instance_id", resource.ToID(a.instance)).
+		Prop("volume_id", resource.ToID(a.volume))
+}
@@ -0,0 +1,61 @@
+package aws_test
+
+import (
+	"testing"
+
+	"blockpropeller.dev/blockpropeller/terraform/resource"
+	"blockpropeller.dev/blockpropeller/terraform/resource/aws"
+	"blockpropeller.dev/lib/test"
+)
+
+func TestEBSVolumeRendering(t *testing.T) {
+	instance := aws.NewInstance("example-0", nil, "", nil, nil)
+	volume := aws.NewEBSVolume("example-0", instance, 500)
+
+	want := `resource "aws_ebs_volume" "example-0" {
+  availability_zone=aws_instance.example-0.availability_zone
+  size=500
+  type="gp2"
+  tags={"Name"="example-0"}
+}
+`
+
+	got := resource.Render(volume)
+
+	test.AssertStringsEqual(t, "EBSVolume.Render()", got, want)
+}
+
+func TestEBSVolumeFromSnapshotRendering(t *testing.T) {
+	instance := aws.NewInstance("example-0", nil, "", nil, nil)
+	volume := aws.NewEBSVolume("example-0", instance, 500).SetSnapshot("snap-0123456789abcdef0")
+
+	want := `resource "aws_ebs_volume" "example-0" {
+  availability_zone=aws_instance.example-0.availability_zone
+  size=500
+  type="gp2"
+  snapshot_id="snap-0123456789abcdef0"
+  tags={"Name"="example-0"}
+}
+`
+
+	got := resource.Render(volume)
+
+	test.AssertStringsEqual(t, "EBSVolume.Render()", got, want)
+}
+
+func TestVolumeAttachmentRendering(t *testing.T) {
+	instance := aws.NewInstance("example-0", nil, "", nil, nil)
+	volume := aws.NewEBSVolume("volume-500", instance, 500)
+	attachment := aws.NewVolumeAttachment("example-volume-att", "/dev/sdh", instance, volume)
+
+	want := `resource "aws_volume_attachment" "example-volume-att" {
+  device_name="/dev/sdh"
+  instance_id=aws_instance.example-0.id
+  volume_id=aws_ebs_volume.volume-500.id
+}
+`
+
+	got := resource.Render(attachment)
+
+	test.AssertStringsEqual(t, "VolumeAttachment.Render()", got, want)
+}
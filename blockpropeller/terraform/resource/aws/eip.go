@@ -0,0 +1,78 @@
+package aws
+
+import (
+	"blockpropeller.dev/blockpropeller/terraform/resource"
+)
+
+// EIP is an Elastic IP address associated with an Instance.
+//
+// Unlike the public address assigned to an Instance on boot,
+// an Elastic IP is retained when the Instance is stopped.
+//
+// An EIP without an Instance is allocated on its own, and can be
+// associated with Instances managed elsewhere through an EIPAssociation.
+type EIP struct {
+	name     string
+	instance *Instance
+}
+
+// NewEIP returns a new EIP instance.
+func NewEIP(name string, instance *Instance) *EIP {
+	return &EIP{
+		name:     name,
+		instance: instance,
+	}
+}
+
+// Type of the resource.
+func (e *EIP) Type() string {
+	return "aws_eip"
+}
+
+// Name of the resource.
+func (e *EIP) Name() string {
+	return e.name
+}
+
+// Properties associated with the resource.
+func (e *EIP) Properties() *resource.Properties {
+	props := resource.NewProperties()
+	if e.instance != nil {
+		props.Prop("instance", resource.ToID(e.instance))
+	}
+
+	return props.Prop("vpc", resource.NewRawProperty("true"))
+}
+
+// EIPAssociation associates an allocated EIP with an Instance.
+type EIPAssociation struct {
+	name         string
+	allocationID string
+	instance     *Instance
+}
+
+// NewEIPAssociation returns a new EIPAssociation instance for the EIP with the allocationID.
+func NewEIPAssociation(name string, allocationID string, instance *Instance) *EIPAssociation {
+	return &EIPAssociation{
+		name:         name,
+		allocationID: allocationID,
+		instance:     instance,
+	}
+}
+
+// Type of the resource.
+func (a *EIPAssociation) Type() string {
+	return "aws_eip_association"
+}
+
+// Name of the resource.
+func (a *EIPAssociation) Name() string {
+	return a.name
+}
+
+// Properties associated with the resource.
+func (a *EIPAssociation) Properties() *resource.Properties {
+	return resource.NewProperties().
+		Prop("allocation_id", resource.NewStringProperty(a.allocationID)).
+		Prop("instance_id", resource.ToID(a.instance))
+}
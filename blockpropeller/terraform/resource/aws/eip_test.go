@@ -0,0 +1,52 @@
+package aws_test
+
+import (
+	"testing"
+
+	"blockpropeller.dev/blockpropeller/terraform/resource"
+	"blockpropeller.dev/blockpropeller/terraform/resource/aws"
+	"blockpropeller.dev/lib/test"
+)
+
+func TestEIPRendering(t *testing.T) {
+	instance := aws.NewInstance("example-0", nil, "", nil, nil)
+	eip := aws.NewEIP("example-0", instance)
+
+	want := `resource "aws_eip" "example-0" {
+  instance=aws_instance.example-0.id
+  vpc=true
+}
+`
+
+	got := resource.Render(eip)
+
+	test.AssertStringsEqual(t, "EIP.Render()", got, want)
+}
+
+func TestEIPWithoutInstanceRendering(t *testing.T) {
+	eip := aws.NewEIP("reserved", nil)
+
+	want := `resource "aws_eip" "reserved" {
+  vpc=true
+}
+`
+
+	got := resource.Render(eip)
+
+	test.AssertStringsEqual(t, "EIP.Render()", got, want)
+}
+
+func TestEIPAssociationRendering(t *testing.T) {
+	instance := aws.NewInstance("example-0", nil, "", nil, nil)
+	association := aws.NewEIPAssociation("example-0", "eipalloc-0123456789abcdef0", instance)
+
+	want := `resource "aws_eip_association" "example-0" {
+  allocation_id="eipalloc-0123456789abcdef0"
+  instance_id=aws_instance.example-0.id
+}
+`
+
+	got := resource.Render(association)
+
+	test.AssertStringsEqual(t, "EIPAssociation.Render()", got, want)
+}
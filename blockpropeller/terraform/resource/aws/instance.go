@@ -0,0 +1,88 @@
+package aws
+
+import (
+	"blockpropeller.dev/blockpropeller/terraform/resource"
+)
+
+// Instance is an AWS EC2 definition of a server.
+//
+// In order to configure an instance, properties such as `ami` and `instance_type` must be set.
+type Instance struct {
+	name           string
+	ami            *AMI
+	instanceType   string
+	keyPair        *KeyPair
+	securityGroups []*SecurityGroup
+	userData       string
+	tags           map[string]string
+}
+
+// NewInstance returns a new Instance instance.
+func NewInstance(name string, ami *AMI, instanceType string, keyPair *KeyPair, securityGroups []*SecurityGroup) *Instance {
+	return &Instance{
+		name:           name,
+		ami:            ami,
+		instanceType:   instanceType,
+		keyPair:        keyPair,
+		securityGroups: securityGroups,
+	}
+}
+
+// SetUserData sets a script that is executed when the Instance boots for the first time.
+func (i *Instance) SetUserData(userData string) *Instance {
+	i.userData = userData
+
+	return i
+}
+
+// SetTags sets the tags applied to the Instance, in addition to its `Name` tag.
+func (i *Instance) SetTags(tags map[string]string) *Instance {
+	i.tags = tags
+
+	return i
+}
+
+// Type of the resource.
+func (i *Instance) Type() string {
+	return "aws_instance"
+}
+
+// Name of the resource.
+func (i *Instance) Name() string {
+	return i.name
+}
+
+// Properties associated with the resource.
+func (i *Instance) Properties() *resource.Properties {
+	var securityGroups []resource.Property
+	for _, securityGroup := range i.securityGroups {
+		securityGroups = append(securityGroups, resource.ToID(securityGroup))
+	}
+
+	props := resource.NewProperties().
+		Prop("ami", resource.ToDataPropSelector(i.ami, "id")).
+		Prop("instance_type", resource.NewStringProperty(i.instanceType)).
+		Prop("key_name", resource.ToPropSelector(i.keyPair, "key_name")).
+		Prop("vpc_security_group_ids", resource.NewArrayProperty(securityGroups...))
+
+	if i.userData != "" {
+		props.Prop("user_data", resource.NewHeredocProperty(i.userData))
+	}
+
+	return props.Prop("tags", nameTags(i.name, i.tags))
+}
+
+// nameTags returns the tags of a resource, together with the `Name` tag
+// AWS uses for displaying resources.
+func nameTags(name string, tags map[string]string) *resource.MapProperty {
+	values := map[string]string{"Name": name}
+	for key, value := range tags {
+		if key == "Name" {
+			continue
+		}
+
+		values[key] = value
+	}
+
+	return resource.NewStringMapProperty(values)
+}
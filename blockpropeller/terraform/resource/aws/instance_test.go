@@ -0,0 +1,79 @@
+package aws_test
+
+import (
+	"testing"
+
+	"blockpropeller.dev/blockpropeller/terraform/resource"
+	"blockpropeller.dev/blockpropeller/terraform/resource/aws"
+	"blockpropeller.dev/lib/test"
+)
+
+func TestInstanceRendering(t *testing.T) {
+	instance := aws.NewInstance(
+		"example-0",
+		aws.NewAMI("ubuntu", "", ""),
+		"c5.xlarge",
+		aws.NewKeyPair("default", "ssh-rsa example@example.com"),
+		nil,
+	)
+
+	want := `resource "aws_instance" "example-0" {
+  ami=data.aws_ami.ubuntu.id
+  instance_type="c5.xlarge"
+  key_name=aws_key_pair.default.key_name
+  vpc_security_group_ids=[]
+  tags={"Name"="example-0"}
+}
+`
+
+	got := resource.Render(instance)
+	test.AssertStringsEqual(t, "Instance.Render()", got, want)
+}
+
+func TestInstanceWithSecurityGroupsAndUserData(t *testing.T) {
+	instance := aws.NewInstance(
+		"example-0",
+		aws.NewAMI("ubuntu", "", ""),
+		"t3.micro",
+		aws.NewKeyPair("default", "ssh-rsa example@example.com"),
+		[]*aws.SecurityGroup{aws.NewSecurityGroup("example-0", nil)},
+	).SetUserData("#!/bin/sh\necho foo\n")
+
+	want := `resource "aws_instance" "example-0" {
+  ami=data.aws_ami.ubuntu.id
+  instance_type="t3.micro"
+  key_name=aws_key_pair.default.key_name
+  vpc_security_group_ids=[aws_security_group.example-0.id]
+  user_data=<<EOF
+#!/bin/sh
+echo foo
+EOF
+  tags={"Name"="example-0"}
+}
+`
+
+	got := resource.Render(instance)
+	test.AssertStringsEqual(t, "Instance.Render()", got, want)
+}
+
+func TestInstanceWithTags(t *testing.T) {
+	instance := aws.NewInstance(
+		"example-0",
+		aws.NewAMI("ubuntu", "", ""),
+		"c5.xlarge",
+		aws.NewKeyPair("default", "ssh-rsa example@example.com"),
+		nil,
+	).SetTags(map[string]string{"env": "prod", "Name": "ignored"})
+
+	want := `resource "aws_instance" "example-0" {
+  ami=data.aws_ami.ubuntu.id
+  instance_type="c5.xlarge"
+  key_name=aws_key_pair.default.key_name
+  vpc_security_group_ids=[]
+  tags={"Name"="example-0", "env"="prod"}
+}
+`
+
+	got := resource.Render(instance)
+	test.AssertStringsEqual(t, "Instance.Render()", got, want)
+}
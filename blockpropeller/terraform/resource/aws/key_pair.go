@@ -0,0 +1,42 @@
+package aws
+
+import (
+	"strings"
+
+	"blockpropeller.dev/blockpropeller/terraform/resource"
+)
+
+// KeyPair is a managed EC2 key pair.
+//
+// A KeyPair resource can then be referenced from
+// an Instance in order to gain SSH access to the
+// provisioned machine.
+type KeyPair struct {
+	name   string
+	pubKey string
+}
+
+// NewKeyPair returns a new KeyPair instance.
+func NewKeyPair(name string, pubKey string) *KeyPair {
+	return &KeyPair{
+		name:   name,
+		pubKey: pubKey,
+	}
+}
+
+// Type of the resource.
+func (k *KeyPair) Type() string {
+	return "aws_key_pair"
+}
+
+// Name of the resource.
+func (k *KeyPair) Name() string {
+	return k.name
+}
+
+// Properties associated with the resource.
+func (k *KeyPair) Properties() *resource.Properties {
+	return resource.NewProperties().
+		Prop("key_name", resource.NewStringProperty(k.name)).
+		Prop("public_key", resource.NewStringProperty(strings.Trim(k.pubKey, "\n")))
+}
@@ -0,0 +1,23 @@
+package aws_test
+
+import (
+	"testing"
+
+	"blockpropeller.dev/blockpropeller/terraform/resource"
+	"blockpropeller.dev/blockpropeller/terraform/resource/aws"
+	"blockpropeller.dev/lib/test"
+)
+
+func TestKeyPairRendering(t *testing.T) {
+	keyPair := aws.NewKeyPair("example", "ssh-rsa example@example.com\n")
+
+	want := `resource "aws_key_pair" "example" {
+  key_name="example"
+  public_key="ssh-rsa example@example.com"
+}
+`
+
+	got := resource.Render(keyPair)
+
+	test.AssertStringsEqual(t, "KeyPair.Render()", got, want)
+}
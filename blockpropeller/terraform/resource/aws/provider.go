@@ -0,0 +1,37 @@
+package aws
+
+import (
+	"bytes"
+
+	"blockpropeller.dev/blockpropeller/terraform/resource"
+)
+
+// Provider configures Terraform to know how to authenticate
+// AWS requests for provisioning resources.
+type Provider struct {
+	props *resource.Properties
+}
+
+// NewProvider returns a new Provider instance.
+//
+// Region is a Property so it can reference a variable, since AWS resources
+// are placed in the region of the provider they are created with.
+func NewProvider(region resource.Property, accessKey string, secretKey string) *Provider {
+	return &Provider{
+		props: resource.NewProperties().
+			Prop("region", region).
+			Prop("access_key", resource.NewStringProperty(accessKey)).
+			Prop("secret_key", resource.NewStringProperty(secretKey)),
+	}
+}
+
+// Render satisfies the resource.Provider interface.
+func (p *Provider) Render() string {
+	var buf bytes.Buffer
+
+	buf.WriteString("provider \"aws\" {\n")
+	buf.WriteString(p.props.Indent(2).Render())
+	buf.WriteString("}\n")
+
+	return buf.String()
+}
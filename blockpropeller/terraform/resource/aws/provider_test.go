@@ -0,0 +1,39 @@
+package aws_test
+
+import (
+	"testing"
+
+	"blockpropeller.dev/blockpropeller/terraform/resource"
+	"blockpropeller.dev/blockpropeller/terraform/resource/aws"
+	"blockpropeller.dev/lib/test"
+)
+
+func TestProviderRendering(t *testing.T) {
+	provider := aws.NewProvider(resource.NewStringProperty("eu-central-1"), "AKIAEXAMPLE", "secret")
+
+	want := `provider "aws" {
+  region="eu-central-1"
+  access_key="AKIAEXAMPLE"
+  secret_key="secret"
+}
+`
+
+	got := provider.Render()
+
+	test.AssertStringsEqual(t, "Provider.Render()", got, want)
+}
+
+func TestProviderRegionVariable(t *testing.T) {
+	provider := aws.NewProvider(resource.ToVariable("region"), "AKIAEXAMPLE", "secret")
+
+	want := `provider "aws" {
+  region=var.region
+  access_key="AKIAEXAMPLE"
+  secret_key="secret"
+}
+`
+
+	got := provider.Render()
+
+	test.AssertStringsEqual(t, "Provider.Render()", got, want)
+}
@@ -0,0 +1,88 @@
+package aws
+
+import (
+	"blockpropeller.dev/blockpropeller/terraform/resource"
+)
+
+// recordTTL is kept low, so records pointing to replaced Instances expire quickly.
+const recordTTL = 300
+
+// Route53Zone is a data source looking up a public Route 53 hosted zone by its domain name.
+//
+// The hosted zone is owned by the user and is never managed by BlockPropeller.
+type Route53Zone struct {
+	name     string
+	zoneName string
+}
+
+// NewRoute53Zone returns a new Route53Zone instance.
+func NewRoute53Zone(name string, zoneName string) *Route53Zone {
+	return &Route53Zone{
+		name:     name,
+		zoneName: zoneName,
+	}
+}
+
+// Type of the data source.
+func (z *Route53Zone) Type() string {
+	return "aws_route53_zone"
+}
+
+// Name of the data source.
+func (z *Route53Zone) Name() string {
+	return z.name
+}
+
+// Properties associated with the data source.
+func (z *Route53Zone) Properties() *resource.Properties {
+	return resource.NewProperties().
+		Prop("name", resource.NewStringProperty(z.zoneName)).
+		Prop("private_zone", resource.NewRawProperty("false"))
+}
+
+// Render satisfies the terraform.Renderer interface.
+func (z *Route53Zone) Render() string {
+	return resource.RenderDataSource(z)
+}
+
+// Route53Record is an A record inside a Route53Zone.
+//
+// Existing records are overwritten, so a replaced Instance can take over
+// a hostname still pointing to the Instance it replaces.
+type Route53Record struct {
+	name     string
+	zone     *Route53Zone
+	hostname string
+	value    resource.Property
+}
+
+// NewRoute53Record returns a new Route53Record instance, pointing the hostname to the value address.
+func NewRoute53Record(name string, zone *Route53Zone, hostname string, value resource.Property) *Route53Record {
+	return &Route53Record{
+		name:     name,
+		zone:     zone,
+		hostname: hostname,
+		value:    value,
+	}
+}
+
+// Type of the resource.
+func (r *Route53Record) Type() string {
+	return "aws_route53_record"
+}
+
+// Name of the resource.
+func (r *Route53Record) Name() string {
+	return r.name
+}
+
+// Properties associated with the resource.
+func (r *Route53Record) Properties() *resource.Properties {
+	return resource.NewProperties().
+		Prop("zone_id", resource.ToDataPropSelector(r.zone, "zone_id")).
+		Prop("name", resource.NewStringProperty(r.hostname)).
+		Prop("type", resource.NewStringProperty("A")).
+		Prop("ttl", resource.NewIntegerProperty(recordTTL)).
+		Prop("records", resource.NewArrayProperty(r.value)).
+		Prop("allow_overwrite", resource.NewRawProperty("true"))
+}
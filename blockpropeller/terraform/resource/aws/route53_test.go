@@ -0,0 +1,45 @@
+package aws_test
+
+import (
+	"testing"
+
+	"blockpropeller.dev/blockpropeller/terraform/resource"
+	"blockpropeller.dev/blockpropeller/terraform/resource/aws"
+	"blockpropeller.dev/lib/test"
+)
+
+func TestRoute53ZoneRendering(t *testing.T) {
+	zone := aws.NewRoute53Zone("nodes-example-com", "nodes.example.com")
+
+	want := `data "aws_route53_zone" "nodes-example-com" {
+  name="nodes.example.com"
+  private_zone=false
+}
+`
+
+	got := zone.Render()
+
+	test.AssertStringsEqual(t, "Route53Zone.Render()", got, want)
+}
+
+func TestRoute53RecordRendering(t *testing.T) {
+	instance := aws.NewInstance("example-0", nil, "", nil, nil)
+	eip := aws.NewEIP("example-0", instance)
+	zone := aws.NewRoute53Zone("nodes-example-com", "nodes.example.com")
+	record := aws.NewRoute53Record("example-0-fullnode-1", zone, "fullnode-1.nodes.example.com",
+		resource.ToPropSelector(eip, "public_ip"))
+
+	want := `resource "aws_route53_record" "example-0-fullnode-1" {
+  zone_id=data.aws_route53_zone.nodes-example-com.zone_id
+  name="fullnode-1.nodes.example.com"
+  type="A"
+  ttl=300
+  records=[aws_eip.example-0.public_ip]
+  allow_overwrite=true
+}
+`
+
+	got := resource.Render(record)
+
+	test.AssertStringsEqual(t, "Route53Record.Render()", got, want)
+}
@@ -0,0 +1,80 @@
+package aws
+
+import (
+	"blockpropeller.dev/blockpropeller/terraform/resource"
+)
+
+// SecurityGroupRule allows inbound traffic for a range of ports
+// coming from a set of IPv4 and IPv6 CIDR blocks.
+type SecurityGroupRule struct {
+	Protocol       string
+	FromPort       int
+	ToPort         int
+	CIDRBlocks     []string
+	IPv6CIDRBlocks []string
+}
+
+// SecurityGroup is a virtual firewall controlling the traffic
+// that is allowed to reach an Instance.
+//
+// All outbound traffic is allowed, while inbound traffic
+// is restricted to the provided rules.
+type SecurityGroup struct {
+	name  string
+	rules []SecurityGroupRule
+}
+
+// NewSecurityGroup returns a new SecurityGroup instance.
+func NewSecurityGroup(name string, rules []SecurityGroupRule) *SecurityGroup {
+	return &SecurityGroup{
+		name:  name,
+		rules: rules,
+	}
+}
+
+// Type of the resource.
+func (sg *SecurityGroup) Type() string {
+	return "aws_security_group"
+}
+
+// Name of the resource.
+func (sg *SecurityGroup) Name() string {
+	return sg.name
+}
+
+// Properties associated with the resource.
+func (sg *SecurityGroup) Properties() *resource.Properties {
+	props := resource.NewProperties().
+		Prop("name", resource.NewStringProperty(sg.name)).
+		Prop("description", resource.NewStringProperty("Managed by BlockPropeller"))
+
+	for _, rule := range sg.rules {
+		var cidrBlocks []resource.Property
+		for _, cidr := range rule.CIDRBlocks {
+			cidrBlocks = append(cidrBlocks, resource.NewStringProperty(cidr))
+		}
+
+		ingress := resource.NewProperties().
+			Prop("protocol", resource.NewStringProperty(rule.Protocol)).
+			Prop("from_port", resource.NewIntegerProperty(rule.FromPort)).
+			Prop("to_port", resource.NewIntegerProperty(rule.ToPort)).
+			Prop("cidr_blocks", resource.NewArrayProperty(cidrBlocks...))
+
+		if len(rule.IPv6CIDRBlocks) > 0 {
+			var ipv6CIDRBlocks []resource.Property
+			for _, cidr := range rule.IPv6CIDRBlocks {
+				ipv6CIDRBlocks = append(ipv6CIDRBlocks, resource.NewStringProperty(cidr))
+			}
+
+			ingress.Prop("ipv6_cidr_blocks", resource.NewArrayProperty(ipv6CIDRBlocks...))
+		}
+
+		props.Block("ingress", ingress)
+	}
+
+	return props.Block("egress", resource.NewProperties().
+		Prop("protocol", resource.NewStringProperty("-1")).
+		Prop("from_port", resource.NewIntegerProperty(0)).
+		Prop("to_port", resource.NewIntegerProperty(0)).
+		Prop("cidr_blocks", resource.NewArrayProperty(resource.NewStringProperty("0.0.0.0/0"))))
+}
@@ -0,0 +1,65 @@
+package aws_test
+
+import (
+	"testing"
+
+	"blockpropeller.dev/blockpropeller/terraform/resource"
+	"blockpropeller.dev/blockpropeller/terraform/resource/aws"
+	"blockpropeller.dev/lib/test"
+)
+
+func TestSecurityGroupRendering(t *testing.T) {
+	securityGroup := aws.NewSecurityGroup("example", nil)
+
+	want := `resource "aws_security_group" "example" {
+  name="example"
+  description="Managed by BlockPropeller"
+  egress {
+    protocol="-1"
+    from_port=0
+    to_port=0
+    cidr_blocks=["0.0.0.0/0"]
+  }
+}
+`
+
+	got := resource.Render(securityGroup)
+
+	test.AssertStringsEqual(t, "SecurityGroup.Render()", got, want)
+}
+
+func TestSecurityGroupWithRules(t *testing.T) {
+	securityGroup := aws.NewSecurityGroup("example", []aws.SecurityGroupRule{
+		{Protocol: "tcp", FromPort: 22, ToPort: 22, CIDRBlocks: []string{"0.0.0.0/0"}, IPv6CIDRBlocks: []string{"::/0"}},
+		{Protocol: "tcp", FromPort: 27146, ToPort: 27147, CIDRBlocks: []string{"10.0.0.0/8", "192.168.1.1/32"}},
+	})
+
+	want := `resource "aws_security_group" "example" {
+  name="example"
+  description="Managed by BlockPropeller"
+  ingress {
+    protocol="tcp"
+    from_port=22
+    to_port=22
+    cidr_blocks=["0.0.0.0/0"]
+    ipv6_cidr_blocks=["::/0"]
+  }
+  ingress {
+    protocol="tcp"
+    from_port=27146
+    to_port=27147
+    cidr_blocks=["10.0.0.0/8", "192.168.1.1/32"]
+  }
+  egress {
+    protocol="-1"
+    from_port=0
+    to_port=0
+    cidr_blocks=["0.0.0.0/0"]
+  }
+}
+`
+
+	got := resource.Render(securityGroup)
+
+	test.AssertStringsEqual(t, "SecurityGroup.Render()", got, want)
+}
@@ -13,6 +13,7 @@ type Droplet struct {
 	region  string
 	size    string
 	sshKeys []*SSHKey
+	tags    []string
 }
 
 // NewDroplet returns a new instance of a Droplet.
@@ -20,6 +21,13 @@ func NewDroplet(name string, image string, region string, size string, sshKeys [
 	return &Droplet{name: name, image: image, region: region, size: size, sshKeys: sshKeys}
 }
 
+// SetTags sets the tags applied to the Droplet.
+func (d *Droplet) SetTags(tags []string) *Droplet {
+	d.tags = tags
+
+	return d
+}
+
 // Type of the resource.
 func (d *Droplet) Type() string {
 	return "digitalocean_droplet"
@@ -37,10 +45,25 @@ func (d *Droplet) Properties() *resource.Properties {
 		sshKeys = append(sshKeys, resource.ToID(sshKey))
 	}
 
-	return resource.NewProperties().
+	props := resource.NewProperties().
 		Prop("name", resource.NewStringProperty(d.name)).
 		Prop("image", resource.NewStringProperty(d.image)).
 		Prop("region", resource.NewStringProperty(d.region)).
 		Prop("size", resource.NewStringProperty(d.size)).
 		Prop("ssh_keys", resource.NewArrayProperty(sshKeys...))
+
+	if len(d.tags) > 0 {
+		props.Prop("tags", stringArray(d.tags))
+	}
+
+	return props
+}
+
+func stringArray(values []string) *resource.ArrayProperty {
+	var props []resource.Property
+	for _, value := range values {
+		props = append(props, resource.NewStringProperty(value))
+	}
+
+	return resource.NewArrayProperty(props...)
 }
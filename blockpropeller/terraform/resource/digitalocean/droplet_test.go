@@ -80,3 +80,26 @@ func TestDropletWithMultipleSSHKeys(t *testing.T) {
 	got := resource.Render(droplet)
 	test.AssertStringsEqual(t, "Droplet.Render()", got, want)
 }
+
+func TestDropletWithTags(t *testing.T) {
+	droplet := digitalocean.NewDroplet(
+		"example-0",
+		"ubuntu-18-04-x64",
+		"fra1",
+		"s-4vcpu-8gb",
+		nil,
+	).SetTags([]string{"env:prod", "team:nodes"})
+
+	want := `resource "digitalocean_droplet" "example-0" {
+  name="example-0"
+  image="ubuntu-18-04-x64"
+  region="fra1"
+  size="s-4vcpu-8gb"
+  ssh_keys=[]
+  tags=["env:prod", "team:nodes"]
+}
+`
+
+	got := resource.Render(droplet)
+	test.AssertStringsEqual(t, "Droplet.Render()", got, want)
+}
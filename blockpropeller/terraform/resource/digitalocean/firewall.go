@@ -0,0 +1,82 @@
+package digitalocean
+
+import (
+	"blockpropeller.dev/blockpropeller/terraform/resource"
+)
+
+// FirewallRule allows inbound traffic on a port, or a range of ports
+// formatted as `from-to`, coming from a set of source addresses.
+type FirewallRule struct {
+	Protocol        string
+	PortRange       string
+	SourceAddresses []string
+}
+
+// Firewall restricts the inbound traffic that is allowed to reach a set of Droplets.
+//
+// All outbound traffic is allowed, while inbound traffic
+// is restricted to the provided rules.
+type Firewall struct {
+	name     string
+	droplets []*Droplet
+	rules    []FirewallRule
+}
+
+// NewFirewall returns a new Firewall instance.
+func NewFirewall(name string, droplets []*Droplet, rules []FirewallRule) *Firewall {
+	return &Firewall{
+		name:     name,
+		droplets: droplets,
+		rules:    rules,
+	}
+}
+
+// Type of the resource.
+func (f *Firewall) Type() string {
+	return "digitalocean_firewall"
+}
+
+// Name of the resource.
+func (f *Firewall) Name() string {
+	return f.name
+}
+
+// Properties associated with the resource.
+func (f *Firewall) Properties() *resource.Properties {
+	var dropletIDs []resource.Property
+	for _, droplet := range f.droplets {
+		dropletIDs = append(dropletIDs, resource.ToID(droplet))
+	}
+
+	props := resource.NewProperties().
+		Prop("name", resource.NewStringProperty(f.name)).
+		Prop("droplet_ids", resource.NewArrayProperty(dropletIDs...))
+
+	for _, rule := range f.rules {
+		var sourceAddresses []resource.Property
+		for _, address := range rule.SourceAddresses {
+			sourceAddresses = append(sourceAddresses, resource.NewStringProperty(address))
+		}
+
+		props.Block("inbound_rule", resource.NewProperties().
+			Prop("protocol", resource.NewStringProperty(rule.Protocol)).
+			Prop("port_range", resource.NewStringProperty(rule.PortRange)).
+			Prop("source_addresses", resource.NewArrayProperty(sourceAddresses...)))
+	}
+
+	anyAddress := resource.NewArrayProperty(
+		resource.NewStringProperty("0.0.0.0/0"),
+		resource.NewStringProperty("::/0"),
+	)
+
+	for _, protocol := range []string{"tcp", "udp"} {
+		props.Block("outbound_rule", resource.NewProperties().
+			Prop("protocol", resource.NewStringProperty(protocol)).
+			Prop("port_range", resource.NewStringProperty("1-65535")).
+			Prop("destination_addresses", anyAddress))
+	}
+
+	return props.Block("outbound_rule", resource.NewProperties().
+		Prop("protocol", resource.NewStringProperty("icmp")).
+		Prop("destination_addresses", anyAddress))
+}
@@ -0,0 +1,51 @@
+package digitalocean_test
+
+import (
+	"testing"
+
+	"blockpropeller.dev/blockpropeller/terraform/resource"
+	"blockpropeller.dev/blockpropeller/terraform/resource/digitalocean"
+	"blockpropeller.dev/lib/test"
+)
+
+func TestFirewallRendering(t *testing.T) {
+	droplet := digitalocean.NewDroplet("example", "", "", "", nil)
+	firewall := digitalocean.NewFirewall("example", []*digitalocean.Droplet{droplet}, []digitalocean.FirewallRule{
+		{Protocol: "tcp", PortRange: "22", SourceAddresses: []string{"0.0.0.0/0", "::/0"}},
+		{Protocol: "tcp", PortRange: "27147", SourceAddresses: []string{"192.168.1.1/32"}},
+	})
+
+	want := `resource "digitalocean_firewall" "example" {
+  name="example"
+  droplet_ids=[digitalocean_droplet.example.id]
+  inbound_rule {
+    protocol="tcp"
+    port_range="22"
+    source_addresses=["0.0.0.0/0", "::/0"]
+  }
+  inbound_rule {
+    protocol="tcp"
+    port_range="27147"
+    source_addresses=["192.168.1.1/32"]
+  }
+  outbound_rule {
+    protocol="tcp"
+    port_range="1-65535"
+    destination_addresses=["0.0.0.0/0", "::/0"]
+  }
+  outbound_rule {
+    protocol="udp"
+    port_range="1-65535"
+    destination_addresses=["0.0.0.0/0", "::/0"]
+  }
+  outbound_rule {
+    protocol="icmp"
+    destination_addresses=["0.0.0.0/0", "::/0"]
+  }
+}
+`
+
+	got := resource.Render(firewall)
+
+	test.AssertStringsEqual(t, "Firewall.Render()", got, want)
+}
@@ -0,0 +1,71 @@
+package digitalocean
+
+import (
+	"blockpropeller.dev/blockpropeller/terraform/resource"
+)
+
+// FloatingIP is a static DigitalOcean IP address, which can be assigned to a Droplet in the same region.
+type FloatingIP struct {
+	name   string
+	region string
+}
+
+// NewFloatingIP returns a new FloatingIP instance.
+func NewFloatingIP(name string, region string) *FloatingIP {
+	return &FloatingIP{
+		name:   name,
+		region: region,
+	}
+}
+
+// Type of the resource.
+func (ip *FloatingIP) Type() string {
+	return "digitalocean_floating_ip"
+}
+
+// Name of the resource.
+func (ip *FloatingIP) Name() string {
+	return ip.name
+}
+
+// Properties associated with the resource.
+func (ip *FloatingIP) Properties() *resource.Properties {
+	return resource.NewProperties().
+		Prop("region", resource.NewStringProperty(ip.region))
+}
+
+// FloatingIPAssignment routes the traffic for a FloatingIP to a Droplet.
+//
+// The FloatingIP is referenced by its address, as it is managed
+// independently from the Droplet it is assigned to.
+type FloatingIPAssignment struct {
+	name      string
+	ipAddress string
+	droplet   *Droplet
+}
+
+// NewFloatingIPAssignment returns a new FloatingIPAssignment instance.
+func NewFloatingIPAssignment(name string, ipAddress string, droplet *Droplet) *FloatingIPAssignment {
+	return &FloatingIPAssignment{
+		name:      name,
+		ipAddress: ipAddress,
+		droplet:   droplet,
+	}
+}
+
+// Type of the resource.
+func (a *FloatingIPAssignment) Type() string {
+	return "digitalocean_floating_ip_assignment"
+}
+
+// Name of the resource.
+func (a *FloatingIPAssignment) Name() string {
+	return a.name
+}
+
+// Properties associated with the resource.
+func (a *FloatingIPAssignment) Properties() *resource.Properties {
+	return resource.NewProperties().
+		Prop("ip_address", resource.NewStringProperty(a.ipAddress)).
+		Prop("droplet_id", resource.ToID(a.droplet))
+}
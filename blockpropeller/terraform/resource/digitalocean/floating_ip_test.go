@@ -0,0 +1,37 @@
+package digitalocean_test
+
+import (
+	"testing"
+
+	"blockpropeller.dev/blockpropeller/terraform/resource"
+	"blockpropeller.dev/blockpropeller/terraform/resource/digitalocean"
+	"blockpropeller.dev/lib/test"
+)
+
+func TestFloatingIPRendering(t *testing.T) {
+	ip := digitalocean.NewFloatingIP("example", "fra1")
+
+	want := `resource "digitalocean_floating_ip" "example" {
+  region="fra1"
+}
+`
+
+	got := resource.Render(ip)
+
+	test.AssertStringsEqual(t, "FloatingIP.Render()", got, want)
+}
+
+func TestFloatingIPAssignmentRendering(t *testing.T) {
+	droplet := digitalocean.NewDroplet("example-0", "", "", "", nil)
+	assignment := digitalocean.NewFloatingIPAssignment("example-0", "203.0.113.10", droplet)
+
+	want := `resource "digitalocean_floating_ip_assignment" "example-0" {
+  ip_address="203.0.113.10"
+  droplet_id=digitalocean_droplet.example-0.id
+}
+`
+
+	got := resource.Render(assignment)
+
+	test.AssertStringsEqual(t, "FloatingIPAssignment.Render()", got, want)
+}
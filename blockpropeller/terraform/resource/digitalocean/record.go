@@ -0,0 +1,48 @@
+package digitalocean
+
+import (
+	"blockpropeller.dev/blockpropeller/terraform/resource"
+)
+
+// recordTTL is kept low, so records pointing to replaced Droplets expire quickly.
+const recordTTL = 300
+
+// Record is an A record in a domain managed through DigitalOcean DNS.
+//
+// The domain itself is owned by the user and must already be added to DigitalOcean.
+type Record struct {
+	name       string
+	domain     string
+	recordName string
+	value      resource.Property
+}
+
+// NewRecord returns a new Record instance, pointing recordName inside the domain to the value address.
+func NewRecord(name string, domain string, recordName string, value resource.Property) *Record {
+	return &Record{
+		name:       name,
+		domain:     domain,
+		recordName: recordName,
+		value:      value,
+	}
+}
+
+// Type of the resource.
+func (r *Record) Type() string {
+	return "digitalocean_record"
+}
+
+// Name of the resource.
+func (r *Record) Name() string {
+	return r.name
+}
+
+// Properties associated with the resource.
+func (r *Record) Properties() *resource.Properties {
+	return resource.NewProperties().
+		Prop("domain", resource.NewStringProperty(r.domain)).
+		Prop("type", resource.NewStringProperty("A")).
+		Prop("name", resource.NewStringProperty(r.recordName)).
+		Prop("value", r.value).
+		Prop("ttl", resource.NewIntegerProperty(recordTTL))
+}
@@ -0,0 +1,28 @@
+package digitalocean_test
+
+import (
+	"testing"
+
+	"blockpropeller.dev/blockpropeller/terraform/resource"
+	"blockpropeller.dev/blockpropeller/terraform/resource/digitalocean"
+	"blockpropeller.dev/lib/test"
+)
+
+func TestRecordRendering(t *testing.T) {
+	droplet := digitalocean.NewDroplet("example-0", "", "", "", nil)
+	record := digitalocean.NewRecord("example-0-fullnode-1", "nodes.example.com", "fullnode-1",
+		resource.ToPropSelector(droplet, "ipv4_address"))
+
+	want := `resource "digitalocean_record" "example-0-fullnode-1" {
+  domain="nodes.example.com"
+  type="A"
+  name="fullnode-1"
+  value=digitalocean_droplet.example-0.ipv4_address
+  ttl=300
+}
+`
+
+	got := resource.Render(record)
+
+	test.AssertStringsEqual(t, "Record.Render()", got, want)
+}
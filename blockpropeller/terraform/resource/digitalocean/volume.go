@@ -6,9 +6,11 @@ import (
 
 // Volume is a DigitalOcean volume that can be attached to a Droplet.
 type Volume struct {
-	name   string
-	region string
-	size   int
+	name     string
+	region   string
+	size     int
+	snapshot string
+	tags     []string
 }
 
 // NewVolume returns a new Volume instance.
@@ -20,6 +22,22 @@ func NewVolume(name string, region string, size int) *Volume {
 	}
 }
 
+// SetSnapshot creates the Volume from an existing VolumeSnapshot, identified by its ID.
+//
+// Volumes created from a snapshot keep the filesystem of the snapshot.
+func (v *Volume) SetSnapshot(snapshotID string) *Volume {
+	v.snapshot = snapshotID
+
+	return v
+}
+
+// SetTags sets the tags applied to the Volume.
+func (v *Volume) SetTags(tags []string) *Volume {
+	v.tags = tags
+
+	return v
+}
+
 // Type of the resource.
 func (v *Volume) Type() string {
 	return "digitalocean_volume"
@@ -32,11 +50,20 @@ func (v *Volume) Name() string {
 
 // Properties associated with the resource.
 func (v *Volume) Properties() *resource.Properties {
-	return resource.NewProperties().
+	props := resource.NewProperties().
 		Prop("name", resource.NewStringProperty("volume")).
 		Prop("region", resource.NewStringProperty(v.region)).
-		Prop("size", resource.NewIntegerProperty(v.size)).
-		Prop("initial_filesystem_type", resource.NewStringProperty("ext4"))
+		Prop("size", resource.NewIntegerProperty(v.size))
+
+	if len(v.tags) > 0 {
+		props.Prop("tags", stringArray(v.tags))
+	}
+
+	if v.snapshot != "" {
+		return props.Prop("snapshot_id", resource.NewStringProperty(v.snapshot))
+	}
+
+	return props.Prop("initial_filesystem_type", resource.NewStringProperty("ext4"))
 }
 
 // VolumeAttachment connects a Volume with a DigitalOcean Droplet.
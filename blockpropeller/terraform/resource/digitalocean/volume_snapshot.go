@@ -0,0 +1,39 @@
+package digitalocean
+
+import (
+	"blockpropeller.dev/blockpropeller/terraform/resource"
+)
+
+// VolumeSnapshot is a point-in-time snapshot of a DigitalOcean Volume.
+//
+// The Volume is referenced by its ID, as it is managed
+// independently from the snapshots taken of it.
+type VolumeSnapshot struct {
+	name     string
+	volumeID string
+}
+
+// NewVolumeSnapshot returns a new VolumeSnapshot instance.
+func NewVolumeSnapshot(name string, volumeID string) *VolumeSnapshot {
+	return &VolumeSnapshot{
+		name:     name,
+		volumeID: volumeID,
+	}
+}
+
+// Type of the resource.
+func (s *VolumeSnapshot) Type() string {
+	return "digitalocean_volume_snapshot"
+}
+
+// Name of the resource.
+func (s *VolumeSnapshot) Name() string {
+	return s.name
+}
+
+// Properties associated with the resource.
+func (s *VolumeSnapshot) Properties() *resource.Properties {
+	return resource.NewProperties().
+		Prop("name", resource.NewStringProperty(s.name)).
+		Prop("volume_id", resource.NewStringProperty(s.volumeID))
+}
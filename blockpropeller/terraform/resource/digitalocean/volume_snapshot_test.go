@@ -0,0 +1,23 @@
+package digitalocean_test
+
+import (
+	"testing"
+
+	"blockpropeller.dev/blockpropeller/terraform/resource"
+	"blockpropeller.dev/blockpropeller/terraform/resource/digitalocean"
+	"blockpropeller.dev/lib/test"
+)
+
+func TestVolumeSnapshotRendering(t *testing.T) {
+	snapshot := digitalocean.NewVolumeSnapshot("example-snapshot", "506f78a4-e098-11e5-ad9f-000f53306ae1")
+
+	want := `resource "digitalocean_volume_snapshot" "example-snapshot" {
+  name="example-snapshot"
+  volume_id="506f78a4-e098-11e5-ad9f-000f53306ae1"
+}
+`
+
+	got := resource.Render(snapshot)
+
+	test.AssertStringsEqual(t, "VolumeSnapshot.Render()", got, want)
+}
@@ -39,3 +39,19 @@ func TestVolumeAttachmentRendering(t *testing.T) {
 
 	test.AssertStringsEqual(t, "VolumeAttachment.Render()", got, want)
 }
+
+func TestVolumeFromSnapshotRendering(t *testing.T) {
+	volume := digitalocean.NewVolume("example", "fra1", 500).SetSnapshot("12345")
+
+	want := `resource "digitalocean_volume" "example" {
+  name="volume"
+  region="fra1"
+  size=500
+  snapshot_id="12345"
+}
+`
+
+	got := resource.Render(volume)
+
+	test.AssertStringsEqual(t, "Volume.Render()", got, want)
+}
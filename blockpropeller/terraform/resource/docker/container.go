@@ -0,0 +1,89 @@
+package docker
+
+import (
+	"blockpropeller.dev/blockpropeller/terraform/resource"
+)
+
+// Container is a Docker container acting as a server.
+//
+// In order to configure a container, properties such as `name` and `image` must be set.
+type Container struct {
+	name       string
+	image      string
+	privileged bool
+	env        []string
+	mounts     []*mount
+}
+
+type mount struct {
+	volume *Volume
+	path   string
+}
+
+// NewContainer returns a new Container instance.
+func NewContainer(name string, image string) *Container {
+	return &Container{
+		name:  name,
+		image: image,
+	}
+}
+
+// SetPrivileged runs the Container in privileged mode.
+func (c *Container) SetPrivileged(privileged bool) *Container {
+	c.privileged = privileged
+
+	return c
+}
+
+// AddEnv sets an environment variable inside the Container.
+func (c *Container) AddEnv(key string, value string) *Container {
+	c.env = append(c.env, key+"="+value)
+
+	return c
+}
+
+// MountVolume mounts the Volume to a path inside the Container.
+func (c *Container) MountVolume(volume *Volume, path string) *Container {
+	c.mounts = append(c.mounts, &mount{volume: volume, path: path})
+
+	return c
+}
+
+// Type of the resource.
+func (c *Container) Type() string {
+	return "docker_container"
+}
+
+// Name of the resource.
+func (c *Container) Name() string {
+	return c.name
+}
+
+// Properties associated with the resource.
+func (c *Container) Properties() *resource.Properties {
+	var env []resource.Property
+	for _, value := range c.env {
+		env = append(env, resource.NewStringProperty(value))
+	}
+
+	props := resource.NewProperties().
+		Prop("name", resource.NewStringProperty(c.name)).
+		Prop("image", resource.NewStringProperty(c.image)).
+		Prop("hostname", resource.NewStringProperty(c.name)).
+		Prop("must_run", resource.NewRawProperty("true"))
+
+	if c.privileged {
+		props.Prop("privileged", resource.NewRawProperty("true"))
+	}
+	if len(env) > 0 {
+		props.Prop("env", resource.NewArrayProperty(env...))
+	}
+
+	for _, m := range c.mounts {
+		props.Block("volumes", resource.NewProperties().
+			Prop("volume_name", resource.ToPropSelector(m.volume, "name")).
+			Prop("container_path", resource.NewStringProperty(m.path)))
+	}
+
+	return props
+}
@@ -0,0 +1,48 @@
+package docker_test
+
+import (
+	"testing"
+
+	"blockpropeller.dev/blockpropeller/terraform/resource"
+	"blockpropeller.dev/blockpropeller/terraform/resource/docker"
+	"blockpropeller.dev/lib/test"
+)
+
+func TestContainerRendering(t *testing.T) {
+	container := docker.NewContainer("example-0", "blockpropeller/local-host:latest")
+
+	want := `resource "docker_container" "example-0" {
+  name="example-0"
+  image="blockpropeller/local-host:latest"
+  hostname="example-0"
+  must_run=true
+}
+`
+
+	got := resource.Render(container)
+	test.AssertStringsEqual(t, "Container.Render()", got, want)
+}
+
+func TestContainerWithEnvAndVolumes(t *testing.T) {
+	container := docker.NewContainer("example-0", "blockpropeller/local-host:latest").
+		SetPrivileged(true).
+		AddEnv("SSH_AUTHORIZED_KEY", "ssh-rsa example").
+		MountVolume(docker.NewVolume("example-0-data"), "/mnt/volume")
+
+	want := `resource "docker_container" "example-0" {
+  name="example-0"
+  image="blockpropeller/local-host:latest"
+  hostname="example-0"
+  must_run=true
+  privileged=true
+  env=["SSH_AUTHORIZED_KEY=ssh-rsa example"]
+  volumes {
+    volume_name=docker_volume.example-0-data.name
+    container_path="/mnt/volume"
+  }
+}
+`
+
+	got := resource.Render(container)
+	test.AssertStringsEqual(t, "Container.Render()", got, want)
+}
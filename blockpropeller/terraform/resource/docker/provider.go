@@ -0,0 +1,32 @@
+package docker
+
+import (
+	"bytes"
+
+	"blockpropeller.dev/blockpropeller/terraform/resource"
+)
+
+// Provider configures Terraform to know which Docker daemon
+// to use for provisioning resources.
+type Provider struct {
+	props *resource.Properties
+}
+
+// NewProvider returns a new Provider instance.
+func NewProvider(host string) *Provider {
+	return &Provider{
+		props: resource.NewProperties().
+			Prop("host", resource.NewStringProperty(host)),
+	}
+}
+
+// Render satisfies the resource.Provider interface.
+func (p *Provider) Render() string {
+	var buf bytes.Buffer
+
+	buf.WriteString("provider \"docker\" {\n")
+	buf.WriteString(p.props.Indent(2).Render())
+	buf.WriteString("}\n")
+
+	return buf.String()
+}
@@ -0,0 +1,21 @@
+package docker_test
+
+import (
+	"testing"
+
+	"blockpropeller.dev/blockpropeller/terraform/resource/docker"
+	"blockpropeller.dev/lib/test"
+)
+
+func TestProviderRendering(t *testing.T) {
+	provider := docker.NewProvider("unix:///var/run/docker.sock")
+
+	want := `provider "docker" {
+  host="unix:///var/run/docker.sock"
+}
+`
+
+	got := provider.Render()
+
+	test.AssertStringsEqual(t, "Provider.Render()", got, want)
+}
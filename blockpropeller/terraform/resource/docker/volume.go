@@ -0,0 +1,33 @@
+package docker
+
+import (
+	"blockpropeller.dev/blockpropeller/terraform/resource"
+)
+
+// Volume is a named Docker volume that can be mounted into a Container.
+type Volume struct {
+	name string
+}
+
+// NewVolume returns a new Volume instance.
+func NewVolume(name string) *Volume {
+	return &Volume{
+		name: name,
+	}
+}
+
+// Type of the resource.
+func (v *Volume) Type() string {
+	return "docker_volume"
+}
+
+// Name of the resource.
+func (v *Volume) Name() string {
+	return v.name
+}
+
+// Properties associated with the resource.
+func (v *Volume) Properties() *resource.Properties {
+	return resource.NewProperties().
+		Prop("name", resource.NewStringProperty(v.name))
+}
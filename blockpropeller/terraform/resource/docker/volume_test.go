@@ -0,0 +1,21 @@
+package docker_test
+
+import (
+	"testing"
+
+	"blockpropeller.dev/blockpropeller/terraform/resource"
+	"blockpropeller.dev/blockpropeller/terraform/resource/docker"
+	"blockpropeller.dev/lib/test"
+)
+
+func TestVolumeRendering(t *testing.T) {
+	volume := docker.NewVolume("example-0-data")
+
+	want := `resource "docker_volume" "example-0-data" {
+  name="example-0-data"
+}
+`
+
+	got := resource.Render(volume)
+	test.AssertStringsEqual(t, "Volume.Render()", got, want)
+}
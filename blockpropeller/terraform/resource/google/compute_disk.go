@@ -0,0 +1,72 @@
+package google
+
+import (
+	"blockpropeller.dev/blockpropeller/terraform/resource"
+)
+
+// ComputeDisk is a persistent disk that can be attached to a ComputeInstance.
+type ComputeDisk struct {
+	name     string
+	zone     string
+	size     int
+	snapshot string
+	labels   map[string]string
+}
+
+// NewComputeDisk returns a new ComputeDisk instance.
+func NewComputeDisk(name string, zone string, size int) *ComputeDisk {
+	return &ComputeDisk{
+		name: name,
+		zone: zone,
+		size: size,
+	}
+}
+
+// SetSnapshot creates the ComputeDisk from an existing ComputeSnapshot, identified by its self link.
+func (d *ComputeDisk) SetSnapshot(snapshot string) *ComputeDisk {
+	d.snapshot = snapshot
+
+	return d
+}
+
+// SetLabels sets the labels applied to the ComputeDisk.
+func (d *ComputeDisk) SetLabels(labels map[string]string) *ComputeDisk {
+	d.labels = labels
+
+	return d
+}
+
+// Type of the resource.
+func (d *ComputeDisk) Type() string {
+	return "google_compute_disk"
+}
+
+// Name of the resource.
+func (d *ComputeDisk) Name() string {
+	return d.name
+}
+
+// Properties associated with the resource.
+func (d *ComputeDisk) Properties() *resource.Properties {
+	props := resource.NewProperties().
+		Prop("name", resource.NewStringProperty(d.name)).
+		Prop("type", resource.NewStringProperty("pd-standard")).
+		Prop("zone", resource.NewStringProperty(d.zone)).
+		Prop("size", resource.NewIntegerProperty(d.size))
+
+	if len(d.labels) > 0 {
+		props.Prop("labels", resource.NewStringMapProperty(d.labels))
+	}
+
+	if d.snapshot != "" {
+		props.Prop("snapshot", resource.NewStringProperty(d.snapshot))
+	}
+
+	return props
+}
+
+// DevicePath returns the path under which an attached ComputeDisk
+// with the provided device name is exposed on the ComputeInstance.
+func DevicePath(deviceName string) string {
+	return "/dev/disk/by-id/google-" + deviceName
+}
@@ -0,0 +1,67 @@
+package google_test
+
+import (
+	"testing"
+
+	"blockpropeller.dev/blockpropeller/terraform/resource"
+	"blockpropeller.dev/blockpropeller/terraform/resource/google"
+	"blockpropeller.dev/lib/test"
+)
+
+func TestComputeDiskRendering(t *testing.T) {
+	disk := google.NewComputeDisk("example", "europe-west3-a", 500)
+
+	want := `resource "google_compute_disk" "example" {
+  name="example"
+  type="pd-standard"
+  zone="europe-west3-a"
+  size=500
+}
+`
+
+	got := resource.Render(disk)
+
+	test.AssertStringsEqual(t, "ComputeDisk.Render()", got, want)
+}
+
+func TestComputeDiskWithLabelsRendering(t *testing.T) {
+	disk := google.NewComputeDisk("example", "europe-west3-a", 500).
+		SetLabels(map[string]string{"env": "prod"})
+
+	want := `resource "google_compute_disk" "example" {
+  name="example"
+  type="pd-standard"
+  zone="europe-west3-a"
+  size=500
+  labels={"env"="prod"}
+}
+`
+
+	got := resource.Render(disk)
+
+	test.AssertStringsEqual(t, "ComputeDisk.Render()", got, want)
+}
+
+func TestComputeDiskFromSnapshotRendering(t *testing.T) {
+	disk := google.NewComputeDisk("example", "europe-west3-a", 500).SetSnapshot("example-snapshot")
+
+	want := `resource "google_compute_disk" "example" {
+  name="example"
+  type="pd-standard"
+  zone="europe-west3-a"
+  size=500
+  snapshot="example-snapshot"
+}
+`
+
+	got := resource.Render(disk)
+
+	test.AssertStringsEqual(t, "ComputeDisk.Render()", got, want)
+}
+
+func TestDevicePath(t *testing.T) {
+	got := google.DevicePath("volume")
+	want := "/dev/disk/by-id/google-volume"
+
+	test.AssertStringsEqual(t, "DevicePath()", got, want)
+}
@@ -0,0 +1,70 @@
+package google
+
+import (
+	"blockpropeller.dev/blockpropeller/terraform/resource"
+)
+
+// FirewallAllow allows inbound traffic for a protocol on a set of ports,
+// or ranges of ports formatted as `from-to`.
+type FirewallAllow struct {
+	Protocol string
+	Ports    []string
+}
+
+// ComputeFirewall allows inbound traffic coming from a set of source ranges
+// to reach the ComputeInstances tagged with the target tag.
+//
+// Traffic that is not explicitly allowed is denied by the default network rules.
+type ComputeFirewall struct {
+	name         string
+	targetTag    string
+	sourceRanges []string
+	allows       []FirewallAllow
+}
+
+// NewComputeFirewall returns a new ComputeFirewall instance.
+func NewComputeFirewall(name string, targetTag string, sourceRanges []string, allows []FirewallAllow) *ComputeFirewall {
+	return &ComputeFirewall{
+		name:         name,
+		targetTag:    targetTag,
+		sourceRanges: sourceRanges,
+		allows:       allows,
+	}
+}
+
+// Type of the resource.
+func (f *ComputeFirewall) Type() string {
+	return "google_compute_firewall"
+}
+
+// Name of the resource.
+func (f *ComputeFirewall) Name() string {
+	return f.name
+}
+
+// Properties associated with the resource.
+func (f *ComputeFirewall) Properties() *resource.Properties {
+	var sourceRanges []resource.Property
+	for _, sourceRange := range f.sourceRanges {
+		sourceRanges = append(sourceRanges, resource.NewStringProperty(sourceRange))
+	}
+
+	props := resource.NewProperties().
+		Prop("name", resource.NewStringProperty(f.name)).
+		Prop("network", resource.NewStringProperty("default")).
+		Prop("target_tags", resource.NewArrayProperty(resource.NewStringProperty(f.targetTag))).
+		Prop("source_ranges", resource.NewArrayProperty(sourceRanges...))
+
+	for _, allow := range f.allows {
+		var ports []resource.Property
+		for _, port := range allow.Ports {
+			ports = append(ports, resource.NewStringProperty(port))
+		}
+
+		props.Block("allow", resource.NewProperties().
+			Prop("protocol", resource.NewStringProperty(allow.Protocol)).
+			Prop("ports", resource.NewArrayProperty(ports...)))
+	}
+
+	return props
+}
@@ -0,0 +1,31 @@
+package google_test
+
+import (
+	"testing"
+
+	"blockpropeller.dev/blockpropeller/terraform/resource"
+	"blockpropeller.dev/blockpropeller/terraform/resource/google"
+	"blockpropeller.dev/lib/test"
+)
+
+func TestComputeFirewallRendering(t *testing.T) {
+	firewall := google.NewComputeFirewall("example", "example-0", []string{"0.0.0.0/0"}, []google.FirewallAllow{
+		{Protocol: "tcp", Ports: []string{"22", "27146-27147"}},
+	})
+
+	want := `resource "google_compute_firewall" "example" {
+  name="example"
+  network="default"
+  target_tags=["example-0"]
+  source_ranges=["0.0.0.0/0"]
+  allow {
+    protocol="tcp"
+    ports=["22", "27146-27147"]
+  }
+}
+`
+
+	got := resource.Render(firewall)
+
+	test.AssertStringsEqual(t, "ComputeFirewall.Render()", got, want)
+}
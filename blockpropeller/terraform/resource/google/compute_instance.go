@@ -0,0 +1,108 @@
+package google
+
+import (
+	"strings"
+
+	"blockpropeller.dev/blockpropeller/terraform/resource"
+)
+
+// ComputeInstance is a Google Compute Engine definition of a server.
+//
+// Unlike other providers, Google Cloud has no managed SSH key resource.
+// SSH keys are instead injected through the `ssh-keys` instance metadata,
+// in the `user:key` format.
+type ComputeInstance struct {
+	name          string
+	machineType   string
+	zone          string
+	image         string
+	sshUser       string
+	sshPubKey     string
+	disks         []attachedDisk
+	startupScript string
+	labels        map[string]string
+}
+
+type attachedDisk struct {
+	deviceName string
+	disk       *ComputeDisk
+}
+
+// NewComputeInstance returns a new ComputeInstance instance.
+func NewComputeInstance(name string, machineType string, zone string, image string, sshUser string, sshPubKey string) *ComputeInstance {
+	return &ComputeInstance{
+		name:        name,
+		machineType: machineType,
+		zone:        zone,
+		image:       image,
+		sshUser:     sshUser,
+		sshPubKey:   sshPubKey,
+	}
+}
+
+// AttachDisk attaches a ComputeDisk to the instance, exposing it under the provided device name.
+func (i *ComputeInstance) AttachDisk(deviceName string, disk *ComputeDisk) *ComputeInstance {
+	i.disks = append(i.disks, attachedDisk{deviceName: deviceName, disk: disk})
+
+	return i
+}
+
+// SetStartupScript sets a script that is executed each time the instance boots.
+func (i *ComputeInstance) SetStartupScript(script string) *ComputeInstance {
+	i.startupScript = script
+
+	return i
+}
+
+// SetLabels sets the labels applied to the instance.
+func (i *ComputeInstance) SetLabels(labels map[string]string) *ComputeInstance {
+	i.labels = labels
+
+	return i
+}
+
+// Type of the resource.
+func (i *ComputeInstance) Type() string {
+	return "google_compute_instance"
+}
+
+// Name of the resource.
+func (i *ComputeInstance) Name() string {
+	return i.name
+}
+
+// Properties associated with the resource.
+func (i *ComputeInstance) Properties() *resource.Properties {
+	props := resource.NewProperties().
+		Prop("name", resource.NewStringProperty(i.name)).
+		Prop("machine_type", resource.NewStringProperty(i.machineType)).
+		Prop("zone", resource.NewStringProperty(i.zone)).
+		Prop("tags", resource.NewArrayProperty(resource.NewStringProperty(i.name))).
+		Block("boot_disk", resource.NewProperties().
+			Block("initialize_params", resource.NewProperties().
+				Prop("image", resource.NewStringProperty(i.image))))
+
+	if len(i.labels) > 0 {
+		props.Prop("labels", resource.NewStringMapProperty(i.labels))
+	}
+
+	for _, attached := range i.disks {
+		props.Block("attached_disk", resource.NewProperties().
+			Prop("source", resource.ToPropSelector(attached.disk, "self_link")).
+			Prop("device_name", resource.NewStringProperty(attached.deviceName)))
+	}
+
+	props.
+		Block("network_interface", resource.NewProperties().
+			Prop("network", resource.NewStringProperty("default")).
+			Block("access_config", resource.NewProperties())).
+		Prop("metadata", resource.NewMapProperty(map[string]resource.Property{
+			"ssh-keys": resource.NewStringProperty(i.sshUser + ":" + strings.Trim(i.sshPubKey, "\n")),
+		}))
+
+	if i.startupScript != "" {
+		props.Prop("metadata_startup_script", resource.NewHeredocProperty(i.startupScript))
+	}
+
+	return props
+}
@@ -0,0 +1,85 @@
+package google_test
+
+import (
+	"testing"
+
+	"blockpropeller.dev/blockpropeller/terraform/resource"
+	"blockpropeller.dev/blockpropeller/terraform/resource/google"
+	"blockpropeller.dev/lib/test"
+)
+
+func TestComputeInstanceRendering(t *testing.T) {
+	instance := google.NewComputeInstance(
+		"example-0",
+		"e2-standard-4",
+		"europe-west3-a",
+		"ubuntu-os-cloud/ubuntu-1804-lts",
+		"blockpropeller",
+		"ssh-rsa example@example.com\n",
+	)
+
+	want := `resource "google_compute_instance" "example-0" {
+  name="example-0"
+  machine_type="e2-standard-4"
+  zone="europe-west3-a"
+  tags=["example-0"]
+  boot_disk {
+    initialize_params {
+      image="ubuntu-os-cloud/ubuntu-1804-lts"
+    }
+  }
+  network_interface {
+    network="default"
+    access_config {
+    }
+  }
+  metadata={"ssh-keys"="blockpropeller:ssh-rsa example@example.com"}
+}
+`
+
+	got := resource.Render(instance)
+	test.AssertStringsEqual(t, "ComputeInstance.Render()", got, want)
+}
+
+func TestComputeInstanceWithDiskAndStartupScript(t *testing.T) {
+	disk := google.NewComputeDisk("example-0", "europe-west3-a", 500)
+
+	instance := google.NewComputeInstance(
+		"example-0",
+		"e2-standard-4",
+		"europe-west3-a",
+		"ubuntu-os-cloud/ubuntu-1804-lts",
+		"blockpropeller",
+		"ssh-rsa example@example.com",
+	).AttachDisk("volume", disk).SetStartupScript("#!/bin/sh\necho foo")
+
+	want := `resource "google_compute_instance" "example-0" {
+  name="example-0"
+  machine_type="e2-standard-4"
+  zone="europe-west3-a"
+  tags=["example-0"]
+  boot_disk {
+    initialize_params {
+      image="ubuntu-os-cloud/ubuntu-1804-lts"
+    }
+  }
+  attached_disk {
+    source=google_compute_disk.example-0.self_link
+    device_name="volume"
+  }
+  network_interface {
+    network="default"
+    access_config {
+    }
+  }
+  metadata={"ssh-keys"="blockpropeller:ssh-rsa example@example.com"}
+  metadata_startup_script=<<EOF
+#!/bin/sh
+echo foo
+EOF
+}
+`
+
+	got := resource.Render(instance)
+	test.AssertStringsEqual(t, "ComputeInstance.Render()", got, want)
+}
@@ -0,0 +1,42 @@
+package google
+
+import (
+	"blockpropeller.dev/blockpropeller/terraform/resource"
+)
+
+// ComputeSnapshot is a point-in-time snapshot of a ComputeDisk.
+//
+// The disk is referenced by its self link, as it is managed
+// independently from the snapshots taken of it.
+type ComputeSnapshot struct {
+	name       string
+	zone       string
+	sourceDisk string
+}
+
+// NewComputeSnapshot returns a new ComputeSnapshot instance.
+func NewComputeSnapshot(name string, zone string, sourceDisk string) *ComputeSnapshot {
+	return &ComputeSnapshot{
+		name:       name,
+		zone:       zone,
+		sourceDisk: sourceDisk,
+	}
+}
+
+// Type of the resource.
+func (s *ComputeSnapshot) Type() string {
+	return "google_compute_snapshot"
+}
+
+// Name of the resource.
+func (s *ComputeSnapshot) Name() string {
+	return s.name
+}
+
+// Properties associated with the resource.
+func (s *ComputeSnapshot) Properties() *resource.Properties {
+	return resource.NewProperties().
+		Prop("name", resource.NewStringProperty(s.name)).
+		Prop("zone", resource.NewStringProperty(s.zone)).
+		Prop("source_disk", resource.NewStringProperty(s.sourceDisk))
+}
@@ -0,0 +1,24 @@
+package google_test
+
+import (
+	"testing"
+
+	"blockpropeller.dev/blockpropeller/terraform/resource"
+	"blockpropeller.dev/blockpropeller/terraform/resource/google"
+	"blockpropeller.dev/lib/test"
+)
+
+func TestComputeSnapshotRendering(t *testing.T) {
+	snapshot := google.NewComputeSnapshot("example-snapshot", "europe-west3-a", "projects/example/zones/europe-west3-a/disks/example")
+
+	want := `resource "google_compute_snapshot" "example-snapshot" {
+  name="example-snapshot"
+  zone="europe-west3-a"
+  source_disk="projects/example/zones/europe-west3-a/disks/example"
+}
+`
+
+	got := resource.Render(snapshot)
+
+	test.AssertStringsEqual(t, "ComputeSnapshot.Render()", got, want)
+}
@@ -0,0 +1,36 @@
+package google
+
+import (
+	"bytes"
+
+	"blockpropeller.dev/blockpropeller/terraform/resource"
+)
+
+// Provider configures Terraform to know how to authenticate
+// Google Cloud requests for provisioning resources.
+type Provider struct {
+	props *resource.Properties
+}
+
+// NewProvider returns a new Provider instance.
+//
+// Credentials are the contents of a service account JSON key file.
+func NewProvider(credentials string, project string, region string) *Provider {
+	return &Provider{
+		props: resource.NewProperties().
+			Prop("credentials", resource.NewHeredocProperty(credentials)).
+			Prop("project", resource.NewStringProperty(project)).
+			Prop("region", resource.NewStringProperty(region)),
+	}
+}
+
+// Render satisfies the resource.Provider interface.
+func (p *Provider) Render() string {
+	var buf bytes.Buffer
+
+	buf.WriteString("provider \"google\" {\n")
+	buf.WriteString(p.props.Indent(2).Render())
+	buf.WriteString("}\n")
+
+	return buf.String()
+}
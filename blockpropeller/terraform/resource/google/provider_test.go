@@ -0,0 +1,25 @@
+package google_test
+
+import (
+	"testing"
+
+	"blockpropeller.dev/blockpropeller/terraform/resource/google"
+	"blockpropeller.dev/lib/test"
+)
+
+func TestProviderRendering(t *testing.T) {
+	provider := google.NewProvider(`{"type": "service_account"}`, "example-project", "europe-west3")
+
+	want := `provider "google" {
+  credentials=<<EOF
+{"type": "service_account"}
+EOF
+  project="example-project"
+  region="europe-west3"
+}
+`
+
+	got := provider.Render()
+
+	test.AssertStringsEqual(t, "Provider.Render()", got, want)
+}
@@ -0,0 +1,61 @@
+package hcloud
+
+import (
+	"blockpropeller.dev/blockpropeller/terraform/resource"
+)
+
+// FirewallRule allows inbound traffic on a port, or a range of ports
+// formatted as `from-to`, coming from a set of source IP ranges.
+type FirewallRule struct {
+	Protocol  string
+	Port      string
+	SourceIPs []string
+}
+
+// Firewall restricts the inbound traffic that is allowed to reach a Server.
+//
+// All outbound traffic is allowed, while inbound traffic
+// is restricted to the provided rules.
+type Firewall struct {
+	name  string
+	rules []FirewallRule
+}
+
+// NewFirewall returns a new Firewall instance.
+func NewFirewall(name string, rules []FirewallRule) *Firewall {
+	return &Firewall{
+		name:  name,
+		rules: rules,
+	}
+}
+
+// Type of the resource.
+func (f *Firewall) Type() string {
+	return "hcloud_firewall"
+}
+
+// Name of the resource.
+func (f *Firewall) Name() string {
+	return f.name
+}
+
+// Properties associated with the resource.
+func (f *Firewall) Properties() *resource.Properties {
+	props := resource.NewProperties().
+		Prop("name", resource.NewStringProperty(f.name))
+
+	for _, rule := range f.rules {
+		var sourceIPs []resource.Property
+		for _, sourceIP := range rule.SourceIPs {
+			sourceIPs = append(sourceIPs, resource.NewStringProperty(sourceIP))
+		}
+
+		props.Block("rule", resource.NewProperties().
+			Prop("direction", resource.NewStringProperty("in")).
+			Prop("protocol", resource.NewStringProperty(rule.Protocol)).
+			Prop("port", resource.NewStringProperty(rule.Port)).
+			Prop("source_ips", resource.NewArrayProperty(sourceIPs...)))
+	}
+
+	return props
+}
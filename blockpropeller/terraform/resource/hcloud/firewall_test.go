@@ -0,0 +1,37 @@
+package hcloud_test
+
+import (
+	"testing"
+
+	"blockpropeller.dev/blockpropeller/terraform/resource"
+	"blockpropeller.dev/blockpropeller/terraform/resource/hcloud"
+	"blockpropeller.dev/lib/test"
+)
+
+func TestFirewallRendering(t *testing.T) {
+	firewall := hcloud.NewFirewall("example", []hcloud.FirewallRule{
+		{Protocol: "tcp", Port: "22", SourceIPs: []string{"0.0.0.0/0", "::/0"}},
+		{Protocol: "tcp", Port: "27146-27147", SourceIPs: []string{"192.168.1.1/32"}},
+	})
+
+	want := `resource "hcloud_firewall" "example" {
+  name="example"
+  rule {
+    direction="in"
+    protocol="tcp"
+    port="22"
+    source_ips=["0.0.0.0/0", "::/0"]
+  }
+  rule {
+    direction="in"
+    protocol="tcp"
+    port="27146-27147"
+    source_ips=["192.168.1.1/32"]
+  }
+}
+`
+
+	got := resource.Render(firewall)
+
+	test.AssertStringsEqual(t, "Firewall.Render()", got, want)
+}
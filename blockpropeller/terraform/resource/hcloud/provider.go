@@ -0,0 +1,32 @@
+package hcloud
+
+import (
+	"bytes"
+
+	"blockpropeller.dev/blockpropeller/terraform/resource"
+)
+
+// Provider configures Terraform to know how to authenticate
+// Hetzner Cloud requests for provisioning resources.
+type Provider struct {
+	props *resource.Properties
+}
+
+// NewProvider returns a new Provider instance.
+func NewProvider(token string) *Provider {
+	return &Provider{
+		props: resource.NewProperties().
+			Prop("token", resource.NewStringProperty(token)),
+	}
+}
+
+// Render satisfies the resource.Provider interface.
+func (p *Provider) Render() string {
+	var buf bytes.Buffer
+
+	buf.WriteString("provider \"hcloud\" {\n")
+	buf.WriteString(p.props.Indent(2).Render())
+	buf.WriteString("}\n")
+
+	return buf.String()
+}
@@ -0,0 +1,21 @@
+package hcloud_test
+
+import (
+	"testing"
+
+	"blockpropeller.dev/blockpropeller/terraform/resource/hcloud"
+	"blockpropeller.dev/lib/test"
+)
+
+func TestProviderRendering(t *testing.T) {
+	provider := hcloud.NewProvider("foobar")
+
+	want := `provider "hcloud" {
+  token="foobar"
+}
+`
+
+	got := provider.Render()
+
+	test.AssertStringsEqual(t, "Provider.Render()", got, want)
+}
@@ -0,0 +1,86 @@
+package hcloud
+
+import (
+	"blockpropeller.dev/blockpropeller/terraform/resource"
+)
+
+// Server is a Hetzner Cloud definition of a server.
+//
+// In order to configure a server, properties such as `image`, `location` and `server_type` must be set.
+type Server struct {
+	name       string
+	image      string
+	location   string
+	serverType string
+	sshKeys    []*SSHKey
+	firewalls  []*Firewall
+	userData   string
+	labels     map[string]string
+}
+
+// NewServer returns a new Server instance.
+func NewServer(name string, image string, location string, serverType string, sshKeys []*SSHKey, firewalls []*Firewall) *Server {
+	return &Server{
+		name:       name,
+		image:      image,
+		location:   location,
+		serverType: serverType,
+		sshKeys:    sshKeys,
+		firewalls:  firewalls,
+	}
+}
+
+// SetUserData sets a script that is executed when the Server boots for the first time.
+func (s *Server) SetUserData(userData string) *Server {
+	s.userData = userData
+
+	return s
+}
+
+// SetLabels sets the labels applied to the Server.
+func (s *Server) SetLabels(labels map[string]string) *Server {
+	s.labels = labels
+
+	return s
+}
+
+// Type of the resource.
+func (s *Server) Type() string {
+	return "hcloud_server"
+}
+
+// Name of the resource.
+func (s *Server) Name() string {
+	return s.name
+}
+
+// Properties associated with the resource.
+func (s *Server) Properties() *resource.Properties {
+	var sshKeys []resource.Property
+	for _, sshKey := range s.sshKeys {
+		sshKeys = append(sshKeys, resource.ToID(sshKey))
+	}
+
+	var firewalls []resource.Property
+	for _, firewall := range s.firewalls {
+		firewalls = append(firewalls, resource.ToID(firewall))
+	}
+
+	props := resource.NewProperties().
+		Prop("name", resource.NewStringProperty(s.name)).
+		Prop("image", resource.NewStringProperty(s.image)).
+		Prop("location", resource.NewStringProperty(s.location)).
+		Prop("server_type", resource.NewStringProperty(s.serverType)).
+		Prop("ssh_keys", resource.NewArrayProperty(sshKeys...)).
+		Prop("firewall_ids", resource.NewArrayProperty(firewalls...))
+
+	if len(s.labels) > 0 {
+		props.Prop("labels", resource.NewStringMapProperty(s.labels))
+	}
+
+	if s.userData != "" {
+		props.Prop("user_data", resource.NewHeredocProperty(s.userData))
+	}
+
+	return props
+}
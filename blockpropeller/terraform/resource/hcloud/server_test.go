@@ -0,0 +1,86 @@
+package hcloud_test
+
+import (
+	"testing"
+
+	"blockpropeller.dev/blockpropeller/terraform/resource"
+	"blockpropeller.dev/blockpropeller/terraform/resource/hcloud"
+	"blockpropeller.dev/lib/test"
+)
+
+func TestServerRendering(t *testing.T) {
+	server := hcloud.NewServer(
+		"example-0",
+		"ubuntu-18.04",
+		"fsn1",
+		"cx41",
+		nil,
+		nil,
+	)
+
+	want := `resource "hcloud_server" "example-0" {
+  name="example-0"
+  image="ubuntu-18.04"
+  location="fsn1"
+  server_type="cx41"
+  ssh_keys=[]
+  firewall_ids=[]
+}
+`
+
+	got := resource.Render(server)
+	test.AssertStringsEqual(t, "Server.Render()", got, want)
+}
+
+func TestServerWithSSHKeysFirewallsAndUserData(t *testing.T) {
+	server := hcloud.NewServer(
+		"example-0",
+		"ubuntu-18.04",
+		"fsn1",
+		"cx41",
+		[]*hcloud.SSHKey{hcloud.NewSSHKey("default", "ssh-rsa example@example.com")},
+		[]*hcloud.Firewall{hcloud.NewFirewall("example-0", nil)},
+	).SetUserData("#!/bin/sh\necho foo")
+
+	want := `resource "hcloud_server" "example-0" {
+  name="example-0"
+  image="ubuntu-18.04"
+  location="fsn1"
+  server_type="cx41"
+  ssh_keys=[hcloud_ssh_key.default.id]
+  firewall_ids=[hcloud_firewall.example-0.id]
+  user_data=<<EOF
+#!/bin/sh
+echo foo
+EOF
+}
+`
+
+	got := resource.Render(server)
+	test.AssertStringsEqual(t, "Server.Render()", got, want)
+}
+
+func TestServerWithLabels(t *testing.T) {
+	server := hcloud.NewServer(
+		"example-0",
+		"ubuntu-18.04",
+		"fsn1",
+		"cx41",
+		nil,
+		nil,
+	).SetLabels(map[string]string{"team": "nodes", "env": "prod"})
+
+	want := `resource "hcloud_server" "example-0" {
+  name="example-0"
+  image="ubuntu-18.04"
+  location="fsn1"
+  server_type="cx41"
+  ssh_keys=[]
+  firewall_ids=[]
+  labels={"env"="prod", "team"="nodes"}
+}
+`
+
+	got := resource.Render(server)
+	test.AssertStringsEqual(t, "Server.Render()", got, want)
+}
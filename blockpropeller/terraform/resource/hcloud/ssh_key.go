@@ -0,0 +1,42 @@
+package hcloud
+
+import (
+	"strings"
+
+	"blockpropeller.dev/blockpropeller/terraform/resource"
+)
+
+// SSHKey is a managed Hetzner Cloud SSH Key.
+//
+// An SSHKey resource can then be referenced from
+// a Server in order to gain SSH access to the
+// provisioned machine.
+type SSHKey struct {
+	name   string
+	pubKey string
+}
+
+// NewSSHKey returns a new SSHKey instance.
+func NewSSHKey(name string, pubKey string) *SSHKey {
+	return &SSHKey{
+		name:   name,
+		pubKey: pubKey,
+	}
+}
+
+// Type of the resource.
+func (k *SSHKey) Type() string {
+	return "hcloud_ssh_key"
+}
+
+// Name of the resource.
+func (k *SSHKey) Name() string {
+	return k.name
+}
+
+// Properties associated with the resource.
+func (k *SSHKey) Properties() *resource.Properties {
+	return resource.NewProperties().
+		Prop("name", resource.NewStringProperty(k.name)).
+		Prop("public_key", resource.NewStringProperty(strings.Trim(k.pubKey, "\n")))
+}
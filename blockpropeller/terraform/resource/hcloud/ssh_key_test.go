@@ -0,0 +1,23 @@
+package hcloud_test
+
+import (
+	"testing"
+
+	"blockpropeller.dev/blockpropeller/terraform/resource"
+	"blockpropeller.dev/blockpropeller/terraform/resource/hcloud"
+	"blockpropeller.dev/lib/test"
+)
+
+func TestSSHKeyRendering(t *testing.T) {
+	sshKey := hcloud.NewSSHKey("example", "ssh-rsa example@example.com")
+
+	want := `resource "hcloud_ssh_key" "example" {
+  name="example"
+  public_key="ssh-rsa example@example.com"
+}
+`
+
+	got := resource.Render(sshKey)
+
+	test.AssertStringsEqual(t, "SSHKey.Render()", got, want)
+}
@@ -0,0 +1,100 @@
+package hcloud
+
+import (
+	"blockpropeller.dev/blockpropeller/terraform/resource"
+)
+
+// Volume is a Hetzner Cloud volume that can be attached to a Server.
+type Volume struct {
+	name     string
+	location string
+	size     int
+	labels   map[string]string
+}
+
+// NewVolume returns a new Volume instance.
+func NewVolume(name string, location string, size int) *Volume {
+	return &Volume{
+		name:     name,
+		location: location,
+		size:     size,
+	}
+}
+
+// SetLabels sets the labels applied to the Volume.
+func (v *Volume) SetLabels(labels map[string]string) *Volume {
+	v.labels = labels
+
+	return v
+}
+
+// Type of the resource.
+func (v *Volume) Type() string {
+	return "hcloud_volume"
+}
+
+// Name of the resource.
+func (v *Volume) Name() string {
+	return v.name
+}
+
+// Properties associated with the resource.
+func (v *Volume) Properties() *resource.Properties {
+	props := resource.NewProperties().
+		Prop("name", resource.NewStringProperty(v.name)).
+		Prop("location", resource.NewStringProperty(v.location)).
+		Prop("size", resource.NewIntegerProperty(v.size)).
+		Prop("format", resource.NewStringProperty("ext4"))
+
+	if len(v.labels) > 0 {
+		props.Prop("labels", resource.NewStringMapProperty(v.labels))
+	}
+
+	return props
+}
+
+// DevicePath returns the path under which the Volume is exposed
+// on the Server it is attached to.
+//
+// The returned path contains a Terraform interpolation sequence,
+// and is only meaningful inside resource definitions.
+func (v *Volume) DevicePath() string {
+	return "/dev/disk/by-id/scsi-0HC_Volume_${" + resource.ToID(v).Render() + "}"
+}
+
+// VolumeAttachment connects a Volume with a Hetzner Cloud Server.
+//
+// Hetzner is able to mount attached volumes automatically, but only under
+// a path derived from the volume ID, so mounting is left to the Server instead.
+type VolumeAttachment struct {
+	name   string
+	server *Server
+	volume *Volume
+}
+
+// NewVolumeAttachment returns a new VolumeAttachment instance.
+func NewVolumeAttachment(name string, server *Server, volume *Volume) *VolumeAttachment {
+	return &VolumeAttachment{
+		name:   name,
+		server: server,
+		volume: volume,
+	}
+}
+
+// Type of the resource.
+func (a *VolumeAttachment) Type() string {
+	return "hcloud_volume_attachment"
+}
+
+// Name of the resource.
+func (a *VolumeAttachment) Name() string {
+	return a.name
+}
+
+// Properties associated with the resource.
+func (a *VolumeAttachment) Properties() *resource.Properties {
+	return resource.NewProperties().
+		Prop("server_id", resource.ToID(a.server)).
+		Prop("volume_id", resource.ToID(a.volume)).
+		Prop("automount", resource.NewRawProperty("false"))
+}
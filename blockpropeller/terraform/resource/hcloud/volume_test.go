@@ -0,0 +1,51 @@
+package hcloud_test
+
+import (
+	"testing"
+
+	"blockpropeller.dev/blockpropeller/terraform/resource"
+	"blockpropeller.dev/blockpropeller/terraform/resource/hcloud"
+	"blockpropeller.dev/lib/test"
+)
+
+func TestVolumeRendering(t *testing.T) {
+	volume := hcloud.NewVolume("example", "fsn1", 500)
+
+	want := `resource "hcloud_volume" "example" {
+  name="example"
+  location="fsn1"
+  size=500
+  format="ext4"
+}
+`
+
+	got := resource.Render(volume)
+
+	test.AssertStringsEqual(t, "Volume.Render()", got, want)
+}
+
+func TestVolumeDevicePath(t *testing.T) {
+	volume := hcloud.NewVolume("example", "fsn1", 500)
+
+	got := volume.DevicePath()
+	want := "/dev/disk/by-id/scsi-0HC_Volume_${hcloud_volume.example.id}"
+
+	test.AssertStringsEqual(t, "Volume.DevicePath()", got, want)
+}
+
+func TestVolumeAttachmentRendering(t *testing.T) {
+	server := hcloud.NewServer("example-0", "", "", "", nil, nil)
+	volume := hcloud.NewVolume("volume-500", "fsn1", 500)
+	attachment := hcloud.NewVolumeAttachment("example-volume-att", server, volume)
+
+	want := `resource "hcloud_volume_attachment" "example-volume-att" {
+  server_id=hcloud_server.example-0.id
+  volume_id=hcloud_volume.volume-500.id
+  automount=false
+}
+`
+
+	got := resource.Render(attachment)
+
+	test.AssertStringsEqual(t, "VolumeAttachment.Render()", got, want)
+}
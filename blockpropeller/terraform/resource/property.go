@@ -2,6 +2,7 @@ package resource
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 )
 
@@ -123,3 +124,80 @@ func (prop ArrayProperty) Render() string {
 
 	return fmt.Sprintf("[%s]", strings.Join(values, ", "))
 }
+
+// MapProperty is a wrapper for a set of key value pairs.
+//
+// In Terraform, maps are defined as a sequence of quoted keys and their values,
+// surrounded by curly braces, and delimited with a comma.
+//
+// For example: `map[string]Property{"Name": "example"}`
+// 	   becomes: `{"Name"="example"}`
+//
+// Keys are rendered in a sorted order, so that rendering the same map
+// always produces the same output.
+type MapProperty struct {
+	values map[string]Property
+}
+
+// NewMapProperty returns a new instance of a MapProperty.
+func NewMapProperty(values map[string]Property) *MapProperty {
+	return &MapProperty{
+		values: values,
+	}
+}
+
+// NewStringMapProperty returns a new instance of a MapProperty holding string values.
+func NewStringMapProperty(values map[string]string) *MapProperty {
+	props := make(map[string]Property, len(values))
+	for key, value := range values {
+		props[key] = NewStringProperty(value)
+	}
+
+	return NewMapProperty(props)
+}
+
+// Render prepares the property into a format appropriate for resource generation.
+//
+// Each property is responsible for rendering itself in a valid Terraform syntax.
+func (prop MapProperty) Render() string {
+	var keys []string
+	for key := range prop.values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var values []string
+	for _, key := range keys {
+		values = append(values, fmt.Sprintf("\"%s\"=%s", key, prop.values[key].Render()))
+	}
+
+	return fmt.Sprintf("{%s}", strings.Join(values, ", "))
+}
+
+// HeredocProperty is a wrapper for a multi-line string constant,
+// such as a script passed to a server on boot.
+//
+// Terraform interpolation sequences inside the value are evaluated,
+// so literal `${` sequences need to be escaped as `$${`.
+//
+// For example: `echo "Hello World!"`
+// 	   becomes: `<<EOF
+// echo "Hello World!"
+// EOF`
+type HeredocProperty struct {
+	value string
+}
+
+// NewHeredocProperty returns a new instance of a HeredocProperty.
+func NewHeredocProperty(value string) *HeredocProperty {
+	return &HeredocProperty{
+		value: value,
+	}
+}
+
+// Render prepares the property into a format appropriate for resource generation.
+//
+// Each property is responsible for rendering itself in a valid Terraform syntax.
+func (prop HeredocProperty) Render() string {
+	return fmt.Sprintf("<<EOF\n%s\nEOF", strings.TrimRight(prop.value, "\n"))
+}
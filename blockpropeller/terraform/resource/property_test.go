@@ -44,6 +44,17 @@ func TestPropertyRendering(t *testing.T) {
 				NewIntegerProperty(1),
 			),
 		), "[1, \"2\", res.name.id, [0, 1]]"},
+		{NewMapProperty(nil), "{}"},
+		{NewMapProperty(map[string]Property{
+			"Name": NewStringProperty("example"),
+		}), "{\"Name\"=\"example\"}"},
+		{NewMapProperty(map[string]Property{
+			"team": NewStringProperty("infra"),
+			"env":  NewStringProperty("prod"),
+			"id":   NewRawProperty("res.name.id"),
+		}), "{\"env\"=\"prod\", \"id\"=res.name.id, \"team\"=\"infra\"}"},
+		{NewHeredocProperty("echo foo"), "<<EOF\necho foo\nEOF"},
+		{NewHeredocProperty("#!/bin/sh\necho foo\n"), "<<EOF\n#!/bin/sh\necho foo\nEOF"},
 	}
 	for _, testCase := range tests {
 		t.Run(testCase.want, func(t *testing.T) {
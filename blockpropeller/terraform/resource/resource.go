@@ -45,6 +45,11 @@ func ToPropSelector(res Resource, name string) Property {
 	return NewRawProperty(fmt.Sprintf("%s.%s.%s", res.Type(), FormatName(res.Name()), name))
 }
 
+// ToDataPropSelector returns a `Property` containing the pointer for a specific property of a data source.
+func ToDataPropSelector(res Resource, name string) Property {
+	return NewRawProperty(fmt.Sprintf("data.%s.%s.%s", res.Type(), FormatName(res.Name()), name))
+}
+
 // FormatName converts the resource name into a format suitable for use in Terraform resource names.
 func FormatName(name string) string {
 	return strings.ReplaceAll(name, " ", "")
@@ -64,12 +69,28 @@ func Render(res Resource) string {
 	return buf.String()
 }
 
+// RenderDataSource transforms a provided Resource into a textual Terraform data source definition.
+//
+// Data sources are looked up by Terraform instead of being created, and can be
+// referenced from other resources by using the `ToDataPropSelector` function.
+func RenderDataSource(res Resource) string {
+	var buf bytes.Buffer
+
+	buf.WriteString(fmt.Sprintf("data \"%s\" \"%s\" {\n",
+		res.Type(), FormatName(res.Name())))
+	buf.WriteString(res.Properties().Indent(2).Render())
+	buf.WriteString("}\n")
+
+	return buf.String()
+}
+
 // namedProperty binds a name to a particular Resource.
 //
 // An array of namedProperties is later used as the body of a `Resource`.
 type namedProperty struct {
-	name string
-	prop Property
+	name  string
+	prop  Property
+	block *Properties
 }
 
 // Properties represents the body of a `Resource`.
@@ -92,7 +113,18 @@ func NewProperties() *Properties {
 // Prop returns a fluent interface to provide better developer experience
 // while instantiating `Properties` structures.
 func (p *Properties) Prop(name string, prop Property) *Properties {
-	p.props = append(p.props, namedProperty{name, prop})
+	p.props = append(p.props, namedProperty{name: name, prop: prop})
+
+	return p
+}
+
+// Block appends a new nested block to an existing set of `Properties`.
+//
+// Nested blocks are used by resources that group their configuration,
+// such as the ingress rules of a security group. The same block name
+// can be appended multiple times.
+func (p *Properties) Block(name string, block *Properties) *Properties {
+	p.props = append(p.props, namedProperty{name: name, block: block})
 
 	return p
 }
@@ -114,10 +146,19 @@ func (p *Properties) Indent(indent int) *Properties {
 func (p Properties) Render() string {
 	var buf bytes.Buffer
 
+	indent := strings.Repeat(" ", p.indent)
+
 	for _, nprop := range p.props {
+		if nprop.block != nil {
+			buf.WriteString(fmt.Sprintf("%s%s {\n", indent, nprop.name))
+			buf.WriteString(nprop.block.Indent(p.indent + 2).Render())
+			buf.WriteString(fmt.Sprintf("%s}\n", indent))
+			continue
+		}
+
 		buf.WriteString(fmt.Sprintf(
 			"%s%s=%s\n",
-			strings.Repeat(" ", p.indent),
+			indent,
 			nprop.name,
 			nprop.prop.Render(),
 		))
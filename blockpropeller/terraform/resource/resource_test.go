@@ -53,6 +53,27 @@ func TestResourceRendering(t *testing.T) {
 	test.AssertStringsEqual(t, "Render()", got, want)
 }
 
+func TestResourceToDataPropSelector(t *testing.T) {
+	res := testResource{}
+
+	got := resource.ToDataPropSelector(res, "id").Render()
+	want := "data.test_resource.test-0.id"
+
+	test.AssertStringsEqual(t, "ToDataPropSelector()", got, want)
+}
+
+func TestDataSourceRendering(t *testing.T) {
+	res := testResource{}
+
+	got := resource.RenderDataSource(res)
+	want := `data "test_resource" "test-0" {
+  name="test-0"
+}
+`
+
+	test.AssertStringsEqual(t, "RenderDataSource()", got, want)
+}
+
 func TestPropertiesRendering(t *testing.T) {
 	props := resource.NewProperties().
 		Prop("foo", resource.NewStringProperty("foo")).
@@ -79,3 +100,33 @@ func TestPropertiesRenderingWithIndentation(t *testing.T) {
 
 	test.AssertStringsEqual(t, "props.Render with indentation", got, want)
 }
+
+func TestPropertiesRenderingWithBlocks(t *testing.T) {
+	props := resource.NewProperties().
+		Prop("foo", resource.NewStringProperty("foo")).
+		Block("rule", resource.NewProperties().
+			Prop("port", resource.NewIntegerProperty(22)).
+			Block("source", resource.NewProperties().
+				Prop("cidr", resource.NewStringProperty("0.0.0.0/0")))).
+		Block("rule", resource.NewProperties().
+			Prop("port", resource.NewIntegerProperty(80))).
+		Block("empty", resource.NewProperties()).
+		Indent(2)
+
+	got := props.Render()
+	want := `  foo="foo"
+  rule {
+    port=22
+    source {
+      cidr="0.0.0.0/0"
+    }
+  }
+  rule {
+    port=80
+  }
+  empty {
+  }
+`
+
+	test.AssertStringsEqual(t, "props.Render with blocks", got, want)
+}
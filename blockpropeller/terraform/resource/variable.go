@@ -0,0 +1,40 @@
+package resource
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// Variable defines an input variable which can be referenced
+// from resources through ToVariable.
+type Variable struct {
+	Name    string
+	Default Property
+}
+
+// NewVariable returns a new Variable instance.
+func NewVariable(name string, def Property) *Variable {
+	return &Variable{
+		Name:    name,
+		Default: def,
+	}
+}
+
+// Render the variable into Terraform syntax.
+func (v *Variable) Render() string {
+	var buf bytes.Buffer
+
+	props := NewProperties().
+		Prop("default", v.Default)
+
+	buf.WriteString(fmt.Sprintf("variable \"%s\" {\n", FormatName(v.Name)))
+	buf.WriteString(props.Indent(2).Render())
+	buf.WriteString("}\n")
+
+	return buf.String()
+}
+
+// ToVariable returns a `Property` containing the pointer to the named variable.
+func ToVariable(name string) Property {
+	return NewRawProperty(fmt.Sprintf("var.%s", FormatName(name)))
+}
@@ -0,0 +1,21 @@
+package resource_test
+
+import (
+	"testing"
+
+	"blockpropeller.dev/blockpropeller/terraform/resource"
+	"blockpropeller.dev/lib/test"
+)
+
+func TestVariableRendering(t *testing.T) {
+	variable := resource.NewVariable("region", resource.NewStringProperty("eu-central-1"))
+
+	got := variable.Render()
+	want := `variable "region" {
+  default="eu-central-1"
+}
+`
+
+	test.AssertStringsEqual(t, "Variable.Render()", got, want)
+	test.AssertStringsEqual(t, "ToVariable()", resource.ToVariable("region").Render(), "var.region")
+}
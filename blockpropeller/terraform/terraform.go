@@ -1,23 +1,31 @@
 package terraform
 
 import (
+	"context"
 	"os"
 	"os/exec"
 	"strings"
+	"time"
 
 	"blockpropeller.dev/lib/log"
 	"github.com/pkg/errors"
 )
 
+var (
+	// ErrTimeout is returned when a Terraform command does not finish in the configured time.
+	ErrTimeout = errors.New("terraform timed out")
+)
+
 // Terraform is a wrapper around a terraform command line utility
 // exposing the ability to plan and provision Terraform resources.
 type Terraform struct {
-	path string
+	path    string
+	timeout time.Duration
 }
 
 // ConfigureTerraform returns a configured Terraform instance.
 func ConfigureTerraform(cfg *Config) *Terraform {
-	return New(cfg.Path)
+	return New(cfg.Path).WithTimeout(cfg.Timeout)
 }
 
 // New returns a new Terraform instance.
@@ -27,6 +35,15 @@ func New(path string) *Terraform {
 	}
 }
 
+// WithTimeout limits the duration of every executed command.
+//
+// Commands running longer than the timeout are killed. A zero timeout disables the limit.
+func (tf *Terraform) WithTimeout(timeout time.Duration) *Terraform {
+	tf.timeout = timeout
+
+	return tf
+}
+
 // Init executes terraform init in the provided workspace.
 //
 // terraform init must be called before terraform plan or apply.
@@ -121,13 +138,24 @@ func (tf *Terraform) Version() (string, error) {
 
 // exec wraps the interaction with the underlying binary.
 func (tf *Terraform) exec(dir string, args ...string) ([]byte, error) {
-	cmd := exec.Command(tf.path, args...)
+	ctx := context.Background()
+	if tf.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, tf.timeout)
+		defer cancel()
+	}
+
+	cmd := exec.CommandContext(ctx, tf.path, args...)
 	cmd.Env = append(os.Environ(), "TF_IN_AUTOMATION=true")
 	if dir != "" {
 		cmd.Dir = dir
 	}
 
 	output, err := cmd.Output()
+	if ctx.Err() == context.DeadlineExceeded {
+		return nil, errors.Wrapf(ErrTimeout, "execution timeout for [terraform %s] after %s",
+			strings.Join(args, " "), tf.timeout)
+	}
 	if execErr, ok := err.(*exec.ExitError); ok {
 		return nil, errors.Wrapf(execErr,
 			"execution error for [terraform %s]: %s",
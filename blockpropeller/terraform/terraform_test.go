@@ -1,15 +1,25 @@
 package terraform_test
 
 import (
+	"os"
+	"strings"
 	"testing"
+	"time"
 
 	"blockpropeller.dev/blockpropeller/terraform"
 	"blockpropeller.dev/lib/test"
+	"blockpropeller.dev/lib/test/fakebin"
 	"github.com/blang/semver"
+	"github.com/pkg/errors"
 )
 
 func TestTerraformIsExecutable(t *testing.T) {
-	tf := terraform.New("/usr/local/bin/terraform")
+	const binPath = "/usr/local/bin/terraform"
+	if _, err := os.Stat(binPath); os.IsNotExist(err) {
+		t.Skip("terraform is not installed")
+	}
+
+	tf := terraform.New(binPath)
 
 	version, err := tf.Version()
 	test.CheckErr(t, "get terraform version", err)
@@ -29,3 +39,64 @@ func TestApplySimpleResource(t *testing.T) {
 func TestCleanupAfterSelf(t *testing.T) {
 	//@TODO: Test terraform cleaning up after itself.
 }
+
+func TestTerraformCommands(t *testing.T) {
+	bin := fakebin.New(t, "terraform")
+	defer test.Close(t, bin)
+
+	bin.On("version").Stdout("Terraform v0.12.12\n")
+	bin.On("output").Stdout("  203.0.113.10\n")
+
+	tf := terraform.New(bin.Path())
+
+	workspace, err := terraform.NewWorkspace()
+	test.CheckErr(t, "create workspace", err)
+	defer test.Close(t, workspace)
+
+	version, err := tf.Version()
+	test.CheckErr(t, "Version()", err)
+	test.AssertStringsEqual(t, "Version()", version, "0.12.12")
+
+	test.CheckErr(t, "Init()", tf.Init(workspace))
+	test.CheckErr(t, "Plan()", tf.Plan(workspace))
+	test.CheckErr(t, "Apply()", tf.Apply(workspace))
+
+	ip, err := tf.Output(workspace, "ip-address")
+	test.CheckErr(t, "Output()", err)
+	test.AssertStringsEqual(t, "Output()", ip, "203.0.113.10")
+
+	test.CheckErr(t, "Destroy()", tf.Destroy(workspace))
+
+	want := []string{
+		"version",
+		"init -no-color -input=false",
+		"plan -out=tfplan -no-color -input=false",
+		"apply -no-color -input=false tfplan",
+		"output -no-color ip-address",
+		"destroy -no-color -auto-approve",
+	}
+	calls := bin.Calls()
+	test.AssertIntsEqual(t, "terraform calls", len(calls), len(want))
+	for i, call := range calls {
+		test.AssertStringsEqual(t, "terraform call", strings.Join(call, " "), want[i])
+	}
+}
+
+func TestTerraformTimeout(t *testing.T) {
+	bin := fakebin.New(t, "terraform")
+	defer test.Close(t, bin)
+
+	bin.On("apply").Hang()
+
+	tf := terraform.New(bin.Path()).WithTimeout(100 * time.Millisecond)
+
+	workspace, err := terraform.NewWorkspace()
+	test.CheckErr(t, "create workspace", err)
+	defer test.Close(t, workspace)
+
+	err = tf.Apply(workspace)
+	test.CheckErrExists(t, "Apply()", err)
+	if errors.Cause(err) != terraform.ErrTimeout {
+		t.Fatalf("Apply(): got %v, want timeout", err)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sort"
 
 	"blockpropeller.dev/blockpropeller/encryption"
 	"blockpropeller.dev/blockpropeller/terraform/resource"
@@ -30,6 +31,11 @@ type WorkspaceSnapshot struct {
 	TerraformState       string `gorm:"column:state;type:text"`
 }
 
+const (
+	definitionsFile = "main.tf"
+	overrideFile    = "override.tf"
+)
+
 // Workspace handles laying out and a set of `Resource`s
 // in a format compatible with the Terraform command line
 // interface.
@@ -39,8 +45,11 @@ type WorkspaceSnapshot struct {
 type Workspace struct {
 	workDir string
 
+	fileName  string
 	readOnly  bool
+	override  bool
 	flushed   bool
+	variables map[string]string
 	items     []Renderer
 	resources []resource.Resource
 }
@@ -55,7 +64,8 @@ func NewWorkspace() (*Workspace, error) {
 	}
 
 	return &Workspace{
-		workDir: workDir,
+		workDir:  workDir,
+		fileName: definitionsFile,
 
 		flushed: true,
 	}, nil
@@ -75,7 +85,7 @@ func RestoreWorkspace(snap *WorkspaceSnapshot) (*Workspace, error) {
 		return nil, errors.Wrap(err, "decrypt terraform definitions")
 	}
 
-	err = ioutil.WriteFile(filepath.Join(snap.WorkspacePath, "main.tf"), definitions, 0655)
+	err = ioutil.WriteFile(filepath.Join(snap.WorkspacePath, definitionsFile), definitions, 0655)
 	if err != nil {
 		return nil, errors.Wrap(err, "restore terraform definitions")
 	}
@@ -102,10 +112,44 @@ func RestoreWorkspace(snap *WorkspaceSnapshot) (*Workspace, error) {
 
 	return &Workspace{
 		workDir:  snap.WorkspacePath,
+		fileName: definitionsFile,
 		readOnly: true,
 	}, nil
 }
 
+// RestoreState writes the Terraform state inside a WorkspaceSnapshot to the Workspace,
+// so the Workspace definitions are applied against already existing infrastructure.
+func (w *Workspace) RestoreState(snap *WorkspaceSnapshot) error {
+	state, err := encryption.Decrypt([]byte(snap.TerraformState))
+	if err != nil {
+		return errors.Wrap(err, "decrypt terraform state")
+	}
+
+	err = ioutil.WriteFile(filepath.Join(w.workDir, "terraform.tfstate"), state, 0644)
+	if err != nil {
+		return errors.Wrap(err, "restore terraform state")
+	}
+
+	return nil
+}
+
+// Override returns a Workspace whose items are flushed into the Terraform override file
+// of this Workspace, merging them into the existing definitions by block name.
+//
+// This allows restored Workspaces to pick up configuration that changed after they were
+// snapshotted, such as rotated provider credentials. Variables are not overridden,
+// so values decided when the Workspace was created are kept.
+// Overrides are not part of Workspace snapshots.
+func (w *Workspace) Override() *Workspace {
+	return &Workspace{
+		workDir:  w.workDir,
+		fileName: overrideFile,
+		override: true,
+
+		flushed: true,
+	}
+}
+
 // Add adds provided items to the list of items that should be flushed to the workspace.
 //
 // Add method does not automatically flush the items. A separate Flush method should be called
@@ -123,6 +167,27 @@ func (w *Workspace) Add(items ...Renderer) {
 	w.items = append(w.items, items...)
 }
 
+// SetVariable sets the default value of an input variable, overriding any previously set value.
+//
+// Variables allow values to be decided after the items referencing them have been added.
+// Variables set on an override Workspace are ignored.
+func (w *Workspace) SetVariable(name string, value string) {
+	if w.readOnly {
+		panic("workspace is readonly")
+	}
+
+	if w.override {
+		return
+	}
+
+	if w.variables == nil {
+		w.variables = make(map[string]string)
+	}
+
+	w.flushed = false
+	w.variables[name] = value
+}
+
 // AddResource acts in the same way as the Add method, only difference being that it
 // accepts a variadic number of Resources instead of items.
 //
@@ -152,6 +217,17 @@ func (w *Workspace) Flush() error {
 
 	var buf bytes.Buffer
 
+	var variableNames []string
+	for name := range w.variables {
+		variableNames = append(variableNames, name)
+	}
+	sort.Strings(variableNames)
+
+	for _, name := range variableNames {
+		buf.WriteString(resource.NewVariable(name, resource.NewStringProperty(w.variables[name])).Render())
+		buf.WriteRune('\n')
+	}
+
 	for _, item := range w.items {
 		buf.WriteString(item.Render())
 		buf.WriteRune('\n')
@@ -162,7 +238,7 @@ func (w *Workspace) Flush() error {
 		buf.WriteRune('\n')
 	}
 
-	err := ioutil.WriteFile(filepath.Join(w.workDir, "main.tf"), buf.Bytes(), 0644)
+	err := ioutil.WriteFile(filepath.Join(w.workDir, w.fileName), buf.Bytes(), 0644)
 	if err != nil {
 		return errors.Wrap(err, "write items to disk")
 	}
@@ -181,7 +257,7 @@ func (w *Workspace) Snapshot() (*WorkspaceSnapshot, error) {
 		WorkspacePath: w.WorkDir(),
 	}
 
-	definitions, err := ioutil.ReadFile(filepath.Join(w.workDir, "main.tf"))
+	definitions, err := ioutil.ReadFile(filepath.Join(w.workDir, definitionsFile))
 	if err != nil && !os.IsNotExist(err) {
 		return nil, errors.Wrap(err, "read terraform definitions")
 	}
@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"testing"
 
+	"blockpropeller.dev/blockpropeller/encryption"
 	"blockpropeller.dev/blockpropeller/terraform"
 	"blockpropeller.dev/blockpropeller/terraform/resource/digitalocean"
 	"blockpropeller.dev/lib/test"
@@ -82,3 +83,81 @@ func TestWorkspaceCleanupOnClose(t *testing.T) {
 		return
 	}
 }
+
+func TestWorkspaceFlushesVariables(t *testing.T) {
+	w, err := terraform.NewWorkspace()
+	test.CheckErr(t, "NewWorkspace()", err)
+	defer test.Close(t, w)
+
+	w.SetVariable("region", "fra1")
+	w.Add(digitalocean.NewProvider("test"))
+	w.SetVariable("region", "ams3")
+
+	err = w.Flush()
+	test.CheckErr(t, "Workspace.Flush()", err)
+
+	got, err := ioutil.ReadFile(filepath.Join(w.WorkDir(), "main.tf"))
+	test.CheckErr(t, "read main.tf", err)
+
+	want := `variable "region" {
+  default="ams3"
+}
+
+provider "digitalocean" {
+  token="test"
+}
+
+`
+	test.AssertStringsEqual(t, "main.tf contents", string(got), want)
+}
+
+func TestWorkspaceOverrideKeepsDefinitions(t *testing.T) {
+	encryption.Init("test")
+
+	w, err := terraform.NewWorkspace()
+	test.CheckErr(t, "NewWorkspace()", err)
+	defer test.Close(t, w)
+
+	w.SetVariable("region", "fra1")
+	w.Add(digitalocean.NewProvider("old-token"))
+
+	err = w.Flush()
+	test.CheckErr(t, "Workspace.Flush()", err)
+
+	snap, err := w.Snapshot()
+	test.CheckErr(t, "Workspace.Snapshot()", err)
+
+	restored, err := terraform.RestoreWorkspace(snap)
+	test.CheckErr(t, "RestoreWorkspace()", err)
+
+	override := restored.Override()
+	override.SetVariable("region", "ams3")
+	override.Add(digitalocean.NewProvider("new-token"))
+
+	err = override.Flush()
+	test.CheckErr(t, "override Workspace.Flush()", err)
+
+	got, err := ioutil.ReadFile(filepath.Join(w.WorkDir(), "override.tf"))
+	test.CheckErr(t, "read override.tf", err)
+
+	want := `provider "digitalocean" {
+  token="new-token"
+}
+
+`
+	test.AssertStringsEqual(t, "override.tf contents", string(got), want)
+
+	definitions, err := ioutil.ReadFile(filepath.Join(w.WorkDir(), "main.tf"))
+	test.CheckErr(t, "read main.tf", err)
+
+	want = `variable "region" {
+  default="fra1"
+}
+
+provider "digitalocean" {
+  token="old-token"
+}
+
+`
+	test.AssertStringsEqual(t, "main.tf contents", string(definitions), want)
+}
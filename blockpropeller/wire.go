@@ -3,6 +3,9 @@ package blockpropeller
 import (
 	"blockpropeller.dev/blockpropeller/account"
 	"blockpropeller.dev/blockpropeller/ansible"
+	"blockpropeller.dev/blockpropeller/audit"
+	"blockpropeller.dev/blockpropeller/byo"
+	"blockpropeller.dev/blockpropeller/oidc"
 	"blockpropeller.dev/blockpropeller/provision"
 	"blockpropeller.dev/blockpropeller/statemachine/middleware"
 	"blockpropeller.dev/blockpropeller/terraform"
@@ -14,14 +17,17 @@ import (
 // AppSet keeps a set of all app level dependencies.
 var AppSet = wire.NewSet(
 	account.Set,
+	audit.Set,
+	oidc.Set,
 	terraform.Set,
 	ansible.Set,
+	byo.Set,
 	provision.Set,
 	middleware.Set,
 
 	ProvideConfig,
 	wire.FieldsOf(new(*Config),
-		"Log", "Server", "WorkerPool", "Database", "JWT", "Encryption", "Terraform", "Ansible"),
+		"Log", "Server", "WorkerPool", "HealthCheck", "Snapshots", "Firewall", "Database", "JWT", "Encryption", "Mail", "OIDC", "Terraform", "Ansible"),
 	NewApp,
 )
 
@@ -8,12 +8,17 @@ package blockpropeller
 import (
 	"blockpropeller.dev/blockpropeller/account"
 	"blockpropeller.dev/blockpropeller/ansible"
+	"blockpropeller.dev/blockpropeller/audit"
+	"blockpropeller.dev/blockpropeller/byo"
 	"blockpropeller.dev/blockpropeller/database"
 	"blockpropeller.dev/blockpropeller/database/transaction"
+	"blockpropeller.dev/blockpropeller/encryption"
 	"blockpropeller.dev/blockpropeller/httpserver"
 	middleware2 "blockpropeller.dev/blockpropeller/httpserver/middleware"
 	"blockpropeller.dev/blockpropeller/httpserver/routes"
 	"blockpropeller.dev/blockpropeller/infrastructure"
+	"blockpropeller.dev/blockpropeller/mail"
+	"blockpropeller.dev/blockpropeller/oidc"
 	"blockpropeller.dev/blockpropeller/provision"
 	"blockpropeller.dev/blockpropeller/statemachine/middleware"
 	"blockpropeller.dev/blockpropeller/terraform"
@@ -35,29 +40,52 @@ func SetupDatabaseApp() (*App, func(), error) {
 		return nil, nil, err
 	}
 	accountRepository := database.NewAccountRepository(db)
+	sessionRepository := database.NewSessionRepository(db)
 	jwtConfig := config.JWT
 	tokenService := account.ConfigureTokenService(jwtConfig)
-	service := account.NewService(accountRepository, tokenService)
+	recoveryCodeRepository := database.NewRecoveryCodeRepository(db)
+	twoFactorService := account.NewTwoFactorService(accountRepository, recoveryCodeRepository, sessionRepository)
+	service := account.NewService(accountRepository, sessionRepository, tokenService, twoFactorService)
+	oneTimeTokenRepository := database.NewOneTimeTokenRepository(db)
+	apiKeyRepository := database.NewAPIKeyRepository(db)
+	apiKeyService := account.NewAPIKeyService(accountRepository, apiKeyRepository)
+	mailConfig := config.Mail
+	mailer := mail.ConfigureMailer(mailConfig)
+	credentialService := account.NewCredentialService(db, accountRepository, oneTimeTokenRepository, service, apiKeyService, mailer, mailConfig)
+	organizationRepository := database.NewOrganizationRepository(db)
+	membershipRepository := database.NewMembershipRepository(db)
+	invitationRepository := database.NewInvitationRepository(db)
+	organizationService := account.NewOrganizationService(organizationRepository, membershipRepository, invitationRepository)
+	auditRepository := database.NewAuditRepository(db)
+	auditService := audit.NewService(auditRepository)
+	projectRepository := database.NewProjectRepository(db)
 	providerSettingsRepository := database.NewProviderSettingsRepository(db)
 	serverRepository := database.NewServerRepository(db)
 	jobRepository := database.NewJobRepository(db)
 	deploymentRepository := database.NewDeploymentRepository(db)
 	jobScheduler := provision.NewJobScheduler(db, jobRepository, serverRepository, deploymentRepository)
+	firewallConfig := config.Firewall
 	terraformConfig := config.Terraform
 	terraformTerraform := terraform.ConfigureTerraform(terraformConfig)
-	serverProvisioner := provision.NewServerProvisioner(terraformTerraform, serverRepository)
+	bootstrapper := byo.NewBootstrapper()
+	serverProvisioner := provision.NewServerProvisioner(firewallConfig, terraformTerraform, bootstrapper, serverRepository, providerSettingsRepository)
 	stepProvisionServer := provision.NewStepProvisionServer(serverProvisioner, jobRepository)
 	ansibleConfig := config.Ansible
 	ansibleAnsible := ansible.ConfigureAnsible(ansibleConfig)
 	deploymentProvisioner := provision.NewDeploymentProvisioner(ansibleAnsible, deploymentRepository)
 	stepProvisionDeployment := provision.NewStepProvisionDeployment(deploymentProvisioner, jobRepository)
+	stepResizeVolume := provision.NewStepResizeVolume(serverProvisioner, jobRepository)
+	stepGrowVolume := provision.NewStepGrowVolume(deploymentProvisioner, jobRepository)
+	stepUpdateFirewall := provision.NewStepUpdateFirewall(serverProvisioner, jobRepository)
+	stepUpdateLabels := provision.NewStepUpdateLabels(serverProvisioner, jobRepository, deploymentRepository)
 	failureMiddleware := provision.NewFailureMiddleware(jobRepository)
 	transactional := middleware.NewTransactional(db)
-	jobStateMachine := provision.ConfigureJobStateMachine(stepProvisionServer, stepProvisionDeployment, failureMiddleware, transactional)
-	serverDestroyer := provision.NewServerDestroyer(terraformTerraform, db, serverRepository, deploymentRepository)
+	jobStateMachine := provision.ConfigureJobStateMachine(stepProvisionServer, stepProvisionDeployment, stepResizeVolume, stepGrowVolume, stepUpdateFirewall, stepUpdateLabels, failureMiddleware, transactional)
+	serverDestroyer := provision.NewServerDestroyer(terraformTerraform, deploymentProvisioner, db, serverRepository, deploymentRepository, providerSettingsRepository)
 	provisioner := provision.NewProvisioner(jobStateMachine, terraformTerraform, serverDestroyer)
+	encryptionRotator := database.NewEncryptionRotator(db)
 	consoleLogger := log.NewConsoleLogger(logConfig)
-	app := NewApp(config, accountRepository, service, providerSettingsRepository, serverRepository, jobRepository, jobScheduler, provisioner, consoleLogger)
+	app := NewApp(config, accountRepository, service, credentialService, twoFactorService, organizationService, auditService, projectRepository, providerSettingsRepository, serverRepository, jobRepository, jobScheduler, provisioner, encryptionRotator, consoleLogger)
 	return app, func() {
 		cleanup()
 	}, nil
@@ -73,45 +101,99 @@ func SetupDatabaseServer() (*AppServer, func(), error) {
 		return nil, nil, err
 	}
 	accountRepository := database.NewAccountRepository(db)
+	sessionRepository := database.NewSessionRepository(db)
 	jwtConfig := config.JWT
 	tokenService := account.ConfigureTokenService(jwtConfig)
-	service := account.NewService(accountRepository, tokenService)
+	recoveryCodeRepository := database.NewRecoveryCodeRepository(db)
+	twoFactorService := account.NewTwoFactorService(accountRepository, recoveryCodeRepository, sessionRepository)
+	service := account.NewService(accountRepository, sessionRepository, tokenService, twoFactorService)
+	oneTimeTokenRepository := database.NewOneTimeTokenRepository(db)
+	apiKeyRepository := database.NewAPIKeyRepository(db)
+	apiKeyService := account.NewAPIKeyService(accountRepository, apiKeyRepository)
+	mailConfig := config.Mail
+	mailer := mail.ConfigureMailer(mailConfig)
+	credentialService := account.NewCredentialService(db, accountRepository, oneTimeTokenRepository, service, apiKeyService, mailer, mailConfig)
+	organizationRepository := database.NewOrganizationRepository(db)
+	membershipRepository := database.NewMembershipRepository(db)
+	invitationRepository := database.NewInvitationRepository(db)
+	organizationService := account.NewOrganizationService(organizationRepository, membershipRepository, invitationRepository)
+	auditRepository := database.NewAuditRepository(db)
+	auditService := audit.NewService(auditRepository)
+	projectRepository := database.NewProjectRepository(db)
 	providerSettingsRepository := database.NewProviderSettingsRepository(db)
 	serverRepository := database.NewServerRepository(db)
 	jobRepository := database.NewJobRepository(db)
 	deploymentRepository := database.NewDeploymentRepository(db)
 	jobScheduler := provision.NewJobScheduler(db, jobRepository, serverRepository, deploymentRepository)
+	firewallConfig := config.Firewall
 	terraformConfig := config.Terraform
 	terraformTerraform := terraform.ConfigureTerraform(terraformConfig)
-	serverProvisioner := provision.NewServerProvisioner(terraformTerraform, serverRepository)
+	bootstrapper := byo.NewBootstrapper()
+	serverProvisioner := provision.NewServerProvisioner(firewallConfig, terraformTerraform, bootstrapper, serverRepository, providerSettingsRepository)
 	stepProvisionServer := provision.NewStepProvisionServer(serverProvisioner, jobRepository)
 	ansibleConfig := config.Ansible
 	ansibleAnsible := ansible.ConfigureAnsible(ansibleConfig)
 	deploymentProvisioner := provision.NewDeploymentProvisioner(ansibleAnsible, deploymentRepository)
 	stepProvisionDeployment := provision.NewStepProvisionDeployment(deploymentProvisioner, jobRepository)
+	stepResizeVolume := provision.NewStepResizeVolume(serverProvisioner, jobRepository)
+	stepGrowVolume := provision.NewStepGrowVolume(deploymentProvisioner, jobRepository)
+	stepUpdateFirewall := provision.NewStepUpdateFirewall(serverProvisioner, jobRepository)
+	stepUpdateLabels := provision.NewStepUpdateLabels(serverProvisioner, jobRepository, deploymentRepository)
 	failureMiddleware := provision.NewFailureMiddleware(jobRepository)
 	transactional := middleware.NewTransactional(db)
-	jobStateMachine := provision.ConfigureJobStateMachine(stepProvisionServer, stepProvisionDeployment, failureMiddleware, transactional)
-	serverDestroyer := provision.NewServerDestroyer(terraformTerraform, db, serverRepository, deploymentRepository)
+	jobStateMachine := provision.ConfigureJobStateMachine(stepProvisionServer, stepProvisionDeployment, stepResizeVolume, stepGrowVolume, stepUpdateFirewall, stepUpdateLabels, failureMiddleware, transactional)
+	serverDestroyer := provision.NewServerDestroyer(terraformTerraform, deploymentProvisioner, db, serverRepository, deploymentRepository, providerSettingsRepository)
 	provisioner := provision.NewProvisioner(jobStateMachine, terraformTerraform, serverDestroyer)
+	encryptionRotator := database.NewEncryptionRotator(db)
 	consoleLogger := log.NewConsoleLogger(logConfig)
-	app := NewApp(config, accountRepository, service, providerSettingsRepository, serverRepository, jobRepository, jobScheduler, provisioner, consoleLogger)
+	app := NewApp(config, accountRepository, service, credentialService, twoFactorService, organizationService, auditService, projectRepository, providerSettingsRepository, serverRepository, jobRepository, jobScheduler, provisioner, encryptionRotator, consoleLogger)
 	serverConfig := config.Server
-	authenticationMiddleware := middleware2.NewAuthenticationMiddleware(service)
-	authentication := routes.NewAuthenticationRoutes(service)
-	routesAccount := routes.NewAccountRoutes(accountRepository)
-	providerSettings := routes.NewProviderSettingsRoutes(providerSettingsRepository)
-	routesProvision := routes.NewProvisionRoutes(jobScheduler, jobRepository, providerSettingsRepository)
-	routesServer := routes.NewServerRoutes(serverDestroyer, deploymentProvisioner, serverRepository)
-	deployment := routes.NewDeploymentRoutes(deploymentRepository)
+	authenticationMiddleware := middleware2.NewAuthenticationMiddleware(service, apiKeyService)
+	authorizationMiddleware := middleware2.NewAuthorizationMiddleware(organizationService)
+	auditMiddleware := middleware2.NewAuditMiddleware(auditService, organizationService, accountRepository)
+	throttleConfig := serverConfig.Throttle
+	throttleStore := database.NewThrottleStore(db)
+	throttle := server.NewThrottle(throttleConfig, throttleStore)
+	oidcConfig := config.OIDC
+	oidcProvider := oidc.NewProvider(oidcConfig)
+	identityRepository := database.NewIdentityRepository(db)
+	ssoAuthorizationRepository := database.NewSSOAuthorizationRepository(db)
+	ssoService := account.NewSSOService(oidcConfig, oidcProvider, accountRepository, identityRepository, ssoAuthorizationRepository, service, apiKeyService, organizationService)
+	authentication := routes.NewAuthenticationRoutes(service, credentialService, ssoService, organizationService, projectRepository)
+	routesAccount := routes.NewAccountRoutes(service, credentialService, accountRepository)
+	apiKey := routes.NewAPIKeyRoutes(apiKeyService, apiKeyRepository)
+	twoFactor := routes.NewTwoFactorRoutes(twoFactorService)
+	organization := routes.NewOrganizationRoutes(organizationService, accountRepository, organizationRepository, membershipRepository, invitationRepository)
+	project := routes.NewProjectRoutes(projectRepository, providerSettingsRepository, serverRepository)
+	providerSettings := routes.NewProviderSettingsRoutes(providerSettingsRepository, projectRepository)
+	reservedIPRepository := database.NewReservedIPRepository(db)
+	reservedIPProvisioner := provision.NewReservedIPProvisioner(terraformTerraform, serverRepository, reservedIPRepository, providerSettingsRepository)
+	volumeSnapshotRepository := database.NewVolumeSnapshotRepository(db)
+	routesProvision := routes.NewProvisionRoutes(jobScheduler, reservedIPProvisioner, jobRepository, providerSettingsRepository, projectRepository, reservedIPRepository, volumeSnapshotRepository)
+	routesServer := routes.NewServerRoutes(serverProvisioner, serverDestroyer, deploymentProvisioner, jobScheduler, serverRepository, providerSettingsRepository, projectRepository)
+	reservedIP := routes.NewReservedIPRoutes(reservedIPProvisioner, reservedIPRepository, providerSettingsRepository)
+	volumeSnapshotProvisioner := provision.NewVolumeSnapshotProvisioner(terraformTerraform, serverRepository, volumeSnapshotRepository, providerSettingsRepository)
+	volumeSnapshot := routes.NewVolumeSnapshotRoutes(volumeSnapshotProvisioner, serverRepository, volumeSnapshotRepository)
+	deployment := routes.NewDeploymentRoutes(jobScheduler, deploymentRepository, providerSettingsRepository)
+	routesAudit := routes.NewAuditRoutes(auditService)
 	router := &httpserver.Router{
 		AuthenticatedMiddleware: authenticationMiddleware,
+		AuthorizationMiddleware: authorizationMiddleware,
+		AuditMiddleware:         auditMiddleware,
+		Throttle:                throttle,
 		AuthRoutes:              authentication,
 		AccountRoutes:           routesAccount,
+		APIKeyRoutes:            apiKey,
+		TwoFactorRoutes:         twoFactor,
+		OrganizationRoutes:      organization,
+		ProjectRoutes:           project,
 		ProviderSettingsRoutes:  providerSettings,
 		ProvisionRoutes:         routesProvision,
 		ServerRoutes:            routesServer,
+		ReservedIPRoutes:        reservedIP,
+		VolumeSnapshotRoutes:    volumeSnapshot,
 		DeploymentRoutes:        deployment,
+		AuditRoutes:             routesAudit,
 	}
 	serverServer, err := server.ProvideServer(serverConfig, router, consoleLogger)
 	if err != nil {
@@ -120,7 +202,11 @@ func SetupDatabaseServer() (*AppServer, func(), error) {
 	}
 	workerPoolConfig := config.WorkerPool
 	workerPool := provision.NewWorkerPool(workerPoolConfig, jobRepository, provisioner)
-	appServer := NewAppServer(app, serverServer, workerPool)
+	healthCheckConfig := config.HealthCheck
+	healthMonitor := provision.NewHealthMonitor(healthCheckConfig, jobScheduler, jobRepository, serverRepository, providerSettingsRepository)
+	snapshotConfig := config.Snapshots
+	snapshotScheduler := provision.NewSnapshotScheduler(snapshotConfig, volumeSnapshotProvisioner, serverRepository, volumeSnapshotRepository)
+	appServer := NewAppServer(app, serverServer, workerPool, healthMonitor, snapshotScheduler)
 	return appServer, func() {
 		cleanup()
 	}, nil
@@ -132,31 +218,54 @@ func SetupInMemoryApp() *App {
 	provider := ProvideFileConfigProvider()
 	config := ProvideConfig(provider)
 	inMemoryRepository := account.NewInMemoryRepository()
+	inMemorySessionRepository := account.NewInMemorySessionRepository()
 	jwtConfig := config.JWT
 	tokenService := account.ConfigureTokenService(jwtConfig)
-	service := account.NewService(inMemoryRepository, tokenService)
+	inMemoryRecoveryCodeRepository := account.NewInMemoryRecoveryCodeRepository()
+	twoFactorService := account.NewTwoFactorService(inMemoryRepository, inMemoryRecoveryCodeRepository, inMemorySessionRepository)
+	service := account.NewService(inMemoryRepository, inMemorySessionRepository, tokenService, twoFactorService)
+	inMemoryTxContext := transaction.NewInMemoryTransactionContext()
+	inMemoryOneTimeTokenRepository := account.NewInMemoryOneTimeTokenRepository()
+	inMemoryAPIKeyRepository := account.NewInMemoryAPIKeyRepository()
+	apiKeyService := account.NewAPIKeyService(inMemoryRepository, inMemoryAPIKeyRepository)
+	mailConfig := config.Mail
+	mailer := mail.ConfigureMailer(mailConfig)
+	credentialService := account.NewCredentialService(inMemoryTxContext, inMemoryRepository, inMemoryOneTimeTokenRepository, service, apiKeyService, mailer, mailConfig)
+	inMemoryOrganizationRepository := account.NewInMemoryOrganizationRepository()
+	inMemoryMembershipRepository := account.NewInMemoryMembershipRepository()
+	inMemoryInvitationRepository := account.NewInMemoryInvitationRepository()
+	organizationService := account.NewOrganizationService(inMemoryOrganizationRepository, inMemoryMembershipRepository, inMemoryInvitationRepository)
+	auditInMemoryRepository := audit.NewInMemoryRepository()
+	auditService := audit.NewService(auditInMemoryRepository)
+	inMemoryProjectRepository := infrastructure.NewInMemoryProjectRepository()
 	inMemoryProviderSettingsRepository := infrastructure.NewInMemoryProviderSettingsRepository()
 	inMemoryServerRepository := infrastructure.NewInMemoryServerRepository()
 	inMemoryJobRepository := provision.NewInMemoryJobRepository()
-	inMemoryTxContext := transaction.NewInMemoryTransactionContext()
 	inMemoryDeploymentRepository := infrastructure.NewInMemoryDeploymentRepository()
 	jobScheduler := provision.NewJobScheduler(inMemoryTxContext, inMemoryJobRepository, inMemoryServerRepository, inMemoryDeploymentRepository)
+	firewallConfig := config.Firewall
 	terraformConfig := config.Terraform
 	terraformTerraform := terraform.ConfigureTerraform(terraformConfig)
-	serverProvisioner := provision.NewServerProvisioner(terraformTerraform, inMemoryServerRepository)
+	bootstrapper := byo.NewBootstrapper()
+	serverProvisioner := provision.NewServerProvisioner(firewallConfig, terraformTerraform, bootstrapper, inMemoryServerRepository, inMemoryProviderSettingsRepository)
 	stepProvisionServer := provision.NewStepProvisionServer(serverProvisioner, inMemoryJobRepository)
 	ansibleConfig := config.Ansible
 	ansibleAnsible := ansible.ConfigureAnsible(ansibleConfig)
 	deploymentProvisioner := provision.NewDeploymentProvisioner(ansibleAnsible, inMemoryDeploymentRepository)
 	stepProvisionDeployment := provision.NewStepProvisionDeployment(deploymentProvisioner, inMemoryJobRepository)
+	stepResizeVolume := provision.NewStepResizeVolume(serverProvisioner, inMemoryJobRepository)
+	stepGrowVolume := provision.NewStepGrowVolume(deploymentProvisioner, inMemoryJobRepository)
+	stepUpdateFirewall := provision.NewStepUpdateFirewall(serverProvisioner, inMemoryJobRepository)
+	stepUpdateLabels := provision.NewStepUpdateLabels(serverProvisioner, inMemoryJobRepository, inMemoryDeploymentRepository)
 	failureMiddleware := provision.NewFailureMiddleware(inMemoryJobRepository)
 	transactional := middleware.NewTransactional(inMemoryTxContext)
-	jobStateMachine := provision.ConfigureJobStateMachine(stepProvisionServer, stepProvisionDeployment, failureMiddleware, transactional)
-	serverDestroyer := provision.NewServerDestroyer(terraformTerraform, inMemoryTxContext, inMemoryServerRepository, inMemoryDeploymentRepository)
+	jobStateMachine := provision.ConfigureJobStateMachine(stepProvisionServer, stepProvisionDeployment, stepResizeVolume, stepGrowVolume, stepUpdateFirewall, stepUpdateLabels, failureMiddleware, transactional)
+	serverDestroyer := provision.NewServerDestroyer(terraformTerraform, deploymentProvisioner, inMemoryTxContext, inMemoryServerRepository, inMemoryDeploymentRepository, inMemoryProviderSettingsRepository)
 	provisioner := provision.NewProvisioner(jobStateMachine, terraformTerraform, serverDestroyer)
+	inMemoryRotator := encryption.NewInMemoryRotator()
 	logConfig := config.Log
 	consoleLogger := log.NewConsoleLogger(logConfig)
-	app := NewApp(config, inMemoryRepository, service, inMemoryProviderSettingsRepository, inMemoryServerRepository, inMemoryJobRepository, jobScheduler, provisioner, consoleLogger)
+	app := NewApp(config, inMemoryRepository, service, credentialService, twoFactorService, organizationService, auditService, inMemoryProjectRepository, inMemoryProviderSettingsRepository, inMemoryServerRepository, inMemoryJobRepository, jobScheduler, provisioner, inMemoryRotator, consoleLogger)
 	return app
 }
 
@@ -164,47 +273,101 @@ func SetupInMemoryServer() (*AppServer, func(), error) {
 	provider := ProvideFileConfigProvider()
 	config := ProvideConfig(provider)
 	inMemoryRepository := account.NewInMemoryRepository()
+	inMemorySessionRepository := account.NewInMemorySessionRepository()
 	jwtConfig := config.JWT
 	tokenService := account.ConfigureTokenService(jwtConfig)
-	service := account.NewService(inMemoryRepository, tokenService)
+	inMemoryRecoveryCodeRepository := account.NewInMemoryRecoveryCodeRepository()
+	twoFactorService := account.NewTwoFactorService(inMemoryRepository, inMemoryRecoveryCodeRepository, inMemorySessionRepository)
+	service := account.NewService(inMemoryRepository, inMemorySessionRepository, tokenService, twoFactorService)
+	inMemoryTxContext := transaction.NewInMemoryTransactionContext()
+	inMemoryOneTimeTokenRepository := account.NewInMemoryOneTimeTokenRepository()
+	inMemoryAPIKeyRepository := account.NewInMemoryAPIKeyRepository()
+	apiKeyService := account.NewAPIKeyService(inMemoryRepository, inMemoryAPIKeyRepository)
+	mailConfig := config.Mail
+	mailer := mail.ConfigureMailer(mailConfig)
+	credentialService := account.NewCredentialService(inMemoryTxContext, inMemoryRepository, inMemoryOneTimeTokenRepository, service, apiKeyService, mailer, mailConfig)
+	inMemoryOrganizationRepository := account.NewInMemoryOrganizationRepository()
+	inMemoryMembershipRepository := account.NewInMemoryMembershipRepository()
+	inMemoryInvitationRepository := account.NewInMemoryInvitationRepository()
+	organizationService := account.NewOrganizationService(inMemoryOrganizationRepository, inMemoryMembershipRepository, inMemoryInvitationRepository)
+	auditInMemoryRepository := audit.NewInMemoryRepository()
+	auditService := audit.NewService(auditInMemoryRepository)
+	inMemoryProjectRepository := infrastructure.NewInMemoryProjectRepository()
 	inMemoryProviderSettingsRepository := infrastructure.NewInMemoryProviderSettingsRepository()
 	inMemoryServerRepository := infrastructure.NewInMemoryServerRepository()
 	inMemoryJobRepository := provision.NewInMemoryJobRepository()
-	inMemoryTxContext := transaction.NewInMemoryTransactionContext()
 	inMemoryDeploymentRepository := infrastructure.NewInMemoryDeploymentRepository()
 	jobScheduler := provision.NewJobScheduler(inMemoryTxContext, inMemoryJobRepository, inMemoryServerRepository, inMemoryDeploymentRepository)
+	firewallConfig := config.Firewall
 	terraformConfig := config.Terraform
 	terraformTerraform := terraform.ConfigureTerraform(terraformConfig)
-	serverProvisioner := provision.NewServerProvisioner(terraformTerraform, inMemoryServerRepository)
+	bootstrapper := byo.NewBootstrapper()
+	serverProvisioner := provision.NewServerProvisioner(firewallConfig, terraformTerraform, bootstrapper, inMemoryServerRepository, inMemoryProviderSettingsRepository)
 	stepProvisionServer := provision.NewStepProvisionServer(serverProvisioner, inMemoryJobRepository)
 	ansibleConfig := config.Ansible
 	ansibleAnsible := ansible.ConfigureAnsible(ansibleConfig)
 	deploymentProvisioner := provision.NewDeploymentProvisioner(ansibleAnsible, inMemoryDeploymentRepository)
 	stepProvisionDeployment := provision.NewStepProvisionDeployment(deploymentProvisioner, inMemoryJobRepository)
+	stepResizeVolume := provision.NewStepResizeVolume(serverProvisioner, inMemoryJobRepository)
+	stepGrowVolume := provision.NewStepGrowVolume(deploymentProvisioner, inMemoryJobRepository)
+	stepUpdateFirewall := provision.NewStepUpdateFirewall(serverProvisioner, inMemoryJobRepository)
+	stepUpdateLabels := provision.NewStepUpdateLabels(serverProvisioner, inMemoryJobRepository, inMemoryDeploymentRepository)
 	failureMiddleware := provision.NewFailureMiddleware(inMemoryJobRepository)
 	transactional := middleware.NewTransactional(inMemoryTxContext)
-	jobStateMachine := provision.ConfigureJobStateMachine(stepProvisionServer, stepProvisionDeployment, failureMiddleware, transactional)
-	serverDestroyer := provision.NewServerDestroyer(terraformTerraform, inMemoryTxContext, inMemoryServerRepository, inMemoryDeploymentRepository)
+	jobStateMachine := provision.ConfigureJobStateMachine(stepProvisionServer, stepProvisionDeployment, stepResizeVolume, stepGrowVolume, stepUpdateFirewall, stepUpdateLabels, failureMiddleware, transactional)
+	serverDestroyer := provision.NewServerDestroyer(terraformTerraform, deploymentProvisioner, inMemoryTxContext, inMemoryServerRepository, inMemoryDeploymentRepository, inMemoryProviderSettingsRepository)
 	provisioner := provision.NewProvisioner(jobStateMachine, terraformTerraform, serverDestroyer)
+	inMemoryRotator := encryption.NewInMemoryRotator()
 	logConfig := config.Log
 	consoleLogger := log.NewConsoleLogger(logConfig)
-	app := NewApp(config, inMemoryRepository, service, inMemoryProviderSettingsRepository, inMemoryServerRepository, inMemoryJobRepository, jobScheduler, provisioner, consoleLogger)
+	app := NewApp(config, inMemoryRepository, service, credentialService, twoFactorService, organizationService, auditService, inMemoryProjectRepository, inMemoryProviderSettingsRepository, inMemoryServerRepository, inMemoryJobRepository, jobScheduler, provisioner, inMemoryRotator, consoleLogger)
 	serverConfig := config.Server
-	authenticationMiddleware := middleware2.NewAuthenticationMiddleware(service)
-	authentication := routes.NewAuthenticationRoutes(service)
-	routesAccount := routes.NewAccountRoutes(inMemoryRepository)
-	providerSettings := routes.NewProviderSettingsRoutes(inMemoryProviderSettingsRepository)
-	routesProvision := routes.NewProvisionRoutes(jobScheduler, inMemoryJobRepository, inMemoryProviderSettingsRepository)
-	routesServer := routes.NewServerRoutes(serverDestroyer, deploymentProvisioner, inMemoryServerRepository)
-	deployment := routes.NewDeploymentRoutes(inMemoryDeploymentRepository)
+	authenticationMiddleware := middleware2.NewAuthenticationMiddleware(service, apiKeyService)
+	authorizationMiddleware := middleware2.NewAuthorizationMiddleware(organizationService)
+	auditMiddleware := middleware2.NewAuditMiddleware(auditService, organizationService, inMemoryRepository)
+	throttleConfig := serverConfig.Throttle
+	inMemoryThrottleStore := server.NewInMemoryThrottleStore()
+	throttle := server.NewThrottle(throttleConfig, inMemoryThrottleStore)
+	oidcConfig := config.OIDC
+	oidcProvider := oidc.NewProvider(oidcConfig)
+	inMemoryIdentityRepository := account.NewInMemoryIdentityRepository()
+	inMemorySSOAuthorizationRepository := account.NewInMemorySSOAuthorizationRepository()
+	ssoService := account.NewSSOService(oidcConfig, oidcProvider, inMemoryRepository, inMemoryIdentityRepository, inMemorySSOAuthorizationRepository, service, apiKeyService, organizationService)
+	authentication := routes.NewAuthenticationRoutes(service, credentialService, ssoService, organizationService, inMemoryProjectRepository)
+	routesAccount := routes.NewAccountRoutes(service, credentialService, inMemoryRepository)
+	apiKey := routes.NewAPIKeyRoutes(apiKeyService, inMemoryAPIKeyRepository)
+	twoFactor := routes.NewTwoFactorRoutes(twoFactorService)
+	organization := routes.NewOrganizationRoutes(organizationService, inMemoryRepository, inMemoryOrganizationRepository, inMemoryMembershipRepository, inMemoryInvitationRepository)
+	project := routes.NewProjectRoutes(inMemoryProjectRepository, inMemoryProviderSettingsRepository, inMemoryServerRepository)
+	providerSettings := routes.NewProviderSettingsRoutes(inMemoryProviderSettingsRepository, inMemoryProjectRepository)
+	inMemoryReservedIPRepository := infrastructure.NewInMemoryReservedIPRepository()
+	reservedIPProvisioner := provision.NewReservedIPProvisioner(terraformTerraform, inMemoryServerRepository, inMemoryReservedIPRepository, inMemoryProviderSettingsRepository)
+	inMemoryVolumeSnapshotRepository := infrastructure.NewInMemoryVolumeSnapshotRepository()
+	routesProvision := routes.NewProvisionRoutes(jobScheduler, reservedIPProvisioner, inMemoryJobRepository, inMemoryProviderSettingsRepository, inMemoryProjectRepository, inMemoryReservedIPRepository, inMemoryVolumeSnapshotRepository)
+	routesServer := routes.NewServerRoutes(serverProvisioner, serverDestroyer, deploymentProvisioner, jobScheduler, inMemoryServerRepository, inMemoryProviderSettingsRepository, inMemoryProjectRepository)
+	reservedIP := routes.NewReservedIPRoutes(reservedIPProvisioner, inMemoryReservedIPRepository, inMemoryProviderSettingsRepository)
+	volumeSnapshotProvisioner := provision.NewVolumeSnapshotProvisioner(terraformTerraform, inMemoryServerRepository, inMemoryVolumeSnapshotRepository, inMemoryProviderSettingsRepository)
+	volumeSnapshot := routes.NewVolumeSnapshotRoutes(volumeSnapshotProvisioner, inMemoryServerRepository, inMemoryVolumeSnapshotRepository)
+	deployment := routes.NewDeploymentRoutes(jobScheduler, inMemoryDeploymentRepository, inMemoryProviderSettingsRepository)
+	routesAudit := routes.NewAuditRoutes(auditService)
 	router := &httpserver.Router{
 		AuthenticatedMiddleware: authenticationMiddleware,
+		AuthorizationMiddleware: authorizationMiddleware,
+		AuditMiddleware:         auditMiddleware,
+		Throttle:                throttle,
 		AuthRoutes:              authentication,
 		AccountRoutes:           routesAccount,
+		APIKeyRoutes:            apiKey,
+		TwoFactorRoutes:         twoFactor,
+		OrganizationRoutes:      organization,
+		ProjectRoutes:           project,
 		ProviderSettingsRoutes:  providerSettings,
 		ProvisionRoutes:         routesProvision,
 		ServerRoutes:            routesServer,
+		ReservedIPRoutes:        reservedIP,
+		VolumeSnapshotRoutes:    volumeSnapshot,
 		DeploymentRoutes:        deployment,
+		AuditRoutes:             routesAudit,
 	}
 	serverServer, err := server.ProvideServer(serverConfig, router, consoleLogger)
 	if err != nil {
@@ -212,7 +375,11 @@ func SetupInMemoryServer() (*AppServer, func(), error) {
 	}
 	workerPoolConfig := config.WorkerPool
 	workerPool := provision.NewWorkerPool(workerPoolConfig, inMemoryJobRepository, provisioner)
-	appServer := NewAppServer(app, serverServer, workerPool)
+	healthCheckConfig := config.HealthCheck
+	healthMonitor := provision.NewHealthMonitor(healthCheckConfig, jobScheduler, inMemoryJobRepository, inMemoryServerRepository, inMemoryProviderSettingsRepository)
+	snapshotConfig := config.Snapshots
+	snapshotScheduler := provision.NewSnapshotScheduler(snapshotConfig, volumeSnapshotProvisioner, inMemoryServerRepository, inMemoryVolumeSnapshotRepository)
+	appServer := NewAppServer(app, serverServer, workerPool, healthMonitor, snapshotScheduler)
 	return appServer, func() {
 	}, nil
 }
@@ -223,30 +390,53 @@ func SetupTestApp(t *testing.T) *App {
 	provider := ProvideTestConfigProvider()
 	config := ProvideConfig(provider)
 	inMemoryRepository := account.NewInMemoryRepository()
+	inMemorySessionRepository := account.NewInMemorySessionRepository()
 	jwtConfig := config.JWT
 	tokenService := account.ConfigureTokenService(jwtConfig)
-	service := account.NewService(inMemoryRepository, tokenService)
+	inMemoryRecoveryCodeRepository := account.NewInMemoryRecoveryCodeRepository()
+	twoFactorService := account.NewTwoFactorService(inMemoryRepository, inMemoryRecoveryCodeRepository, inMemorySessionRepository)
+	service := account.NewService(inMemoryRepository, inMemorySessionRepository, tokenService, twoFactorService)
+	inMemoryTxContext := transaction.NewInMemoryTransactionContext()
+	inMemoryOneTimeTokenRepository := account.NewInMemoryOneTimeTokenRepository()
+	inMemoryAPIKeyRepository := account.NewInMemoryAPIKeyRepository()
+	apiKeyService := account.NewAPIKeyService(inMemoryRepository, inMemoryAPIKeyRepository)
+	inMemoryMailer := mail.NewInMemoryMailer()
+	mailConfig := config.Mail
+	credentialService := account.NewCredentialService(inMemoryTxContext, inMemoryRepository, inMemoryOneTimeTokenRepository, service, apiKeyService, inMemoryMailer, mailConfig)
+	inMemoryOrganizationRepository := account.NewInMemoryOrganizationRepository()
+	inMemoryMembershipRepository := account.NewInMemoryMembershipRepository()
+	inMemoryInvitationRepository := account.NewInMemoryInvitationRepository()
+	organizationService := account.NewOrganizationService(inMemoryOrganizationRepository, inMemoryMembershipRepository, inMemoryInvitationRepository)
+	auditInMemoryRepository := audit.NewInMemoryRepository()
+	auditService := audit.NewService(auditInMemoryRepository)
+	inMemoryProjectRepository := infrastructure.NewInMemoryProjectRepository()
 	inMemoryProviderSettingsRepository := infrastructure.NewInMemoryProviderSettingsRepository()
 	inMemoryServerRepository := infrastructure.NewInMemoryServerRepository()
 	inMemoryJobRepository := provision.NewInMemoryJobRepository()
-	inMemoryTxContext := transaction.NewInMemoryTransactionContext()
 	inMemoryDeploymentRepository := infrastructure.NewInMemoryDeploymentRepository()
 	jobScheduler := provision.NewJobScheduler(inMemoryTxContext, inMemoryJobRepository, inMemoryServerRepository, inMemoryDeploymentRepository)
+	firewallConfig := config.Firewall
 	terraformConfig := config.Terraform
 	terraformTerraform := terraform.ConfigureTerraform(terraformConfig)
-	serverProvisioner := provision.NewServerProvisioner(terraformTerraform, inMemoryServerRepository)
+	bootstrapper := byo.NewBootstrapper()
+	serverProvisioner := provision.NewServerProvisioner(firewallConfig, terraformTerraform, bootstrapper, inMemoryServerRepository, inMemoryProviderSettingsRepository)
 	stepProvisionServer := provision.NewStepProvisionServer(serverProvisioner, inMemoryJobRepository)
 	ansibleConfig := config.Ansible
 	ansibleAnsible := ansible.ConfigureAnsible(ansibleConfig)
 	deploymentProvisioner := provision.NewDeploymentProvisioner(ansibleAnsible, inMemoryDeploymentRepository)
 	stepProvisionDeployment := provision.NewStepProvisionDeployment(deploymentProvisioner, inMemoryJobRepository)
+	stepResizeVolume := provision.NewStepResizeVolume(serverProvisioner, inMemoryJobRepository)
+	stepGrowVolume := provision.NewStepGrowVolume(deploymentProvisioner, inMemoryJobRepository)
+	stepUpdateFirewall := provision.NewStepUpdateFirewall(serverProvisioner, inMemoryJobRepository)
+	stepUpdateLabels := provision.NewStepUpdateLabels(serverProvisioner, inMemoryJobRepository, inMemoryDeploymentRepository)
 	failureMiddleware := provision.NewFailureMiddleware(inMemoryJobRepository)
 	transactional := middleware.NewTransactional(inMemoryTxContext)
-	jobStateMachine := provision.ConfigureJobStateMachine(stepProvisionServer, stepProvisionDeployment, failureMiddleware, transactional)
-	serverDestroyer := provision.NewServerDestroyer(terraformTerraform, inMemoryTxContext, inMemoryServerRepository, inMemoryDeploymentRepository)
+	jobStateMachine := provision.ConfigureJobStateMachine(stepProvisionServer, stepProvisionDeployment, stepResizeVolume, stepGrowVolume, stepUpdateFirewall, stepUpdateLabels, failureMiddleware, transactional)
+	serverDestroyer := provision.NewServerDestroyer(terraformTerraform, deploymentProvisioner, inMemoryTxContext, inMemoryServerRepository, inMemoryDeploymentRepository, inMemoryProviderSettingsRepository)
 	provisioner := provision.NewProvisioner(jobStateMachine, terraformTerraform, serverDestroyer)
+	inMemoryRotator := encryption.NewInMemoryRotator()
 	testingLogger := log.NewTestingLogger(t)
-	app := NewApp(config, inMemoryRepository, service, inMemoryProviderSettingsRepository, inMemoryServerRepository, inMemoryJobRepository, jobScheduler, provisioner, testingLogger)
+	app := NewApp(config, inMemoryRepository, service, credentialService, twoFactorService, organizationService, auditService, inMemoryProjectRepository, inMemoryProviderSettingsRepository, inMemoryServerRepository, inMemoryJobRepository, jobScheduler, provisioner, inMemoryRotator, testingLogger)
 	return app
 }
 
@@ -254,46 +444,100 @@ func SetupTestServer(t *testing.T) (*AppServer, func(), error) {
 	provider := ProvideTestConfigProvider()
 	config := ProvideConfig(provider)
 	inMemoryRepository := account.NewInMemoryRepository()
+	inMemorySessionRepository := account.NewInMemorySessionRepository()
 	jwtConfig := config.JWT
 	tokenService := account.ConfigureTokenService(jwtConfig)
-	service := account.NewService(inMemoryRepository, tokenService)
+	inMemoryRecoveryCodeRepository := account.NewInMemoryRecoveryCodeRepository()
+	twoFactorService := account.NewTwoFactorService(inMemoryRepository, inMemoryRecoveryCodeRepository, inMemorySessionRepository)
+	service := account.NewService(inMemoryRepository, inMemorySessionRepository, tokenService, twoFactorService)
+	inMemoryTxContext := transaction.NewInMemoryTransactionContext()
+	inMemoryOneTimeTokenRepository := account.NewInMemoryOneTimeTokenRepository()
+	inMemoryAPIKeyRepository := account.NewInMemoryAPIKeyRepository()
+	apiKeyService := account.NewAPIKeyService(inMemoryRepository, inMemoryAPIKeyRepository)
+	inMemoryMailer := mail.NewInMemoryMailer()
+	mailConfig := config.Mail
+	credentialService := account.NewCredentialService(inMemoryTxContext, inMemoryRepository, inMemoryOneTimeTokenRepository, service, apiKeyService, inMemoryMailer, mailConfig)
+	inMemoryOrganizationRepository := account.NewInMemoryOrganizationRepository()
+	inMemoryMembershipRepository := account.NewInMemoryMembershipRepository()
+	inMemoryInvitationRepository := account.NewInMemoryInvitationRepository()
+	organizationService := account.NewOrganizationService(inMemoryOrganizationRepository, inMemoryMembershipRepository, inMemoryInvitationRepository)
+	auditInMemoryRepository := audit.NewInMemoryRepository()
+	auditService := audit.NewService(auditInMemoryRepository)
+	inMemoryProjectRepository := infrastructure.NewInMemoryProjectRepository()
 	inMemoryProviderSettingsRepository := infrastructure.NewInMemoryProviderSettingsRepository()
 	inMemoryServerRepository := infrastructure.NewInMemoryServerRepository()
 	inMemoryJobRepository := provision.NewInMemoryJobRepository()
-	inMemoryTxContext := transaction.NewInMemoryTransactionContext()
 	inMemoryDeploymentRepository := infrastructure.NewInMemoryDeploymentRepository()
 	jobScheduler := provision.NewJobScheduler(inMemoryTxContext, inMemoryJobRepository, inMemoryServerRepository, inMemoryDeploymentRepository)
+	firewallConfig := config.Firewall
 	terraformConfig := config.Terraform
 	terraformTerraform := terraform.ConfigureTerraform(terraformConfig)
-	serverProvisioner := provision.NewServerProvisioner(terraformTerraform, inMemoryServerRepository)
+	bootstrapper := byo.NewBootstrapper()
+	serverProvisioner := provision.NewServerProvisioner(firewallConfig, terraformTerraform, bootstrapper, inMemoryServerRepository, inMemoryProviderSettingsRepository)
 	stepProvisionServer := provision.NewStepProvisionServer(serverProvisioner, inMemoryJobRepository)
 	ansibleConfig := config.Ansible
 	ansibleAnsible := ansible.ConfigureAnsible(ansibleConfig)
 	deploymentProvisioner := provision.NewDeploymentProvisioner(ansibleAnsible, inMemoryDeploymentRepository)
 	stepProvisionDeployment := provision.NewStepProvisionDeployment(deploymentProvisioner, inMemoryJobRepository)
+	stepResizeVolume := provision.NewStepResizeVolume(serverProvisioner, inMemoryJobRepository)
+	stepGrowVolume := provision.NewStepGrowVolume(deploymentProvisioner, inMemoryJobRepository)
+	stepUpdateFirewall := provision.NewStepUpdateFirewall(serverProvisioner, inMemoryJobRepository)
+	stepUpdateLabels := provision.NewStepUpdateLabels(serverProvisioner, inMemoryJobRepository, inMemoryDeploymentRepository)
 	failureMiddleware := provision.NewFailureMiddleware(inMemoryJobRepository)
 	transactional := middleware.NewTransactional(inMemoryTxContext)
-	jobStateMachine := provision.ConfigureJobStateMachine(stepProvisionServer, stepProvisionDeployment, failureMiddleware, transactional)
-	serverDestroyer := provision.NewServerDestroyer(terraformTerraform, inMemoryTxContext, inMemoryServerRepository, inMemoryDeploymentRepository)
+	jobStateMachine := provision.ConfigureJobStateMachine(stepProvisionServer, stepProvisionDeployment, stepResizeVolume, stepGrowVolume, stepUpdateFirewall, stepUpdateLabels, failureMiddleware, transactional)
+	serverDestroyer := provision.NewServerDestroyer(terraformTerraform, deploymentProvisioner, inMemoryTxContext, inMemoryServerRepository, inMemoryDeploymentRepository, inMemoryProviderSettingsRepository)
 	provisioner := provision.NewProvisioner(jobStateMachine, terraformTerraform, serverDestroyer)
+	inMemoryRotator := encryption.NewInMemoryRotator()
 	testingLogger := log.NewTestingLogger(t)
-	app := NewApp(config, inMemoryRepository, service, inMemoryProviderSettingsRepository, inMemoryServerRepository, inMemoryJobRepository, jobScheduler, provisioner, testingLogger)
+	app := NewApp(config, inMemoryRepository, service, credentialService, twoFactorService, organizationService, auditService, inMemoryProjectRepository, inMemoryProviderSettingsRepository, inMemoryServerRepository, inMemoryJobRepository, jobScheduler, provisioner, inMemoryRotator, testingLogger)
 	serverConfig := config.Server
-	authenticationMiddleware := middleware2.NewAuthenticationMiddleware(service)
-	authentication := routes.NewAuthenticationRoutes(service)
-	routesAccount := routes.NewAccountRoutes(inMemoryRepository)
-	providerSettings := routes.NewProviderSettingsRoutes(inMemoryProviderSettingsRepository)
-	routesProvision := routes.NewProvisionRoutes(jobScheduler, inMemoryJobRepository, inMemoryProviderSettingsRepository)
-	routesServer := routes.NewServerRoutes(serverDestroyer, deploymentProvisioner, inMemoryServerRepository)
-	deployment := routes.NewDeploymentRoutes(inMemoryDeploymentRepository)
+	authenticationMiddleware := middleware2.NewAuthenticationMiddleware(service, apiKeyService)
+	authorizationMiddleware := middleware2.NewAuthorizationMiddleware(organizationService)
+	auditMiddleware := middleware2.NewAuditMiddleware(auditService, organizationService, inMemoryRepository)
+	throttleConfig := serverConfig.Throttle
+	inMemoryThrottleStore := server.NewInMemoryThrottleStore()
+	throttle := server.NewThrottle(throttleConfig, inMemoryThrottleStore)
+	oidcConfig := config.OIDC
+	oidcProvider := oidc.NewProvider(oidcConfig)
+	inMemoryIdentityRepository := account.NewInMemoryIdentityRepository()
+	inMemorySSOAuthorizationRepository := account.NewInMemorySSOAuthorizationRepository()
+	ssoService := account.NewSSOService(oidcConfig, oidcProvider, inMemoryRepository, inMemoryIdentityRepository, inMemorySSOAuthorizationRepository, service, apiKeyService, organizationService)
+	authentication := routes.NewAuthenticationRoutes(service, credentialService, ssoService, organizationService, inMemoryProjectRepository)
+	routesAccount := routes.NewAccountRoutes(service, credentialService, inMemoryRepository)
+	apiKey := routes.NewAPIKeyRoutes(apiKeyService, inMemoryAPIKeyRepository)
+	twoFactor := routes.NewTwoFactorRoutes(twoFactorService)
+	organization := routes.NewOrganizationRoutes(organizationService, inMemoryRepository, inMemoryOrganizationRepository, inMemoryMembershipRepository, inMemoryInvitationRepository)
+	project := routes.NewProjectRoutes(inMemoryProjectRepository, inMemoryProviderSettingsRepository, inMemoryServerRepository)
+	providerSettings := routes.NewProviderSettingsRoutes(inMemoryProviderSettingsRepository, inMemoryProjectRepository)
+	inMemoryReservedIPRepository := infrastructure.NewInMemoryReservedIPRepository()
+	reservedIPProvisioner := provision.NewReservedIPProvisioner(terraformTerraform, inMemoryServerRepository, inMemoryReservedIPRepository, inMemoryProviderSettingsRepository)
+	inMemoryVolumeSnapshotRepository := infrastructure.NewInMemoryVolumeSnapshotRepository()
+	routesProvision := routes.NewProvisionRoutes(jobScheduler, reservedIPProvisioner, inMemoryJobRepository, inMemoryProviderSettingsRepository, inMemoryProjectRepository, inMemoryReservedIPRepository, inMemoryVolumeSnapshotRepository)
+	routesServer := routes.NewServerRoutes(serverProvisioner, serverDestroyer, deploymentProvisioner, jobScheduler, inMemoryServerRepository, inMemoryProviderSettingsRepository, inMemoryProjectRepository)
+	reservedIP := routes.NewReservedIPRoutes(reservedIPProvisioner, inMemoryReservedIPRepository, inMemoryProviderSettingsRepository)
+	volumeSnapshotProvisioner := provision.NewVolumeSnapshotProvisioner(terraformTerraform, inMemoryServerRepository, inMemoryVolumeSnapshotRepository, inMemoryProviderSettingsRepository)
+	volumeSnapshot := routes.NewVolumeSnapshotRoutes(volumeSnapshotProvisioner, inMemoryServerRepository, inMemoryVolumeSnapshotRepository)
+	deployment := routes.NewDeploymentRoutes(jobScheduler, inMemoryDeploymentRepository, inMemoryProviderSettingsRepository)
+	routesAudit := routes.NewAuditRoutes(auditService)
 	router := &httpserver.Router{
 		AuthenticatedMiddleware: authenticationMiddleware,
+		AuthorizationMiddleware: authorizationMiddleware,
+		AuditMiddleware:         auditMiddleware,
+		Throttle:                throttle,
 		AuthRoutes:              authentication,
 		AccountRoutes:           routesAccount,
+		APIKeyRoutes:            apiKey,
+		TwoFactorRoutes:         twoFactor,
+		OrganizationRoutes:      organization,
+		ProjectRoutes:           project,
 		ProviderSettingsRoutes:  providerSettings,
 		ProvisionRoutes:         routesProvision,
 		ServerRoutes:            routesServer,
+		ReservedIPRoutes:        reservedIP,
+		VolumeSnapshotRoutes:    volumeSnapshot,
 		DeploymentRoutes:        deployment,
+		AuditRoutes:             routesAudit,
 	}
 	serverServer, err := server.ProvideServer(serverConfig, router, testingLogger)
 	if err != nil {
@@ -301,7 +545,11 @@ func SetupTestServer(t *testing.T) (*AppServer, func(), error) {
 	}
 	workerPoolConfig := config.WorkerPool
 	workerPool := provision.NewWorkerPool(workerPoolConfig, inMemoryJobRepository, provisioner)
-	appServer := NewAppServer(app, serverServer, workerPool)
+	healthCheckConfig := config.HealthCheck
+	healthMonitor := provision.NewHealthMonitor(healthCheckConfig, jobScheduler, inMemoryJobRepository, inMemoryServerRepository, inMemoryProviderSettingsRepository)
+	snapshotConfig := config.Snapshots
+	snapshotScheduler := provision.NewSnapshotScheduler(snapshotConfig, volumeSnapshotProvisioner, inMemoryServerRepository, inMemoryVolumeSnapshotRepository)
+	appServer := NewAppServer(app, serverServer, workerPool, healthMonitor, snapshotScheduler)
 	return appServer, func() {
 	}, nil
 }
@@ -309,17 +557,17 @@ func SetupTestServer(t *testing.T) (*AppServer, func(), error) {
 // inject_database.go:
 
 var dbAppSet = wire.NewSet(
-	ProvideFileConfigProvider, log.NewConsoleLogger, wire.Bind(new(log.Logger), new(*log.ConsoleLogger)), database.Set, database.NewAccountRepository, wire.Bind(new(account.Repository), new(*database.AccountRepository)), database.NewJobRepository, wire.Bind(new(provision.JobRepository), new(*database.JobRepository)), database.NewServerRepository, wire.Bind(new(infrastructure.ServerRepository), new(*database.ServerRepository)), database.NewDeploymentRepository, wire.Bind(new(infrastructure.DeploymentRepository), new(*database.DeploymentRepository)), database.NewProviderSettingsRepository, wire.Bind(new(infrastructure.ProviderSettingsRepository), new(*database.ProviderSettingsRepository)), AppSet,
+	ProvideFileConfigProvider, log.NewConsoleLogger, wire.Bind(new(log.Logger), new(*log.ConsoleLogger)), database.Set, database.NewAccountRepository, wire.Bind(new(account.Repository), new(*database.AccountRepository)), database.NewJobRepository, wire.Bind(new(provision.JobRepository), new(*database.JobRepository)), database.NewServerRepository, wire.Bind(new(infrastructure.ServerRepository), new(*database.ServerRepository)), database.NewDeploymentRepository, wire.Bind(new(infrastructure.DeploymentRepository), new(*database.DeploymentRepository)), database.NewProviderSettingsRepository, wire.Bind(new(infrastructure.ProviderSettingsRepository), new(*database.ProviderSettingsRepository)), database.NewProjectRepository, wire.Bind(new(infrastructure.ProjectRepository), new(*database.ProjectRepository)), database.NewOrganizationRepository, wire.Bind(new(account.OrganizationRepository), new(*database.OrganizationRepository)), database.NewMembershipRepository, wire.Bind(new(account.MembershipRepository), new(*database.MembershipRepository)), database.NewInvitationRepository, wire.Bind(new(account.InvitationRepository), new(*database.InvitationRepository)), database.NewAPIKeyRepository, wire.Bind(new(account.APIKeyRepository), new(*database.APIKeyRepository)), database.NewSessionRepository, wire.Bind(new(account.SessionRepository), new(*database.SessionRepository)), database.NewOneTimeTokenRepository, wire.Bind(new(account.OneTimeTokenRepository), new(*database.OneTimeTokenRepository)), database.NewRecoveryCodeRepository, wire.Bind(new(account.RecoveryCodeRepository), new(*database.RecoveryCodeRepository)), database.NewIdentityRepository, wire.Bind(new(account.IdentityRepository), new(*database.IdentityRepository)), database.NewSSOAuthorizationRepository, wire.Bind(new(account.SSOAuthorizationRepository), new(*database.SSOAuthorizationRepository)), database.NewAuditRepository, wire.Bind(new(audit.Repository), new(*database.AuditRepository)), database.NewThrottleStore, wire.Bind(new(server.ThrottleStore), new(*database.ThrottleStore)), database.NewEncryptionRotator, wire.Bind(new(encryption.Rotator), new(*database.EncryptionRotator)), mail.ConfigureMailer, database.NewReservedIPRepository, wire.Bind(new(infrastructure.ReservedIPRepository), new(*database.ReservedIPRepository)), database.NewVolumeSnapshotRepository, wire.Bind(new(infrastructure.VolumeSnapshotRepository), new(*database.VolumeSnapshotRepository)), AppSet,
 )
 
 // inject_memory.go:
 
 var inMemAppSet = wire.NewSet(
-	ProvideFileConfigProvider, log.NewConsoleLogger, wire.Bind(new(log.Logger), new(*log.ConsoleLogger)), transaction.NewInMemoryTransactionContext, wire.Bind(new(transaction.TxContext), new(*transaction.InMemoryTxContext)), account.NewInMemoryRepository, wire.Bind(new(account.Repository), new(*account.InMemoryRepository)), provision.NewInMemoryJobRepository, wire.Bind(new(provision.JobRepository), new(*provision.InMemoryJobRepository)), infrastructure.NewInMemoryServerRepository, wire.Bind(new(infrastructure.ServerRepository), new(*infrastructure.InMemoryServerRepository)), infrastructure.NewInMemoryDeploymentRepository, wire.Bind(new(infrastructure.DeploymentRepository), new(*infrastructure.InMemoryDeploymentRepository)), infrastructure.NewInMemoryProviderSettingsRepository, wire.Bind(new(infrastructure.ProviderSettingsRepository), new(*infrastructure.InMemoryProviderSettingsRepository)), AppSet,
+	ProvideFileConfigProvider, log.NewConsoleLogger, wire.Bind(new(log.Logger), new(*log.ConsoleLogger)), transaction.NewInMemoryTransactionContext, wire.Bind(new(transaction.TxContext), new(*transaction.InMemoryTxContext)), account.NewInMemoryRepository, wire.Bind(new(account.Repository), new(*account.InMemoryRepository)), provision.NewInMemoryJobRepository, wire.Bind(new(provision.JobRepository), new(*provision.InMemoryJobRepository)), infrastructure.NewInMemoryServerRepository, wire.Bind(new(infrastructure.ServerRepository), new(*infrastructure.InMemoryServerRepository)), infrastructure.NewInMemoryDeploymentRepository, wire.Bind(new(infrastructure.DeploymentRepository), new(*infrastructure.InMemoryDeploymentRepository)), infrastructure.NewInMemoryProviderSettingsRepository, wire.Bind(new(infrastructure.ProviderSettingsRepository), new(*infrastructure.InMemoryProviderSettingsRepository)), infrastructure.NewInMemoryProjectRepository, wire.Bind(new(infrastructure.ProjectRepository), new(*infrastructure.InMemoryProjectRepository)), account.NewInMemoryOrganizationRepository, wire.Bind(new(account.OrganizationRepository), new(*account.InMemoryOrganizationRepository)), account.NewInMemoryMembershipRepository, wire.Bind(new(account.MembershipRepository), new(*account.InMemoryMembershipRepository)), account.NewInMemoryInvitationRepository, wire.Bind(new(account.InvitationRepository), new(*account.InMemoryInvitationRepository)), account.NewInMemoryAPIKeyRepository, wire.Bind(new(account.APIKeyRepository), new(*account.InMemoryAPIKeyRepository)), account.NewInMemorySessionRepository, wire.Bind(new(account.SessionRepository), new(*account.InMemorySessionRepository)), account.NewInMemoryOneTimeTokenRepository, wire.Bind(new(account.OneTimeTokenRepository), new(*account.InMemoryOneTimeTokenRepository)), account.NewInMemoryRecoveryCodeRepository, wire.Bind(new(account.RecoveryCodeRepository), new(*account.InMemoryRecoveryCodeRepository)), account.NewInMemoryIdentityRepository, wire.Bind(new(account.IdentityRepository), new(*account.InMemoryIdentityRepository)), account.NewInMemorySSOAuthorizationRepository, wire.Bind(new(account.SSOAuthorizationRepository), new(*account.InMemorySSOAuthorizationRepository)), audit.NewInMemoryRepository, wire.Bind(new(audit.Repository), new(*audit.InMemoryRepository)), server.NewInMemoryThrottleStore, wire.Bind(new(server.ThrottleStore), new(*server.InMemoryThrottleStore)), encryption.NewInMemoryRotator, wire.Bind(new(encryption.Rotator), new(*encryption.InMemoryRotator)), mail.ConfigureMailer, infrastructure.NewInMemoryReservedIPRepository, wire.Bind(new(infrastructure.ReservedIPRepository), new(*infrastructure.InMemoryReservedIPRepository)), infrastructure.NewInMemoryVolumeSnapshotRepository, wire.Bind(new(infrastructure.VolumeSnapshotRepository), new(*infrastructure.InMemoryVolumeSnapshotRepository)), AppSet,
 )
 
 // inject_testing.go:
 
 var testAppSet = wire.NewSet(
-	ProvideTestConfigProvider, log.NewTestingLogger, wire.Bind(new(log.Logger), new(*log.TestingLogger)), transaction.NewInMemoryTransactionContext, wire.Bind(new(transaction.TxContext), new(*transaction.InMemoryTxContext)), account.NewInMemoryRepository, wire.Bind(new(account.Repository), new(*account.InMemoryRepository)), provision.NewInMemoryJobRepository, wire.Bind(new(provision.JobRepository), new(*provision.InMemoryJobRepository)), infrastructure.NewInMemoryServerRepository, wire.Bind(new(infrastructure.ServerRepository), new(*infrastructure.InMemoryServerRepository)), infrastructure.NewInMemoryDeploymentRepository, wire.Bind(new(infrastructure.DeploymentRepository), new(*infrastructure.InMemoryDeploymentRepository)), infrastructure.NewInMemoryProviderSettingsRepository, wire.Bind(new(infrastructure.ProviderSettingsRepository), new(*infrastructure.InMemoryProviderSettingsRepository)), AppSet,
+	ProvideTestConfigProvider, log.NewTestingLogger, wire.Bind(new(log.Logger), new(*log.TestingLogger)), transaction.NewInMemoryTransactionContext, wire.Bind(new(transaction.TxContext), new(*transaction.InMemoryTxContext)), account.NewInMemoryRepository, wire.Bind(new(account.Repository), new(*account.InMemoryRepository)), provision.NewInMemoryJobRepository, wire.Bind(new(provision.JobRepository), new(*provision.InMemoryJobRepository)), infrastructure.NewInMemoryServerRepository, wire.Bind(new(infrastructure.ServerRepository), new(*infrastructure.InMemoryServerRepository)), infrastructure.NewInMemoryDeploymentRepository, wire.Bind(new(infrastructure.DeploymentRepository), new(*infrastructure.InMemoryDeploymentRepository)), infrastructure.NewInMemoryProviderSettingsRepository, wire.Bind(new(infrastructure.ProviderSettingsRepository), new(*infrastructure.InMemoryProviderSettingsRepository)), infrastructure.NewInMemoryProjectRepository, wire.Bind(new(infrastructure.ProjectRepository), new(*infrastructure.InMemoryProjectRepository)), account.NewInMemoryOrganizationRepository, wire.Bind(new(account.OrganizationRepository), new(*account.InMemoryOrganizationRepository)), account.NewInMemoryMembershipRepository, wire.Bind(new(account.MembershipRepository), new(*account.InMemoryMembershipRepository)), account.NewInMemoryInvitationRepository, wire.Bind(new(account.InvitationRepository), new(*account.InMemoryInvitationRepository)), account.NewInMemoryAPIKeyRepository, wire.Bind(new(account.APIKeyRepository), new(*account.InMemoryAPIKeyRepository)), account.NewInMemorySessionRepository, wire.Bind(new(account.SessionRepository), new(*account.InMemorySessionRepository)), account.NewInMemoryOneTimeTokenRepository, wire.Bind(new(account.OneTimeTokenRepository), new(*account.InMemoryOneTimeTokenRepository)), account.NewInMemoryRecoveryCodeRepository, wire.Bind(new(account.RecoveryCodeRepository), new(*account.InMemoryRecoveryCodeRepository)), account.NewInMemoryIdentityRepository, wire.Bind(new(account.IdentityRepository), new(*account.InMemoryIdentityRepository)), account.NewInMemorySSOAuthorizationRepository, wire.Bind(new(account.SSOAuthorizationRepository), new(*account.InMemorySSOAuthorizationRepository)), audit.NewInMemoryRepository, wire.Bind(new(audit.Repository), new(*audit.InMemoryRepository)), server.NewInMemoryThrottleStore, wire.Bind(new(server.ThrottleStore), new(*server.InMemoryThrottleStore)), encryption.NewInMemoryRotator, wire.Bind(new(encryption.Rotator), new(*encryption.InMemoryRotator)), mail.NewInMemoryMailer, wire.Bind(new(mail.Mailer), new(*mail.InMemoryMailer)), infrastructure.NewInMemoryReservedIPRepository, wire.Bind(new(infrastructure.ReservedIPRepository), new(*infrastructure.InMemoryReservedIPRepository)), infrastructure.NewInMemoryVolumeSnapshotRepository, wire.Bind(new(infrastructure.VolumeSnapshotRepository), new(*infrastructure.InMemoryVolumeSnapshotRepository)), AppSet,
 )
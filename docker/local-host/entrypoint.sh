@@ -0,0 +1,21 @@
+#!/bin/sh
+set -e
+
+if [ -n "$SSH_AUTHORIZED_KEY" ]; then
+    grep -q -x -F "$SSH_AUTHORIZED_KEY" /root/.ssh/authorized_keys 2>/dev/null || \
+        echo "$SSH_AUTHORIZED_KEY" >> /root/.ssh/authorized_keys
+    chmod 600 /root/.ssh/authorized_keys
+fi
+
+# There is no init system inside the container, so keep the Docker daemon
+# running once the docker role has installed it.
+(
+    while true; do
+        if command -v dockerd >/dev/null 2>&1 && ! pgrep -x dockerd >/dev/null; then
+            dockerd >/var/log/dockerd.log 2>&1 &
+        fi
+        sleep 2
+    done
+) &
+
+exec /usr/sbin/sshd -D -e
@@ -15,20 +15,49 @@ import (
 	"blockpropeller.dev/lib/test"
 	"github.com/blang/semver"
 
+	_ "blockpropeller.dev/blockpropeller/terraform/cloudprovider/aws"
 	_ "blockpropeller.dev/blockpropeller/terraform/cloudprovider/digitalocean"
+	_ "blockpropeller.dev/blockpropeller/terraform/cloudprovider/google"
+	_ "blockpropeller.dev/blockpropeller/terraform/cloudprovider/hcloud"
 )
 
 func TestProvisioningJob(t *testing.T) {
 	test.Integration(t)
 
 	app := blockpropeller.SetupTestApp(t)
-	app.InitGlobal()
+	test.CheckErr(t, "init global", app.InitGlobal())
 
 	acc := createTestAccount(t, app)
 
 	provider := infrastructure.NewProviderSettings(
 		acc.ID, "Test Provider", infrastructure.ProviderDigitalOcean, app.Config.DigitalOcean.AccessToken)
 
+	runProvisioningJob(t, app, acc, provider)
+}
+
+// TestLocalProvisioningJob runs the whole provisioning process against containers
+// on a local Docker daemon, requiring no cloud provider account.
+//
+// The blockpropeller/local-host image must be built beforehand with `make local-host-image`.
+func TestLocalProvisioningJob(t *testing.T) {
+	test.Integration(t)
+
+	app := blockpropeller.SetupTestApp(t)
+	test.CheckErr(t, "init global", app.InitGlobal())
+
+	if app.Config.Local == nil || app.Config.Local.DockerHost == "" {
+		t.Skip("local Docker host not configured")
+	}
+
+	acc := createTestAccount(t, app)
+
+	provider := infrastructure.NewProviderSettings(
+		acc.ID, "Local Provider", infrastructure.ProviderLocal, app.Config.Local.DockerHost)
+
+	runProvisioningJob(t, app, acc, provider)
+}
+
+func runProvisioningJob(t *testing.T, app *blockpropeller.App, acc *account.Account, provider *infrastructure.ProviderSettings) {
 	server, err := infrastructure.NewServerBuilder(acc.ID).
 		Provider(provider.Type).
 		Build()
@@ -66,7 +95,7 @@ func TestProvisioningJob(t *testing.T) {
 	test.AssertStringsEqual(t, "sever provisioning state",
 		srv.State.String(), infrastructure.ServerStateOk.String())
 	test.AssertStringsEqual(t, "server provider",
-		srv.Provider.String(), infrastructure.ProviderDigitalOcean.String())
+		srv.Provider.String(), provider.Type.String())
 
 	test.AssertIntsEqual(t, "server has deployment", len(srv.Deployments), 1)
 	test.AssertStringsEqual(t, "deployment ready",
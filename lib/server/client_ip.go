@@ -0,0 +1,113 @@
+package server
+
+import (
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo"
+	"github.com/pkg/errors"
+)
+
+const clientIPKey = "_client_ip"
+
+// ParseTrustedProxies parses the addresses of trusted proxies, given either as IPs or CIDR ranges.
+func ParseTrustedProxies(proxies []string) ([]*net.IPNet, error) {
+	var nets []*net.IPNet
+	for _, proxy := range proxies {
+		if !strings.Contains(proxy, "/") {
+			ip := net.ParseIP(proxy)
+			if ip == nil {
+				return nil, errors.Errorf("invalid trusted proxy %s", proxy)
+			}
+
+			nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(len(ip)*8, len(ip)*8)})
+			continue
+		}
+
+		_, ipNet, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid trusted proxy %s", proxy)
+		}
+
+		nets = append(nets, ipNet)
+	}
+
+	return nets, nil
+}
+
+// ClientIPMiddleware returns a middleware resolving the IP address of the client sending the request.
+//
+// The X-Forwarded-For and X-Real-IP headers are honoured only for requests coming from the trusted proxies,
+// as anyone else can set them to an arbitrary address.
+func ClientIPMiddleware(trustedProxies []*net.IPNet) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.Set(clientIPKey, resolveClientIP(c.Request(), trustedProxies))
+
+			return next(c)
+		}
+	}
+}
+
+// ClientIP returns the IP address of the client sending the request,
+// as resolved by the ClientIPMiddleware, or the address of the peer if it was not used.
+func ClientIP(c echo.Context) string {
+	if ip, ok := c.Get(clientIPKey).(string); ok {
+		return ip
+	}
+
+	return remoteIP(c.Request())
+}
+
+// resolveClientIP walks the X-Forwarded-For header from the closest proxy,
+// returning the first address which is not a trusted proxy.
+func resolveClientIP(req *http.Request, trustedProxies []*net.IPNet) string {
+	ip := remoteIP(req)
+	if !isTrustedProxy(ip, trustedProxies) {
+		return ip
+	}
+
+	forwarded := strings.Split(req.Header.Get(echo.HeaderXForwardedFor), ",")
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(forwarded[i])
+		if net.ParseIP(hop) == nil {
+			break
+		}
+
+		ip = hop
+		if !isTrustedProxy(ip, trustedProxies) {
+			return ip
+		}
+	}
+
+	if realIP := strings.TrimSpace(req.Header.Get(echo.HeaderXRealIP)); net.ParseIP(realIP) != nil {
+		return realIP
+	}
+
+	return ip
+}
+
+func remoteIP(req *http.Request) string {
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		return req.RemoteAddr
+	}
+
+	return host
+}
+
+func isTrustedProxy(ip string, trustedProxies []*net.IPNet) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+
+	for _, proxy := range trustedProxies {
+		if proxy.Contains(parsed) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -15,6 +15,13 @@ type Config struct {
 	ReadTimeout time.Duration `yaml:"read_timeout"`
 	// WriteTimeout in seconds.
 	WriteTimeout time.Duration `yaml:"write_timeout"`
+
+	// TrustedProxies are the IPs or CIDR ranges of the proxies whose X-Forwarded-For
+	// and X-Real-IP headers are trusted to hold the IP address of the client.
+	TrustedProxies []string `yaml:"trusted_proxies"`
+
+	// Throttle limits the failed attempts of sensitive endpoints.
+	Throttle *ThrottleConfig `yaml:"throttle"`
 }
 
 // Validate satisfies the config.Config interface.
@@ -31,5 +38,9 @@ func (cfg *Config) Validate() error {
 		cfg.WriteTimeout = 30
 	}
 
+	if _, err := ParseTrustedProxies(cfg.TrustedProxies); err != nil {
+		return err
+	}
+
 	return nil
 }
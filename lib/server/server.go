@@ -21,6 +21,11 @@ type Server struct {
 
 // ProvideServer configures a Server instance and prepares it for listening for new requests.
 func ProvideServer(cfg *Config, router Router, logger log.Logger) (*Server, error) {
+	trustedProxies, err := ParseTrustedProxies(cfg.TrustedProxies)
+	if err != nil {
+		return nil, err
+	}
+
 	e := echo.New()
 	e.HideBanner = true
 	e.Validator = newRequestValidator()
@@ -28,9 +33,10 @@ func ProvideServer(cfg *Config, router Router, logger log.Logger) (*Server, erro
 	e.Use(middleware.CORS())
 
 	e.HTTPErrorHandler = httpErrorHandler
+	e.Use(ClientIPMiddleware(trustedProxies))
 	e.Use(LoggerMiddleware(logger))
 
-	err := router.RegisterRoutes(e)
+	err = router.RegisterRoutes(e)
 	if err != nil {
 		return nil, errors.Wrap(err, "register routes")
 	}
@@ -0,0 +1,395 @@
+package server
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"blockpropeller.dev/lib/log"
+	"github.com/labstack/echo"
+	"github.com/pkg/errors"
+)
+
+// ThrottleConfig for limiting the failed attempts of sensitive endpoints, such as logging in.
+type ThrottleConfig struct {
+	// Window in which failed attempts count towards a lockout.
+	Window time.Duration `yaml:"window"`
+
+	// MaxFailuresPerIP is the number of failed attempts from a single IP address before it is locked out.
+	MaxFailuresPerIP int `yaml:"max_failures_per_ip"`
+	// MaxFailuresPerAccount is the number of failed attempts for a single account before it is locked out.
+	MaxFailuresPerAccount int `yaml:"max_failures_per_account"`
+
+	// Lockout is the length of the first lockout, which doubles with every consecutive one.
+	Lockout time.Duration `yaml:"lockout"`
+	// MaxLockout caps the length of a lockout. Keys with no failed attempts
+	// for as long are forgiven all their previous lockouts.
+	MaxLockout time.Duration `yaml:"max_lockout"`
+}
+
+// Validate satisfies the config.Config interface.
+func (cfg *ThrottleConfig) Validate() error {
+	if cfg.Window == 0 {
+		cfg.Window = 15 * time.Minute
+	}
+	if cfg.MaxFailuresPerIP == 0 {
+		cfg.MaxFailuresPerIP = 50
+	}
+	if cfg.MaxFailuresPerAccount == 0 {
+		cfg.MaxFailuresPerAccount = 5
+	}
+	if cfg.Lockout == 0 {
+		cfg.Lockout = time.Minute
+	}
+	if cfg.MaxLockout == 0 {
+		cfg.MaxLockout = 24 * time.Hour
+	}
+
+	if cfg.Lockout > cfg.MaxLockout {
+		return errors.New("throttle lockout cannot be longer than the max lockout")
+	}
+
+	return nil
+}
+
+// ThrottleRule limits the failed attempts sharing a key.
+type ThrottleRule struct {
+	// Name keeps the keys of different rules apart.
+	Name string
+	// Key extracts the value attempts are counted by from a request,
+	// or an empty string when the rule does not apply.
+	Key func(c echo.Context) string
+	// MaxFailures is the number of failed attempts before the key is locked out.
+	MaxFailures int
+	// ResetOnSuccess forgets the failed attempts of the key after a successful one.
+	ResetOnSuccess bool
+}
+
+// Throttle limits failed attempts by locking out their keys for an exponentially growing time.
+//
+// Requests failing with 401 Unauthorized or 403 Forbidden count as failed attempts, as do the ones
+// marked with MarkFailedAttempt, while locked out requests are rejected with 429 Too Many Requests before reaching the handler.
+// Every attempt is counted before reaching the handler and forgotten unless it fails,
+// so concurrent attempts cannot exceed the limit.
+type Throttle struct {
+	cfg   *ThrottleConfig
+	store ThrottleStore
+
+	mu sync.Mutex
+}
+
+// NewThrottle returns a new Throttle instance.
+func NewThrottle(cfg *ThrottleConfig, store ThrottleStore) *Throttle {
+	return &Throttle{cfg: cfg, store: store}
+}
+
+// ByIP returns a ThrottleRule limiting the failed attempts from a single IP address,
+// as resolved by the ClientIPMiddleware.
+func (t *Throttle) ByIP() ThrottleRule {
+	return ThrottleRule{
+		Name:        "ip",
+		Key:         ClientIP,
+		MaxFailures: t.cfg.MaxFailuresPerIP,
+	}
+}
+
+// ByAccount returns a ThrottleRule limiting the failed attempts for a single account,
+// identified by a field of the request body.
+//
+// Field values are compared case insensitively, and do not need to belong to an existing account,
+// so unknown accounts are throttled the same way as existing ones.
+func (t *Throttle) ByAccount(field string) ThrottleRule {
+	return t.ByAccountKey(func(c echo.Context) string {
+		return strings.ToLower(strings.TrimSpace(bodyField(c, field)))
+	})
+}
+
+// ByAccountKey returns a ThrottleRule limiting the failed attempts for a single account,
+// identified by the provided key, such as the account a request is authenticated as.
+func (t *Throttle) ByAccountKey(key func(c echo.Context) string) ThrottleRule {
+	return ThrottleRule{
+		Name:           "account",
+		Key:            key,
+		MaxFailures:    t.cfg.MaxFailuresPerAccount,
+		ResetOnSuccess: true,
+	}
+}
+
+// Middleware returns a middleware throttling the failed attempts according to the provided rules.
+func (t *Throttle) Middleware(rules ...ThrottleRule) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			keys := make(map[string]ThrottleRule)
+			for _, rule := range rules {
+				value := rule.Key(c)
+				if value == "" {
+					continue
+				}
+
+				keys[throttleKey(rule.Name, value)] = rule
+			}
+
+			lockedUntil, rule, err := t.countAttempt(c, keys)
+			if err != nil {
+				return err
+			}
+			if !lockedUntil.IsZero() {
+				retryAfter := int(time.Until(lockedUntil)/time.Second) + 1
+				c.Response().Header().Set("Retry-After", strconv.Itoa(retryAfter))
+
+				return echo.NewHTTPError(http.StatusTooManyRequests, "too many failed attempts, try again later").
+					SetInternal(errors.Errorf("%s locked out until %s", rule.Name, lockedUntil.Format(time.RFC3339)))
+			}
+
+			err = next(c)
+			for key, rule := range keys {
+				var resultErr error
+				switch {
+				case isIncompleteAttempt(c):
+					resultErr = t.forgetAttempt(c, key)
+				case isFailedAttempt(c, err):
+					resultErr = t.recordFailure(c, key, rule)
+				case err == nil && rule.ResetOnSuccess:
+					resultErr = t.store.Delete(c.Request().Context(), key)
+				default:
+					resultErr = t.forgetAttempt(c, key)
+				}
+				if resultErr != nil {
+					log.ErrorErr(resultErr, "record attempt", log.Fields{"rule": rule.Name})
+				}
+			}
+
+			return err
+		}
+	}
+}
+
+// countAttempt counts an attempt for all the keys, unless one of them is locked out,
+// in which case the time it is locked out until is returned along with its rule.
+func (t *Throttle) countAttempt(c echo.Context, keys map[string]ThrottleRule) (time.Time, ThrottleRule, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	ctx := c.Request().Context()
+	now := time.Now()
+
+	states := make(map[string]*ThrottleState, len(keys))
+	for key, rule := range keys {
+		state, err := t.store.Find(ctx, key)
+		switch {
+		case errors.Cause(err) == ErrThrottleStateNotFound:
+			state = &ThrottleState{Key: key}
+		case err != nil:
+			return time.Time{}, rule, errors.Wrap(err, "find throttle state")
+		case state.IsLocked(now):
+			return state.LockedUntil, rule, nil
+		case now.Sub(state.LastFailureAt) > t.cfg.MaxLockout:
+			state.Failures = 0
+			state.Lockouts = 0
+		case now.Sub(state.LastFailureAt) > t.cfg.Window:
+			state.Failures = 0
+		}
+
+		// Attempts still in progress already count towards the limit.
+		if state.Failures >= rule.MaxFailures {
+			t.lockOut(c, state, rule, now)
+
+			err = t.store.Save(ctx, state)
+			if err != nil {
+				return time.Time{}, rule, errors.Wrap(err, "save throttle state")
+			}
+
+			return state.LockedUntil, rule, nil
+		}
+
+		states[key] = state
+	}
+
+	for _, state := range states {
+		state.Failures++
+		state.LastFailureAt = now
+
+		err := t.store.Save(ctx, state)
+		if err != nil {
+			return time.Time{}, ThrottleRule{}, errors.Wrap(err, "save throttle state")
+		}
+	}
+
+	return time.Time{}, ThrottleRule{}, nil
+}
+
+// forgetAttempt forgets an attempt counted for the key which did not fail.
+func (t *Throttle) forgetAttempt(c echo.Context, key string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	ctx := c.Request().Context()
+
+	state, err := t.store.Find(ctx, key)
+	if errors.Cause(err) == ErrThrottleStateNotFound {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "find throttle state")
+	}
+	// The attempt was already forgotten by a lockout in the meantime.
+	if state.Failures == 0 {
+		return nil
+	}
+
+	state.Failures--
+
+	err = t.store.Save(ctx, state)
+	if err != nil {
+		return errors.Wrap(err, "save throttle state")
+	}
+
+	return nil
+}
+
+// recordFailure records the counted attempt for the key as failed,
+// locking the key out once it reaches the max failures of the rule.
+func (t *Throttle) recordFailure(c echo.Context, key string, rule ThrottleRule) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	ctx := c.Request().Context()
+	now := time.Now()
+
+	state, err := t.store.Find(ctx, key)
+	if errors.Cause(err) == ErrThrottleStateNotFound {
+		state = &ThrottleState{Key: key}
+	} else if err != nil {
+		return errors.Wrap(err, "find throttle state")
+	}
+	if state.IsLocked(now) {
+		return nil
+	}
+
+	state.LastFailureAt = now
+	if state.Failures < rule.MaxFailures {
+		log.Info("Failed attempt", log.Fields{
+			"rule":     rule.Name,
+			"ip":       ClientIP(c),
+			"path":     c.Request().URL.Path,
+			"failures": state.Failures,
+		})
+
+		return nil
+	}
+
+	t.lockOut(c, state, rule, now)
+
+	err = t.store.Save(ctx, state)
+	if err != nil {
+		return errors.Wrap(err, "save throttle state")
+	}
+
+	return nil
+}
+
+// lockOut locks the key of the state out, starting from the provided time.
+func (t *Throttle) lockOut(c echo.Context, state *ThrottleState, rule ThrottleRule, now time.Time) {
+	state.LockedUntil = now.Add(t.lockout(state.Lockouts))
+	state.LastFailureAt = now
+	state.Lockouts++
+	state.Failures = 0
+
+	log.Warn("Locked out after failed attempts", log.Fields{
+		"rule":         rule.Name,
+		"ip":           ClientIP(c),
+		"path":         c.Request().URL.Path,
+		"locked_until": state.LockedUntil,
+	})
+}
+
+// lockout returns the length of a lockout after the provided number of previous lockouts.
+func (t *Throttle) lockout(previous int) time.Duration {
+	lockout := t.cfg.Lockout
+	for i := 0; i < previous && lockout < t.cfg.MaxLockout; i++ {
+		lockout *= 2
+	}
+	if lockout > t.cfg.MaxLockout {
+		lockout = t.cfg.MaxLockout
+	}
+
+	return lockout
+}
+
+// isFailedAttempt checks whether the handler rejected the credentials of the request.
+func isFailedAttempt(c echo.Context, err error) bool {
+	if failed, _ := c.Get(failedAttemptKey).(bool); failed {
+		return true
+	}
+
+	he, ok := errors.Cause(err).(*echo.HTTPError)
+	if !ok {
+		return false
+	}
+
+	return he.Code == http.StatusUnauthorized || he.Code == http.StatusForbidden
+}
+
+const (
+	failedAttemptKey     = "_failed_attempt"
+	incompleteAttemptKey = "_incomplete_attempt"
+)
+
+// MarkFailedAttempt marks the attempt of a request as failed, for handlers rejecting
+// the credentials with a status other than 401 Unauthorized or 403 Forbidden.
+func MarkFailedAttempt(c echo.Context) {
+	c.Set(failedAttemptKey, true)
+}
+
+// MarkIncompleteAttempt marks the attempt of a request as one which has to be completed,
+// such as a login missing its second factor, so it neither counts as failed nor forgets the failures.
+func MarkIncompleteAttempt(c echo.Context) {
+	c.Set(incompleteAttemptKey, true)
+}
+
+func isIncompleteAttempt(c echo.Context) bool {
+	incomplete, _ := c.Get(incompleteAttemptKey).(bool)
+
+	return incomplete
+}
+
+// throttleKey hashes the value of a rule, so the values are not stored in plain text.
+func throttleKey(rule, value string) string {
+	hash := sha256.Sum256([]byte(rule + ":" + value))
+
+	return hex.EncodeToString(hash[:])
+}
+
+// bodyField reads a field from a JSON or form request body,
+// leaving the body in place for the handler to bind.
+func bodyField(c echo.Context, field string) string {
+	req := c.Request()
+	if !strings.HasPrefix(req.Header.Get(echo.HeaderContentType), echo.MIMEApplicationJSON) {
+		return c.FormValue(field)
+	}
+	if req.Body == nil {
+		return ""
+	}
+
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		return ""
+	}
+	req.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return ""
+	}
+
+	value, _ := fields[field].(string)
+
+	return value
+}
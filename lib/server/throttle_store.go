@@ -0,0 +1,80 @@
+package server
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ErrThrottleStateNotFound is returned when a ThrottleStore has no failed attempts recorded for a key.
+var ErrThrottleStateNotFound = errors.New("throttle state not found")
+
+// ThrottleState records the failed attempts sharing a throttling key.
+type ThrottleState struct {
+	// Key is the hash of the rule name and the value the attempts are throttled by.
+	Key string `gorm:"type:varchar(64);primary_key"`
+
+	// Failures is the number of failed attempts since the last lockout.
+	Failures int `gorm:"not null"`
+	// Lockouts is the number of consecutive lockouts, which doubles the length of the next one.
+	Lockouts int `gorm:"not null"`
+
+	LastFailureAt time.Time `gorm:"type:timestamp not null"`
+	LockedUntil   time.Time `gorm:"type:timestamp not null"`
+}
+
+// IsLocked checks whether the key is locked out at the provided time.
+func (state *ThrottleState) IsLocked(at time.Time) bool {
+	return at.Before(state.LockedUntil)
+}
+
+// ThrottleStore defines an interface for storing and retrieving the state of throttled keys.
+type ThrottleStore interface {
+	// Find the ThrottleState of a key.
+	Find(ctx context.Context, key string) (*ThrottleState, error)
+	// Save the ThrottleState of a key, creating it if it does not exist yet.
+	Save(ctx context.Context, state *ThrottleState) error
+	// Delete the ThrottleState of a key, forgetting all its failed attempts.
+	Delete(ctx context.Context, key string) error
+}
+
+// InMemoryThrottleStore holds the throttle states inside an in-memory map.
+//
+// Throttle states are not persisted on disk and won't survive program restarts.
+type InMemoryThrottleStore struct {
+	states sync.Map
+}
+
+// NewInMemoryThrottleStore returns a new InMemoryThrottleStore instance.
+func NewInMemoryThrottleStore() *InMemoryThrottleStore {
+	return &InMemoryThrottleStore{}
+}
+
+// Find the ThrottleState of a key.
+func (store *InMemoryThrottleStore) Find(ctx context.Context, key string) (*ThrottleState, error) {
+	state, ok := store.states.Load(key)
+	if !ok {
+		return nil, ErrThrottleStateNotFound
+	}
+
+	clone := *state.(*ThrottleState)
+
+	return &clone, nil
+}
+
+// Save the ThrottleState of a key, creating it if it does not exist yet.
+func (store *InMemoryThrottleStore) Save(ctx context.Context, state *ThrottleState) error {
+	clone := *state
+	store.states.Store(state.Key, &clone)
+
+	return nil
+}
+
+// Delete the ThrottleState of a key, forgetting all its failed attempts.
+func (store *InMemoryThrottleStore) Delete(ctx context.Context, key string) error {
+	store.states.Delete(key)
+
+	return nil
+}
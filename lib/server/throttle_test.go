@@ -0,0 +1,268 @@
+package server_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"blockpropeller.dev/lib/server"
+	"blockpropeller.dev/lib/test"
+	"github.com/labstack/echo"
+)
+
+// recordingThrottleStore keeps the last ThrottleState saved as locked out.
+type recordingThrottleStore struct {
+	*server.InMemoryThrottleStore
+
+	locked *server.ThrottleState
+}
+
+func (store *recordingThrottleStore) Save(ctx context.Context, state *server.ThrottleState) error {
+	if state.IsLocked(time.Now()) {
+		clone := *state
+		store.locked = &clone
+	}
+
+	return store.InMemoryThrottleStore.Save(ctx, state)
+}
+
+type throttleFixture struct {
+	e     *echo.Echo
+	store *recordingThrottleStore
+
+	// attempts counts the requests which reached the handler.
+	attempts int32
+	// release blocks the handler until it is closed, if set.
+	release chan struct{}
+}
+
+// newThrottleFixture serves a login endpoint accepting only the "password" password,
+// asking for the second factor of the "incomplete" password and refusing the "malformed" one as a bad request.
+func newThrottleFixture(t *testing.T, cfg *server.ThrottleConfig, trustedProxies ...string) *throttleFixture {
+	test.CheckErr(t, "validate throttle config", cfg.Validate())
+
+	proxies, err := server.ParseTrustedProxies(trustedProxies)
+	test.CheckErr(t, "parse trusted proxies", err)
+
+	f := &throttleFixture{
+		e:     echo.New(),
+		store: &recordingThrottleStore{InMemoryThrottleStore: server.NewInMemoryThrottleStore()},
+	}
+	throttle := server.NewThrottle(cfg, f.store)
+
+	f.e.Use(server.ClientIPMiddleware(proxies))
+	f.e.POST("/login", func(c echo.Context) error {
+		atomic.AddInt32(&f.attempts, 1)
+		if f.release != nil {
+			<-f.release
+		}
+
+		var req struct {
+			Email    string `json:"email"`
+			Password string `json:"password"`
+		}
+		if err := c.Bind(&req); err != nil {
+			return err
+		}
+		if req.Password == "incomplete" {
+			server.MarkIncompleteAttempt(c)
+
+			return echo.ErrUnauthorized
+		}
+		if req.Password == "malformed" {
+			server.MarkFailedAttempt(c)
+
+			return echo.ErrBadRequest
+		}
+		if req.Password != "password" {
+			return echo.ErrForbidden
+		}
+
+		return c.String(http.StatusOK, req.Email)
+	}, throttle.Middleware(throttle.ByIP(), throttle.ByAccount("email")))
+
+	return f
+}
+
+func (f *throttleFixture) login(ip, email, password string) *httptest.ResponseRecorder {
+	return f.loginForwarded(ip, "", email, password)
+}
+
+// loginForwarded logs in from the ip, with the provided X-Forwarded-For header.
+func (f *throttleFixture) loginForwarded(ip, forwardedFor, email, password string) *httptest.ResponseRecorder {
+	body := `{"email": "` + email + `", "password": "` + password + `"}`
+
+	req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.RemoteAddr = ip + ":54321"
+	if forwardedFor != "" {
+		req.Header.Set(echo.HeaderXForwardedFor, forwardedFor)
+	}
+
+	rec := httptest.NewRecorder()
+	f.e.ServeHTTP(rec, req)
+
+	return rec
+}
+
+func TestThrottleLocksOutAccount(t *testing.T) {
+	f := newThrottleFixture(t, &server.ThrottleConfig{MaxFailuresPerAccount: 3, Lockout: time.Minute})
+
+	for i := 0; i < 2; i++ {
+		rec := f.login("10.0.0.1", "user@example.com", "wrong")
+		test.AssertIntsEqual(t, "failed login status", rec.Code, http.StatusForbidden)
+	}
+
+	rec := f.login("10.0.0.1", "user@example.com", "password")
+	test.AssertIntsEqual(t, "successful login status", rec.Code, http.StatusOK)
+	test.AssertStringsEqual(t, "handler received body", rec.Body.String(), "user@example.com")
+
+	// A successful login forgets the previous failures of the account.
+	for i := 0; i < 3; i++ {
+		rec = f.login("10.0.0.1", "user@example.com", "wrong")
+		test.AssertIntsEqual(t, "failed login status", rec.Code, http.StatusForbidden)
+	}
+
+	rec = f.login("10.0.0.2", "User@Example.com", "password")
+	test.AssertIntsEqual(t, "locked out login status", rec.Code, http.StatusTooManyRequests)
+	test.AssertStringsEqual(t, "retry after", rec.Header().Get("Retry-After"), "60")
+
+	rec = f.login("10.0.0.1", "other@example.com", "password")
+	test.AssertIntsEqual(t, "other account login status", rec.Code, http.StatusOK)
+}
+
+func TestThrottleLocksOutIP(t *testing.T) {
+	f := newThrottleFixture(t, &server.ThrottleConfig{MaxFailuresPerIP: 3})
+
+	for _, email := range []string{"a@example.com", "b@example.com", "c@example.com"} {
+		rec := f.login("10.0.0.1", email, "wrong")
+		test.AssertIntsEqual(t, "failed login status", rec.Code, http.StatusForbidden)
+	}
+
+	rec := f.login("10.0.0.1", "d@example.com", "password")
+	test.AssertIntsEqual(t, "locked out ip status", rec.Code, http.StatusTooManyRequests)
+
+	rec = f.login("10.0.0.2", "d@example.com", "password")
+	test.AssertIntsEqual(t, "other ip status", rec.Code, http.StatusOK)
+}
+
+func TestThrottleIgnoresSpoofedForwardedFor(t *testing.T) {
+	f := newThrottleFixture(t, &server.ThrottleConfig{MaxFailuresPerIP: 3}, "10.0.0.100")
+
+	for _, spoofed := range []string{"192.0.2.1", "192.0.2.2", "192.0.2.3"} {
+		rec := f.loginForwarded("10.0.0.1", spoofed, spoofed+"@example.com", "wrong")
+		test.AssertIntsEqual(t, "failed login status", rec.Code, http.StatusForbidden)
+	}
+
+	rec := f.loginForwarded("10.0.0.1", "192.0.2.4", "d@example.com", "password")
+	test.AssertIntsEqual(t, "spoofed ip status", rec.Code, http.StatusTooManyRequests)
+
+	// Trusted proxies forward the IP of the client, which is locked out on its own.
+	for _, email := range []string{"a@example.com", "b@example.com", "c@example.com"} {
+		rec = f.loginForwarded("10.0.0.100", "192.0.2.1, 10.0.0.100", email, "wrong")
+		test.AssertIntsEqual(t, "failed login through proxy status", rec.Code, http.StatusForbidden)
+	}
+
+	rec = f.loginForwarded("10.0.0.100", "192.0.2.1", "d@example.com", "password")
+	test.AssertIntsEqual(t, "locked out client status", rec.Code, http.StatusTooManyRequests)
+
+	rec = f.loginForwarded("10.0.0.100", "192.0.2.2", "d@example.com", "password")
+	test.AssertIntsEqual(t, "other client status", rec.Code, http.StatusOK)
+}
+
+func TestThrottleCountsConcurrentAttempts(t *testing.T) {
+	f := newThrottleFixture(t, &server.ThrottleConfig{MaxFailuresPerAccount: 3})
+	f.release = make(chan struct{})
+
+	var wg sync.WaitGroup
+	codes := make(chan int, 10)
+	for i := 0; i < cap(codes); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			codes <- f.login("10.0.0.1", "user@example.com", "wrong").Code
+		}()
+	}
+
+	// Wait for the attempts within the limit to reach the handler, and the others to be rejected.
+	rejected := 0
+	for rejected < cap(codes)-3 {
+		code := <-codes
+		test.AssertIntsEqual(t, "concurrent login status", code, http.StatusTooManyRequests)
+		rejected++
+	}
+
+	close(f.release)
+	wg.Wait()
+	close(codes)
+
+	for code := range codes {
+		test.AssertIntsEqual(t, "failed login status", code, http.StatusForbidden)
+	}
+	test.AssertIntsEqual(t, "attempts reaching the handler", int(atomic.LoadInt32(&f.attempts)), 3)
+}
+
+func TestThrottleIgnoresIncompleteAttempts(t *testing.T) {
+	f := newThrottleFixture(t, &server.ThrottleConfig{MaxFailuresPerAccount: 2})
+
+	rec := f.login("10.0.0.1", "user@example.com", "wrong")
+	test.AssertIntsEqual(t, "failed login status", rec.Code, http.StatusForbidden)
+
+	// Incomplete attempts do not count as failed, nor forget the previous failures.
+	for i := 0; i < 3; i++ {
+		rec = f.login("10.0.0.1", "user@example.com", "incomplete")
+		test.AssertIntsEqual(t, "incomplete login status", rec.Code, http.StatusUnauthorized)
+	}
+
+	rec = f.login("10.0.0.1", "user@example.com", "wrong")
+	test.AssertIntsEqual(t, "failed login status", rec.Code, http.StatusForbidden)
+
+	rec = f.login("10.0.0.1", "user@example.com", "password")
+	test.AssertIntsEqual(t, "locked out login status", rec.Code, http.StatusTooManyRequests)
+}
+
+func TestThrottleCountsMarkedFailures(t *testing.T) {
+	f := newThrottleFixture(t, &server.ThrottleConfig{MaxFailuresPerAccount: 2})
+
+	for i := 0; i < 2; i++ {
+		rec := f.login("10.0.0.1", "user@example.com", "malformed")
+		test.AssertIntsEqual(t, "malformed login status", rec.Code, http.StatusBadRequest)
+	}
+
+	rec := f.login("10.0.0.1", "user@example.com", "password")
+	test.AssertIntsEqual(t, "locked out login status", rec.Code, http.StatusTooManyRequests)
+}
+
+func TestThrottleLockoutGrowsExponentially(t *testing.T) {
+	cfg := &server.ThrottleConfig{
+		MaxFailuresPerAccount: 1,
+		Lockout:               time.Minute,
+		MaxLockout:            3 * time.Minute,
+	}
+	f := newThrottleFixture(t, cfg)
+
+	for _, expected := range []time.Duration{time.Minute, 2 * time.Minute, 3 * time.Minute, 3 * time.Minute} {
+		start := time.Now()
+
+		rec := f.login("10.0.0.1", "user@example.com", "wrong")
+		test.AssertIntsEqual(t, "failed login status", rec.Code, http.StatusForbidden)
+
+		state := f.store.locked
+		f.store.locked = nil
+		test.AssertBoolEqual(t, "account locked out", state != nil, true)
+		test.AssertBoolEqual(t, "lockout length", state.LockedUntil.Sub(start) >= expected, true)
+		test.AssertBoolEqual(t, "lockout length", state.LockedUntil.Sub(start) < expected+time.Second, true)
+
+		rec = f.login("10.0.0.1", "user@example.com", "password")
+		test.AssertIntsEqual(t, "locked out login status", rec.Code, http.StatusTooManyRequests)
+
+		// Lift the lockout, keeping count of the previous ones.
+		state.LockedUntil = time.Now()
+		test.CheckErr(t, "lift lockout", f.store.Save(context.Background(), state))
+	}
+}
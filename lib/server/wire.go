@@ -6,4 +6,6 @@ import "github.com/google/wire"
 // that does not depend on any underlying dependencies.
 var Set = wire.NewSet(
 	ProvideServer,
+	NewThrottle,
+	wire.FieldsOf(new(*Config), "Throttle"),
 )
@@ -0,0 +1,268 @@
+// Package fakebin provides scripted stand-ins for external executables,
+// such as terraform and ansible-playbook, to be used inside tests.
+//
+// A fake binary records the arguments of every invocation and responds
+// according to the rules configured by the test. Rules are matched
+// against the invocation arguments, so `On("apply")` matches
+// `terraform apply -no-color tfplan` and `On("site.yaml")` matches
+// an ansible-playbook run of the site.yaml playbook.
+//
+// Invocations without a matching rule succeed without any output.
+package fakebin
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+const (
+	argSeparator  = "\037"
+	callSeparator = "\036"
+)
+
+// script is the body of every fake binary.
+//
+// The first argument with a rule directory selects the response.
+// Responses for the n-th matching call can be overridden in a numbered
+// subdirectory of the rule, which enables simulating transient failures.
+const script = `#!/bin/sh
+dir=%s
+
+{ for arg in "$@"; do printf '%%s\037' "$arg"; done; printf '\036'; } >> "$dir/calls"
+
+rule=""
+for arg in "$@"; do
+	case "$arg" in
+	""|.|..|*/*) continue ;;
+	esac
+	if [ -d "$dir/rules/$arg" ]; then
+		rule="$dir/rules/$arg"
+		break
+	fi
+done
+
+if [ -z "$rule" ]; then
+	exit 0
+fi
+
+n=$(( $(cat "$rule/count" 2>/dev/null || echo 0) + 1 ))
+echo "$n" > "$rule/count"
+if [ -d "$rule/$n" ]; then
+	rule="$rule/$n"
+fi
+
+if [ -f "$rule/capture" ]; then cp "$(cat "$rule/capture")" "$rule/captured" 2>/dev/null; fi
+if [ -f "$rule/stdout" ]; then cat "$rule/stdout"; fi
+if [ -f "$rule/stderr" ]; then cat "$rule/stderr" >&2; fi
+if [ -f "$rule/hang" ]; then exec sleep 3600; fi
+if [ -f "$rule/exit" ]; then exit "$(cat "$rule/exit")"; fi
+
+exit 0
+`
+
+// Binary is a fake executable living in a temporary directory.
+type Binary struct {
+	t    *testing.T
+	dir  string
+	path string
+}
+
+// New creates a new fake executable with the provided name.
+//
+// Close must be called in order to clean up the created files.
+func New(t *testing.T, name string) *Binary {
+	dir, err := ioutil.TempDir(os.TempDir(), "fakebin-")
+	if err != nil {
+		t.Fatalf("fakebin: create temp dir: %s", err)
+	}
+
+	bin := &Binary{
+		t:    t,
+		dir:  dir,
+		path: filepath.Join(dir, name),
+	}
+
+	err = os.MkdirAll(filepath.Join(dir, "rules"), 0755)
+	if err != nil {
+		t.Fatalf("fakebin: create rules dir: %s", err)
+	}
+
+	err = ioutil.WriteFile(bin.path, []byte(fmt.Sprintf(script, shellQuote(dir))), 0755)
+	if err != nil {
+		t.Fatalf("fakebin: write script: %s", err)
+	}
+
+	return bin
+}
+
+// Path returns the absolute path of the fake executable.
+func (bin *Binary) Path() string {
+	return bin.path
+}
+
+// On configures the response for every invocation containing the provided argument.
+func (bin *Binary) On(arg string) *Response {
+	if arg == "" || arg == "." || arg == ".." || strings.Contains(arg, "/") {
+		bin.t.Fatalf("fakebin: invalid rule argument: %q", arg)
+	}
+
+	return bin.response(filepath.Join(bin.dir, "rules", arg))
+}
+
+// OnCall configures the response for the n-th invocation containing the provided argument,
+// overriding the response configured through On.
+//
+// Calls are counted starting from 1.
+func (bin *Binary) OnCall(arg string, n int) *Response {
+	// Make sure the rule matches even when no response is configured through On.
+	bin.On(arg)
+
+	return bin.response(filepath.Join(bin.dir, "rules", arg, strconv.Itoa(n)))
+}
+
+func (bin *Binary) response(dir string) *Response {
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		bin.t.Fatalf("fakebin: create rule dir: %s", err)
+	}
+
+	return &Response{t: bin.t, dir: dir}
+}
+
+// Calls returns the arguments of all invocations so far, in order.
+func (bin *Binary) Calls() [][]string {
+	data, err := ioutil.ReadFile(filepath.Join(bin.dir, "calls"))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		bin.t.Fatalf("fakebin: read calls: %s", err)
+	}
+
+	var calls [][]string
+	for _, rawCall := range strings.Split(string(data), callSeparator) {
+		if rawCall == "" {
+			continue
+		}
+
+		args := strings.Split(rawCall, argSeparator)
+		calls = append(calls, args[:len(args)-1])
+	}
+
+	return calls
+}
+
+// Commands returns the first argument of every invocation so far, in order.
+//
+// This is usually the subcommand, as in `init` for `terraform init`.
+func (bin *Binary) Commands() []string {
+	var cmds []string
+	for _, call := range bin.Calls() {
+		if len(call) == 0 {
+			cmds = append(cmds, "")
+			continue
+		}
+
+		cmds = append(cmds, call[0])
+	}
+
+	return cmds
+}
+
+// Reset forgets all recorded invocations and configured responses.
+func (bin *Binary) Reset() {
+	err := os.RemoveAll(filepath.Join(bin.dir, "calls"))
+	if err != nil {
+		bin.t.Fatalf("fakebin: remove calls: %s", err)
+	}
+
+	err = os.RemoveAll(filepath.Join(bin.dir, "rules"))
+	if err != nil {
+		bin.t.Fatalf("fakebin: remove rules: %s", err)
+	}
+
+	err = os.MkdirAll(filepath.Join(bin.dir, "rules"), 0755)
+	if err != nil {
+		bin.t.Fatalf("fakebin: create rules dir: %s", err)
+	}
+}
+
+// Close removes the fake executable and all its recorded state.
+func (bin *Binary) Close() error {
+	return os.RemoveAll(bin.dir)
+}
+
+// Response describes the behaviour of a fake executable for matching invocations.
+type Response struct {
+	t   *testing.T
+	dir string
+}
+
+// Stdout sets the output written to the standard output.
+func (r *Response) Stdout(out string) *Response {
+	r.write("stdout", out)
+
+	return r
+}
+
+// Stderr sets the output written to the standard error.
+func (r *Response) Stderr(out string) *Response {
+	r.write("stderr", out)
+
+	return r
+}
+
+// Exit sets the exit code of the invocation.
+func (r *Response) Exit(code int) *Response {
+	r.write("exit", strconv.Itoa(code))
+
+	return r
+}
+
+// Capture makes the invocation keep a copy of the file at the provided path,
+// relative to its working directory, to be inspected through Captured.
+//
+// This allows asserting on files that are cleaned up once the invocation is done.
+func (r *Response) Capture(path string) *Response {
+	r.write("capture", path)
+
+	return r
+}
+
+// Captured returns the contents of the file copied by the last invocation configured with Capture.
+//
+// An empty string is returned if no file was captured.
+func (r *Response) Captured() string {
+	data, err := ioutil.ReadFile(filepath.Join(r.dir, "captured"))
+	if os.IsNotExist(err) {
+		return ""
+	}
+	if err != nil {
+		r.t.Fatalf("fakebin: read captured file: %s", err)
+	}
+
+	return string(data)
+}
+
+// Hang makes the invocation block until it is killed.
+func (r *Response) Hang() *Response {
+	r.write("hang", "")
+
+	return r
+}
+
+func (r *Response) write(name string, content string) {
+	err := ioutil.WriteFile(filepath.Join(r.dir, name), []byte(content), 0644)
+	if err != nil {
+		r.t.Fatalf("fakebin: write %s: %s", name, err)
+	}
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.Replace(s, "'", `'"'"'`, -1) + "'"
+}
@@ -0,0 +1,98 @@
+package fakebin_test
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"blockpropeller.dev/lib/test"
+	"blockpropeller.dev/lib/test/fakebin"
+)
+
+func TestBinaryRecordsCalls(t *testing.T) {
+	bin := fakebin.New(t, "terraform")
+	defer test.Close(t, bin)
+
+	out, err := exec.Command(bin.Path(), "init", "-no-color").Output()
+	test.CheckErr(t, "run init", err)
+	test.AssertStringsEqual(t, "init output", string(out), "")
+
+	_, err = exec.Command(bin.Path(), "--extra-vars", "key='value with spaces'", "").Output()
+	test.CheckErr(t, "run with extra vars", err)
+
+	calls := bin.Calls()
+	test.AssertIntsEqual(t, "len(Calls())", len(calls), 2)
+	test.AssertStringsEqual(t, "Calls()[0]", strings.Join(calls[0], "|"), "init|-no-color")
+	test.AssertStringsEqual(t, "Calls()[1]", strings.Join(calls[1], "|"), "--extra-vars|key='value with spaces'|")
+	test.AssertStringsEqual(t, "Commands()", strings.Join(bin.Commands(), ","), "init,--extra-vars")
+}
+
+func TestBinaryResponses(t *testing.T) {
+	bin := fakebin.New(t, "ansible-playbook")
+	defer test.Close(t, bin)
+
+	bin.On("site.yaml").Stdout("ok\n")
+	bin.OnCall("site.yaml", 1).Stderr("unreachable").Exit(4)
+
+	cmd := exec.Command(bin.Path(), "--inventory", "127.0.0.1,", "site.yaml")
+	_, err := cmd.Output()
+	exitErr, ok := err.(*exec.ExitError)
+	if !ok {
+		t.Fatalf("first call: expected exit error, got %v", err)
+	}
+	test.AssertIntsEqual(t, "first call exit code", exitErr.ExitCode(), 4)
+	test.AssertStringsEqual(t, "first call stderr", string(exitErr.Stderr), "unreachable")
+
+	out, err := exec.Command(bin.Path(), "--inventory", "127.0.0.1,", "site.yaml").Output()
+	test.CheckErr(t, "second call", err)
+	test.AssertStringsEqual(t, "second call output", string(out), "ok\n")
+}
+
+func TestBinaryHang(t *testing.T) {
+	bin := fakebin.New(t, "terraform")
+	defer test.Close(t, bin)
+
+	bin.On("apply").Hang()
+
+	cmd := exec.Command(bin.Path(), "apply")
+	err := cmd.Start()
+	test.CheckErr(t, "start apply", err)
+
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+
+	select {
+	case <-done:
+		t.Fatal("expected apply to hang")
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	err = cmd.Process.Kill()
+	test.CheckErr(t, "kill apply", err)
+	<-done
+}
+
+func TestBinaryCapture(t *testing.T) {
+	bin := fakebin.New(t, "terraform")
+	defer test.Close(t, bin)
+
+	destroy := bin.On("destroy").Capture("main.tf")
+
+	workDir, err := ioutil.TempDir(os.TempDir(), "fakebin-workdir-")
+	test.CheckErr(t, "create work dir", err)
+	defer os.RemoveAll(workDir)
+
+	err = ioutil.WriteFile(filepath.Join(workDir, "main.tf"), []byte(`provider "digitalocean" {}`), 0644)
+	test.CheckErr(t, "write main.tf", err)
+
+	cmd := exec.Command(bin.Path(), "destroy")
+	cmd.Dir = workDir
+	err = cmd.Run()
+	test.CheckErr(t, "run destroy", err)
+
+	test.AssertStringsEqual(t, "Captured()", destroy.Captured(), `provider "digitalocean" {}`)
+}
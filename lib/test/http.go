@@ -47,6 +47,31 @@ func SendPost(url string, src interface{}, expectCode int, dest interface{}) err
 	return sendRequest(req, expectCode, dest)
 }
 
+// SendPut is a shorthand for sending a PUT HTTP request.
+func SendPut(url string, src interface{}, expectCode int, dest interface{}) error {
+	body, err := RequestBody(src)
+	if err != nil {
+		return errors.Wrap(err, "prepare http request body")
+	}
+
+	req, err := http.NewRequest("PUT", withBaseURL(url), body)
+	if err != nil {
+		return errors.Wrap(err, "create put http request")
+	}
+
+	return sendRequest(req, expectCode, dest)
+}
+
+// SendDelete is a shorthand for sending a DELETE HTTP request.
+func SendDelete(url string, expectCode int, dest interface{}) error {
+	req, err := http.NewRequest("DELETE", withBaseURL(url), nil)
+	if err != nil {
+		return errors.Wrap(err, "create delete http request")
+	}
+
+	return sendRequest(req, expectCode, dest)
+}
+
 // sendRequest is a utility function for sending an HTTP request.
 func sendRequest(req *http.Request, expectCode int, dest interface{}) error {
 	for key, value := range headers {